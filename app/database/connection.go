@@ -168,6 +168,14 @@ func RunMigrations() error {
 		&models.ProductIngredient{},
 		&models.IngredientMovement{},
 
+		// Purchase models
+		&models.Supplier{},
+		&models.InventoryPurchase{},
+		&models.InventoryPurchaseItem{},
+		&models.PurchaseWithholding{},
+		&models.SupportDocument{},
+		&models.SupportDocumentAdjustmentNote{},
+
 		// Customer models
 		&models.Customer{},
 
@@ -186,15 +194,23 @@ func RunMigrations() error {
 		&models.Order{},
 		&models.OrderItem{},
 		&models.OrderItemModifier{},
+		&models.OrderStatusEvent{},
+
+		// Reservation models
+		&models.Reservation{},
+		&models.WaitlistEntry{},
 
 		// Sale models
 		&models.PaymentMethod{},
 		&models.Sale{},
 		&models.Payment{},
 		&models.PaymentAllocation{},
+		&models.SaleRefund{},
+		&models.SaleRefundVoid{},
 		&models.ElectronicInvoice{},
 		&models.CreditNote{},
 		&models.DebitNote{},
+		&models.ContingencyEvent{},
 
 		// Employee models
 		&models.Employee{},
@@ -204,6 +220,20 @@ func RunMigrations() error {
 		&models.CashRegisterReport{},
 		&models.AuditLog{},
 
+		// Mail models
+		&models.MailMessage{},
+		&models.MailAttachment{},
+		&models.MailDeliveryAttempt{},
+
+		// Print spooler models
+		&models.PrintJob{},
+		&models.ReceiptTemplate{},
+
+		// Time clock models
+		&models.TimeClockEntry{},
+		&models.TimeClockBreak{},
+		&models.ShiftSchedule{},
+
 		// Config models
 		&models.SystemConfig{},
 		&models.RestaurantConfig{},
@@ -213,6 +243,8 @@ func RunMigrations() error {
 		&models.UITheme{},
 		&models.GoogleSheetsConfig{},
 		&models.MCPConfig{},
+		&models.MCPClient{},
+		&models.MCPToolCallLog{},
 		&models.NetworkConfig{},
 		&models.TunnelConfig{},
 
@@ -226,6 +258,9 @@ func RunMigrations() error {
 		&models.BoldConfig{},
 		&models.BoldTerminal{},
 		&models.BoldPendingPayment{},
+		&models.BoldPendingVoid{},
+		&models.BoldReconciliation{},
+		&models.BoldReconciliationItem{},
 		&models.BoldWebhookLog{},
 	)
 
@@ -355,6 +390,20 @@ func runAdditionalMigrations() error {
 		return fmt.Errorf("failed to add type_organization_id column to restaurant_configs: %w", err)
 	}
 
+	// Move Bold payment methods and payments to the payment gateway columns
+	if err := db.Exec(`
+		UPDATE payment_methods SET gateway_id = 'bold'
+		WHERE use_bold_terminal = true AND (gateway_id IS NULL OR gateway_id = '')
+	`).Error; err != nil {
+		return fmt.Errorf("failed to migrate Bold payment methods to gateways: %w", err)
+	}
+	if err := db.Exec(`
+		UPDATE payments SET gateway_id = 'bold', gateway_transaction_id = bold_integration_id
+		WHERE bold_integration_id <> '' AND (gateway_transaction_id IS NULL OR gateway_transaction_id = '')
+	`).Error; err != nil {
+		return fmt.Errorf("failed to migrate Bold payments to gateways: %w", err)
+	}
+
 	log.Println("✅ Additional migrations completed successfully")
 	return nil
 }
@@ -407,6 +456,17 @@ func createIndexes() {
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at)")
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_orders_table_id ON orders(table_id)")
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id)")
+	db.Exec("CREATE INDEX IF NOT EXISTS idx_order_status_events_order_created ON order_status_events(order_id, created_at)")
+
+	// Reservation indexes
+	db.Exec("CREATE INDEX IF NOT EXISTS idx_reservations_table_time ON reservations(table_id, reserved_at)")
+
+	// Time clock indexes
+	db.Exec("CREATE INDEX IF NOT EXISTS idx_time_clock_entries_employee_clock_in ON time_clock_entries(employee_id, clock_in)")
+	// One open shift per employee, so concurrent clock-ins can't both succeed
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_time_clock_entries_open_unique ON time_clock_entries(employee_id) WHERE clock_out IS NULL AND deleted_at IS NULL").Error; err != nil {
+		log.Printf("Warning: Could not create open time clock entry index (duplicate open shifts?): %v", err)
+	}
 
 	// Sale indexes
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_sales_created_at ON sales(created_at)")
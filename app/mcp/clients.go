@@ -0,0 +1,223 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClientPermissions are the permissions of an MCP client authenticated with its own API key
+type ClientPermissions struct {
+	ID                 uint
+	Name               string
+	AllTools           bool     // No tool or category restriction
+	AllowedTools       []string // Tools the client may use when AllTools is false
+	WriteAccess        bool     // Scope "write": may call tools that modify data
+	RateLimitPerMinute int      // 0 = unlimited
+}
+
+// ToolCallLog describes a tool call for the audit log
+type ToolCallLog struct {
+	ClientID   *uint
+	ClientName string
+	Tool       string
+	Arguments  map[string]interface{}
+	Status     string // success, error, denied, rate_limited
+	Error      string
+	Duration   time.Duration
+	IPAddress  string
+}
+
+// Tool call statuses
+const (
+	toolCallSuccess     = "success"
+	toolCallError       = "error"
+	toolCallDenied      = "denied"
+	toolCallRateLimited = "rate_limited"
+)
+
+// requestClient is the caller of a request, stored in the request context
+// client is nil for the global API key or when authentication is disabled
+type requestClient struct {
+	client *ClientPermissions
+	ip     string
+}
+
+type clientContextKey struct{}
+
+// clientFromContext returns the caller of a request
+func clientFromContext(ctx context.Context) *requestClient {
+	if caller, ok := ctx.Value(clientContextKey{}).(*requestClient); ok {
+		return caller
+	}
+	return &requestClient{}
+}
+
+// clientID returns the ID sessions are bound to (0 = global API key)
+func (c *requestClient) clientID() uint {
+	if c.client == nil {
+		return 0
+	}
+	return c.client.ID
+}
+
+// requestAPIKey returns the API key sent in the X-API-Key header, a bearer token or the api_key query param
+func requestAPIKey(r *http.Request) string {
+	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+		return apiKey
+	}
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return r.URL.Query().Get("api_key")
+}
+
+// isGlobalAPIKey checks the key against the global API key of MCPConfig
+func (s *MCPServer) isGlobalAPIKey(apiKey string) bool {
+	return s.apiKey != "" && subtle.ConstantTimeCompare([]byte(apiKey), []byte(s.apiKey)) == 1
+}
+
+// canUseTool checks whether a tool is enabled and allowed for the client
+func (s *MCPServer) canUseTool(client *ClientPermissions, toolName string) bool {
+	if s.isToolDisabled(toolName) {
+		return false
+	}
+	if client == nil || client.AllTools {
+		return true
+	}
+	for _, tool := range client.AllowedTools {
+		if tool == toolName {
+			return true
+		}
+	}
+	return false
+}
+
+// toolAccessError returns why the client can't call a tool ("" if it can)
+func (s *MCPServer) toolAccessError(client *ClientPermissions, toolName string) string {
+	if s.isToolDisabled(toolName) {
+		return fmt.Sprintf("Tool '%s' is disabled.", toolName)
+	}
+	if s.readOnlyMode && isWriteOperation(toolName) {
+		return "Server is in read-only mode. Write operations are not allowed."
+	}
+	if !s.canUseTool(client, toolName) {
+		return fmt.Sprintf("Tool '%s' is not allowed for this client.", toolName)
+	}
+	if client != nil && !client.WriteAccess && isWriteOperation(toolName) {
+		return "This client has read-only access. Write operations are not allowed."
+	}
+	return ""
+}
+
+// resourceTools maps resources to the tools that expose the same data:
+// a client can read a resource if it may use any of them
+var resourceTools = map[string][]string{
+	resourceMenu:          {"list_products"},
+	resourceSalesToday:    {"get_daily_sales_report", "get_today_sales"},
+	resourceOpenOrders:    {"list_orders", "get_pending_orders"},
+	resourceLowStock:      {"get_low_stock_alerts"},
+	resourceDailyReport:   {"get_daily_sales_report"},
+	resourceOrderTemplate: {"get_order"},
+}
+
+// canReadResource checks whether the client may read a resource (or resource template)
+func (s *MCPServer) canReadResource(client *ClientPermissions, uri string) bool {
+	tools, exists := resourceTools[uri]
+	if !exists {
+		for _, prefix := range []string{resourceDailyReport, resourceOrderTemplate} {
+			if strings.HasPrefix(uri, prefix) {
+				tools = resourceTools[prefix]
+				break
+			}
+		}
+	}
+	for _, tool := range tools {
+		if s.canUseTool(client, tool) {
+			return true
+		}
+	}
+	return false
+}
+
+// promptTools lists the tools a client needs for each prompt
+var promptTools = map[string][]string{
+	"end_of_day_summary":      {"get_daily_sales_report"},
+	"restock_recommendations": {"get_low_stock_alerts"},
+	"sales_trend_analysis":    {"get_sales_by_period"},
+	"employee_performance":    {"get_sales_by_employee"},
+	"menu_optimization":       {"list_products", "get_top_products"},
+}
+
+// canGetPrompt checks whether the client may use a prompt
+func (s *MCPServer) canGetPrompt(client *ClientPermissions, name string) bool {
+	for _, tool := range promptTools[name] {
+		if !s.canUseTool(client, tool) {
+			return false
+		}
+	}
+	return true
+}
+
+// logToolCall records a tool call in the audit log
+func (s *MCPServer) logToolCall(ctx context.Context, toolName string, args map[string]interface{}, status, errMsg string, duration time.Duration) {
+	if s.deps.ClientService == nil {
+		return
+	}
+	caller := clientFromContext(ctx)
+	entry := ToolCallLog{
+		Tool:      toolName,
+		Arguments: args,
+		Status:    status,
+		Error:     errMsg,
+		Duration:  duration,
+		IPAddress: caller.ip,
+	}
+	if caller.client != nil {
+		clientID := caller.client.ID
+		entry.ClientID = &clientID
+		entry.ClientName = caller.client.Name
+	}
+	s.deps.ClientService.LogToolCall(entry)
+}
+
+// rateLimiter counts the calls of each client in one-minute windows
+type rateLimiter struct {
+	mu      sync.Mutex
+	windows map[uint]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{windows: make(map[uint]*rateWindow)}
+}
+
+// allow records a call and reports whether the client is still within its limit
+func (l *rateLimiter) allow(client *ClientPermissions) bool {
+	if client == nil || client.RateLimitPerMinute <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	window, exists := l.windows[client.ID]
+	if !exists || now.Sub(window.start) >= time.Minute {
+		window = &rateWindow{start: now}
+		l.windows[client.ID] = window
+	}
+	if window.count >= client.RateLimitPerMinute {
+		return false
+	}
+	window.count++
+	return true
+}
@@ -0,0 +1,214 @@
+package mcp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeClientService authenticates the keys of a fixed set of clients
+type fakeClientService struct {
+	clients map[string]*ClientPermissions
+}
+
+func (f *fakeClientService) HasClients() bool {
+	return len(f.clients) > 0
+}
+
+func (f *fakeClientService) AuthenticateClient(apiKey string, ip string) (*ClientPermissions, error) {
+	if client, exists := f.clients[apiKey]; exists {
+		return client, nil
+	}
+	return nil, errors.New("invalid API key")
+}
+
+func (f *fakeClientService) LogToolCall(entry ToolCallLog) {}
+
+func TestRequestAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		headers map[string]string
+		want    string
+	}{
+		{"X-API-Key header", "/mcp", map[string]string{"X-API-Key": "k1"}, "k1"},
+		{"bearer token", "/mcp", map[string]string{"Authorization": "Bearer k2"}, "k2"},
+		{"lowercase bearer", "/mcp", map[string]string{"Authorization": "bearer k3"}, "k3"},
+		{"query param", "/mcp?api_key=k4", nil, "k4"},
+		{"header wins over query", "/mcp?api_key=k4", map[string]string{"X-API-Key": "k1"}, "k1"},
+		{"basic auth is ignored", "/mcp", map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}, ""},
+		{"no key", "/mcp", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tt.url, nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if got := requestAPIKey(r); got != tt.want {
+				t.Errorf("requestAPIKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"direct LAN client", "192.168.1.20:5000", nil, "192.168.1.20"},
+		{"LAN client spoofing proxy headers", "192.168.1.20:5000",
+			map[string]string{"CF-Connecting-IP": "127.0.0.1", "X-Forwarded-For": "127.0.0.1", "X-Real-IP": "127.0.0.1"}, "192.168.1.20"},
+		{"tunnel with Cloudflare header", "127.0.0.1:5000",
+			map[string]string{"CF-Connecting-IP": "1.2.3.4", "X-Forwarded-For": "5.6.7.8"}, "1.2.3.4"},
+		{"proxy with forwarded chain", "[::1]:5000",
+			map[string]string{"X-Forwarded-For": "5.6.7.8, 10.0.0.1"}, "5.6.7.8"},
+		{"proxy with real IP", "127.0.0.1:5000", map[string]string{"X-Real-IP": "9.9.9.9"}, "9.9.9.9"},
+		{"loopback without headers", "127.0.0.1:5000", nil, "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/mcp", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if got := getClientIP(r); got != tt.want {
+				t.Errorf("getClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	reader := &ClientPermissions{ID: 7, Name: "Reportes"}
+	clients := &fakeClientService{clients: map[string]*ClientPermissions{"client-key": reader}}
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		allowedIPs string
+		clients    ClientServiceInterface
+		path       string
+		remoteAddr string
+		headers    map[string]string
+		wantStatus int
+		wantClient *ClientPermissions
+	}{
+		{"open server", "", "", nil, "/mcp", "192.168.1.20:5000", nil, http.StatusOK, nil},
+		{"health check skips auth", "global", "10.0.0.1", nil, "/health", "192.168.1.20:5000", nil, http.StatusOK, nil},
+		{"missing key", "global", "", nil, "/mcp", "192.168.1.20:5000", nil, http.StatusUnauthorized, nil},
+		{"global key", "global", "", clients, "/mcp", "192.168.1.20:5000",
+			map[string]string{"X-API-Key": "global"}, http.StatusOK, nil},
+		{"client key", "global", "", clients, "/mcp", "192.168.1.20:5000",
+			map[string]string{"Authorization": "Bearer client-key"}, http.StatusOK, reader},
+		{"client key without global key", "", "", clients, "/mcp", "192.168.1.20:5000",
+			map[string]string{"X-API-Key": "client-key"}, http.StatusOK, reader},
+		{"clients require a key", "", "", clients, "/mcp", "192.168.1.20:5000", nil, http.StatusUnauthorized, nil},
+		{"unknown key", "global", "", clients, "/mcp", "192.168.1.20:5000",
+			map[string]string{"X-API-Key": "other"}, http.StatusUnauthorized, nil},
+		{"client key without clients", "global", "", nil, "/mcp", "192.168.1.20:5000",
+			map[string]string{"X-API-Key": "client-key"}, http.StatusUnauthorized, nil},
+		{"allowed IP", "", "192.168.1.20, 10.0.0.1", nil, "/mcp", "192.168.1.20:5000", nil, http.StatusOK, nil},
+		{"IP not allowed", "", "10.0.0.1", nil, "/mcp", "192.168.1.20:5000", nil, http.StatusForbidden, nil},
+		{"spoofed header does not bypass the IP list", "", "10.0.0.1", nil, "/mcp", "192.168.1.20:5000",
+			map[string]string{"X-Forwarded-For": "10.0.0.1"}, http.StatusForbidden, nil},
+		{"forwarded IP from a local proxy", "", "10.0.0.1", nil, "/mcp", "127.0.0.1:5000",
+			map[string]string{"X-Forwarded-For": "10.0.0.1"}, http.StatusOK, nil},
+		{"wildcard IP", "", "*", nil, "/mcp", "192.168.1.20:5000", nil, http.StatusOK, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMCPServer(0, tt.apiKey, tt.allowedIPs, "", false, "", &ServiceDependencies{ClientService: tt.clients})
+
+			var caller *requestClient
+			handler := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				caller = clientFromContext(r.Context())
+			}))
+			r := httptest.NewRequest("POST", tt.path, nil)
+			r.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && caller.client != tt.wantClient {
+				t.Errorf("caller client = %v, want %v", caller.client, tt.wantClient)
+			}
+		})
+	}
+}
+
+func TestToolAccessError(t *testing.T) {
+	s := NewMCPServer(0, "", "", "", false, "delete_product", &ServiceDependencies{})
+	readOnly := NewMCPServer(0, "", "", "", true, "", &ServiceDependencies{})
+	limited := &ClientPermissions{ID: 1, AllowedTools: []string{"list_products", "create_order"}}
+	writer := &ClientPermissions{ID: 2, AllowedTools: []string{"list_products", "create_order"}, WriteAccess: true}
+	admin := &ClientPermissions{ID: 3, AllTools: true, WriteAccess: true}
+
+	tests := []struct {
+		name    string
+		server  *MCPServer
+		client  *ClientPermissions
+		tool    string
+		allowed bool
+	}{
+		{"global key reads", s, nil, "list_products", true},
+		{"global key writes", s, nil, "create_order", true},
+		{"disabled tool", s, admin, "delete_product", false},
+		{"read-only server", readOnly, nil, "create_order", false},
+		{"read-only server still reads", readOnly, admin, "list_products", true},
+		{"allowed tool", s, limited, "list_products", true},
+		{"tool outside the list", s, limited, "get_today_sales", false},
+		{"read-only client", s, limited, "create_order", false},
+		{"write client", s, writer, "create_order", true},
+		{"all tools", s, admin, "get_today_sales", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.server.toolAccessError(tt.client, tt.tool)
+			if (got == "") != tt.allowed {
+				t.Errorf("toolAccessError(%q) = %q, allowed %v", tt.tool, got, tt.allowed)
+			}
+		})
+	}
+
+	if !s.canReadResource(limited, resourceMenu) {
+		t.Error("client with list_products should read the menu resource")
+	}
+	if s.canReadResource(limited, resourceLowStock) {
+		t.Error("client without get_low_stock_alerts should not read the low stock resource")
+	}
+	if s.canGetPrompt(limited, "end_of_day_summary") {
+		t.Error("client without get_daily_sales_report should not get the end of day prompt")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter()
+	client := &ClientPermissions{ID: 1, RateLimitPerMinute: 2}
+	other := &ClientPermissions{ID: 2, RateLimitPerMinute: 2}
+
+	if !l.allow(client) || !l.allow(client) {
+		t.Fatal("calls within the limit were rejected")
+	}
+	if l.allow(client) {
+		t.Error("third call in the same minute was allowed")
+	}
+	if !l.allow(other) {
+		t.Error("the limit of one client affected another")
+	}
+	for i := 0; i < 5; i++ {
+		if !l.allow(nil) || !l.allow(&ClientPermissions{ID: 3}) {
+			t.Fatal("global key and unlimited clients should never be limited")
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// getPromptDefinitions returns the canned analyses for prompts/list
+func getPromptDefinitions() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"name":        "end_of_day_summary",
+			"description": "Summarize a day of operation: sales, payment methods, top products, cash register and pending issues",
+			"arguments": []map[string]interface{}{
+				{
+					"name":        "date",
+					"description": "Date to summarize (YYYY-MM-DD). Defaults to today.",
+					"required":    false,
+				},
+			},
+		},
+		{
+			"name":        "restock_recommendations",
+			"description": "Suggest what to buy based on low stock products and ingredients",
+		},
+		{
+			"name":        "sales_trend_analysis",
+			"description": "Analyze how sales evolved over a period and what drives the changes",
+			"arguments": []map[string]interface{}{
+				{
+					"name":        "from_date",
+					"description": "Start date (YYYY-MM-DD). Defaults to 30 days ago.",
+					"required":    false,
+				},
+				{
+					"name":        "to_date",
+					"description": "End date (YYYY-MM-DD). Defaults to today.",
+					"required":    false,
+				},
+				{
+					"name":        "group_by",
+					"description": "day, week or month (default: day)",
+					"required":    false,
+				},
+			},
+		},
+		{
+			"name":        "employee_performance",
+			"description": "Compare the sales of each employee over a period",
+			"arguments": []map[string]interface{}{
+				{
+					"name":        "from_date",
+					"description": "Start date (YYYY-MM-DD). Defaults to 7 days ago.",
+					"required":    false,
+				},
+				{
+					"name":        "to_date",
+					"description": "End date (YYYY-MM-DD). Defaults to today.",
+					"required":    false,
+				},
+			},
+		},
+		{
+			"name":        "menu_optimization",
+			"description": "Review the menu against the best sellers and suggest price or menu changes",
+		},
+	}
+}
+
+// getPrompt builds the messages of a prompt, embedding the current POS data
+// Data the client has no access to is left out
+func (s *MCPServer) getPrompt(name string, args map[string]string, client *ClientPermissions) (map[string]interface{}, error) {
+	today := time.Now().Format("2006-01-02")
+	argOrDefault := func(key, defaultValue string) string {
+		if value := args[key]; value != "" {
+			return value
+		}
+		return defaultValue
+	}
+
+	var description, instructions string
+	var resources []string
+	data := map[string]interface{}{}
+
+	switch name {
+	case "end_of_day_summary":
+		date := argOrDefault("date", today)
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid date (use YYYY-MM-DD): %s", date)
+		}
+		description = fmt.Sprintf("End of day summary for %s", date)
+		instructions = fmt.Sprintf("Write the end of day summary of the restaurant for %s, in Spanish, for the owner. "+
+			"Include total sales, number of orders and average ticket, the breakdown by payment method, the best selling products, "+
+			"the cash register status and anything that needs attention (open orders, refunds, low stock). "+
+			"Finish with 3 concrete recommendations for tomorrow.", date)
+		if date == today {
+			resources = []string{resourceSalesToday, resourceOpenOrders, resourceLowStock}
+		} else {
+			resources = []string{resourceDailyReport + date}
+		}
+		if s.deps.DashboardService != nil && s.canUseTool(client, "get_top_products") {
+			if top, err := s.deps.DashboardService.GetTopSellingItems(10); err == nil {
+				data["top_selling_items"] = top
+			}
+		}
+		if date == today && s.deps.CashService != nil && s.canUseTool(client, "get_open_cash_register") {
+			if register, err := s.deps.CashService.GetOpenCashRegister(); err == nil {
+				data["open_cash_register"] = register
+			}
+		}
+
+	case "restock_recommendations":
+		description = "Restock recommendations"
+		instructions = "Using the low stock list and the inventory report, write a purchase list in Spanish grouped by supplier when known. " +
+			"Prioritize items that are out of stock or used by best selling products, suggest quantities to reach a comfortable stock " +
+			"and point out items that may be over-stocked."
+		resources = []string{resourceLowStock}
+		if s.deps.ReportsService != nil && s.canUseTool(client, "get_inventory_report") {
+			if report, err := s.deps.ReportsService.GetInventoryReport(); err == nil {
+				data["inventory_report"] = report
+			}
+		}
+
+	case "sales_trend_analysis":
+		from := argOrDefault("from_date", time.Now().AddDate(0, 0, -30).Format("2006-01-02"))
+		to := argOrDefault("to_date", today)
+		groupBy := argOrDefault("group_by", "day")
+		if s.deps.ReportsService == nil {
+			return nil, fmt.Errorf("reports service not available")
+		}
+		byPeriod, err := s.deps.ReportsService.GetSalesByPeriod(from, to, groupBy)
+		if err != nil {
+			return nil, err
+		}
+		data["sales_by_period"] = byPeriod
+		if s.canUseTool(client, "get_sales_by_payment_method") {
+			if byMethod, err := s.deps.ReportsService.GetSalesByPaymentMethod(from, to); err == nil {
+				data["sales_by_payment_method"] = byMethod
+			}
+		}
+		description = fmt.Sprintf("Sales trend from %s to %s", from, to)
+		instructions = fmt.Sprintf("Analyze the sales of the restaurant from %s to %s grouped by %s, in Spanish. "+
+			"Identify the trend, the best and worst periods, weekly patterns and changes in payment methods. "+
+			"Explain the likely causes and suggest actions to improve the weak periods.", from, to, groupBy)
+
+	case "employee_performance":
+		from := argOrDefault("from_date", time.Now().AddDate(0, 0, -7).Format("2006-01-02"))
+		to := argOrDefault("to_date", today)
+		if s.deps.ReportsService == nil {
+			return nil, fmt.Errorf("reports service not available")
+		}
+		byEmployee, err := s.deps.ReportsService.GetSalesByEmployee(from, to)
+		if err != nil {
+			return nil, err
+		}
+		data["sales_by_employee"] = byEmployee
+		description = fmt.Sprintf("Employee performance from %s to %s", from, to)
+		instructions = fmt.Sprintf("Compare the performance of each employee from %s to %s, in Spanish: sales, number of orders "+
+			"and average ticket. Highlight who stands out and who may need support, without making assumptions beyond the data.", from, to)
+
+	case "menu_optimization":
+		description = "Menu optimization"
+		instructions = "Review the menu against the best selling products, in Spanish. Point out products that rarely sell, " +
+			"categories with too many or too few options and prices that look out of line, and suggest concrete changes."
+		resources = []string{resourceMenu}
+		if s.deps.DashboardService != nil {
+			if top, err := s.deps.DashboardService.GetTopSellingItems(20); err == nil {
+				data["top_selling_items"] = top
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("prompt not found: %s", name)
+	}
+
+	messages := []map[string]interface{}{
+		{
+			"role": "user",
+			"content": map[string]interface{}{
+				"type": "text",
+				"text": instructions,
+			},
+		},
+	}
+	for _, uri := range resources {
+		if !s.canReadResource(client, uri) {
+			continue
+		}
+		text, err := s.readResource(uri)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, map[string]interface{}{
+			"role": "user",
+			"content": map[string]interface{}{
+				"type": "resource",
+				"resource": map[string]interface{}{
+					"uri":      uri,
+					"mimeType": "application/json",
+					"text":     text,
+				},
+			},
+		})
+	}
+	if len(data) > 0 {
+		text, _ := json.MarshalIndent(data, "", "  ")
+		messages = append(messages, map[string]interface{}{
+			"role": "user",
+			"content": map[string]interface{}{
+				"type": "text",
+				"text": string(text),
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"description": description,
+		"messages":    messages,
+	}, nil
+}
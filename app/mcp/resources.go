@@ -0,0 +1,248 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resource URIs exposed by the server
+const (
+	resourceMenu          = "pos://menu"
+	resourceSalesToday    = "pos://sales/today"
+	resourceOpenOrders    = "pos://orders/open"
+	resourceLowStock      = "pos://inventory/low-stock"
+	resourceDailyReport   = "pos://reports/daily/"
+	resourceOrderTemplate = "pos://orders/"
+)
+
+// errResourceNotFound is returned for unknown resource URIs
+var errResourceNotFound = errors.New("resource not found")
+
+// openOrderStatuses are the order statuses still being worked on
+var openOrderStatuses = []string{"pending", "preparing", "ready"}
+
+// getResourceDefinitions returns the static resources for resources/list
+func getResourceDefinitions() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"uri":         resourceMenu,
+			"name":        "Menu",
+			"description": "Active products grouped by category, with prices",
+			"mimeType":    "application/json",
+		},
+		{
+			"uri":         resourceSalesToday,
+			"name":        "Today's sales summary",
+			"description": "Totals for today: sales, orders, payment methods and top products",
+			"mimeType":    "application/json",
+		},
+		{
+			"uri":         resourceOpenOrders,
+			"name":        "Open orders",
+			"description": "Orders that are pending, being prepared or ready to deliver",
+			"mimeType":    "application/json",
+		},
+		{
+			"uri":         resourceLowStock,
+			"name":        "Low stock",
+			"description": "Products and ingredients at or below their minimum stock",
+			"mimeType":    "application/json",
+		},
+	}
+}
+
+// getResourceTemplates returns the parameterized resources for resources/templates/list
+func getResourceTemplates() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"uriTemplate": resourceDailyReport + "{date}",
+			"name":        "Daily sales report",
+			"description": "Sales summary of a specific date (YYYY-MM-DD)",
+			"mimeType":    "application/json",
+		},
+		{
+			"uriTemplate": resourceOrderTemplate + "{order_id}",
+			"name":        "Order",
+			"description": "Order details including items, status and totals",
+			"mimeType":    "application/json",
+		},
+	}
+}
+
+// readResource returns the content of a resource as JSON text
+func (s *MCPServer) readResource(uri string) (string, error) {
+	data, err := s.resourceData(uri)
+	if err != nil {
+		return "", err
+	}
+	text, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding resource: %w", err)
+	}
+	return string(text), nil
+}
+
+// resourceData loads the data behind a resource URI
+func (s *MCPServer) resourceData(uri string) (interface{}, error) {
+	switch {
+	case uri == resourceMenu:
+		return s.menuResource()
+
+	case uri == resourceSalesToday:
+		return s.salesSummaryResource(time.Now().Format("2006-01-02"))
+
+	case uri == resourceOpenOrders:
+		return s.openOrdersResource()
+
+	case uri == resourceLowStock:
+		return s.lowStockResource()
+
+	case strings.HasPrefix(uri, resourceDailyReport):
+		date := strings.TrimPrefix(uri, resourceDailyReport)
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid date in resource URI (use YYYY-MM-DD): %s", date)
+		}
+		return s.salesSummaryResource(date)
+
+	case strings.HasPrefix(uri, resourceOrderTemplate):
+		orderID, err := strconv.ParseUint(strings.TrimPrefix(uri, resourceOrderTemplate), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errResourceNotFound, uri)
+		}
+		if s.deps.OrderService == nil {
+			return nil, fmt.Errorf("order service not available")
+		}
+		return s.deps.OrderService.GetOrder(uint(orderID))
+
+	default:
+		return nil, fmt.Errorf("%w: %s", errResourceNotFound, uri)
+	}
+}
+
+// menuResource groups the active products by category
+func (s *MCPServer) menuResource() (interface{}, error) {
+	if s.deps.ProductService == nil {
+		return nil, fmt.Errorf("product service not available")
+	}
+	categories, err := s.deps.ProductService.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.deps.ProductService.GetAllProducts()
+	if err != nil {
+		return nil, err
+	}
+
+	productsByCategory := make(map[float64][]map[string]interface{})
+	for _, product := range products {
+		if active, ok := product["is_active"].(bool); ok && !active {
+			continue
+		}
+		categoryID, _ := product["category_id"].(float64)
+		productsByCategory[categoryID] = append(productsByCategory[categoryID], map[string]interface{}{
+			"id":          product["id"],
+			"name":        product["name"],
+			"description": product["description"],
+			"price":       product["price"],
+			"stock":       product["stock"],
+		})
+	}
+
+	menu := []map[string]interface{}{}
+	for _, category := range categories {
+		if active, ok := category["is_active"].(bool); ok && !active {
+			continue
+		}
+		categoryID, _ := category["id"].(float64)
+		menu = append(menu, map[string]interface{}{
+			"id":       category["id"],
+			"name":     category["name"],
+			"products": productsByCategory[categoryID],
+		})
+		delete(productsByCategory, categoryID)
+	}
+	if uncategorized := productsByCategory[0]; len(uncategorized) > 0 {
+		menu = append(menu, map[string]interface{}{
+			"id":       0,
+			"name":     "Sin categoría",
+			"products": uncategorized,
+		})
+	}
+
+	return map[string]interface{}{"categories": menu}, nil
+}
+
+// salesSummaryResource builds the sales summary of a date
+func (s *MCPServer) salesSummaryResource(date string) (interface{}, error) {
+	if s.deps.ReportsService == nil {
+		return nil, fmt.Errorf("reports service not available")
+	}
+	report, err := s.deps.ReportsService.GetDailySalesReport(date)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := map[string]interface{}{
+		"date":   date,
+		"report": report,
+	}
+	if date == time.Now().Format("2006-01-02") && s.deps.DashboardService != nil {
+		if stats, err := s.deps.DashboardService.GetDashboardStats(); err == nil {
+			summary["dashboard"] = stats
+		}
+		if register, err := s.deps.DashboardService.GetCashRegisterStatus(); err == nil {
+			summary["cash_register"] = register
+		}
+	}
+	return summary, nil
+}
+
+// openOrdersResource lists the orders that haven't been delivered, paid or cancelled
+func (s *MCPServer) openOrdersResource() (interface{}, error) {
+	if s.deps.OrderService == nil {
+		return nil, fmt.Errorf("order service not available")
+	}
+	orders := []map[string]interface{}{}
+	for _, status := range openOrderStatuses {
+		byStatus, err := s.deps.OrderService.GetOrdersByStatus(status)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, byStatus...)
+	}
+	return map[string]interface{}{
+		"count":  len(orders),
+		"orders": orders,
+	}, nil
+}
+
+// lowStockResource lists products and ingredients below their minimum stock
+func (s *MCPServer) lowStockResource() (interface{}, error) {
+	result := map[string]interface{}{
+		"products":    []map[string]interface{}{},
+		"ingredients": []map[string]interface{}{},
+	}
+	if s.deps.ProductService != nil {
+		products, err := s.deps.ProductService.GetLowStockProducts()
+		if err != nil {
+			return nil, err
+		}
+		if products != nil {
+			result["products"] = products
+		}
+	}
+	if s.deps.IngredientService != nil {
+		ingredients, err := s.deps.IngredientService.GetLowStockIngredients()
+		if err != nil {
+			return nil, err
+		}
+		if ingredients != nil {
+			result["ingredients"] = ingredients
+		}
+	}
+	return result, nil
+}
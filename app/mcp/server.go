@@ -1,11 +1,16 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -13,14 +18,21 @@ import (
 
 // MCPServer implements the MCP protocol with HTTP transport
 type MCPServer struct {
-	httpServer    *http.Server
-	port          int
-	apiKey        string
-	allowedIPs    []string
-	readOnlyMode  bool
-	disabledTools []string
-	isRunning     bool
-	mu            sync.RWMutex
+	httpServer     *http.Server
+	port           int
+	apiKey         string
+	allowedIPs     []string
+	allowedOrigins []string
+	readOnlyMode   bool
+	disabledTools  []string
+	isRunning      bool
+	mu             sync.RWMutex
+	limiter        *rateLimiter
+
+	// Streamable HTTP sessions
+	sessions   map[string]*mcpSession
+	sessionsMu sync.RWMutex
+	watchStop  chan struct{}
 
 	// Service dependencies
 	deps *ServiceDependencies
@@ -34,6 +46,12 @@ type ServiceDependencies struct {
 	IngredientService IngredientServiceInterface
 	DashboardService  DashboardServiceInterface
 	ReportsService    ReportsServiceInterface
+	ClientService     ClientServiceInterface
+	CashService       CashRegisterServiceInterface
+	TableService      TableServiceInterface
+	ComboService      ComboServiceInterface
+	PageService       CustomPageServiceInterface
+	DIANService       DIANServiceInterface
 }
 
 // ProductServiceInterface defines methods needed from ProductService
@@ -106,8 +124,61 @@ type ReportsServiceInterface interface {
 	GetInventoryReport() (map[string]interface{}, error)
 }
 
+// CashRegisterServiceInterface defines methods needed for cash register operations
+type CashRegisterServiceInterface interface {
+	GetOpenCashRegister() (map[string]interface{}, error)
+	OpenCashRegister(employeeID uint, openingAmount float64, notes string) (map[string]interface{}, error)
+	CloseCashRegister(registerID uint, closingAmount float64, notes string) (map[string]interface{}, error)
+	AddCashMovement(registerID uint, amount float64, movementType, description, reference string, employeeID uint) error
+	GetCashMovements(registerID uint) ([]map[string]interface{}, error)
+	GetCashRegisterHistory(limit, offset int) ([]map[string]interface{}, error)
+}
+
+// TableServiceInterface defines methods needed for tables and their occupancy
+type TableServiceInterface interface {
+	GetTables() ([]map[string]interface{}, error)
+	GetTable(id uint) (map[string]interface{}, error)
+	UpdateTableStatus(id uint, status string) error
+	GetTableAreas() ([]map[string]interface{}, error)
+}
+
+// ComboServiceInterface defines methods needed from ComboService
+type ComboServiceInterface interface {
+	GetAllCombos() ([]map[string]interface{}, error)
+	GetCombo(id uint) (map[string]interface{}, error)
+	CreateCombo(data map[string]interface{}) (map[string]interface{}, error)
+	UpdateCombo(id uint, data map[string]interface{}) (map[string]interface{}, error)
+	DeleteCombo(id uint) error
+	ToggleComboActive(id uint) (map[string]interface{}, error)
+}
+
+// CustomPageServiceInterface defines methods needed from CustomPageService
+type CustomPageServiceInterface interface {
+	GetAllPages() ([]map[string]interface{}, error)
+	GetPageProducts(pageID uint) ([]map[string]interface{}, error)
+	CreatePage(data map[string]interface{}) (map[string]interface{}, error)
+	UpdatePage(id uint, data map[string]interface{}) (map[string]interface{}, error)
+	DeletePage(id uint) error
+	SetPageProducts(pageID uint, productIDs []uint) error
+}
+
+// DIANServiceInterface defines methods needed for electronic invoice status and DIAN reports
+type DIANServiceInterface interface {
+	GetInvoiceStatus(saleID uint, refresh bool) (map[string]interface{}, error)
+	GetInvoicesByStatus(status, from, to string) ([]map[string]interface{}, error)
+	GetDIANClosingReport(date, period string) (map[string]interface{}, error)
+	GetDIANClosingReportRange(from, to string) (map[string]interface{}, error)
+}
+
+// ClientServiceInterface resolves the API keys of MCP clients and records their tool calls
+type ClientServiceInterface interface {
+	HasClients() bool
+	AuthenticateClient(apiKey string, ip string) (*ClientPermissions, error)
+	LogToolCall(entry ToolCallLog)
+}
+
 // NewMCPServer creates a new MCP server instance
-func NewMCPServer(port int, apiKey string, allowedIPs string, readOnlyMode bool, disabledTools string, deps *ServiceDependencies) *MCPServer {
+func NewMCPServer(port int, apiKey string, allowedIPs string, allowedOrigins string, readOnlyMode bool, disabledTools string, deps *ServiceDependencies) *MCPServer {
 	// Parse allowed IPs
 	var ips []string
 	if allowedIPs != "" {
@@ -116,6 +187,14 @@ func NewMCPServer(port int, apiKey string, allowedIPs string, readOnlyMode bool,
 		}
 	}
 
+	// Parse allowed browser origins
+	var origins []string
+	if allowedOrigins != "" {
+		for _, origin := range strings.Split(allowedOrigins, ",") {
+			origins = append(origins, strings.TrimRight(strings.TrimSpace(origin), "/"))
+		}
+	}
+
 	// Parse disabled tools
 	var disabled []string
 	if disabledTools != "" {
@@ -125,12 +204,15 @@ func NewMCPServer(port int, apiKey string, allowedIPs string, readOnlyMode bool,
 	}
 
 	s := &MCPServer{
-		port:          port,
-		apiKey:        apiKey,
-		allowedIPs:    ips,
-		readOnlyMode:  readOnlyMode,
-		disabledTools: disabled,
-		deps:          deps,
+		port:           port,
+		apiKey:         apiKey,
+		allowedIPs:     ips,
+		allowedOrigins: origins,
+		readOnlyMode:   readOnlyMode,
+		disabledTools:  disabled,
+		sessions:       make(map[string]*mcpSession),
+		limiter:        newRateLimiter(),
+		deps:           deps,
 	}
 
 	return s
@@ -160,6 +242,9 @@ func (s *MCPServer) Start() error {
 		}
 	}()
 
+	s.watchStop = make(chan struct{})
+	go s.watchSessions(s.watchStop)
+
 	s.isRunning = true
 	return nil
 }
@@ -173,6 +258,19 @@ func (s *MCPServer) Stop() error {
 		return nil
 	}
 
+	if s.watchStop != nil {
+		close(s.watchStop)
+		s.watchStop = nil
+	}
+
+	// Close the notification streams so Shutdown doesn't wait for them
+	s.sessionsMu.Lock()
+	for id, session := range s.sessions {
+		session.close()
+		delete(s.sessions, id)
+	}
+	s.sessionsMu.Unlock()
+
 	if s.httpServer != nil {
 		if err := s.httpServer.Shutdown(context.Background()); err != nil {
 			return err
@@ -205,22 +303,38 @@ func (s *MCPServer) createHTTPHandler() http.Handler {
 		})
 	})
 
-	// MCP SSE endpoint
+	// MCP Streamable HTTP endpoint
+	mux.HandleFunc("/mcp", s.handleStreamableHTTP)
+
+	// MCP SSE endpoint (legacy transport, POST and DELETE are Streamable HTTP)
 	mux.HandleFunc("/sse", s.handleSSE)
 
 	// MCP message endpoint (for JSON-RPC over HTTP POST)
 	mux.HandleFunc("/message", s.handleMessage)
 
 	// Wrap with middleware
-	return s.authMiddleware(s.corsMiddleware(mux))
+	// Origin is checked before anything else, and preflight requests carry no API key
+	return s.corsMiddleware(s.authMiddleware(mux))
 }
 
-// corsMiddleware adds CORS headers
+// corsMiddleware rejects browser requests from origins that aren't allowed and adds CORS headers
+// for the allowed ones. Checking Origin protects the local server against DNS rebinding: a page on
+// any domain can resolve to 127.0.0.1, but the browser still sends its real origin.
 func (s *MCPServer) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+		origin := r.Header.Get("Origin")
+		if origin != "" {
+			if !s.isOriginAllowed(origin) {
+				log.Printf("MCP: rejected request from origin %s", origin)
+				http.Error(w, "Forbidden origin", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Vary", "Origin")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Mcp-Session-Id, MCP-Protocol-Version, Last-Event-ID")
+			w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id")
+		}
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -231,7 +345,31 @@ func (s *MCPServer) corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// isOriginAllowed reports whether a browser origin may call the server
+// Requests without Origin come from MCP clients outside a browser and don't go through here
+func (s *MCPServer) isOriginAllowed(origin string) bool {
+	origin = strings.TrimRight(origin, "/")
+	for _, allowed := range s.allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	switch parsed.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	// Wails webview of the desktop app
+	return parsed.Scheme == "wails"
+}
+
 // authMiddleware handles API key and IP validation
+// Requests authenticate with the global API key or with the key of an MCP client;
+// authentication is required as soon as either is configured
 func (s *MCPServer) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Skip auth for health check
@@ -240,13 +378,28 @@ func (s *MCPServer) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		clientIP := getClientIP(r)
+		caller := &requestClient{ip: clientIP}
+
 		// Check API key if configured
-		if s.apiKey != "" {
-			apiKey := r.Header.Get("X-API-Key")
-			if apiKey == "" {
-				apiKey = r.URL.Query().Get("api_key")
-			}
-			if apiKey != s.apiKey {
+		hasClients := s.deps.ClientService != nil && s.deps.ClientService.HasClients()
+		if s.apiKey != "" || hasClients {
+			apiKey := requestAPIKey(r)
+			switch {
+			case apiKey == "":
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			case s.isGlobalAPIKey(apiKey):
+				// Global key: access limited only by the server settings
+			case hasClients:
+				client, err := s.deps.ClientService.AuthenticateClient(apiKey, clientIP)
+				if err != nil {
+					log.Printf("MCP: rejected API key from %s: %v", clientIP, err)
+					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					return
+				}
+				caller.client = client
+			default:
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				return
 			}
@@ -254,7 +407,6 @@ func (s *MCPServer) authMiddleware(next http.Handler) http.Handler {
 
 		// Check allowed IPs if configured
 		if len(s.allowedIPs) > 0 {
-			clientIP := getClientIP(r)
 			allowed := false
 			for _, ip := range s.allowedIPs {
 				if ip == clientIP || ip == "*" {
@@ -268,7 +420,7 @@ func (s *MCPServer) authMiddleware(next http.Handler) http.Handler {
 			}
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientContextKey{}, caller)))
 	})
 }
 
@@ -286,8 +438,8 @@ var (
 
 // handleSSE handles both SSE connections (GET) and Streamable HTTP transport (POST)
 func (s *MCPServer) handleSSE(w http.ResponseWriter, r *http.Request) {
-	// Handle POST for Streamable HTTP transport (used by mcp-remote with http-first strategy)
-	if r.Method == "POST" {
+	// Handle POST and DELETE for Streamable HTTP transport (used by mcp-remote with http-first strategy)
+	if r.Method == "POST" || r.Method == "DELETE" {
 		s.handleStreamableHTTP(w, r)
 		return
 	}
@@ -302,7 +454,6 @@ func (s *MCPServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -360,24 +511,161 @@ func (s *MCPServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleStreamableHTTP handles the Streamable HTTP transport for MCP
-// This is used by mcp-remote with http-first strategy
+// This is used by mcp-remote with http-first strategy and by current MCP clients:
+// POST carries JSON-RPC messages, GET opens the notification stream and DELETE ends the session
 func (s *MCPServer) handleStreamableHTTP(w http.ResponseWriter, r *http.Request) {
-	var request map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	switch r.Method {
+	case "POST":
+		s.handleStreamablePost(w, r)
+	case "GET":
+		s.handleStreamableStream(w, r)
+	case "DELETE":
+		sessionID := r.Header.Get(sessionHeader)
+		if sessionID == "" {
+			http.Error(w, "Missing Mcp-Session-Id header", http.StatusBadRequest)
+			return
+		}
+		if !s.deleteSession(sessionID, clientFromContext(r.Context()).clientID()) {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleStreamablePost processes a JSON-RPC message or batch sent with POST
+func (s *MCPServer) handleStreamablePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	log.Printf("MCP Streamable HTTP request: %v", request)
+	var messages []map[string]interface{}
+	body = bytes.TrimSpace(body)
+	batch := len(body) > 0 && body[0] == '['
+	if batch {
+		err = json.Unmarshal(body, &messages)
+	} else {
+		var message map[string]interface{}
+		err = json.Unmarshal(body, &message)
+		messages = []map[string]interface{}{message}
+	}
+	if err != nil || len(messages) == 0 || messages[0] == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(rpcError(nil, -32700, "Parse error"))
+		return
+	}
 
-	// Handle the MCP request
-	response := s.handleMCPRequest(r.Context(), request)
+	// initialize opens a new session, every other message must belong to one
+	if !batch && messages[0]["method"] == "initialize" {
+		log.Printf("MCP Streamable HTTP request: initialize")
+		response := s.handleMCPRequest(r.Context(), messages[0])
+		if result, ok := response["result"].(map[string]interface{}); ok {
+			params, _ := messages[0]["params"].(map[string]interface{})
+			clientInfo, _ := params["clientInfo"].(map[string]interface{})
+			version, _ := result["protocolVersion"].(string)
+			session := s.newSession(version, clientInfo, clientFromContext(r.Context()).clientID())
+			w.Header().Set(sessionHeader, session.id)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
 
-	log.Printf("MCP Streamable HTTP response: %v", response)
+	sessionID := r.Header.Get(sessionHeader)
+	if sessionID == "" {
+		http.Error(w, "Missing Mcp-Session-Id header", http.StatusBadRequest)
+		return
+	}
+	session := s.getSession(sessionID, clientFromContext(r.Context()).clientID())
+	if session == nil {
+		// The client must initialize again
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if version := r.Header.Get(protocolVersionHeader); version != "" && negotiateProtocolVersion(version) != version {
+		http.Error(w, "Unsupported MCP-Protocol-Version", http.StatusBadRequest)
+		return
+	}
+	ctx := context.WithValue(r.Context(), sessionContextKey{}, session)
+
+	var responses []map[string]interface{}
+	for _, message := range messages {
+		log.Printf("MCP Streamable HTTP request: %v (session %s)", message["method"], session.id)
+		if response := s.handleMCPRequest(ctx, message); response != nil {
+			responses = append(responses, response)
+		}
+	}
+
+	// Only notifications or responses: nothing to answer
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 
-	// Send response as JSON
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	if batch {
+		json.NewEncoder(w).Encode(responses)
+	} else {
+		json.NewEncoder(w).Encode(responses[0])
+	}
+}
+
+// handleStreamableStream opens the SSE stream a session receives server notifications on
+func (s *MCPServer) handleStreamableStream(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sessionID := r.Header.Get(sessionHeader)
+	if sessionID == "" {
+		http.Error(w, "Missing Mcp-Session-Id header", http.StatusBadRequest)
+		return
+	}
+	session := s.getSession(sessionID, clientFromContext(r.Context()).clientID())
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	log.Printf("MCP notification stream opened for session %s", session.id)
+
+	keepAlive := time.NewTicker(sessionKeepAlivePeriod)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case msg := <-session.notifications:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", string(msg))
+			flusher.Flush()
+		case <-keepAlive.C:
+			// An open stream keeps the session alive
+			s.getSession(session.id, session.clientID)
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			log.Printf("MCP notification stream closed for session %s", session.id)
+			return
+		case <-session.done:
+			return
+		}
+	}
 }
 
 // handleMessage handles JSON-RPC messages
@@ -398,6 +686,11 @@ func (s *MCPServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 
 	// Handle the MCP request
 	response := s.handleMCPRequest(r.Context(), request)
+	if response == nil {
+		// Notifications don't get a response
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 
 	// If we have a session ID, send response via SSE
 	if sessionID != "" {
@@ -422,39 +715,76 @@ func (s *MCPServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleMCPRequest processes MCP JSON-RPC requests
+// Returns nil for notifications and client responses, which don't get a response
 func (s *MCPServer) handleMCPRequest(ctx context.Context, request map[string]interface{}) map[string]interface{} {
 	method, _ := request["method"].(string)
-	id := request["id"]
+	id, hasID := request["id"]
 	params, _ := request["params"].(map[string]interface{})
 
+	if !hasID || method == "" {
+		s.handleNotification(ctx, method)
+		return nil
+	}
+
 	switch method {
 	case "initialize":
-		return s.handleInitialize(id)
+		return s.handleInitialize(id, params)
+	case "ping":
+		return rpcResult(id, map[string]interface{}{})
 	case "tools/list":
-		return s.handleToolsList(id)
+		return s.handleToolsList(ctx, id)
 	case "tools/call":
 		return s.handleToolCall(ctx, id, params)
+	case "resources/list":
+		return rpcResult(id, map[string]interface{}{"resources": s.filterDefinitions(ctx, getResourceDefinitions(), "uri")})
+	case "resources/templates/list":
+		return rpcResult(id, map[string]interface{}{"resourceTemplates": s.filterDefinitions(ctx, getResourceTemplates(), "uriTemplate")})
+	case "resources/read":
+		return s.handleResourceRead(ctx, id, params)
+	case "resources/subscribe", "resources/unsubscribe":
+		return s.handleResourceSubscription(ctx, id, method, params)
+	case "prompts/list":
+		return rpcResult(id, map[string]interface{}{"prompts": s.filterDefinitions(ctx, getPromptDefinitions(), "name")})
+	case "prompts/get":
+		return s.handlePromptGet(ctx, id, params)
 	default:
-		return map[string]interface{}{
-			"jsonrpc": "2.0",
-			"id":      id,
-			"error": map[string]interface{}{
-				"code":    -32601,
-				"message": "Method not found",
-			},
+		return rpcError(id, -32601, "Method not found")
+	}
+}
+
+// handleNotification handles notifications sent by the client
+func (s *MCPServer) handleNotification(ctx context.Context, method string) {
+	session, _ := ctx.Value(sessionContextKey{}).(*mcpSession)
+	switch method {
+	case "notifications/initialized":
+		if session != nil {
+			session.mu.Lock()
+			session.initialized = true
+			session.mu.Unlock()
 		}
+	case "notifications/cancelled":
+		// Requests are answered synchronously, there is nothing to cancel
 	}
 }
 
 // handleInitialize handles the initialize request
-func (s *MCPServer) handleInitialize(id interface{}) map[string]interface{} {
+func (s *MCPServer) handleInitialize(id interface{}, params map[string]interface{}) map[string]interface{} {
+	requestedVersion, _ := params["protocolVersion"].(string)
+
 	return map[string]interface{}{
 		"jsonrpc": "2.0",
 		"id":      id,
 		"result": map[string]interface{}{
-			"protocolVersion": "2024-11-05",
+			"protocolVersion": negotiateProtocolVersion(requestedVersion),
 			"capabilities": map[string]interface{}{
 				"tools": map[string]interface{}{},
+				"resources": map[string]interface{}{
+					"subscribe":   true,
+					"listChanged": false,
+				},
+				"prompts": map[string]interface{}{
+					"listChanged": false,
+				},
 			},
 			"serverInfo": map[string]interface{}{
 				"name":    "POS-MCP-Server",
@@ -464,6 +794,129 @@ func (s *MCPServer) handleInitialize(id interface{}) map[string]interface{} {
 	}
 }
 
+// filterDefinitions keeps the resources, resource templates or prompts the client has access to
+func (s *MCPServer) filterDefinitions(ctx context.Context, definitions []map[string]interface{}, key string) []map[string]interface{} {
+	client := clientFromContext(ctx).client
+	allowed := []map[string]interface{}{}
+	for _, definition := range definitions {
+		value, _ := definition[key].(string)
+		if key == "name" && s.canGetPrompt(client, value) || key != "name" && s.canReadResource(client, value) {
+			allowed = append(allowed, definition)
+		}
+	}
+	return allowed
+}
+
+// handleResourceRead handles the resources/read request
+func (s *MCPServer) handleResourceRead(ctx context.Context, id interface{}, params map[string]interface{}) map[string]interface{} {
+	uri, _ := params["uri"].(string)
+	if uri == "" {
+		return rpcError(id, -32602, "uri is required")
+	}
+	client := clientFromContext(ctx).client
+	if !s.canReadResource(client, uri) {
+		return rpcError(id, -32002, fmt.Sprintf("%s: %s", errResourceNotFound, uri))
+	}
+	if !s.limiter.allow(client) {
+		return rpcError(id, -32000, "Rate limit exceeded")
+	}
+
+	text, err := s.readResource(uri)
+	if errors.Is(err, errResourceNotFound) {
+		return rpcError(id, -32002, err.Error())
+	}
+	if err != nil {
+		return rpcError(id, -32603, err.Error())
+	}
+
+	return rpcResult(id, map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{
+				"uri":      uri,
+				"mimeType": "application/json",
+				"text":     text,
+			},
+		},
+	})
+}
+
+// handleResourceSubscription handles resources/subscribe and resources/unsubscribe
+// Updates are delivered as notifications on the session's GET stream
+func (s *MCPServer) handleResourceSubscription(ctx context.Context, id interface{}, method string, params map[string]interface{}) map[string]interface{} {
+	session, _ := ctx.Value(sessionContextKey{}).(*mcpSession)
+	if session == nil {
+		return rpcError(id, -32600, "Resource subscriptions require a Streamable HTTP session")
+	}
+	uri, _ := params["uri"].(string)
+	if uri == "" {
+		return rpcError(id, -32602, "uri is required")
+	}
+
+	if method == "resources/unsubscribe" {
+		session.mu.Lock()
+		delete(session.subscriptions, uri)
+		session.mu.Unlock()
+		return rpcResult(id, map[string]interface{}{})
+	}
+
+	if !s.canReadResource(clientFromContext(ctx).client, uri) {
+		return rpcError(id, -32002, fmt.Sprintf("%s: %s", errResourceNotFound, uri))
+	}
+	if _, err := s.resourceData(uri); errors.Is(err, errResourceNotFound) {
+		return rpcError(id, -32002, err.Error())
+	}
+	hash := s.resourceHash(uri)
+	session.mu.Lock()
+	session.subscriptions[uri] = hash
+	session.mu.Unlock()
+	return rpcResult(id, map[string]interface{}{})
+}
+
+// handlePromptGet handles the prompts/get request
+func (s *MCPServer) handlePromptGet(ctx context.Context, id interface{}, params map[string]interface{}) map[string]interface{} {
+	name, _ := params["name"].(string)
+	client := clientFromContext(ctx).client
+	if !s.canGetPrompt(client, name) {
+		return rpcError(id, -32602, fmt.Sprintf("prompt not found: %s", name))
+	}
+	if !s.limiter.allow(client) {
+		return rpcError(id, -32000, "Rate limit exceeded")
+	}
+	args := make(map[string]string)
+	if rawArgs, ok := params["arguments"].(map[string]interface{}); ok {
+		for key, value := range rawArgs {
+			args[key] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	result, err := s.getPrompt(name, args, client)
+	if err != nil {
+		return rpcError(id, -32602, err.Error())
+	}
+	return rpcResult(id, result)
+}
+
+// rpcResult builds a JSON-RPC success response
+func rpcResult(id interface{}, result interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result":  result,
+	}
+}
+
+// rpcError builds a JSON-RPC error response
+func rpcError(id interface{}, code int, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	}
+}
+
 // isToolDisabled checks if a tool is disabled
 func (s *MCPServer) isToolDisabled(toolName string) bool {
 	for _, disabled := range s.disabledTools {
@@ -475,14 +928,15 @@ func (s *MCPServer) isToolDisabled(toolName string) bool {
 }
 
 // handleToolsList handles the tools/list request
-func (s *MCPServer) handleToolsList(id interface{}) map[string]interface{} {
+func (s *MCPServer) handleToolsList(ctx context.Context, id interface{}) map[string]interface{} {
 	allTools := s.getToolDefinitions()
+	client := clientFromContext(ctx).client
 
-	// Filter out disabled tools
+	// Filter out disabled tools and the tools the client can't call
 	var enabledTools []map[string]interface{}
 	for _, tool := range allTools {
 		if name, ok := tool["name"].(string); ok {
-			if !s.isToolDisabled(name) {
+			if s.canUseTool(client, name) && (client == nil || client.WriteAccess || !isWriteOperation(name)) {
 				enabledTools = append(enabledTools, tool)
 			}
 		}
@@ -498,61 +952,31 @@ func (s *MCPServer) handleToolsList(id interface{}) map[string]interface{} {
 }
 
 // handleToolCall handles tool execution
+// Every call, allowed or not, is recorded in the tool call log
 func (s *MCPServer) handleToolCall(ctx context.Context, id interface{}, params map[string]interface{}) map[string]interface{} {
 	toolName, _ := params["name"].(string)
 	toolArgs, _ := params["arguments"].(map[string]interface{})
+	client := clientFromContext(ctx).client
+	start := time.Now()
 
-	// Check if tool is disabled
-	if s.isToolDisabled(toolName) {
-		return map[string]interface{}{
-			"jsonrpc": "2.0",
-			"id":      id,
-			"result": map[string]interface{}{
-				"content": []map[string]interface{}{
-					{
-						"type": "text",
-						"text": fmt.Sprintf("Error: Tool '%s' is disabled.", toolName),
-					},
-				},
-				"isError": true,
-			},
-		}
+	// Check disabled tools, read-only mode and the client's permissions
+	if reason := s.toolAccessError(client, toolName); reason != "" {
+		s.logToolCall(ctx, toolName, toolArgs, toolCallDenied, reason, time.Since(start))
+		return toolErrorResult(id, fmt.Sprintf("Error: %s", reason))
 	}
 
-	// Check read-only mode for write operations
-	if s.readOnlyMode && isWriteOperation(toolName) {
-		return map[string]interface{}{
-			"jsonrpc": "2.0",
-			"id":      id,
-			"result": map[string]interface{}{
-				"content": []map[string]interface{}{
-					{
-						"type": "text",
-						"text": "Error: Server is in read-only mode. Write operations are not allowed.",
-					},
-				},
-				"isError": true,
-			},
-		}
+	if !s.limiter.allow(client) {
+		s.logToolCall(ctx, toolName, toolArgs, toolCallRateLimited, "rate limit exceeded", time.Since(start))
+		return toolErrorResult(id, fmt.Sprintf("Error: Rate limit exceeded (%d calls per minute). Try again later.", client.RateLimitPerMinute))
 	}
 
 	// Execute the tool
 	result, err := s.executeTool(ctx, toolName, toolArgs)
 	if err != nil {
-		return map[string]interface{}{
-			"jsonrpc": "2.0",
-			"id":      id,
-			"result": map[string]interface{}{
-				"content": []map[string]interface{}{
-					{
-						"type": "text",
-						"text": fmt.Sprintf("Error: %s", err.Error()),
-					},
-				},
-				"isError": true,
-			},
-		}
+		s.logToolCall(ctx, toolName, toolArgs, toolCallError, err.Error(), time.Since(start))
+		return toolErrorResult(id, fmt.Sprintf("Error: %s", err.Error()))
 	}
+	s.logToolCall(ctx, toolName, toolArgs, toolCallSuccess, "", time.Since(start))
 
 	// Convert result to JSON string
 	resultJSON, _ := json.MarshalIndent(result, "", "  ")
@@ -571,11 +995,29 @@ func (s *MCPServer) handleToolCall(ctx context.Context, id interface{}, params m
 	}
 }
 
+// toolErrorResult builds a tool result that reports an error to the model
+func toolErrorResult(id interface{}, text string) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result": map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": text,
+				},
+			},
+			"isError": true,
+		},
+	}
+}
+
 // isWriteOperation checks if a tool performs write operations
 func isWriteOperation(toolName string) bool {
 	writeOps := []string{
 		"create_", "update_", "delete_", "adjust_",
 		"add_", "remove_", "send_", "mark_", "refund_",
+		"open_", "close_", "record_", "set_", "toggle_",
 	}
 	for _, prefix := range writeOps {
 		if strings.HasPrefix(toolName, prefix) {
@@ -586,26 +1028,27 @@ func isWriteOperation(toolName string) bool {
 }
 
 // getClientIP extracts client IP from request
+// Forwarding headers are only honored when the request comes from a local proxy or
+// tunnel; a client connecting directly could set them to anything.
 func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
-	xff := r.Header.Get("X-Forwarded-For")
-	if xff != "" {
-		ips := strings.Split(xff, ",")
-		return strings.TrimSpace(ips[0])
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
 	}
-
-	// Check X-Real-IP header
-	xri := r.Header.Get("X-Real-IP")
-	if xri != "" {
-		return xri
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return host
 	}
 
-	// Fall back to RemoteAddr
-	ip := r.RemoteAddr
-	if idx := strings.LastIndex(ip, ":"); idx != -1 {
-		ip = ip[:idx]
+	if ip := strings.TrimSpace(r.Header.Get("CF-Connecting-IP")); ip != "" {
+		return ip
+	}
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		return strings.TrimSpace(strings.Split(xff, ",")[0])
 	}
-	return ip
+	if xri := strings.TrimSpace(r.Header.Get("X-Real-IP")); xri != "" {
+		return xri
+	}
+	return host
 }
 
 // getToolDefinitions returns all tool definitions for the MCP protocol
@@ -620,6 +1063,11 @@ func (s *MCPServer) getToolDefinitions() []map[string]interface{} {
 	tools = append(tools, getSalesTools()...)
 	tools = append(tools, getOrderTools()...)
 	tools = append(tools, getReportTools()...)
+	tools = append(tools, getCashRegisterTools()...)
+	tools = append(tools, getTableTools()...)
+	tools = append(tools, getComboTools()...)
+	tools = append(tools, getCustomPageTools()...)
+	tools = append(tools, getDIANTools()...)
 
 	return tools
 }
@@ -672,6 +1120,34 @@ func (s *MCPServer) executeTool(ctx context.Context, name string, args map[strin
 		strings.HasPrefix(name, "get_inventory_report"):
 		return executeReportTool(s.deps.DashboardService, s.deps.ReportsService, name, args)
 
+	// Cash register tools
+	case strings.HasPrefix(name, "get_open_cash_register"), strings.HasPrefix(name, "open_cash_register"),
+		strings.HasPrefix(name, "close_cash_register"), strings.HasPrefix(name, "record_cash_movement"),
+		strings.HasPrefix(name, "list_cash_"):
+		return executeCashRegisterTool(s.deps.CashService, name, args)
+
+	// Table tools
+	case strings.HasPrefix(name, "list_table"), strings.HasPrefix(name, "get_table"),
+		strings.HasPrefix(name, "set_table_status"):
+		return executeTableTool(s.deps.TableService, name, args)
+
+	// Combo tools
+	case strings.HasPrefix(name, "list_combos"), strings.HasPrefix(name, "get_combo"),
+		strings.HasPrefix(name, "create_combo"), strings.HasPrefix(name, "update_combo"),
+		strings.HasPrefix(name, "delete_combo"), strings.HasPrefix(name, "toggle_combo"):
+		return executeComboTool(s.deps.ComboService, name, args)
+
+	// Custom page tools
+	case strings.HasPrefix(name, "list_custom_pages"), strings.HasPrefix(name, "get_custom_page"),
+		strings.HasPrefix(name, "create_custom_page"), strings.HasPrefix(name, "update_custom_page"),
+		strings.HasPrefix(name, "delete_custom_page"), strings.HasPrefix(name, "set_custom_page"):
+		return executeCustomPageTool(s.deps.PageService, name, args)
+
+	// DIAN tools
+	case strings.HasPrefix(name, "get_invoice_status"), strings.HasPrefix(name, "list_electronic_invoices"),
+		strings.HasPrefix(name, "get_dian"):
+		return executeDIANTool(s.deps.DIANService, name, args)
+
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", name)
 	}
@@ -0,0 +1,214 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// sessionHeader carries the streamable HTTP session ID
+	sessionHeader = "Mcp-Session-Id"
+	// protocolVersionHeader carries the negotiated protocol version on requests after initialize
+	protocolVersionHeader = "MCP-Protocol-Version"
+
+	sessionIdleTimeout     = 30 * time.Minute
+	resourceWatchInterval  = 20 * time.Second
+	sessionKeepAlivePeriod = 25 * time.Second
+)
+
+// supportedProtocolVersions lists the MCP revisions the server speaks, newest first
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// mcpSession is a streamable HTTP session created by initialize
+type mcpSession struct {
+	id              string
+	clientID        uint // MCP client that created the session (0 = global API key)
+	protocolVersion string
+	clientInfo      map[string]interface{}
+	initialized     bool
+	createdAt       time.Time
+	lastSeen        time.Time
+
+	// subscriptions maps subscribed resource URIs to the hash of their last content
+	subscriptions map[string]string
+	// notifications are delivered through the GET event stream
+	notifications chan []byte
+	done          chan struct{}
+	closeOnce     sync.Once
+	mu            sync.Mutex
+}
+
+type sessionContextKey struct{}
+
+// newSession creates and registers a session
+func (s *MCPServer) newSession(protocolVersion string, clientInfo map[string]interface{}, clientID uint) *mcpSession {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+
+	session := &mcpSession{
+		id:              hex.EncodeToString(buf),
+		clientID:        clientID,
+		protocolVersion: protocolVersion,
+		clientInfo:      clientInfo,
+		createdAt:       time.Now(),
+		lastSeen:        time.Now(),
+		subscriptions:   make(map[string]string),
+		notifications:   make(chan []byte, 100),
+		done:            make(chan struct{}),
+	}
+
+	s.sessionsMu.Lock()
+	s.sessions[session.id] = session
+	s.sessionsMu.Unlock()
+
+	log.Printf("MCP session created: %s (protocol %s)", session.id, protocolVersion)
+	return session
+}
+
+// getSession returns an active session of the client and refreshes its last activity
+func (s *MCPServer) getSession(id string, clientID uint) *mcpSession {
+	s.sessionsMu.RLock()
+	session, exists := s.sessions[id]
+	s.sessionsMu.RUnlock()
+	if !exists || session.clientID != clientID {
+		return nil
+	}
+	session.mu.Lock()
+	session.lastSeen = time.Now()
+	session.mu.Unlock()
+	return session
+}
+
+// deleteSession terminates a session of the client
+func (s *MCPServer) deleteSession(id string, clientID uint) bool {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	session, exists := s.sessions[id]
+	if !exists || session.clientID != clientID {
+		return false
+	}
+	session.close()
+	delete(s.sessions, id)
+	log.Printf("MCP session terminated: %s", id)
+	return true
+}
+
+// close ends the session's notification streams
+func (session *mcpSession) close() {
+	session.closeOnce.Do(func() { close(session.done) })
+}
+
+// notify queues a JSON-RPC notification for the session's event stream
+func (session *mcpSession) notify(method string, params interface{}) {
+	message, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	})
+	select {
+	case session.notifications <- message:
+	default:
+		log.Printf("MCP: notification %s dropped for session %s (queue full)", method, session.id)
+	}
+}
+
+// negotiateProtocolVersion returns the requested version if supported, otherwise the latest one
+func negotiateProtocolVersion(requested string) string {
+	for _, version := range supportedProtocolVersions {
+		if version == requested {
+			return version
+		}
+	}
+	return supportedProtocolVersions[0]
+}
+
+// watchSessions expires idle sessions and notifies subscribers when a resource changes
+func (s *MCPServer) watchSessions(stop chan struct{}) {
+	ticker := time.NewTicker(resourceWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.expireSessions()
+			s.checkSubscriptions()
+		}
+	}
+}
+
+// expireSessions closes the sessions idle for longer than sessionIdleTimeout
+func (s *MCPServer) expireSessions() {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	for id, session := range s.sessions {
+		session.mu.Lock()
+		idle := time.Since(session.lastSeen) > sessionIdleTimeout
+		session.mu.Unlock()
+		if idle {
+			session.close()
+			delete(s.sessions, id)
+			log.Printf("MCP session expired: %s", id)
+		}
+	}
+}
+
+// checkSubscriptions reads each subscribed resource once and sends
+// notifications/resources/updated to the sessions whose copy is stale
+func (s *MCPServer) checkSubscriptions() {
+	s.sessionsMu.RLock()
+	sessions := make([]*mcpSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.sessionsMu.RUnlock()
+
+	hashes := make(map[string]string)
+	for _, session := range sessions {
+		session.mu.Lock()
+		uris := make([]string, 0, len(session.subscriptions))
+		for uri := range session.subscriptions {
+			uris = append(uris, uri)
+		}
+		session.mu.Unlock()
+
+		for _, uri := range uris {
+			hash, cached := hashes[uri]
+			if !cached {
+				hash = s.resourceHash(uri)
+				hashes[uri] = hash
+			}
+			if hash == "" {
+				continue
+			}
+
+			session.mu.Lock()
+			previous, subscribed := session.subscriptions[uri]
+			changed := subscribed && previous != hash
+			if changed {
+				session.subscriptions[uri] = hash
+			}
+			session.mu.Unlock()
+
+			if changed {
+				session.notify("notifications/resources/updated", map[string]interface{}{"uri": uri})
+			}
+		}
+	}
+}
+
+// resourceHash fingerprints the current content of a resource ("" if it can't be read)
+func (s *MCPServer) resourceHash(uri string) string {
+	text, err := s.readResource(uri)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
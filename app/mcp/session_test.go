@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionsAreBoundToTheirClient(t *testing.T) {
+	s := NewMCPServer(0, "", "", "", false, "", &ServiceDependencies{})
+	session := s.newSession(supportedProtocolVersions[0], nil, 7)
+
+	if s.getSession(session.id, 7) != session {
+		t.Fatal("owner could not resume its session")
+	}
+	if s.getSession(session.id, 8) != nil {
+		t.Error("another client resumed the session")
+	}
+	if s.getSession(session.id, 0) != nil {
+		t.Error("the global key resumed a client session")
+	}
+	if s.deleteSession(session.id, 8) {
+		t.Error("another client deleted the session")
+	}
+	if !s.deleteSession(session.id, 7) {
+		t.Fatal("owner could not delete its session")
+	}
+	if s.getSession(session.id, 7) != nil {
+		t.Error("deleted session is still active")
+	}
+}
+
+func TestExpireSessions(t *testing.T) {
+	s := NewMCPServer(0, "", "", "", false, "", &ServiceDependencies{})
+	idle := s.newSession(supportedProtocolVersions[0], nil, 0)
+	active := s.newSession(supportedProtocolVersions[0], nil, 0)
+	idle.lastSeen = time.Now().Add(-sessionIdleTimeout - time.Minute)
+
+	s.expireSessions()
+
+	if s.getSession(idle.id, 0) != nil {
+		t.Error("idle session was not expired")
+	}
+	if s.getSession(active.id, 0) == nil {
+		t.Error("active session was expired")
+	}
+}
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	for _, version := range supportedProtocolVersions {
+		if got := negotiateProtocolVersion(version); got != version {
+			t.Errorf("negotiateProtocolVersion(%q) = %q", version, got)
+		}
+	}
+	if got := negotiateProtocolVersion("1999-01-01"); got != supportedProtocolVersions[0] {
+		t.Errorf("unsupported version negotiated %q, want %q", got, supportedProtocolVersions[0])
+	}
+}
@@ -0,0 +1,229 @@
+package mcp
+
+import (
+	"fmt"
+)
+
+// getCashRegisterTools returns tool definitions for cash register operations
+func getCashRegisterTools() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"name":        "get_open_cash_register",
+			"description": "Get the currently open cash register with its opening amount, manual movements, expected cash and sales summary by payment method",
+			"inputSchema": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			"name":        "open_cash_register",
+			"description": "Open a cash register session for an employee with the initial cash in the drawer",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"employee_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "ID of the employee opening the register",
+					},
+					"opening_amount": map[string]interface{}{
+						"type":        "number",
+						"description": "Cash in the drawer when opening",
+					},
+					"notes": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional notes",
+					},
+				},
+				"required": []string{"employee_id", "opening_amount"},
+			},
+		},
+		{
+			"name":        "close_cash_register",
+			"description": "Close a cash register session with the counted cash and return the closing report with the difference against the expected amount",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"register_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "ID of the cash register (defaults to the currently open one)",
+					},
+					"closing_amount": map[string]interface{}{
+						"type":        "number",
+						"description": "Cash counted in the drawer",
+					},
+					"notes": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional notes",
+					},
+				},
+				"required": []string{"closing_amount"},
+			},
+		},
+		{
+			"name":        "record_cash_movement",
+			"description": "Record a manual cash movement in an open register: a deposit, a withdrawal or an adjustment",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"register_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "ID of the cash register (defaults to the currently open one)",
+					},
+					"type": map[string]interface{}{
+						"type":        "string",
+						"description": "Movement type",
+						"enum":        []string{"deposit", "withdrawal", "adjustment"},
+					},
+					"amount": map[string]interface{}{
+						"type":        "number",
+						"description": "Amount of the movement (always positive, withdrawals are subtracted)",
+					},
+					"description": map[string]interface{}{
+						"type":        "string",
+						"description": "Reason for the movement",
+					},
+					"reference": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional reference (receipt number, supplier, etc.)",
+					},
+					"employee_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "Employee recording the movement (defaults to the register's employee)",
+					},
+				},
+				"required": []string{"type", "amount", "description"},
+			},
+		},
+		{
+			"name":        "list_cash_movements",
+			"description": "List the movements of a cash register",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"register_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "ID of the cash register (defaults to the currently open one)",
+					},
+				},
+			},
+		},
+		{
+			"name":        "list_cash_register_history",
+			"description": "List previous cash register sessions, newest first",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of sessions (default: 20)",
+					},
+					"offset": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of sessions to skip",
+					},
+				},
+			},
+		},
+	}
+}
+
+// executeCashRegisterTool executes a cash register tool
+func executeCashRegisterTool(svc CashRegisterServiceInterface, name string, args map[string]interface{}) (interface{}, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("cash register service not available")
+	}
+
+	// registerID returns the register_id argument or the currently open register
+	registerID := func() (uint, uint, error) {
+		if id, ok := args["register_id"].(float64); ok && id > 0 {
+			return uint(id), 0, nil
+		}
+		register, err := svc.GetOpenCashRegister()
+		if err != nil {
+			return 0, 0, err
+		}
+		id, _ := register["id"].(float64)
+		employeeID, _ := register["employee_id"].(float64)
+		return uint(id), uint(employeeID), nil
+	}
+
+	switch name {
+	case "get_open_cash_register":
+		return svc.GetOpenCashRegister()
+
+	case "open_cash_register":
+		employeeID, ok := args["employee_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("employee_id is required")
+		}
+		openingAmount, ok := args["opening_amount"].(float64)
+		if !ok || openingAmount < 0 {
+			return nil, fmt.Errorf("opening_amount is required and can't be negative")
+		}
+		notes, _ := args["notes"].(string)
+		return svc.OpenCashRegister(uint(employeeID), openingAmount, notes)
+
+	case "close_cash_register":
+		closingAmount, ok := args["closing_amount"].(float64)
+		if !ok || closingAmount < 0 {
+			return nil, fmt.Errorf("closing_amount is required and can't be negative")
+		}
+		id, _, err := registerID()
+		if err != nil {
+			return nil, err
+		}
+		notes, _ := args["notes"].(string)
+		return svc.CloseCashRegister(id, closingAmount, notes)
+
+	case "record_cash_movement":
+		movementType, _ := args["type"].(string)
+		if movementType != "deposit" && movementType != "withdrawal" && movementType != "adjustment" {
+			return nil, fmt.Errorf("type must be deposit, withdrawal or adjustment")
+		}
+		amount, ok := args["amount"].(float64)
+		if !ok || amount <= 0 {
+			return nil, fmt.Errorf("amount is required and must be positive")
+		}
+		description, _ := args["description"].(string)
+		if description == "" {
+			return nil, fmt.Errorf("description is required")
+		}
+		reference, _ := args["reference"].(string)
+		id, registerEmployeeID, err := registerID()
+		if err != nil {
+			return nil, err
+		}
+		employeeID := registerEmployeeID
+		if e, ok := args["employee_id"].(float64); ok && e > 0 {
+			employeeID = uint(e)
+		}
+		if err := svc.AddCashMovement(id, amount, movementType, description, reference, employeeID); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("Cash %s of %.2f recorded in register %d", movementType, amount, id),
+		}, nil
+
+	case "list_cash_movements":
+		id, _, err := registerID()
+		if err != nil {
+			return nil, err
+		}
+		return svc.GetCashMovements(id)
+
+	case "list_cash_register_history":
+		limit := 20
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = int(l)
+		}
+		offset := 0
+		if o, ok := args["offset"].(float64); ok && o > 0 {
+			offset = int(o)
+		}
+		return svc.GetCashRegisterHistory(limit, offset)
+
+	default:
+		return nil, fmt.Errorf("unknown cash register tool: %s", name)
+	}
+}
@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// fakeCashService records the calls the cash register tools make
+type fakeCashService struct {
+	open  map[string]interface{} // nil = no open register
+	calls []string
+}
+
+func (f *fakeCashService) GetOpenCashRegister() (map[string]interface{}, error) {
+	if f.open == nil {
+		return nil, errors.New("no hay caja abierta")
+	}
+	return f.open, nil
+}
+
+func (f *fakeCashService) OpenCashRegister(employeeID uint, openingAmount float64, notes string) (map[string]interface{}, error) {
+	f.calls = append(f.calls, fmt.Sprintf("open %d %.0f", employeeID, openingAmount))
+	return map[string]interface{}{"id": float64(1)}, nil
+}
+
+func (f *fakeCashService) CloseCashRegister(registerID uint, closingAmount float64, notes string) (map[string]interface{}, error) {
+	f.calls = append(f.calls, fmt.Sprintf("close %d %.0f", registerID, closingAmount))
+	return map[string]interface{}{"id": float64(registerID)}, nil
+}
+
+func (f *fakeCashService) AddCashMovement(registerID uint, amount float64, movementType, description, reference string, employeeID uint) error {
+	f.calls = append(f.calls, fmt.Sprintf("movement %d %s %.0f by %d", registerID, movementType, amount, employeeID))
+	return nil
+}
+
+func (f *fakeCashService) GetCashMovements(registerID uint) ([]map[string]interface{}, error) {
+	f.calls = append(f.calls, fmt.Sprintf("movements %d", registerID))
+	return nil, nil
+}
+
+func (f *fakeCashService) GetCashRegisterHistory(limit, offset int) ([]map[string]interface{}, error) {
+	f.calls = append(f.calls, fmt.Sprintf("history %d %d", limit, offset))
+	return nil, nil
+}
+
+func TestExecuteCashRegisterTool(t *testing.T) {
+	openRegister := map[string]interface{}{"id": float64(5), "employee_id": float64(3)}
+
+	tests := []struct {
+		name     string
+		tool     string
+		args     map[string]interface{}
+		open     map[string]interface{}
+		wantErr  bool
+		wantCall string
+	}{
+		{"open register", "open_cash_register", map[string]interface{}{"employee_id": float64(3), "opening_amount": float64(100000)},
+			nil, false, "open 3 100000"},
+		{"open without employee", "open_cash_register", map[string]interface{}{"opening_amount": float64(100000)}, nil, true, ""},
+		{"open with negative amount", "open_cash_register", map[string]interface{}{"employee_id": float64(3), "opening_amount": float64(-1)},
+			nil, true, ""},
+		{"close the open register", "close_cash_register", map[string]interface{}{"closing_amount": float64(250000)},
+			openRegister, false, "close 5 250000"},
+		{"close a given register", "close_cash_register", map[string]interface{}{"register_id": float64(9), "closing_amount": float64(0)},
+			nil, false, "close 9 0"},
+		{"close without an open register", "close_cash_register", map[string]interface{}{"closing_amount": float64(1)}, nil, true, ""},
+		{"close without amount", "close_cash_register", map[string]interface{}{}, openRegister, true, ""},
+		{"withdrawal by the register employee", "record_cash_movement",
+			map[string]interface{}{"type": "withdrawal", "amount": float64(20000), "description": "Proveedor"},
+			openRegister, false, "movement 5 withdrawal 20000 by 3"},
+		{"deposit by another employee", "record_cash_movement",
+			map[string]interface{}{"type": "deposit", "amount": float64(5000), "description": "Base", "employee_id": float64(8)},
+			openRegister, false, "movement 5 deposit 5000 by 8"},
+		{"unknown movement type", "record_cash_movement",
+			map[string]interface{}{"type": "sale", "amount": float64(5000), "description": "Venta"}, openRegister, true, ""},
+		{"zero amount", "record_cash_movement",
+			map[string]interface{}{"type": "deposit", "amount": float64(0), "description": "Base"}, openRegister, true, ""},
+		{"missing description", "record_cash_movement",
+			map[string]interface{}{"type": "deposit", "amount": float64(5000)}, openRegister, true, ""},
+		{"movements of the open register", "list_cash_movements", map[string]interface{}{}, openRegister, false, "movements 5"},
+		{"default history page", "list_cash_register_history", map[string]interface{}{}, nil, false, "history 20 0"},
+		{"history page", "list_cash_register_history", map[string]interface{}{"limit": float64(5), "offset": float64(10)},
+			nil, false, "history 5 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cash := &fakeCashService{open: tt.open}
+			s := NewMCPServer(0, "", "", "", false, "", &ServiceDependencies{CashService: cash})
+
+			_, err := s.executeTool(context.Background(), tt.tool, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("executeTool(%s) error = %v, wantErr %v", tt.tool, err, tt.wantErr)
+			}
+			if tt.wantCall == "" {
+				if len(cash.calls) > 0 {
+					t.Errorf("rejected call reached the service: %v", cash.calls)
+				}
+				return
+			}
+			if len(cash.calls) != 1 || cash.calls[0] != tt.wantCall {
+				t.Errorf("service calls = %v, want [%s]", cash.calls, tt.wantCall)
+			}
+		})
+	}
+}
+
+func TestCashRegisterToolsWithoutService(t *testing.T) {
+	s := NewMCPServer(0, "", "", "", false, "", &ServiceDependencies{})
+	for _, tool := range getCashRegisterTools() {
+		if _, err := s.executeTool(context.Background(), tool["name"].(string), map[string]interface{}{}); err == nil {
+			t.Errorf("%s succeeded without a cash register service", tool["name"])
+		}
+	}
+}
+
+func TestCashRegisterToolsAreWriteOperations(t *testing.T) {
+	writes := map[string]bool{"open_cash_register": true, "close_cash_register": true, "record_cash_movement": true}
+	for _, tool := range getCashRegisterTools() {
+		name := tool["name"].(string)
+		if isWriteOperation(name) != writes[name] {
+			t.Errorf("isWriteOperation(%s) = %v, want %v", name, isWriteOperation(name), writes[name])
+		}
+	}
+}
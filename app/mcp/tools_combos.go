@@ -0,0 +1,232 @@
+package mcp
+
+import (
+	"fmt"
+)
+
+// comboItemsSchema describes the products of a combo
+var comboItemsSchema = map[string]interface{}{
+	"type":        "array",
+	"description": "Products included in the combo",
+	"items": map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"product_id": map[string]interface{}{
+				"type":        "integer",
+				"description": "Product ID",
+			},
+			"quantity": map[string]interface{}{
+				"type":        "integer",
+				"description": "Quantity of the product in the combo (default: 1)",
+			},
+		},
+		"required": []string{"product_id"},
+	},
+}
+
+// getComboTools returns tool definitions for combo operations
+func getComboTools() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"name":        "list_combos",
+			"description": "List all combos with their products and price, including inactive ones",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"active_only": map[string]interface{}{
+						"type":        "boolean",
+						"description": "If true, only return combos available for sale",
+					},
+				},
+			},
+		},
+		{
+			"name":        "get_combo",
+			"description": "Get a combo by ID with its products",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"combo_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "The unique ID of the combo",
+					},
+				},
+				"required": []string{"combo_id"},
+			},
+		},
+		{
+			"name":        "create_combo",
+			"description": "Create a combo: a group of products sold together at a single price",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Combo name",
+					},
+					"description": map[string]interface{}{
+						"type":        "string",
+						"description": "Combo description",
+					},
+					"price": map[string]interface{}{
+						"type":        "number",
+						"description": "Price of the combo",
+					},
+					"category_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "Category where the combo is shown",
+					},
+					"tax_type_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "DIAN tax type ID (default: 1)",
+					},
+					"display_order": map[string]interface{}{
+						"type":        "integer",
+						"description": "Position in the menu",
+					},
+					"items": comboItemsSchema,
+				},
+				"required": []string{"name", "price", "items"},
+			},
+		},
+		{
+			"name":        "update_combo",
+			"description": "Update a combo. Only the provided fields change; if items is provided it replaces the products of the combo.",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"combo_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "The unique ID of the combo",
+					},
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Combo name",
+					},
+					"description": map[string]interface{}{
+						"type":        "string",
+						"description": "Combo description",
+					},
+					"price": map[string]interface{}{
+						"type":        "number",
+						"description": "Price of the combo",
+					},
+					"category_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "Category where the combo is shown",
+					},
+					"tax_type_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "DIAN tax type ID",
+					},
+					"display_order": map[string]interface{}{
+						"type":        "integer",
+						"description": "Position in the menu",
+					},
+					"is_active": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether the combo is available for sale",
+					},
+					"items": comboItemsSchema,
+				},
+				"required": []string{"combo_id"},
+			},
+		},
+		{
+			"name":        "delete_combo",
+			"description": "Delete a combo",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"combo_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "The unique ID of the combo",
+					},
+				},
+				"required": []string{"combo_id"},
+			},
+		},
+		{
+			"name":        "toggle_combo_active",
+			"description": "Activate an inactive combo or deactivate an active one",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"combo_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "The unique ID of the combo",
+					},
+				},
+				"required": []string{"combo_id"},
+			},
+		},
+	}
+}
+
+// executeComboTool executes a combo tool
+func executeComboTool(svc ComboServiceInterface, name string, args map[string]interface{}) (interface{}, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("combo service not available")
+	}
+
+	switch name {
+	case "list_combos":
+		combos, err := svc.GetAllCombos()
+		if err != nil {
+			return nil, err
+		}
+		if activeOnly, _ := args["active_only"].(bool); !activeOnly {
+			return combos, nil
+		}
+		var active []map[string]interface{}
+		for _, combo := range combos {
+			if isActive, _ := combo["is_active"].(bool); isActive {
+				active = append(active, combo)
+			}
+		}
+		return active, nil
+
+	case "get_combo":
+		comboID, ok := args["combo_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("combo_id is required")
+		}
+		return svc.GetCombo(uint(comboID))
+
+	case "create_combo":
+		if _, ok := args["items"].([]interface{}); !ok {
+			return nil, fmt.Errorf("items is required")
+		}
+		return svc.CreateCombo(args)
+
+	case "update_combo":
+		comboID, ok := args["combo_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("combo_id is required")
+		}
+		return svc.UpdateCombo(uint(comboID), args)
+
+	case "delete_combo":
+		comboID, ok := args["combo_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("combo_id is required")
+		}
+		if err := svc.DeleteCombo(uint(comboID)); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("Combo %d deleted", int(comboID)),
+		}, nil
+
+	case "toggle_combo_active":
+		comboID, ok := args["combo_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("combo_id is required")
+		}
+		return svc.ToggleComboActive(uint(comboID))
+
+	default:
+		return nil, fmt.Errorf("unknown combo tool: %s", name)
+	}
+}
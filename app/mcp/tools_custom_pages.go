@@ -0,0 +1,226 @@
+package mcp
+
+import (
+	"fmt"
+)
+
+// getCustomPageTools returns tool definitions for custom pages (the product tabs of the POS screen)
+func getCustomPageTools() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"name":        "list_custom_pages",
+			"description": "List the custom pages of the POS screen (groups of products shown as tabs)",
+			"inputSchema": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			"name":        "get_custom_page_products",
+			"description": "Get the products of a custom page in display order",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "The unique ID of the custom page",
+					},
+				},
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			"name":        "create_custom_page",
+			"description": "Create a custom page, optionally with its products",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Page name",
+					},
+					"description": map[string]interface{}{
+						"type":        "string",
+						"description": "Page description",
+					},
+					"icon": map[string]interface{}{
+						"type":        "string",
+						"description": "Material icon name",
+					},
+					"color": map[string]interface{}{
+						"type":        "string",
+						"description": "Color of the tab (e.g. #FF5722)",
+					},
+					"display_order": map[string]interface{}{
+						"type":        "integer",
+						"description": "Position of the tab",
+					},
+					"product_ids": map[string]interface{}{
+						"type":        "array",
+						"description": "Products of the page in display order",
+						"items":       map[string]interface{}{"type": "integer"},
+					},
+				},
+				"required": []string{"name"},
+			},
+		},
+		{
+			"name":        "update_custom_page",
+			"description": "Update a custom page. Only the provided fields change.",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "The unique ID of the custom page",
+					},
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Page name",
+					},
+					"description": map[string]interface{}{
+						"type":        "string",
+						"description": "Page description",
+					},
+					"icon": map[string]interface{}{
+						"type":        "string",
+						"description": "Material icon name",
+					},
+					"color": map[string]interface{}{
+						"type":        "string",
+						"description": "Color of the tab",
+					},
+					"display_order": map[string]interface{}{
+						"type":        "integer",
+						"description": "Position of the tab",
+					},
+					"is_active": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether the page is shown",
+					},
+				},
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			"name":        "delete_custom_page",
+			"description": "Delete a custom page (the products themselves are not deleted)",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "The unique ID of the custom page",
+					},
+				},
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			"name":        "set_custom_page_products",
+			"description": "Replace the products of a custom page. The order of product_ids is the display order.",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "The unique ID of the custom page",
+					},
+					"product_ids": map[string]interface{}{
+						"type":        "array",
+						"description": "Products of the page in display order",
+						"items":       map[string]interface{}{"type": "integer"},
+					},
+				},
+				"required": []string{"page_id", "product_ids"},
+			},
+		},
+	}
+}
+
+// productIDsArg reads the product_ids argument
+func productIDsArg(args map[string]interface{}) ([]uint, bool) {
+	raw, ok := args["product_ids"].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	ids := make([]uint, 0, len(raw))
+	for _, value := range raw {
+		if id, ok := value.(float64); ok && id > 0 {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids, true
+}
+
+// executeCustomPageTool executes a custom page tool
+func executeCustomPageTool(svc CustomPageServiceInterface, name string, args map[string]interface{}) (interface{}, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("custom page service not available")
+	}
+
+	switch name {
+	case "list_custom_pages":
+		return svc.GetAllPages()
+
+	case "get_custom_page_products":
+		pageID, ok := args["page_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("page_id is required")
+		}
+		return svc.GetPageProducts(uint(pageID))
+
+	case "create_custom_page":
+		page, err := svc.CreatePage(args)
+		if err != nil {
+			return nil, err
+		}
+		if productIDs, ok := productIDsArg(args); ok {
+			pageID, _ := page["id"].(float64)
+			if err := svc.SetPageProducts(uint(pageID), productIDs); err != nil {
+				return nil, err
+			}
+		}
+		return page, nil
+
+	case "update_custom_page":
+		pageID, ok := args["page_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("page_id is required")
+		}
+		return svc.UpdatePage(uint(pageID), args)
+
+	case "delete_custom_page":
+		pageID, ok := args["page_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("page_id is required")
+		}
+		if err := svc.DeletePage(uint(pageID)); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("Custom page %d deleted", int(pageID)),
+		}, nil
+
+	case "set_custom_page_products":
+		pageID, ok := args["page_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("page_id is required")
+		}
+		productIDs, ok := productIDsArg(args)
+		if !ok {
+			return nil, fmt.Errorf("product_ids is required")
+		}
+		if err := svc.SetPageProducts(uint(pageID), productIDs); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("Custom page %d now has %d products", int(pageID), len(productIDs)),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown custom page tool: %s", name)
+	}
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"fmt"
+)
+
+// getDIANTools returns tool definitions for electronic invoicing status and DIAN reports
+func getDIANTools() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"name":        "get_invoice_status",
+			"description": "Get the DIAN validation status of the electronic invoice of a sale (status, CUFE, validation message, retries). Use refresh to query DIAN again.",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"sale_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "ID of the sale",
+					},
+					"refresh": map[string]interface{}{
+						"type":        "boolean",
+						"description": "If true, query DIAN for the current validation result before returning",
+					},
+				},
+				"required": []string{"sale_id"},
+			},
+		},
+		{
+			"name":        "list_electronic_invoices",
+			"description": "List electronic invoices by DIAN status, e.g. to find rejected or still validating invoices",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"status": map[string]interface{}{
+						"type":        "string",
+						"description": "Invoice status (all statuses if omitted)",
+						"enum":        []string{"pending", "sent", "validating", "accepted", "rejected"},
+					},
+					"from_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Start date (YYYY-MM-DD)",
+					},
+					"to_date": map[string]interface{}{
+						"type":        "string",
+						"description": "End date (YYYY-MM-DD)",
+					},
+				},
+			},
+		},
+		{
+			"name":        "get_dian_closing_report",
+			"description": "Get the DIAN closing report (invoice ranges, totals by tax, payment method and department) for a day, week, month or year, or for a custom date range",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"date": map[string]interface{}{
+						"type":        "string",
+						"description": "Reference date (YYYY-MM-DD). Defaults to today.",
+					},
+					"period": map[string]interface{}{
+						"type":        "string",
+						"description": "Period around the date (default: daily)",
+						"enum":        []string{"daily", "weekly", "monthly", "yearly"},
+					},
+					"from_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of a custom range (YYYY-MM-DD), used together with to_date instead of date/period",
+					},
+					"to_date": map[string]interface{}{
+						"type":        "string",
+						"description": "End of a custom range (YYYY-MM-DD)",
+					},
+				},
+			},
+		},
+	}
+}
+
+// executeDIANTool executes a DIAN tool
+func executeDIANTool(svc DIANServiceInterface, name string, args map[string]interface{}) (interface{}, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DIAN service not available")
+	}
+
+	switch name {
+	case "get_invoice_status":
+		saleID, ok := args["sale_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("sale_id is required")
+		}
+		refresh, _ := args["refresh"].(bool)
+		return svc.GetInvoiceStatus(uint(saleID), refresh)
+
+	case "list_electronic_invoices":
+		status, _ := args["status"].(string)
+		fromDate, _ := args["from_date"].(string)
+		toDate, _ := args["to_date"].(string)
+		return svc.GetInvoicesByStatus(status, fromDate, toDate)
+
+	case "get_dian_closing_report":
+		fromDate, _ := args["from_date"].(string)
+		toDate, _ := args["to_date"].(string)
+		if fromDate != "" || toDate != "" {
+			if fromDate == "" || toDate == "" {
+				return nil, fmt.Errorf("from_date and to_date are required for a custom range")
+			}
+			return svc.GetDIANClosingReportRange(fromDate, toDate)
+		}
+		date, _ := args["date"].(string)
+		period := "daily"
+		if p, ok := args["period"].(string); ok && p != "" {
+			period = p
+		}
+		return svc.GetDIANClosingReport(date, period)
+
+	default:
+		return nil, fmt.Errorf("unknown DIAN tool: %s", name)
+	}
+}
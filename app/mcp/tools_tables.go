@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"fmt"
+)
+
+// tableStatuses are the statuses a table can be set to
+var tableStatuses = []string{"available", "occupied", "reserved", "cleaning"}
+
+// getTableTools returns tool definitions for tables and their occupancy
+func getTableTools() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"name":        "list_tables",
+			"description": "List the active tables with their area, status and the order currently assigned to each one (occupancy)",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"status": map[string]interface{}{
+						"type":        "string",
+						"description": "Only return tables with this status",
+						"enum":        tableStatuses,
+					},
+					"area_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "Only return tables of this area",
+					},
+				},
+			},
+		},
+		{
+			"name":        "get_table",
+			"description": "Get a table by ID with its area and current order",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"table_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "The unique ID of the table",
+					},
+				},
+				"required": []string{"table_id"},
+			},
+		},
+		{
+			"name":        "set_table_status",
+			"description": "Change the status of a table (available, occupied, reserved or cleaning). Connected terminals are notified.",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"table_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "The unique ID of the table",
+					},
+					"status": map[string]interface{}{
+						"type":        "string",
+						"description": "New status",
+						"enum":        tableStatuses,
+					},
+				},
+				"required": []string{"table_id", "status"},
+			},
+		},
+		{
+			"name":        "list_table_areas",
+			"description": "List the table areas (dining room, terrace, bar, etc.)",
+			"inputSchema": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+	}
+}
+
+// executeTableTool executes a table tool
+func executeTableTool(svc TableServiceInterface, name string, args map[string]interface{}) (interface{}, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("table service not available")
+	}
+
+	switch name {
+	case "list_tables":
+		tables, err := svc.GetTables()
+		if err != nil {
+			return nil, err
+		}
+		status, _ := args["status"].(string)
+		areaID, _ := args["area_id"].(float64)
+		if status == "" && areaID == 0 {
+			return tables, nil
+		}
+		var filtered []map[string]interface{}
+		for _, table := range tables {
+			if status != "" && table["status"] != status {
+				continue
+			}
+			if areaID != 0 && table["area_id"] != areaID {
+				continue
+			}
+			filtered = append(filtered, table)
+		}
+		return filtered, nil
+
+	case "get_table":
+		tableID, ok := args["table_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("table_id is required")
+		}
+		return svc.GetTable(uint(tableID))
+
+	case "set_table_status":
+		tableID, ok := args["table_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("table_id is required")
+		}
+		status, _ := args["status"].(string)
+		valid := false
+		for _, s := range tableStatuses {
+			if s == status {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("status must be one of: available, occupied, reserved, cleaning")
+		}
+		if err := svc.UpdateTableStatus(uint(tableID), status); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("Table %d status updated to %s", int(tableID), status),
+		}, nil
+
+	case "list_table_areas":
+		return svc.GetTableAreas()
+
+	default:
+		return nil, fmt.Errorf("unknown table tool: %s", name)
+	}
+}
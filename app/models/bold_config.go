@@ -91,6 +91,20 @@ type BoldTerminalResponse struct {
 	Name           string `json:"name"`
 }
 
+// BoldTransaction represents a transaction listed by the Bold transactions API
+type BoldTransaction struct {
+	PaymentID      string  `json:"payment_id"`
+	IntegrationID  string  `json:"integration_id"`
+	Reference      string  `json:"reference"`
+	Status         string  `json:"status"` // APPROVED, REJECTED, VOIDED, PARTIALLY_REFUNDED...
+	TotalAmount    float64 `json:"total_amount"`
+	ApprovalNumber string  `json:"approval_number"`
+	BoldCode       string  `json:"bold_code"`
+	CardBrand      string  `json:"card_brand"`
+	CardMaskedPan  string  `json:"card_masked_pan"`
+	TransactionAt  string  `json:"transaction_date"` // RFC 3339
+}
+
 // BoldPaymentRequest represents the request to create a payment
 type BoldPaymentRequest struct {
 	Amount        BoldAmount `json:"amount"`
@@ -149,7 +163,8 @@ type BoldPendingPayment struct {
 	IntegrationID string  `json:"integration_id" gorm:"uniqueIndex;not null"` // Bold's integration_id
 	Reference     string  `json:"reference"`                                   // Our internal reference
 	Amount        float64 `json:"amount"`                                      // Payment amount
-	Status        string  `json:"status" gorm:"default:'pending'"`             // "pending", "approved", "rejected", "cancelled"
+	TipAmount     float64 `json:"tip_amount"`                                  // Tip included in Amount
+	Status        string  `json:"status" gorm:"default:'pending'"`             // "pending", "approved", "rejected", "cancelled", "expired"
 
 	// Context information to complete the payment
 	PaymentMethodID   uint   `json:"payment_method_id"`             // POS payment method ID
@@ -158,6 +173,8 @@ type BoldPendingPayment struct {
 	CustomerID        uint   `json:"customer_id,omitempty"`         // Customer ID if available
 	EmployeeID        uint   `json:"employee_id,omitempty"`         // Employee ID if available
 	CashRegisterID    uint   `json:"cash_register_id,omitempty"`    // Cash register ID if available
+	SaleID            uint   `gorm:"index" json:"sale_id,omitempty"`   // Sale completed with this payment
+	POSPaymentID      uint   `json:"pos_payment_id,omitempty"`         // Payment row created for this Bold payment
 
 	// Webhook notification data (populated when webhook received)
 	PaymentID      string     `json:"payment_id"`       // Bold's payment_id from webhook
@@ -167,11 +184,159 @@ type BoldPendingPayment struct {
 	CardMaskedPan  string     `json:"card_masked_pan"`  // Masked card number
 	WebhookData    string     `json:"webhook_data" gorm:"type:text"` // Full webhook JSON for reference
 
+	// Pay-by-link (delivery and phone orders)
+	Channel        string     `json:"channel" gorm:"default:'terminal'"`        // "terminal" or "link"
+	PaymentLinkURL string     `json:"payment_link_url,omitempty"`               // Checkout URL sent to the customer
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`                     // Link expiration
+	SentVia        string     `json:"sent_via,omitempty"`                       // "whatsapp", "email"
+	SentTo         string     `json:"sent_to,omitempty"`                        // Phone or email the link was sent to
+	ProcessError   string     `json:"process_error,omitempty" gorm:"type:text"` // Error completing the sale automatically
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
+// Bold pending payment channels
+const (
+	BoldChannelTerminal = "terminal"
+	BoldChannelLink     = "link"
+)
+
+// BoldPaymentLinkRequest represents the request to create a pay-by-link (Bold payment links API)
+type BoldPaymentLinkRequest struct {
+	AmountType     string     `json:"amount_type"` // "CLOSE" = fixed amount
+	Amount         BoldAmount `json:"amount"`
+	Description    string     `json:"description"`
+	Reference      string     `json:"reference,omitempty"`
+	ExpirationDate int64      `json:"expiration_date"` // Unix time in nanoseconds
+	PayerEmail     string     `json:"payer_email,omitempty"`
+	CallbackURL    string     `json:"callback_url,omitempty"`
+}
+
+// BoldPaymentLinkResponse represents the API response for payment link creation
+type BoldPaymentLinkResponse struct {
+	Payload struct {
+		PaymentLink string `json:"payment_link"` // Link ID (LNK_...)
+		URL         string `json:"url"`          // Checkout URL
+	} `json:"payload"`
+	Errors []interface{} `json:"errors"`
+}
+
+// BoldVoidRequest represents the request to void or refund an approved payment
+type BoldVoidRequest struct {
+	Amount    BoldAmount `json:"amount"`
+	Reason    string     `json:"reason,omitempty"`
+	Reference string     `json:"reference"`
+	UserEmail string     `json:"user_email"`
+}
+
+// BoldVoidResponse represents the API response for a void request
+type BoldVoidResponse struct {
+	Payload BoldVoidPayload `json:"payload"`
+	Errors  []interface{}   `json:"errors"`
+}
+
+// BoldVoidPayload represents the payload in void response
+type BoldVoidPayload struct {
+	VoidID string `json:"void_id"`
+	Status string `json:"status"`
+}
+
+// BoldPendingVoid tracks Bold voids/refunds awaiting webhook confirmation
+type BoldPendingVoid struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	IntegrationID string  `json:"integration_id" gorm:"index;not null"` // integration_id of the original payment
+	PaymentID     string  `json:"payment_id" gorm:"index"`              // Bold's payment_id of the original payment
+	VoidID        string  `json:"void_id"`                              // Identifier returned by Bold for the void
+	Reference     string  `json:"reference"`                            // Our internal reference
+	Amount        float64 `json:"amount"`                               // Amount to return to the card
+	IsPartial     bool    `json:"is_partial"`                           // Partial refund instead of full void
+	Reason        string  `json:"reason"`
+	Status        string  `json:"status" gorm:"default:'pending'"` // "pending", "approved", "rejected", "failed"
+
+	// POS context
+	SaleID         uint `json:"sale_id,omitempty" gorm:"index"`
+	POSPaymentID   uint `json:"pos_payment_id,omitempty"`
+	EmployeeID     uint `json:"employee_id,omitempty"`
+	CashRegisterID uint `json:"cash_register_id,omitempty"`
+
+	ErrorMessage string     `json:"error_message" gorm:"type:text"`
+	WebhookData  string     `json:"webhook_data" gorm:"type:text"` // Full webhook JSON for reference
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// Bold reconciliation match statuses
+const (
+	BoldMatchMatched        = "matched"         // Bold transaction and POS payment agree
+	BoldMatchAmountMismatch = "amount_mismatch" // Matched, but amounts differ
+	BoldMatchBoldOrphan     = "bold_orphan"     // Approved at Bold but no sale in the POS
+	BoldMatchPOSOrphan      = "pos_orphan"      // Sale recorded as Bold but never approved
+)
+
+// BoldReconciliation represents the reconciliation of Bold transactions against POS payments
+// for a day or for a cash register shift
+type BoldReconciliation struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	PeriodStart    time.Time `json:"period_start" gorm:"index"`
+	PeriodEnd      time.Time `json:"period_end"`
+	CashRegisterID *uint     `json:"cash_register_id,omitempty" gorm:"index"` // Set when attached to a cash register close
+	Status         string    `json:"status"`                                  // "balanced", "discrepancies"
+	Source         string    `json:"source"`                                  // "bold_api", or "local" when the Bold API couldn't be reached
+
+	// Totals (amounts include tips, as charged at Bold)
+	BoldCount           int     `json:"bold_count"`
+	BoldTotal           float64 `json:"bold_total"`
+	POSCount            int     `json:"pos_count"`
+	POSTotal            float64 `json:"pos_total"`
+	MatchedCount        int     `json:"matched_count"`
+	MatchedTotal        float64 `json:"matched_total"`
+	AmountMismatchCount int     `json:"amount_mismatch_count"`
+	BoldOrphanCount     int     `json:"bold_orphan_count"`
+	BoldOrphanTotal     float64 `json:"bold_orphan_total"`
+	POSOrphanCount      int     `json:"pos_orphan_count"`
+	POSOrphanTotal      float64 `json:"pos_orphan_total"`
+	Difference          float64 `json:"difference"` // BoldTotal - POSTotal
+
+	Items       []BoldReconciliationItem `json:"items,omitempty" gorm:"foreignKey:ReconciliationID"`
+	GeneratedBy uint                     `json:"generated_by,omitempty"` // 0 = daily job
+	CreatedAt   time.Time                `json:"created_at"`
+}
+
+// BoldReconciliationItem represents one line of a Bold reconciliation
+type BoldReconciliationItem struct {
+	ID               uint   `gorm:"primaryKey" json:"id"`
+	ReconciliationID uint   `gorm:"index" json:"reconciliation_id"`
+	MatchStatus      string `json:"match_status"` // matched, amount_mismatch, bold_orphan, pos_orphan
+	MatchedBy        string `json:"matched_by"`   // "integration_id", "reference", "amount"
+
+	// Bold side
+	IntegrationID  string     `json:"integration_id"`
+	BoldPaymentID  string     `json:"bold_payment_id"`
+	ApprovalNumber string     `json:"approval_number"`
+	CardBrand      string     `json:"card_brand"`
+	CardMaskedPan  string     `json:"card_masked_pan"`
+	BoldStatus     string     `json:"bold_status"`
+	BoldAmount     float64    `json:"bold_amount"`
+	BoldAt         *time.Time `json:"bold_at,omitempty"`
+
+	// POS side
+	PaymentID  uint       `json:"payment_id,omitempty"`
+	SaleID     uint       `json:"sale_id,omitempty"`
+	SaleNumber string     `json:"sale_number"`
+	POSAmount  float64    `json:"pos_amount"` // Payment amount plus tip
+	POSAt      *time.Time `json:"pos_at,omitempty"`
+
+	Difference float64 `json:"difference"` // BoldAmount - POSAmount
+	Notes      string  `json:"notes"`
+}
+
 // BoldWebhookLog represents a raw webhook attempt (for debugging)
 type BoldWebhookLog struct {
 	ID uint `gorm:"primaryKey" json:"id"`
@@ -92,7 +92,7 @@ type RestaurantConfig struct {
 type DIANConfig struct {
 	ID uint `gorm:"primaryKey" json:"id"`
 	// Environment
-	Environment string `json:"environment"` // "test", "production"
+	Environment string `json:"environment"` // "test", "production", "mock" (local simulator)
 	IsEnabled   bool   `json:"is_enabled"`
 
 	// Company Data
@@ -139,16 +139,75 @@ type DIANConfig struct {
 	DebitNoteResolutionDateFrom time.Time `json:"debit_note_resolution_date_from"`
 	DebitNoteResolutionDateTo   time.Time `json:"debit_note_resolution_date_to"`
 
+	// Resolution POS Equivalent Document (Documento Equivalente Electrónico POS)
+	POSResolutionNumber   string    `json:"pos_resolution_number"`
+	POSResolutionPrefix   string    `json:"pos_resolution_prefix"`
+	POSResolutionFrom     int       `json:"pos_resolution_from"`
+	POSResolutionTo       int       `json:"pos_resolution_to"`
+	POSResolutionDateFrom time.Time `json:"pos_resolution_date_from"`
+	POSResolutionDateTo   time.Time `json:"pos_resolution_date_to"`
+	POSTechnicalKey       string    `json:"pos_technical_key"`
+
+	// Resolution Support Document (Documento Soporte)
+	SupportDocResolutionNumber   string    `json:"support_doc_resolution_number"`
+	SupportDocResolutionPrefix   string    `json:"support_doc_resolution_prefix"`
+	SupportDocResolutionFrom     int       `json:"support_doc_resolution_from"`
+	SupportDocResolutionTo       int       `json:"support_doc_resolution_to"`
+	SupportDocResolutionDateFrom time.Time `json:"support_doc_resolution_date_from"`
+	SupportDocResolutionDateTo   time.Time `json:"support_doc_resolution_date_to"`
+
+	// Support Document Adjustment Note
+	SupportDocAdjustmentPrefix string `json:"support_doc_adjustment_prefix"`
+	SupportDocAdjustmentFrom   int    `json:"support_doc_adjustment_from"`
+	SupportDocAdjustmentTo     int    `json:"support_doc_adjustment_to"`
+
+	// Resolution Contingency Invoice (Factura de contingencia facturador - tipo 03)
+	ContingencyResolutionNumber   string    `json:"contingency_resolution_number"`
+	ContingencyResolutionPrefix   string    `json:"contingency_resolution_prefix"`
+	ContingencyResolutionFrom     int       `json:"contingency_resolution_from"`
+	ContingencyResolutionTo       int       `json:"contingency_resolution_to"`
+	ContingencyResolutionDateFrom time.Time `json:"contingency_resolution_date_from"`
+	ContingencyResolutionDateTo   time.Time `json:"contingency_resolution_date_to"`
+	ContingencyTechnicalKey       string    `json:"contingency_technical_key"`
+
+	// Contingency Mode (DIAN / provider outages)
+	ContingencyModeActive   bool `json:"contingency_mode_active"`                       // Invoices are issued with the contingency range and reported later
+	ContingencyAutoActivate bool `json:"contingency_auto_activate" gorm:"default:true"` // Switch to contingency automatically when the provider is unreachable
+	ContingencyReportHours  int  `json:"contingency_report_hours" gorm:"default:48"`    // Window to report contingency invoices once the service is back
+
+	// POS Cash Register (required by the POS equivalent document)
+	UsePOSEquivalentDocument bool   `json:"use_pos_equivalent_document"` // Issue a POS document instead of a simple receipt for CONSUMIDOR FINAL sales
+	POSPlateNumber           string `json:"pos_plate_number"`            // Cash register plate / serial number
+	POSLocation              string `json:"pos_location"`                // Cash register physical location
+	POSCashType              string `json:"pos_cash_type"`               // Cash register type, e.g. "Caja principal"
+	POSSoftwareOwnerName     string `json:"pos_software_owner_name"`     // Software manufacturer (owner name)
+	POSSoftwareCompanyName   string `json:"pos_software_company_name"`   // Software manufacturer (company name)
+	POSSoftwareName          string `json:"pos_software_name"`           // Software manufacturer (software name)
+
 	// API Settings
 	APIURL       string `json:"api_url"`
 	APIToken     string `json:"api_token"`
 	TestSetID    string `json:"test_set_id"`
 	UseTestSetID bool   `json:"use_test_set_id"` // If true, includes test_set_id in URL (some tests don't need it)
 
+	// Local Mock DIAN API (Environment "mock", for development and training)
+	MockLatencyMs          int     `json:"mock_latency_ms" gorm:"default:300"`        // Delay added to every mock response
+	MockAsyncValidation    bool    `json:"mock_async_validation" gorm:"default:true"` // Answer with a zip key and validate later, like the test set
+	MockValidationSeconds  int     `json:"mock_validation_seconds" gorm:"default:5"`  // Time until an async document is validated
+	MockRejectAbove        float64 `json:"mock_reject_above"`                         // Reject documents with a payable amount above this (0 = never)
+	MockRejectCustomers    string  `json:"mock_reject_customers"`                     // Comma separated customer identifications that are always rejected
+	MockRejectRate         int     `json:"mock_reject_rate"`                          // Percentage of documents rejected at random
+	MockServiceUnavailable bool    `json:"mock_service_unavailable"`                  // Answer 503 to simulate a DIAN outage
+
 	// Counters
 	LastInvoiceNumber    int `json:"last_invoice_number"`
 	LastCreditNoteNumber int `json:"last_credit_note_number"`
 	LastDebitNoteNumber  int `json:"last_debit_note_number"`
+	LastPOSNumber        int `json:"last_pos_number"`
+
+	LastSupportDocNumber           int `json:"last_support_doc_number"`
+	LastSupportDocAdjustmentNumber int `json:"last_support_doc_adjustment_number"`
+	LastContingencyNumber          int `json:"last_contingency_number"`
 
 	// Alert Settings
 	InvoiceLimitAlertThreshold int `json:"invoice_limit_alert_threshold" gorm:"default:100"` // Alert when remaining invoices <= threshold
@@ -162,13 +221,16 @@ type DIANConfig struct {
 	EmailEncryption string `json:"email_encryption"`
 
 	// Configuration Steps Completion Tracking
-	Step1Completed bool `json:"step1_completed"` // Company configuration
-	Step2Completed bool `json:"step2_completed"` // Software configuration
-	Step3Completed bool `json:"step3_completed"` // Certificate configuration
-	Step4Completed bool `json:"step4_completed"` // Resolution configuration (Invoice)
-	Step5Completed bool `json:"step5_completed"` // Resolution configuration (Credit Note - NC)
-	Step6Completed bool `json:"step6_completed"` // Resolution configuration (Debit Note - ND)
-	Step7Completed bool `json:"step7_completed"` // Production migration
+	Step1Completed  bool `json:"step1_completed"`  // Company configuration
+	Step2Completed  bool `json:"step2_completed"`  // Software configuration
+	Step3Completed  bool `json:"step3_completed"`  // Certificate configuration
+	Step4Completed  bool `json:"step4_completed"`  // Resolution configuration (Invoice)
+	Step5Completed  bool `json:"step5_completed"`  // Resolution configuration (Credit Note - NC)
+	Step6Completed  bool `json:"step6_completed"`  // Resolution configuration (Debit Note - ND)
+	Step7Completed  bool `json:"step7_completed"`  // Production migration
+	Step8Completed  bool `json:"step8_completed"`  // Resolution configuration (POS Equivalent Document)
+	Step9Completed  bool `json:"step9_completed"`  // Resolution configuration (Support Document)
+	Step10Completed bool `json:"step10_completed"` // Resolution configuration (Contingency Invoice)
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -190,6 +252,7 @@ type PrinterConfig struct {
 	AutoCut          bool      `json:"auto_cut"`
 	CashDrawer       bool      `json:"cash_drawer"`       // Has cash drawer attached
 	PrintKitchenCopy bool      `json:"print_kitchen_copy"` // Print kitchen copy when order is created
+	BackupPrinterID  *uint     `json:"backup_printer_id"`  // Printer that takes the jobs of this one when it fails
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 }
@@ -270,8 +333,9 @@ type MCPConfig struct {
 	Port    int  `json:"port" gorm:"default:8090"`
 
 	// Security
-	APIKey     string `json:"api_key"`     // Optional API key for authentication
-	AllowedIPs string `json:"allowed_ips"` // Comma-separated list of allowed IPs (empty = all allowed)
+	APIKey         string `json:"api_key"`         // Optional API key for authentication
+	AllowedIPs     string `json:"allowed_ips"`     // Comma-separated list of allowed IPs (empty = all allowed)
+	AllowedOrigins string `json:"allowed_origins"` // Comma-separated browser origins allowed besides localhost (Origin header)
 
 	// Features
 	ReadOnlyMode  bool   `json:"read_only_mode" gorm:"default:false"` // If true, only read operations are allowed
@@ -281,6 +345,51 @@ type MCPConfig struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// MCP client scopes
+const (
+	MCPScopeRead  = "read"  // Only read operations
+	MCPScopeWrite = "write" // Read and write operations
+)
+
+// MCPClient represents an application allowed to use the MCP server with its own API key
+type MCPClient struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"not null" json:"name"`
+	Description string `json:"description"`
+
+	// Credentials (the key itself is only shown when created or regenerated)
+	KeyHash   string `gorm:"uniqueIndex;not null" json:"-"` // SHA-256 of the API key
+	KeyPrefix string `json:"key_prefix"`                    // First characters of the key, to recognize it
+
+	// Permissions
+	AllowedTools       string     `json:"allowed_tools"`                           // Comma-separated tool names
+	AllowedCategories  string     `json:"allowed_categories"`                      // Comma-separated categories ("Reportes", "Ventas"...); empty tools and categories = all
+	Scope              string     `gorm:"default:'read'" json:"scope"`             // "read" or "write"
+	RateLimitPerMinute int        `gorm:"default:60" json:"rate_limit_per_minute"` // 0 = unlimited
+	ExpiresAt          *time.Time `json:"expires_at,omitempty"`
+	IsActive           bool       `gorm:"default:true" json:"is_active"`
+
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MCPToolCallLog records every tool call made through the MCP server
+type MCPToolCallLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ClientID   *uint     `gorm:"index" json:"client_id,omitempty"` // nil = global API key or no authentication
+	ClientName string    `json:"client_name"`
+	Tool       string    `gorm:"index" json:"tool"`
+	Arguments  string    `gorm:"type:text" json:"arguments"` // JSON
+	Status     string    `gorm:"index" json:"status"`        // success, error, denied, rate_limited
+	Error      string    `gorm:"type:text" json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
 // NetworkConfig represents network and port configuration for all services
 type NetworkConfig struct {
 	ID uint `gorm:"primaryKey" json:"id"`
@@ -16,6 +16,7 @@ type Employee struct {
 	Role        string         `json:"role"` // "admin", "cashier", "waiter", "kitchen"
 	Email       string         `json:"email"`
 	Phone       string         `json:"phone"`
+	HourlyRate  float64        `gorm:"default:0" json:"hourly_rate"` // Base hourly wage, used for labor cost reports
 	IsActive    bool           `gorm:"default:true" json:"is_active"`
 	LastLoginAt *time.Time     `json:"last_login_at,omitempty"`
 	CreatedAt   time.Time      `json:"created_at"`
@@ -70,6 +71,8 @@ type CashRegisterReport struct {
 	TotalRefunds    float64   `json:"total_refunds"`
 	TotalDiscounts  float64   `json:"total_discounts"`
 	TotalTax        float64   `json:"total_tax"`
+	TotalTips       float64   `json:"total_tips"`
+	CashTips        float64   `json:"cash_tips"` // Tips received in cash (included in ExpectedBalance)
 	NumberOfSales   int       `json:"number_of_sales"`
 	NumberOfRefunds int       `json:"number_of_refunds"`
 	CashDeposits    float64   `json:"cash_deposits"`
@@ -82,6 +85,10 @@ type CashRegisterReport struct {
 	GeneratedBy     uint      `json:"generated_by"`
 	Employee        *Employee `gorm:"foreignKey:GeneratedBy" json:"employee,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
+
+	// Bold reconciliation attached at close (nil if Bold is disabled)
+	BoldReconciliationID *uint               `json:"bold_reconciliation_id,omitempty"`
+	BoldReconciliation   *BoldReconciliation `gorm:"foreignKey:BoldReconciliationID" json:"bold_reconciliation,omitempty"`
 }
 
 // Session represents an active employee session
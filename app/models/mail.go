@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Mail message kinds
+const (
+	MailKindInvoice            = "invoice"
+	MailKindDIANClosingReport  = "dian_closing_report"
+	MailKindCashRegisterReport = "cash_register_report"
+	MailKindLowStockAlert      = "low_stock_alert"
+	MailKindInvoiceRangeAlert  = "invoice_range_alert"
+	MailKindPaymentLink        = "payment_link"
+	MailKindTest               = "test"
+)
+
+// Mail message statuses
+const (
+	MailStatusPending   = "pending"   // Waiting for its first delivery attempt
+	MailStatusRetrying  = "retrying"  // Last attempt failed, will be retried at NextAttemptAt
+	MailStatusSent      = "sent"      // Accepted by the SMTP server
+	MailStatusFailed    = "failed"    // Gave up after MaxAttempts
+	MailStatusCancelled = "cancelled" // Cancelled by the user before delivery
+)
+
+// MailMessage is an email in the outgoing queue
+// Every delivery attempt is logged in MailDeliveryAttempt
+type MailMessage struct {
+	ID            uint                  `gorm:"primaryKey" json:"id"`
+	Kind          string                `gorm:"index" json:"kind"` // "invoice", "dian_closing_report", "low_stock_alert", ...
+	To            string                `json:"to"`                // Comma separated recipients
+	Subject       string                `json:"subject"`
+	HTMLBody      string                `gorm:"type:text" json:"html_body"`
+	Status        string                `gorm:"index;default:'pending'" json:"status"`
+	Attempts      int                   `json:"attempts"`
+	MaxAttempts   int                   `gorm:"default:5" json:"max_attempts"`
+	NextAttemptAt *time.Time            `gorm:"index" json:"next_attempt_at,omitempty"`
+	LastError     string                `json:"last_error"`
+	SentAt        *time.Time            `json:"sent_at,omitempty"`
+	ReferenceType string                `gorm:"index" json:"reference_type"` // "sale", "cash_register_report", "dian_closing_report"...
+	ReferenceID   uint                  `gorm:"index" json:"reference_id"`
+	Attachments   []MailAttachment      `gorm:"foreignKey:MailMessageID" json:"attachments,omitempty"`
+	DeliveryLog   []MailDeliveryAttempt `gorm:"foreignKey:MailMessageID" json:"delivery_log,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+}
+
+// MailAttachment is a file attached to a queued email
+type MailAttachment struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	MailMessageID uint      `gorm:"index" json:"mail_message_id"`
+	FileName      string    `json:"file_name"`
+	ContentType   string    `json:"content_type"`
+	Data          string    `gorm:"type:text" json:"-"` // Base64 encoded content
+	Size          int       `json:"size"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// MailDeliveryAttempt logs one delivery attempt of a queued email
+type MailDeliveryAttempt struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	MailMessageID uint      `gorm:"index" json:"mail_message_id"`
+	Attempt       int       `json:"attempt"`
+	Server        string    `json:"server"` // host:port used for the attempt
+	Success       bool      `json:"success"`
+	Error         string    `json:"error"`
+	DurationMs    int64     `json:"duration_ms"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -86,11 +86,44 @@ type Order struct {
 	// Kitchen acknowledgment tracking
 	KitchenAcknowledged   bool       `gorm:"default:false" json:"kitchen_acknowledged"`
 	KitchenAcknowledgedAt *time.Time `json:"kitchen_acknowledged_at,omitempty"`
+	StatusHistory []OrderStatusEvent `gorm:"foreignKey:OrderID" json:"status_history,omitempty"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
+// Order status change sources
+const (
+	OrderStatusSourcePOS     = "pos"
+	OrderStatusSourceKitchen = "kitchen"
+	OrderStatusSourceWaiter  = "waiter"
+	OrderStatusSourceMCP     = "mcp"
+	OrderStatusSourceRappi   = "rappi"
+	OrderStatusSourceSystem  = "system"
+)
+
+// OrderStatusEvent records a single order status transition
+type OrderStatusEvent struct {
+	ID         uint        `gorm:"primaryKey" json:"id"`
+	OrderID    uint        `gorm:"index" json:"order_id"`
+	FromStatus OrderStatus `json:"from_status"` // Empty for the creation event
+	ToStatus   OrderStatus `gorm:"index" json:"to_status"`
+	EmployeeID *uint       `json:"employee_id,omitempty"`
+	Employee   *Employee   `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
+	Actor      string      `json:"actor"`  // Who made the change when not an employee (kitchen client ID, MCP client, Rappi)
+	Source     string      `json:"source"` // "pos", "kitchen", "waiter", "mcp", "rappi", "system"
+	Reason     string      `json:"reason,omitempty"`
+	CreatedAt  time.Time   `gorm:"index" json:"created_at"`
+}
+
+// OrderStatusChange describes who changed an order status, from where and why
+type OrderStatusChange struct {
+	Source     string
+	EmployeeID *uint
+	Actor      string
+	Reason     string
+}
+
 // OrderItem represents an item in an order
 type OrderItem struct {
 	ID              uint                `gorm:"primaryKey" json:"id"`
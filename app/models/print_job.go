@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Print job types
+const (
+	PrintJobReceipt            = "receipt"
+	PrintJobInvoice            = "invoice"
+	PrintJobKitchen            = "kitchen"
+	PrintJobOrder              = "order"
+	PrintJobWaiterReceipt      = "waiter_receipt"
+	PrintJobCashRegisterReport = "cash_register_report"
+	PrintJobDIANClosingReport  = "dian_closing_report"
+	PrintJobCustomerForm       = "customer_form"
+)
+
+// Print job statuses
+const (
+	PrintJobStatusPending   = "pending"   // Waiting in the queue of its printer
+	PrintJobStatusPrinting  = "printing"  // Being sent to the printer
+	PrintJobStatusRetrying  = "retrying"  // Last attempt failed, will be retried at NextAttemptAt
+	PrintJobStatusPrinted   = "printed"   // Accepted by the printer (or its backup)
+	PrintJobStatusFailed    = "failed"    // Gave up after MaxAttempts
+	PrintJobStatusCancelled = "cancelled" // Cancelled by the user before printing
+	PrintJobStatusUnknown   = "unknown"   // The printer stopped answering during the write; it may have printed, so it is not retried automatically
+)
+
+// PrintJob is a document in the print spooler queue of a printer.
+// Payload holds the rendered ESC/POS bytes so the job can be retried,
+// sent to the backup printer or reprinted later without rendering it again
+type PrintJob struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	PrinterID     uint       `gorm:"index" json:"printer_id"`     // Target printer
+	PrintedOnID   *uint      `json:"printed_on_id,omitempty"`     // Printer that actually printed it (backup on failover)
+	JobType       string     `gorm:"index" json:"job_type"`       // "receipt", "invoice", "kitchen", ...
+	Description   string     `json:"description"`                 // Human readable summary, e.g. "Comanda orden ORD-0012"
+	ReferenceType string     `gorm:"index" json:"reference_type"` // "sale", "order", "cash_register_report"...
+	ReferenceID   uint       `gorm:"index" json:"reference_id"`
+	Payload       string     `gorm:"type:text" json:"-"` // Base64 encoded ESC/POS data
+	Size          int        `json:"size"`
+	Status        string     `gorm:"index;default:'pending'" json:"status"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `gorm:"default:5" json:"max_attempts"`
+	NextAttemptAt *time.Time `gorm:"index" json:"next_attempt_at,omitempty"`
+	LastError     string     `json:"last_error"`
+	PrintedAt     *time.Time `json:"printed_at,omitempty"`
+	IsReprint     bool       `json:"is_reprint"`
+	ReprintOfID   *uint      `json:"reprint_of_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -35,6 +35,7 @@ type Category struct {
 	Description  string         `json:"description"`
 	Icon         string         `json:"icon"`
 	Color        string         `json:"color"` // For UI display
+	KitchenStation string       `json:"kitchen_station"` // Kitchen station that prepares this category ("grill", "bar", ...); empty uses category name
 	DisplayOrder int            `json:"display_order"`
 	IsActive     bool           `gorm:"default:true" json:"is_active"`
 	Products     []Product      `json:"products,omitempty"`
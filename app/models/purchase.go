@@ -0,0 +1,132 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Supplier represents a vendor the restaurant buys goods from
+// Identification fields mirror Customer so suppliers can be sent to DIAN the same way
+type Supplier struct {
+	ID                           uint           `gorm:"primaryKey" json:"id"`
+	IdentificationType           string         `json:"identification_type"` // NIT, CC, CE, etc.
+	IdentificationNumber         string         `gorm:"unique" json:"identification_number"`
+	DV                           *string        `json:"dv,omitempty"`                              // Digito verificación (solo para NIT)
+	Name                         string         `gorm:"not null" json:"name"`                      // Razón social o nombre completo
+	Email                        string         `json:"email"`                                     // Email principal
+	Phone                        string         `json:"phone"`                                     // Teléfono de contacto
+	Address                      string         `json:"address"`                                   // Dirección física
+	MunicipalityID               *int           `json:"municipality_id,omitempty"`                 // Municipio
+	TypeDocumentIdentificationID *int           `json:"type_document_identification_id,omitempty"` // DIAN ID tipo documento (opcional - si no se envía usa default según IdentificationType)
+	TypeOrganizationID           *int           `json:"type_organization_id,omitempty"`            // DIAN: 1=Jurídica, 2=Natural
+	TypeLiabilityID              *int           `json:"type_liability_id,omitempty"`               // DIAN responsabilidades fiscales
+	TypeRegimeID                 *int           `json:"type_regime_id,omitempty"`                  // DIAN régimen tributario
+	MerchantRegistration         *string        `json:"merchant_registration,omitempty"`           // Matrícula mercantil
+	IssuesInvoices               bool           `gorm:"default:false" json:"issues_invoices"`      // false = purchases require a support document
+	IsActive                     bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt                    time.Time      `json:"created_at"`
+	UpdatedAt                    time.Time      `json:"updated_at"`
+	DeletedAt                    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// InventoryPurchase represents goods bought from a supplier and added to inventory
+type InventoryPurchase struct {
+	ID                    uint                    `gorm:"primaryKey" json:"id"`
+	PurchaseNumber        string                  `gorm:"unique;not null" json:"purchase_number"`
+	SupplierID            uint                    `gorm:"index" json:"supplier_id"`
+	Supplier              *Supplier               `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
+	SupplierInvoiceNumber string                  `json:"supplier_invoice_number"` // Supplier's own invoice, when it issues one
+	Items                 []InventoryPurchaseItem `gorm:"foreignKey:PurchaseID" json:"items"`
+	Withholdings          []PurchaseWithholding   `gorm:"foreignKey:PurchaseID" json:"withholdings"`
+	Subtotal              float64                 `json:"subtotal"`
+	Tax                   float64                 `json:"tax"`
+	WithholdingTotal      float64                 `json:"withholding_total"`
+	Total                 float64                 `json:"total"`                                 // Amount paid to the supplier (subtotal + tax - withholdings)
+	PaymentMethodCode     int                     `gorm:"default:10" json:"payment_method_code"` // DIAN payment method code (10 = Efectivo)
+	NeedsSupportDocument  bool                    `json:"needs_support_document"`
+	SupportDocument       *SupportDocument        `gorm:"foreignKey:PurchaseID" json:"support_document,omitempty"`
+	EmployeeID            *uint                   `gorm:"index" json:"employee_id,omitempty"`
+	Employee              *Employee               `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
+	Notes                 string                  `json:"notes"`
+	PurchasedAt           time.Time               `json:"purchased_at"`
+	CreatedAt             time.Time               `json:"created_at"`
+	UpdatedAt             time.Time               `json:"updated_at"`
+}
+
+// InventoryPurchaseItem represents a purchased line, optionally linked to an ingredient or product
+type InventoryPurchaseItem struct {
+	ID            uint        `gorm:"primaryKey" json:"id"`
+	PurchaseID    uint        `gorm:"index" json:"purchase_id"`
+	IngredientID  *uint       `gorm:"index" json:"ingredient_id,omitempty"`
+	Ingredient    *Ingredient `gorm:"foreignKey:IngredientID" json:"ingredient,omitempty"`
+	ProductID     *uint       `gorm:"index" json:"product_id,omitempty"`
+	Product       *Product    `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Description   string      `json:"description"`
+	Quantity      float64     `json:"quantity"`
+	UnitMeasureID int         `gorm:"default:70" json:"unit_measure_id"` // DIAN unit measure (70 = Unidad)
+	UnitPrice     float64     `json:"unit_price"`
+	TaxPercent    float64     `json:"tax_percent"` // IVA charged by the supplier (usually 0)
+	TaxAmount     float64     `json:"tax_amount"`
+	Subtotal      float64     `json:"subtotal"`
+}
+
+// DIAN withholding tax IDs used in support documents
+const (
+	WithholdingReteIVA   = 5
+	WithholdingReteRenta = 6
+	WithholdingReteICA   = 7
+)
+
+// PurchaseWithholding represents a tax withheld from the supplier payment
+type PurchaseWithholding struct {
+	ID            uint    `gorm:"primaryKey" json:"id"`
+	PurchaseID    uint    `gorm:"index" json:"purchase_id"`
+	TaxID         int     `json:"tax_id"` // 5 = ReteIVA, 6 = ReteRenta (ReteFuente), 7 = ReteICA
+	Percent       float64 `json:"percent"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	Amount        float64 `json:"amount"`
+}
+
+// SupportDocument represents a DIAN electronic support document (Documento Soporte)
+// issued for purchases from suppliers not obliged to invoice
+type SupportDocument struct {
+	ID                  uint                            `gorm:"primaryKey" json:"id"`
+	PurchaseID          uint                            `gorm:"unique" json:"purchase_id"`
+	Purchase            *InventoryPurchase              `json:"-"`
+	Number              string                          `json:"number"`
+	Prefix              string                          `json:"prefix"`
+	CUDS                string                          `json:"cuds"` // Código Único de Documento Soporte
+	QRCode              string                          `json:"qr_code"`
+	ZipKey              string                          `json:"zip_key"`
+	Status              string                          `json:"status"` // "sent", "validating", "accepted", "rejected", "error"
+	IsValid             *bool                           `json:"is_valid,omitempty"`
+	ValidationMessage   string                          `json:"validation_message"`
+	DIANResponse        string                          `gorm:"type:text" json:"dian_response"`
+	RequestData         string                          `gorm:"type:text" json:"request_data"`
+	SentAt              *time.Time                      `json:"sent_at,omitempty"`
+	AcceptedAt          *time.Time                      `json:"accepted_at,omitempty"`
+	ValidationCheckedAt *time.Time                      `json:"validation_checked_at,omitempty"`
+	LastError           string                          `json:"last_error"`
+	AdjustmentNotes     []SupportDocumentAdjustmentNote `json:"adjustment_notes,omitempty"`
+	CreatedAt           time.Time                       `json:"created_at"`
+	UpdatedAt           time.Time                       `json:"updated_at"`
+}
+
+// SupportDocumentAdjustmentNote represents a DIAN adjustment note to a support document
+type SupportDocumentAdjustmentNote struct {
+	ID                uint             `gorm:"primaryKey" json:"id"`
+	SupportDocumentID uint             `gorm:"index" json:"support_document_id"`
+	SupportDocument   *SupportDocument `json:"-"`
+	Number            string           `json:"number"`
+	Prefix            string           `json:"prefix"`
+	CUDS              string           `json:"cuds"`
+	Reason            string           `json:"reason"`
+	DiscrepancyCode   int              `json:"discrepancy_code"`
+	Amount            float64          `json:"amount"`
+	Status            string           `json:"status"`
+	DIANResponse      string           `gorm:"type:text" json:"dian_response"`
+	RequestData       string           `gorm:"type:text" json:"request_data"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ReceiptTemplate is a printable layout written as a Go template over the receipt
+// document model. DocumentType uses the print job types ("invoice", "receipt",
+// "kitchen", "order", "cash_register_report"). A template bound to a printer takes
+// precedence over the one for all printers; without an active template the
+// built-in layout is printed
+type ReceiptTemplate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Name         string    `gorm:"not null" json:"name"`
+	DocumentType string    `gorm:"index;not null" json:"document_type"`
+	PrinterID    *uint     `gorm:"index" json:"printer_id,omitempty"` // nil = all printers
+	Content      string    `gorm:"type:text" json:"content"`
+	IsActive     bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
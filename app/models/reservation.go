@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReservationStatus represents the status of a table reservation
+type ReservationStatus string
+
+const (
+	ReservationStatusConfirmed ReservationStatus = "confirmed"
+	ReservationStatusSeated    ReservationStatus = "seated"
+	ReservationStatusCompleted ReservationStatus = "completed"
+	ReservationStatusCancelled ReservationStatus = "cancelled"
+	ReservationStatusNoShow    ReservationStatus = "no_show"
+)
+
+// Reservation represents a future table booking
+type Reservation struct {
+	ID              uint              `gorm:"primaryKey" json:"id"`
+	CustomerID      *uint             `gorm:"index" json:"customer_id,omitempty"`
+	Customer        *Customer         `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+	CustomerName    string            `gorm:"not null" json:"customer_name"` // Name for the booking (copied from customer if linked)
+	CustomerPhone   string            `json:"customer_phone"`
+	PartySize       int               `gorm:"not null" json:"party_size"`
+	ReservedAt      time.Time         `gorm:"index;not null" json:"reserved_at"`  // Booking start time
+	DurationMinutes int               `gorm:"default:90" json:"duration_minutes"` // Expected table occupation
+	TableID         *uint             `gorm:"index" json:"table_id,omitempty"`
+	Table           *Table            `gorm:"foreignKey:TableID" json:"table,omitempty"`
+	AreaID          *uint             `json:"area_id,omitempty"` // Preferred area (used for table suggestions)
+	Area            *TableArea        `gorm:"foreignKey:AreaID" json:"area,omitempty"`
+	Status          ReservationStatus `gorm:"index;default:'confirmed'" json:"status"`
+	Notes           string            `json:"notes"`
+	Source          string            `json:"source"` // "pos", "phone", "waiter_app", "online"
+	EmployeeID      *uint             `json:"employee_id,omitempty"`
+	Employee        *Employee         `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
+	TableHeldAt     *time.Time        `json:"table_held_at,omitempty"` // When the table was switched to "reserved"
+	SeatedAt        *time.Time        `json:"seated_at,omitempty"`
+	CancelledAt     *time.Time        `json:"cancelled_at,omitempty"`
+	CancelReason    string            `json:"cancel_reason,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt    `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// EndsAt returns the time the reservation is expected to free the table
+func (r *Reservation) EndsAt() time.Time {
+	return r.ReservedAt.Add(time.Duration(r.DurationMinutes) * time.Minute)
+}
+
+// IsActive returns true if the reservation still blocks its table
+func (r *Reservation) IsActive() bool {
+	return r.Status == ReservationStatusConfirmed || r.Status == ReservationStatusSeated
+}
+
+// WaitlistStatus represents the status of a walk-in waitlist entry
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWaiting  WaitlistStatus = "waiting"
+	WaitlistStatusNotified WaitlistStatus = "notified" // Table is ready, party was called
+	WaitlistStatusSeated   WaitlistStatus = "seated"
+	WaitlistStatusLeft     WaitlistStatus = "left"
+)
+
+// WaitlistEntry represents a walk-in party waiting for a table
+type WaitlistEntry struct {
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	CustomerID        *uint          `gorm:"index" json:"customer_id,omitempty"`
+	Customer          *Customer      `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+	CustomerName      string         `gorm:"not null" json:"customer_name"`
+	CustomerPhone     string         `json:"customer_phone"`
+	PartySize         int            `gorm:"not null" json:"party_size"`
+	AreaID            *uint          `json:"area_id,omitempty"` // Preferred area
+	Status            WaitlistStatus `gorm:"index;default:'waiting'" json:"status"`
+	QuotedWaitMinutes int            `json:"quoted_wait_minutes"` // Wait time quoted to the party when added
+	Position          int            `gorm:"-" json:"position"`   // Current position in the waitlist (computed)
+	TableID           *uint          `json:"table_id,omitempty"`  // Table where the party was seated
+	Table             *Table         `gorm:"foreignKey:TableID" json:"table,omitempty"`
+	Notes             string         `json:"notes"`
+	NotifiedAt        *time.Time     `json:"notified_at,omitempty"`
+	SeatedAt          *time.Time     `json:"seated_at,omitempty"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
@@ -18,6 +18,7 @@ type Sale struct {
 	Tax                    float64            `json:"tax"`
 	Discount               float64            `json:"discount"`
 	ServiceCharge          float64            `json:"service_charge"` // Cargo por servicio
+	Tip                    float64            `json:"tip"`            // Propina voluntaria (not part of Total, not invoiced)
 	Total                  float64            `json:"total"`
 	PaymentMethod          string             `json:"payment_method"`
 	PaymentDetails         []Payment          `gorm:"foreignKey:SaleID" json:"payment_details"`
@@ -38,18 +39,75 @@ type Sale struct {
 
 // Payment represents payment details for a sale
 type Payment struct {
-	ID              uint                `gorm:"primaryKey" json:"id"`
-	SaleID          uint                `gorm:"index" json:"sale_id"`
-	Sale            *Sale               `gorm:"foreignKey:SaleID" json:"-"`
-	PaymentMethodID uint                `gorm:"index" json:"payment_method_id"`
-	PaymentMethod   *PaymentMethod      `gorm:"foreignKey:PaymentMethodID" json:"payment_method,omitempty"`
-	Amount          float64             `json:"amount"`
-	Reference       string              `json:"reference"`             // Transaction ID, check number, etc.
-	VoucherImage    string              `gorm:"type:text" json:"voucher_image,omitempty"` // Base64 encoded voucher image or file path
-	Allocations     []PaymentAllocation `gorm:"foreignKey:PaymentID" json:"allocations,omitempty"` // Product allocations for split payments
-	CreatedAt       time.Time           `json:"created_at"`
+	ID                   uint                `gorm:"primaryKey" json:"id"`
+	SaleID               uint                `gorm:"index" json:"sale_id"`
+	Sale                 *Sale               `gorm:"foreignKey:SaleID" json:"-"`
+	PaymentMethodID      uint                `gorm:"index" json:"payment_method_id"`
+	PaymentMethod        *PaymentMethod      `gorm:"foreignKey:PaymentMethodID" json:"payment_method,omitempty"`
+	Amount               float64             `json:"amount"`
+	TipAmount            float64             `json:"tip_amount"`                                    // Voluntary tip charged on top of Amount
+	Reference            string              `json:"reference"`                                     // Transaction ID, check number, etc.
+	VoucherImage         string              `gorm:"type:text" json:"voucher_image,omitempty"`      // Base64 encoded voucher image or file path
+	GatewayID            string              `gorm:"index" json:"gateway_id,omitempty"`             // Payment gateway that processed the payment ("bold", "mock", ...)
+	GatewayTransactionID string              `gorm:"index" json:"gateway_transaction_id,omitempty"` // Transaction ID at the gateway
+	BoldIntegrationID    string              `gorm:"index" json:"bold_integration_id,omitempty"`    // Bold integration_id when paid through a Bold terminal
+	Status               string              `gorm:"default:'completed'" json:"status"`             // "completed", "void_pending", "voided", "partially_voided", "void_rejected"
+	VoidAmount           float64             `json:"void_amount"`                                   // Amount requested or returned through the gateway by refunds
+	VoidedAt             *time.Time          `json:"voided_at,omitempty"`
+	Allocations          []PaymentAllocation `gorm:"foreignKey:PaymentID" json:"allocations,omitempty"` // Product allocations for split payments
+	CreatedAt            time.Time           `json:"created_at"`
 }
 
+// Payment statuses
+const (
+	PaymentStatusCompleted    = "completed"
+	PaymentStatusVoidPending  = "void_pending"
+	PaymentStatusVoided       = "voided"
+	PaymentStatusPartlyVoided = "partially_voided"
+	PaymentStatusVoidRejected = "void_rejected"
+)
+
+// SaleRefund tracks a refund from the request until the gateways accepted its voids
+// The sale, inventory and cash drawer are only updated once every void was accepted
+type SaleRefund struct {
+	ID           uint             `gorm:"primaryKey" json:"id"`
+	SaleID       uint             `gorm:"index" json:"sale_id"`
+	Amount       float64          `json:"amount"`
+	CashAmount   float64          `json:"cash_amount"` // Part of Amount that comes out of the cash drawer
+	Reason       string           `json:"reason"`
+	EmployeeID   uint             `json:"employee_id"`
+	Status       string           `gorm:"index" json:"status"` // "pending", "completed", "failed", "void_rejected"
+	ErrorMessage string           `gorm:"type:text" json:"error_message,omitempty"`
+	Voids        []SaleRefundVoid `gorm:"foreignKey:RefundID" json:"voids,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// SaleRefundVoid is a gateway void requested by a refund
+type SaleRefundVoid struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	RefundID      uint      `gorm:"index" json:"refund_id"`
+	PaymentID     uint      `gorm:"index" json:"payment_id"`
+	GatewayID     string    `json:"gateway_id"`
+	TransactionID string    `json:"transaction_id"`
+	Amount        float64   `json:"amount"`              // Amount sent to the gateway (includes the tip on full refunds)
+	SaleAmount    float64   `json:"sale_amount"`         // Part of Amount that counts toward the refund
+	PaymentStatus string    `json:"payment_status"`      // Status of the payment before the void, restored if the call fails
+	Status        string    `gorm:"index" json:"status"` // "pending", "requested", "voided", "rejected", "failed"
+	ErrorMessage  string    `gorm:"type:text" json:"error_message,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Sale refund void statuses
+const (
+	RefundVoidPending   = "pending"   // Persisted, not sent to the gateway yet
+	RefundVoidRequested = "requested" // Accepted by the gateway, waiting for confirmation
+	RefundVoidVoided    = "voided"
+	RefundVoidRejected  = "rejected"
+	RefundVoidFailed    = "failed" // The gateway call failed, sent again on retry
+)
+
 // PaymentAllocation represents payment allocation to specific order items (for split payments)
 type PaymentAllocation struct {
 	ID          uint       `gorm:"primaryKey" json:"id"`
@@ -79,6 +137,9 @@ type PaymentMethod struct {
 	// Bold integration
 	UseBoldTerminal      bool      `gorm:"default:false" json:"use_bold_terminal"` // Whether to process this payment through Bold datáfono
 	BoldPaymentMethod    string    `json:"bold_payment_method"`   // Bold payment method type: "POS", "NEQUI", "DAVIPLATA", "PAY_BY_LINK"
+	// Payment gateway (card acquirer) used to charge this method
+	GatewayID            string    `json:"gateway_id"`                      // Registered gateway ID: "bold", "mock", ... (empty = no gateway)
+	GatewayConfig        string    `gorm:"type:text" json:"gateway_config"` // Gateway specific configuration (JSON)
 	CreatedAt            time.Time `json:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at"`
 }
@@ -110,13 +171,14 @@ type ElectronicInvoice struct {
 	ID                   uint         `gorm:"primaryKey" json:"id"`
 	SaleID               uint         `gorm:"unique" json:"sale_id"`
 	Sale                 *Sale        `json:"-"`
+	DocumentType         string       `gorm:"default:'invoice'" json:"document_type"` // "invoice", "pos_equivalent"
 	InvoiceNumber        string       `json:"invoice_number"`
 	Prefix               string       `json:"prefix"`
 	UUID                 *string      `json:"uuid,omitempty"`           // Optional UUID from DIAN (not generated, only stored if DIAN sends it)
 	CUFE                 string       `json:"cufe"`                     // Código Único de Facturación Electrónica (always present, used as unique ID)
 	QRCode               string       `json:"qr_code"`                  // QR code URL or data
 	ZipKey               string       `json:"zip_key"`                  // ZIP key for status verification
-	Status               string       `json:"status"`                   // "pending", "sent", "accepted", "rejected", "validating"
+	Status               string       `json:"status"`                   // "pending", "contingency", "contingency_reporting", "sent", "accepted", "rejected", "validating"
 	IsValid              *bool        `json:"is_valid,omitempty"`       // DIAN validation result
 	ValidationMessage    string       `json:"validation_message"`       // DIAN validation message
 	DIANResponse         string       `gorm:"type:text" json:"dian_response"` // JSON response from DIAN
@@ -128,6 +190,10 @@ type ElectronicInvoice struct {
 	RequestData          string       `gorm:"type:text" json:"request_data"` // JSON request sent to DIAN API
 	RetryCount           int          `json:"retry_count"`
 	LastError            string       `json:"last_error"`
+	IsContingency        bool         `gorm:"default:false" json:"is_contingency"`      // Issued with the contingency range during an outage
+	ContingencyEventID   *uint        `gorm:"index" json:"contingency_event_id,omitempty"` // Outage in which it was issued
+	ReportedAt           *time.Time   `json:"reported_at,omitempty"`    // When a contingency invoice was reported to DIAN
+	EmailPending         bool         `gorm:"default:false" json:"email_pending"` // Email it from our SMTP server once DIAN accepts it
 	CreditNotes          []CreditNote `json:"credit_notes,omitempty"`
 	DebitNotes           []DebitNote  `json:"debit_notes,omitempty"`
 	CreatedAt            time.Time    `json:"created_at"`
@@ -169,3 +235,21 @@ type DebitNote struct {
 	CreatedAt           time.Time          `json:"created_at"`
 	UpdatedAt           time.Time          `json:"updated_at"`
 }
+
+// ContingencyEvent represents an outage window of the DIAN provider during which
+// invoices were issued in contingency mode
+type ContingencyEvent struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	StartedAt         time.Time  `json:"started_at"`
+	EndedAt           *time.Time `json:"ended_at,omitempty"`
+	Reason            string     `json:"reason"`       // Error that triggered the contingency or reason given by the user
+	ActivatedBy       string     `json:"activated_by"` // "auto" or "manual"
+	EmployeeID        *uint      `json:"employee_id,omitempty"`
+	InvoicesIssued    int        `json:"invoices_issued"`           // Contingency invoices issued during the outage
+	InvoicesReported  int        `json:"invoices_reported"`         // Contingency invoices already reported to DIAN
+	ReportDeadline    *time.Time `json:"report_deadline,omitempty"` // Invoices must be reported before this time
+	ReportCompletedAt *time.Time `json:"report_completed_at,omitempty"`
+	Notes             string     `json:"notes"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TimeClockStatus represents the state of a time clock entry
+type TimeClockStatus string
+
+const (
+	TimeClockStatusWorking TimeClockStatus = "working"
+	TimeClockStatusOnBreak TimeClockStatus = "on_break"
+	TimeClockStatusClosed  TimeClockStatus = "closed"
+)
+
+// TimeClockEntry represents a single clock-in/clock-out period of an employee
+type TimeClockEntry struct {
+	ID         uint             `gorm:"primaryKey" json:"id"`
+	EmployeeID uint             `gorm:"index;not null" json:"employee_id"`
+	Employee   *Employee        `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
+	ClockIn    time.Time        `gorm:"index;not null" json:"clock_in"`
+	ClockOut   *time.Time       `json:"clock_out,omitempty"`
+	Status     TimeClockStatus  `gorm:"index;default:'working'" json:"status"`
+	Breaks     []TimeClockBreak `gorm:"foreignKey:EntryID" json:"breaks,omitempty"`
+	Source     string           `json:"source"` // "pin", "manual"
+	Notes      string           `json:"notes"`
+	EditedBy   *uint            `json:"edited_by,omitempty"` // Manager who last edited the entry
+	EditedAt   *time.Time       `json:"edited_at,omitempty"`
+	EditReason string           `json:"edit_reason,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt   `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// TimeClockBreak represents a break taken during a time clock entry
+type TimeClockBreak struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	EntryID   uint       `gorm:"index;not null" json:"entry_id"`
+	StartAt   time.Time  `json:"start_at"`
+	EndAt     *time.Time `json:"end_at,omitempty"`
+	Paid      bool       `gorm:"default:false" json:"paid"` // Paid breaks count as worked time
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ShiftSchedule represents a planned shift for an employee
+type ShiftSchedule struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	EmployeeID uint           `gorm:"index;not null" json:"employee_id"`
+	Employee   *Employee      `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
+	Role       string         `gorm:"index" json:"role"` // Role covered in this shift ("cashier", "waiter", "kitchen", ...)
+	StartAt    time.Time      `gorm:"index;not null" json:"start_at"`
+	EndAt      time.Time      `gorm:"not null" json:"end_at"`
+	Notes      string         `json:"notes"`
+	CreatedBy  *uint          `json:"created_by,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
@@ -77,33 +77,11 @@ func Encrypt(plaintext string) (string, error) {
 		return "", nil
 	}
 
-	// Get or generate encryption key
-	key, err := GenerateKeyIfNotExists()
+	ciphertext, err := EncryptBytes([]byte(plaintext))
 	if err != nil {
 		return "", err
 	}
 
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", fmt.Errorf("could not create cipher: %w", err)
-	}
-
-	// Create GCM mode
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", fmt.Errorf("could not create GCM: %w", err)
-	}
-
-	// Generate nonce
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", fmt.Errorf("could not generate nonce: %w", err)
-	}
-
-	// Encrypt
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
-
 	// Encode to base64 for JSON storage
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
@@ -114,45 +92,81 @@ func Decrypt(ciphertext string) (string, error) {
 		return "", nil
 	}
 
-	// Get encryption key
-	key, err := GenerateKeyIfNotExists()
-	if err != nil {
-		return "", err
-	}
-
 	// Decode from base64
 	data, err := base64.StdEncoding.DecodeString(ciphertext)
 	if err != nil {
 		return "", fmt.Errorf("could not decode ciphertext: %w", err)
 	}
 
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
+	plaintext, err := DecryptBytes(data)
 	if err != nil {
-		return "", fmt.Errorf("could not create cipher: %w", err)
+		return "", err
 	}
 
-	// Create GCM mode
-	gcm, err := cipher.NewGCM(block)
+	return string(plaintext), nil
+}
+
+// EncryptBytes encrypts binary data (e.g. backup files) using AES-GCM with the application key
+// The nonce is prepended to the returned ciphertext
+func EncryptBytes(plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM()
 	if err != nil {
-		return "", fmt.Errorf("could not create GCM: %w", err)
+		return nil, err
+	}
+
+	// Generate nonce
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("could not generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// DecryptBytes decrypts data produced by EncryptBytes
+func DecryptBytes(data []byte) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
 	}
 
 	// Extract nonce
 	nonceSize := gcm.NonceSize()
 	if len(data) < nonceSize {
-		return "", fmt.Errorf("ciphertext too short")
+		return nil, fmt.Errorf("ciphertext too short")
 	}
 
 	nonce, cipherData := data[:nonceSize], data[nonceSize:]
 
-	// Decrypt
 	plaintext, err := gcm.Open(nil, nonce, cipherData, nil)
 	if err != nil {
-		return "", fmt.Errorf("could not decrypt: %w", err)
+		return nil, fmt.Errorf("could not decrypt: %w", err)
 	}
 
-	return string(plaintext), nil
+	return plaintext, nil
+}
+
+// newGCM creates the AES-GCM cipher for the application key
+func newGCM() (cipher.AEAD, error) {
+	// Get or generate encryption key
+	key, err := GenerateKeyIfNotExists()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create AES cipher
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cipher: %w", err)
+	}
+
+	// Create GCM mode
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("could not create GCM: %w", err)
+	}
+
+	return gcm, nil
 }
 
 // EncryptIfNeeded encrypts a value only if it's not already encrypted
@@ -0,0 +1,166 @@
+package security
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// streamChunkSize is the plaintext size of each encrypted chunk of a stream
+const streamChunkSize = 64 * 1024
+
+// Each chunk is written as: 4-byte length, 1-byte final flag, nonce + sealed data
+// The chunk index and the final flag are authenticated, so chunks can't be
+// reordered, dropped or truncated without the decryption failing
+const streamHeaderSize = 5
+
+// streamWriter encrypts data in chunks using AES-GCM with the application key
+type streamWriter struct {
+	w      io.Writer
+	gcm    cipher.AEAD
+	buf    []byte
+	index  uint64
+	closed bool
+}
+
+// NewEncryptWriter returns a writer that encrypts everything written to it into w
+// Close must be called to write the final chunk; it does not close w
+func NewEncryptWriter(w io.Writer) (io.WriteCloser, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+	return &streamWriter{w: w, gcm: gcm, buf: make([]byte, 0, streamChunkSize)}, nil
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("write to closed encrypt writer")
+	}
+	written := 0
+	for len(p) > 0 {
+		n := copy(s.buf[len(s.buf):cap(s.buf)], p)
+		s.buf = s.buf[:len(s.buf)+n]
+		p = p[n:]
+		written += n
+		if len(s.buf) == cap(s.buf) {
+			if err := s.writeChunk(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (s *streamWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.writeChunk(true)
+}
+
+func (s *streamWriter) writeChunk(final bool) error {
+	nonce := make([]byte, s.gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("could not generate nonce: %w", err)
+	}
+	sealed := s.gcm.Seal(nonce, nonce, s.buf, streamChunkAAD(s.index, final))
+
+	header := make([]byte, streamHeaderSize)
+	binary.BigEndian.PutUint32(header, uint32(len(sealed)))
+	if final {
+		header[4] = 1
+	}
+	if _, err := s.w.Write(header); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(sealed); err != nil {
+		return err
+	}
+	s.index++
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// streamReader decrypts a stream written by NewEncryptWriter
+type streamReader struct {
+	r     *bufio.Reader
+	gcm   cipher.AEAD
+	plain []byte
+	index uint64
+	done  bool
+}
+
+// NewDecryptReader returns a reader that decrypts a stream written by NewEncryptWriter
+// Reading fails if the stream was modified or truncated
+func NewDecryptReader(r io.Reader) (io.Reader, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+	return &streamReader{r: bufio.NewReader(r), gcm: gcm}, nil
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	for len(s.plain) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := s.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.plain)
+	s.plain = s.plain[n:]
+	return n, nil
+}
+
+func (s *streamReader) readChunk() error {
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(s.r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return errors.New("encrypted stream is truncated")
+		}
+		return err
+	}
+	size := binary.BigEndian.Uint32(header)
+	final := header[4] == 1
+	if size < uint32(s.gcm.NonceSize()+s.gcm.Overhead()) || size > uint32(streamChunkSize+s.gcm.NonceSize()+s.gcm.Overhead()) {
+		return fmt.Errorf("invalid encrypted chunk size %d", size)
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(s.r, sealed); err != nil {
+		return errors.New("encrypted stream is truncated")
+	}
+	nonceSize := s.gcm.NonceSize()
+	plain, err := s.gcm.Open(nil, sealed[:nonceSize], sealed[nonceSize:], streamChunkAAD(s.index, final))
+	if err != nil {
+		return fmt.Errorf("could not decrypt: %w", err)
+	}
+	s.index++
+	s.plain = plain
+
+	if final {
+		if _, err := s.r.ReadByte(); err != io.EOF {
+			return errors.New("unexpected data after the end of the encrypted stream")
+		}
+		s.done = true
+	}
+	return nil
+}
+
+// streamChunkAAD binds a chunk to its position in the stream and marks the last one
+func streamChunkAAD(index uint64, final bool) []byte {
+	aad := make([]byte, 9)
+	binary.BigEndian.PutUint64(aad, index)
+	if final {
+		aad[8] = 1
+	}
+	return aad
+}
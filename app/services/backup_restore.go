@@ -0,0 +1,550 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"PosApp/app/database"
+	"PosApp/app/security"
+
+	"gorm.io/gorm"
+)
+
+// BackupCheck is the result of checking a backup against the current database schema
+type BackupCheck struct {
+	FileName   string   `json:"file_name"`
+	AppVersion string   `json:"app_version"`
+	Reason     string   `json:"reason"`
+	CreatedAt  string   `json:"created_at"`
+	Tables     int      `json:"tables"`
+	Rows       int64    `json:"rows"`
+	Compatible bool     `json:"compatible"`
+	Errors     []string `json:"errors"`   // Problems that prevent the restore
+	Warnings   []string `json:"warnings"` // Data that will not be restored
+	Restored   bool     `json:"restored"`
+	SafetyCopy string   `json:"safety_copy,omitempty"` // Backup of the replaced data
+}
+
+// dbSchema maps table names to their columns
+type dbSchema map[string][]backupColumn
+
+// tableNames returns the tables of the schema sorted by name
+func (schema dbSchema) tableNames() []string {
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// column returns a column of a table
+func (schema dbSchema) column(table, name string) (backupColumn, bool) {
+	for _, column := range schema[table] {
+		if column.Name == name {
+			return column, true
+		}
+	}
+	return backupColumn{}, false
+}
+
+// currentSchema reads the tables and columns of the current database schema
+func currentSchema(tx *gorm.DB) (dbSchema, error) {
+	var columns []struct {
+		TableName     string
+		ColumnName    string
+		DataType      string
+		IsNullable    string
+		ColumnDefault *string
+	}
+	err := tx.Raw(`
+		SELECT c.table_name, c.column_name, c.data_type, c.is_nullable, c.column_default
+		FROM information_schema.columns c
+		JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = current_schema() AND t.table_type = 'BASE TABLE'
+		ORDER BY c.table_name, c.ordinal_position
+	`).Scan(&columns).Error
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo el esquema de la base de datos: %w", err)
+	}
+
+	schema := dbSchema{}
+	for _, c := range columns {
+		schema[c.TableName] = append(schema[c.TableName], backupColumn{
+			Name:       c.ColumnName,
+			Type:       c.DataType,
+			Nullable:   c.IsNullable == "YES",
+			HasDefault: c.ColumnDefault != nil,
+		})
+	}
+	return schema, nil
+}
+
+// backupReader reads the manifest and data blocks of a backup file
+type backupReader struct {
+	manifest *backupManifest
+	decoder  *json.Decoder
+	file     *os.File
+}
+
+// openBackup decrypts a backup file as it is read and returns a reader positioned at the first data block
+func openBackup(path string) (*backupReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo la copia: %w", err)
+	}
+	reader, err := decryptBackup(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	gz, err := gzip.NewReader(reader)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("la copia está dañada: %w", err)
+	}
+
+	decoder := json.NewDecoder(gz)
+	var manifest backupManifest
+	if err := decoder.Decode(&manifest); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("la copia está dañada: %w", err)
+	}
+	return &backupReader{manifest: &manifest, decoder: decoder, file: file}, nil
+}
+
+// decryptBackup checks the header of a backup file and returns its decrypted content
+// Backups of older versions were encrypted as a single block and are decrypted in memory
+func decryptBackup(file *os.File) (io.Reader, error) {
+	magic := make([]byte, len(backupMagic))
+	if _, err := io.ReadFull(file, magic); err != nil || (!bytes.Equal(magic, backupMagic) && !bytes.Equal(magic, backupMagicV1)) {
+		return nil, fmt.Errorf("el archivo no es una copia de seguridad de PosApp")
+	}
+
+	if bytes.Equal(magic, backupMagicV1) {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo la copia: %w", err)
+		}
+		payload, err := security.DecryptBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo descifrar la copia (¿fue creada en otro equipo con otra clave?): %w", err)
+		}
+		return bytes.NewReader(payload), nil
+	}
+
+	decrypted, err := security.NewDecryptReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo descifrar la copia: %w", err)
+	}
+	// Decrypt the first chunk now, so a copy made with another key is reported as such
+	reader := bufio.NewReader(decrypted)
+	if _, err := reader.Peek(1); err != nil {
+		return nil, fmt.Errorf("no se pudo descifrar la copia (¿fue creada en otro equipo con otra clave?): %w", err)
+	}
+	return reader, nil
+}
+
+// next reads the next data block; it returns io.EOF after the last one
+func (r *backupReader) next() (*backupBlock, error) {
+	var block backupBlock
+	if err := r.decoder.Decode(&block); err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, fmt.Errorf("la copia está dañada: %w", err)
+	}
+	return &block, nil
+}
+
+func (r *backupReader) Close() error {
+	return r.file.Close()
+}
+
+// checkCompatibility compares the schema of a backup with the current (migrated) schema
+func checkCompatibility(manifest *backupManifest, current dbSchema) (errs []string, warnings []string) {
+	if manifest.Format > backupFormatVersion {
+		errs = append(errs, fmt.Sprintf("formato de copia %d no soportado por esta versión", manifest.Format))
+	}
+	if manifest.AppVersion != "" && compareVersions(manifest.AppVersion, CurrentVersion) > 0 {
+		errs = append(errs, fmt.Sprintf("la copia fue creada con la versión %s, más reciente que la instalada (%s); actualiza la aplicación antes de restaurarla", manifest.AppVersion, CurrentVersion))
+	}
+
+	for _, table := range manifest.Tables {
+		if _, ok := current[table.Name]; !ok {
+			if table.Rows > 0 {
+				warnings = append(warnings, fmt.Sprintf("la tabla %s ya no existe; sus %d registros no se restaurarán", table.Name, table.Rows))
+			}
+			continue
+		}
+
+		backupColumns := map[string]backupColumn{}
+		for _, column := range table.Columns {
+			backupColumns[column.Name] = column
+			currentColumn, ok := current.column(table.Name, column.Name)
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("la columna %s.%s ya no existe y no se restaurará", table.Name, column.Name))
+				continue
+			}
+			if currentColumn.Type != column.Type {
+				warnings = append(warnings, fmt.Sprintf("la columna %s.%s cambió de tipo (%s → %s)", table.Name, column.Name, column.Type, currentColumn.Type))
+			}
+		}
+
+		if table.Rows == 0 {
+			continue
+		}
+		for _, column := range current[table.Name] {
+			if _, ok := backupColumns[column.Name]; !ok && !column.Nullable && !column.HasDefault {
+				errs = append(errs, fmt.Sprintf("la columna obligatoria %s.%s no existe en la copia", table.Name, column.Name))
+			}
+		}
+	}
+	return errs, warnings
+}
+
+// CheckBackup reports whether a backup can be restored on the current database schema
+func (s *BackupService) CheckBackup(fileName string) (*BackupCheck, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	path, err := s.backupPath(fileName)
+	if err != nil {
+		return nil, err
+	}
+	backup, err := openBackup(path)
+	if err != nil {
+		return nil, err
+	}
+	backup.Close()
+	current, err := currentSchema(s.db)
+	if err != nil {
+		return nil, err
+	}
+	check := newBackupCheck(fileName, backup.manifest, current)
+	if err := s.checkTablesNotInBackup(check, backup.manifest, current); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+func newBackupCheck(fileName string, manifest *backupManifest, current dbSchema) *BackupCheck {
+	check := &BackupCheck{
+		FileName:   fileName,
+		AppVersion: manifest.AppVersion,
+		Reason:     manifest.Reason,
+		CreatedAt:  manifest.CreatedAt.Format("2006-01-02 15:04:05"),
+		Tables:     len(manifest.Tables),
+		Errors:     []string{},
+		Warnings:   []string{},
+	}
+	for _, table := range manifest.Tables {
+		check.Rows += table.Rows
+	}
+	errs, warnings := checkCompatibility(manifest, current)
+	check.Errors = append(check.Errors, errs...)
+	check.Warnings = append(check.Warnings, warnings...)
+	check.Compatible = len(check.Errors) == 0
+	return check
+}
+
+// tablesNotInBackup returns the tables of the current schema that the backup does not contain
+func tablesNotInBackup(manifest *backupManifest, current dbSchema) []string {
+	inBackup := map[string]bool{}
+	for _, table := range manifest.Tables {
+		inBackup[table.Name] = true
+	}
+	var tables []string
+	for _, name := range current.tableNames() {
+		if !inBackup[name] {
+			tables = append(tables, name)
+		}
+	}
+	return tables
+}
+
+// checkTablesNotInBackup warns about the current rows of tables the backup does not contain;
+// the restore empties them so they don't reference replaced data
+func (s *BackupService) checkTablesNotInBackup(check *BackupCheck, manifest *backupManifest, current dbSchema) error {
+	for _, table := range tablesNotInBackup(manifest, current) {
+		var rows int64
+		if err := s.db.Table(table).Count(&rows).Error; err != nil {
+			return fmt.Errorf("error contando filas de %s: %w", table, err)
+		}
+		if rows > 0 {
+			check.Warnings = append(check.Warnings, fmt.Sprintf("la tabla %s no existe en la copia; sus %d registros actuales se eliminarán", table, rows))
+		}
+	}
+	return nil
+}
+
+// RestoreBackup replaces the data of the database with the data of a backup
+// The schema is migrated first (RunMigrations) and checked for compatibility; a safety
+// backup of the current data is created before anything is replaced. The application
+// must be restarted afterwards so services reload their cached configuration
+func (s *BackupService) RestoreBackup(fileName string) (*BackupCheck, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	path, err := s.backupPath(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	backupMu.Lock()
+	defer backupMu.Unlock()
+
+	backup, err := openBackup(path)
+	if err != nil {
+		return nil, err
+	}
+	backup.Close()
+	manifest := backup.manifest
+
+	// Bring the schema to the current version before comparing
+	if err := database.RunMigrations(); err != nil {
+		return nil, fmt.Errorf("error migrando la base de datos: %w", err)
+	}
+	current, err := currentSchema(s.db)
+	if err != nil {
+		return nil, err
+	}
+	check := newBackupCheck(fileName, manifest, current)
+	if !check.Compatible {
+		return check, fmt.Errorf("la copia no es compatible: %s", strings.Join(check.Errors, "; "))
+	}
+	if err := s.checkTablesNotInBackup(check, manifest, current); err != nil {
+		return nil, err
+	}
+
+	safety, err := s.createBackup(BackupReasonPreRestore)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo respaldar los datos actuales antes de restaurar: %w", err)
+	}
+	check.SafetyCopy = safety.FileName
+
+	parents, err := tableParents(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Skip foreign key triggers when the database user is allowed to (superuser);
+		// otherwise the rows are inserted in dependency order
+		checkReferences := false
+		tx.SavePoint("replication_role")
+		if err := tx.Exec("SET LOCAL session_replication_role = replica").Error; err != nil {
+			tx.RollbackTo("replication_role")
+			checkReferences = true
+		}
+
+		// Every table of the schema is emptied, listed explicitly: tables missing from the
+		// backup were reported by the check, and no other table can be wiped by a cascade
+		quoted := make([]string, 0, len(current))
+		for _, table := range current.tableNames() {
+			quoted = append(quoted, quoteIdent(table))
+		}
+		if len(quoted) > 0 {
+			if err := tx.Exec("TRUNCATE " + strings.Join(quoted, ", ")).Error; err != nil {
+				return fmt.Errorf("error vaciando las tablas: %w", err)
+			}
+		}
+
+		if err := restoreRows(tx, path, manifest, current, parents, checkReferences); err != nil {
+			return err
+		}
+
+		for _, table := range current.tableNames() {
+			if _, ok := current.column(table, "id"); !ok {
+				continue
+			}
+			if err := tx.Exec(fmt.Sprintf(
+				"SELECT setval(pg_get_serial_sequence(?, 'id'), COALESCE((SELECT MAX(id) FROM %s), 0) + 1, false)",
+				quoteIdent(table)), quoteIdent(table)).Error; err != nil {
+				return fmt.Errorf("error actualizando la secuencia de %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return check, fmt.Errorf("error restaurando la copia (no se modificaron los datos): %w", err)
+	}
+
+	check.Restored = true
+	log.Printf("Backup: restored %s (%d tables, %d rows), previous data saved in %s", fileName, check.Tables, check.Rows, safety.FileName)
+	return check, nil
+}
+
+// restoreRows inserts the rows of a backup as they are read from the file
+// When foreign keys are checked, a table is inserted only once the tables it references are
+// complete; backups list parents first so one pass is enough, otherwise the file is read again
+// for the tables that were skipped
+func restoreRows(tx *gorm.DB, path string, manifest *backupManifest, current dbSchema, parents map[string][]string, checkReferences bool) error {
+	pending := map[string]bool{}
+	for _, table := range manifest.Tables {
+		if _, ok := current[table.Name]; ok && table.Rows > 0 {
+			pending[table.Name] = true
+		}
+	}
+
+	force := false
+	for len(pending) > 0 {
+		inserted, err := restorePass(tx, path, manifest, current, parents, pending, checkReferences && !force)
+		if err != nil {
+			return err
+		}
+		if inserted == 0 && force {
+			return fmt.Errorf("la copia está dañada: faltan los datos de %d tablas", len(pending))
+		}
+		// Circular references: insert the remaining tables as they come
+		force = inserted == 0
+	}
+	return nil
+}
+
+// restorePass reads the backup once and inserts the pending tables whose parents are complete
+// It returns the number of tables inserted
+func restorePass(tx *gorm.DB, path string, manifest *backupManifest, current dbSchema, parents map[string][]string, pending map[string]bool, checkReferences bool) (int, error) {
+	backup, err := openBackup(path)
+	if err != nil {
+		return 0, err
+	}
+	defer backup.Close()
+
+	ready := map[string]bool{}
+	inserted := 0
+	previous := ""
+	for {
+		block, err := backup.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return inserted, err
+		}
+
+		// Tables are written contiguously, so a table is complete when the next one starts
+		if block.Table != previous {
+			if ready[previous] {
+				delete(pending, previous)
+				inserted++
+			}
+			previous = block.Table
+			ready[block.Table] = pending[block.Table] && (!checkReferences || !hasPendingParent(block.Table, parents, pending))
+		}
+		if !ready[block.Table] {
+			continue
+		}
+		if err := insertBackupRows(tx, block.Table, restoreColumns(manifest, current, block.Table), block.Rows); err != nil {
+			return inserted, fmt.Errorf("error restaurando %s: %w", block.Table, err)
+		}
+	}
+	if ready[previous] {
+		delete(pending, previous)
+		inserted++
+	}
+	return inserted, nil
+}
+
+// hasPendingParent reports whether a table references a table that is not restored yet
+func hasPendingParent(table string, parents map[string][]string, pending map[string]bool) bool {
+	for _, parent := range parents[table] {
+		if parent != table && pending[parent] {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreColumns returns the columns of a table present in both the backup and the current schema
+func restoreColumns(manifest *backupManifest, current dbSchema, table string) []string {
+	for _, t := range manifest.Tables {
+		if t.Name != table {
+			continue
+		}
+		var columns []string
+		for _, column := range t.Columns {
+			if _, ok := current.column(table, column.Name); ok {
+				columns = append(columns, quoteIdent(column.Name))
+			}
+		}
+		return columns
+	}
+	return nil
+}
+
+// insertBackupRows inserts row_to_json rows; PostgreSQL converts each value to the column type
+// and columns missing from the backup keep their defaults
+func insertBackupRows(tx *gorm.DB, table string, columns []string, rows []json.RawMessage) error {
+	if len(rows) == 0 || len(columns) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	list := strings.Join(columns, ", ")
+	query := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM json_populate_recordset(NULL::%s, ?::json)",
+		quoteIdent(table), list, list, quoteIdent(table))
+	return tx.Exec(query, string(data)).Error
+}
+
+// tableParents returns, for each table, the tables its foreign keys reference
+func tableParents(db *gorm.DB) (map[string][]string, error) {
+	var references []struct {
+		Child  string
+		Parent string
+	}
+	err := db.Raw(`
+		SELECT c.conrelid::regclass::text AS child, c.confrelid::regclass::text AS parent
+		FROM pg_constraint c
+		WHERE c.contype = 'f' AND c.conrelid <> c.confrelid
+	`).Scan(&references).Error
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo las relaciones entre tablas: %w", err)
+	}
+
+	parents := map[string][]string{}
+	for _, ref := range references {
+		child, parent := strings.Trim(ref.Child, `"`), strings.Trim(ref.Parent, `"`)
+		parents[child] = append(parents[child], parent)
+	}
+	return parents, nil
+}
+
+// dependencyOrder sorts tables so parents come before the tables that reference them
+func dependencyOrder(names []string, parents map[string][]string) []string {
+	pending := map[string]bool{}
+	for _, name := range names {
+		pending[name] = true
+	}
+
+	order := make([]string, 0, len(names))
+	for len(pending) > 0 {
+		progress := false
+		for _, name := range names {
+			if !pending[name] || hasPendingParent(name, parents, pending) {
+				continue
+			}
+			order = append(order, name)
+			delete(pending, name)
+			progress = true
+		}
+		if !progress {
+			// Circular references: add the rest in the given order
+			for _, name := range names {
+				if pending[name] {
+					order = append(order, name)
+					delete(pending, name)
+				}
+			}
+		}
+	}
+	return order
+}
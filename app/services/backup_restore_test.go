@@ -0,0 +1,76 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"PosApp/app/security"
+)
+
+func TestDependencyOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		tables  []string
+		parents map[string][]string
+		want    []string
+	}{
+		{"no references", []string{"a", "b"}, nil, []string{"a", "b"}},
+		{"parent after child by name", []string{"payments", "sales"}, map[string][]string{"payments": {"sales"}}, []string{"sales", "payments"}},
+		{"chain", []string{"a", "b", "c"}, map[string][]string{"a": {"b"}, "b": {"c"}}, []string{"c", "b", "a"}},
+		{"self reference", []string{"categories"}, map[string][]string{"categories": {"categories"}}, []string{"categories"}},
+		{"cycle keeps the given order", []string{"a", "b"}, map[string][]string{"a": {"b"}, "b": {"a"}}, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dependencyOrder(tt.tables, tt.parents); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dependencyOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupEncryptionStream(t *testing.T) {
+	t.Setenv("APPDATA", t.TempDir())
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 10000)
+
+	var encrypted bytes.Buffer
+	writer, err := security.NewEncryptWriter(&encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write(payload); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := encrypted.Bytes()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"complete", data, false},
+		{"truncated", data[:len(data)/2], true},
+		{"trailing data", append(append([]byte{}, data...), 0), true},
+		{"modified", func() []byte { d := append([]byte{}, data...); d[len(d)/2] ^= 1; return d }(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := security.NewDecryptReader(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(reader)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(got, payload) {
+				t.Errorf("decrypted %d bytes, want %d", len(got), len(payload))
+			}
+		})
+	}
+}
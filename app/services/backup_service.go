@@ -0,0 +1,573 @@
+package services
+
+import (
+	"bufio"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"PosApp/app/config"
+	"PosApp/app/database"
+	"PosApp/app/security"
+
+	"gorm.io/gorm"
+)
+
+// Backup reasons, part of the backup file name
+const (
+	BackupReasonScheduled      = "scheduled"
+	BackupReasonManual         = "manual"
+	BackupReasonPreUpdate      = "pre_update"
+	BackupReasonPreDIANProd    = "pre_dian_production"
+	BackupReasonPreRestore     = "pre_restore"
+	backupFileExtension        = ".posbak"
+	backupFormatVersion        = 1
+	backupBlockRows            = 500
+	backupSchedulerCheckPeriod = 10 * time.Minute
+)
+
+// backupMagic identifies backup files; the encrypted stream (security.NewEncryptWriter) follows it
+var backupMagic = []byte("POSBAK2\n")
+
+// backupMagicV1 identifies backups encrypted as a single block, written by older versions
+var backupMagicV1 = []byte("POSBAK1\n")
+
+// backupMu serializes backups and restores across service instances
+var backupMu sync.Mutex
+
+// BackupService exports the whole database to compressed, encrypted files
+// and restores them. Files are gzip'd JSON encrypted with the application key
+// (security package), so they can only be restored on a machine with the same key.bin
+type BackupService struct {
+	*BaseService
+	mu       sync.Mutex
+	stopChan chan struct{}
+	running  bool
+}
+
+// BackupSettings are the backup preferences (stored as system configs)
+type BackupSettings struct {
+	Enabled        bool   `json:"enabled"`         // Run scheduled backups
+	IntervalHours  int    `json:"interval_hours"`  // Hours between scheduled backups
+	RetentionCount int    `json:"retention_count"` // Backups to keep (0 = no limit)
+	RetentionDays  int    `json:"retention_days"`  // Days to keep backups (0 = no limit)
+	Directory      string `json:"directory"`       // Empty for the default directory next to config.json
+}
+
+// BackupInfo describes a backup file
+type BackupInfo struct {
+	FileName  string    `json:"file_name"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// backupManifest is the first JSON value of a backup
+type backupManifest struct {
+	Format     int           `json:"format"`
+	AppVersion string        `json:"app_version"`
+	Reason     string        `json:"reason"`
+	CreatedAt  time.Time     `json:"created_at"`
+	Tables     []backupTable `json:"tables"`
+}
+
+// backupTable is the schema of a table at backup time
+type backupTable struct {
+	Name    string         `json:"name"`
+	Columns []backupColumn `json:"columns"`
+	Rows    int64          `json:"rows"`
+}
+
+type backupColumn struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Nullable   bool   `json:"nullable"`
+	HasDefault bool   `json:"has_default"`
+}
+
+// backupBlock holds up to backupBlockRows rows of a table, each row as produced by row_to_json
+type backupBlock struct {
+	Table string            `json:"table"`
+	Rows  []json.RawMessage `json:"rows"`
+}
+
+// NewBackupService creates a new backup service
+func NewBackupService() *BackupService {
+	return &BackupService{
+		BaseService: NewBaseService(),
+	}
+}
+
+// GetBackupSettings returns the backup preferences
+func (s *BackupService) GetBackupSettings() BackupSettings {
+	configSvc := NewConfigService()
+	directory, _ := configSvc.GetSystemConfig("backup_directory")
+	return BackupSettings{
+		Enabled:        configSvc.GetSystemConfigBool("backup_enabled", true),
+		IntervalHours:  configSvc.GetSystemConfigInt("backup_interval_hours", 24),
+		RetentionCount: configSvc.GetSystemConfigInt("backup_retention_count", 14),
+		RetentionDays:  configSvc.GetSystemConfigInt("backup_retention_days", 30),
+		Directory:      directory,
+	}
+}
+
+// SaveBackupSettings saves the backup preferences
+func (s *BackupService) SaveBackupSettings(settings BackupSettings) error {
+	if settings.IntervalHours < 1 {
+		return fmt.Errorf("el intervalo entre copias debe ser de al menos 1 hora")
+	}
+	if settings.RetentionCount < 0 || settings.RetentionDays < 0 {
+		return fmt.Errorf("la retención no puede ser negativa")
+	}
+	if settings.Directory != "" {
+		if err := os.MkdirAll(settings.Directory, 0755); err != nil {
+			return fmt.Errorf("no se puede usar la carpeta de copias: %w", err)
+		}
+	}
+
+	configSvc := NewConfigService()
+	values := []struct {
+		key, value, configType string
+	}{
+		{"backup_enabled", strconv.FormatBool(settings.Enabled), "boolean"},
+		{"backup_interval_hours", strconv.Itoa(settings.IntervalHours), "number"},
+		{"backup_retention_count", strconv.Itoa(settings.RetentionCount), "number"},
+		{"backup_retention_days", strconv.Itoa(settings.RetentionDays), "number"},
+		{"backup_directory", settings.Directory, "string"},
+	}
+	for _, v := range values {
+		if err := configSvc.SetSystemConfig(v.key, v.value, v.configType, "backup"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backupDir returns the directory where backups are written
+func (s *BackupService) backupDir() (string, error) {
+	dir := s.GetBackupSettings().Directory
+	if dir == "" {
+		configPath, err := config.GetConfigPath()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(filepath.Dir(configPath), "backups")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("no se pudo crear la carpeta de copias: %w", err)
+	}
+	return dir, nil
+}
+
+// BackupNow creates a manual backup
+func (s *BackupService) BackupNow() (*BackupInfo, error) {
+	return s.CreateBackup(BackupReasonManual)
+}
+
+// CreateBackup exports every table of the database to a new backup file and applies the retention rules
+func (s *BackupService) CreateBackup(reason string) (*BackupInfo, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	backupMu.Lock()
+	defer backupMu.Unlock()
+
+	info, err := s.createBackup(reason)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRetention(); err != nil {
+		log.Printf("Backup: retention failed: %v", err)
+	}
+	return info, nil
+}
+
+// createBackup writes the backup file; the caller holds backupMu
+// The dump is compressed and encrypted while it is written, so it is never held in memory
+func (s *BackupService) createBackup(reason string) (info *BackupInfo, err error) {
+	dir, err := s.backupDir()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	fileName := fmt.Sprintf("posapp_%s_%s%s", now.Format("20060102_150405"), reason, backupFileExtension)
+	path := filepath.Join(dir, fileName)
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error guardando la copia de seguridad: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	out := bufio.NewWriter(file)
+	if _, err := out.Write(backupMagic); err != nil {
+		return nil, fmt.Errorf("error guardando la copia de seguridad: %w", err)
+	}
+	encrypted, err := security.NewEncryptWriter(out)
+	if err != nil {
+		return nil, fmt.Errorf("error cifrando la copia de seguridad: %w", err)
+	}
+	gz := gzip.NewWriter(encrypted)
+	manifest := &backupManifest{
+		Format:     backupFormatVersion,
+		AppVersion: CurrentVersion,
+		Reason:     reason,
+		CreatedAt:  now,
+	}
+
+	// A read-only repeatable read transaction gives a consistent snapshot of all tables
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		schema, err := currentSchema(tx)
+		if err != nil {
+			return err
+		}
+		parents, err := tableParents(tx)
+		if err != nil {
+			return err
+		}
+		// Parents are written before the tables that reference them, so a restore
+		// can insert the rows in the order they are read
+		for _, table := range dependencyOrder(schema.tableNames(), parents) {
+			var rows int64
+			if err := tx.Table(table).Count(&rows).Error; err != nil {
+				return fmt.Errorf("error contando filas de %s: %w", table, err)
+			}
+			manifest.Tables = append(manifest.Tables, backupTable{
+				Name:    table,
+				Columns: schema[table],
+				Rows:    rows,
+			})
+		}
+
+		encoder := json.NewEncoder(gz)
+		if err := encoder.Encode(manifest); err != nil {
+			return err
+		}
+		for _, table := range manifest.Tables {
+			if err := exportTable(tx, encoder, table); err != nil {
+				return fmt.Errorf("error exportando %s: %w", table.Name, err)
+			}
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("error creando la copia de seguridad: %w", err)
+	}
+	if err = gz.Close(); err == nil {
+		if err = encrypted.Close(); err == nil {
+			if err = out.Flush(); err == nil {
+				err = file.Sync()
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error guardando la copia de seguridad: %w", err)
+	}
+	if err = file.Close(); err != nil {
+		return nil, fmt.Errorf("error guardando la copia de seguridad: %w", err)
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		return nil, fmt.Errorf("error guardando la copia de seguridad: %w", err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Backup: created %s (%d tables, %d bytes)", fileName, len(manifest.Tables), stat.Size())
+	return &BackupInfo{
+		FileName:  fileName,
+		Path:      path,
+		Size:      stat.Size(),
+		Reason:    reason,
+		CreatedAt: now,
+	}, nil
+}
+
+// exportTable writes the rows of a table in blocks, ordered by id when the table has one
+func exportTable(tx *gorm.DB, encoder *json.Encoder, table backupTable) error {
+	query := fmt.Sprintf("SELECT row_to_json(t)::text FROM %s t", quoteIdent(table.Name))
+	for _, column := range table.Columns {
+		if column.Name == "id" {
+			query += " ORDER BY t.id"
+			break
+		}
+	}
+
+	rows, err := tx.Raw(query).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	block := backupBlock{Table: table.Name}
+	for rows.Next() {
+		var row string
+		if err := rows.Scan(&row); err != nil {
+			return err
+		}
+		block.Rows = append(block.Rows, json.RawMessage(row))
+		if len(block.Rows) == backupBlockRows {
+			if err := encoder.Encode(block); err != nil {
+				return err
+			}
+			block.Rows = nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(block.Rows) > 0 {
+		return encoder.Encode(block)
+	}
+	return nil
+}
+
+// ExportBackupKey copies the encryption key (key.bin) to a directory, e.g. a USB drive
+// Backups can only be restored with this key; to restore them on another computer, copy
+// the exported key.bin to %APPDATA%\PosApp on that computer before starting the application
+func (s *BackupService) ExportBackupKey(directory string) (string, error) {
+	directory = strings.TrimSpace(directory)
+	if directory == "" {
+		return "", fmt.Errorf("indica la carpeta donde exportar la clave")
+	}
+	if stat, err := os.Stat(directory); err != nil || !stat.IsDir() {
+		return "", fmt.Errorf("la carpeta %s no existe", directory)
+	}
+
+	key, err := security.GenerateKeyIfNotExists()
+	if err != nil {
+		return "", fmt.Errorf("error leyendo la clave de cifrado: %w", err)
+	}
+	path := filepath.Join(directory, "key.bin")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return "", fmt.Errorf("ya existe un archivo key.bin en %s", directory)
+		}
+		return "", fmt.Errorf("error exportando la clave: %w", err)
+	}
+	if _, err := file.Write(key); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", fmt.Errorf("error exportando la clave: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("error exportando la clave: %w", err)
+	}
+	log.Printf("Backup: encryption key exported to %s", path)
+	return path, nil
+}
+
+// ListBackups returns the backup files, newest first
+func (s *BackupService) ListBackups() ([]BackupInfo, error) {
+	dir, err := s.backupDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo la carpeta de copias: %w", err)
+	}
+
+	backups := []BackupInfo{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), backupFileExtension) {
+			continue
+		}
+		stat, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, parseBackupFileName(dir, entry.Name(), stat))
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// parseBackupFileName reads the date and reason from posapp_<date>_<time>_<reason>.posbak
+func parseBackupFileName(dir, name string, stat os.FileInfo) BackupInfo {
+	info := BackupInfo{
+		FileName:  name,
+		Path:      filepath.Join(dir, name),
+		Size:      stat.Size(),
+		CreatedAt: stat.ModTime(),
+	}
+	parts := strings.SplitN(strings.TrimSuffix(name, backupFileExtension), "_", 4)
+	if len(parts) == 4 && parts[0] == "posapp" {
+		if t, err := time.ParseInLocation("20060102_150405", parts[1]+"_"+parts[2], time.Local); err == nil {
+			info.CreatedAt = t
+		}
+		info.Reason = parts[3]
+	}
+	return info
+}
+
+// DeleteBackup deletes a backup file
+func (s *BackupService) DeleteBackup(fileName string) error {
+	path, err := s.backupPath(fileName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("error eliminando la copia: %w", err)
+	}
+	return nil
+}
+
+// backupPath resolves a backup file name inside the backup directory
+func (s *BackupService) backupPath(fileName string) (string, error) {
+	name := filepath.Base(fileName)
+	if name != fileName || !strings.HasSuffix(name, backupFileExtension) {
+		return "", fmt.Errorf("nombre de copia inválido: %s", fileName)
+	}
+	dir, err := s.backupDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("la copia %s no existe", fileName)
+	}
+	return path, nil
+}
+
+// applyRetention deletes backups beyond the retention count or older than the retention days
+// The newest backup is always kept
+func (s *BackupService) applyRetention() error {
+	settings := s.GetBackupSettings()
+	backups, err := s.ListBackups()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -settings.RetentionDays)
+	for i, backup := range backups {
+		if i == 0 {
+			continue
+		}
+		expired := settings.RetentionDays > 0 && backup.CreatedAt.Before(cutoff)
+		overCount := settings.RetentionCount > 0 && i >= settings.RetentionCount
+		if !expired && !overCount {
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil {
+			log.Printf("Backup: could not delete old backup %s: %v", backup.FileName, err)
+			continue
+		}
+		log.Printf("Backup: deleted old backup %s", backup.FileName)
+	}
+	return nil
+}
+
+// Start begins the backup scheduler
+func (s *BackupService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+	go s.run(s.stopChan)
+	log.Println("Backup scheduler started")
+}
+
+// Stop stops the backup scheduler
+func (s *BackupService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return
+	}
+	close(s.stopChan)
+	s.running = false
+	log.Println("Backup scheduler stopped")
+}
+
+// run checks periodically whether the last backup is older than the configured interval,
+// so a backup missed while the computer was off runs soon after the next start
+func (s *BackupService) run(stop chan struct{}) {
+	// Initial delay so the backup does not compete with startup
+	select {
+	case <-time.After(2 * time.Minute):
+	case <-stop:
+		return
+	}
+
+	ticker := time.NewTicker(backupSchedulerCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		s.runScheduledBackup()
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// runScheduledBackup creates a backup when scheduled backups are enabled and due
+func (s *BackupService) runScheduledBackup() {
+	if s.EnsureDB() != nil {
+		return
+	}
+	settings := s.GetBackupSettings()
+	if !settings.Enabled {
+		return
+	}
+
+	backups, err := s.ListBackups()
+	if err != nil {
+		log.Printf("Backup: could not list backups: %v", err)
+		return
+	}
+	if len(backups) > 0 && time.Since(backups[0].CreatedAt) < time.Duration(settings.IntervalHours)*time.Hour {
+		return
+	}
+
+	log.Println("Backup: starting scheduled backup...")
+	if _, err := s.CreateBackup(BackupReasonScheduled); err != nil {
+		log.Printf("Backup: scheduled backup failed: %v", err)
+	}
+}
+
+// BackupBeforeChange creates a backup before a risky operation (update, DIAN production migration)
+// It is skipped when the database is not configured yet
+// Returns the file name of the backup ("" when there is no database yet)
+func BackupBeforeChange(reason string) (string, error) {
+	if database.GetDB() == nil {
+		return "", nil
+	}
+	backup, err := NewBackupService().CreateBackup(reason)
+	if err != nil {
+		return "", fmt.Errorf("no se pudo crear la copia de seguridad previa: %w", err)
+	}
+	return backup.FileName, nil
+}
+
+// quoteIdent quotes a PostgreSQL identifier
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
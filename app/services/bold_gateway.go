@@ -0,0 +1,224 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"PosApp/app/models"
+
+	"gorm.io/gorm"
+)
+
+// BoldGatewayID is the gateway ID of the Bold integration
+const BoldGatewayID = "bold"
+
+func init() {
+	RegisterPaymentGateway(BoldGatewayID, "Bold", "Datáfonos Bold, Nequi, Daviplata y links de pago", newBoldGateway)
+}
+
+// boldGatewayConfig is the per payment method configuration of the Bold gateway
+type boldGatewayConfig struct {
+	PaymentMethod  string `json:"payment_method"`  // "POS", "NEQUI", "DAVIPLATA" (defaults to the method's BoldPaymentMethod)
+	TerminalModel  string `json:"terminal_model"`  // Defaults to BoldConfig.DefaultTerminalModel
+	TerminalSerial string `json:"terminal_serial"` // Defaults to BoldConfig.DefaultTerminalSerial
+}
+
+// boldGateway implements PaymentGateway on top of BoldService
+type boldGateway struct {
+	db     *gorm.DB
+	bold   *BoldService
+	config boldGatewayConfig
+}
+
+func newBoldGateway(db *gorm.DB, config string) (PaymentGateway, error) {
+	gateway := &boldGateway{db: db, bold: NewBoldService(db)}
+	if config != "" {
+		if err := json.Unmarshal([]byte(config), &gateway.config); err != nil {
+			return nil, fmt.Errorf("invalid Bold gateway configuration: %w", err)
+		}
+	}
+	return gateway, nil
+}
+
+func (g *boldGateway) ID() string {
+	return BoldGatewayID
+}
+
+func (g *boldGateway) Enabled() bool {
+	config, err := g.bold.GetBoldConfig()
+	return err == nil && config.Enabled
+}
+
+func (g *boldGateway) CreatePayment(req GatewayPaymentRequest) (*GatewayTransaction, error) {
+	config, err := g.bold.GetBoldConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var method models.PaymentMethod
+	if err := g.db.First(&method, req.PaymentMethodID).Error; err != nil {
+		return nil, fmt.Errorf("payment method ID %d not found", req.PaymentMethodID)
+	}
+
+	paymentMethod := g.config.PaymentMethod
+	if paymentMethod == "" {
+		paymentMethod = method.BoldPaymentMethod
+	}
+	if paymentMethod == "" {
+		paymentMethod = "POS"
+	}
+	terminalModel, terminalSerial := g.config.TerminalModel, g.config.TerminalSerial
+	if terminalSerial == "" {
+		terminalModel, terminalSerial = config.DefaultTerminalModel, config.DefaultTerminalSerial
+	}
+
+	paymentReq := &models.BoldPaymentRequest{
+		Amount: models.BoldAmount{
+			Currency:    "COP",
+			Taxes:       []models.BoldTax{},
+			TipAmount:   req.TipAmount,
+			TotalAmount: req.Amount,
+		},
+		PaymentMethod:  paymentMethod,
+		TerminalModel:  terminalModel,
+		TerminalSerial: terminalSerial,
+		Reference:      req.Reference,
+		UserEmail:      config.UserEmail,
+		Description:    req.Description,
+	}
+	if req.PayerEmail != "" {
+		paymentReq.Payer = &models.BoldPayer{Email: req.PayerEmail}
+	}
+
+	response, err := g.bold.CreatePaymentWithContext(paymentReq, method.ID, method.Name,
+		req.OrderID, req.CustomerID, req.EmployeeID, req.CashRegisterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GatewayTransaction{
+		GatewayID:     BoldGatewayID,
+		TransactionID: response.Payload.IntegrationID,
+		Status:        GatewayStatusPending,
+		Amount:        req.Amount,
+		TipAmount:     req.TipAmount,
+		Reference:     fmt.Sprintf("Bold-%s", response.Payload.IntegrationID),
+	}, nil
+}
+
+func (g *boldGateway) GetPaymentStatus(transactionID string) (*GatewayTransaction, error) {
+	payment, err := g.bold.GetPendingPayment(transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("bold payment %s not found: %w", transactionID, err)
+	}
+	return boldTransaction(payment), nil
+}
+
+func (g *boldGateway) VoidPayment(req GatewayVoidRequest) (*GatewayTransaction, error) {
+	void, err := g.bold.VoidPayment(req.TransactionID, req.Amount, req.Reason, req.EmployeeID, req.CashRegisterID)
+	if err != nil {
+		return nil, err
+	}
+	return &GatewayTransaction{
+		GatewayID:     BoldGatewayID,
+		TransactionID: req.TransactionID,
+		Status:        GatewayStatusVoidPending, // Confirmed by the VOID_APPROVED webhook
+		Amount:        void.Amount,
+		Reference:     void.Reference,
+	}, nil
+}
+
+func (g *boldGateway) ParseWebhook(body []byte, headers http.Header) (*GatewayWebhookEvent, error) {
+	config, err := g.bold.GetBoldConfig()
+	if err != nil {
+		return nil, err
+	}
+	notification, failedStatus, err := parseBoldWebhook(config, body, headers.Get("x-bold-signature"))
+	if err != nil {
+		if failedStatus == "failed_signature" {
+			return nil, fmt.Errorf("%w: %v", ErrGatewayWebhookSignature, err)
+		}
+		return nil, err
+	}
+
+	event := &GatewayWebhookEvent{
+		GatewayID:     BoldGatewayID,
+		TransactionID: notification.Subject,
+		Raw:           notification,
+	}
+	if event.TransactionID == "" {
+		event.TransactionID = notification.Data.Metadata.Reference
+	}
+	switch notification.Type {
+	case "SALE_APPROVED":
+		event.Type = GatewayEventPaymentApproved
+	case "SALE_REJECTED":
+		event.Type = GatewayEventPaymentRejected
+	case "VOID_APPROVED":
+		event.Type = GatewayEventVoidApproved
+	case "VOID_REJECTED":
+		event.Type = GatewayEventVoidRejected
+	default:
+		return nil, fmt.Errorf("unknown Bold notification type: %s", notification.Type)
+	}
+
+	event.Transaction = &GatewayTransaction{
+		GatewayID:      BoldGatewayID,
+		TransactionID:  event.TransactionID,
+		Amount:         notification.Data.Amount.Total,
+		TipAmount:      notification.Data.Amount.Tip,
+		ApprovalNumber: notification.Data.ApprovalNumber,
+	}
+	if notification.Data.Card != nil {
+		event.Transaction.CardBrand = notification.Data.Card.Brand
+		event.Transaction.CardMaskedPan = notification.Data.Card.MaskedPan
+	}
+	return event, nil
+}
+
+func (g *boldGateway) ApplyWebhook(event *GatewayWebhookEvent, notifier WebSocketServer) error {
+	notification, ok := event.Raw.(*models.BoldWebhookNotification)
+	if !ok {
+		return fmt.Errorf("not a Bold notification")
+	}
+	webhooks := NewBoldWebhookService(g.db, g.bold)
+	webhooks.SetWebSocketServer(notifier)
+	return webhooks.processWebhook(notification)
+}
+
+func (g *boldGateway) TransactionIDFromReference(reference string) string {
+	return BoldIntegrationIDFromReference(reference)
+}
+
+func (g *boldGateway) LinkPaymentToSale(tx *gorm.DB, transactionID string, saleID, paymentID uint) error {
+	// Bold voids and reconciliation look payments up by bold_integration_id
+	if err := tx.Model(&models.Payment{}).Where("id = ?", paymentID).
+		Update("bold_integration_id", transactionID).Error; err != nil {
+		return err
+	}
+	return g.bold.LinkPaymentToSale(tx, transactionID, saleID, paymentID)
+}
+
+// boldTransaction maps a Bold pending payment to a gateway transaction
+func boldTransaction(payment *models.BoldPendingPayment) *GatewayTransaction {
+	status := payment.Status
+	switch payment.Status {
+	case "expired":
+		status = GatewayStatusCancelled
+	case "partially_refunded":
+		status = GatewayStatusApproved
+	}
+	return &GatewayTransaction{
+		GatewayID:      BoldGatewayID,
+		TransactionID:  payment.IntegrationID,
+		Status:         status,
+		Amount:         payment.Amount,
+		TipAmount:      payment.TipAmount,
+		Reference:      fmt.Sprintf("Bold-%s", payment.IntegrationID),
+		ApprovalNumber: payment.ApprovalNumber,
+		CardBrand:      payment.CardBrand,
+		CardMaskedPan:  payment.CardMaskedPan,
+		Message:        payment.ProcessError,
+	}
+}
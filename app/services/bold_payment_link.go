@@ -0,0 +1,423 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"PosApp/app/models"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// PaymentLinkShare represents a payment link ready to be shared with the customer
+type PaymentLinkShare struct {
+	IntegrationID string `json:"integration_id"`
+	URL           string `json:"url"`
+	QRCode        string `json:"qr_code"`                // PNG data URL
+	WhatsAppURL   string `json:"whatsapp_url,omitempty"` // wa.me URL that opens the chat with the message
+	MailtoURL     string `json:"mailto_url,omitempty"`   // mailto: URL that opens the email client with the message
+	Message       string `json:"message"`
+}
+
+// CreatePaymentLink creates a Bold payment link for the total of an order and tracks it as pending
+// The sale is completed automatically when Bold notifies the payment (see BoldWebhookService)
+func (s *BoldService) CreatePaymentLink(orderID, paymentMethodID, employeeID, cashRegisterID uint, expiresInMinutes int, payerEmail string) (*models.BoldPendingPayment, error) {
+	config, err := s.GetBoldConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return nil, fmt.Errorf("bold integration is not enabled")
+	}
+	if !config.EnablePayByLink {
+		return nil, fmt.Errorf("los links de pago Bold no están habilitados")
+	}
+
+	apiKey, err := s.GetAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key not configured")
+	}
+
+	var order models.Order
+	if err := s.db.First(&order, orderID).Error; err != nil {
+		return nil, fmt.Errorf("orden no encontrada")
+	}
+	if order.Status == models.OrderStatusPaid || order.Status == models.OrderStatusCancelled {
+		return nil, fmt.Errorf("la orden %s ya está %s", order.OrderNumber, order.Status)
+	}
+	if order.Total <= 0 {
+		return nil, fmt.Errorf("la orden %s no tiene valor a cobrar", order.OrderNumber)
+	}
+
+	var paymentMethod models.PaymentMethod
+	if err := s.db.First(&paymentMethod, paymentMethodID).Error; err != nil {
+		return nil, fmt.Errorf("payment method ID %d not found", paymentMethodID)
+	}
+
+	// Only one active link per order
+	s.expirePaymentLinks()
+	var active int64
+	s.db.Model(&models.BoldPendingPayment{}).
+		Where("order_id = ? AND channel = ? AND status = ?", orderID, models.BoldChannelLink, "pending").
+		Count(&active)
+	if active > 0 {
+		return nil, fmt.Errorf("la orden %s ya tiene un link de pago activo, cancélelo antes de generar otro", order.OrderNumber)
+	}
+
+	if expiresInMinutes <= 0 {
+		expiresInMinutes = NewConfigService().GetSystemConfigInt("bold_payment_link_expiration_minutes", 60)
+	}
+	expiresAt := time.Now().Add(time.Duration(expiresInMinutes) * time.Minute)
+
+	var restaurant models.RestaurantConfig
+	s.db.First(&restaurant)
+	description := fmt.Sprintf("Pedido %s", order.OrderNumber)
+	if restaurant.Name != "" {
+		description = fmt.Sprintf("%s - %s", restaurant.Name, description)
+	}
+
+	reference := fmt.Sprintf("LINK-%s-%d", order.OrderNumber, time.Now().Unix())
+	linkReq := models.BoldPaymentLinkRequest{
+		AmountType: "CLOSE",
+		Amount: models.BoldAmount{
+			Currency:    "COP",
+			Taxes:       []models.BoldTax{},
+			TotalAmount: order.Total,
+		},
+		Description:    description,
+		Reference:      reference,
+		ExpirationDate: expiresAt.UnixNano(),
+		PayerEmail:     payerEmail,
+	}
+
+	requestBody, err := json.Marshal(linkReq)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", config.BaseURL+"/online/link/v1", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "x-api-key "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result models.BoldPaymentLinkResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	if result.Payload.PaymentLink == "" || result.Payload.URL == "" {
+		return nil, fmt.Errorf("bold did not return a payment link: %s", string(body))
+	}
+
+	pendingPayment := &models.BoldPendingPayment{
+		IntegrationID:     result.Payload.PaymentLink,
+		Reference:         reference,
+		Amount:            order.Total,
+		Status:            "pending",
+		PaymentMethodID:   paymentMethod.ID,
+		PaymentMethodName: paymentMethod.Name,
+		OrderID:           order.ID,
+		EmployeeID:        employeeID,
+		CashRegisterID:    cashRegisterID,
+		Channel:           models.BoldChannelLink,
+		PaymentLinkURL:    result.Payload.URL,
+		ExpiresAt:         &expiresAt,
+	}
+	if order.CustomerID != nil {
+		pendingPayment.CustomerID = *order.CustomerID
+	}
+	if err := s.CreatePendingPayment(pendingPayment); err != nil {
+		return nil, fmt.Errorf("error saving payment link: %w", err)
+	}
+
+	config.TotalPayments++
+	now := time.Now()
+	config.LastSyncAt = &now
+	config.LastSyncStatus = "success"
+	s.db.Save(config)
+
+	fmt.Printf("🔗 Bold payment link created: %s for order %s (%.2f, expires %s)\n",
+		pendingPayment.IntegrationID, order.OrderNumber, order.Total, expiresAt.Format("15:04"))
+	return pendingPayment, nil
+}
+
+// GetPaymentLinkShare returns the link, its QR code and the message to send to the customer
+// If phone is given, a WhatsApp URL is included and the link is marked as sent by WhatsApp
+// If email is given, a mailto URL is included and the link is marked as sent by email
+func (s *BoldService) GetPaymentLinkShare(integrationID string, phone string, email string) (*PaymentLinkShare, error) {
+	link, err := s.getActivePaymentLink(integrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := qrcode.Encode(link.PaymentLinkURL, qrcode.Medium, 256)
+	if err != nil {
+		return nil, fmt.Errorf("error generating QR code: %w", err)
+	}
+
+	share := &PaymentLinkShare{
+		IntegrationID: link.IntegrationID,
+		URL:           link.PaymentLinkURL,
+		QRCode:        "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+		Message:       s.paymentLinkMessage(link),
+	}
+
+	if phone != "" {
+		number := normalizeWhatsAppNumber(phone)
+		if number == "" {
+			return nil, fmt.Errorf("número de teléfono inválido: %s", phone)
+		}
+		share.WhatsAppURL = fmt.Sprintf("https://wa.me/%s?text=%s", number, strings.ReplaceAll(url.QueryEscape(share.Message), "+", "%20"))
+		s.db.Model(link).Updates(map[string]interface{}{"sent_via": "whatsapp", "sent_to": number})
+	}
+
+	if email = strings.TrimSpace(email); email != "" {
+		if !strings.Contains(email, "@") {
+			return nil, fmt.Errorf("correo electrónico inválido: %s", email)
+		}
+		var order models.Order
+		s.db.First(&order, link.OrderID)
+		query := url.Values{}
+		query.Set("subject", fmt.Sprintf("Link de pago - Pedido %s", order.OrderNumber))
+		query.Set("body", share.Message)
+		share.MailtoURL = "mailto:" + url.PathEscape(email) + "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+		s.db.Model(link).Updates(map[string]interface{}{"sent_via": "email", "sent_to": email})
+	}
+
+	return share, nil
+}
+
+// SendPaymentLinkEmail emails the payment link to the customer through the mail queue
+func (s *BoldService) SendPaymentLinkEmail(integrationID string, email string) error {
+	link, err := s.getActivePaymentLink(integrationID)
+	if err != nil {
+		return err
+	}
+	email = strings.TrimSpace(email)
+	if email == "" || !strings.Contains(email, "@") {
+		return fmt.Errorf("correo electrónico inválido: %s", email)
+	}
+
+	var order models.Order
+	s.db.First(&order, link.OrderID)
+
+	title := fmt.Sprintf("Link de pago - Pedido %s", order.OrderNumber)
+	body, err := renderMailTemplate("payment_link", title, mailRestaurant(s.db), mailPaymentLinkData{
+		OrderNumber: order.OrderNumber,
+		Message:     s.paymentLinkMessage(link),
+		URL:         link.PaymentLinkURL,
+	})
+	if err != nil {
+		return err
+	}
+	message, err := queueMail(s.db, mailRequest{
+		Kind:          models.MailKindPaymentLink,
+		To:            []string{email},
+		Subject:       title,
+		HTMLBody:      body,
+		ReferenceType: "order",
+		ReferenceID:   order.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Model(link).Updates(map[string]interface{}{"sent_via": "email", "sent_to": email}).Error; err != nil {
+		return err
+	}
+
+	// Try right away so the cashier knows whether the customer got the link
+	if err := deliverQueuedMail(s.db, message.ID); err != nil {
+		return fmt.Errorf("no se pudo enviar el correo (se reintentará automáticamente): %w", err)
+	}
+	return nil
+}
+
+// CancelPaymentLink stops the POS from applying an active payment link
+// Bold's API has no way to cancel a link, it stays payable at Bold until it expires. The link
+// status is checked at Bold first so a link being paid is not cancelled, and a payment notified
+// later for a cancelled link is not applied to the order but flagged for review.
+func (s *BoldService) CancelPaymentLink(integrationID string) error {
+	link, err := s.getActivePaymentLink(integrationID)
+	if err != nil {
+		return err
+	}
+
+	status, err := s.getPaymentLinkStatus(link.IntegrationID)
+	if err != nil {
+		fmt.Printf("⚠️  Could not check payment link %s at Bold, cancelling locally: %v\n", link.IntegrationID, err)
+	} else if status == "PAID" || status == "PROCESSING" {
+		return fmt.Errorf("el link de pago %s ya fue pagado o se está pagando en Bold (estado %s)", link.IntegrationID, status)
+	}
+
+	result := s.db.Model(&models.BoldPendingPayment{}).
+		Where("integration_id = ? AND channel = ? AND status = ?", integrationID, models.BoldChannelLink, "pending").
+		Update("status", "cancelled")
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("el link de pago %s no está activo", integrationID)
+	}
+	if link.ExpiresAt != nil {
+		fmt.Printf("🔗 Payment link %s cancelled in the POS, it remains payable at Bold until %s\n", link.IntegrationID, link.ExpiresAt.Format("15:04"))
+	}
+	return nil
+}
+
+// getPaymentLinkStatus returns the status of a payment link at Bold (ACTIVE, PROCESSING, PAID, REJECTED, CANCELLED, EXPIRED)
+func (s *BoldService) getPaymentLinkStatus(integrationID string) (string, error) {
+	config, err := s.GetBoldConfig()
+	if err != nil {
+		return "", err
+	}
+	apiKey, err := s.GetAPIKey()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("GET", config.BaseURL+"/online/link/v1/"+url.PathEscape(integrationID), nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "x-api-key "+apiKey)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Payload struct {
+			Status string `json:"status"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("error parsing response: %w", err)
+	}
+	return strings.ToUpper(result.Payload.Status), nil
+}
+
+// GetPaymentLinks returns the payment links, optionally filtered by order (0 = all recent)
+func (s *BoldService) GetPaymentLinks(orderID uint, limit int) ([]models.BoldPendingPayment, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	s.expirePaymentLinks()
+
+	var links []models.BoldPendingPayment
+	query := s.db.Where("channel = ?", models.BoldChannelLink).Order("created_at DESC").Limit(limit)
+	if orderID > 0 {
+		query = query.Where("order_id = ?", orderID)
+	}
+	err := query.Find(&links).Error
+	return links, err
+}
+
+// expirePaymentLinks marks the pending links past their expiration as expired
+func (s *BoldService) expirePaymentLinks() {
+	s.db.Model(&models.BoldPendingPayment{}).
+		Where("channel = ? AND status = ? AND expires_at < ?", models.BoldChannelLink, "pending", time.Now()).
+		Update("status", "expired")
+}
+
+func (s *BoldService) getActivePaymentLink(integrationID string) (*models.BoldPendingPayment, error) {
+	s.expirePaymentLinks()
+	link, err := s.GetPendingPayment(integrationID)
+	if err != nil || link.Channel != models.BoldChannelLink {
+		return nil, fmt.Errorf("link de pago %s no encontrado", integrationID)
+	}
+	if link.Status != "pending" {
+		return nil, fmt.Errorf("el link de pago %s no está activo (estado: %s)", integrationID, link.Status)
+	}
+	return link, nil
+}
+
+func (s *BoldService) paymentLinkMessage(link *models.BoldPendingPayment) string {
+	var order models.Order
+	s.db.First(&order, link.OrderID)
+	var restaurant models.RestaurantConfig
+	s.db.First(&restaurant)
+
+	message := fmt.Sprintf("Hola, este es el link para pagar tu pedido %s por $%s", order.OrderNumber, formatPesos(link.Amount))
+	if restaurant.Name != "" {
+		message = fmt.Sprintf("Hola, este es el link de %s para pagar tu pedido %s por $%s", restaurant.Name, order.OrderNumber, formatPesos(link.Amount))
+	}
+	message += ":\n" + link.PaymentLinkURL
+	if link.ExpiresAt != nil {
+		message += fmt.Sprintf("\nVálido hasta las %s.", link.ExpiresAt.Format("15:04"))
+	}
+	return message
+}
+
+// formatPesos formats an amount without decimals and with thousands separators (12.500)
+func formatPesos(amount float64) string {
+	digits := fmt.Sprintf("%.0f", amount)
+	negative := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(digits, "-")
+	var out strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out.WriteByte('.')
+		}
+		out.WriteRune(r)
+	}
+	if negative {
+		return "-" + out.String()
+	}
+	return out.String()
+}
+
+// normalizeWhatsAppNumber keeps the digits of a phone and adds the Colombian prefix to local mobile numbers
+func normalizeWhatsAppNumber(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	number := digits.String()
+	if len(number) == 10 && strings.HasPrefix(number, "3") {
+		number = "57" + number
+	}
+	if len(number) < 10 {
+		return ""
+	}
+	return number
+}
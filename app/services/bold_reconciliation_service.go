@@ -0,0 +1,588 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"PosApp/app/models"
+
+	"gorm.io/gorm"
+)
+
+// BoldReconciliationService matches the transactions approved at Bold against the payments recorded in the POS
+type BoldReconciliationService struct {
+	db       *gorm.DB
+	wsServer WebSocketServer
+	stopChan chan bool
+}
+
+func NewBoldReconciliationService(db *gorm.DB) *BoldReconciliationService {
+	return &BoldReconciliationService{db: db}
+}
+
+// SetWebSocketServer sets the WebSocket server used to notify reconciliation discrepancies
+func (s *BoldReconciliationService) SetWebSocketServer(wsServer WebSocketServer) {
+	s.wsServer = wsServer
+}
+
+// boldPOSPayment is a POS payment recorded as paid through Bold
+type boldPOSPayment struct {
+	models.Payment
+	SaleNumber string
+}
+
+// ReconcileDay reconciles the Bold transactions of a calendar day (local time)
+func (s *BoldReconciliationService) ReconcileDay(date time.Time, employeeID uint) (*models.BoldReconciliation, error) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 1)
+	return s.reconcile(start, end, nil, employeeID)
+}
+
+// ReconcileCashRegister reconciles the Bold transactions of a cash register shift
+func (s *BoldReconciliationService) ReconcileCashRegister(registerID uint, employeeID uint) (*models.BoldReconciliation, error) {
+	var register models.CashRegister
+	if err := s.db.First(&register, registerID).Error; err != nil {
+		return nil, fmt.Errorf("caja no encontrada")
+	}
+
+	end := time.Now()
+	if register.ClosedAt != nil {
+		end = *register.ClosedAt
+	}
+	return s.reconcile(register.OpenedAt, end, &register.ID, employeeID)
+}
+
+// reconcile builds and stores the reconciliation of a period
+// When registerID is set only the transactions of that cash register are considered
+func (s *BoldReconciliationService) reconcile(start, end time.Time, registerID *uint, employeeID uint) (*models.BoldReconciliation, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	// Bold side: transactions approved at Bold (including the ones voided later)
+	boldPayments, source, err := s.boldTransactions(start, end, registerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// POS side: payments of sales recorded as Bold (linked integration_id or Bold payment method)
+	var posPayments []boldPOSPayment
+	posQuery := s.db.Table("payments").
+		Select("payments.*, sales.sale_number").
+		Joins("JOIN sales ON sales.id = payments.sale_id AND sales.deleted_at IS NULL").
+		Joins("LEFT JOIN payment_methods ON payment_methods.id = payments.payment_method_id").
+		Where("sales.created_at >= ? AND sales.created_at < ?", start, end).
+		Where("payments.bold_integration_id <> '' OR payment_methods.use_bold_terminal = ? OR payment_methods.gateway_id = ?", true, BoldGatewayID)
+	if registerID != nil {
+		posQuery = posQuery.Where("sales.cash_register_id = ?", *registerID)
+	}
+	if err := posQuery.Order("payments.created_at").Scan(&posPayments).Error; err != nil {
+		return nil, fmt.Errorf("error loading POS payments: %w", err)
+	}
+
+	tolerance := s.amountTolerance()
+	items := make([]models.BoldReconciliationItem, 0, len(boldPayments)+len(posPayments))
+	usedBold := make(map[*models.BoldPendingPayment]bool)
+	usedPOS := make(map[uint]bool)
+
+	match := func(bold *models.BoldPendingPayment, pos *boldPOSPayment, matchedBy string) {
+		usedBold[bold] = true
+		usedPOS[pos.ID] = true
+		item := newBoldSideItem(bold)
+		fillPOSSide(&item, pos)
+		item.MatchedBy = matchedBy
+		item.Difference = item.BoldAmount - item.POSAmount
+		item.MatchStatus = models.BoldMatchMatched
+		if math.Abs(item.Difference) > tolerance {
+			item.MatchStatus = models.BoldMatchAmountMismatch
+			item.Notes = fmt.Sprintf("Diferencia de $%.0f", item.Difference)
+		}
+		if bold.Status != "approved" {
+			item.Notes = strings.TrimSpace(item.Notes + " Transacción anulada en Bold")
+		}
+		items = append(items, item)
+	}
+
+	// 1. Match by integration_id (payment linked when the sale was processed)
+	boldByIntegration := make(map[string]*models.BoldPendingPayment)
+	boldByPOSPayment := make(map[uint]*models.BoldPendingPayment)
+	for i := range boldPayments {
+		boldByIntegration[boldPayments[i].IntegrationID] = &boldPayments[i]
+		if boldPayments[i].POSPaymentID > 0 {
+			boldByPOSPayment[boldPayments[i].POSPaymentID] = &boldPayments[i]
+		}
+	}
+	for i := range posPayments {
+		pos := &posPayments[i]
+		bold := boldByPOSPayment[pos.ID]
+		if bold == nil && pos.BoldIntegrationID != "" {
+			bold = boldByIntegration[pos.BoldIntegrationID]
+		}
+		if bold != nil && !usedBold[bold] {
+			match(bold, pos, "integration_id")
+		}
+	}
+
+	// 2. Match by reference (approval number or Bold code typed in the payment reference)
+	for i := range posPayments {
+		pos := &posPayments[i]
+		if usedPOS[pos.ID] || pos.Reference == "" {
+			continue
+		}
+		for j := range boldPayments {
+			bold := &boldPayments[j]
+			if usedBold[bold] {
+				continue
+			}
+			if bold.Reference == pos.Reference ||
+				(bold.ApprovalNumber != "" && strings.Contains(pos.Reference, bold.ApprovalNumber)) ||
+				(bold.BoldCode != "" && strings.Contains(pos.Reference, bold.BoldCode)) {
+				match(bold, pos, "reference")
+				break
+			}
+		}
+	}
+
+	// 3. Match by amount, taking the closest Bold transaction in time
+	for i := range posPayments {
+		pos := &posPayments[i]
+		if usedPOS[pos.ID] {
+			continue
+		}
+		var best *models.BoldPendingPayment
+		for j := range boldPayments {
+			bold := &boldPayments[j]
+			if usedBold[bold] || math.Abs(bold.Amount-(pos.Amount+pos.TipAmount)) > tolerance {
+				continue
+			}
+			if best == nil || absDuration(bold.CreatedAt.Sub(pos.CreatedAt)) < absDuration(best.CreatedAt.Sub(pos.CreatedAt)) {
+				best = bold
+			}
+		}
+		if best != nil {
+			match(best, pos, "amount")
+		}
+	}
+
+	// 4. Orphans on either side
+	for i := range boldPayments {
+		bold := &boldPayments[i]
+		if usedBold[bold] {
+			continue
+		}
+		item := newBoldSideItem(bold)
+		item.MatchStatus = models.BoldMatchBoldOrphan
+		item.Difference = item.BoldAmount
+		item.Notes = "Aprobada en Bold sin venta registrada en el POS"
+		items = append(items, item)
+	}
+	orphanStatus, err := s.trackedStatuses(posPayments, usedPOS)
+	if err != nil {
+		return nil, err
+	}
+	for i := range posPayments {
+		pos := &posPayments[i]
+		if usedPOS[pos.ID] {
+			continue
+		}
+		item := models.BoldReconciliationItem{MatchStatus: models.BoldMatchPOSOrphan}
+		fillPOSSide(&item, pos)
+		item.IntegrationID = pos.BoldIntegrationID
+		item.Difference = -item.POSAmount
+		item.Notes = "Venta registrada como Bold sin aprobación en Bold"
+		if status, ok := orphanStatus[pos.BoldIntegrationID]; ok {
+			item.BoldStatus = status
+			item.Notes = fmt.Sprintf("Venta registrada como Bold con estado '%s' en Bold", status)
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return itemTime(items[i]).Before(itemTime(items[j]))
+	})
+
+	reconciliation := &models.BoldReconciliation{
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		CashRegisterID: registerID,
+		Source:         source,
+		GeneratedBy:    employeeID,
+		Items:          items,
+	}
+	for _, bold := range boldPayments {
+		reconciliation.BoldCount++
+		reconciliation.BoldTotal += bold.Amount
+	}
+	for _, pos := range posPayments {
+		reconciliation.POSCount++
+		reconciliation.POSTotal += pos.Amount + pos.TipAmount
+	}
+	for _, item := range items {
+		switch item.MatchStatus {
+		case models.BoldMatchMatched:
+			reconciliation.MatchedCount++
+			reconciliation.MatchedTotal += item.BoldAmount
+		case models.BoldMatchAmountMismatch:
+			reconciliation.AmountMismatchCount++
+		case models.BoldMatchBoldOrphan:
+			reconciliation.BoldOrphanCount++
+			reconciliation.BoldOrphanTotal += item.BoldAmount
+		case models.BoldMatchPOSOrphan:
+			reconciliation.POSOrphanCount++
+			reconciliation.POSOrphanTotal += item.POSAmount
+		}
+	}
+	reconciliation.Difference = reconciliation.BoldTotal - reconciliation.POSTotal
+	reconciliation.Status = "balanced"
+	if reconciliation.AmountMismatchCount+reconciliation.BoldOrphanCount+reconciliation.POSOrphanCount > 0 {
+		reconciliation.Status = "discrepancies"
+	}
+
+	if err := s.db.Create(reconciliation).Error; err != nil {
+		return nil, fmt.Errorf("error saving reconciliation: %w", err)
+	}
+
+	log.Printf("💳 [BOLD] Reconciliation %d (%s - %s): %d matched, %d mismatched, %d Bold orphans, %d POS orphans",
+		reconciliation.ID, start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04"),
+		reconciliation.MatchedCount, reconciliation.AmountMismatchCount,
+		reconciliation.BoldOrphanCount, reconciliation.POSOrphanCount)
+
+	if reconciliation.Status == "discrepancies" && s.wsServer != nil {
+		s.wsServer.BroadcastJSON("bold_reconciliation_discrepancies", map[string]interface{}{
+			"reconciliation_id":     reconciliation.ID,
+			"period_start":          start,
+			"period_end":            end,
+			"amount_mismatch_count": reconciliation.AmountMismatchCount,
+			"bold_orphan_count":     reconciliation.BoldOrphanCount,
+			"pos_orphan_count":      reconciliation.POSOrphanCount,
+		})
+	}
+
+	return reconciliation, nil
+}
+
+// reconciledBoldStatuses are the Bold statuses of money that was charged (voids included, they
+// were charged first)
+var reconciledBoldStatuses = map[string]bool{"approved": true, "voided": true, "partially_refunded": true}
+
+// boldTransactions returns the Bold side of a period: the transactions listed by the Bold API,
+// with the POS context of our tracking rows when we have one. Transactions made on the terminal
+// without going through the POS show up as well. When the API can't be reached the tracking rows
+// are used alone (source "local").
+func (s *BoldReconciliationService) boldTransactions(start, end time.Time, registerID *uint) ([]models.BoldPendingPayment, string, error) {
+	inRegister := func(payment *models.BoldPendingPayment) bool {
+		return registerID == nil || payment.CashRegisterID == 0 || payment.CashRegisterID == *registerID
+	}
+
+	listed, err := NewBoldService(s.db).ListTransactions(start, end)
+	if err != nil {
+		log.Printf("⚠️  [BOLD] Could not list transactions from the Bold API, reconciling with local records: %v", err)
+		var tracked []models.BoldPendingPayment
+		if err := s.db.Where("created_at >= ? AND created_at < ?", start, end).
+			Where("status IN ?", []string{"approved", "voided", "partially_refunded"}).
+			Order("created_at").Find(&tracked).Error; err != nil {
+			return nil, "", fmt.Errorf("error loading Bold transactions: %w", err)
+		}
+		payments := make([]models.BoldPendingPayment, 0, len(tracked))
+		for i := range tracked {
+			if inRegister(&tracked[i]) {
+				payments = append(payments, tracked[i])
+			}
+		}
+		return payments, "local", nil
+	}
+
+	// Our tracking rows of the listed transactions, in one query
+	var paymentIDs, integrationIDs []string
+	for _, transaction := range listed {
+		if transaction.PaymentID != "" {
+			paymentIDs = append(paymentIDs, transaction.PaymentID)
+		}
+		if transaction.IntegrationID != "" {
+			integrationIDs = append(integrationIDs, transaction.IntegrationID)
+		}
+	}
+	var tracked []models.BoldPendingPayment
+	if len(paymentIDs)+len(integrationIDs) > 0 {
+		trackedQuery := s.db.Where("1 = 0")
+		if len(paymentIDs) > 0 {
+			trackedQuery = trackedQuery.Or("payment_id IN ?", paymentIDs)
+		}
+		if len(integrationIDs) > 0 {
+			trackedQuery = trackedQuery.Or("integration_id IN ?", integrationIDs)
+		}
+		if err := trackedQuery.Find(&tracked).Error; err != nil {
+			return nil, "", fmt.Errorf("error loading Bold transactions: %w", err)
+		}
+	}
+	byPaymentID := make(map[string]*models.BoldPendingPayment)
+	byIntegration := make(map[string]*models.BoldPendingPayment)
+	for i := range tracked {
+		if tracked[i].PaymentID != "" {
+			byPaymentID[tracked[i].PaymentID] = &tracked[i]
+		}
+		byIntegration[tracked[i].IntegrationID] = &tracked[i]
+	}
+
+	payments := make([]models.BoldPendingPayment, 0, len(listed))
+	for _, transaction := range listed {
+		status := strings.ToLower(transaction.Status)
+		if !reconciledBoldStatuses[status] {
+			continue
+		}
+
+		var payment models.BoldPendingPayment
+		if local := byPaymentID[transaction.PaymentID]; local != nil {
+			payment = *local
+		} else if local := byIntegration[transaction.IntegrationID]; local != nil && transaction.IntegrationID != "" {
+			payment = *local
+		} else {
+			payment = models.BoldPendingPayment{IntegrationID: transaction.IntegrationID, Reference: transaction.Reference}
+		}
+
+		// Bold is the source of truth for what was charged
+		payment.PaymentID = transaction.PaymentID
+		payment.Status = status
+		payment.Amount = transaction.TotalAmount
+		if transaction.ApprovalNumber != "" {
+			payment.ApprovalNumber = transaction.ApprovalNumber
+		}
+		if transaction.BoldCode != "" {
+			payment.BoldCode = transaction.BoldCode
+		}
+		if transaction.CardBrand != "" {
+			payment.CardBrand = transaction.CardBrand
+			payment.CardMaskedPan = transaction.CardMaskedPan
+		}
+		if at, err := time.Parse(time.RFC3339, transaction.TransactionAt); err == nil {
+			payment.CreatedAt = at
+		}
+
+		if inRegister(&payment) {
+			payments = append(payments, payment)
+		}
+	}
+	sort.SliceStable(payments, func(i, j int) bool {
+		return payments[i].CreatedAt.Before(payments[j].CreatedAt)
+	})
+	return payments, "bold_api", nil
+}
+
+// trackedStatuses returns the Bold status of the unmatched POS payments that have an integration_id
+func (s *BoldReconciliationService) trackedStatuses(posPayments []boldPOSPayment, usedPOS map[uint]bool) (map[string]string, error) {
+	var integrationIDs []string
+	for _, pos := range posPayments {
+		if !usedPOS[pos.ID] && pos.BoldIntegrationID != "" {
+			integrationIDs = append(integrationIDs, pos.BoldIntegrationID)
+		}
+	}
+	statuses := make(map[string]string)
+	if len(integrationIDs) == 0 {
+		return statuses, nil
+	}
+
+	var tracked []models.BoldPendingPayment
+	if err := s.db.Select("integration_id", "status").Where("integration_id IN ?", integrationIDs).Find(&tracked).Error; err != nil {
+		return nil, fmt.Errorf("error loading Bold payments: %w", err)
+	}
+	for _, pending := range tracked {
+		statuses[pending.IntegrationID] = pending.Status
+	}
+	return statuses, nil
+}
+
+func newBoldSideItem(bold *models.BoldPendingPayment) models.BoldReconciliationItem {
+	boldAt := bold.CreatedAt
+	return models.BoldReconciliationItem{
+		IntegrationID:  bold.IntegrationID,
+		BoldPaymentID:  bold.PaymentID,
+		ApprovalNumber: bold.ApprovalNumber,
+		CardBrand:      bold.CardBrand,
+		CardMaskedPan:  bold.CardMaskedPan,
+		BoldStatus:     bold.Status,
+		BoldAmount:     bold.Amount,
+		BoldAt:         &boldAt,
+	}
+}
+
+func fillPOSSide(item *models.BoldReconciliationItem, pos *boldPOSPayment) {
+	posAt := pos.CreatedAt
+	item.PaymentID = pos.ID
+	item.SaleID = pos.SaleID
+	item.SaleNumber = pos.SaleNumber
+	item.POSAmount = pos.Amount + pos.TipAmount
+	item.POSAt = &posAt
+}
+
+func itemTime(item models.BoldReconciliationItem) time.Time {
+	if item.BoldAt != nil {
+		return *item.BoldAt
+	}
+	if item.POSAt != nil {
+		return *item.POSAt
+	}
+	return time.Time{}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// amountTolerance returns the accepted difference (in pesos) between Bold and POS amounts
+func (s *BoldReconciliationService) amountTolerance() float64 {
+	tolerance := NewConfigService().GetSystemConfigInt("bold_reconciliation_tolerance", 1)
+	if tolerance < 0 {
+		tolerance = 0
+	}
+	return float64(tolerance)
+}
+
+// GetReconciliation returns a reconciliation with its items
+func (s *BoldReconciliationService) GetReconciliation(id uint) (*models.BoldReconciliation, error) {
+	var reconciliation models.BoldReconciliation
+	if err := s.db.Preload("Items").First(&reconciliation, id).Error; err != nil {
+		return nil, fmt.Errorf("conciliación no encontrada")
+	}
+	return &reconciliation, nil
+}
+
+// GetReconciliations returns the latest reconciliations without items
+func (s *BoldReconciliationService) GetReconciliations(limit int) ([]models.BoldReconciliation, error) {
+	if limit <= 0 {
+		limit = 30
+	}
+	var reconciliations []models.BoldReconciliation
+	err := s.db.Order("created_at DESC").Limit(limit).Find(&reconciliations).Error
+	return reconciliations, err
+}
+
+// ExportReconciliationCSV exports a reconciliation to CSV
+func (s *BoldReconciliationService) ExportReconciliationCSV(id uint) ([]byte, error) {
+	reconciliation, err := s.GetReconciliation(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	writer.Write([]string{"Conciliación Bold", fmt.Sprintf("%d", reconciliation.ID)})
+	writer.Write([]string{"Desde", reconciliation.PeriodStart.Format("2006-01-02 15:04")})
+	writer.Write([]string{"Hasta", reconciliation.PeriodEnd.Format("2006-01-02 15:04")})
+	if reconciliation.CashRegisterID != nil {
+		writer.Write([]string{"Caja", fmt.Sprintf("%d", *reconciliation.CashRegisterID)})
+	}
+	writer.Write([]string{})
+
+	writer.Write([]string{"Estado", "Cruce por", "Integration ID", "Payment ID Bold", "Aprobación", "Franquicia", "Tarjeta",
+		"Estado Bold", "Fecha Bold", "Monto Bold", "Venta", "Fecha POS", "Monto POS", "Diferencia", "Observaciones"})
+	for _, item := range reconciliation.Items {
+		writer.Write([]string{
+			item.MatchStatus,
+			item.MatchedBy,
+			item.IntegrationID,
+			item.BoldPaymentID,
+			item.ApprovalNumber,
+			item.CardBrand,
+			item.CardMaskedPan,
+			item.BoldStatus,
+			formatOptionalTime(item.BoldAt),
+			fmt.Sprintf("%.2f", item.BoldAmount),
+			item.SaleNumber,
+			formatOptionalTime(item.POSAt),
+			fmt.Sprintf("%.2f", item.POSAmount),
+			fmt.Sprintf("%.2f", item.Difference),
+			item.Notes,
+		})
+	}
+
+	writer.Write([]string{})
+	writer.Write([]string{"Resumen"})
+	writer.Write([]string{"Transacciones Bold", fmt.Sprintf("%d", reconciliation.BoldCount), fmt.Sprintf("%.2f", reconciliation.BoldTotal)})
+	writer.Write([]string{"Pagos POS", fmt.Sprintf("%d", reconciliation.POSCount), fmt.Sprintf("%.2f", reconciliation.POSTotal)})
+	writer.Write([]string{"Conciliados", fmt.Sprintf("%d", reconciliation.MatchedCount), fmt.Sprintf("%.2f", reconciliation.MatchedTotal)})
+	writer.Write([]string{"Diferencia de monto", fmt.Sprintf("%d", reconciliation.AmountMismatchCount)})
+	writer.Write([]string{"Aprobados en Bold sin venta", fmt.Sprintf("%d", reconciliation.BoldOrphanCount), fmt.Sprintf("%.2f", reconciliation.BoldOrphanTotal)})
+	writer.Write([]string{"Ventas Bold sin aprobación", fmt.Sprintf("%d", reconciliation.POSOrphanCount), fmt.Sprintf("%.2f", reconciliation.POSOrphanTotal)})
+	writer.Write([]string{"Diferencia total", fmt.Sprintf("%.2f", reconciliation.Difference)})
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// StartDailyReconciliation reconciles the previous day once a day, after the configured hour
+func (s *BoldReconciliationService) StartDailyReconciliation() {
+	if s.stopChan != nil || s.db == nil {
+		return
+	}
+	s.stopChan = make(chan bool)
+
+	go func(stop chan bool) {
+		ticker := time.NewTicker(30 * time.Minute)
+		defer ticker.Stop()
+
+		log.Println("💳 [BOLD] Daily reconciliation job started")
+		s.runDailyReconciliation()
+		for {
+			select {
+			case <-ticker.C:
+				s.runDailyReconciliation()
+			case <-stop:
+				log.Println("💳 [BOLD] Daily reconciliation job stopped")
+				return
+			}
+		}
+	}(s.stopChan)
+}
+
+// StopDailyReconciliation stops the daily reconciliation job
+func (s *BoldReconciliationService) StopDailyReconciliation() {
+	if s.stopChan == nil {
+		return
+	}
+	close(s.stopChan)
+	s.stopChan = nil
+}
+
+// runDailyReconciliation reconciles yesterday if Bold is enabled and it hasn't been done yet
+func (s *BoldReconciliationService) runDailyReconciliation() {
+	var config models.BoldConfig
+	if err := s.db.First(&config).Error; err != nil || !config.Enabled {
+		return
+	}
+
+	hour := NewConfigService().GetSystemConfigInt("bold_reconciliation_hour", 6)
+	now := time.Now()
+	if now.Hour() < hour {
+		return
+	}
+
+	yesterday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, -1)
+	var count int64
+	s.db.Model(&models.BoldReconciliation{}).
+		Where("period_start = ? AND cash_register_id IS NULL", yesterday).
+		Count(&count)
+	if count > 0 {
+		return
+	}
+
+	if _, err := s.ReconcileDay(yesterday, 0); err != nil {
+		log.Printf("💳 [BOLD] Daily reconciliation failed: %v", err)
+	}
+}
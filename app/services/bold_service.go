@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"PosApp/app/models"
@@ -207,6 +210,67 @@ func (s *BoldService) GetTerminals() ([]models.BoldTerminalResponse, error) {
 	return result.Payload.AvailableTerminals, nil
 }
 
+// ListTransactions fetches the transactions made at Bold in a period, following the pages of the report
+func (s *BoldService) ListTransactions(start, end time.Time) ([]models.BoldTransaction, error) {
+	config, err := s.GetBoldConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return nil, fmt.Errorf("bold integration is not enabled")
+	}
+
+	apiKey, err := s.GetAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key not configured")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	var transactions []models.BoldTransaction
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("start_date", start.Format(time.RFC3339))
+		query.Set("end_date", end.Format(time.RFC3339))
+		query.Set("page", strconv.Itoa(page))
+		req, err := http.NewRequest("GET", config.BaseURL+"/payments/transactions?"+query.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+		req.Header.Set("Authorization", "x-api-key "+apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error executing request: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		}
+
+		var result struct {
+			Payload struct {
+				Transactions []models.BoldTransaction `json:"transactions"`
+				TotalPages   int                      `json:"total_pages"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("error parsing response: %w", err)
+		}
+		transactions = append(transactions, result.Payload.Transactions...)
+		if page >= result.Payload.TotalPages || len(result.Payload.Transactions) == 0 {
+			return transactions, nil
+		}
+	}
+}
+
 // CreatePaymentWithContext creates a payment through Bold API and tracks it as pending
 // This version accepts payment context for tracking
 func (s *BoldService) CreatePaymentWithContext(paymentReq *models.BoldPaymentRequest, paymentMethodID uint, paymentMethodName string, orderID, customerID, employeeID, cashRegisterID uint) (*models.BoldPaymentResponse, error) {
@@ -221,6 +285,7 @@ func (s *BoldService) CreatePaymentWithContext(paymentReq *models.BoldPaymentReq
 		IntegrationID:     response.Payload.IntegrationID,
 		Reference:         paymentReq.Reference,
 		Amount:            paymentReq.Amount.TotalAmount,
+		TipAmount:         paymentReq.Amount.TipAmount,
 		Status:            "pending",
 		PaymentMethodID:   paymentMethodID,
 		PaymentMethodName: paymentMethodName,
@@ -454,3 +519,162 @@ func (s *BoldService) TestConnection() error {
 	_, err := s.GetPaymentMethods()
 	return err
 }
+
+// VoidPayment voids (full) or refunds (partial) an approved Bold payment and tracks it until the webhook confirms it
+// amount <= 0 voids the full payment amount
+func (s *BoldService) VoidPayment(integrationID string, amount float64, reason string, employeeID, cashRegisterID uint) (*models.BoldPendingVoid, error) {
+	config, err := s.GetBoldConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return nil, fmt.Errorf("bold integration is not enabled")
+	}
+
+	apiKey, err := s.GetAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key not configured")
+	}
+
+	payment, err := s.GetPendingPayment(integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("bold payment %s not found: %w", integrationID, err)
+	}
+	if payment.Status != "approved" {
+		return nil, fmt.Errorf("bold payment %s cannot be voided (status: %s)", integrationID, payment.Status)
+	}
+	if payment.PaymentID == "" {
+		return nil, fmt.Errorf("bold payment %s has no payment_id (webhook not received yet)", integrationID)
+	}
+
+	// Only one void can be in flight per payment
+	var inFlight int64
+	s.db.Model(&models.BoldPendingVoid{}).
+		Where("integration_id = ? AND status = ?", integrationID, "pending").
+		Count(&inFlight)
+	if inFlight > 0 {
+		return nil, fmt.Errorf("a void is already pending for bold payment %s", integrationID)
+	}
+
+	if amount <= 0 || amount > payment.Amount {
+		amount = payment.Amount
+	}
+
+	void := &models.BoldPendingVoid{
+		IntegrationID:  integrationID,
+		PaymentID:      payment.PaymentID,
+		Reference:      fmt.Sprintf("VOID-%s-%d", payment.Reference, time.Now().Unix()),
+		Amount:         amount,
+		IsPartial:      amount < payment.Amount,
+		Reason:         reason,
+		Status:         "pending",
+		SaleID:         payment.SaleID,
+		POSPaymentID:   payment.POSPaymentID,
+		EmployeeID:     employeeID,
+		CashRegisterID: cashRegisterID,
+	}
+
+	voidReq := models.BoldVoidRequest{
+		Amount: models.BoldAmount{
+			Currency:    "COP",
+			Taxes:       []models.BoldTax{},
+			TotalAmount: amount,
+		},
+		Reason:    reason,
+		Reference: void.Reference,
+		UserEmail: config.UserEmail,
+	}
+
+	requestBody, err := json.Marshal(voidReq)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	// Full voids and partial refunds share the endpoint; Bold decides based on the amount
+	url := fmt.Sprintf("%s/payments/%s/void", config.BaseURL, payment.PaymentID)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "x-api-key "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
+		void.Status = "failed"
+		void.ErrorMessage = fmt.Sprintf("API returned status %d: %s", resp.StatusCode, string(body))
+		s.db.Create(void)
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result models.BoldVoidResponse
+	if err := json.Unmarshal(body, &result); err == nil {
+		void.VoidID = result.Payload.VoidID
+	}
+
+	if err := s.db.Create(void).Error; err != nil {
+		return nil, fmt.Errorf("error saving void tracker: %w", err)
+	}
+
+	// Mark the POS payment as waiting for the void confirmation
+	if payment.POSPaymentID > 0 {
+		s.db.Model(&models.Payment{}).
+			Where("id = ?", payment.POSPaymentID).
+			Update("status", models.PaymentStatusVoidPending)
+	}
+
+	fmt.Printf("↩️  Bold void requested: integration_id=%s amount=%.2f void_id=%s\n", integrationID, amount, void.VoidID)
+	return void, nil
+}
+
+// GetPendingVoids returns the void trackers, optionally filtered by sale (0 = all recent)
+func (s *BoldService) GetPendingVoids(saleID uint, limit int) ([]models.BoldPendingVoid, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var voids []models.BoldPendingVoid
+	query := s.db.Order("created_at DESC").Limit(limit)
+	if saleID > 0 {
+		query = query.Where("sale_id = ?", saleID)
+	}
+	err := query.Find(&voids).Error
+	return voids, err
+}
+
+// LinkPaymentToSale records which sale and POS payment completed a Bold payment
+func (s *BoldService) LinkPaymentToSale(tx *gorm.DB, integrationID string, saleID, posPaymentID uint) error {
+	return tx.Model(&models.BoldPendingPayment{}).
+		Where("integration_id = ?", integrationID).
+		Updates(map[string]interface{}{
+			"sale_id":        saleID,
+			"pos_payment_id": posPaymentID,
+		}).Error
+}
+
+// BoldIntegrationIDFromReference extracts the integration_id from a POS payment reference
+// built by the payment dialog ("Bold-<integration_id> | Código: ... | Aprob: ...")
+func BoldIntegrationIDFromReference(reference string) string {
+	if !strings.HasPrefix(reference, "Bold-") {
+		return ""
+	}
+	id := strings.TrimPrefix(reference, "Bold-")
+	if idx := strings.Index(id, " "); idx >= 0 {
+		id = id[:idx]
+	}
+	return strings.TrimSpace(id)
+}
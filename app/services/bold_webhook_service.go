@@ -6,10 +6,13 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
+	"strings"
 	"time"
 
 	"PosApp/app/models"
@@ -45,8 +48,8 @@ func (s *BoldWebhookService) SetWebSocketServer(wsServer WebSocketServer) {
 func (s *BoldWebhookService) StartWebhookServer(port int) error {
 	mux := http.NewServeMux()
 
-	// Webhook endpoint
-	mux.HandleFunc("/webhook/bold", s.handleWebhook)
+	// Webhook endpoint of every registered payment gateway: /webhook/<gateway id>
+	mux.HandleFunc("/webhook/", s.handleWebhook)
 
 	// Health check endpoint
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -74,7 +77,7 @@ func (s *BoldWebhookService) StartWebhookServer(port int) error {
 	}
 
 	log.Printf("🌐 Bold webhook server starting on port %d", port)
-	log.Printf("📡 Webhook endpoint: http://localhost:%d/webhook/bold", port)
+	log.Printf("📡 Webhook endpoints: http://localhost:%d/webhook/<pasarela> (Bold: /webhook/bold)", port)
 
 	// Start server in goroutine
 	go func() {
@@ -86,7 +89,7 @@ func (s *BoldWebhookService) StartWebhookServer(port int) error {
 	return nil
 }
 
-// handleWebhook processes incoming webhook notifications from Bold
+// handleWebhook processes incoming webhook notifications of the payment gateway in the path
 func (s *BoldWebhookService) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	log.Printf("🔔 Webhook request received: Method=%s, URL=%s, RemoteAddr=%s", r.Method, r.URL.Path, r.RemoteAddr)
 
@@ -120,6 +123,17 @@ func (s *BoldWebhookService) handleWebhook(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	gatewayID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/webhook/"), "/")
+	gateway, err := NewPaymentGateway(s.db, gatewayID, "")
+	if err != nil {
+		log.Printf("❌ %v", err)
+		webhookLog.ProcessStatus = "failed_gateway"
+		webhookLog.ErrorMessage = err.Error()
+		s.db.Create(webhookLog)
+		http.NotFound(w, r)
+		return
+	}
+
 	// Read request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -138,21 +152,63 @@ func (s *BoldWebhookService) handleWebhook(w http.ResponseWriter, r *http.Reques
 	log.Printf("📦 Webhook body received (%d bytes)", len(body))
 	log.Printf("📄 Webhook body: %s", string(body))
 
-	// Get signature from header
-	signature := r.Header.Get("x-bold-signature")
-	log.Printf("🔑 Signature from header: %s", signature)
-
-	// Verify signature
-	config, err := s.boldService.GetBoldConfig()
+	// Verify signature and parse
+	event, err := gateway.ParseWebhook(body, r.Header)
 	if err != nil {
-		log.Printf("❌ Error getting Bold config: %v", err)
-		webhookLog.ProcessStatus = "failed_config"
-		webhookLog.ErrorMessage = fmt.Sprintf("Error getting Bold config: %v", err)
+		webhookLog.ErrorMessage = err.Error()
+		if errors.Is(err, ErrGatewayWebhookSignature) {
+			webhookLog.ProcessStatus = "failed_signature"
+			s.db.Create(webhookLog)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		} else {
+			webhookLog.ProcessStatus = "failed_parse"
+			s.db.Create(webhookLog)
+			http.Error(w, "Invalid notification", http.StatusBadRequest)
+		}
+		return
+	}
+
+	// Log notification
+	log.Printf("📨 %s webhook received: Type=%s, TransactionID=%s", gateway.ID(), event.Type, event.TransactionID)
+
+	// Process webhook based on type
+	if err := s.processGatewayEvent(gateway, event); err != nil {
+		log.Printf("❌ Error processing webhook: %v", err)
+		webhookLog.ProcessStatus = "failed_processing"
+		webhookLog.ErrorMessage = fmt.Sprintf("Error processing: %v", err)
+		webhookLog.MatchedPayment = false
 		s.db.Create(webhookLog)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		// Still return 200 to prevent Bold from retrying
+		w.WriteHeader(http.StatusOK)
 		return
 	}
 
+	// Success! Save log entry
+	webhookLog.ProcessStatus = "success"
+	webhookLog.MatchedPayment = true
+	s.db.Create(webhookLog)
+
+	log.Printf("✅ Webhook processed successfully and logged")
+
+	// Respond with 200 OK
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// processGatewayEvent applies a notification to the gateway's transactions and tells the frontend
+func (s *BoldWebhookService) processGatewayEvent(gateway PaymentGateway, event *GatewayWebhookEvent) error {
+	if err := gateway.ApplyWebhook(event, s.wsServer); err != nil {
+		return err
+	}
+	if s.wsServer != nil {
+		s.wsServer.BroadcastJSON("payment_gateway_update", event)
+	}
+	return nil
+}
+
+// parseBoldWebhook verifies the signature of a Bold notification and parses it
+// On error it also returns the webhook log status ("failed_signature", "failed_parse")
+func parseBoldWebhook(config *models.BoldConfig, body []byte, signature string) (*models.BoldWebhookNotification, string, error) {
 	log.Printf("🌍 Bold environment: %s", config.Environment)
 
 	// Get the appropriate secret key based on environment
@@ -173,22 +229,14 @@ func (s *BoldWebhookService) handleWebhook(w http.ResponseWriter, r *http.Reques
 	// Only verify signature if we have both signature and secret key
 	// In test environment, signature verification is optional
 	if signature != "" && secretKey != "" {
-		if !s.verifySignature(body, signature, secretKey) {
+		if !verifyBoldSignature(body, signature, secretKey) {
 			log.Printf("❌ Invalid webhook signature - Expected vs Received mismatch")
-			webhookLog.ProcessStatus = "failed_signature"
-			webhookLog.ErrorMessage = "Invalid signature"
-			s.db.Create(webhookLog)
-			http.Error(w, "Invalid signature", http.StatusUnauthorized)
-			return
+			return nil, "failed_signature", fmt.Errorf("Invalid signature")
 		}
 		log.Printf("✅ Signature verified successfully")
 	} else if config.Environment == "production" && signature == "" {
 		log.Printf("⚠️  Production environment but no signature received - rejecting")
-		webhookLog.ProcessStatus = "failed_signature"
-		webhookLog.ErrorMessage = "Missing signature in production"
-		s.db.Create(webhookLog)
-		http.Error(w, "Missing signature", http.StatusUnauthorized)
-		return
+		return nil, "failed_signature", fmt.Errorf("Missing signature in production")
 	} else {
 		log.Printf("⚠️  Skipping signature verification (test environment or no signature/secret configured)")
 	}
@@ -198,43 +246,14 @@ func (s *BoldWebhookService) handleWebhook(w http.ResponseWriter, r *http.Reques
 	if err := json.Unmarshal(body, &notification); err != nil {
 		log.Printf("❌ Error parsing webhook notification: %v", err)
 		log.Printf("   Raw body was: %s", string(body))
-		webhookLog.ProcessStatus = "failed_parse"
-		webhookLog.ErrorMessage = fmt.Sprintf("Error parsing JSON: %v", err)
-		s.db.Create(webhookLog)
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
-	}
-
-	// Log notification
-	log.Printf("📨 Bold webhook received: Type=%s, PaymentID=%s, Subject=%s",
-		notification.Type, notification.Data.PaymentID, notification.Subject)
-
-	// Process webhook based on type
-	if err := s.processWebhook(&notification); err != nil {
-		log.Printf("❌ Error processing webhook: %v", err)
-		webhookLog.ProcessStatus = "failed_processing"
-		webhookLog.ErrorMessage = fmt.Sprintf("Error processing: %v", err)
-		webhookLog.MatchedPayment = false
-		s.db.Create(webhookLog)
-		// Still return 200 to prevent Bold from retrying
-		w.WriteHeader(http.StatusOK)
-		return
+		return nil, "failed_parse", fmt.Errorf("Error parsing JSON: %v", err)
 	}
 
-	// Success! Save log entry
-	webhookLog.ProcessStatus = "success"
-	webhookLog.MatchedPayment = true
-	s.db.Create(webhookLog)
-
-	log.Printf("✅ Webhook processed successfully and logged")
-
-	// Respond with 200 OK
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("OK"))
+	return &notification, "", nil
 }
 
-// verifySignature verifies the webhook signature using HMAC-SHA256
-func (s *BoldWebhookService) verifySignature(body []byte, signature string, secretKey string) bool {
+// verifyBoldSignature verifies the webhook signature using HMAC-SHA256
+func verifyBoldSignature(body []byte, signature string, secretKey string) bool {
 	// Convert body to base64
 	encoded := base64.StdEncoding.EncodeToString(body)
 
@@ -281,6 +300,10 @@ func (s *BoldWebhookService) processWebhook(notification *models.BoldWebhookNoti
 	if err == gorm.ErrRecordNotFound {
 		// Try to find by reference
 		err = s.db.Where("reference = ?", notification.Data.Metadata.Reference).First(&pendingPayment).Error
+		if err == gorm.ErrRecordNotFound && notification.Data.PaymentID != "" {
+			// Void notifications may only carry Bold's payment_id of the original payment
+			err = s.db.Where("payment_id = ?", notification.Data.PaymentID).First(&pendingPayment).Error
+		}
 		if err != nil {
 			log.Printf("⚠️  No pending payment found for integration_id=%s or reference=%s",
 				integrationID, notification.Data.Metadata.Reference)
@@ -290,6 +313,14 @@ func (s *BoldWebhookService) processWebhook(notification *models.BoldWebhookNoti
 		return fmt.Errorf("error finding pending payment: %w", err)
 	}
 
+	// Store full webhook data as JSON
+	webhookJSON, _ := json.Marshal(notification)
+
+	// Void notifications resolve a void request and keep the data of the original sale
+	if notification.Type == "VOID_APPROVED" || notification.Type == "VOID_REJECTED" {
+		return s.applyVoidResult(&pendingPayment, notification.Type == "VOID_APPROVED", sanitizeString(string(webhookJSON)))
+	}
+
 	// Update pending payment with webhook data
 	// IMPORTANT: Sanitize all string fields to remove null bytes and invalid UTF-8
 	// PostgreSQL does not allow null bytes in text fields
@@ -302,10 +333,11 @@ func (s *BoldWebhookService) processWebhook(notification *models.BoldWebhookNoti
 		pendingPayment.ApprovalNumber = sanitizeString(notification.Data.ApprovalNumber)
 	}
 
-	// Store full webhook data as JSON
-	webhookJSON, _ := json.Marshal(notification)
 	pendingPayment.WebhookData = sanitizeString(string(webhookJSON))
 
+	// Status before this notification (a link may have been cancelled or expired in the POS)
+	previousStatus := pendingPayment.Status
+
 	// Update status based on notification type
 	switch notification.Type {
 	case "SALE_APPROVED":
@@ -316,14 +348,6 @@ func (s *BoldWebhookService) processWebhook(notification *models.BoldWebhookNoti
 		pendingPayment.Status = "rejected"
 		log.Printf("❌ Payment rejected: %s", pendingPayment.IntegrationID)
 
-	case "VOID_APPROVED":
-		pendingPayment.Status = "voided"
-		log.Printf("↩️  Payment voided: %s", pendingPayment.IntegrationID)
-
-	case "VOID_REJECTED":
-		// Void rejected means the original payment is still valid
-		log.Printf("⚠️  Void rejected: %s", pendingPayment.IntegrationID)
-
 	default:
 		log.Printf("⚠️  Unknown notification type: %s", notification.Type)
 	}
@@ -356,9 +380,291 @@ func (s *BoldWebhookService) processWebhook(notification *models.BoldWebhookNoti
 		log.Printf("⚠️  No WebSocket server configured for notifications")
 	}
 
+	// Payment links complete the sale of the order automatically
+	if pendingPayment.Channel == models.BoldChannelLink && notification.Type == "SALE_APPROVED" {
+		s.completeLinkSale(&pendingPayment, previousStatus, notification.Data.Amount.Tip)
+	}
+
 	return nil
 }
 
+// completeLinkSale processes the sale of an order paid through a Bold payment link
+func (s *BoldWebhookService) completeLinkSale(link *models.BoldPendingPayment, previousStatus string, tip float64) {
+	fail := func(message string) {
+		log.Printf("⚠️  Payment link %s: %s", link.IntegrationID, message)
+		s.db.Model(link).Update("process_error", message)
+		if s.wsServer != nil {
+			s.wsServer.BroadcastJSON("bold_payment_link_alert", map[string]interface{}{
+				"integration_id": link.IntegrationID,
+				"order_id":       link.OrderID,
+				"amount":         link.Amount,
+				"error":          message,
+			})
+		}
+	}
+
+	if previousStatus == "cancelled" || previousStatus == "expired" {
+		fail(fmt.Sprintf("pago aprobado en Bold para un link en estado '%s', revise la orden y anule el pago si es necesario", previousStatus))
+		return
+	}
+	if link.SaleID > 0 {
+		return // Already completed (duplicate notification)
+	}
+
+	// Use the register the link was created from, or the one currently open
+	cashRegisterID := link.CashRegisterID
+	var register models.CashRegister
+	if err := s.db.Where("id = ? AND status = ?", cashRegisterID, "open").First(&register).Error; err != nil {
+		cashRegisterID = 0
+		if err := s.db.Where("status = ?", "open").Order("opened_at DESC").First(&register).Error; err == nil {
+			cashRegisterID = register.ID
+		}
+	}
+
+	amount := link.Amount - tip
+	if tip <= 0 || amount <= 0 {
+		tip = 0
+		amount = link.Amount
+	}
+	reference := fmt.Sprintf("Bold-%s | Link", link.IntegrationID)
+	if link.ApprovalNumber != "" {
+		reference += " | Aprob: " + link.ApprovalNumber
+	}
+
+	sale, err := NewSalesService().ProcessSale(link.OrderID, []PaymentData{{
+		PaymentMethodID:      link.PaymentMethodID,
+		Amount:               amount,
+		TipAmount:            tip,
+		Reference:            reference,
+		GatewayID:            BoldGatewayID,
+		GatewayTransactionID: link.IntegrationID,
+	}}, nil, false, false, link.EmployeeID, cashRegisterID, true)
+	if err != nil {
+		fail(fmt.Sprintf("no se pudo completar la venta: %v", err))
+		return
+	}
+
+	log.Printf("✅ Payment link %s completed sale %s", link.IntegrationID, sale.SaleNumber)
+	if s.wsServer != nil {
+		s.wsServer.BroadcastJSON("bold_payment_link_paid", map[string]interface{}{
+			"integration_id": link.IntegrationID,
+			"order_id":       link.OrderID,
+			"sale_id":        sale.ID,
+			"sale_number":    sale.SaleNumber,
+			"amount":         link.Amount,
+		})
+	}
+}
+
+// applyVoidResult resolves the pending void of a Bold payment and updates the linked POS payment
+func (s *BoldWebhookService) applyVoidResult(pendingPayment *models.BoldPendingPayment, approved bool, webhookData string) error {
+	var pendingVoid models.BoldPendingVoid
+	err := s.db.Where("integration_id = ? AND status = ?", pendingPayment.IntegrationID, "pending").
+		Order("created_at DESC").First(&pendingVoid).Error
+	if err == gorm.ErrRecordNotFound {
+		// Void made from the Bold dashboard, track it so the POS reflects it
+		pendingVoid = models.BoldPendingVoid{
+			IntegrationID: pendingPayment.IntegrationID,
+			PaymentID:     pendingPayment.PaymentID,
+			Reference:     pendingPayment.Reference,
+			Amount:        pendingPayment.Amount,
+			Reason:        "Anulación desde Bold",
+			SaleID:        pendingPayment.SaleID,
+			POSPaymentID:  pendingPayment.POSPaymentID,
+		}
+	} else if err != nil {
+		return fmt.Errorf("error finding pending void: %w", err)
+	}
+
+	now := time.Now()
+	pendingVoid.ResolvedAt = &now
+	pendingVoid.WebhookData = webhookData
+	paymentStatus := models.PaymentStatusVoidRejected
+	if approved {
+		pendingVoid.Status = "approved"
+		paymentStatus = models.PaymentStatusVoided
+		if pendingVoid.IsPartial {
+			paymentStatus = models.PaymentStatusPartlyVoided
+			pendingPayment.Status = "partially_refunded"
+		} else {
+			pendingPayment.Status = "voided"
+		}
+		log.Printf("↩️  Payment voided: %s (Amount: %.2f)", pendingPayment.IntegrationID, pendingVoid.Amount)
+	} else {
+		// Void rejected means the original payment is still valid
+		pendingVoid.Status = "rejected"
+		log.Printf("⚠️  Void rejected: %s", pendingPayment.IntegrationID)
+	}
+
+	var alert *voidAlert
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&pendingVoid).Error; err != nil {
+			return fmt.Errorf("error saving pending void: %w", err)
+		}
+		if err := tx.Save(pendingPayment).Error; err != nil {
+			return fmt.Errorf("error saving pending payment: %w", err)
+		}
+
+		// Update the POS payment linked to the Bold payment
+		var payment models.Payment
+		query := tx.Preload("PaymentMethod")
+		if pendingVoid.POSPaymentID > 0 {
+			query = query.Where("id = ?", pendingVoid.POSPaymentID)
+		} else {
+			query = query.Where("bold_integration_id = ?", pendingPayment.IntegrationID)
+		}
+		if err := query.First(&payment).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				log.Printf("⚠️  No POS payment linked to Bold payment %s", pendingPayment.IntegrationID)
+				return nil
+			}
+			return fmt.Errorf("error finding POS payment: %w", err)
+		}
+
+		// Voids requested by a POS refund carry the refunded amount of the payment
+		var refundVoid models.SaleRefundVoid
+		fromRefund := tx.Where("payment_id = ? AND status = ?", payment.ID, models.RefundVoidRequested).
+			Order("id DESC").First(&refundVoid).Error == nil
+
+		updates := map[string]interface{}{"status": paymentStatus}
+		if approved {
+			updates["voided_at"] = now
+			if !fromRefund {
+				// Void made from the Bold dashboard
+				if pendingVoid.IsPartial {
+					updates["void_amount"] = gorm.Expr("void_amount + ?", math.Min(pendingVoid.Amount, payment.Amount))
+				} else {
+					updates["void_amount"] = payment.Amount
+				}
+			}
+		} else if fromRefund {
+			updates["void_amount"] = gorm.Expr("void_amount - ?", refundVoid.SaleAmount)
+		}
+		if err := tx.Model(&payment).Updates(updates).Error; err != nil {
+			return fmt.Errorf("error updating POS payment: %w", err)
+		}
+
+		if fromRefund {
+			refundVoid.Status = models.RefundVoidVoided
+			if !approved {
+				refundVoid.Status = models.RefundVoidRejected
+			}
+			if err := tx.Model(&refundVoid).Update("status", refundVoid.Status).Error; err != nil {
+				return fmt.Errorf("error updating refund void: %w", err)
+			}
+			if !approved {
+				var err error
+				if alert, err = revertRefundVoid(tx, &refundVoid); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Money only leaves the drawer if the payment method is counted in the cash register
+		if !approved || payment.PaymentMethod == nil || !payment.PaymentMethod.AffectsCashRegister {
+			return nil
+		}
+		var sale models.Sale
+		if err := tx.First(&sale, payment.SaleID).Error; err != nil || sale.CashRegisterID == nil {
+			return nil
+		}
+		var register models.CashRegister
+		if err := tx.Where("id = ? AND status = ?", *sale.CashRegisterID, "open").First(&register).Error; err != nil {
+			log.Printf("⚠️  Cash register of sale %s is closed, void of %s not recorded in the register", sale.SaleNumber, pendingPayment.IntegrationID)
+			return nil
+		}
+		description := fmt.Sprintf("Anulación Bold %s", pendingPayment.IntegrationID)
+		return tx.Create(&models.CashMovement{
+			CashRegisterID: register.ID,
+			Type:           "refund",
+			Amount:         -pendingVoid.Amount,
+			Description:    description,
+			Reason:         description,
+			Reference:      sale.SaleNumber,
+			EmployeeID:     pendingVoid.EmployeeID,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("💾 Pending void updated: ID=%d, Status=%s", pendingVoid.ID, pendingVoid.Status)
+
+	if alert != nil {
+		log.Printf("🚨 %s", alert.Message)
+		if s.wsServer != nil {
+			s.wsServer.BroadcastJSON("bold_void_alert", map[string]interface{}{
+				"integration_id": pendingPayment.IntegrationID,
+				"sale_id":        alert.SaleID,
+				"sale_number":    alert.SaleNumber,
+				"amount":         alert.Amount,
+				"error":          alert.Message,
+			})
+		}
+	}
+
+	if s.wsServer != nil {
+		s.wsServer.BroadcastJSON("bold_void_update", map[string]interface{}{
+			"integration_id": pendingPayment.IntegrationID,
+			"void_id":        pendingVoid.ID,
+			"status":         pendingVoid.Status,
+			"amount":         pendingVoid.Amount,
+			"is_partial":     pendingVoid.IsPartial,
+			"sale_id":        pendingVoid.SaleID,
+			"payment_status": paymentStatus,
+		})
+	}
+
+	return nil
+}
+
+// voidAlert describes a refund left unpaid because the gateway rejected its void
+type voidAlert struct {
+	SaleID     uint
+	SaleNumber string
+	Amount     float64
+	Message    string
+}
+
+// revertRefundVoid takes a rejected void out of an applied refund: the sale is no longer fully
+// refunded and the money still owed to the customer is noted, so staff can return it another way
+// A refund that wasn't applied yet is just marked so it never is
+func revertRefundVoid(tx *gorm.DB, refundVoid *models.SaleRefundVoid) (*voidAlert, error) {
+	var refund models.SaleRefund
+	if err := tx.First(&refund, refundVoid.RefundID).Error; err != nil {
+		return nil, fmt.Errorf("error finding refund: %w", err)
+	}
+	previousStatus := refund.Status
+	if err := tx.Model(&refund).Updates(map[string]interface{}{
+		"status":        "void_rejected",
+		"error_message": fmt.Sprintf("anulación de %.2f rechazada por la pasarela", refundVoid.Amount),
+	}).Error; err != nil {
+		return nil, fmt.Errorf("error updating refund: %w", err)
+	}
+
+	var sale models.Sale
+	if err := tx.First(&sale, refund.SaleID).Error; err != nil {
+		return nil, fmt.Errorf("error finding sale: %w", err)
+	}
+	alert := &voidAlert{SaleID: sale.ID, SaleNumber: sale.SaleNumber, Amount: refundVoid.SaleAmount}
+	if previousStatus != "completed" {
+		alert.Message = fmt.Sprintf("la pasarela rechazó la anulación de %.2f de la venta %s, la devolución no se aplicó", refundVoid.Amount, sale.SaleNumber)
+		return alert, nil
+	}
+
+	alert.Message = fmt.Sprintf("la pasarela rechazó la anulación de %.2f de la venta %s: el dinero no volvió a la tarjeta, devuélvalo por otro medio o reintente la devolución", refundVoid.Amount, sale.SaleNumber)
+	updates := map[string]interface{}{
+		"notes": strings.TrimSpace(fmt.Sprintf("%s | Anulación rechazada: %.2f pendiente de devolver", sale.Notes, refundVoid.SaleAmount)),
+	}
+	if sale.Status == "refunded" {
+		updates["status"] = "partial_refund"
+	}
+	if err := tx.Model(&sale).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("error updating sale: %w", err)
+	}
+	return alert, nil
+}
+
 // GetPendingPayment retrieves a pending payment by integration ID
 func (s *BoldWebhookService) GetPendingPayment(integrationID string) (*models.BoldPendingPayment, error) {
 	var payment models.BoldPendingPayment
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"PosApp/app/models"
+)
+
+// Employee roles accepted by the Config API
+const (
+	RoleAdmin   = "admin"
+	RoleCashier = "cashier"
+	RoleWaiter  = "waiter"
+	RoleKitchen = "kitchen"
+)
+
+type apiContextKey string
+
+const apiEmployeeKey apiContextKey = "employee"
+
+// loginIPAttemptsFactor multiplies the per-username attempts allowed to a single client IP
+// across all usernames before the IP itself is locked
+const loginIPAttemptsFactor = 3
+
+// RevokeSessionsRequest represents the request body for revoking all sessions of an employee
+type RevokeSessionsRequest struct {
+	EmployeeID uint `json:"employee_id"`
+}
+
+// loginAttempt tracks failed logins for a single key (client IP, or client IP and username)
+type loginAttempt struct {
+	failures    int
+	firstFailAt time.Time
+	lockedUntil time.Time
+	window      time.Duration
+}
+
+// loginLimiter rate-limits login attempts and locks out keys after too many failures
+type loginLimiter struct {
+	mu       sync.Mutex
+	attempts map[string]*loginAttempt
+}
+
+func newLoginLimiter() *loginLimiter {
+	return &loginLimiter{attempts: make(map[string]*loginAttempt)}
+}
+
+// lockedFor returns how long a key is still locked out (0 if not locked)
+func (l *loginLimiter) lockedFor(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	attempt, exists := l.attempts[key]
+	if !exists {
+		return 0
+	}
+	if remaining := time.Until(attempt.lockedUntil); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// fail records a failed attempt and returns true if the key became locked
+func (l *loginLimiter) fail(key string, maxAttempts int, window time.Duration) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.prune(now)
+	attempt, exists := l.attempts[key]
+	if !exists || now.Sub(attempt.firstFailAt) > window {
+		attempt = &loginAttempt{firstFailAt: now, window: window}
+		l.attempts[key] = attempt
+	}
+	attempt.failures++
+	if attempt.failures >= maxAttempts {
+		attempt.lockedUntil = now.Add(window)
+		attempt.failures = 0
+		attempt.firstFailAt = now
+		return true
+	}
+	return false
+}
+
+// reset clears the failures of a key after a successful login
+func (l *loginLimiter) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.attempts, key)
+}
+
+// prune drops keys whose failure window and lockout are over, so the map doesn't grow forever
+// The caller must hold the lock
+func (l *loginLimiter) prune(now time.Time) {
+	for key, attempt := range l.attempts {
+		if now.After(attempt.lockedUntil) && now.Sub(attempt.firstFailAt) > attempt.window {
+			delete(l.attempts, key)
+		}
+	}
+}
+
+// requireAuth wraps a handler so it only runs with a valid session token
+// If roles are given, the employee must have one of them (admin is always allowed)
+func (s *ConfigAPIServer) requireAuth(handler http.HandlerFunc, roles ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.employeeService == nil {
+			s.sendJSON(w, http.StatusServiceUnavailable, APIResponse{
+				Success: false,
+				Error:   "Employee service not available",
+			})
+			return
+		}
+
+		token := bearerToken(r)
+		if token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="pos"`)
+			s.sendJSON(w, http.StatusUnauthorized, APIResponse{
+				Success: false,
+				Error:   "Authorization token required",
+			})
+			return
+		}
+
+		employee, err := s.employeeService.ValidateSession(token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="pos", error="invalid_token"`)
+			s.sendJSON(w, http.StatusUnauthorized, APIResponse{
+				Success: false,
+				Error:   "Invalid or expired token",
+			})
+			return
+		}
+
+		if !hasRole(employee.Role, roles) {
+			log.Printf("[CONFIG API] Forbidden: %s (role %s) tried %s %s", employee.Username, employee.Role, r.Method, r.URL.Path)
+			s.sendJSON(w, http.StatusForbidden, APIResponse{
+				Success: false,
+				Error:   "Insufficient permissions",
+			})
+			return
+		}
+
+		handler(w, r.WithContext(context.WithValue(r.Context(), apiEmployeeKey, employee)))
+	}
+}
+
+// authEmployee returns the authenticated employee of a request (nil if unauthenticated)
+func authEmployee(r *http.Request) *models.Employee {
+	employee, _ := r.Context().Value(apiEmployeeKey).(*models.Employee)
+	return employee
+}
+
+func hasRole(role string, allowed []string) bool {
+	if len(allowed) == 0 || role == RoleAdmin {
+		return true
+	}
+	for _, r := range allowed {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// clientIP returns the client IP
+// The Cloudflare tunnel headers are only honored when the request comes from the local
+// tunnel process; a client connecting directly on the LAN could set them to anything.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return host
+	}
+
+	if ip := strings.TrimSpace(r.Header.Get("CF-Connecting-IP")); ip != "" {
+		return ip
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	return host
+}
+
+// loginLimits returns the max failed attempts and the lockout window
+func (s *ConfigAPIServer) loginLimits() (int, time.Duration) {
+	maxAttempts, lockoutMinutes := 5, 15
+	if s.configService != nil {
+		maxAttempts = s.configService.GetSystemConfigInt("api_login_max_attempts", 5)
+		lockoutMinutes = s.configService.GetSystemConfigInt("api_login_lockout_minutes", 15)
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	if lockoutMinutes <= 0 {
+		lockoutMinutes = 15
+	}
+	return maxAttempts, time.Duration(lockoutMinutes) * time.Minute
+}
+
+// handleRefreshToken exchanges a valid token for a new one with a fresh expiration
+func (s *ConfigAPIServer) handleRefreshToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.sendJSON(w, http.StatusMethodNotAllowed, APIResponse{
+			Success: false,
+			Error:   "Method not allowed. Use POST.",
+		})
+		return
+	}
+
+	session, err := s.employeeService.RefreshSession(bearerToken(r), r.UserAgent(), clientIP(r))
+	if err != nil {
+		s.sendJSON(w, http.StatusUnauthorized, APIResponse{
+			Success: false,
+			Error:   "Invalid or expired token",
+		})
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: "Token refreshed",
+		Data:    newAuthUserData(session.Employee, session),
+	})
+}
+
+// handleLogout revokes the token used in the request
+func (s *ConfigAPIServer) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.sendJSON(w, http.StatusMethodNotAllowed, APIResponse{
+			Success: false,
+			Error:   "Method not allowed. Use POST.",
+		})
+		return
+	}
+
+	if err := s.employeeService.RevokeSession(bearerToken(r)); err != nil {
+		s.sendJSON(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to revoke session: %v", err),
+		})
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: "Session revoked",
+	})
+}
+
+// handleRevokeSessions revokes all sessions of an employee (admin only)
+func (s *ConfigAPIServer) handleRevokeSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.sendJSON(w, http.StatusMethodNotAllowed, APIResponse{
+			Success: false,
+			Error:   "Method not allowed. Use POST.",
+		})
+		return
+	}
+
+	var req RevokeSessionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.EmployeeID == 0 {
+		s.sendJSON(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "employee_id is required",
+		})
+		return
+	}
+
+	revoked, err := s.employeeService.RevokeEmployeeSessions(req.EmployeeID)
+	if err != nil {
+		s.sendJSON(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to revoke sessions: %v", err),
+		})
+		return
+	}
+
+	admin := authEmployee(r)
+	s.employeeService.LogAudit(admin.ID, "revoke_sessions", "employee", req.EmployeeID, "", fmt.Sprintf("%d", revoked), clientIP(r), r.UserAgent())
+	log.Printf("[CONFIG API] %s revoked %d sessions of employee %d", admin.Username, revoked, req.EmployeeID)
+
+	s.sendJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d sessions revoked", revoked),
+	})
+}
+
+// handleMe returns the authenticated employee
+func (s *ConfigAPIServer) handleMe(w http.ResponseWriter, r *http.Request) {
+	employee := authEmployee(r)
+	s.sendJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    newAuthUserData(employee, nil),
+	})
+}
+
+func newAuthUserData(employee *models.Employee, session *models.Session) AuthUserData {
+	data := AuthUserData{
+		ID:       employee.ID,
+		Name:     employee.Name,
+		Username: employee.Username,
+		Role:     employee.Role,
+		Email:    employee.Email,
+		Phone:    employee.Phone,
+	}
+	if session != nil {
+		data.Token = session.Token
+		expiresAt := session.ExpiresAt
+		data.ExpiresAt = &expiresAt
+	}
+	return data
+}
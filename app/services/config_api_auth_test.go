@@ -0,0 +1,65 @@
+package services
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"direct LAN client", "192.168.1.20:5000", nil, "192.168.1.20"},
+		{"LAN client spoofing tunnel headers", "192.168.1.20:5000",
+			map[string]string{"CF-Connecting-IP": "1.2.3.4", "X-Forwarded-For": "5.6.7.8"}, "192.168.1.20"},
+		{"tunnel with Cloudflare header", "127.0.0.1:5000",
+			map[string]string{"CF-Connecting-IP": "1.2.3.4", "X-Forwarded-For": "5.6.7.8"}, "1.2.3.4"},
+		{"tunnel with forwarded chain", "[::1]:5000",
+			map[string]string{"X-Forwarded-For": "5.6.7.8, 10.0.0.1"}, "5.6.7.8"},
+		{"loopback without headers", "127.0.0.1:5000", nil, "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if got := clientIP(r); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginLimiterLocksAndPrunes(t *testing.T) {
+	l := newLoginLimiter()
+	for i := 1; i < 3; i++ {
+		if l.fail("user:1.2.3.4|admin", 3, time.Minute) {
+			t.Fatalf("locked after %d failures, want 3", i)
+		}
+	}
+	if !l.fail("user:1.2.3.4|admin", 3, time.Minute) {
+		t.Fatal("not locked after 3 failures")
+	}
+	if l.lockedFor("user:1.2.3.4|admin") <= 0 {
+		t.Error("key should be locked")
+	}
+	if l.lockedFor("user:5.6.7.8|admin") > 0 {
+		t.Error("the same username from another IP should not be locked")
+	}
+
+	// Expired keys are dropped on the next failure
+	l.attempts["ip:9.9.9.9"] = &loginAttempt{failures: 1, firstFailAt: time.Now().Add(-time.Hour), window: time.Minute}
+	l.fail("ip:1.1.1.1", 3, time.Minute)
+	if _, exists := l.attempts["ip:9.9.9.9"]; exists {
+		t.Error("expired key was not pruned")
+	}
+	if _, exists := l.attempts["user:1.2.3.4|admin"]; !exists {
+		t.Error("locked key was pruned")
+	}
+}
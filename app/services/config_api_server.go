@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"PosApp/app/models"
@@ -23,6 +24,7 @@ type ConfigAPIServer struct {
 	loggerService       *LoggerService
 	salesService        *SalesService
 	configService       *ConfigService
+	loginLimiter        *loginLimiter
 }
 
 // InvoiceLimitConfigRequest represents the request body for updating invoice limits
@@ -45,8 +47,9 @@ type AuthUserData struct {
 	Username string `json:"username"`
 	Role     string `json:"role"`
 	Email    string `json:"email"`
-	Phone    string `json:"phone"`
-	Token    string `json:"token"`
+	Phone     string     `json:"phone"`
+	Token     string     `json:"token"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // APIResponse represents a standard API response
@@ -229,6 +232,7 @@ func NewConfigAPIServer(
 		loggerService:       loggerService,
 		salesService:        salesService,
 		configService:       configService,
+		loginLimiter:        newLoginLimiter(),
 	}
 }
 
@@ -242,28 +246,33 @@ func (s *ConfigAPIServer) Start() error {
 	// API info
 	mux.HandleFunc("/", s.handleInfo)
 
-	// Invoice limit configuration endpoints
-	mux.HandleFunc("/api/v1/config/invoice-limits", s.handleInvoiceLimits)
-	mux.HandleFunc("/api/v1/config/invoice-limits/status", s.handleInvoiceLimitsStatus)
-	mux.HandleFunc("/api/v1/config/invoice-limits/sync", s.handleInvoiceLimitsSync)
+	// Invoice limit configuration endpoints (admin only)
+	mux.HandleFunc("/api/v1/config/invoice-limits", s.requireAuth(s.handleInvoiceLimits, RoleAdmin))
+	mux.HandleFunc("/api/v1/config/invoice-limits/status", s.requireAuth(s.handleInvoiceLimitsStatus, RoleAdmin))
+	mux.HandleFunc("/api/v1/config/invoice-limits/sync", s.requireAuth(s.handleInvoiceLimitsSync, RoleAdmin))
 
-	// Authentication endpoint
+	// Authentication endpoints
 	mux.HandleFunc("/api/v1/auth/login", s.handleLogin)
+	mux.HandleFunc("/api/v1/auth/refresh", s.handleRefreshToken)
+	mux.HandleFunc("/api/v1/auth/logout", s.requireAuth(s.handleLogout))
+	mux.HandleFunc("/api/v1/auth/me", s.requireAuth(s.handleMe))
+	mux.HandleFunc("/api/v1/auth/validate", s.requireAuth(s.handleMe))
+	mux.HandleFunc("/api/v1/auth/revoke", s.requireAuth(s.handleRevokeSessions, RoleAdmin))
 
 	// Order endpoints for PWA
-	mux.HandleFunc("/api/v1/orders/types", s.handleGetOrderTypes)
-	mux.HandleFunc("/api/v1/orders/products", s.handleGetProducts)
-	mux.HandleFunc("/api/v1/orders/pending", s.handleGetPendingOrders)
-	mux.HandleFunc("/api/v1/orders", s.handleOrders)
+	mux.HandleFunc("/api/v1/orders/types", s.requireAuth(s.handleGetOrderTypes))
+	mux.HandleFunc("/api/v1/orders/products", s.requireAuth(s.handleGetProducts))
+	mux.HandleFunc("/api/v1/orders/pending", s.requireAuth(s.handleGetPendingOrders))
+	mux.HandleFunc("/api/v1/orders", s.requireAuth(s.handleOrders, RoleCashier, RoleWaiter))
 
 	// Table endpoint for PWA
-	mux.HandleFunc("/api/v1/tables", s.handleGetTables)
+	mux.HandleFunc("/api/v1/tables", s.requireAuth(s.handleGetTables))
 
 	// Sales endpoint for PWA
-	mux.HandleFunc("/api/v1/sales", s.handleGetSales)
+	mux.HandleFunc("/api/v1/sales", s.requireAuth(s.handleGetSales, RoleCashier, RoleWaiter))
 
 	// Tunnel configuration endpoint for mobile apps
-	mux.HandleFunc("/api/v1/server/tunnel", s.handleTunnelConfig)
+	mux.HandleFunc("/api/v1/server/tunnel", s.requireAuth(s.handleTunnelConfig))
 	mux.HandleFunc("/api/v1/server/info", s.handleServerInfo)
 
 	s.server = &http.Server{
@@ -281,11 +290,16 @@ func (s *ConfigAPIServer) Start() error {
 	log.Printf("[CONFIG API]   GET    /api/v1/config/invoice-limits/status")
 	log.Printf("[CONFIG API]   POST   /api/v1/config/invoice-limits/sync")
 	log.Printf("[CONFIG API]   POST   /api/v1/auth/login")
+	log.Printf("[CONFIG API]   POST   /api/v1/auth/refresh")
+	log.Printf("[CONFIG API]   POST   /api/v1/auth/logout")
+	log.Printf("[CONFIG API]   GET    /api/v1/auth/me")
+	log.Printf("[CONFIG API]   POST   /api/v1/auth/revoke")
 	log.Printf("[CONFIG API]   GET    /api/v1/orders/types")
 	log.Printf("[CONFIG API]   GET    /api/v1/orders/products")
 	log.Printf("[CONFIG API]   GET    /api/v1/orders/pending")
 	log.Printf("[CONFIG API]   POST   /api/v1/orders")
 	log.Printf("[CONFIG API]   GET    /api/v1/tables")
+	log.Printf("[CONFIG API] All endpoints except /health, /api/v1/auth/login and /api/v1/server/info require 'Authorization: Bearer <token>'")
 
 	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("config API server error: %w", err)
@@ -368,7 +382,11 @@ func (s *ConfigAPIServer) handleInfo(w http.ResponseWriter, r *http.Request) {
 				"PUT /api/v1/config/invoice-limits":    "Update invoice limit configuration",
 				"GET /api/v1/config/invoice-limits/status": "Get current invoice limit status",
 				"POST /api/v1/config/invoice-limits/sync":  "Force sync with Google Sheets",
+				"POST /api/v1/auth/login":                  "Login, returns a session token",
+				"POST /api/v1/auth/refresh":                "Exchange a valid token for a new one",
+				"POST /api/v1/auth/logout":                 "Revoke the current token",
 			},
+			"authentication": "Authorization: Bearer <token>",
 		},
 	})
 }
@@ -529,11 +547,6 @@ func (s *ConfigAPIServer) handleInvoiceLimitsSync(w http.ResponseWriter, r *http
 	})
 }
 
-// generateToken creates a simple token for the session
-func (s *ConfigAPIServer) generateToken(employeeID uint) string {
-	return fmt.Sprintf("%d:%d", employeeID, time.Now().UnixNano())
-}
-
 // handleLogin handles username/password login
 func (s *ConfigAPIServer) handleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -571,34 +584,61 @@ func (s *ConfigAPIServer) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reject locked out clients before checking the password
+	// Usernames are locked per client IP, so nobody can lock an employee out from elsewhere;
+	// the IP itself is locked after trying many usernames
+	ip := clientIP(r)
+	ipKey := "ip:" + ip
+	userKey := "user:" + ip + "|" + strings.ToLower(req.Username)
+	for _, key := range []string{ipKey, userKey} {
+		if remaining := s.loginLimiter.lockedFor(key); remaining > 0 {
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(remaining.Seconds())+1))
+			s.sendJSON(w, http.StatusTooManyRequests, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Too many failed login attempts. Try again in %d minutes", int(remaining.Minutes())+1),
+			})
+			return
+		}
+	}
+
 	// Authenticate
 	employee, err := s.employeeService.AuthenticateEmployee(req.Username, req.Password)
 	if err != nil {
-		log.Printf("[CONFIG API] Failed login attempt for user: %s", req.Username)
+		log.Printf("[CONFIG API] Failed login attempt for user: %s from %s", req.Username, ip)
+		maxAttempts, lockout := s.loginLimits()
+		if s.loginLimiter.fail(userKey, maxAttempts, lockout) {
+			log.Printf("[CONFIG API] Login locked for %s (%v) after %d failed attempts", userKey, lockout, maxAttempts)
+		}
+		if s.loginLimiter.fail(ipKey, maxAttempts*loginIPAttemptsFactor, lockout) {
+			log.Printf("[CONFIG API] Login locked for %s (%v) after %d failed attempts", ipKey, lockout, maxAttempts*loginIPAttemptsFactor)
+		}
 		s.sendJSON(w, http.StatusUnauthorized, APIResponse{
 			Success: false,
 			Error:   "Invalid credentials",
 		})
 		return
 	}
+	s.loginLimiter.reset(ipKey)
+	s.loginLimiter.reset(userKey)
 
-	// Generate token
-	token := s.generateToken(employee.ID)
+	// Issue a random session token stored in the sessions table
+	session, err := s.employeeService.CreateSession(employee.ID, r.UserAgent(), ip)
+	if err != nil {
+		log.Printf("[CONFIG API] Failed to create session for user %s: %v", employee.Username, err)
+		s.sendJSON(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to create session",
+		})
+		return
+	}
+	go s.employeeService.CleanExpiredSessions()
 
 	log.Printf("[CONFIG API] Successful login for user: %s (ID: %d, Role: %s)", employee.Username, employee.ID, employee.Role)
 
 	s.sendJSON(w, http.StatusOK, APIResponse{
 		Success: true,
 		Message: "Login successful",
-		Data: AuthUserData{
-			ID:       employee.ID,
-			Name:     employee.Name,
-			Username: employee.Username,
-			Role:     employee.Role,
-			Email:    employee.Email,
-			Phone:    employee.Phone,
-			Token:    token,
-		},
+		Data:    newAuthUserData(employee, session),
 	})
 }
 
@@ -836,6 +876,11 @@ func (s *ConfigAPIServer) handleOrders(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Orders are attributed to the authenticated employee; only admins may create on behalf of others
+	if caller := authEmployee(r); caller != nil && (req.EmployeeID == 0 || caller.Role != RoleAdmin) {
+		req.EmployeeID = caller.ID
+	}
+
 	// Create the order
 	order := &models.Order{
 		OrderTypeID:          &req.OrderTypeID,
@@ -1157,6 +1202,13 @@ func (s *ConfigAPIServer) handleTunnelConfig(w http.ResponseWriter, r *http.Requ
 	case http.MethodGet:
 		s.getTunnelConfig(w, r)
 	case http.MethodPut:
+		if employee := authEmployee(r); employee == nil || employee.Role != RoleAdmin {
+			s.sendJSON(w, http.StatusForbidden, APIResponse{
+				Success: false,
+				Error:   "Insufficient permissions",
+			})
+			return
+		}
 		s.updateTunnelConfig(w, r)
 	default:
 		s.sendJSON(w, http.StatusMethodNotAllowed, APIResponse{
@@ -115,7 +115,32 @@ func (s *ConfigService) InitializeDefaultSystemConfigs() error {
 	}{
 		{"low_stock_threshold", "10", "number", "inventory"},
 		{"kitchen_refresh_interval", "30", "number", "ui"},
+		{"kitchen_ticket_sla_minutes", "15", "number", "kitchen"},
+		{"reservation_default_duration", "90", "number", "reservations"},
+		{"reservation_hold_minutes", "30", "number", "reservations"},
+		{"reservation_no_show_minutes", "20", "number", "reservations"},
+		{"labor_daily_hours", "8", "number", "labor"},
+		{"labor_night_start_hour", "19", "number", "labor"},
+		{"labor_night_end_hour", "6", "number", "labor"},
+		{"labor_night_surcharge_percent", "35", "number", "labor"},
+		{"labor_overtime_day_percent", "25", "number", "labor"},
+		{"labor_overtime_night_percent", "75", "number", "labor"},
+		{"labor_sunday_surcharge_percent", "90", "number", "labor"},
+		{"labor_holidays", "", "string", "labor"},
+		{"session_duration_hours", "24", "number", "security"},
+		{"api_login_max_attempts", "5", "number", "security"},
+		{"api_login_lockout_minutes", "15", "number", "security"},
+		{"bold_reconciliation_hour", "6", "number", "bold"},
+		{"bold_reconciliation_tolerance", "1", "number", "bold"},
+		{"bold_payment_link_expiration_minutes", "60", "number", "bold"},
 		{"max_offline_days", "7", "number", "sync"},
+		{"update_channel", "stable", "string", "update"},
+		{"update_defer_until_closing", "false", "boolean", "update"},
+		{"backup_enabled", "true", "boolean", "backup"},
+		{"backup_interval_hours", "24", "number", "backup"},
+		{"backup_retention_count", "14", "number", "backup"},
+		{"backup_retention_days", "30", "number", "backup"},
+		{"backup_directory", "", "string", "backup"},
 		// Cloudflare Tunnel configuration
 		{"tunnel_enabled", "false", "boolean", "network"},
 		{"tunnel_url", "", "string", "network"},
@@ -395,6 +420,9 @@ func (s *ConfigService) SavePrinterConfig(config *models.PrinterConfig) error {
 	if err := s.EnsureDB(); err != nil {
 		return err
 	}
+	if config.BackupPrinterID != nil && config.ID != 0 && *config.BackupPrinterID == config.ID {
+		return fmt.Errorf("una impresora no puede ser su propio respaldo")
+	}
 	// If setting as default, unset other defaults
 	if config.IsDefault {
 		s.db.Model(&models.PrinterConfig{}).Where("id != ?", config.ID).Update("is_default", false)
@@ -411,6 +439,8 @@ func (s *ConfigService) DeletePrinterConfig(id uint) error {
 	if err := s.EnsureDB(); err != nil {
 		return err
 	}
+	// Printers backed up by this one lose their backup
+	s.db.Model(&models.PrinterConfig{}).Where("backup_printer_id = ?", id).Update("backup_printer_id", nil)
 	return s.db.Delete(&models.PrinterConfig{}, id).Error
 }
 
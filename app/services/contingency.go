@@ -0,0 +1,604 @@
+package services
+
+import (
+	"PosApp/app/database"
+	"PosApp/app/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// errDIANUnavailable marks failures reaching the DIAN provider (network errors and 5xx responses),
+// as opposed to documents rejected by the API
+var errDIANUnavailable = errors.New("DIAN provider unavailable")
+
+// errContingencyReportClaimed is returned when another worker is already reporting the invoice
+var errContingencyReportClaimed = errors.New("contingency invoice is already being reported")
+
+// maxContingencyReportRetries limits automatic report attempts of a rejected contingency invoice
+const maxContingencyReportRetries = 5
+
+// Contingency invoice statuses: pending report, and claimed by a reporter while it is sent
+const (
+	contingencyStatusPending   = "contingency"
+	contingencyStatusReporting = "contingency_reporting"
+)
+
+// issueContingencyInvoice issues an invoice with the contingency range without contacting DIAN.
+// The full request is stored so it can be reported once the service is back
+func (s *InvoiceService) issueContingencyInvoice(sale *models.Sale, sendEmailToCustomer bool) (*models.ElectronicInvoice, error) {
+	if !s.config.Step10Completed || s.config.ContingencyResolutionPrefix == "" {
+		return nil, fmt.Errorf("contingency resolution is not configured")
+	}
+
+	invoiceData, err := s.prepareInvoiceData(sale, sendEmailToCustomer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare invoice data: %w", err)
+	}
+
+	// The number is reserved atomically so concurrent sales never get the same one
+	number, err := reserveContingencyNumber(s.db, s.config.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.config.LastContingencyNumber = number
+	invoiceData.Number = number
+	invoiceData.TypeDocumentID = 3 // Contingency Invoice (tipo 03)
+	invoiceData.ResolutionNumber = s.config.ContingencyResolutionNumber
+	invoiceData.Prefix = s.config.ContingencyResolutionPrefix
+
+	requestDataJSON, err := json.Marshal(invoiceData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal contingency invoice: %w", err)
+	}
+
+	var event models.ContingencyEvent
+	var eventID *uint
+	if err := s.db.Where("ended_at IS NULL").Order("started_at DESC").First(&event).Error; err == nil {
+		eventID = &event.ID
+	}
+
+	electronicInvoice := &models.ElectronicInvoice{
+		SaleID:             sale.ID,
+		DocumentType:       "invoice",
+		InvoiceNumber:      strconv.Itoa(number),
+		Prefix:             invoiceData.Prefix,
+		Status:             contingencyStatusPending,
+		ValidationMessage:  "Factura de contingencia pendiente de reporte a la DIAN",
+		RequestData:        string(requestDataJSON),
+		IsContingency:      true,
+		ContingencyEventID: eventID,
+	}
+
+	if err := s.db.Create(electronicInvoice).Error; err != nil {
+		return nil, fmt.Errorf("failed to save contingency invoice: %w", err)
+	}
+
+	if eventID != nil {
+		s.db.Model(&event).UpdateColumn("invoices_issued", gorm.Expr("invoices_issued + 1"))
+	}
+
+	fmt.Printf("📴 Contingency invoice %s%d issued for sale #%s\n", invoiceData.Prefix, number, sale.SaleNumber)
+	return electronicInvoice, nil
+}
+
+// reserveContingencyNumber atomically advances the contingency consecutive, starting at the
+// beginning of the range, and returns the reserved number
+func reserveContingencyNumber(db *gorm.DB, configID uint) (int, error) {
+	var reserved struct {
+		LastContingencyNumber int
+	}
+	result := db.Raw(`
+		UPDATE dian_configs SET last_contingency_number = GREATEST(last_contingency_number + 1, contingency_resolution_from)
+		WHERE id = ? AND (contingency_resolution_to = 0 OR GREATEST(last_contingency_number + 1, contingency_resolution_from) <= contingency_resolution_to)
+		RETURNING last_contingency_number
+	`, configID).Scan(&reserved)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to reserve contingency invoice number: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, fmt.Errorf("contingency resolution range exhausted")
+	}
+	return reserved.LastContingencyNumber, nil
+}
+
+// canSwitchToContingency reports whether a failed send should switch invoicing to contingency mode
+func (s *InvoiceService) canSwitchToContingency(err error) bool {
+	return err != nil && errors.Is(err, errDIANUnavailable) && s.config.ContingencyAutoActivate && s.config.Step10Completed
+}
+
+// switchToContingency activates contingency mode after a provider outage and issues the sale
+// as a contingency invoice in place of the failed document
+func (s *InvoiceService) switchToContingency(sale *models.Sale, sendEmailToCustomer bool, failed *models.ElectronicInvoice, sendErr error) (*models.ElectronicInvoice, error) {
+	fmt.Printf("⚠️  DIAN provider unavailable, switching to contingency mode: %v\n", sendErr)
+	if _, err := activateContingency(s.db, sendErr.Error(), "auto", nil); err != nil {
+		return failed, sendErr
+	}
+	s.config.ContingencyModeActive = true
+
+	// Replace the error record with the contingency invoice
+	if failed != nil && failed.ID > 0 {
+		s.db.Delete(&models.ElectronicInvoice{}, failed.ID)
+	}
+	return s.issueContingencyInvoice(sale, sendEmailToCustomer)
+}
+
+// ReportContingencyInvoices sends the pending contingency invoices to DIAN.
+// It stops at the first provider outage and returns how many invoices were reported
+func (s *InvoiceService) ReportContingencyInvoices() (int, error) {
+	var config models.DIANConfig
+	if err := s.db.First(&config).Error; err != nil {
+		return 0, fmt.Errorf("DIAN configuration not found")
+	}
+	s.config = &config
+
+	if !config.IsEnabled {
+		return 0, fmt.Errorf("electronic invoicing is disabled")
+	}
+	if config.ContingencyModeActive {
+		return 0, fmt.Errorf("contingency mode is still active")
+	}
+
+	var invoices []models.ElectronicInvoice
+	if err := s.db.Where("status = ? AND retry_count < ?", "contingency", maxContingencyReportRetries).
+		Order("id ASC").Find(&invoices).Error; err != nil {
+		return 0, err
+	}
+
+	reported := 0
+	for i := range invoices {
+		if err := s.reportContingencyInvoice(&invoices[i]); err != nil {
+			if errors.Is(err, errContingencyReportClaimed) {
+				continue
+			}
+			fmt.Printf("❌ Failed to report contingency invoice %s%s: %v\n", invoices[i].Prefix, invoices[i].InvoiceNumber, err)
+			if errors.Is(err, errDIANUnavailable) {
+				return reported, err
+			}
+			continue
+		}
+		reported++
+	}
+
+	completeContingencyEvents(s.db)
+	return reported, nil
+}
+
+// ReportContingencyInvoice sends a single pending contingency invoice to DIAN
+func (s *InvoiceService) ReportContingencyInvoice(invoice *models.ElectronicInvoice) error {
+	var config models.DIANConfig
+	if err := s.db.First(&config).Error; err != nil {
+		return fmt.Errorf("DIAN configuration not found")
+	}
+	s.config = &config
+
+	if err := s.reportContingencyInvoice(invoice); err != nil {
+		return err
+	}
+	completeContingencyEvents(s.db)
+	return nil
+}
+
+// reportContingencyInvoice sends the stored request of a contingency invoice and records the DIAN result
+func (s *InvoiceService) reportContingencyInvoice(invoice *models.ElectronicInvoice) error {
+	if invoice.Status == contingencyStatusReporting {
+		return errContingencyReportClaimed
+	}
+	if invoice.Status != contingencyStatusPending {
+		return fmt.Errorf("invoice %s%s is not pending contingency report", invoice.Prefix, invoice.InvoiceNumber)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(invoice.RequestData), &data); err != nil {
+		return fmt.Errorf("failed to read stored contingency request: %w", err)
+	}
+
+	// Claim the invoice so the worker and a manual report never send it twice
+	claim := s.db.Model(&models.ElectronicInvoice{}).
+		Where("id = ? AND status = ?", invoice.ID, contingencyStatusPending).
+		Update("status", contingencyStatusReporting)
+	if claim.Error != nil {
+		return claim.Error
+	}
+	if claim.RowsAffected == 0 {
+		return errContingencyReportClaimed
+	}
+
+	response, err := s.sendToDIAN(data, "invoice")
+	if err != nil {
+		release := map[string]interface{}{"status": contingencyStatusPending}
+		if !errors.Is(err, errDIANUnavailable) {
+			invoice.RetryCount++
+			invoice.LastError = err.Error()
+			release["retry_count"] = invoice.RetryCount
+			release["last_error"] = invoice.LastError
+		}
+		s.db.Model(&models.ElectronicInvoice{}).Where("id = ?", invoice.ID).Updates(release)
+		return err
+	}
+
+	result := parseDocumentResponse(response)
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		responseJSON = []byte("{}")
+	}
+
+	now := time.Now()
+	invoice.UUID = &result.UUID
+	invoice.CUFE = result.Key
+	invoice.QRCode = result.QRCode
+	invoice.ZipKey = result.ZipKey
+	invoice.Status = result.Status
+	invoice.IsValid = result.IsValid
+	invoice.ValidationMessage = result.ValidationMessage
+	invoice.DIANResponse = string(responseJSON)
+	invoice.LastError = ""
+	invoice.SentAt = &now
+	invoice.ReportedAt = &now
+	invoice.ValidationCheckedAt = &now
+	if result.IsValid != nil && *result.IsValid {
+		invoice.AcceptedAt = &now
+	}
+
+	if err := s.db.Save(invoice).Error; err != nil {
+		return fmt.Errorf("failed to save reported contingency invoice: %w", err)
+	}
+
+	if invoice.ContingencyEventID != nil {
+		s.db.Model(&models.ContingencyEvent{}).Where("id = ?", *invoice.ContingencyEventID).
+			UpdateColumn("invoices_reported", gorm.Expr("invoices_reported + 1"))
+	}
+
+	if invoice.Status == "accepted" {
+		queueAcceptedInvoiceMail(s.db, invoice.ID)
+	}
+	if result.ZipKey != "" {
+		go s.validateZipKeyAsync(&models.ElectronicInvoice{}, invoice.ID, result.ZipKey)
+	}
+
+	fmt.Printf("📤 Contingency invoice %s%s reported to DIAN (Status: %s)\n", invoice.Prefix, invoice.InvoiceNumber, invoice.Status)
+	return nil
+}
+
+// activateContingency switches invoicing to contingency mode and opens an outage event
+func activateContingency(db *gorm.DB, reason, activatedBy string, employeeID *uint) (*models.ContingencyEvent, error) {
+	var config models.DIANConfig
+	if err := db.First(&config).Error; err != nil {
+		return nil, fmt.Errorf("DIAN configuration not found")
+	}
+	if !config.Step10Completed {
+		return nil, fmt.Errorf("contingency resolution is not configured")
+	}
+
+	var event models.ContingencyEvent
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&config).UpdateColumn("contingency_mode_active", true).Error; err != nil {
+			return err
+		}
+
+		// Reuse the open event if the mode was already active
+		if err := tx.Where("ended_at IS NULL").Order("started_at DESC").First(&event).Error; err == nil {
+			return nil
+		}
+
+		event = models.ContingencyEvent{
+			StartedAt:   time.Now(),
+			Reason:      reason,
+			ActivatedBy: activatedBy,
+			EmployeeID:  employeeID,
+		}
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to activate contingency mode: %w", err)
+	}
+
+	fmt.Printf("📴 Contingency mode activated (%s): %s\n", activatedBy, reason)
+	return &event, nil
+}
+
+// deactivateContingency leaves contingency mode and closes the open outage event,
+// starting the window to report its invoices
+func deactivateContingency(db *gorm.DB) (*models.ContingencyEvent, error) {
+	var config models.DIANConfig
+	if err := db.First(&config).Error; err != nil {
+		return nil, fmt.Errorf("DIAN configuration not found")
+	}
+
+	reportHours := config.ContingencyReportHours
+	if reportHours <= 0 {
+		reportHours = 48
+	}
+
+	var event models.ContingencyEvent
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&config).UpdateColumn("contingency_mode_active", false).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("ended_at IS NULL").Order("started_at DESC").First(&event).Error; err != nil {
+			return nil
+		}
+
+		now := time.Now()
+		deadline := now.Add(time.Duration(reportHours) * time.Hour)
+		event.EndedAt = &now
+		event.ReportDeadline = &deadline
+		return tx.Save(&event).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to deactivate contingency mode: %w", err)
+	}
+
+	fmt.Printf("📶 Contingency mode deactivated\n")
+	return &event, nil
+}
+
+// completeContingencyEvents marks closed outage events whose invoices were all reported
+func completeContingencyEvents(db *gorm.DB) {
+	var events []models.ContingencyEvent
+	db.Where("ended_at IS NOT NULL AND report_completed_at IS NULL").Find(&events)
+
+	for _, event := range events {
+		var pending int64
+		db.Model(&models.ElectronicInvoice{}).
+			Where("contingency_event_id = ? AND status IN ?", event.ID, []string{contingencyStatusPending, contingencyStatusReporting}).
+			Count(&pending)
+		if pending == 0 {
+			now := time.Now()
+			db.Model(&event).UpdateColumn("report_completed_at", now)
+		}
+	}
+}
+
+// isDIANProviderReachable probes the DIAN provider API
+func isDIANProviderReachable(apiURL string) bool {
+	if apiURL == "" {
+		return false
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(apiURL)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode < http.StatusInternalServerError
+}
+
+// contingencyWorkerStarted keeps a second start from releasing the claims of the running worker
+var contingencyWorkerStarted atomic.Bool
+
+// StartContingencyWorker starts a background worker that ends automatic contingencies
+// when the provider is back and reports the pending contingency invoices
+func StartContingencyWorker() {
+	if !contingencyWorkerStarted.CompareAndSwap(false, true) {
+		return
+	}
+
+	// Reports interrupted by a shutdown are sent again
+	if db := database.GetDB(); db != nil {
+		db.Model(&models.ElectronicInvoice{}).
+			Where("status = ?", contingencyStatusReporting).
+			Update("status", contingencyStatusPending)
+	}
+
+	go func() {
+		ticker := time.NewTicker(60 * time.Second) // Check every minute
+		defer ticker.Stop()
+
+		for range ticker.C {
+			processContingency()
+		}
+	}()
+}
+
+// processContingency runs one contingency worker cycle
+func processContingency() {
+	db := database.GetDB()
+	if db == nil {
+		return
+	}
+
+	var config models.DIANConfig
+	if err := db.First(&config).Error; err != nil || !config.IsEnabled {
+		return
+	}
+
+	if config.ContingencyModeActive {
+		// Only contingencies started automatically end automatically
+		var event models.ContingencyEvent
+		if err := db.Where("ended_at IS NULL").Order("started_at DESC").First(&event).Error; err != nil || event.ActivatedBy != "auto" {
+			return
+		}
+		if !isDIANProviderReachable(dianAPIURL(&config)) {
+			return
+		}
+		if _, err := deactivateContingency(db); err != nil {
+			fmt.Printf("Error ending contingency mode: %v\n", err)
+			return
+		}
+	}
+
+	var pending int64
+	db.Model(&models.ElectronicInvoice{}).
+		Where("status = ? AND retry_count < ?", contingencyStatusPending, maxContingencyReportRetries).
+		Count(&pending)
+	if pending == 0 {
+		return
+	}
+
+	fmt.Printf("Reporting %d contingency invoices to DIAN...\n", pending)
+	reported, err := NewInvoiceService().ReportContingencyInvoices()
+	if err != nil {
+		fmt.Printf("Error reporting contingency invoices: %v\n", err)
+	}
+	if reported > 0 {
+		fmt.Printf("✅ %d contingency invoices reported to DIAN\n", reported)
+	}
+}
+
+// ==================== DIAN SERVICE: CONTINGENCY MODE ====================
+
+// ConfigureContingencyResolution configures the contingency invoice resolution in DIAN API
+func (s *DIANService) ConfigureContingencyResolution() error {
+	var dianConfig models.DIANConfig
+	if err := s.db.First(&dianConfig).Error; err != nil {
+		return fmt.Errorf("DIAN configuration not found")
+	}
+
+	if dianConfig.APIToken == "" {
+		return fmt.Errorf("API token not found. Please configure company first (Step 1)")
+	}
+
+	// Validate required fields
+	if dianConfig.ContingencyResolutionNumber == "" {
+		return fmt.Errorf("contingency resolution number is required")
+	}
+	if dianConfig.ContingencyResolutionPrefix == "" {
+		return fmt.Errorf("contingency resolution prefix is required")
+	}
+
+	// Handle zero-value dates by using default test environment dates
+	dateFrom := dianConfig.ContingencyResolutionDateFrom
+	if dateFrom.IsZero() {
+		dateFrom = time.Date(2019, 1, 19, 0, 0, 0, 0, time.UTC)
+	}
+
+	dateTo := dianConfig.ContingencyResolutionDateTo
+	if dateTo.IsZero() {
+		dateTo = time.Date(2030, 1, 19, 0, 0, 0, 0, time.UTC)
+	}
+
+	data := map[string]interface{}{
+		"type_document_id":  3, // Contingency Invoice (tipo 03)
+		"prefix":            dianConfig.ContingencyResolutionPrefix,
+		"resolution":        dianConfig.ContingencyResolutionNumber,
+		"resolution_date":   dateFrom.Format("2006-01-02"),
+		"from":              dianConfig.ContingencyResolutionFrom,
+		"to":                dianConfig.ContingencyResolutionTo,
+		"generated_to_date": 0, // Always 0 for initial configuration
+		"date_from":         dateFrom.Format("2006-01-02"),
+		"date_to":           dateTo.Format("2006-01-02"),
+	}
+	if dianConfig.ContingencyTechnicalKey != "" {
+		data["technical_key"] = dianConfig.ContingencyTechnicalKey
+	}
+	if err := s.putResolution(&dianConfig, data); err != nil {
+		return err
+	}
+
+	// Start the consecutive at the beginning of the range
+	if dianConfig.LastContingencyNumber < dianConfig.ContingencyResolutionFrom-1 {
+		dianConfig.LastContingencyNumber = dianConfig.ContingencyResolutionFrom - 1
+	}
+
+	dianConfig.Step10Completed = true
+	if err := s.db.Save(&dianConfig).Error; err != nil {
+		return fmt.Errorf("failed to save step completion: %w", err)
+	}
+
+	s.config = &dianConfig
+
+	return nil
+}
+
+// ActivateContingencyMode manually switches invoicing to contingency mode
+func (s *DIANService) ActivateContingencyMode(reason string, employeeID uint) error {
+	if reason == "" {
+		reason = "Activación manual"
+	}
+
+	var employee *uint
+	if employeeID != 0 {
+		employee = &employeeID
+	}
+
+	if _, err := activateContingency(s.db, reason, "manual", employee); err != nil {
+		return err
+	}
+	return s.loadConfig()
+}
+
+// DeactivateContingencyMode ends contingency mode and reports the pending invoices in background
+func (s *DIANService) DeactivateContingencyMode() error {
+	if _, err := deactivateContingency(s.db); err != nil {
+		return err
+	}
+
+	go func() {
+		reported, err := NewInvoiceService().ReportContingencyInvoices()
+		if err != nil {
+			fmt.Printf("Error reporting contingency invoices: %v\n", err)
+			return
+		}
+		fmt.Printf("✅ %d contingency invoices reported to DIAN\n", reported)
+	}()
+
+	return s.loadConfig()
+}
+
+// ReportContingencyInvoices reports the pending contingency invoices to DIAN now
+func (s *DIANService) ReportContingencyInvoices() (int, error) {
+	return NewInvoiceService().ReportContingencyInvoices()
+}
+
+// GetContingencyStatus returns the contingency mode, the open outage and the pending report window
+func (s *DIANService) GetContingencyStatus() (map[string]interface{}, error) {
+	var config models.DIANConfig
+	if err := s.db.First(&config).Error; err != nil {
+		return nil, fmt.Errorf("DIAN configuration not found")
+	}
+
+	var pending int64
+	s.db.Model(&models.ElectronicInvoice{}).Where("status IN ?", []string{contingencyStatusPending, contingencyStatusReporting}).Count(&pending)
+
+	status := map[string]interface{}{
+		"active":           config.ContingencyModeActive,
+		"configured":       config.Step10Completed,
+		"auto_activate":    config.ContingencyAutoActivate,
+		"pending_invoices": pending,
+		"last_number":      config.LastContingencyNumber,
+		"range_to":         config.ContingencyResolutionTo,
+	}
+
+	var current models.ContingencyEvent
+	if err := s.db.Where("ended_at IS NULL").Order("started_at DESC").First(&current).Error; err == nil {
+		status["current_event"] = current
+	}
+
+	// Earliest deadline among closed outages that still have invoices to report
+	var overdue models.ContingencyEvent
+	if err := s.db.Where("ended_at IS NOT NULL AND report_completed_at IS NULL").
+		Order("report_deadline ASC").First(&overdue).Error; err == nil && overdue.ReportDeadline != nil {
+		status["report_deadline"] = overdue.ReportDeadline
+		status["report_overdue"] = time.Now().After(*overdue.ReportDeadline)
+	}
+
+	return status, nil
+}
+
+// GetContingencyEvents returns the outage log, most recent first
+func (s *DIANService) GetContingencyEvents(limit int) ([]models.ContingencyEvent, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var events []models.ContingencyEvent
+	err := s.db.Order("started_at DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// GetContingencyInvoices returns the invoices issued during an outage event
+func (s *DIANService) GetContingencyInvoices(eventID uint) ([]models.ElectronicInvoice, error) {
+	var invoices []models.ElectronicInvoice
+	err := s.db.Where("contingency_event_id = ?", eventID).Order("id ASC").Find(&invoices).Error
+	return invoices, err
+}
@@ -0,0 +1,510 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Minimal IPP client for CUPS (RFC 8010/8011). It submits raw ESC/POS jobs to named
+// queues and reads the printer state, so Linux and macOS terminals print through the
+// system spooler like Windows ones. The server is taken from CUPS_SERVER (host[:port]
+// or a socket path), else the local CUPS socket, else localhost:631.
+
+// IPP operations
+const (
+	ippOpPrintJob             uint16 = 0x0002
+	ippOpGetPrinterAttributes uint16 = 0x000B
+	ippOpCUPSGetDefault       uint16 = 0x4001
+	ippOpCUPSGetPrinters      uint16 = 0x4002
+)
+
+// IPP delimiter and value tags
+const (
+	ippTagOperation byte = 0x01
+	ippTagEnd       byte = 0x03
+	ippTagPrinter   byte = 0x04
+	ippTagInteger   byte = 0x21
+	ippTagBoolean   byte = 0x22
+	ippTagEnum      byte = 0x23
+	ippTagTextLang  byte = 0x35
+	ippTagNameLang  byte = 0x36
+	ippTagText      byte = 0x41
+	ippTagName      byte = 0x42
+	ippTagKeyword   byte = 0x44
+	ippTagURI       byte = 0x45
+	ippTagCharset   byte = 0x47
+	ippTagLanguage  byte = 0x48
+	ippTagMimeType  byte = 0x49
+)
+
+// printer-state values
+const (
+	ippPrinterPrinting = 4
+	ippPrinterStopped  = 5
+)
+
+// cupsRawFormat makes CUPS pass the document to the printer without filtering
+const cupsRawFormat = "application/vnd.cups-raw"
+
+// cupsPrinterAttributes are the attributes read for discovery and status
+var cupsPrinterAttributes = []string{
+	"printer-name", "printer-info", "printer-make-and-model", "device-uri",
+	"printer-state", "printer-state-reasons", "printer-state-message", "printer-is-accepting-jobs",
+}
+
+// ippAttribute is an attribute with its values; integers, enums and booleans are int
+type ippAttribute struct {
+	Name   string
+	Values []interface{}
+}
+
+type ippGroup struct {
+	Tag        byte
+	Attributes map[string]*ippAttribute
+}
+
+type ippMessage struct {
+	buf bytes.Buffer
+}
+
+func newIPPRequest(operation uint16, printerURI string) *ippMessage {
+	m := &ippMessage{}
+	m.buf.Write([]byte{1, 1}) // IPP/1.1, supported by every CUPS version
+	binary.Write(&m.buf, binary.BigEndian, operation)
+	binary.Write(&m.buf, binary.BigEndian, uint32(1))
+	m.buf.WriteByte(ippTagOperation)
+	m.add(ippTagCharset, "attributes-charset", "utf-8")
+	m.add(ippTagLanguage, "attributes-natural-language", "es")
+	if printerURI != "" {
+		m.add(ippTagURI, "printer-uri", printerURI)
+	}
+	user := os.Getenv("USER")
+	if user == "" {
+		user = "pos"
+	}
+	m.add(ippTagName, "requesting-user-name", user)
+	return m
+}
+
+// add writes an attribute; extra values are written as additional values of it
+func (m *ippMessage) add(tag byte, name string, values ...string) {
+	for i, value := range values {
+		m.buf.WriteByte(tag)
+		if i > 0 {
+			name = ""
+		}
+		binary.Write(&m.buf, binary.BigEndian, uint16(len(name)))
+		m.buf.WriteString(name)
+		binary.Write(&m.buf, binary.BigEndian, uint16(len(value)))
+		m.buf.WriteString(value)
+	}
+}
+
+func (m *ippMessage) bytes(document []byte) []byte {
+	m.buf.WriteByte(ippTagEnd)
+	m.buf.Write(document)
+	return m.buf.Bytes()
+}
+
+// parseIPPResponse returns the status code and attribute groups of a response
+func parseIPPResponse(data []byte) (uint16, []ippGroup, error) {
+	if len(data) < 8 {
+		return 0, nil, fmt.Errorf("invalid IPP response")
+	}
+	status := binary.BigEndian.Uint16(data[2:4])
+	pos := 8
+
+	var groups []ippGroup
+	var current *ippGroup
+	var last *ippAttribute
+	read := func(n int) ([]byte, error) {
+		if pos+n > len(data) {
+			return nil, fmt.Errorf("truncated IPP response")
+		}
+		value := data[pos : pos+n]
+		pos += n
+		return value, nil
+	}
+	readShort := func() (int, error) {
+		b, err := read(2)
+		if err != nil {
+			return 0, err
+		}
+		return int(binary.BigEndian.Uint16(b)), nil
+	}
+
+	for pos < len(data) {
+		tag := data[pos]
+		pos++
+		if tag == ippTagEnd {
+			break
+		}
+		if tag < 0x10 {
+			groups = append(groups, ippGroup{Tag: tag, Attributes: map[string]*ippAttribute{}})
+			current = &groups[len(groups)-1]
+			last = nil
+			continue
+		}
+
+		nameLength, err := readShort()
+		if err != nil {
+			return status, groups, err
+		}
+		name, err := read(nameLength)
+		if err != nil {
+			return status, groups, err
+		}
+		valueLength, err := readShort()
+		if err != nil {
+			return status, groups, err
+		}
+		raw, err := read(valueLength)
+		if err != nil {
+			return status, groups, err
+		}
+
+		var value interface{}
+		switch tag {
+		case ippTagInteger, ippTagEnum:
+			if len(raw) == 4 {
+				value = int(int32(binary.BigEndian.Uint32(raw)))
+			}
+		case ippTagBoolean:
+			if len(raw) == 1 {
+				value = int(raw[0])
+			}
+		case ippTagTextLang, ippTagNameLang:
+			// Language length, language, text length, text
+			if len(raw) >= 2 {
+				langLength := int(binary.BigEndian.Uint16(raw))
+				if len(raw) >= 4+langLength {
+					value = string(raw[4+langLength:])
+				}
+			}
+		default:
+			value = string(raw)
+		}
+
+		if current == nil {
+			continue
+		}
+		if nameLength == 0 && last != nil {
+			last.Values = append(last.Values, value)
+			continue
+		}
+		last = &ippAttribute{Name: string(name), Values: []interface{}{value}}
+		current.Attributes[last.Name] = last
+	}
+	return status, groups, nil
+}
+
+func (g ippGroup) str(name string) string {
+	if attr, ok := g.Attributes[name]; ok && len(attr.Values) > 0 {
+		if s, ok := attr.Values[0].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func (g ippGroup) strs(name string) []string {
+	var values []string
+	if attr, ok := g.Attributes[name]; ok {
+		for _, v := range attr.Values {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+func (g ippGroup) num(name string) int {
+	if attr, ok := g.Attributes[name]; ok && len(attr.Values) > 0 {
+		if n, ok := attr.Values[0].(int); ok {
+			return n
+		}
+	}
+	return 0
+}
+
+// ippStatusMessage describes the IPP status codes CUPS usually returns
+func ippStatusMessage(status uint16) string {
+	switch status {
+	case 0x0401:
+		return "acceso denegado por CUPS"
+	case 0x0402, 0x0403:
+		return "CUPS requiere autenticación"
+	case 0x0406:
+		return "la cola de impresión no existe en CUPS"
+	case 0x040A:
+		return "formato de documento no soportado por la cola"
+	case 0x0501, 0x0502:
+		return "operación no soportada por el servidor IPP"
+	case 0x0506:
+		return "la impresora no está aceptando trabajos"
+	case 0x0507:
+		return "la impresora está ocupada"
+	}
+	return fmt.Sprintf("estado IPP 0x%04X", status)
+}
+
+// cupsEndpoint returns the HTTP URL, the printer-uri and the client for a queue name
+// or an ipp://, ipps://, http:// or https:// printer URI. An empty queue addresses the server
+func cupsEndpoint(queue string) (string, string, *http.Client) {
+	client := &http.Client{Timeout: printerWriteTimeout}
+
+	if strings.Contains(queue, "://") {
+		u, err := url.Parse(queue)
+		if err == nil {
+			printerURI := queue
+			switch u.Scheme {
+			case "ipp":
+				u.Scheme = "http"
+			case "ipps":
+				u.Scheme = "https"
+			}
+			if u.Port() == "" && (strings.HasPrefix(queue, "ipp://") || strings.HasPrefix(queue, "ipps://")) {
+				u.Host = net.JoinHostPort(u.Hostname(), "631")
+			}
+			return u.String(), printerURI, client
+		}
+	}
+
+	server := os.Getenv("CUPS_SERVER")
+	if server == "" {
+		for _, socket := range []string{"/run/cups/cups.sock", "/var/run/cups/cups.sock"} {
+			if _, err := os.Stat(socket); err == nil {
+				server = socket
+				break
+			}
+		}
+	}
+
+	host := server
+	if server == "" {
+		host = "localhost:631"
+	} else if strings.HasPrefix(server, "/") {
+		socket := server
+		host = "localhost"
+		dialer := &net.Dialer{Timeout: printerConnectTimeout}
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		}
+	} else if !strings.Contains(host, ":") {
+		host += ":631"
+	}
+	if client.Transport == nil {
+		client.Transport = &http.Transport{DialContext: (&net.Dialer{Timeout: printerConnectTimeout}).DialContext}
+	}
+
+	path := "/"
+	if queue != "" {
+		path = "/printers/" + url.PathEscape(queue)
+	}
+	return "http://" + host + path, "ipp://localhost" + path, client
+}
+
+// cupsRequest sends an IPP request and returns the response groups
+func cupsRequest(client *http.Client, endpoint string, body []byte) ([]ippGroup, error) {
+	resp, err := client.Post(endpoint, "application/ipp", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo conectar con CUPS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CUPS respondió HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read IPP response: %w", err)
+	}
+	status, groups, err := parseIPPResponse(data)
+	if err != nil {
+		return nil, err
+	}
+	if status > 0x00FF {
+		return groups, fmt.Errorf("%s", ippStatusMessage(status))
+	}
+	return groups, nil
+}
+
+// cupsPrinterState is the state of a CUPS queue
+type cupsPrinterState struct {
+	Name          string
+	Info          string
+	Model         string
+	DeviceURI     string
+	State         int // 3 idle, 4 processing, 5 stopped
+	Reasons       []string
+	Message       string
+	AcceptingJobs bool
+}
+
+func newCUPSPrinterState(group ippGroup) cupsPrinterState {
+	state := cupsPrinterState{
+		Name:          group.str("printer-name"),
+		Info:          group.str("printer-info"),
+		Model:         group.str("printer-make-and-model"),
+		DeviceURI:     group.str("device-uri"),
+		State:         group.num("printer-state"),
+		Message:       group.str("printer-state-message"),
+		AcceptingJobs: group.num("printer-is-accepting-jobs") == 1,
+	}
+	for _, reason := range group.strs("printer-state-reasons") {
+		if reason != "none" {
+			state.Reasons = append(state.Reasons, reason)
+		}
+	}
+	return state
+}
+
+// Health returns the status reported to the frontend: "online", "printing",
+// "paper_out", "offline" or "error"
+func (p cupsPrinterState) Health() string {
+	status := "online"
+	if p.State == ippPrinterPrinting {
+		status = "printing"
+	}
+	for _, reason := range p.Reasons {
+		// Reasons may carry a -report, -warning or -error severity suffix
+		if strings.HasSuffix(reason, "-warning") {
+			continue
+		}
+		base := strings.TrimSuffix(strings.TrimSuffix(reason, "-report"), "-error")
+		switch base {
+		case "media-empty", "media-needed":
+			return "paper_out"
+		case "offline", "shutdown", "timed-out", "connecting-to-device":
+			status = "offline"
+		case "media-jam", "cover-open", "door-open", "input-tray-missing", "output-area-full",
+			"marker-supply-empty", "other":
+			if status != "offline" {
+				status = "error"
+			}
+		}
+	}
+	if status == "online" || status == "printing" {
+		if p.State == ippPrinterStopped || !p.AcceptingJobs {
+			status = "offline"
+		}
+	}
+	return status
+}
+
+// StatusMessage describes the state for the user
+func (p cupsPrinterState) StatusMessage() string {
+	switch p.Health() {
+	case "paper_out":
+		return "Sin papel"
+	case "offline":
+		if p.State == ippPrinterStopped {
+			return "Cola de impresión detenida"
+		}
+		if !p.AcceptingJobs {
+			return "La cola no acepta trabajos"
+		}
+		return "Impresora desconectada"
+	case "error":
+		if p.Message != "" {
+			return p.Message
+		}
+		return "Error en la impresora: " + strings.Join(p.Reasons, ", ")
+	case "printing":
+		return "Imprimiendo"
+	}
+	return "Lista"
+}
+
+// cupsGetPrinterState reads the state of a queue
+func cupsGetPrinterState(queue string) (*cupsPrinterState, error) {
+	endpoint, printerURI, client := cupsEndpoint(queue)
+	req := newIPPRequest(ippOpGetPrinterAttributes, printerURI)
+	req.add(ippTagKeyword, "requested-attributes", cupsPrinterAttributes...)
+	groups, err := cupsRequest(client, endpoint, req.bytes(nil))
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		if group.Tag == ippTagPrinter {
+			state := newCUPSPrinterState(group)
+			if state.Name == "" {
+				state.Name = queue
+			}
+			return &state, nil
+		}
+	}
+	return nil, fmt.Errorf("CUPS no devolvió el estado de %s", queue)
+}
+
+// cupsListPrinters returns the queues of the CUPS server and the default one
+func cupsListPrinters() ([]cupsPrinterState, string, error) {
+	endpoint, _, client := cupsEndpoint("")
+	req := newIPPRequest(ippOpCUPSGetPrinters, "")
+	req.add(ippTagKeyword, "requested-attributes", cupsPrinterAttributes...)
+	groups, err := cupsRequest(client, endpoint, req.bytes(nil))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var printers []cupsPrinterState
+	for _, group := range groups {
+		if group.Tag == ippTagPrinter {
+			printers = append(printers, newCUPSPrinterState(group))
+		}
+	}
+
+	defaultName := ""
+	req = newIPPRequest(ippOpCUPSGetDefault, "")
+	req.add(ippTagKeyword, "requested-attributes", "printer-name")
+	if groups, err := cupsRequest(client, endpoint, req.bytes(nil)); err == nil {
+		for _, group := range groups {
+			if group.Tag == ippTagPrinter {
+				defaultName = group.str("printer-name")
+			}
+		}
+	}
+	return printers, defaultName, nil
+}
+
+// cupsPrintRaw submits a raw job to a queue and returns its job id
+func cupsPrintRaw(queue, jobName string, data []byte) (int, error) {
+	endpoint, printerURI, client := cupsEndpoint(queue)
+	req := newIPPRequest(ippOpPrintJob, printerURI)
+	req.add(ippTagName, "job-name", jobName)
+	req.add(ippTagMimeType, "document-format", cupsRawFormat)
+	groups, err := cupsRequest(client, endpoint, req.bytes(data))
+	if err != nil {
+		return 0, err
+	}
+	for _, group := range groups {
+		if id := group.num("job-id"); id > 0 {
+			return id, nil
+		}
+	}
+	return 0, nil
+}
+
+// cupsPrinterReady fails when the queue cannot print now. CUPS would hold the job
+// until the printer comes back, so the error lets the spooler retry or fail over
+func cupsPrinterReady(queue string) error {
+	state, err := cupsGetPrinterState(queue)
+	if err != nil {
+		return err
+	}
+	switch state.Health() {
+	case "online", "printing":
+		return nil
+	}
+	return fmt.Errorf("impresora %s: %s", queue, state.StatusMessage())
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ippResponse builds a response with the given status code; build adds its groups
+func ippResponse(status uint16, build func(m *ippMessage)) []byte {
+	m := &ippMessage{}
+	m.buf.Write([]byte{1, 1, byte(status >> 8), byte(status), 0, 0, 0, 1})
+	build(m)
+	return m.bytes(nil)
+}
+
+func TestParseIPPResponse(t *testing.T) {
+	withLanguage := func(text string) string {
+		return string([]byte{0, 2}) + "es" + string([]byte{0, byte(len(text))}) + text
+	}
+	printer := ippResponse(0x0000, func(m *ippMessage) {
+		m.buf.WriteByte(ippTagOperation)
+		m.add(ippTagCharset, "attributes-charset", "utf-8")
+		m.buf.WriteByte(ippTagPrinter)
+		m.add(ippTagName, "printer-name", "cocina")
+		m.add(ippTagEnum, "printer-state", string([]byte{0, 0, 0, 5}))
+		m.add(ippTagKeyword, "printer-state-reasons", "media-empty-error", "paused")
+		m.add(ippTagBoolean, "printer-is-accepting-jobs", string([]byte{1}))
+		m.add(ippTagTextLang, "printer-state-message", withLanguage("Sin papel"))
+	})
+
+	tests := []struct {
+		name       string
+		data       []byte
+		wantStatus uint16
+		wantGroups int
+		wantErr    bool
+	}{
+		{"printer attributes", printer, 0x0000, 2, false},
+		{"error status", ippResponse(0x0406, func(m *ippMessage) {
+			m.buf.WriteByte(ippTagOperation)
+			m.add(ippTagCharset, "attributes-charset", "utf-8")
+		}), 0x0406, 1, false},
+		{"several printers", ippResponse(0x0000, func(m *ippMessage) {
+			m.buf.WriteByte(ippTagPrinter)
+			m.add(ippTagName, "printer-name", "caja")
+			m.buf.WriteByte(ippTagPrinter)
+			m.add(ippTagName, "printer-name", "cocina")
+		}), 0x0000, 2, false},
+		{"too short", []byte{1, 1, 0}, 0, 0, true},
+		{"truncated", printer[:len(printer)-5], 0x0000, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, groups, err := parseIPPResponse(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIPPResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status != tt.wantStatus || len(groups) != tt.wantGroups {
+				t.Errorf("parseIPPResponse() = status 0x%04X, %d groups, want 0x%04X, %d groups", status, len(groups), tt.wantStatus, tt.wantGroups)
+			}
+		})
+	}
+
+	_, groups, _ := parseIPPResponse(printer)
+	got := newCUPSPrinterState(groups[1])
+	want := cupsPrinterState{
+		Name:          "cocina",
+		State:         ippPrinterStopped,
+		Reasons:       []string{"media-empty-error", "paused"},
+		Message:       "Sin papel",
+		AcceptingJobs: true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("newCUPSPrinterState() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCUPSPrinterStateHealth(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       cupsPrinterState
+		wantHealth  string
+		wantMessage string
+	}{
+		{"idle", cupsPrinterState{State: 3, AcceptingJobs: true}, "online", "Lista"},
+		{"printing", cupsPrinterState{State: ippPrinterPrinting, AcceptingJobs: true}, "printing", "Imprimiendo"},
+		{"paper out", cupsPrinterState{State: 3, AcceptingJobs: true, Reasons: []string{"media-empty-error"}}, "paper_out", "Sin papel"},
+		{"warnings are ignored", cupsPrinterState{State: 3, AcceptingJobs: true, Reasons: []string{"media-low-warning", "offline-warning"}}, "online", "Lista"},
+		{"offline device", cupsPrinterState{State: 3, AcceptingJobs: true, Reasons: []string{"offline-report"}}, "offline", "Impresora desconectada"},
+		{"stopped queue", cupsPrinterState{State: ippPrinterStopped, AcceptingJobs: true}, "offline", "Cola de impresión detenida"},
+		{"rejecting jobs", cupsPrinterState{State: 3}, "offline", "La cola no acepta trabajos"},
+		{"cover open", cupsPrinterState{State: 3, AcceptingJobs: true, Reasons: []string{"cover-open-error"}}, "error", "Error en la impresora: cover-open-error"},
+		{"error message", cupsPrinterState{State: 3, AcceptingJobs: true, Reasons: []string{"media-jam"}, Message: "Atasco"}, "error", "Atasco"},
+		{"offline wins over error", cupsPrinterState{State: 3, AcceptingJobs: true, Reasons: []string{"offline", "media-jam"}}, "offline", "Impresora desconectada"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.state.Health(); got != tt.wantHealth {
+				t.Errorf("Health() = %q, want %q", got, tt.wantHealth)
+			}
+			if got := tt.state.StatusMessage(); got != tt.wantMessage {
+				t.Errorf("StatusMessage() = %q, want %q", got, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestCUPSEndpoint(t *testing.T) {
+	t.Setenv("CUPS_SERVER", "print.local")
+	tests := []struct {
+		queue       string
+		wantURL     string
+		wantPrinter string
+	}{
+		{"cocina", "http://print.local:631/printers/cocina", "ipp://localhost/printers/cocina"},
+		{"", "http://print.local:631/", "ipp://localhost/"},
+		{"ipp://10.0.0.5/ipp/print", "http://10.0.0.5:631/ipp/print", "ipp://10.0.0.5/ipp/print"},
+		{"ipps://printer.local:8443/ipp", "https://printer.local:8443/ipp", "ipps://printer.local:8443/ipp"},
+		{"http://10.0.0.5:631/printers/caja", "http://10.0.0.5:631/printers/caja", "http://10.0.0.5:631/printers/caja"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.queue, func(t *testing.T) {
+			endpoint, printerURI, _ := cupsEndpoint(tt.queue)
+			if endpoint != tt.wantURL || printerURI != tt.wantPrinter {
+				t.Errorf("cupsEndpoint(%q) = %q, %q, want %q, %q", tt.queue, endpoint, printerURI, tt.wantURL, tt.wantPrinter)
+			}
+		})
+	}
+}
@@ -0,0 +1,1136 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"PosApp/app/models"
+)
+
+// DIANEnvironmentMock is the DIAN environment that sends every API call to the embedded mock server
+const DIANEnvironmentMock = "mock"
+
+// MockDIANOptions controls how the mock DIAN API answers
+type MockDIANOptions struct {
+	Latency         time.Duration // Delay added to every response
+	AsyncValidation bool          // Answer documents with a zip key, like the habilitation test set
+	ValidationDelay time.Duration // Time until an async document is validated
+	RejectAbove     float64       // Reject documents with a payable amount above this (0 = never)
+	RejectCustomers []string      // Customer identifications that are always rejected
+	RejectRate      int           // Percentage of documents rejected at random
+	Unavailable     bool          // Answer 503 to every request (simulated outage)
+}
+
+// mockDIANOptionsFromConfig builds the mock server options from the DIAN configuration
+func mockDIANOptionsFromConfig(config *models.DIANConfig) MockDIANOptions {
+	options := MockDIANOptions{
+		Latency:         time.Duration(config.MockLatencyMs) * time.Millisecond,
+		AsyncValidation: config.MockAsyncValidation,
+		ValidationDelay: time.Duration(config.MockValidationSeconds) * time.Second,
+		RejectAbove:     config.MockRejectAbove,
+		RejectRate:      config.MockRejectRate,
+		Unavailable:     config.MockServiceUnavailable,
+	}
+	for _, id := range strings.Split(config.MockRejectCustomers, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			options.RejectCustomers = append(options.RejectCustomers, id)
+		}
+	}
+	return options
+}
+
+// MockDIANServer is an embeddable stand-in for the DIAN UBL 2.1 API.
+// It implements the endpoints used by InvoiceService and DIANService with responses shaped
+// like the real provider, so invoicing can be developed and trained offline without
+// consuming test set numbers. State is kept in memory.
+type MockDIANServer struct {
+	mu          sync.Mutex
+	options     MockDIANOptions
+	server      *http.Server
+	url         string
+	random      *rand.Rand
+	company     mockDIANCompany
+	environment int                           // 1 = production, 2 = test
+	resolutions map[string]mockDIANResolution // "<type_document_id>-<prefix>"
+	documents   map[string]*mockDIANDocument  // zip key -> document
+	issued      map[string]string             // "<type_document_id>-<prefix><number>" -> document key
+	lastNumbers map[string]int                // "<type_document_id>-<prefix>" -> last number received
+}
+
+type mockDIANCompany struct {
+	NIT          string
+	DV           string
+	BusinessName string
+	SoftwareID   string
+	SoftwarePIN  string
+}
+
+type mockDIANResolution struct {
+	TypeDocumentID int
+	Prefix         string
+	Resolution     string
+	ResolutionDate string
+	TechnicalKey   string
+	From           int
+	To             int
+	DateFrom       string
+	DateTo         string
+}
+
+type mockDIANDocument struct {
+	Key            string
+	Number         string
+	TypeDocumentID int
+	IsValid        bool
+	Errors         []string
+	ReadyAt        time.Time
+}
+
+// NewMockDIANServer creates a mock DIAN API with empty state
+func NewMockDIANServer(options MockDIANOptions) *MockDIANServer {
+	return &MockDIANServer{
+		options:     options,
+		random:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		environment: 2,
+		resolutions: make(map[string]mockDIANResolution),
+		documents:   make(map[string]*mockDIANDocument),
+		issued:      make(map[string]string),
+		lastNumbers: make(map[string]int),
+	}
+}
+
+// Handler returns the HTTP handler of the mock API so it can be mounted in another server
+func (m *MockDIANServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", m.handle(false, m.handleRoot))
+	mux.HandleFunc("/api/ubl2.1/config/", m.handleConfig)
+	mux.HandleFunc("/api/ubl2.1/numbering-range", m.handle(true, m.handleNumberingRange))
+	mux.HandleFunc("/api/ubl2.1/next-consecutive", m.handle(true, m.handleNextConsecutive))
+	mux.HandleFunc("/api/ubl2.1/status/zip/", m.handle(true, m.handleStatusZip))
+	mux.HandleFunc("/api/ubl2.1/", m.handle(true, m.handleDocument))
+	mux.HandleFunc("/api/send-email-employee/", m.handle(true, m.handleSendEmail))
+	return mux
+}
+
+// Start starts the mock API on addr ("127.0.0.1:0" picks a free port)
+func (m *MockDIANServer) Start(addr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.server != nil {
+		return fmt.Errorf("mock DIAN server already running")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to start mock DIAN server: %w", err)
+	}
+
+	m.url = "http://" + listener.Addr().String()
+	m.server = &http.Server{
+		Handler:      m.Handler(),
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 60 * time.Second,
+	}
+
+	go func(server *http.Server) {
+		log.Printf("[MOCK DIAN] Server starting on %s", m.url)
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("[MOCK DIAN] Server error: %v", err)
+		}
+	}(m.server)
+
+	return nil
+}
+
+// Stop stops the mock API
+func (m *MockDIANServer) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.server == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("error shutting down mock DIAN server: %w", err)
+	}
+
+	m.server = nil
+	m.url = ""
+	log.Printf("[MOCK DIAN] Server stopped")
+	return nil
+}
+
+// URL returns the base URL of the running mock API
+func (m *MockDIANServer) URL() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.url
+}
+
+// SetOptions replaces the latency, validation and rejection options
+func (m *MockDIANServer) SetOptions(options MockDIANOptions) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.options = options
+}
+
+// Reset forgets every received document and consecutive
+func (m *MockDIANServer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.documents = make(map[string]*mockDIANDocument)
+	m.issued = make(map[string]string)
+	m.lastNumbers = make(map[string]int)
+}
+
+// Stats returns a summary of the mock API state
+func (m *MockDIANServer) Stats() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending, accepted, rejected := 0, 0, 0
+	now := time.Now()
+	for _, document := range m.documents {
+		switch {
+		case now.Before(document.ReadyAt):
+			pending++
+		case document.IsValid:
+			accepted++
+		default:
+			rejected++
+		}
+	}
+
+	environment := "test"
+	if m.environment == 1 {
+		environment = "production"
+	}
+
+	return map[string]interface{}{
+		"running":            m.server != nil,
+		"url":                m.url,
+		"environment":        environment,
+		"documents_received": len(m.issued),
+		"async_pending":      pending,
+		"async_accepted":     accepted,
+		"async_rejected":     rejected,
+		"resolutions":        len(m.resolutions),
+		"unavailable":        m.options.Unavailable,
+	}
+}
+
+// seed loads the company, software and resolutions already configured in the POS,
+// so the mock behaves as if the configuration steps had been run against it
+func (m *MockDIANServer) seed(config *models.DIANConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.company = mockDIANCompany{
+		NIT:          config.IdentificationNumber,
+		DV:           config.DV,
+		BusinessName: config.BusinessName,
+		SoftwareID:   config.SoftwareID,
+		SoftwarePIN:  config.SoftwarePIN,
+	}
+	if config.Environment == "production" {
+		m.environment = 1
+	}
+
+	seedResolution := func(typeDocumentID int, prefix, resolution, technicalKey string, from, to int, dateFrom, dateTo time.Time) {
+		if prefix == "" {
+			return
+		}
+		m.resolutions[mockResolutionKey(typeDocumentID, prefix)] = mockDIANResolution{
+			TypeDocumentID: typeDocumentID,
+			Prefix:         prefix,
+			Resolution:     resolution,
+			ResolutionDate: mockDate(dateFrom),
+			TechnicalKey:   technicalKey,
+			From:           from,
+			To:             to,
+			DateFrom:       mockDate(dateFrom),
+			DateTo:         mockDate(dateTo),
+		}
+	}
+
+	seedResolution(1, config.ResolutionPrefix, config.ResolutionNumber, config.TechnicalKey,
+		config.ResolutionFrom, config.ResolutionTo, config.ResolutionDateFrom, config.ResolutionDateTo)
+	seedResolution(4, config.CreditNoteResolutionPrefix, config.CreditNoteResolutionNumber, "",
+		config.CreditNoteResolutionFrom, config.CreditNoteResolutionTo, config.CreditNoteResolutionDateFrom, config.CreditNoteResolutionDateTo)
+	seedResolution(5, config.DebitNoteResolutionPrefix, config.DebitNoteResolutionNumber, "",
+		config.DebitNoteResolutionFrom, config.DebitNoteResolutionTo, config.DebitNoteResolutionDateFrom, config.DebitNoteResolutionDateTo)
+	seedResolution(3, config.ContingencyResolutionPrefix, config.ContingencyResolutionNumber, config.ContingencyTechnicalKey,
+		config.ContingencyResolutionFrom, config.ContingencyResolutionTo, config.ContingencyResolutionDateFrom, config.ContingencyResolutionDateTo)
+}
+
+// handle wraps a handler with the simulated latency, outage and bearer authentication
+func (m *MockDIANServer) handle(requireAuth bool, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		options := m.options
+		m.mu.Unlock()
+
+		if options.Latency > 0 {
+			time.Sleep(options.Latency)
+		}
+
+		if options.Unavailable {
+			mockDIANJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+				"message": "Servicio de la DIAN no disponible (simulado)",
+			})
+			return
+		}
+
+		if requireAuth && strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer")) == "" {
+			mockDIANJSON(w, http.StatusUnauthorized, map[string]interface{}{"message": "Unauthenticated."})
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// handleRoot answers the connection test
+func (m *MockDIANServer) handleRoot(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		mockDIANJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Ruta no encontrada"})
+		return
+	}
+	mockDIANJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "API DIAN UBL 2.1 (simulador local)",
+	})
+}
+
+// handleConfig handles POST /config/{nit}/{dv} and PUT /config/{software|certificate|logo|resolution|environment}
+func (m *MockDIANServer) handleConfig(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/ubl2.1/config/"), "/"), "/")
+
+	// Company configuration does not require a token: it is the step that returns it
+	if len(parts) == 2 && r.Method == http.MethodPost {
+		m.handle(false, func(w http.ResponseWriter, r *http.Request) {
+			m.handleCompany(w, r, parts[0], parts[1])
+		})(w, r)
+		return
+	}
+
+	m.handle(true, func(w http.ResponseWriter, r *http.Request) {
+		if len(parts) != 1 || r.Method != http.MethodPut {
+			mockDIANJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Ruta no encontrada"})
+			return
+		}
+
+		body, ok := mockDIANBody(w, r)
+		if !ok {
+			return
+		}
+
+		switch parts[0] {
+		case "software":
+			m.handleSoftware(w, body)
+		case "certificate":
+			if mockString(body["certificate"]) == "" || mockString(body["password"]) == "" {
+				mockDIANValidationError(w, "certificate", "El campo certificate y password son obligatorios.")
+				return
+			}
+			mockDIANJSON(w, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"message": "Certificado creado con éxito",
+				"certificado": map[string]interface{}{
+					"name":            "simulador.p12",
+					"expiration_date": time.Now().AddDate(1, 0, 0).Format("2006-01-02 15:04:05"),
+				},
+			})
+		case "logo":
+			if mockString(body["logo"]) == "" {
+				mockDIANValidationError(w, "logo", "El campo logo es obligatorio.")
+				return
+			}
+			mockDIANJSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": "Logo almacenado con éxito"})
+		case "resolution":
+			m.handleResolution(w, body)
+		case "environment":
+			m.mu.Lock()
+			if id := mockInt(body["type_environment_id"]); id == 1 || id == 2 {
+				m.environment = id
+			}
+			environment := m.environment
+			m.mu.Unlock()
+			mockDIANJSON(w, http.StatusOK, map[string]interface{}{
+				"message": "Ambiente actualizado con éxito",
+				"company": map[string]interface{}{"type_environment_id": environment},
+			})
+		default:
+			mockDIANJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Ruta no encontrada"})
+		}
+	})(w, r)
+}
+
+// handleCompany registers the company and returns its API token
+func (m *MockDIANServer) handleCompany(w http.ResponseWriter, r *http.Request, nit, dv string) {
+	body, ok := mockDIANBody(w, r)
+	if !ok {
+		return
+	}
+	if mockString(body["business_name"]) == "" {
+		mockDIANValidationError(w, "business_name", "El campo business name es obligatorio.")
+		return
+	}
+
+	m.mu.Lock()
+	m.company.NIT = nit
+	m.company.DV = dv
+	m.company.BusinessName = mockString(body["business_name"])
+	m.mu.Unlock()
+
+	// The token is derived from the NIT so it survives restarts of the mock
+	sum := sha256.Sum256([]byte("mock-dian-" + nit))
+
+	mockDIANJSON(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"message":  "Empresa creada/actualizada con éxito",
+		"password": nit,
+		"token":    hex.EncodeToString(sum[:]),
+		"company": map[string]interface{}{
+			"identification_number": nit,
+			"dv":                    dv,
+			"business_name":         body["business_name"],
+		},
+	})
+}
+
+// handleSoftware registers the software ID and PIN used for the CUDE
+func (m *MockDIANServer) handleSoftware(w http.ResponseWriter, body map[string]interface{}) {
+	id := mockString(body["id"])
+	pin := mockString(body["pin"])
+	if id == "" || pin == "" {
+		mockDIANValidationError(w, "id", "Los campos id y pin son obligatorios.")
+		return
+	}
+
+	m.mu.Lock()
+	m.company.SoftwareID = id
+	m.company.SoftwarePIN = pin
+	m.mu.Unlock()
+
+	mockDIANJSON(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"message":  "Software creado/actualizado con éxito",
+		"software": map[string]interface{}{"identifier": id, "pin": pin},
+	})
+}
+
+// handleResolution registers a numbering resolution for a document type
+func (m *MockDIANServer) handleResolution(w http.ResponseWriter, body map[string]interface{}) {
+	typeDocumentID := mockInt(body["type_document_id"])
+	prefix := mockString(body["prefix"])
+	if typeDocumentID == 0 {
+		mockDIANValidationError(w, "type_document_id", "El campo type document id es obligatorio.")
+		return
+	}
+	if prefix == "" {
+		mockDIANValidationError(w, "prefix", "El campo prefix es obligatorio.")
+		return
+	}
+
+	resolution := mockDIANResolution{
+		TypeDocumentID: typeDocumentID,
+		Prefix:         prefix,
+		Resolution:     mockString(body["resolution"]),
+		ResolutionDate: mockString(body["resolution_date"]),
+		TechnicalKey:   mockString(body["technical_key"]),
+		From:           mockInt(body["from"]),
+		To:             mockInt(body["to"]),
+		DateFrom:       mockString(body["date_from"]),
+		DateTo:         mockString(body["date_to"]),
+	}
+
+	m.mu.Lock()
+	m.resolutions[mockResolutionKey(typeDocumentID, prefix)] = resolution
+	m.mu.Unlock()
+
+	mockDIANJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Resolución creada/actualizada con éxito",
+		"resolution": map[string]interface{}{
+			"type_document_id": typeDocumentID,
+			"prefix":           prefix,
+			"resolution":       resolution.Resolution,
+			"from":             resolution.From,
+			"to":               resolution.To,
+		},
+	})
+}
+
+// handleNumberingRange returns the invoice resolutions authorized for the software
+func (m *MockDIANServer) handleNumberingRange(w http.ResponseWriter, r *http.Request) {
+	if _, ok := mockDIANBody(w, r); !ok {
+		return
+	}
+
+	m.mu.Lock()
+	var ranges []interface{}
+	for _, resolution := range m.sortedResolutions(1) {
+		// The habilitation prefix is not authorized in production
+		if m.environment == 1 && resolution.Prefix == "SETP" {
+			continue
+		}
+		ranges = append(ranges, mockNumberRange(resolution))
+	}
+	if len(ranges) == 0 {
+		ranges = append(ranges, mockNumberRange(m.defaultInvoiceResolution()))
+	}
+	m.mu.Unlock()
+
+	var numberRangeResponse interface{} = ranges
+	if len(ranges) == 1 {
+		numberRangeResponse = ranges[0]
+	}
+
+	mockDIANJSON(w, http.StatusOK, mockSOAPEnvelope("GetNumberingRangeResponse", map[string]interface{}{
+		"GetNumberingRangeResult": map[string]interface{}{
+			"OperationCode":        "100",
+			"OperationDescription": "Acción completada OK.",
+			"ResponseList": map[string]interface{}{
+				"NumberRangeResponse": numberRangeResponse,
+			},
+		},
+	}))
+}
+
+// handleNextConsecutive returns the next number for a document type and prefix
+func (m *MockDIANServer) handleNextConsecutive(w http.ResponseWriter, r *http.Request) {
+	body, ok := mockDIANBody(w, r)
+	if !ok {
+		return
+	}
+
+	typeDocumentID := mockInt(body["type_document_id"])
+	prefix := mockString(body["prefix"])
+	key := mockResolutionKey(typeDocumentID, prefix)
+
+	m.mu.Lock()
+	number := m.lastNumbers[key] + 1
+	if resolution, exists := m.resolutions[key]; exists && number < resolution.From {
+		number = resolution.From
+	}
+	m.mu.Unlock()
+
+	mockDIANJSON(w, http.StatusOK, NextConsecutiveResponse{
+		Success:        true,
+		TypeDocumentID: typeDocumentID,
+		Prefix:         prefix,
+		Number:         number,
+	})
+}
+
+// handleStatusZip returns the validation result of a document sent asynchronously
+func (m *MockDIANServer) handleStatusZip(w http.ResponseWriter, r *http.Request) {
+	zipKey := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/ubl2.1/status/zip/"), "/")
+
+	m.mu.Lock()
+	document, exists := m.documents[zipKey]
+	m.mu.Unlock()
+
+	dianResponse := map[string]interface{}{}
+	switch {
+	case !exists:
+		// IsValid is omitted: the POS keeps polling until its own timeout
+		dianResponse["StatusCode"] = "66"
+		dianResponse["StatusDescription"] = "NSU no encontrado"
+	case time.Now().Before(document.ReadyAt):
+		dianResponse["StatusCode"] = "98"
+		dianResponse["StatusDescription"] = "En proceso de validación"
+	default:
+		dianResponse = mockValidationResult(document)
+		dianResponse["ErrorMessage"] = strings.Join(document.Errors, "; ")
+	}
+
+	mockDIANJSON(w, http.StatusOK, mockSOAPEnvelope("GetStatusZipResponse", map[string]interface{}{
+		"GetStatusZipResult": map[string]interface{}{
+			"DianResponse": dianResponse,
+		},
+	}))
+}
+
+// handleSendEmail accepts the resend email request
+func (m *MockDIANServer) handleSendEmail(w http.ResponseWriter, r *http.Request) {
+	if _, ok := mockDIANBody(w, r); !ok {
+		return
+	}
+	mockDIANJSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": "Correo enviado con éxito (simulado)"})
+}
+
+// mockDocumentTypes maps the document endpoints to their default type_document_id
+var mockDocumentTypes = map[string]int{
+	"invoice":          1,
+	"credit-note":      4,
+	"debit-note":       5,
+	"eqdoc":            15,
+	"support-document": 11,
+	"sd-credit-note":   13,
+}
+
+// handleDocument handles POST /{document} and POST /{document}/{test_set_id}
+func (m *MockDIANServer) handleDocument(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/ubl2.1/"), "/"), "/")
+	defaultType, known := mockDocumentTypes[parts[0]]
+	if !known || len(parts) > 2 || r.Method != http.MethodPost {
+		mockDIANJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Ruta no encontrada"})
+		return
+	}
+	withTestSet := len(parts) == 2 && parts[1] != ""
+
+	body, ok := mockDIANBody(w, r)
+	if !ok {
+		return
+	}
+
+	typeDocumentID := mockInt(body["type_document_id"])
+	if typeDocumentID == 0 {
+		typeDocumentID = defaultType
+	}
+	number := mockInt(body["number"])
+	prefix := mockString(body["prefix"])
+	if number == 0 {
+		mockDIANValidationError(w, "number", "El campo number es obligatorio.")
+		return
+	}
+
+	// Support documents identify the seller, every other document the customer
+	partyField := "customer"
+	if typeDocumentID == 11 || typeDocumentID == 13 {
+		partyField = "seller"
+	}
+	party, _ := body[partyField].(map[string]interface{})
+	partyID := mockString(party["identification_number"])
+	if partyID == "" {
+		mockDIANValidationError(w, partyField+".identification_number", "El campo identification number es obligatorio.")
+		return
+	}
+
+	fullNumber := fmt.Sprintf("%s%d", prefix, number)
+	issuedKey := mockResolutionKey(typeDocumentID, fullNumber)
+
+	m.mu.Lock()
+	key := m.documentKey(typeDocumentID, fullNumber, body, partyID)
+	errors := m.validateDocument(typeDocumentID, prefix, number, issuedKey, body, partyID)
+	if _, duplicated := m.issued[issuedKey]; !duplicated && len(errors) == 0 {
+		m.issued[issuedKey] = key
+	}
+	if number > m.lastNumbers[mockResolutionKey(typeDocumentID, prefix)] {
+		m.lastNumbers[mockResolutionKey(typeDocumentID, prefix)] = number
+	}
+	async := withTestSet || m.options.AsyncValidation
+	validationDelay := m.options.ValidationDelay
+	environment := m.environment
+	nit := m.company.NIT
+	m.mu.Unlock()
+
+	document := &mockDIANDocument{
+		Key:            key,
+		Number:         fullNumber,
+		TypeDocumentID: typeDocumentID,
+		IsValid:        len(errors) == 0,
+		Errors:         errors,
+	}
+
+	var responseDian map[string]interface{}
+	if async {
+		zipKey := m.newZipKey()
+		document.ReadyAt = time.Now().Add(validationDelay)
+
+		m.mu.Lock()
+		m.documents[zipKey] = document
+		m.mu.Unlock()
+
+		responseDian = mockSOAPEnvelope("SendTestSetAsyncResponse", map[string]interface{}{
+			"SendTestSetAsyncResult": map[string]interface{}{
+				"ErrorMessageList": map[string]interface{}{},
+				"ZipKey":           zipKey,
+			},
+		})
+	} else {
+		result := mockValidationResult(document)
+		if len(errors) > 0 {
+			result["ErrorMessage"] = map[string]interface{}{"string": errors}
+		}
+		responseDian = mockSOAPEnvelope("SendBillSyncResponse", map[string]interface{}{
+			"SendBillSyncResult": result,
+		})
+	}
+
+	keyField := "cude"
+	switch typeDocumentID {
+	case 1, 3:
+		keyField = "cufe"
+	case 11, 13:
+		keyField = "cuds"
+	}
+
+	fileName := fmt.Sprintf("%s-%s", mockDocumentLabel(typeDocumentID), fullNumber)
+	mockDIANJSON(w, http.StatusOK, map[string]interface{}{
+		"success":       true,
+		"message":       fmt.Sprintf("%s #%s generada con éxito", mockDocumentLabel(typeDocumentID), fullNumber),
+		"ResponseDian":  responseDian["ResponseDian"],
+		keyField:        key,
+		"QRStr":         mockQRString(environment, fullNumber, body, nit, partyID, key),
+		"urlinvoicexml": fileName + ".xml",
+		"urlinvoicepdf": fileName + ".pdf",
+		"attacheddocument": base64.StdEncoding.EncodeToString(
+			mockAttachedDocument(fullNumber, key, keyField, body, nit, partyID, document.IsValid)),
+	})
+}
+
+// mockAttachedDocument builds a simplified AttachedDocument: the container the provider
+// returns with the signed document and the DIAN validation result, sent to the customer
+func mockAttachedDocument(fullNumber, key, keyField string, body map[string]interface{}, nit, partyID string, isValid bool) []byte {
+	escape := func(value string) string {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(value))
+		return buf.String()
+	}
+	response := "02" // Rejected
+	if isValid {
+		response = "00" // Processed correctly
+	}
+
+	doc := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<AttachedDocument xmlns="urn:oasis:names:specification:ubl:schema:xsd:AttachedDocument-2" xmlns:cac="urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2" xmlns:cbc="urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2">
+  <cbc:UBLVersionID>UBL 2.1</cbc:UBLVersionID>
+  <cbc:CustomizationID>Documentos adjuntos</cbc:CustomizationID>
+  <cbc:ProfileID>Factura Electrónica de Venta</cbc:ProfileID>
+  <cbc:ProfileExecutionID>2</cbc:ProfileExecutionID>
+  <cbc:ID>%s</cbc:ID>
+  <cbc:IssueDate>%s</cbc:IssueDate>
+  <cbc:IssueTime>%s-05:00</cbc:IssueTime>
+  <cbc:DocumentType>Contenedor de Factura Electrónica</cbc:DocumentType>
+  <cbc:ParentDocumentID>%s</cbc:ParentDocumentID>
+  <cac:SenderParty><cac:PartyTaxScheme><cbc:CompanyID>%s</cbc:CompanyID></cac:PartyTaxScheme></cac:SenderParty>
+  <cac:ReceiverParty><cac:PartyTaxScheme><cbc:CompanyID>%s</cbc:CompanyID></cac:PartyTaxScheme></cac:ReceiverParty>
+  <cac:ParentDocumentLineReference>
+    <cbc:LineID>1</cbc:LineID>
+    <cac:DocumentReference>
+      <cbc:ID>%s</cbc:ID>
+      <cbc:UUID schemeName="%s">%s</cbc:UUID>
+      <cbc:DocumentType>ApplicationResponse</cbc:DocumentType>
+      <cac:ResultOfVerification><cbc:ValidatorID>Unidad Especial Dirección de Impuestos y Aduanas Nacionales (simulador)</cbc:ValidatorID><cbc:ValidationResultCode>%s</cbc:ValidationResultCode></cac:ResultOfVerification>
+    </cac:DocumentReference>
+  </cac:ParentDocumentLineReference>
+</AttachedDocument>
+`, escape(fullNumber), escape(mockString(body["date"])), escape(mockString(body["time"])), escape(fullNumber),
+		escape(nit), escape(partyID), escape(fullNumber), strings.ToUpper(keyField)+"-SHA384", escape(key), response)
+	return []byte(doc)
+}
+
+// validateDocument applies the built-in DIAN rules and the configured rejection rules
+// Must be called with the lock held
+func (m *MockDIANServer) validateDocument(typeDocumentID int, prefix string, number int, issuedKey string, body map[string]interface{}, partyID string) []string {
+	var errors []string
+
+	if key, duplicated := m.issued[issuedKey]; duplicated {
+		errors = append(errors, fmt.Sprintf("Regla: 90, Rechazo: Documento con CUFE/CUDE '%s' procesado anteriormente.", key))
+	}
+
+	if resolution, exists := m.resolutions[mockResolutionKey(typeDocumentID, prefix)]; exists && resolution.To > 0 {
+		if number < resolution.From || number > resolution.To {
+			errors = append(errors, fmt.Sprintf("Regla: FAD05e, Rechazo: El número %d no está dentro del rango de numeración autorizado (%d - %d).",
+				number, resolution.From, resolution.To))
+		}
+	}
+
+	totals := mockDocumentTotals(body)
+	if lines, found := mockDocumentLines(body); found {
+		linesTotal := 0.0
+		for _, line := range lines {
+			if lineMap, ok := line.(map[string]interface{}); ok {
+				linesTotal += mockFloat(lineMap["line_extension_amount"])
+			}
+		}
+		if math.Abs(linesTotal-mockFloat(totals["line_extension_amount"])) > 1 {
+			errors = append(errors, fmt.Sprintf("Regla: FAU04, Rechazo: El valor bruto (%.2f) no corresponde a la suma de las líneas (%.2f).",
+				mockFloat(totals["line_extension_amount"]), linesTotal))
+		}
+	}
+
+	payable := mockFloat(totals["payable_amount"])
+	if m.options.RejectAbove > 0 && payable > m.options.RejectAbove {
+		errors = append(errors, fmt.Sprintf("Regla: FAU14, Rechazo: Valor a pagar %.2f supera el límite del simulador (%.2f).", payable, m.options.RejectAbove))
+	}
+	for _, id := range m.options.RejectCustomers {
+		if id == partyID {
+			errors = append(errors, fmt.Sprintf("Regla: FAK24, Rechazo: El adquirente %s no está habilitado (regla del simulador).", partyID))
+			break
+		}
+	}
+	if m.options.RejectRate > 0 && m.random.Intn(100) < m.options.RejectRate {
+		errors = append(errors, "Regla: 99, Rechazo: Rechazo aleatorio del simulador.")
+	}
+
+	return errors
+}
+
+// documentKey computes the CUFE/CUDE/CUDS as DIAN does: SHA-384 of the document fields, the
+// company NIT, the acquirer, the technical key (invoices) or software PIN (other documents)
+// and the environment. Must be called with the lock held
+func (m *MockDIANServer) documentKey(typeDocumentID int, fullNumber string, body map[string]interface{}, partyID string) string {
+	totals := mockDocumentTotals(body)
+	taxes := mockTaxAmounts(body)
+
+	secret := m.company.SoftwarePIN
+	if typeDocumentID == 1 || typeDocumentID == 3 {
+		if resolution, exists := m.resolutions[mockResolutionKey(typeDocumentID, mockString(body["prefix"]))]; exists {
+			secret = resolution.TechnicalKey
+		}
+	}
+
+	input := fullNumber +
+		mockString(body["date"]) +
+		mockString(body["time"]) + "-05:00" +
+		fmt.Sprintf("%.2f", mockFloat(totals["line_extension_amount"])) +
+		"01" + fmt.Sprintf("%.2f", taxes[1]) +
+		"04" + fmt.Sprintf("%.2f", taxes[4]) +
+		"03" + fmt.Sprintf("%.2f", taxes[3]) +
+		fmt.Sprintf("%.2f", mockFloat(totals["payable_amount"])) +
+		m.company.NIT +
+		partyID +
+		secret +
+		strconv.Itoa(m.environment)
+
+	sum := sha512.Sum384([]byte(input))
+	return hex.EncodeToString(sum[:])
+}
+
+// defaultInvoiceResolution returns the resolution DIAN assigns when none has been configured
+// Must be called with the lock held
+func (m *MockDIANServer) defaultInvoiceResolution() mockDIANResolution {
+	if m.environment == 1 {
+		return mockDIANResolution{
+			TypeDocumentID: 1,
+			Prefix:         "FE",
+			Resolution:     "18764000000001",
+			ResolutionDate: time.Now().Format("2006-01-02"),
+			TechnicalKey:   "ed8b1b5b2a9f4e6e8a3c0c0b8d2f5a7e9c1b3d5f",
+			From:           1,
+			To:             5000,
+			DateFrom:       time.Now().Format("2006-01-02"),
+			DateTo:         time.Now().AddDate(2, 0, 0).Format("2006-01-02"),
+		}
+	}
+	return mockDIANResolution{
+		TypeDocumentID: 1,
+		Prefix:         "SETP",
+		Resolution:     "18760000001",
+		ResolutionDate: "2019-01-19",
+		TechnicalKey:   "fc8eac422eba16e22ffd8c6f94b3f40a6e38162c",
+		From:           990000000,
+		To:             995000000,
+		DateFrom:       "2019-01-19",
+		DateTo:         "2030-01-19",
+	}
+}
+
+// sortedResolutions returns the resolutions of a document type ordered by prefix
+// Must be called with the lock held
+func (m *MockDIANServer) sortedResolutions(typeDocumentID int) []mockDIANResolution {
+	var resolutions []mockDIANResolution
+	for _, resolution := range m.resolutions {
+		if resolution.TypeDocumentID == typeDocumentID {
+			resolutions = append(resolutions, resolution)
+		}
+	}
+	sort.Slice(resolutions, func(i, j int) bool { return resolutions[i].Prefix < resolutions[j].Prefix })
+	return resolutions
+}
+
+// newZipKey generates a zip key with the format of the DIAN tracking IDs
+func (m *MockDIANServer) newZipKey() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := make([]byte, 16)
+	m.random.Read(b)
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// mockValidationResult builds the DIAN validation fields of a document
+func mockValidationResult(document *mockDIANDocument) map[string]interface{} {
+	if document.IsValid {
+		return map[string]interface{}{
+			"IsValid":           "true",
+			"StatusCode":        "00",
+			"StatusDescription": "Procesado Correctamente.",
+			"StatusMessage":     fmt.Sprintf("La %s %s, ha sido autorizada.", mockDocumentLabel(document.TypeDocumentID), document.Number),
+			"XmlDocumentKey":    document.Key,
+		}
+	}
+	return map[string]interface{}{
+		"IsValid":           "false",
+		"StatusCode":        "99",
+		"StatusDescription": "Validación contiene errores en campos mandatorios.",
+		"StatusMessage":     "Documento con errores en campos mandatorios.",
+		"XmlDocumentKey":    document.Key,
+	}
+}
+
+// mockDocumentLabel returns the name DIAN uses for a document type
+func mockDocumentLabel(typeDocumentID int) string {
+	switch typeDocumentID {
+	case 3:
+		return "Factura de contingencia"
+	case 4:
+		return "Nota crédito"
+	case 5:
+		return "Nota débito"
+	case 11:
+		return "Documento soporte"
+	case 13:
+		return "Nota de ajuste documento soporte"
+	case 15:
+		return "Documento equivalente POS"
+	default:
+		return "Factura electrónica"
+	}
+}
+
+// mockQRString builds the QR content printed on the document
+func mockQRString(environment int, fullNumber string, body map[string]interface{}, nit, partyID, key string) string {
+	totals := mockDocumentTotals(body)
+	taxes := mockTaxAmounts(body)
+
+	catalog := "https://catalogo-vpfe-hab.dian.gov.co"
+	if environment == 1 {
+		catalog = "https://catalogo-vpfe.dian.gov.co"
+	}
+
+	return fmt.Sprintf("NumFac: %s\nFecFac: %s\nHorFac: %s-05:00\nNitFac: %s\nDocAdq: %s\nValFac: %.2f\nValIva: %.2f\nValOtroIm: %.2f\nValTolFac: %.2f\nCUFE: %s\n%s/document/searchqr?documentkey=%s",
+		fullNumber, mockString(body["date"]), mockString(body["time"]), nit, partyID,
+		mockFloat(totals["line_extension_amount"]), taxes[1], taxes[3]+taxes[4],
+		mockFloat(totals["payable_amount"]), key, catalog, key)
+}
+
+// mockNumberRange builds a NumberRangeResponse entry
+func mockNumberRange(resolution mockDIANResolution) map[string]interface{} {
+	return map[string]interface{}{
+		"ResolutionNumber": resolution.Resolution,
+		"ResolutionDate":   resolution.ResolutionDate,
+		"Prefix":           resolution.Prefix,
+		"FromNumber":       strconv.Itoa(resolution.From),
+		"ToNumber":         strconv.Itoa(resolution.To),
+		"ValidDateFrom":    resolution.DateFrom,
+		"ValidDateTo":      resolution.DateTo,
+		"TechnicalKey":     resolution.TechnicalKey,
+	}
+}
+
+// mockSOAPEnvelope wraps a result in the ResponseDian.Envelope.Body structure of the provider
+func mockSOAPEnvelope(operation string, result map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"ResponseDian": map[string]interface{}{
+			"Envelope": map[string]interface{}{
+				"Body": map[string]interface{}{
+					operation: result,
+				},
+			},
+		},
+	}
+}
+
+// mockDocumentTotals returns the monetary totals of a document (debit notes use requested_monetary_totals)
+func mockDocumentTotals(body map[string]interface{}) map[string]interface{} {
+	if totals, ok := body["legal_monetary_totals"].(map[string]interface{}); ok {
+		return totals
+	}
+	if totals, ok := body["requested_monetary_totals"].(map[string]interface{}); ok {
+		return totals
+	}
+	return map[string]interface{}{}
+}
+
+// mockDocumentLines returns the lines of a document (invoice_lines, credit_note_lines, ...)
+func mockDocumentLines(body map[string]interface{}) ([]interface{}, bool) {
+	for field, value := range body {
+		if strings.HasSuffix(field, "_lines") {
+			if lines, ok := value.([]interface{}); ok {
+				return lines, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// mockTaxAmounts sums the document taxes by tax_id (1 = IVA, 3 = ICA, 4 = INC)
+func mockTaxAmounts(body map[string]interface{}) map[int]float64 {
+	amounts := make(map[int]float64)
+	if taxTotals, ok := body["tax_totals"].([]interface{}); ok {
+		for _, tax := range taxTotals {
+			if taxMap, ok := tax.(map[string]interface{}); ok {
+				amounts[mockInt(taxMap["tax_id"])] += mockFloat(taxMap["tax_amount"])
+			}
+		}
+	}
+	return amounts
+}
+
+func mockResolutionKey(typeDocumentID int, prefix string) string {
+	return fmt.Sprintf("%d-%s", typeDocumentID, prefix)
+}
+
+func mockDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// mockString reads a JSON value that may come as a string or a number
+func mockString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// mockFloat reads a JSON amount that may come as a string ("1000.00") or a number
+func mockFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	default:
+		return 0
+	}
+}
+
+func mockInt(value interface{}) int {
+	return int(mockFloat(value))
+}
+
+// mockDIANBody decodes the JSON request body, answering 400 when it is invalid
+func mockDIANBody(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	body := make(map[string]interface{})
+	if r.Body == nil || r.ContentLength == 0 {
+		return body, true
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		mockDIANJSON(w, http.StatusBadRequest, map[string]interface{}{"message": "JSON inválido: " + err.Error()})
+		return nil, false
+	}
+	return body, true
+}
+
+// mockDIANValidationError answers like the provider's request validation (422)
+func mockDIANValidationError(w http.ResponseWriter, field, message string) {
+	mockDIANJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+		"message": "The given data was invalid.",
+		"errors":  map[string]interface{}{field: []string{message}},
+	})
+}
+
+func mockDIANJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// Embedded mock server used by the "mock" DIAN environment
+var (
+	mockDIANMu     sync.Mutex
+	mockDIANServer *MockDIANServer
+)
+
+// ensureMockDIANServer starts the embedded mock API on first use and refreshes its options
+func ensureMockDIANServer(config *models.DIANConfig) (*MockDIANServer, error) {
+	mockDIANMu.Lock()
+	defer mockDIANMu.Unlock()
+
+	if mockDIANServer == nil {
+		server := NewMockDIANServer(mockDIANOptionsFromConfig(config))
+		server.seed(config)
+		if err := server.Start("127.0.0.1:0"); err != nil {
+			return nil, err
+		}
+		mockDIANServer = server
+		return server, nil
+	}
+
+	mockDIANServer.SetOptions(mockDIANOptionsFromConfig(config))
+	return mockDIANServer, nil
+}
+
+// dianAPIURL returns the base URL of the DIAN API for a configuration.
+// In the "mock" environment it points to the embedded mock server, starting it if needed
+func dianAPIURL(config *models.DIANConfig) string {
+	if config == nil {
+		return ""
+	}
+	if config.Environment != DIANEnvironmentMock {
+		return config.APIURL
+	}
+
+	server, err := ensureMockDIANServer(config)
+	if err != nil {
+		log.Printf("[MOCK DIAN] %v", err)
+		return ""
+	}
+	return server.URL()
+}
+
+// GetMockDIANStatus returns the state of the embedded mock DIAN API
+func (s *DIANService) GetMockDIANStatus() (map[string]interface{}, error) {
+	var dianConfig models.DIANConfig
+	if err := s.db.First(&dianConfig).Error; err != nil {
+		return nil, fmt.Errorf("DIAN configuration not found")
+	}
+
+	if dianConfig.Environment != DIANEnvironmentMock {
+		return map[string]interface{}{"running": false}, nil
+	}
+
+	server, err := ensureMockDIANServer(&dianConfig)
+	if err != nil {
+		return nil, err
+	}
+	return server.Stats(), nil
+}
+
+// ResetMockDIAN forgets the documents received by the mock DIAN API so numbers can be reused
+func (s *DIANService) ResetMockDIAN() error {
+	mockDIANMu.Lock()
+	server := mockDIANServer
+	mockDIANMu.Unlock()
+
+	if server != nil {
+		server.Reset()
+	}
+	return nil
+}
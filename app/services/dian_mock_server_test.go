@@ -0,0 +1,228 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"PosApp/app/models"
+)
+
+// newMockDIANTest starts a mock DIAN API with an invoice resolution and returns an
+// InvoiceService pointed at it
+func newMockDIANTest(t *testing.T, options MockDIANOptions) (*MockDIANServer, *InvoiceService) {
+	t.Helper()
+	mock := NewMockDIANServer(options)
+	mock.seed(&models.DIANConfig{
+		IdentificationNumber: "900123456",
+		DV:                   "7",
+		SoftwarePIN:          "12345",
+		ResolutionPrefix:     "SETP",
+		ResolutionNumber:     "18760000001",
+		TechnicalKey:         "fc8eac422eba16e22ffd8c6f94b3f40a6e38162c",
+		ResolutionFrom:       990000000,
+		ResolutionTo:         995000000,
+		ResolutionDateFrom:   time.Date(2019, 1, 19, 0, 0, 0, 0, time.UTC),
+		ResolutionDateTo:     time.Date(2030, 1, 19, 0, 0, 0, 0, time.UTC),
+	})
+	server := httptest.NewServer(mock.Handler())
+	t.Cleanup(server.Close)
+
+	invoices := &InvoiceService{
+		config: &models.DIANConfig{Environment: "test", APIURL: server.URL, APIToken: "token"},
+		client: server.Client(),
+	}
+	return mock, invoices
+}
+
+// mockInvoiceBody builds an invoice request with a single line
+func mockInvoiceBody(number int, customer string, total float64) map[string]interface{} {
+	return map[string]interface{}{
+		"number":   number,
+		"prefix":   "SETP",
+		"date":     "2026-10-18",
+		"time":     "10:00:00",
+		"customer": map[string]interface{}{"identification_number": customer},
+		"legal_monetary_totals": map[string]interface{}{
+			"line_extension_amount": total,
+			"payable_amount":        total,
+		},
+		"invoice_lines": []interface{}{
+			map[string]interface{}{"line_extension_amount": total},
+		},
+	}
+}
+
+func TestMockDIANSyncInvoice(t *testing.T) {
+	_, invoices := newMockDIANTest(t, MockDIANOptions{RejectAbove: 1000000, RejectCustomers: []string{"222222222"}})
+
+	tests := []struct {
+		name       string
+		body       map[string]interface{}
+		wantStatus string
+	}{
+		{"accepted", mockInvoiceBody(990000001, "1017123456", 50000), "accepted"},
+		{"duplicated number", mockInvoiceBody(990000001, "1017123456", 50000), "rejected"},
+		{"outside the resolution range", mockInvoiceBody(1, "1017123456", 50000), "rejected"},
+		{"above the simulator limit", mockInvoiceBody(990000002, "1017123456", 2000000), "rejected"},
+		{"rejected customer", mockInvoiceBody(990000003, "222222222", 50000), "rejected"},
+		{"lines don't add up", func() map[string]interface{} {
+			body := mockInvoiceBody(990000004, "1017123456", 50000)
+			body["invoice_lines"] = []interface{}{map[string]interface{}{"line_extension_amount": 10000.0}}
+			return body
+		}(), "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := invoices.sendToDIAN(tt.body, "invoice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := parseDocumentResponse(response)
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %s (%s), want %s", result.Status, result.ValidationMessage, tt.wantStatus)
+			}
+			if len(result.Key) != 96 {
+				t.Errorf("CUFE = %q, want a SHA-384 hex digest", result.Key)
+			}
+			if !strings.Contains(result.QRCode, result.Key) {
+				t.Error("QR doesn't include the CUFE")
+			}
+		})
+	}
+}
+
+func TestMockDIANAsyncValidation(t *testing.T) {
+	mock, invoices := newMockDIANTest(t, MockDIANOptions{AsyncValidation: true, ValidationDelay: time.Hour})
+
+	response, err := invoices.sendToDIAN(mockInvoiceBody(990000010, "1017123456", 50000), "pos_equivalent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := parseDocumentResponse(response)
+	if result.Status != "validating" || result.ZipKey == "" {
+		t.Fatalf("async send = %s with zip key %q, want validating", result.Status, result.ZipKey)
+	}
+
+	statusZip := func(zipKey string) map[string]interface{} {
+		t.Helper()
+		req, _ := http.NewRequest("POST", invoices.config.APIURL+"/api/ubl2.1/status/zip/"+zipKey, bytes.NewBufferString("{}"))
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := invoices.client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return body["ResponseDian"].(map[string]interface{})["Envelope"].(map[string]interface{})["Body"].(map[string]interface{})["GetStatusZipResponse"].(map[string]interface{})["GetStatusZipResult"].(map[string]interface{})["DianResponse"].(map[string]interface{})
+	}
+
+	if status := statusZip(result.ZipKey); status["StatusCode"] != "98" || status["IsValid"] != nil {
+		t.Errorf("status before validation = %v, want in process", status)
+	}
+	if status := statusZip("unknown"); status["StatusCode"] != "66" {
+		t.Errorf("status of an unknown zip key = %v, want 66", status["StatusCode"])
+	}
+
+	// Finish the validation as if the delay had elapsed
+	mock.mu.Lock()
+	mock.documents[result.ZipKey].ReadyAt = time.Now()
+	mock.mu.Unlock()
+
+	status := statusZip(result.ZipKey)
+	if status["IsValid"] != "true" || status["XmlDocumentKey"] != result.Key {
+		t.Errorf("status after validation = %v, want valid with key %s", status, result.Key)
+	}
+	if stats := mock.Stats(); stats["async_accepted"] != 1 || stats["documents_received"] != 1 {
+		t.Errorf("stats = %v, want one accepted document", stats)
+	}
+}
+
+func TestMockDIANRequests(t *testing.T) {
+	mock, invoices := newMockDIANTest(t, MockDIANOptions{})
+
+	post := func(path, token string, body interface{}) (int, map[string]interface{}) {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", invoices.config.APIURL+path, bytes.NewBuffer(data))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := invoices.client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result
+	}
+
+	if code, _ := post("/api/ubl2.1/invoice", "", mockInvoiceBody(990000001, "1017123456", 1000)); code != http.StatusUnauthorized {
+		t.Errorf("document without token = %d, want 401", code)
+	}
+	if code, result := post("/api/ubl2.1/config/900123456/7", "", map[string]interface{}{"business_name": "Restaurante"}); code != http.StatusOK || result["token"] == "" {
+		t.Errorf("company configuration = %d %v, want a token", code, result)
+	}
+	if code, _ := post("/api/ubl2.1/invoice", "token", map[string]interface{}{"prefix": "SETP"}); code != http.StatusUnprocessableEntity {
+		t.Errorf("document without number = %d, want 422", code)
+	}
+	if code, _ := post("/api/ubl2.1/payroll", "token", map[string]interface{}{}); code != http.StatusNotFound {
+		t.Errorf("unknown document = %d, want 404", code)
+	}
+
+	consecutive := map[string]interface{}{"type_document_id": 1, "prefix": "SETP"}
+	if _, result := post("/api/ubl2.1/next-consecutive", "token", consecutive); result["number"] != float64(990000000) {
+		t.Errorf("first consecutive = %v, want the start of the resolution", result["number"])
+	}
+	if _, err := invoices.sendToDIAN(mockInvoiceBody(990000005, "1017123456", 1000), "invoice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, result := post("/api/ubl2.1/next-consecutive", "token", consecutive); result["number"] != float64(990000006) {
+		t.Errorf("consecutive after a document = %v, want 990000006", result["number"])
+	}
+
+	mock.SetOptions(MockDIANOptions{Unavailable: true})
+	if _, err := invoices.sendToDIAN(mockInvoiceBody(990000006, "1017123456", 1000), "invoice"); !errors.Is(err, errDIANUnavailable) {
+		t.Errorf("send during a simulated outage error = %v, want errDIANUnavailable", err)
+	}
+}
+
+func TestDIANAPIURLStartsTheMockServer(t *testing.T) {
+	t.Cleanup(func() {
+		mockDIANMu.Lock()
+		defer mockDIANMu.Unlock()
+		if mockDIANServer != nil {
+			mockDIANServer.Stop()
+			mockDIANServer = nil
+		}
+	})
+
+	if got := dianAPIURL(&models.DIANConfig{Environment: "test", APIURL: "https://api.example.com"}); got != "https://api.example.com" {
+		t.Errorf("test environment URL = %q", got)
+	}
+
+	url := dianAPIURL(&models.DIANConfig{Environment: DIANEnvironmentMock, APIURL: "https://api.example.com"})
+	if !strings.HasPrefix(url, "http://127.0.0.1:") {
+		t.Fatalf("mock environment URL = %q, want the local simulator", url)
+	}
+	resp, err := http.Get(url + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("simulator root = %d, want 200", resp.StatusCode)
+	}
+	if again := dianAPIURL(&models.DIANConfig{Environment: DIANEnvironmentMock}); again != url {
+		t.Errorf("second call started another simulator at %q", again)
+	}
+}
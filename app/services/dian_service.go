@@ -80,7 +80,7 @@ func (s *DIANService) ConfigureCompany() (map[string]interface{}, error) {
 		return nil, fmt.Errorf("NIT and DV are required in DIAN configuration")
 	}
 
-	if dianConfig.APIURL == "" {
+	if dianAPIURL(&dianConfig) == "" {
 		return nil, fmt.Errorf("API URL is required in DIAN configuration")
 	}
 
@@ -110,7 +110,7 @@ func (s *DIANService) ConfigureCompany() (map[string]interface{}, error) {
 
 	// Build URL: {api_url}/api/ubl2.1/config/{nit}/{dv}
 	url := fmt.Sprintf("%s/api/ubl2.1/config/%s/%s",
-		dianConfig.APIURL,
+		dianAPIURL(&dianConfig),
 		dianConfig.IdentificationNumber,
 		dianConfig.DV,
 	)
@@ -184,7 +184,7 @@ func (s *DIANService) ConfigureSoftware() error {
 		return fmt.Errorf("Software ID and PIN are required")
 	}
 
-	url := fmt.Sprintf("%s/api/ubl2.1/config/software", dianConfig.APIURL)
+	url := fmt.Sprintf("%s/api/ubl2.1/config/software", dianAPIURL(&dianConfig))
 
 	// Convert PIN to integer if numeric
 	var pinValue interface{} = dianConfig.SoftwarePIN
@@ -246,7 +246,7 @@ func (s *DIANService) ConfigureCertificate() error {
 		return fmt.Errorf("Certificate and password are required")
 	}
 
-	url := fmt.Sprintf("%s/api/ubl2.1/config/certificate", dianConfig.APIURL)
+	url := fmt.Sprintf("%s/api/ubl2.1/config/certificate", dianAPIURL(&dianConfig))
 
 	data := map[string]interface{}{
 		"certificate": dianConfig.Certificate,
@@ -316,7 +316,7 @@ func (s *DIANService) ConfigureLogo() error {
 		return fmt.Errorf("failed to process logo: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/api/ubl2.1/config/logo", dianConfig.APIURL)
+	url := fmt.Sprintf("%s/api/ubl2.1/config/logo", dianAPIURL(&dianConfig))
 
 	data := map[string]interface{}{
 		"logo": logoBase64,
@@ -459,7 +459,7 @@ func (s *DIANService) ConfigureResolution() error {
 		return fmt.Errorf("Technical key is required")
 	}
 
-	url := fmt.Sprintf("%s/api/ubl2.1/config/resolution", dianConfig.APIURL)
+	url := fmt.Sprintf("%s/api/ubl2.1/config/resolution", dianAPIURL(&dianConfig))
 
 	// Handle zero-value dates by using default test environment dates
 	dateFrom := dianConfig.ResolutionDateFrom
@@ -536,7 +536,7 @@ func (s *DIANService) ConfigureCreditNoteResolution() error {
 		return fmt.Errorf("Credit Note resolution prefix is required")
 	}
 
-	url := fmt.Sprintf("%s/api/ubl2.1/config/resolution", dianConfig.APIURL)
+	url := fmt.Sprintf("%s/api/ubl2.1/config/resolution", dianAPIURL(&dianConfig))
 
 	data := map[string]interface{}{
 		"type_document_id": 4, // Credit Note
@@ -607,7 +607,7 @@ func (s *DIANService) ConfigureDebitNoteResolution() error {
 		return fmt.Errorf("Debit Note resolution prefix is required")
 	}
 
-	url := fmt.Sprintf("%s/api/ubl2.1/config/resolution", dianConfig.APIURL)
+	url := fmt.Sprintf("%s/api/ubl2.1/config/resolution", dianAPIURL(&dianConfig))
 
 	data := map[string]interface{}{
 		"type_document_id": 5, // Debit Note
@@ -662,13 +662,212 @@ func (s *DIANService) ConfigureDebitNoteResolution() error {
 	return nil
 }
 
+// ConfigurePOSResolution configures the POS equivalent document resolution in DIAN API
+func (s *DIANService) ConfigurePOSResolution() error {
+	var dianConfig models.DIANConfig
+	if err := s.db.First(&dianConfig).Error; err != nil {
+		return fmt.Errorf("DIAN configuration not found")
+	}
+
+	if dianConfig.APIToken == "" {
+		return fmt.Errorf("API token not found. Please configure company first (Step 1)")
+	}
+
+	// Validate required fields
+	if dianConfig.POSResolutionNumber == "" {
+		return fmt.Errorf("POS resolution number is required")
+	}
+	if dianConfig.POSResolutionPrefix == "" {
+		return fmt.Errorf("POS resolution prefix is required")
+	}
+	if dianConfig.POSTechnicalKey == "" {
+		return fmt.Errorf("POS technical key is required")
+	}
+	if dianConfig.POSPlateNumber == "" {
+		return fmt.Errorf("POS cash register plate number is required")
+	}
+
+	url := fmt.Sprintf("%s/api/ubl2.1/config/resolution", dianAPIURL(&dianConfig))
+
+	// Handle zero-value dates by using default test environment dates
+	dateFrom := dianConfig.POSResolutionDateFrom
+	if dateFrom.IsZero() {
+		dateFrom = time.Date(2019, 1, 19, 0, 0, 0, 0, time.UTC)
+	}
+
+	dateTo := dianConfig.POSResolutionDateTo
+	if dateTo.IsZero() {
+		dateTo = time.Date(2030, 1, 19, 0, 0, 0, 0, time.UTC)
+	}
+
+	data := map[string]interface{}{
+		"type_document_id":  15, // POS Equivalent Document
+		"prefix":            dianConfig.POSResolutionPrefix,
+		"resolution":        dianConfig.POSResolutionNumber,
+		"resolution_date":   dateFrom.Format("2006-01-02"),
+		"technical_key":     dianConfig.POSTechnicalKey,
+		"from":              dianConfig.POSResolutionFrom,
+		"to":                dianConfig.POSResolutionTo,
+		"generated_to_date": 0, // Always 0 for initial configuration
+		"date_from":         dateFrom.Format("2006-01-02"),
+		"date_to":           dateTo.Format("2006-01-02"),
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", dianConfig.APIToken))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("DIAN API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	// Start the consecutive at the beginning of the range
+	if dianConfig.LastPOSNumber < dianConfig.POSResolutionFrom-1 {
+		dianConfig.LastPOSNumber = dianConfig.POSResolutionFrom - 1
+	}
+
+	dianConfig.Step8Completed = true
+	if err := s.db.Save(&dianConfig).Error; err != nil {
+		return fmt.Errorf("failed to save step completion: %w", err)
+	}
+
+	s.config = &dianConfig
+
+	return nil
+}
+
+// ConfigureSupportDocumentResolution configures the support document resolution in DIAN API
+// If an adjustment note prefix is set, its numbering range is configured as well
+func (s *DIANService) ConfigureSupportDocumentResolution() error {
+	var dianConfig models.DIANConfig
+	if err := s.db.First(&dianConfig).Error; err != nil {
+		return fmt.Errorf("DIAN configuration not found")
+	}
+
+	if dianConfig.APIToken == "" {
+		return fmt.Errorf("API token not found. Please configure company first (Step 1)")
+	}
+
+	// Validate required fields
+	if dianConfig.SupportDocResolutionNumber == "" {
+		return fmt.Errorf("support document resolution number is required")
+	}
+	if dianConfig.SupportDocResolutionPrefix == "" {
+		return fmt.Errorf("support document resolution prefix is required")
+	}
+
+	// Handle zero-value dates by using default test environment dates
+	dateFrom := dianConfig.SupportDocResolutionDateFrom
+	if dateFrom.IsZero() {
+		dateFrom = time.Date(2019, 1, 19, 0, 0, 0, 0, time.UTC)
+	}
+
+	dateTo := dianConfig.SupportDocResolutionDateTo
+	if dateTo.IsZero() {
+		dateTo = time.Date(2030, 1, 19, 0, 0, 0, 0, time.UTC)
+	}
+
+	data := map[string]interface{}{
+		"type_document_id":  11, // Support Document
+		"prefix":            dianConfig.SupportDocResolutionPrefix,
+		"resolution":        dianConfig.SupportDocResolutionNumber,
+		"resolution_date":   dateFrom.Format("2006-01-02"),
+		"from":              dianConfig.SupportDocResolutionFrom,
+		"to":                dianConfig.SupportDocResolutionTo,
+		"generated_to_date": 0, // Always 0 for initial configuration
+		"date_from":         dateFrom.Format("2006-01-02"),
+		"date_to":           dateTo.Format("2006-01-02"),
+	}
+	if err := s.putResolution(&dianConfig, data); err != nil {
+		return err
+	}
+
+	if dianConfig.SupportDocAdjustmentPrefix != "" {
+		adjustmentData := map[string]interface{}{
+			"type_document_id": 13, // Support Document Adjustment Note
+			"prefix":           dianConfig.SupportDocAdjustmentPrefix,
+			"from":             dianConfig.SupportDocAdjustmentFrom,
+			"to":               dianConfig.SupportDocAdjustmentTo,
+		}
+		if err := s.putResolution(&dianConfig, adjustmentData); err != nil {
+			return fmt.Errorf("failed to configure adjustment note numbering: %w", err)
+		}
+	}
+
+	// Start the consecutives at the beginning of their ranges
+	if dianConfig.LastSupportDocNumber < dianConfig.SupportDocResolutionFrom-1 {
+		dianConfig.LastSupportDocNumber = dianConfig.SupportDocResolutionFrom - 1
+	}
+	if dianConfig.LastSupportDocAdjustmentNumber < dianConfig.SupportDocAdjustmentFrom-1 {
+		dianConfig.LastSupportDocAdjustmentNumber = dianConfig.SupportDocAdjustmentFrom - 1
+	}
+
+	dianConfig.Step9Completed = true
+	if err := s.db.Save(&dianConfig).Error; err != nil {
+		return fmt.Errorf("failed to save step completion: %w", err)
+	}
+
+	s.config = &dianConfig
+
+	return nil
+}
+
+// putResolution sends a numbering range to the DIAN API resolution endpoint
+func (s *DIANService) putResolution(dianConfig *models.DIANConfig, data map[string]interface{}) error {
+	url := fmt.Sprintf("%s/api/ubl2.1/config/resolution", dianAPIURL(dianConfig))
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", dianConfig.APIToken))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("DIAN API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
 // ChangeEnvironment changes between test and production environment
 func (s *DIANService) ChangeEnvironment(environment string) error {
 	if s.config == nil || s.config.APIToken == "" {
 		return fmt.Errorf("DIAN configuration not complete")
 	}
 
-	url := fmt.Sprintf("%s/api/ubl2.1/config/environment", s.config.APIURL)
+	url := fmt.Sprintf("%s/api/ubl2.1/config/environment", dianAPIURL(s.config))
 
 	// Environment IDs: 1=Production, 2=Test
 	envID := 2 // Test by default
@@ -707,7 +906,10 @@ func (s *DIANService) ChangeEnvironment(environment string) error {
 		return fmt.Errorf("DIAN API error: %s", string(body))
 	}
 
-	s.config.Environment = environment
+	// The mock environment keeps pointing to the simulator, which tracks test/production itself
+	if s.config.Environment != DIANEnvironmentMock {
+		s.config.Environment = environment
+	}
 
 	// Mark step 7 as completed when migrating to production
 	if environment == "production" {
@@ -725,7 +927,7 @@ func (s *DIANService) GetNumberingRanges() (map[string]interface{}, error) {
 		return nil, fmt.Errorf("DIAN configuration not complete")
 	}
 
-	url := fmt.Sprintf("%s/api/ubl2.1/numbering-range", s.config.APIURL)
+	url := fmt.Sprintf("%s/api/ubl2.1/numbering-range", dianAPIURL(s.config))
 
 	data := map[string]interface{}{
 		"IDSoftware": s.config.SoftwareID,
@@ -882,6 +1084,11 @@ func (s *DIANService) MigrateToProduction() error {
 
 	fmt.Println("🚀 Starting production migration...")
 
+	// Back up the database: the migration replaces the resolution and consecutive numbers
+	if _, err := BackupBeforeChange(BackupReasonPreDIANProd); err != nil {
+		return err
+	}
+
 	// Step 1: Change environment to production
 	fmt.Println("📍 Step 1: Changing environment to production...")
 	if err := s.ChangeEnvironment("production"); err != nil {
@@ -1275,6 +1482,9 @@ func (s *DIANService) ResetConfigurationSteps() error {
 	config.Step5Completed = false
 	config.Step6Completed = false
 	config.Step7Completed = false
+	config.Step8Completed = false
+	config.Step9Completed = false
+	config.Step10Completed = false
 
 	if err := s.db.Save(&config).Error; err != nil {
 		return fmt.Errorf("failed to reset configuration steps: %w", err)
@@ -1289,11 +1499,11 @@ func (s *DIANService) ResetConfigurationSteps() error {
 
 // TestConnection tests the connection to DIAN API
 func (s *DIANService) TestConnection() error {
-	if s.config == nil || s.config.APIURL == "" {
+	if s.config == nil || dianAPIURL(s.config) == "" {
 		return fmt.Errorf("DIAN API URL not configured")
 	}
 
-	req, err := http.NewRequest("GET", s.config.APIURL, nil)
+	req, err := http.NewRequest("GET", dianAPIURL(s.config), nil)
 	if err != nil {
 		return err
 	}
@@ -1443,10 +1653,10 @@ func (s *DIANService) SendInvoice(invoice *DIANInvoice) (*DIANInvoiceResponse, e
 		if dianConfig.TestSetID == "" {
 			return nil, fmt.Errorf("test set ID not configured for test environment")
 		}
-		url = fmt.Sprintf("%s/api/ubl2.1/invoice/%s", dianConfig.APIURL, dianConfig.TestSetID)
+		url = fmt.Sprintf("%s/api/ubl2.1/invoice/%s", dianAPIURL(&dianConfig), dianConfig.TestSetID)
 	} else {
 		// In production mode or test without test_set_id, no test_set_id in URL
-		url = fmt.Sprintf("%s/api/ubl2.1/invoice", dianConfig.APIURL)
+		url = fmt.Sprintf("%s/api/ubl2.1/invoice", dianAPIURL(&dianConfig))
 	}
 
 	// Marshal invoice to JSON
@@ -1505,7 +1715,9 @@ func (s *DIANService) SendInvoice(invoice *DIANInvoice) (*DIANInvoiceResponse, e
 }
 
 // ResendInvoiceEmail resends the invoice email to the customer
-// Uses the DIAN API endpoint: POST /api/send-email-employee/NO
+// When invoices are emailed from our own SMTP server (mail_send_invoices) and the server is
+// configured, the email is queued there and the provider is not contacted; otherwise it uses
+// the DIAN API endpoint: POST /api/send-email-employee/NO
 func (s *DIANService) ResendInvoiceEmail(prefix string, invoiceNumber string) error {
 	// Load config
 	var dianConfig models.DIANConfig
@@ -1513,12 +1725,21 @@ func (s *DIANService) ResendInvoiceEmail(prefix string, invoiceNumber string) er
 		return fmt.Errorf("DIAN configuration not found")
 	}
 
+	if mailSendsInvoices(s.db) {
+		var invoice models.ElectronicInvoice
+		if err := s.db.Where("prefix = ? AND invoice_number = ?", prefix, invoiceNumber).First(&invoice).Error; err != nil {
+			return fmt.Errorf("factura %s%s no encontrada", prefix, invoiceNumber)
+		}
+		_, err := queueInvoiceMail(s.db, invoice.SaleID, "")
+		return err
+	}
+
 	if dianConfig.APIToken == "" {
 		return fmt.Errorf("API token not found. Please complete DIAN configuration first")
 	}
 
 	// Build URL: {api_url}/api/send-email-employee/NO
-	url := fmt.Sprintf("%s/api/send-email-employee/NO", dianConfig.APIURL)
+	url := fmt.Sprintf("%s/api/send-email-employee/NO", dianAPIURL(&dianConfig))
 
 	data := map[string]interface{}{
 		"company_idnumber": dianConfig.IdentificationNumber,
@@ -1574,7 +1795,7 @@ func (s *DIANService) GetNextConsecutive(typeDocumentID int, prefix string) (*Ne
 		return nil, fmt.Errorf("API token not found. Please complete DIAN configuration first")
 	}
 
-	url := fmt.Sprintf("%s/api/ubl2.1/next-consecutive", dianConfig.APIURL)
+	url := fmt.Sprintf("%s/api/ubl2.1/next-consecutive", dianAPIURL(&dianConfig))
 
 	data := map[string]interface{}{
 		"type_document_id": typeDocumentID,
@@ -1631,7 +1852,9 @@ func (s *DIANService) ResetTestResolution() error {
 	dianConfig.ResolutionDateFrom, _ = time.Parse("2006-01-02", "2019-01-19")
 	dianConfig.ResolutionDateTo, _ = time.Parse("2006-01-02", "2030-01-19")
 	dianConfig.LastInvoiceNumber = 0
-	dianConfig.Environment = "test"
+	if dianConfig.Environment != DIANEnvironmentMock {
+		dianConfig.Environment = "test"
+	}
 	dianConfig.Step4Completed = false
 	dianConfig.Step7Completed = false
 
@@ -1713,6 +1936,32 @@ func (s *DIANService) GetResolutionLimitStatus() (*ResolutionLimitStatus, error)
 	}, nil
 }
 
+// GetPOSResolutionLimitStatus returns the POS equivalent document range status for notifications
+func (s *DIANService) GetPOSResolutionLimitStatus() (*ResolutionLimitStatus, error) {
+	var dianConfig models.DIANConfig
+	if err := s.db.First(&dianConfig).Error; err != nil {
+		return nil, fmt.Errorf("DIAN configuration not found")
+	}
+
+	remaining := dianConfig.POSResolutionTo - dianConfig.LastPOSNumber
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	threshold := dianConfig.InvoiceLimitAlertThreshold
+	if threshold == 0 {
+		threshold = 100 // Default
+	}
+
+	return &ResolutionLimitStatus{
+		RemainingInvoices: remaining,
+		AlertThreshold:    threshold,
+		IsNearLimit:       remaining <= threshold,
+		CurrentNumber:     dianConfig.LastPOSNumber,
+		EndNumber:         dianConfig.POSResolutionTo,
+	}, nil
+}
+
 // UpdateAlertThreshold updates the invoice limit alert threshold
 func (s *DIANService) UpdateAlertThreshold(threshold int) error {
 	var dianConfig models.DIANConfig
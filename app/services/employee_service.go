@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // EmployeeService handles employee and cash register operations
@@ -323,9 +324,15 @@ func (s *EmployeeService) CloseCashRegister(registerID uint, closingAmount float
 		return nil, err
 	}
 
+	// Attach the Bold reconciliation of the shift
+	s.attachBoldReconciliation(report, &register)
+
 	// Print report
 	go s.printerSvc.PrintCashRegisterReport(report)
 
+	// Email report to the owners if enabled
+	go queueCashRegisterClosingMail(s.db, report.ID)
+
 	// Send report to Google Sheets if enabled
 	go func() {
 		googleSheetsService := NewGoogleSheetsService(s.db)
@@ -340,6 +347,27 @@ func (s *EmployeeService) CloseCashRegister(registerID uint, closingAmount float
 	return report, nil
 }
 
+// attachBoldReconciliation reconciles the Bold transactions of the register and links the result to the report
+// Reconciliation errors never block the close
+func (s *EmployeeService) attachBoldReconciliation(report *models.CashRegisterReport, register *models.CashRegister) {
+	var boldConfig models.BoldConfig
+	if err := s.db.First(&boldConfig).Error; err != nil || !boldConfig.Enabled {
+		return
+	}
+
+	reconciliation, err := NewBoldReconciliationService(s.db).ReconcileCashRegister(register.ID, register.EmployeeID)
+	if err != nil {
+		log.Printf("Warning: Failed to reconcile Bold payments for cash register %d: %v", register.ID, err)
+		return
+	}
+
+	report.BoldReconciliationID = &reconciliation.ID
+	report.BoldReconciliation = reconciliation
+	if err := s.db.Model(report).Update("bold_reconciliation_id", reconciliation.ID).Error; err != nil {
+		log.Printf("Warning: Failed to attach Bold reconciliation to cash register report %d: %v", report.ID, err)
+	}
+}
+
 // PrintCurrentCashRegisterReport generates and prints a current cash register report without closing
 func (s *EmployeeService) PrintCurrentCashRegisterReport(registerID uint) error {
 	if err := s.EnsureDB(); err != nil {
@@ -400,6 +428,13 @@ func (s *EmployeeService) PrintCurrentCashRegisterReport(registerID uint) error
 				continue
 			}
 
+			if tipCollected(&payment) {
+				report.TotalTips += payment.TipAmount
+				if payment.PaymentMethod.AffectsCashRegister {
+					report.CashTips += payment.TipAmount
+				}
+			}
+
 			// For cash register report display: Only include payment methods that should show in cash summary
 			if payment.PaymentMethod.ShowInCashSummary {
 				hasVisiblePayment = true
@@ -474,6 +509,14 @@ func (s *EmployeeService) PrintLastCashRegisterReport(employeeID uint) error {
 		return fmt.Errorf("failed to generate report: %w", err)
 	}
 
+	// Reuse the Bold reconciliation made at close
+	var reconciliation models.BoldReconciliation
+	if err := s.db.Where("cash_register_id = ?", register.ID).Order("created_at DESC").First(&reconciliation).Error; err == nil {
+		report.BoldReconciliationID = &reconciliation.ID
+		report.BoldReconciliation = &reconciliation
+		s.db.Model(report).Update("bold_reconciliation_id", reconciliation.ID)
+	}
+
 	// Print the report
 	return s.printerSvc.PrintCashRegisterReport(report)
 }
@@ -484,7 +527,7 @@ func (s *EmployeeService) GetCashRegisterReport(reportID uint) (*models.CashRegi
 		return nil, err
 	}
 	var report models.CashRegisterReport
-	err := s.db.Preload("Employee").First(&report, reportID).Error
+	err := s.db.Preload("Employee").Preload("BoldReconciliation").First(&report, reportID).Error
 	if err != nil {
 		return nil, fmt.Errorf("cash register report not found: %w", err)
 	}
@@ -598,6 +641,9 @@ type CashRegisterSalesSummary struct {
 	CountDisplay               int                `json:"count_display"`
 	ServiceChargeByPayment     map[string]float64 `json:"service_charge_by_payment"`  // Service charge breakdown by payment method
 	TotalServiceCharge         float64            `json:"total_service_charge"`       // Total service charge collected
+	TipsByPayment              map[string]float64 `json:"tips_by_payment"`            // Voluntary tips breakdown by payment method
+	TotalTips                  float64            `json:"total_tips"`                 // Total voluntary tips collected
+	CashTips                   float64            `json:"cash_tips"`                  // Tips that stay in the drawer (affects_cash_register)
 }
 
 // GetCashRegisterSalesSummary returns an optimized sales summary for a cash register
@@ -617,6 +663,7 @@ func (s *EmployeeService) GetCashRegisterSalesSummary(registerID uint, onlyElect
 		ByPaymentMethod:        make(map[string]float64),
 		ByPaymentMethodDisplay: make(map[string]float64),
 		ServiceChargeByPayment: make(map[string]float64),
+		TipsByPayment:          make(map[string]float64),
 	}
 
 	// Query for aggregated payment totals by payment method
@@ -711,6 +758,39 @@ func (s *EmployeeService) GetCashRegisterSalesSummary(registerID uint, onlyElect
 		}
 	}
 
+	// Query for voluntary tips by payment method (tips are not part of sale totals)
+	type TipSummary struct {
+		PaymentMethodName   string
+		AffectsCashRegister bool
+		TotalTips           float64
+	}
+
+	var tipResults []TipSummary
+
+	tipQuery := collectedTipsQuery(s.db, registerID, register.OpenedAt).
+		Select(`
+			payment_methods.name as payment_method_name,
+			payment_methods.affects_cash_register,
+			SUM(payments.tip_amount) as total_tips
+		`).
+		Group("payment_methods.name, payment_methods.affects_cash_register")
+
+	if onlyElectronic {
+		tipQuery = tipQuery.Where("sales.needs_electronic_invoice = ?", true)
+	}
+
+	if err := tipQuery.Scan(&tipResults).Error; err != nil {
+		log.Printf("Warning: error calculating tips summary: %v", err)
+	} else {
+		for _, r := range tipResults {
+			summary.TipsByPayment[r.PaymentMethodName] += r.TotalTips
+			summary.TotalTips += r.TotalTips
+			if r.AffectsCashRegister {
+				summary.CashTips += r.TotalTips
+			}
+		}
+	}
+
 	// Get accurate sale counts
 	var countBalance int64
 	countQuery := s.db.Table("sales").
@@ -808,6 +888,16 @@ func (s *EmployeeService) calculateExpectedCash(register *models.CashRegister) f
 	}
 	log.Printf("  DEBUG: Total from payments: %.2f", paymentTotal)
 
+	// Tips are collected on top of the payment and stay in the drawer until distributed
+	// Same rule as the closing summary (see collectedTipsQuery)
+	tipTotal := 0.0
+	collectedTipsQuery(s.db, register.ID, register.OpenedAt).
+		Where("payment_methods.affects_cash_register = ?", true).
+		Select("COALESCE(SUM(payments.tip_amount), 0)").
+		Scan(&tipTotal)
+	expected += tipTotal
+	log.Printf("  DEBUG: Total from tips: %.2f", tipTotal)
+
 	// Subtract refunded payments that affected cash register (money returned to customer)
 	// IMPORTANT: Only include payment methods where affects_cash_register = true
 	// CRITICAL: Only count sales created AFTER the cash register was opened
@@ -829,11 +919,29 @@ func (s *EmployeeService) calculateExpectedCash(register *models.CashRegister) f
 	}
 	log.Printf("  DEBUG: Total from refunds: -%.2f", refundTotal)
 
-	log.Printf("DEBUG calculateExpectedCash: Final expected=%.2f (Opening=%.2f + Movements=%.2f + Payments=%.2f - Refunds=%.2f)",
-		expected, register.OpeningAmount, movementTotal, paymentTotal, refundTotal)
+	log.Printf("DEBUG calculateExpectedCash: Final expected=%.2f (Opening=%.2f + Movements=%.2f + Payments=%.2f + Tips=%.2f - Refunds=%.2f)",
+		expected, register.OpeningAmount, movementTotal, paymentTotal, tipTotal, refundTotal)
 	return expected
 }
 
+// collectedTipsQuery selects the payments of a cash register shift whose tips count as collected.
+// A refund returns the sale amount, not the tip, so tips of refunded sales are still collected;
+// only tips of gateway payments voided in full go back to the customer's card.
+// Expected cash, the closing summary, the printed report and the tip pool all follow this rule.
+func collectedTipsQuery(db *gorm.DB, registerID uint, openedAt time.Time) *gorm.DB {
+	return db.Table("payments").
+		Joins("JOIN sales ON payments.sale_id = sales.id AND sales.deleted_at IS NULL").
+		Joins("JOIN payment_methods ON payments.payment_method_id = payment_methods.id").
+		Where("sales.cash_register_id = ?", registerID).
+		Where("sales.created_at >= ?", openedAt).
+		Where("payments.tip_amount > 0 AND payments.status <> ?", models.PaymentStatusVoided)
+}
+
+// tipCollected applies the collectedTipsQuery rule to a loaded payment
+func tipCollected(payment *models.Payment) bool {
+	return payment.TipAmount > 0 && payment.Status != models.PaymentStatusVoided
+}
+
 func (s *EmployeeService) generateCashRegisterReport(register *models.CashRegister) (*models.CashRegisterReport, error) {
 	report := &models.CashRegisterReport{
 		CashRegisterID:  register.ID,
@@ -944,7 +1052,7 @@ func (s *EmployeeService) CreateSession(employeeID uint, deviceInfo, ipAddress s
 		Token:      token,
 		DeviceInfo: deviceInfo,
 		IPAddress:  ipAddress,
-		ExpiresAt:  time.Now().Add(24 * time.Hour), // 24 hour expiration
+		ExpiresAt:  time.Now().Add(s.sessionDuration()),
 	}
 
 	if err := s.db.Create(session).Error; err != nil {
@@ -970,6 +1078,12 @@ func (s *EmployeeService) ValidateSession(token string) (*models.Employee, error
 		return nil, fmt.Errorf("invalid or expired session")
 	}
 
+	// Sessions of deactivated or deleted employees are no longer valid
+	if session.Employee == nil || !session.Employee.IsActive {
+		s.db.Delete(&session)
+		return nil, fmt.Errorf("invalid or expired session")
+	}
+
 	// Update session activity
 	session.UpdatedAt = time.Now()
 	s.db.Save(&session)
@@ -977,6 +1091,47 @@ func (s *EmployeeService) ValidateSession(token string) (*models.Employee, error
 	return session.Employee, nil
 }
 
+// RefreshSession replaces a valid session token with a new one and extends its expiration
+func (s *EmployeeService) RefreshSession(token, deviceInfo, ipAddress string) (*models.Session, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	employee, err := s.ValidateSession(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var session *models.Session
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Only one of concurrent refreshes of the same token gets a new session
+		result := tx.Where("token = ?", token).Delete(&models.Session{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return fmt.Errorf("invalid or expired session")
+		}
+		tokenBytes := make([]byte, 32)
+		if _, err := rand.Read(tokenBytes); err != nil {
+			return err
+		}
+		session = &models.Session{
+			EmployeeID: employee.ID,
+			Token:      hex.EncodeToString(tokenBytes),
+			DeviceInfo: deviceInfo,
+			IPAddress:  ipAddress,
+			ExpiresAt:  time.Now().Add(s.sessionDuration()),
+		}
+		return tx.Create(session).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	session.Employee = employee
+	return session, nil
+}
+
 // RevokeSession revokes a session
 func (s *EmployeeService) RevokeSession(token string) error {
 	if err := s.EnsureDB(); err != nil {
@@ -985,6 +1140,38 @@ func (s *EmployeeService) RevokeSession(token string) error {
 	return s.db.Where("token = ?", token).Delete(&models.Session{}).Error
 }
 
+// RevokeEmployeeSessions revokes all sessions of an employee
+func (s *EmployeeService) RevokeEmployeeSessions(employeeID uint) (int64, error) {
+	if err := s.EnsureDB(); err != nil {
+		return 0, err
+	}
+	result := s.db.Where("employee_id = ?", employeeID).Delete(&models.Session{})
+	return result.RowsAffected, result.Error
+}
+
+// GetActiveSessions gets the non-expired sessions, optionally for a single employee (0 = all)
+func (s *EmployeeService) GetActiveSessions(employeeID uint) ([]models.Session, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	var sessions []models.Session
+	query := s.db.Preload("Employee").Where("expires_at > ?", time.Now())
+	if employeeID > 0 {
+		query = query.Where("employee_id = ?", employeeID)
+	}
+	err := query.Order("updated_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// sessionDuration returns the configured session lifetime
+func (s *EmployeeService) sessionDuration() time.Duration {
+	hours := NewConfigService().GetSystemConfigInt("session_duration_hours", 24)
+	if hours <= 0 {
+		hours = 24
+	}
+	return time.Duration(hours) * time.Hour
+}
+
 // CleanExpiredSessions cleans up expired sessions
 func (s *EmployeeService) CleanExpiredSessions() error {
 	if err := s.EnsureDB(); err != nil {
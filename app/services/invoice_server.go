@@ -145,26 +145,44 @@ func (s *InvoiceService) SendInvoice(sale *models.Sale, sendEmailToCustomer bool
 		return nil, fmt.Errorf("electronic invoicing is disabled")
 	}
 
+	// During an outage invoices are issued with the contingency range and reported later
+	if config.ContingencyModeActive {
+		return s.issueContingencyInvoice(sale, sendEmailToCustomer)
+	}
+
 	// Prepare invoice data
 	invoiceData, err := s.prepareInvoiceData(sale, sendEmailToCustomer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare invoice data: %w", err)
 	}
 
-	// Marshal invoice data to JSON for storage (what we send to DIAN)
-	requestDataJSON, err := json.Marshal(invoiceData)
+	invoice, err := s.sendSaleDocument(sale, invoiceData, invoiceData.Number, invoiceData.Prefix, "invoice")
+	if s.canSwitchToContingency(err) {
+		return s.switchToContingency(sale, sendEmailToCustomer, invoice, err)
+	}
+	return invoice, err
+}
+
+// sendSaleDocument sends a sale document (invoice or POS equivalent document) to DIAN,
+// stores the ElectronicInvoice record and advances the matching consecutive
+func (s *InvoiceService) sendSaleDocument(sale *models.Sale, data interface{}, number int, prefix string, documentType string) (*models.ElectronicInvoice, error) {
+	documentName := "factura"
+	if documentType == "pos_equivalent" {
+		documentName = "documento equivalente POS"
+	}
+
+	// Marshal document data to JSON for storage (what we send to DIAN)
+	requestDataJSON, err := json.Marshal(data)
 	if err != nil {
 		fmt.Printf("Warning: Could not marshal invoice request data to JSON: %v\n", err)
 		requestDataJSON = []byte("{}")
 	}
 
 	// Send to DIAN API
-	response, err := s.sendToDIAN(invoiceData, "invoice")
+	response, err := s.sendToDIAN(data, documentType)
 
 	// Always create electronic invoice record, even on error
 	now := time.Now()
-	status := "error"
-	validationMessage := ""
 
 	// Create error response if sendToDIAN failed
 	if err != nil {
@@ -172,7 +190,7 @@ func (s *InvoiceService) SendInvoice(sale *models.Sale, sendEmailToCustomer bool
 		errorResponse := map[string]interface{}{
 			"success": false,
 			"error":   err.Error(),
-			"message": "Error al enviar factura a DIAN",
+			"message": fmt.Sprintf("Error al enviar %s a DIAN", documentName),
 		}
 
 		// Convert error to JSON
@@ -181,9 +199,10 @@ func (s *InvoiceService) SendInvoice(sale *models.Sale, sendEmailToCustomer bool
 		// Create electronic invoice record with error
 		electronicInvoice := &models.ElectronicInvoice{
 			SaleID:            sale.ID,
-			InvoiceNumber:     strconv.Itoa(invoiceData.Number),
-			Prefix:            invoiceData.Prefix,
-			Status:            status,
+			DocumentType:      documentType,
+			InvoiceNumber:     strconv.Itoa(number),
+			Prefix:            prefix,
+			Status:            "error",
 			ValidationMessage: fmt.Sprintf("Error: %s", err.Error()),
 			DIANResponse:      string(responseJSON),
 			RequestData:       string(requestDataJSON),
@@ -198,59 +217,12 @@ func (s *InvoiceService) SendInvoice(sale *models.Sale, sendEmailToCustomer bool
 		}
 
 		// Queue disabled (QueuedInvoice model removed)
-		// s.queueInvoice(sale.ID, invoiceData, "invoice", err.Error())
+		// s.queueInvoice(sale.ID, data, documentType, err.Error())
 		return electronicInvoice, fmt.Errorf("failed to send invoice: %w", err)
 	}
 
-	// Extract fields safely from response
-	var uuid, cufe, qrCode, zipKey string
-	if val, ok := response["uuid"].(string); ok {
-		uuid = val
-	}
-	if val, ok := response["cufe"].(string); ok {
-		cufe = val
-	}
-	if val, ok := response["qr_code"].(string); ok {
-		qrCode = val
-	}
-	if val, ok := response["zip_key"].(string); ok {
-		zipKey = val
-	}
-
-	// Determine initial status and validation (now already declared above)
-	status = "sent"
-	var isValid *bool
-	validationMessage = ""
-
-	// Check if there's a zipKey (means test_set_id was used - async validation)
-	hasZipKey := zipKey != ""
-
-	// Check if sync validation result is present (no test_set_id used)
-	if isValidStr, ok := response["is_valid"].(string); ok {
-		isValidBool := isValidStr == "true"
-		isValid = &isValidBool
-
-		if isValidBool {
-			status = "accepted"
-			validationMessage = "Validado exitosamente por DIAN (síncrono)"
-		} else {
-			status = "rejected"
-			// Build error message from status and error messages
-			statusCode, _ := response["status_code"].(string)
-			statusDesc, _ := response["status_description"].(string)
-			validationMessage = fmt.Sprintf("Código: %s - %s", statusCode, statusDesc)
-
-			// Add detailed error messages if present
-			if errorMsgs, ok := response["error_messages"].([]string); ok && len(errorMsgs) > 0 {
-				validationMessage += "\nErrores:\n- " + strings.Join(errorMsgs, "\n- ")
-			}
-		}
-	} else if hasZipKey {
-		// test_set_id was used - validation is asynchronous via zipkey
-		status = "validating"
-		validationMessage = "Pendiente de validación DIAN (verificando con zipkey...)"
-		fmt.Printf("📋 Invoice sent with test_set_id - Status: validating, ZipKey: %s\n", zipKey)
-	}
+	// Extract document key and validation status from response
+	result := parseDocumentResponse(response)
 
 	// Convert full response to JSON string for storage
 	responseJSON, err := json.Marshal(response)
@@ -262,15 +234,16 @@ func (s *InvoiceService) SendInvoice(sale *models.Sale, sendEmailToCustomer bool
 	// Create electronic invoice record
 	electronicInvoice := &models.ElectronicInvoice{
 		SaleID:              sale.ID,
-		InvoiceNumber:       strconv.Itoa(invoiceData.Number),
-		Prefix:              invoiceData.Prefix,
-		UUID:                &uuid,
-		CUFE:                cufe,
-		QRCode:              qrCode,
-		ZipKey:              zipKey,
-		Status:              status,
-		IsValid:             isValid,
-		ValidationMessage:   validationMessage,
+		DocumentType:        documentType,
+		InvoiceNumber:       strconv.Itoa(number),
+		Prefix:              prefix,
+		UUID:                &result.UUID,
+		CUFE:                result.Key,
+		QRCode:              result.QRCode,
+		ZipKey:              result.ZipKey,
+		Status:              result.Status,
+		IsValid:             result.IsValid,
+		ValidationMessage:   result.ValidationMessage,
 		DIANResponse:        string(responseJSON),
 		RequestData:         string(requestDataJSON),
 		SentAt:              &now,
@@ -278,7 +251,7 @@ func (s *InvoiceService) SendInvoice(sale *models.Sale, sendEmailToCustomer bool
 	}
 
 	// If validated synchronously and accepted, set AcceptedAt
-	if isValid != nil && *isValid {
+	if result.IsValid != nil && *result.IsValid {
 		electronicInvoice.AcceptedAt = &now
 	}
 
@@ -286,20 +259,87 @@ func (s *InvoiceService) SendInvoice(sale *models.Sale, sendEmailToCustomer bool
 		return nil, fmt.Errorf("failed to save electronic invoice: %w", err)
 	}
 
-	// Update invoice counter
-	config.LastInvoiceNumber++
-	s.db.Save(&config)
+	// Update the invoice consecutive (POS numbers are reserved before sending)
+	// Only the counter column is written so a stale config can't revert the contingency mode flag
+	if documentType != "pos_equivalent" {
+		s.config.LastInvoiceNumber++
+		s.db.Model(s.config).UpdateColumn("last_invoice_number", s.config.LastInvoiceNumber)
+	}
 
 	// If zipkey was returned, start validation worker
-	if hasZipKey {
-		go s.validateZipKeyAsync(electronicInvoice.ID, zipKey)
+	if result.ZipKey != "" {
+		go s.validateZipKeyAsync(&models.ElectronicInvoice{}, electronicInvoice.ID, result.ZipKey)
 	}
 
 	return electronicInvoice, nil
 }
 
-// validateZipKeyAsync validates invoice with zipkey asynchronously
-func (s *InvoiceService) validateZipKeyAsync(invoiceID uint, zipKey string) {
+// documentResult holds the fields extracted from a DIAN document response
+type documentResult struct {
+	UUID              string
+	Key               string // CUFE, CUDE or CUDS depending on the document
+	QRCode            string
+	ZipKey            string
+	Status            string // "sent", "accepted", "rejected", "validating"
+	IsValid           *bool
+	ValidationMessage string
+}
+
+// parseDocumentResponse extracts the document key and the validation status from a sendToDIAN response
+func parseDocumentResponse(response map[string]interface{}) documentResult {
+	result := documentResult{Status: "sent"}
+
+	// Extract fields safely from response
+	if val, ok := response["uuid"].(string); ok {
+		result.UUID = val
+	}
+	// Invoices are identified by a CUFE, equivalent documents by a CUDE and support documents by a CUDS
+	for _, key := range []string{"cufe", "cude", "cuds"} {
+		if val, ok := response[key].(string); ok && val != "" {
+			result.Key = val
+			break
+		}
+	}
+	if val, ok := response["qr_code"].(string); ok {
+		result.QRCode = val
+	}
+	if val, ok := response["zip_key"].(string); ok {
+		result.ZipKey = val
+	}
+
+	// Check if sync validation result is present (no test_set_id used)
+	if isValidStr, ok := response["is_valid"].(string); ok {
+		isValidBool := isValidStr == "true"
+		result.IsValid = &isValidBool
+
+		if isValidBool {
+			result.Status = "accepted"
+			result.ValidationMessage = "Validado exitosamente por DIAN (síncrono)"
+		} else {
+			result.Status = "rejected"
+			// Build error message from status and error messages
+			statusCode, _ := response["status_code"].(string)
+			statusDesc, _ := response["status_description"].(string)
+			result.ValidationMessage = fmt.Sprintf("Código: %s - %s", statusCode, statusDesc)
+
+			// Add detailed error messages if present
+			if errorMsgs, ok := response["error_messages"].([]string); ok && len(errorMsgs) > 0 {
+				result.ValidationMessage += "\nErrores:\n- " + strings.Join(errorMsgs, "\n- ")
+			}
+		}
+	} else if result.ZipKey != "" {
+		// test_set_id was used - validation is asynchronous via zipkey
+		result.Status = "validating"
+		result.ValidationMessage = "Pendiente de validación DIAN (verificando con zipkey...)"
+		fmt.Printf("📋 Document sent with test_set_id - Status: validating, ZipKey: %s\n", result.ZipKey)
+	}
+
+	return result
+}
+
+// validateZipKeyAsync validates a document with zipkey asynchronously
+// model is the document table to update (ElectronicInvoice or SupportDocument)
+func (s *InvoiceService) validateZipKeyAsync(model interface{}, invoiceID uint, zipKey string) {
 	maxRetries := 20 // Try for up to 20 times (20 * 3 seconds = 60 seconds)
 	retryInterval := 3 * time.Second
 
@@ -342,8 +382,10 @@ func (s *InvoiceService) validateZipKeyAsync(invoiceID uint, zipKey string) {
 			fmt.Printf("❌ Invoice rejected by DIAN: %s\n", validationMessage)
 		}
 
-		if err := s.db.Model(&models.ElectronicInvoice{}).Where("id = ?", invoiceID).Updates(updateData).Error; err != nil {
+		if err := s.db.Model(model).Where("id = ?", invoiceID).Updates(updateData).Error; err != nil {
 			fmt.Printf("Error updating invoice status: %v\n", err)
+		} else if _, ok := model.(*models.ElectronicInvoice); ok && isValid {
+			queueAcceptedInvoiceMail(s.db, invoiceID)
 		}
 
 		return // Successfully validated, exit
@@ -351,7 +393,7 @@ func (s *InvoiceService) validateZipKeyAsync(invoiceID uint, zipKey string) {
 
 	// Max retries reached without validation
 	fmt.Printf("⚠️  Max retries reached for zipkey validation, invoice remains in validating status\n")
-	s.db.Model(&models.ElectronicInvoice{}).Where("id = ?", invoiceID).Updates(map[string]interface{}{
+	s.db.Model(model).Where("id = ?", invoiceID).Updates(map[string]interface{}{
 		"validation_message": "Timeout esperando validación DIAN - Verifique manualmente",
 	})
 }
@@ -365,7 +407,7 @@ func (s *InvoiceService) checkZipKeyStatus(zipKey string) (validated bool, isVal
 	}
 
 	// Call status endpoint
-	url := fmt.Sprintf("%s/api/ubl2.1/status/zip/%s", config.APIURL, zipKey)
+	url := fmt.Sprintf("%s/api/ubl2.1/status/zip/%s", dianAPIURL(&config), zipKey)
 
 	// Prepare request body
 	requestData := map[string]interface{}{
@@ -576,6 +618,9 @@ func (s *InvoiceService) prepareInvoiceData(sale *models.Sale, sendEmailToCustom
 		})
 	}
 
+	// Voluntary tips (sale.Tip) are intentionally left out: they are not part of
+	// sale.Total and are not taxable income of the business
+
 	return invoice, nil
 }
 
@@ -706,9 +751,15 @@ func (s *InvoiceService) sendToDIAN(data interface{}, documentType string) (map[
 		endpoint = "credit-note"
 	} else if documentType == "debit_note" {
 		endpoint = "debit-note"
+	} else if documentType == "pos_equivalent" {
+		endpoint = "eqdoc"
+	} else if documentType == "support_document" {
+		endpoint = "support-document"
+	} else if documentType == "support_document_adjustment" {
+		endpoint = "sd-credit-note"
 	}
 
-	url := fmt.Sprintf("%s/api/ubl2.1/%s", s.config.APIURL, endpoint)
+	url := fmt.Sprintf("%s/api/ubl2.1/%s", dianAPIURL(s.config), endpoint)
 	// Debug: Log the config values for test_set_id
 	fmt.Printf("🔧 DIAN Config Debug - Environment: %s, UseTestSetID: %v, TestSetID: %s\n",
 		s.config.Environment, s.config.UseTestSetID, s.config.TestSetID)
@@ -738,15 +789,18 @@ func (s *InvoiceService) sendToDIAN(data interface{}, documentType string) (map[
 
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", errDIANUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", errDIANUnavailable, err)
 	}
 
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: DIAN API error (status %d): %s", errDIANUnavailable, resp.StatusCode, string(body))
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("DIAN API error: %s", string(body))
 	}
@@ -1147,7 +1201,7 @@ func (s *InvoiceService) SendCreditNote(electronicInvoice *models.ElectronicInvo
 
 	// Update credit note counter
 	config.LastCreditNoteNumber++
-	s.db.Save(&config)
+	s.db.Model(&config).UpdateColumn("last_credit_note_number", config.LastCreditNoteNumber)
 
 	return creditNote, nil
 }
@@ -1199,7 +1253,7 @@ func (s *InvoiceService) SendDebitNote(electronicInvoice *models.ElectronicInvoi
 
 	// Update debit note counter
 	config.LastDebitNoteNumber++
-	s.db.Save(&config)
+	s.db.Model(&config).UpdateColumn("last_debit_note_number", config.LastDebitNoteNumber)
 
 	return debitNote, nil
 }
@@ -1552,7 +1606,7 @@ func (s *InvoiceService) ConfigureCompany() (*CompanyConfigResponse, error) {
 		return nil, fmt.Errorf("NIT and DV are required")
 	}
 
-	if config.APIURL == "" {
+	if dianAPIURL(&config) == "" {
 		return nil, fmt.Errorf("API URL is required")
 	}
 
@@ -1577,7 +1631,7 @@ func (s *InvoiceService) ConfigureCompany() (*CompanyConfigResponse, error) {
 
 	// Build URL: {api_url}/api/ubl2.1/config/{nit}/{dv}
 	url := fmt.Sprintf("%s/api/ubl2.1/config/%s/%s",
-		config.APIURL,
+		dianAPIURL(&config),
 		config.IdentificationNumber,
 		config.DV)
 
@@ -1653,7 +1707,7 @@ func (s *InvoiceService) CheckInvoiceStatus(invoiceID uint) error {
 	}
 
 	// Build URL
-	url := fmt.Sprintf("%s/api/ubl2.1/status/zip/%s", config.APIURL, invoice.ZipKey)
+	url := fmt.Sprintf("%s/api/ubl2.1/status/zip/%s", dianAPIURL(&config), invoice.ZipKey)
 
 	// Prepare request body
 	requestData := map[string]interface{}{
@@ -1748,9 +1802,15 @@ func (s *InvoiceService) CheckInvoiceStatus(invoiceID uint) error {
 		invoice.ValidationMessage = fmt.Sprintf("En validación - Código: %s - %s", statusCode, statusDescription)
 	}
 
-	if err := s.db.Save(&invoice).Error; err != nil {
+	// Only the validation columns are written so a concurrent email_pending mark is kept
+	if err := s.db.Model(&invoice).
+		Select("status", "is_valid", "accepted_at", "validation_message", "validation_checked_at").
+		Updates(&invoice).Error; err != nil {
 		return fmt.Errorf("failed to update invoice: %w", err)
 	}
+	if invoice.Status == "accepted" {
+		queueAcceptedInvoiceMail(s.db, invoice.ID)
+	}
 
 	fmt.Printf("Invoice %d validation status: IsValid=%v, Status=%s, Message=%s\n",
 		invoice.ID, invoice.IsValid, invoice.Status, invoice.ValidationMessage)
@@ -0,0 +1,1002 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"PosApp/app/models"
+
+	"gorm.io/gorm"
+)
+
+// mailRetryDelays are the waits before each retry of a failed delivery
+var mailRetryDelays = []time.Duration{
+	1 * time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	1 * time.Hour,
+	3 * time.Hour,
+}
+
+const (
+	mailQueueCheckPeriod  = 30 * time.Second // How often the worker looks for due messages
+	mailAlertCheckPeriod  = 5 * time.Minute  // How often reports and alerts are checked
+	mailRangeExpiryDays   = 30               // Alert when a numbering resolution expires within these days
+	mailQueueBatchSize    = 20
+	mailMessagesPageLimit = 200
+)
+
+// mailQueueWake wakes the queue worker when a message is queued
+var mailQueueWake = make(chan struct{}, 1)
+
+// mailDeliveryMu serializes deliveries so a message is never sent twice
+// by the worker and an immediate send
+var mailDeliveryMu sync.Mutex
+
+// MailService sends email through the SMTP server of the DIAN configuration:
+// invoices to customers, closing reports and stock/numbering alerts to owners.
+// Messages are queued in the database and retried with backoff; every attempt
+// is logged in MailDeliveryAttempt
+type MailService struct {
+	*BaseService
+	mu             sync.Mutex
+	stopChan       chan struct{}
+	running        bool
+	lastAlertCheck time.Time
+}
+
+// MailSettings are the email preferences (stored as system configs).
+// The SMTP server itself is part of the DIAN configuration
+type MailSettings struct {
+	OwnerEmails             string `json:"owner_emails"`               // Recipients of reports and alerts (comma separated, empty = restaurant email)
+	SendInvoices            bool   `json:"send_invoices"`              // Email invoices to customers from our SMTP server instead of the DIAN provider
+	SendDailyClosingReport  bool   `json:"send_daily_closing_report"`  // Email the DIAN closing report of the previous day
+	DailyReportTime         string `json:"daily_report_time"`          // "HH:MM" when the daily closing report is sent
+	SendCashRegisterReports bool   `json:"send_cash_register_reports"` // Email every cash register closing
+	SendLowStockAlerts      bool   `json:"send_low_stock_alerts"`
+	SendInvoiceRangeAlerts  bool   `json:"send_invoice_range_alerts"` // Numbering ranges close to their end or expiration
+	MaxAttempts             int    `json:"max_attempts"`              // Delivery attempts before a message is marked as failed
+	UseLocalSMTP            bool   `json:"use_local_smtp"`            // Deliver to the built-in test SMTP server instead of the real one
+}
+
+// LocalSMTPInbox is the content of the built-in test SMTP server
+type LocalSMTPInbox struct {
+	Running  bool           `json:"running"`
+	Address  string         `json:"address"`
+	Messages []CapturedMail `json:"messages"`
+}
+
+// mailRequest is a message to queue
+type mailRequest struct {
+	Kind          string
+	To            []string
+	Subject       string
+	HTMLBody      string
+	Attachments   []mailFile
+	ReferenceType string
+	ReferenceID   uint
+}
+
+var mailTimePattern = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+// NewMailService creates a new mail service
+func NewMailService() *MailService {
+	return &MailService{
+		BaseService: NewBaseService(),
+	}
+}
+
+// GetMailSettings returns the email preferences
+func (s *MailService) GetMailSettings() MailSettings {
+	configSvc := NewConfigService()
+	owners, _ := configSvc.GetSystemConfig("mail_owner_emails")
+	reportTime, _ := configSvc.GetSystemConfig("mail_daily_report_time")
+	if reportTime == "" {
+		reportTime = "06:00"
+	}
+	return MailSettings{
+		OwnerEmails:             owners,
+		SendInvoices:            configSvc.GetSystemConfigBool("mail_send_invoices", false),
+		SendDailyClosingReport:  configSvc.GetSystemConfigBool("mail_send_daily_closing_report", false),
+		DailyReportTime:         reportTime,
+		SendCashRegisterReports: configSvc.GetSystemConfigBool("mail_send_cash_register_reports", false),
+		SendLowStockAlerts:      configSvc.GetSystemConfigBool("mail_send_low_stock_alerts", false),
+		SendInvoiceRangeAlerts:  configSvc.GetSystemConfigBool("mail_send_invoice_range_alerts", false),
+		MaxAttempts:             configSvc.GetSystemConfigInt("mail_max_attempts", 5),
+		UseLocalSMTP:            configSvc.GetSystemConfigBool("mail_use_local_smtp", false),
+	}
+}
+
+// SaveMailSettings saves the email preferences
+func (s *MailService) SaveMailSettings(settings MailSettings) error {
+	if settings.MaxAttempts < 1 || settings.MaxAttempts > 20 {
+		return fmt.Errorf("el número de intentos debe estar entre 1 y 20")
+	}
+	if !mailTimePattern.MatchString(settings.DailyReportTime) {
+		return fmt.Errorf("hora de envío inválida: %s (use HH:MM)", settings.DailyReportTime)
+	}
+	for _, address := range strings.FieldsFunc(settings.OwnerEmails, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		if address = strings.TrimSpace(address); address != "" && !strings.Contains(address, "@") {
+			return fmt.Errorf("correo electrónico inválido: %s", address)
+		}
+	}
+
+	configSvc := NewConfigService()
+	values := []struct {
+		key, value, configType string
+	}{
+		{"mail_owner_emails", strings.Join(splitMailRecipients(settings.OwnerEmails), ", "), "string"},
+		{"mail_send_invoices", strconv.FormatBool(settings.SendInvoices), "boolean"},
+		{"mail_send_daily_closing_report", strconv.FormatBool(settings.SendDailyClosingReport), "boolean"},
+		{"mail_daily_report_time", settings.DailyReportTime, "string"},
+		{"mail_send_cash_register_reports", strconv.FormatBool(settings.SendCashRegisterReports), "boolean"},
+		{"mail_send_low_stock_alerts", strconv.FormatBool(settings.SendLowStockAlerts), "boolean"},
+		{"mail_send_invoice_range_alerts", strconv.FormatBool(settings.SendInvoiceRangeAlerts), "boolean"},
+		{"mail_max_attempts", strconv.Itoa(settings.MaxAttempts), "number"},
+		{"mail_use_local_smtp", strconv.FormatBool(settings.UseLocalSMTP), "boolean"},
+	}
+	for _, v := range values {
+		if err := configSvc.SetSystemConfig(v.key, v.value, v.configType, "mail"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ==================== QUEUE ====================
+
+// queueMail stores a message in the outgoing queue and wakes the worker
+func queueMail(db *gorm.DB, request mailRequest) (*models.MailMessage, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if len(request.To) == 0 {
+		return nil, fmt.Errorf("el correo no tiene destinatarios")
+	}
+
+	now := time.Now()
+	message := &models.MailMessage{
+		Kind:          request.Kind,
+		To:            strings.Join(request.To, ", "),
+		Subject:       request.Subject,
+		HTMLBody:      request.HTMLBody,
+		Status:        models.MailStatusPending,
+		MaxAttempts:   NewConfigService().GetSystemConfigInt("mail_max_attempts", 5),
+		NextAttemptAt: &now,
+		ReferenceType: request.ReferenceType,
+		ReferenceID:   request.ReferenceID,
+	}
+	for _, file := range request.Attachments {
+		message.Attachments = append(message.Attachments, models.MailAttachment{
+			FileName:    file.FileName,
+			ContentType: file.ContentType,
+			Data:        base64.StdEncoding.EncodeToString(file.Data),
+			Size:        len(file.Data),
+		})
+	}
+
+	if err := db.Create(message).Error; err != nil {
+		return nil, fmt.Errorf("failed to queue mail: %w", err)
+	}
+
+	select {
+	case mailQueueWake <- struct{}{}:
+	default:
+	}
+	return message, nil
+}
+
+// deliverQueuedMail makes one delivery attempt of a queued message, logs it and
+// schedules the next retry when it fails. Messages already sent or cancelled are skipped
+func deliverQueuedMail(db *gorm.DB, messageID uint) error {
+	mailDeliveryMu.Lock()
+	defer mailDeliveryMu.Unlock()
+
+	var message models.MailMessage
+	if err := db.Preload("Attachments").First(&message, messageID).Error; err != nil {
+		return fmt.Errorf("correo no encontrado")
+	}
+	if message.Status != models.MailStatusPending && message.Status != models.MailStatusRetrying {
+		return nil
+	}
+
+	attempt := models.MailDeliveryAttempt{
+		MailMessageID: message.ID,
+		Attempt:       message.Attempts + 1,
+	}
+	start := time.Now()
+
+	transport, err := mailTransportFromConfig(db)
+	if err == nil {
+		attempt.Server = transport.Addr()
+		mail := &outgoingMail{
+			To:       splitMailRecipients(message.To),
+			Subject:  message.Subject,
+			HTMLBody: message.HTMLBody,
+		}
+		for _, attachment := range message.Attachments {
+			data, decodeErr := base64.StdEncoding.DecodeString(attachment.Data)
+			if decodeErr != nil {
+				err = fmt.Errorf("adjunto %s dañado: %w", attachment.FileName, decodeErr)
+				break
+			}
+			mail.Attachments = append(mail.Attachments, mailFile{
+				FileName:    attachment.FileName,
+				ContentType: attachment.ContentType,
+				Data:        data,
+			})
+		}
+		if err == nil {
+			err = deliverMail(transport, mail)
+		}
+	}
+
+	now := time.Now()
+	attempt.DurationMs = now.Sub(start).Milliseconds()
+	attempt.Success = err == nil
+	updates := map[string]interface{}{"attempts": attempt.Attempt}
+
+	if err == nil {
+		updates["status"] = models.MailStatusSent
+		updates["sent_at"] = now
+		updates["next_attempt_at"] = nil
+		updates["last_error"] = ""
+	} else {
+		attempt.Error = err.Error()
+		updates["last_error"] = err.Error()
+		if attempt.Attempt >= message.MaxAttempts {
+			updates["status"] = models.MailStatusFailed
+			updates["next_attempt_at"] = nil
+		} else {
+			delay := mailRetryDelays[len(mailRetryDelays)-1]
+			if attempt.Attempt-1 < len(mailRetryDelays) {
+				delay = mailRetryDelays[attempt.Attempt-1]
+			}
+			updates["status"] = models.MailStatusRetrying
+			updates["next_attempt_at"] = now.Add(delay)
+		}
+	}
+
+	if logErr := db.Create(&attempt).Error; logErr != nil {
+		log.Printf("Warning: Failed to log delivery attempt of mail %d: %v", message.ID, logErr)
+	}
+	if updateErr := db.Model(&models.MailMessage{}).Where("id = ?", message.ID).Updates(updates).Error; updateErr != nil {
+		log.Printf("Warning: Failed to update mail %d: %v", message.ID, updateErr)
+	}
+
+	if err != nil {
+		log.Printf("Mail: delivery of %d (%s) failed, attempt %d/%d: %v", message.ID, message.Kind, attempt.Attempt, message.MaxAttempts, err)
+		return err
+	}
+	log.Printf("Mail: %d (%s) sent to %s", message.ID, message.Kind, message.To)
+	return nil
+}
+
+// processQueue delivers the messages whose next attempt is due
+func (s *MailService) processQueue() {
+	var ids []uint
+	err := s.db.Model(&models.MailMessage{}).
+		Where("status IN ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)",
+			[]string{models.MailStatusPending, models.MailStatusRetrying}, time.Now()).
+		Order("id").
+		Limit(mailQueueBatchSize).
+		Pluck("id", &ids).Error
+	if err != nil {
+		log.Printf("Mail: could not read queue: %v", err)
+		return
+	}
+	for _, id := range ids {
+		deliverQueuedMail(s.db, id)
+	}
+}
+
+// Start begins the mail queue worker
+func (s *MailService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+	go s.run(s.stopChan)
+	log.Println("Mail queue worker started")
+}
+
+// Stop stops the mail queue worker
+func (s *MailService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return
+	}
+	close(s.stopChan)
+	s.running = false
+	log.Println("Mail queue worker stopped")
+}
+
+// run delivers due messages when woken by a new message or periodically,
+// and checks the scheduled reports and alerts
+func (s *MailService) run(stop chan struct{}) {
+	// Initial delay so pending mail does not compete with startup
+	select {
+	case <-time.After(20 * time.Second):
+	case <-stop:
+		return
+	}
+
+	ticker := time.NewTicker(mailQueueCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		if s.EnsureDB() == nil {
+			s.processQueue()
+			if time.Since(s.lastAlertCheck) >= mailAlertCheckPeriod {
+				s.lastAlertCheck = time.Now()
+				s.runScheduledChecks()
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-mailQueueWake:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// ==================== MESSAGES ====================
+
+// GetMailMessages returns the queued and sent messages, newest first
+// status filters by status ("" = all)
+func (s *MailService) GetMailMessages(status string, limit int, offset int) ([]models.MailMessage, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > mailMessagesPageLimit {
+		limit = mailMessagesPageLimit
+	}
+
+	query := s.db.Omit("html_body").
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "mail_message_id", "file_name", "content_type", "size", "created_at")
+		})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var messages []models.MailMessage
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// GetMailMessage returns a message with its attachments and delivery log
+func (s *MailService) GetMailMessage(id uint) (*models.MailMessage, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var message models.MailMessage
+	err := s.db.Preload("Attachments", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "mail_message_id", "file_name", "content_type", "size", "created_at")
+	}).Preload("DeliveryLog", func(db *gorm.DB) *gorm.DB {
+		return db.Order("attempt")
+	}).First(&message, id).Error
+	if err != nil {
+		return nil, fmt.Errorf("correo no encontrado")
+	}
+	return &message, nil
+}
+
+// RetryMailMessage schedules one more delivery attempt of a failed, retrying or cancelled message
+func (s *MailService) RetryMailMessage(id uint) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+
+	var message models.MailMessage
+	if err := s.db.First(&message, id).Error; err != nil {
+		return fmt.Errorf("correo no encontrado")
+	}
+	if message.Status == models.MailStatusSent {
+		return fmt.Errorf("el correo ya fue enviado")
+	}
+
+	maxAttempts := message.MaxAttempts
+	if message.Attempts >= maxAttempts {
+		maxAttempts = message.Attempts + 1
+	}
+	err := s.db.Model(&message).Updates(map[string]interface{}{
+		"status":          models.MailStatusPending,
+		"max_attempts":    maxAttempts,
+		"next_attempt_at": time.Now(),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	select {
+	case mailQueueWake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// CancelMailMessage cancels a message that has not been sent yet
+func (s *MailService) CancelMailMessage(id uint) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.MailMessage{}).
+		Where("id = ? AND status IN ?", id, []string{models.MailStatusPending, models.MailStatusRetrying}).
+		Updates(map[string]interface{}{"status": models.MailStatusCancelled, "next_attempt_at": nil})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("solo se pueden cancelar correos pendientes")
+	}
+	return nil
+}
+
+// ==================== SENDING ====================
+
+// SendTestEmail sends a test email right away and returns the delivery error, if any
+func (s *MailService) SendTestEmail(to string) (*models.MailMessage, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	recipients := splitMailRecipients(to)
+	if len(recipients) == 0 {
+		recipients = mailOwnerRecipients(s.db)
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("indique un correo de destino")
+	}
+
+	server := ""
+	if transport, err := mailTransportFromConfig(s.db); err == nil {
+		server = transport.Addr()
+	}
+	restaurant := mailRestaurant(s.db)
+	body, err := renderMailTemplate("test", "Correo de prueba", restaurant, mailTestData{Server: server, SentAt: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := queueMail(s.db, mailRequest{
+		Kind:     models.MailKindTest,
+		To:       recipients,
+		Subject:  "Correo de prueba - " + restaurant.Name,
+		HTMLBody: body,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := deliverQueuedMail(s.db, message.ID); err != nil {
+		return nil, fmt.Errorf("no se pudo enviar el correo de prueba: %w", err)
+	}
+	return s.GetMailMessage(message.ID)
+}
+
+// SendInvoiceEmail queues the invoice of a sale with its PDF and XML
+// email empty sends it to the customer's email
+func (s *MailService) SendInvoiceEmail(saleID uint, email string) (*models.MailMessage, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	return queueInvoiceMail(s.db, saleID, email)
+}
+
+// SendDIANClosingReportEmail queues the DIAN closing report of a period to the owners
+func (s *MailService) SendDIANClosingReportEmail(dateStr string, period string) (*models.MailMessage, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	report, err := NewSalesService().GetDIANClosingReportWithPeriod(dateStr, period)
+	if err != nil {
+		return nil, err
+	}
+	return queueDIANClosingReportMail(s.db, report, period)
+}
+
+// SendDIANClosingReportCustomRangeEmail queues the DIAN closing report of a custom date range to the owners
+func (s *MailService) SendDIANClosingReportCustomRangeEmail(startDateStr string, endDateStr string) (*models.MailMessage, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	report, err := NewSalesService().GetDIANClosingReportCustomRange(startDateStr, endDateStr)
+	if err != nil {
+		return nil, err
+	}
+	return queueDIANClosingReportMail(s.db, report, "custom")
+}
+
+// SendCashRegisterReportEmail queues a cash register closing report to the owners
+func (s *MailService) SendCashRegisterReportEmail(reportID uint) (*models.MailMessage, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var report models.CashRegisterReport
+	if err := s.db.Preload("Employee").Preload("BoldReconciliation").First(&report, reportID).Error; err != nil {
+		return nil, fmt.Errorf("reporte de caja no encontrado")
+	}
+	return queueCashRegisterReportMail(s.db, &report)
+}
+
+// queueInvoiceMail queues the email of an electronic invoice / POS document with its PDF and signed XML
+func queueInvoiceMail(db *gorm.DB, saleID uint, email string) (*models.MailMessage, error) {
+	pdf, sale, err := NewPDFService().RenderInvoicePDF(saleID)
+	if err != nil {
+		return nil, err
+	}
+	invoice := sale.ElectronicInvoice
+	if invoice.Status != "accepted" || invoice.CUFE == "" {
+		return nil, fmt.Errorf("la factura %s%s no ha sido aceptada por la DIAN", invoice.Prefix, invoice.InvoiceNumber)
+	}
+
+	recipients := splitMailRecipients(email)
+	if len(recipients) == 0 && sale.Customer != nil {
+		recipients = splitMailRecipients(sale.Customer.Email)
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("el cliente no tiene correo electrónico")
+	}
+
+	documentLabel, documentCode := "Factura electrónica de venta", "01"
+	if invoice.DocumentType == "pos_equivalent" {
+		documentLabel, documentCode = "Documento equivalente electrónico POS", "20"
+	} else if invoice.IsContingency {
+		documentLabel, documentCode = "Factura de contingencia", "03"
+	}
+	number := invoice.Prefix + invoice.InvoiceNumber
+
+	pdfName := invoicePDFFileName(invoice)
+	attachments := []mailFile{{FileName: pdfName, ContentType: "application/pdf", Data: pdf}}
+	if xml := invoiceXMLDocument(invoice); xml != nil {
+		attachments = append(attachments, mailFile{
+			FileName:    strings.TrimSuffix(pdfName, ".pdf") + ".xml",
+			ContentType: "application/xml",
+			Data:        xml,
+		})
+	}
+
+	restaurant := mailRestaurant(db)
+	data := mailInvoiceData{
+		DocumentLabel:  documentLabel,
+		DocumentNumber: number,
+		Date:           invoice.CreatedAt,
+		Total:          sale.Total,
+		CUFE:           invoice.CUFE,
+	}
+	if sale.Customer != nil && sale.Customer.IdentificationNumber != "222222222222" {
+		data.CustomerName = sale.Customer.Name
+	}
+	for _, file := range attachments {
+		data.Attachments = append(data.Attachments, file.FileName)
+	}
+	body, err := renderMailTemplate("invoice", documentLabel+" "+number, restaurant, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subject in the format required by DIAN: NIT;Issuer;Number;Document code;Commercial name
+	businessName := restaurant.BusinessName
+	if businessName == "" {
+		businessName = restaurant.Name
+	}
+	subject := fmt.Sprintf("%s;%s;%s;%s;%s", restaurant.IdentificationNumber, businessName, number, documentCode, restaurant.Name)
+
+	return queueMail(db, mailRequest{
+		Kind:          models.MailKindInvoice,
+		To:            recipients,
+		Subject:       subject,
+		HTMLBody:      body,
+		Attachments:   attachments,
+		ReferenceType: "sale",
+		ReferenceID:   sale.ID,
+	})
+}
+
+// markInvoiceEmailPending marks an invoice to be emailed from our SMTP server and queues it if DIAN already accepted it
+func markInvoiceEmailPending(db *gorm.DB, invoiceID uint) {
+	db.Model(&models.ElectronicInvoice{}).Where("id = ?", invoiceID).UpdateColumn("email_pending", true)
+	queueAcceptedInvoiceMail(db, invoiceID)
+}
+
+// queueAcceptedInvoiceMail queues the email of an invoice marked as email pending once DIAN accepted it
+// Both the sale flow (after marking it) and the validation paths (after accepting it) call it;
+// the update claims the email so it is queued exactly once
+func queueAcceptedInvoiceMail(db *gorm.DB, invoiceID uint) {
+	claim := db.Model(&models.ElectronicInvoice{}).
+		Where("id = ? AND email_pending = ? AND status = ?", invoiceID, true, "accepted").
+		UpdateColumn("email_pending", false)
+	if claim.Error != nil || claim.RowsAffected == 0 {
+		return
+	}
+
+	var invoice models.ElectronicInvoice
+	if err := db.First(&invoice, invoiceID).Error; err != nil {
+		return
+	}
+	if _, err := queueInvoiceMail(db, invoice.SaleID, ""); err != nil {
+		log.Printf("Failed to queue email of invoice %s%s: %v", invoice.Prefix, invoice.InvoiceNumber, err)
+	}
+}
+
+// invoiceXMLDocument returns the signed XML of an invoice: the AttachedDocument returned
+// by the DIAN provider when available, otherwise the invoice XML
+func invoiceXMLDocument(invoice *models.ElectronicInvoice) []byte {
+	var response map[string]interface{}
+	if invoice.DIANResponse != "" && json.Unmarshal([]byte(invoice.DIANResponse), &response) == nil {
+		for _, key := range []string{"attacheddocument", "invoicexml"} {
+			encoded, ok := response[key].(string)
+			if !ok || encoded == "" {
+				continue
+			}
+			if data, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+				return data
+			}
+		}
+	}
+	if strings.HasPrefix(strings.TrimSpace(invoice.XMLDocument), "<") {
+		return []byte(invoice.XMLDocument)
+	}
+	return nil
+}
+
+// queueDIANClosingReportMail queues a DIAN closing report with its PDF to the owners
+func queueDIANClosingReportMail(db *gorm.DB, report *DIANClosingReport, period string) (*models.MailMessage, error) {
+	recipients := mailOwnerRecipients(db)
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no hay correos de propietarios configurados")
+	}
+
+	pdfSvc := NewPDFService()
+	pdf, err := pdfSvc.renderDIANClosingReport(report, period)
+	if err != nil {
+		return nil, err
+	}
+
+	periodLabels := map[string]string{
+		"daily":   "diario",
+		"weekly":  "semanal",
+		"monthly": "mensual",
+		"yearly":  "anual",
+		"custom":  "personalizado",
+	}
+	periodLabel := periodLabels[period]
+	if periodLabel == "" {
+		periodLabel = period
+	}
+
+	dateLabel := report.ReportDate
+	fileName := fmt.Sprintf("cierre-dian-%s-%s.pdf", period, report.ReportDate)
+	if report.ReportEndDate != "" && report.ReportEndDate != report.ReportDate {
+		dateLabel = report.ReportDate + " al " + report.ReportEndDate
+		fileName = fmt.Sprintf("cierre-dian-%s-%s_%s.pdf", period, report.ReportDate, report.ReportEndDate)
+	}
+
+	title := fmt.Sprintf("Cierre DIAN %s %s", periodLabel, dateLabel)
+	restaurant := mailRestaurant(db)
+	body, err := renderMailTemplate("dian_closing_report", title, restaurant, mailDIANClosingData{Report: report, PeriodLabel: periodLabel})
+	if err != nil {
+		return nil, err
+	}
+
+	return queueMail(db, mailRequest{
+		Kind:          models.MailKindDIANClosingReport,
+		To:            recipients,
+		Subject:       title + " - " + restaurant.Name,
+		HTMLBody:      body,
+		Attachments:   []mailFile{{FileName: fileName, ContentType: "application/pdf", Data: pdf}},
+		ReferenceType: "dian_closing_report",
+	})
+}
+
+// queueCashRegisterReportMail queues a cash register closing report with its PDF to the owners
+func queueCashRegisterReportMail(db *gorm.DB, report *models.CashRegisterReport) (*models.MailMessage, error) {
+	recipients := mailOwnerRecipients(db)
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no hay correos de propietarios configurados")
+	}
+
+	pdf, err := NewPDFService().renderCashRegisterReport(report)
+	if err != nil {
+		return nil, err
+	}
+
+	employeeName := ""
+	if report.Employee != nil {
+		employeeName = report.Employee.Name
+	}
+	title := fmt.Sprintf("Cierre de caja %s", report.Date.Format("02/01/2006"))
+	restaurant := mailRestaurant(db)
+	body, err := renderMailTemplate("cash_register_report", title, restaurant, mailCashRegisterData{Report: report, EmployeeName: employeeName})
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := fmt.Sprintf("cierre-caja-%d-%s.pdf", report.CashRegisterID, report.Date.Format("2006-01-02"))
+	return queueMail(db, mailRequest{
+		Kind:          models.MailKindCashRegisterReport,
+		To:            recipients,
+		Subject:       title + " - " + restaurant.Name,
+		HTMLBody:      body,
+		Attachments:   []mailFile{{FileName: fileName, ContentType: "application/pdf", Data: pdf}},
+		ReferenceType: "cash_register_report",
+		ReferenceID:   report.ID,
+	})
+}
+
+// queueCashRegisterClosingMail emails a cash register closing when enabled in the mail settings
+func queueCashRegisterClosingMail(db *gorm.DB, reportID uint) {
+	if db == nil || !NewConfigService().GetSystemConfigBool("mail_send_cash_register_reports", false) {
+		return
+	}
+
+	var report models.CashRegisterReport
+	if err := db.Preload("Employee").Preload("BoldReconciliation").First(&report, reportID).Error; err != nil {
+		log.Printf("Warning: Failed to load cash register report %d for email: %v", reportID, err)
+		return
+	}
+	if _, err := queueCashRegisterReportMail(db, &report); err != nil {
+		log.Printf("Warning: Failed to queue cash register report email: %v", err)
+	}
+}
+
+// mailSendsInvoices reports whether invoices are emailed from our SMTP server
+// instead of by the DIAN provider; the provider keeps sending them while the server is not configured
+func mailSendsInvoices(db *gorm.DB) bool {
+	if !NewConfigService().GetSystemConfigBool("mail_send_invoices", false) {
+		return false
+	}
+	_, err := mailTransportFromConfig(db)
+	return err == nil
+}
+
+// mailOwnerRecipients returns the recipients of reports and alerts
+func mailOwnerRecipients(db *gorm.DB) []string {
+	owners, _ := NewConfigService().GetSystemConfig("mail_owner_emails")
+	if recipients := splitMailRecipients(owners); len(recipients) > 0 {
+		return recipients
+	}
+	return splitMailRecipients(mailRestaurant(db).Email)
+}
+
+// mailRestaurant returns the restaurant configuration used in templates
+func mailRestaurant(db *gorm.DB) *models.RestaurantConfig {
+	var restaurant models.RestaurantConfig
+	db.First(&restaurant)
+	return &restaurant
+}
+
+// ==================== SCHEDULED REPORTS AND ALERTS ====================
+
+// runScheduledChecks sends the daily closing report and the stock and numbering alerts when due
+func (s *MailService) runScheduledChecks() {
+	settings := s.GetMailSettings()
+	if len(mailOwnerRecipients(s.db)) == 0 {
+		return
+	}
+	if settings.SendDailyClosingReport {
+		s.checkDailyClosingReport(settings)
+	}
+	if settings.SendLowStockAlerts {
+		s.checkLowStock()
+	}
+	if settings.SendInvoiceRangeAlerts {
+		s.checkInvoiceRanges()
+	}
+}
+
+// checkDailyClosingReport sends the DIAN closing report of the previous day once the configured time has passed
+func (s *MailService) checkDailyClosingReport(settings MailSettings) {
+	now := time.Now()
+	if now.Format("15:04") < settings.DailyReportTime {
+		return
+	}
+
+	reportDate := now.AddDate(0, 0, -1).Format("2006-01-02")
+	configSvc := NewConfigService()
+	if last, _ := configSvc.GetSystemConfig("mail_last_daily_report_date"); last >= reportDate {
+		return
+	}
+
+	report, err := NewSalesService().GetDIANClosingReportWithPeriod(reportDate, "daily")
+	if err != nil {
+		log.Printf("Mail: could not build daily closing report for %s: %v", reportDate, err)
+		return
+	}
+	if _, err := queueDIANClosingReportMail(s.db, report, "daily"); err != nil {
+		log.Printf("Mail: could not queue daily closing report for %s: %v", reportDate, err)
+		return
+	}
+	configSvc.SetSystemConfig("mail_last_daily_report_date", reportDate, "string", "mail")
+}
+
+// checkLowStock alerts the owners when a product or ingredient reaches its minimum stock.
+// Items already alerted are not repeated until they are restocked and drop again
+func (s *MailService) checkLowStock() {
+	var products []models.Product
+	s.db.Where("track_inventory = ? AND stock <= minimum_stock AND is_active = ?", true, true).
+		Order("name").Find(&products)
+	var ingredients []models.Ingredient
+	s.db.Where("is_active = ? AND stock <= min_stock", true).
+		Order("name").Find(&ingredients)
+
+	var data mailLowStockData
+	var keys []string
+	for _, product := range products {
+		data.Products = append(data.Products, mailStockItem{
+			Name:    product.Name,
+			Stock:   float64(product.Stock),
+			Minimum: float64(product.MinimumStock),
+		})
+		keys = append(keys, fmt.Sprintf("p%d", product.ID))
+	}
+	for _, ingredient := range ingredients {
+		data.Ingredients = append(data.Ingredients, mailStockItem{
+			Name:    ingredient.Name,
+			Stock:   ingredient.Stock,
+			Minimum: ingredient.MinStock,
+			Unit:    ingredient.Unit,
+		})
+		keys = append(keys, fmt.Sprintf("i%d", ingredient.ID))
+	}
+	sort.Strings(keys)
+
+	configSvc := NewConfigService()
+	previous, _ := configSvc.GetSystemConfig("mail_low_stock_alerted")
+	alerted := make(map[string]bool)
+	for _, key := range strings.Split(previous, ",") {
+		alerted[key] = true
+	}
+	hasNew := false
+	for _, key := range keys {
+		if !alerted[key] {
+			hasNew = true
+			break
+		}
+	}
+
+	current := strings.Join(keys, ",")
+	if hasNew {
+		restaurant := mailRestaurant(s.db)
+		title := fmt.Sprintf("Alerta de inventario bajo (%d)", len(keys))
+		body, err := renderMailTemplate("low_stock_alert", title, restaurant, data)
+		if err != nil {
+			log.Printf("Mail: %v", err)
+			return
+		}
+		_, err = queueMail(s.db, mailRequest{
+			Kind:     models.MailKindLowStockAlert,
+			To:       mailOwnerRecipients(s.db),
+			Subject:  title + " - " + restaurant.Name,
+			HTMLBody: body,
+		})
+		if err != nil {
+			log.Printf("Mail: could not queue low stock alert: %v", err)
+			return
+		}
+	}
+	if current != previous {
+		configSvc.SetSystemConfig("mail_low_stock_alerted", current, "string", "mail")
+	}
+}
+
+// checkInvoiceRanges alerts the owners, at most once a day, when a DIAN numbering range
+// is close to its last number or expiration date
+func (s *MailService) checkInvoiceRanges() {
+	today := time.Now().Format("2006-01-02")
+	configSvc := NewConfigService()
+	if last, _ := configSvc.GetSystemConfig("mail_last_range_alert_date"); last == today {
+		return
+	}
+
+	var dianConfig models.DIANConfig
+	if err := s.db.First(&dianConfig).Error; err != nil || !dianConfig.IsEnabled {
+		return
+	}
+
+	threshold := dianConfig.InvoiceLimitAlertThreshold
+	if threshold == 0 {
+		threshold = 100
+	}
+
+	var data mailInvoiceRangeData
+	addRange := func(label, prefix string, current, end int, expiresAt time.Time) {
+		if end <= 0 {
+			return
+		}
+		alert := mailRangeAlert{
+			Label:     label,
+			Prefix:    prefix,
+			Current:   current,
+			End:       end,
+			Remaining: end - current,
+			ExpiresAt: expiresAt,
+		}
+		if alert.Remaining < 0 {
+			alert.Remaining = 0
+		}
+		if !expiresAt.IsZero() {
+			alert.DaysLeft = int(time.Until(expiresAt).Hours() / 24)
+			alert.Expired = time.Now().After(expiresAt)
+		}
+		if alert.Remaining <= threshold || (!expiresAt.IsZero() && alert.DaysLeft <= mailRangeExpiryDays) {
+			data.Ranges = append(data.Ranges, alert)
+		}
+	}
+	addRange("Factura electrónica", dianConfig.ResolutionPrefix, dianConfig.LastInvoiceNumber,
+		dianConfig.ResolutionTo, dianConfig.ResolutionDateTo)
+	if dianConfig.UsePOSEquivalentDocument {
+		addRange("Documento equivalente POS", dianConfig.POSResolutionPrefix, dianConfig.LastPOSNumber,
+			dianConfig.POSResolutionTo, dianConfig.POSResolutionDateTo)
+	}
+
+	if len(data.Ranges) > 0 {
+		restaurant := mailRestaurant(s.db)
+		title := "Rango de numeración DIAN por agotarse"
+		body, err := renderMailTemplate("invoice_range_alert", title, restaurant, data)
+		if err != nil {
+			log.Printf("Mail: %v", err)
+			return
+		}
+		_, err = queueMail(s.db, mailRequest{
+			Kind:     models.MailKindInvoiceRangeAlert,
+			To:       mailOwnerRecipients(s.db),
+			Subject:  title + " - " + restaurant.Name,
+			HTMLBody: body,
+		})
+		if err != nil {
+			log.Printf("Mail: could not queue invoice range alert: %v", err)
+			return
+		}
+	}
+	configSvc.SetSystemConfig("mail_last_range_alert_date", today, "string", "mail")
+}
+
+// ==================== LOCAL SMTP SERVER ====================
+
+// GetLocalSMTPInbox returns the messages received by the built-in test SMTP server
+func (s *MailService) GetLocalSMTPInbox() LocalSMTPInbox {
+	localSMTPMu.Lock()
+	server := localSMTPServer
+	localSMTPMu.Unlock()
+
+	if server == nil {
+		return LocalSMTPInbox{Messages: []CapturedMail{}}
+	}
+	return LocalSMTPInbox{
+		Running:  server.Addr() != "",
+		Address:  server.Addr(),
+		Messages: server.Messages(),
+	}
+}
+
+// ClearLocalSMTPInbox removes the messages received by the built-in test SMTP server
+func (s *MailService) ClearLocalSMTPInbox() {
+	localSMTPMu.Lock()
+	server := localSMTPServer
+	localSMTPMu.Unlock()
+
+	if server != nil {
+		server.Clear()
+	}
+}
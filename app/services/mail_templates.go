@@ -0,0 +1,260 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"PosApp/app/models"
+)
+
+// Email templates. Every message is rendered into the shared layout, which adds
+// the restaurant header and footer; the templates only provide the body content
+
+// mailLayoutData is the data of the shared layout
+type mailLayoutData struct {
+	Title          string
+	RestaurantName string
+	RestaurantInfo string
+	Content        template.HTML
+}
+
+// mailInvoiceData is the data of the invoice email sent to customers
+type mailInvoiceData struct {
+	CustomerName   string
+	DocumentLabel  string // "Factura electrónica de venta", "Documento equivalente POS"...
+	DocumentNumber string
+	Date           time.Time
+	Total          float64
+	CUFE           string
+	Attachments    []string
+}
+
+// mailDIANClosingData is the data of the DIAN closing report email
+type mailDIANClosingData struct {
+	Report      *DIANClosingReport
+	PeriodLabel string
+}
+
+// mailCashRegisterData is the data of the cash register closing email
+type mailCashRegisterData struct {
+	Report       *models.CashRegisterReport
+	EmployeeName string
+}
+
+// mailStockItem is a product or ingredient below its minimum stock
+type mailStockItem struct {
+	Name    string
+	Stock   float64
+	Minimum float64
+	Unit    string
+}
+
+// mailLowStockData is the data of the low stock alert
+type mailLowStockData struct {
+	Products    []mailStockItem
+	Ingredients []mailStockItem
+}
+
+// mailRangeAlert is a numbering range close to its end or expiration
+type mailRangeAlert struct {
+	Label     string
+	Prefix    string
+	Current   int
+	End       int
+	Remaining int
+	ExpiresAt time.Time
+	Expired   bool
+	DaysLeft  int
+}
+
+// mailInvoiceRangeData is the data of the numbering range alert
+type mailInvoiceRangeData struct {
+	Ranges []mailRangeAlert
+}
+
+// mailPaymentLinkData is the data of the payment link email
+type mailPaymentLinkData struct {
+	OrderNumber string
+	Message     string
+	URL         string
+}
+
+// mailTestData is the data of the test email
+type mailTestData struct {
+	Server string
+	SentAt time.Time
+}
+
+var mailTemplateFuncs = template.FuncMap{
+	"money": pdfMoney,
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("02/01/2006")
+	},
+	"datetime": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("02/01/2006 15:04")
+	},
+	"number": func(v float64) string {
+		return fmt.Sprintf("%.2f", v)
+	},
+	"multiline": func(text string) template.HTML {
+		return template.HTML(strings.ReplaceAll(template.HTMLEscapeString(text), "\n", "<br>"))
+	},
+}
+
+var mailTemplates = template.Must(template.New("mail").Funcs(mailTemplateFuncs).Parse(`
+{{define "layout"}}<!DOCTYPE html>
+<html lang="es">
+<head><meta charset="UTF-8"><title>{{.Title}}</title></head>
+<body style="margin:0;padding:0;background:#f4f4f4;font-family:Arial,Helvetica,sans-serif;color:#333;">
+<table width="100%" cellpadding="0" cellspacing="0" style="background:#f4f4f4;padding:20px 0;">
+<tr><td align="center">
+<table width="600" cellpadding="0" cellspacing="0" style="background:#ffffff;border-radius:6px;overflow:hidden;">
+<tr><td style="background:#1976d2;color:#ffffff;padding:18px 24px;">
+<div style="font-size:20px;font-weight:bold;">{{.RestaurantName}}</div>
+<div style="font-size:14px;opacity:0.9;">{{.Title}}</div>
+</td></tr>
+<tr><td style="padding:24px;font-size:14px;line-height:1.5;">{{.Content}}</td></tr>
+<tr><td style="padding:14px 24px;background:#fafafa;color:#888;font-size:12px;">
+{{if .RestaurantInfo}}{{.RestaurantInfo}}<br>{{end}}Este correo fue generado automáticamente, por favor no responda a este mensaje.
+</td></tr>
+</table>
+</td></tr>
+</table>
+</body>
+</html>{{end}}
+
+{{define "invoice"}}
+<p>Hola{{if .CustomerName}} {{.CustomerName}}{{end}},</p>
+<p>Gracias por su compra. Adjuntamos su <strong>{{.DocumentLabel}}</strong> número <strong>{{.DocumentNumber}}</strong>.</p>
+<table cellpadding="6" cellspacing="0" style="border-collapse:collapse;width:100%;">
+<tr><td style="border-bottom:1px solid #eee;">Fecha</td><td style="border-bottom:1px solid #eee;text-align:right;">{{datetime .Date}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Total</td><td style="border-bottom:1px solid #eee;text-align:right;"><strong>{{money .Total}}</strong></td></tr>
+</table>
+{{if .CUFE}}<p style="font-size:11px;color:#666;word-break:break-all;">CUFE/CUDE: {{.CUFE}}</p>{{end}}
+{{if .Attachments}}<p>Archivos adjuntos:</p><ul>{{range .Attachments}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{end}}
+
+{{define "dian_closing_report"}}
+{{with .Report}}
+<p>Cierre {{$.PeriodLabel}} del {{.ReportDate}}{{if and .ReportEndDate (ne .ReportEndDate .ReportDate)}} al {{.ReportEndDate}}{{end}}.</p>
+<table cellpadding="6" cellspacing="0" style="border-collapse:collapse;width:100%;">
+<tr><td style="border-bottom:1px solid #eee;">Documentos</td><td style="border-bottom:1px solid #eee;text-align:right;">{{.TotalInvoices}}{{if .FirstInvoiceNumber}} ({{.FirstInvoiceNumber}} - {{.LastInvoiceNumber}}){{end}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Subtotal</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .TotalSubtotal}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Impuestos</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .TotalTax}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Descuentos</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .TotalDiscount}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Notas crédito</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .TotalCreditNotes}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Notas débito</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .TotalDebitNotes}}</td></tr>
+<tr><td><strong>Total</strong></td><td style="text-align:right;"><strong>{{money .GrandTotal}}</strong></td></tr>
+</table>
+{{if .PaymentMethods}}
+<p style="margin-top:18px;"><strong>Medios de pago</strong></p>
+<table cellpadding="6" cellspacing="0" style="border-collapse:collapse;width:100%;">
+{{range .PaymentMethods}}<tr><td style="border-bottom:1px solid #eee;">{{.MethodName}}</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .Total}}</td></tr>{{end}}
+</table>
+{{end}}
+<p>El reporte completo se encuentra en el PDF adjunto.</p>
+{{end}}
+{{end}}
+
+{{define "cash_register_report"}}
+{{with .Report}}
+<p>Cierre de caja del {{date .Date}}{{if $.EmployeeName}} realizado por <strong>{{$.EmployeeName}}</strong>{{end}}.</p>
+<table cellpadding="6" cellspacing="0" style="border-collapse:collapse;width:100%;">
+<tr><td style="border-bottom:1px solid #eee;">Ventas ({{.NumberOfSales}})</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .TotalSales}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Efectivo</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .TotalCash}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Tarjeta</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .TotalCard}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Digital</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .TotalDigital}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Propinas</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .TotalTips}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Base inicial</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .OpeningBalance}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Efectivo esperado</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .ExpectedBalance}}</td></tr>
+<tr><td style="border-bottom:1px solid #eee;">Efectivo contado</td><td style="border-bottom:1px solid #eee;text-align:right;">{{money .ClosingBalance}}</td></tr>
+<tr><td><strong>Diferencia</strong></td><td style="text-align:right;{{if lt .Difference 0.0}}color:#c62828;{{end}}"><strong>{{money .Difference}}</strong></td></tr>
+</table>
+{{if .Notes}}<p><strong>Notas:</strong> {{.Notes}}</p>{{end}}
+<p>El reporte completo se encuentra en el PDF adjunto.</p>
+{{end}}
+{{end}}
+
+{{define "low_stock_alert"}}
+<p>Los siguientes artículos están en o por debajo de su stock mínimo:</p>
+{{if .Products}}
+<p><strong>Productos</strong></p>
+<table cellpadding="6" cellspacing="0" style="border-collapse:collapse;width:100%;">
+<tr style="background:#fafafa;"><th align="left">Producto</th><th align="right">Stock</th><th align="right">Mínimo</th></tr>
+{{range .Products}}<tr><td style="border-bottom:1px solid #eee;">{{.Name}}</td><td style="border-bottom:1px solid #eee;text-align:right;color:#c62828;">{{number .Stock}} {{.Unit}}</td><td style="border-bottom:1px solid #eee;text-align:right;">{{number .Minimum}} {{.Unit}}</td></tr>{{end}}
+</table>
+{{end}}
+{{if .Ingredients}}
+<p><strong>Ingredientes</strong></p>
+<table cellpadding="6" cellspacing="0" style="border-collapse:collapse;width:100%;">
+<tr style="background:#fafafa;"><th align="left">Ingrediente</th><th align="right">Stock</th><th align="right">Mínimo</th></tr>
+{{range .Ingredients}}<tr><td style="border-bottom:1px solid #eee;">{{.Name}}</td><td style="border-bottom:1px solid #eee;text-align:right;color:#c62828;">{{number .Stock}} {{.Unit}}</td><td style="border-bottom:1px solid #eee;text-align:right;">{{number .Minimum}} {{.Unit}}</td></tr>{{end}}
+</table>
+{{end}}
+{{end}}
+
+{{define "invoice_range_alert"}}
+<p>Los siguientes rangos de numeración autorizados por la DIAN requieren atención:</p>
+<table cellpadding="6" cellspacing="0" style="border-collapse:collapse;width:100%;">
+<tr style="background:#fafafa;"><th align="left">Rango</th><th align="right">Actual / Final</th><th align="right">Restantes</th><th align="right">Vigencia</th></tr>
+{{range .Ranges}}<tr>
+<td style="border-bottom:1px solid #eee;">{{.Label}}{{if .Prefix}} ({{.Prefix}}){{end}}</td>
+<td style="border-bottom:1px solid #eee;text-align:right;">{{.Current}} / {{.End}}</td>
+<td style="border-bottom:1px solid #eee;text-align:right;color:#c62828;"><strong>{{.Remaining}}</strong></td>
+<td style="border-bottom:1px solid #eee;text-align:right;">{{if .Expired}}<span style="color:#c62828;">Vencida</span>{{else if not .ExpiresAt.IsZero}}{{date .ExpiresAt}} ({{.DaysLeft}} días){{end}}</td>
+</tr>{{end}}
+</table>
+<p>Solicite una nueva resolución de numeración en el portal de la DIAN y actualícela en la configuración antes de agotar el rango.</p>
+{{end}}
+
+{{define "payment_link"}}
+<p>{{multiline .Message}}</p>
+<p style="text-align:center;margin:24px 0;"><a href="{{.URL}}" style="background:#1976d2;color:#ffffff;padding:12px 24px;border-radius:4px;text-decoration:none;font-weight:bold;">Pagar ahora</a></p>
+<p style="font-size:12px;color:#666;">Si el botón no funciona copie este enlace en su navegador: {{.URL}}</p>
+{{end}}
+
+{{define "test"}}
+<p>Este es un correo de prueba.</p>
+<p>Si lo está leyendo, la configuración de correo funciona correctamente.</p>
+<p style="font-size:12px;color:#666;">Servidor: {{.Server}}<br>Enviado: {{datetime .SentAt}}</p>
+{{end}}
+`))
+
+// renderMailTemplate renders a template into the shared layout
+func renderMailTemplate(name string, title string, restaurant *models.RestaurantConfig, data interface{}) (string, error) {
+	var content bytes.Buffer
+	if err := mailTemplates.ExecuteTemplate(&content, name, data); err != nil {
+		return "", fmt.Errorf("failed to render mail template %s: %w", name, err)
+	}
+
+	layout := mailLayoutData{
+		Title:   title,
+		Content: template.HTML(content.String()),
+	}
+	if restaurant != nil {
+		layout.RestaurantName = restaurant.Name
+		info := restaurant.Address
+		if restaurant.Phone != "" {
+			if info != "" {
+				info += " - "
+			}
+			info += "Tel. " + restaurant.Phone
+		}
+		layout.RestaurantInfo = info
+	}
+
+	var page bytes.Buffer
+	if err := mailTemplates.ExecuteTemplate(&page, "layout", layout); err != nil {
+		return "", fmt.Errorf("failed to render mail layout: %w", err)
+	}
+	return page.String(), nil
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// localSMTPMaxMessages is how many captured messages the local SMTP server keeps
+const localSMTPMaxMessages = 200
+
+// LocalSMTPServer is a minimal in-process SMTP server that accepts every message
+// and keeps it in memory. It stands in for the real mail server in tests and demos
+type LocalSMTPServer struct {
+	mu       sync.Mutex
+	listener net.Listener
+	addr     string
+	nextID   int
+	messages []CapturedMail
+}
+
+// CapturedMail is a message received by the local SMTP server
+type CapturedMail struct {
+	ID          int                  `json:"id"`
+	From        string               `json:"from"`
+	To          []string             `json:"to"`
+	Subject     string               `json:"subject"`
+	HTMLBody    string               `json:"html_body"`
+	Attachments []CapturedAttachment `json:"attachments"`
+	Size        int                  `json:"size"`
+	ReceivedAt  time.Time            `json:"received_at"`
+	Raw         string               `json:"-"`
+}
+
+// CapturedAttachment describes an attachment of a captured message
+type CapturedAttachment struct {
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+}
+
+// NewLocalSMTPServer creates a stopped local SMTP server
+func NewLocalSMTPServer() *LocalSMTPServer {
+	return &LocalSMTPServer{}
+}
+
+// Start listens on addr ("127.0.0.1:0" picks a free port)
+func (m *LocalSMTPServer) Start(addr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.listener != nil {
+		return fmt.Errorf("local SMTP server already running")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to start local SMTP server: %w", err)
+	}
+	m.listener = listener
+	m.addr = listener.Addr().String()
+
+	go func() {
+		log.Printf("[LOCAL SMTP] Server starting on %s", listener.Addr())
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go m.serve(conn)
+		}
+	}()
+
+	return nil
+}
+
+// Stop closes the listener
+func (m *LocalSMTPServer) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.listener == nil {
+		return nil
+	}
+	err := m.listener.Close()
+	m.listener = nil
+	m.addr = ""
+	log.Printf("[LOCAL SMTP] Server stopped")
+	return err
+}
+
+// Addr returns host:port of the running server
+func (m *LocalSMTPServer) Addr() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.addr
+}
+
+// HostPort returns the host and port of the running server
+func (m *LocalSMTPServer) HostPort() (string, int) {
+	host, portStr, _ := net.SplitHostPort(m.Addr())
+	port, _ := strconv.Atoi(portStr)
+	return host, port
+}
+
+// Messages returns the captured messages, newest first
+func (m *LocalSMTPServer) Messages() []CapturedMail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := make([]CapturedMail, len(m.messages))
+	for i, msg := range m.messages {
+		messages[len(m.messages)-1-i] = msg
+	}
+	return messages
+}
+
+// Clear forgets every captured message
+func (m *LocalSMTPServer) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
+
+func (m *LocalSMTPServer) store(from string, to []string, raw []byte) {
+	captured := parseCapturedMail(raw)
+	captured.From = from
+	captured.To = to
+	captured.Size = len(raw)
+	captured.ReceivedAt = time.Now()
+	captured.Raw = string(raw)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	captured.ID = m.nextID
+	m.messages = append(m.messages, captured)
+	if len(m.messages) > localSMTPMaxMessages {
+		m.messages = m.messages[len(m.messages)-localSMTPMaxMessages:]
+	}
+}
+
+// serve speaks enough SMTP for net/smtp: EHLO/HELO, AUTH, MAIL, RCPT, DATA, RSET, NOOP and QUIT
+func (m *LocalSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Minute))
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		conn.Write([]byte(line + "\r\n"))
+	}
+
+	var from string
+	var recipients []string
+	reply("220 localhost PosApp local SMTP ready")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(line)
+		if idx := strings.Index(verb, " "); idx != -1 {
+			verb = verb[:idx]
+		}
+
+		switch verb {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250-8BITMIME")
+			reply("250 AUTH PLAIN LOGIN")
+		case "HELO":
+			reply("250 localhost")
+		case "AUTH":
+			fields := strings.Fields(line)
+			if len(fields) >= 2 && strings.EqualFold(fields[1], "LOGIN") {
+				// Username and password challenges; any credentials are accepted
+				reply("334 VXNlcm5hbWU6")
+				if _, err := reader.ReadString('\n'); err != nil {
+					return
+				}
+				reply("334 UGFzc3dvcmQ6")
+				if _, err := reader.ReadString('\n'); err != nil {
+					return
+				}
+			} else if len(fields) == 2 {
+				reply("334 ")
+				if _, err := reader.ReadString('\n'); err != nil {
+					return
+				}
+			}
+			reply("235 Authentication successful")
+		case "MAIL":
+			from = smtpPathArgument(line)
+			recipients = nil
+			reply("250 OK")
+		case "RCPT":
+			recipients = append(recipients, smtpPathArgument(line))
+			reply("250 OK")
+		case "DATA":
+			if len(recipients) == 0 {
+				reply("503 RCPT first")
+				continue
+			}
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data bytes.Buffer
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" || dataLine == ".\n" {
+					break
+				}
+				// Undo dot-stuffing
+				if strings.HasPrefix(dataLine, "..") {
+					dataLine = dataLine[1:]
+				}
+				data.WriteString(dataLine)
+			}
+			m.store(from, recipients, data.Bytes())
+			from, recipients = "", nil
+			reply("250 OK: queued")
+		case "RSET":
+			from, recipients = "", nil
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// smtpPathArgument extracts the address of "MAIL FROM:<a@b>" / "RCPT TO:<a@b>"
+func smtpPathArgument(line string) string {
+	if start := strings.Index(line, "<"); start != -1 {
+		if end := strings.Index(line[start:], ">"); end != -1 {
+			return line[start+1 : start+end]
+		}
+	}
+	if idx := strings.Index(line, ":"); idx != -1 {
+		return strings.TrimSpace(line[idx+1:])
+	}
+	return ""
+}
+
+// parseCapturedMail decodes the subject, HTML body and attachment list of a raw message
+func parseCapturedMail(raw []byte) CapturedMail {
+	var captured CapturedMail
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return captured
+	}
+
+	decoder := new(mime.WordDecoder)
+	if subject, err := decoder.DecodeHeader(msg.Header.Get("Subject")); err == nil {
+		captured.Subject = subject
+	} else {
+		captured.Subject = msg.Header.Get("Subject")
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		captured.HTMLBody = string(decodeCapturedPart(msg.Body, msg.Header.Get("Content-Transfer-Encoding")))
+		return captured
+	}
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err != nil {
+			break
+		}
+		data := decodeCapturedPart(part, part.Header.Get("Content-Transfer-Encoding"))
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if part.FileName() == "" && partType == "text/html" {
+			captured.HTMLBody = string(data)
+			continue
+		}
+		captured.Attachments = append(captured.Attachments, CapturedAttachment{
+			FileName:    part.FileName(),
+			ContentType: partType,
+			Size:        len(data),
+		})
+	}
+	return captured
+}
+
+func decodeCapturedPart(r io.Reader, encoding string) []byte {
+	data, _ := io.ReadAll(r)
+	if strings.EqualFold(encoding, "base64") {
+		cleaned := strings.NewReplacer("\r", "", "\n", "").Replace(string(data))
+		if decoded, err := base64.StdEncoding.DecodeString(cleaned); err == nil {
+			return decoded
+		}
+	}
+	return data
+}
+
+// Embedded local SMTP server used when mail test mode is enabled
+var (
+	localSMTPMu     sync.Mutex
+	localSMTPServer *LocalSMTPServer
+)
+
+// ensureLocalSMTPServer starts the local SMTP server on first use
+func ensureLocalSMTPServer() (*LocalSMTPServer, error) {
+	localSMTPMu.Lock()
+	defer localSMTPMu.Unlock()
+
+	if localSMTPServer == nil {
+		server := NewLocalSMTPServer()
+		if err := server.Start("127.0.0.1:0"); err != nil {
+			return nil, err
+		}
+		localSMTPServer = server
+	}
+	return localSMTPServer, nil
+}
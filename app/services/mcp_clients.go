@@ -0,0 +1,303 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"PosApp/app/database"
+	"PosApp/app/mcp"
+	"PosApp/app/models"
+)
+
+// mcpKeyPrefix identifies MCP client keys
+const mcpKeyPrefix = "posmcp_"
+
+// MCPClientKey is returned when a client is created or its key regenerated
+// The API key is only available at that moment, the database keeps its hash
+type MCPClientKey struct {
+	Client models.MCPClient `json:"client"`
+	APIKey string           `json:"api_key"`
+}
+
+// generateMCPKey creates a random API key and returns it with its hash and display prefix
+func generateMCPKey() (key, hash, prefix string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("error generating API key: %w", err)
+	}
+	key = mcpKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, hashMCPKey(key), key[:len(mcpKeyPrefix)+6], nil
+}
+
+func hashMCPKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validateMCPClient normalizes a client and checks its permissions
+func validateMCPClient(client *models.MCPClient) error {
+	client.Name = strings.TrimSpace(client.Name)
+	if client.Name == "" {
+		return fmt.Errorf("el nombre del cliente es requerido")
+	}
+	if client.Scope == "" {
+		client.Scope = models.MCPScopeRead
+	}
+	if client.Scope != models.MCPScopeRead && client.Scope != models.MCPScopeWrite {
+		return fmt.Errorf("alcance inválido: %s (use read o write)", client.Scope)
+	}
+	if client.RateLimitPerMinute < 0 {
+		return fmt.Errorf("el límite de llamadas no puede ser negativo")
+	}
+
+	tools := make(map[string]bool)
+	categories := make(map[string]bool)
+	for _, tool := range getAllTools() {
+		tools[tool["name"].(string)] = true
+		categories[tool["category"].(string)] = true
+	}
+	allowedTools := splitList(client.AllowedTools)
+	for _, tool := range allowedTools {
+		if !tools[tool] {
+			return fmt.Errorf("herramienta desconocida: %s", tool)
+		}
+	}
+	allowedCategories := splitList(client.AllowedCategories)
+	for _, category := range allowedCategories {
+		if !categories[category] {
+			return fmt.Errorf("categoría desconocida: %s", category)
+		}
+	}
+	client.AllowedTools = strings.Join(allowedTools, ",")
+	client.AllowedCategories = strings.Join(allowedCategories, ",")
+	return nil
+}
+
+// GetMCPClients returns all MCP clients
+func (s *MCPService) GetMCPClients() ([]models.MCPClient, error) {
+	db := database.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+	var clients []models.MCPClient
+	err := db.Order("name").Find(&clients).Error
+	return clients, err
+}
+
+// CreateMCPClient creates a client and returns its API key (shown only once)
+func (s *MCPService) CreateMCPClient(client models.MCPClient) (*MCPClientKey, error) {
+	db := database.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+	if err := validateMCPClient(&client); err != nil {
+		return nil, err
+	}
+
+	key, hash, prefix, err := generateMCPKey()
+	if err != nil {
+		return nil, err
+	}
+	client.ID = 0
+	client.KeyHash = hash
+	client.KeyPrefix = prefix
+	client.IsActive = true
+	client.LastUsedAt = nil
+	client.LastUsedIP = ""
+	if err := db.Create(&client).Error; err != nil {
+		return nil, fmt.Errorf("error creating MCP client: %w", err)
+	}
+
+	log.Printf("MCP: client '%s' created (scope %s)", client.Name, client.Scope)
+	return &MCPClientKey{Client: client, APIKey: key}, nil
+}
+
+// UpdateMCPClient updates the name and permissions of a client (the key doesn't change)
+func (s *MCPService) UpdateMCPClient(client models.MCPClient) error {
+	db := database.GetDB()
+	if db == nil {
+		return fmt.Errorf("database not available")
+	}
+	if err := validateMCPClient(&client); err != nil {
+		return err
+	}
+
+	var existing models.MCPClient
+	if err := db.First(&existing, client.ID).Error; err != nil {
+		return fmt.Errorf("cliente MCP no encontrado")
+	}
+	return db.Model(&existing).Select(
+		"Name", "Description", "AllowedTools", "AllowedCategories", "Scope",
+		"RateLimitPerMinute", "ExpiresAt", "IsActive",
+	).Updates(&client).Error
+}
+
+// RegenerateMCPClientKey replaces the API key of a client, the previous key stops working immediately
+func (s *MCPService) RegenerateMCPClientKey(id uint) (*MCPClientKey, error) {
+	db := database.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+	var client models.MCPClient
+	if err := db.First(&client, id).Error; err != nil {
+		return nil, fmt.Errorf("cliente MCP no encontrado")
+	}
+
+	key, hash, prefix, err := generateMCPKey()
+	if err != nil {
+		return nil, err
+	}
+	client.KeyHash = hash
+	client.KeyPrefix = prefix
+	if err := db.Model(&client).Updates(map[string]interface{}{"key_hash": hash, "key_prefix": prefix}).Error; err != nil {
+		return nil, err
+	}
+
+	log.Printf("MCP: API key of client '%s' regenerated", client.Name)
+	return &MCPClientKey{Client: client, APIKey: key}, nil
+}
+
+// DeleteMCPClient deletes a client (its tool call log is kept)
+func (s *MCPService) DeleteMCPClient(id uint) error {
+	db := database.GetDB()
+	if db == nil {
+		return fmt.Errorf("database not available")
+	}
+	return db.Delete(&models.MCPClient{}, id).Error
+}
+
+// GetMCPToolCallLogs returns the tool call log with optional filters
+func (s *MCPService) GetMCPToolCallLogs(clientID uint, tool, status string, limit, offset int) ([]models.MCPToolCallLog, error) {
+	db := database.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := db.Model(&models.MCPToolCallLog{})
+	if clientID > 0 {
+		query = query.Where("client_id = ?", clientID)
+	}
+	if tool != "" {
+		query = query.Where("tool = ?", tool)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var logs []models.MCPToolCallLog
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&logs).Error
+	return logs, err
+}
+
+// ========== Client Adapter ==========
+
+// MCPClientAdapter implements mcp.ClientServiceInterface on top of the MCPClient table
+type MCPClientAdapter struct{}
+
+func NewMCPClientAdapter() *MCPClientAdapter {
+	return &MCPClientAdapter{}
+}
+
+// HasClients reports whether any active client exists (clients make authentication mandatory)
+func (a *MCPClientAdapter) HasClients() bool {
+	db := database.GetDB()
+	if db == nil {
+		return false
+	}
+	var count int64
+	db.Model(&models.MCPClient{}).Where("is_active = ?", true).Count(&count)
+	return count > 0
+}
+
+func (a *MCPClientAdapter) AuthenticateClient(apiKey string, ip string) (*mcp.ClientPermissions, error) {
+	db := database.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+
+	var client models.MCPClient
+	if err := db.Where("key_hash = ?", hashMCPKey(apiKey)).First(&client).Error; err != nil {
+		return nil, fmt.Errorf("invalid API key")
+	}
+	if !client.IsActive {
+		return nil, fmt.Errorf("client '%s' is disabled", client.Name)
+	}
+	if client.ExpiresAt != nil && time.Now().After(*client.ExpiresAt) {
+		return nil, fmt.Errorf("API key of client '%s' expired on %s", client.Name, client.ExpiresAt.Format("2006-01-02"))
+	}
+
+	// Avoid a write per request, a minute of precision is enough
+	if client.LastUsedAt == nil || time.Since(*client.LastUsedAt) > time.Minute || client.LastUsedIP != ip {
+		db.Model(&client).UpdateColumns(map[string]interface{}{"last_used_at": time.Now(), "last_used_ip": ip})
+	}
+
+	permissions := &mcp.ClientPermissions{
+		ID:                 client.ID,
+		Name:               client.Name,
+		AllTools:           client.AllowedTools == "" && client.AllowedCategories == "",
+		WriteAccess:        client.Scope == models.MCPScopeWrite,
+		RateLimitPerMinute: client.RateLimitPerMinute,
+	}
+	if !permissions.AllTools {
+		permissions.AllowedTools = splitList(client.AllowedTools)
+		categories := splitList(client.AllowedCategories)
+		for _, tool := range getAllTools() {
+			for _, category := range categories {
+				if tool["category"] == category {
+					permissions.AllowedTools = append(permissions.AllowedTools, tool["name"].(string))
+				}
+			}
+		}
+	}
+	return permissions, nil
+}
+
+// LogToolCall stores a tool call in the background so the call isn't slowed down
+func (a *MCPClientAdapter) LogToolCall(entry mcp.ToolCallLog) {
+	db := database.GetDB()
+	if db == nil {
+		return
+	}
+
+	arguments, _ := json.Marshal(entry.Arguments)
+	callLog := models.MCPToolCallLog{
+		ClientID:   entry.ClientID,
+		ClientName: entry.ClientName,
+		Tool:       entry.Tool,
+		Arguments:  string(arguments),
+		Status:     entry.Status,
+		Error:      entry.Error,
+		DurationMs: entry.Duration.Milliseconds(),
+		IPAddress:  entry.IPAddress,
+	}
+	if callLog.ClientName == "" {
+		callLog.ClientName = "Clave global"
+	}
+
+	go func() {
+		if err := db.Create(&callLog).Error; err != nil {
+			log.Printf("MCP: error saving tool call log: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"PosApp/app/models"
+)
+
+func TestGenerateMCPKey(t *testing.T) {
+	key, hash, prefix, err := generateMCPKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, mcpKeyPrefix) || !strings.HasPrefix(key, prefix) {
+		t.Errorf("key %q doesn't start with %q and display prefix %q", key, mcpKeyPrefix, prefix)
+	}
+	if hash != hashMCPKey(key) {
+		t.Error("stored hash doesn't match the key")
+	}
+	other, _, _, _ := generateMCPKey()
+	if other == key {
+		t.Error("two generated keys are equal")
+	}
+}
+
+func TestValidateMCPClient(t *testing.T) {
+	tests := []struct {
+		name           string
+		client         models.MCPClient
+		wantErr        bool
+		wantScope      string
+		wantTools      string
+		wantCategories string
+	}{
+		{"defaults to read", models.MCPClient{Name: "  Reportes  "}, false, models.MCPScopeRead, "", ""},
+		{"write scope", models.MCPClient{Name: "Bot", Scope: models.MCPScopeWrite}, false, models.MCPScopeWrite, "", ""},
+		{"normalizes lists", models.MCPClient{Name: "Bot", AllowedTools: " list_products, ,get_product ", AllowedCategories: "Clientes,"},
+			false, models.MCPScopeRead, "list_products,get_product", "Clientes"},
+		{"missing name", models.MCPClient{Name: "  "}, true, "", "", ""},
+		{"invalid scope", models.MCPClient{Name: "Bot", Scope: "admin"}, true, "", "", ""},
+		{"negative rate limit", models.MCPClient{Name: "Bot", RateLimitPerMinute: -1}, true, "", "", ""},
+		{"unknown tool", models.MCPClient{Name: "Bot", AllowedTools: "drop_database"}, true, "", "", ""},
+		{"unknown category", models.MCPClient{Name: "Bot", AllowedCategories: "Secretos"}, true, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := tt.client
+			err := validateMCPClient(&client)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateMCPClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if client.Name != strings.TrimSpace(tt.client.Name) || client.Scope != tt.wantScope ||
+				client.AllowedTools != tt.wantTools || client.AllowedCategories != tt.wantCategories {
+				t.Errorf("validateMCPClient() = %q %q %q %q", client.Name, client.Scope, client.AllowedTools, client.AllowedCategories)
+			}
+		})
+	}
+}
@@ -26,6 +26,12 @@ type MCPService struct {
 	ingredientAdapter *IngredientMCPAdapter
 	dashboardAdapter  *DashboardMCPAdapter
 	reportsAdapter    *ReportsMCPAdapter
+	clientAdapter     *MCPClientAdapter
+	cashAdapter       *CashRegisterMCPAdapter
+	tableAdapter      *TableMCPAdapter
+	comboAdapter      *ComboMCPAdapter
+	pageAdapter       *CustomPageMCPAdapter
+	dianAdapter       *DIANMCPAdapter
 }
 
 // NewMCPService creates a new MCP service
@@ -36,6 +42,9 @@ func NewMCPService(
 	ingredientService *IngredientService,
 	dashboardService *DashboardService,
 	reportsService *ReportsService,
+	employeeService *EmployeeService,
+	comboService *ComboService,
+	customPageService *CustomPageService,
 ) *MCPService {
 	svc := &MCPService{
 		productAdapter:    NewProductMCPAdapter(productService),
@@ -44,6 +53,12 @@ func NewMCPService(
 		ingredientAdapter: NewIngredientMCPAdapter(ingredientService),
 		dashboardAdapter:  NewDashboardMCPAdapter(dashboardService),
 		reportsAdapter:    NewReportsMCPAdapter(reportsService),
+		clientAdapter:     NewMCPClientAdapter(),
+		cashAdapter:       NewCashRegisterMCPAdapter(employeeService),
+		tableAdapter:      NewTableMCPAdapter(orderService),
+		comboAdapter:      NewComboMCPAdapter(comboService),
+		pageAdapter:       NewCustomPageMCPAdapter(customPageService),
+		dianAdapter:       NewDIANMCPAdapter(salesService),
 	}
 
 	// Load config
@@ -151,6 +166,12 @@ func (s *MCPService) Start() error {
 		IngredientService: s.ingredientAdapter,
 		DashboardService:  s.dashboardAdapter,
 		ReportsService:    s.reportsAdapter,
+		ClientService:     s.clientAdapter,
+		CashService:       s.cashAdapter,
+		TableService:      s.tableAdapter,
+		ComboService:      s.comboAdapter,
+		PageService:       s.pageAdapter,
+		DIANService:       s.dianAdapter,
 	}
 
 	// Create and start server
@@ -158,6 +179,7 @@ func (s *MCPService) Start() error {
 		s.config.Port,
 		s.config.APIKey,
 		s.config.AllowedIPs,
+		s.config.AllowedOrigins,
 		s.config.ReadOnlyMode,
 		s.config.DisabledTools,
 		deps,
@@ -220,6 +242,9 @@ func (s *MCPService) GetStatus() map[string]interface{} {
 		status["api_key_set"] = s.config.APIKey != ""
 		status["read_only_mode"] = s.config.ReadOnlyMode
 	}
+	if s.clientAdapter != nil {
+		status["clients_enabled"] = s.clientAdapter.HasClients()
+	}
 
 	if s.server != nil {
 		status["running"] = s.server.IsRunning()
@@ -291,6 +316,41 @@ func getAllTools() []map[string]interface{} {
 		{"name": "get_sales_by_employee", "category": "Reportes", "description": "Ventas por empleado"},
 		{"name": "get_cash_register_status", "category": "Reportes", "description": "Estado de caja"},
 		{"name": "get_inventory_report", "category": "Reportes", "description": "Reporte de inventario"},
+
+		// Cash register
+		{"name": "get_open_cash_register", "category": "Caja", "description": "Caja abierta actual"},
+		{"name": "open_cash_register", "category": "Caja", "description": "Abrir caja"},
+		{"name": "close_cash_register", "category": "Caja", "description": "Cerrar caja"},
+		{"name": "record_cash_movement", "category": "Caja", "description": "Registrar movimiento de caja"},
+		{"name": "list_cash_movements", "category": "Caja", "description": "Movimientos de caja"},
+		{"name": "list_cash_register_history", "category": "Caja", "description": "Historial de cajas"},
+
+		// Tables
+		{"name": "list_tables", "category": "Mesas", "description": "Listar mesas y su ocupación"},
+		{"name": "get_table", "category": "Mesas", "description": "Obtener mesa por ID"},
+		{"name": "set_table_status", "category": "Mesas", "description": "Cambiar estado de mesa"},
+		{"name": "list_table_areas", "category": "Mesas", "description": "Listar áreas de mesas"},
+
+		// Combos
+		{"name": "list_combos", "category": "Combos", "description": "Listar combos"},
+		{"name": "get_combo", "category": "Combos", "description": "Obtener combo por ID"},
+		{"name": "create_combo", "category": "Combos", "description": "Crear combo"},
+		{"name": "update_combo", "category": "Combos", "description": "Actualizar combo"},
+		{"name": "delete_combo", "category": "Combos", "description": "Eliminar combo"},
+		{"name": "toggle_combo_active", "category": "Combos", "description": "Activar/desactivar combo"},
+
+		// Custom pages
+		{"name": "list_custom_pages", "category": "Páginas", "description": "Listar páginas personalizadas"},
+		{"name": "get_custom_page_products", "category": "Páginas", "description": "Productos de una página"},
+		{"name": "create_custom_page", "category": "Páginas", "description": "Crear página personalizada"},
+		{"name": "update_custom_page", "category": "Páginas", "description": "Actualizar página personalizada"},
+		{"name": "delete_custom_page", "category": "Páginas", "description": "Eliminar página personalizada"},
+		{"name": "set_custom_page_products", "category": "Páginas", "description": "Asignar productos a página"},
+
+		// DIAN
+		{"name": "get_invoice_status", "category": "DIAN", "description": "Estado de validación de factura electrónica"},
+		{"name": "list_electronic_invoices", "category": "DIAN", "description": "Listar facturas electrónicas por estado"},
+		{"name": "get_dian_closing_report", "category": "DIAN", "description": "Reporte de cierre DIAN"},
 	}
 }
 
@@ -810,7 +870,10 @@ func (a *OrderMCPAdapter) GetOrder(id uint) (map[string]interface{}, error) {
 }
 
 func (a *OrderMCPAdapter) UpdateOrderStatus(id uint, status string) error {
-	return a.svc.UpdateOrderStatus(id, models.OrderStatus(status))
+	return a.svc.UpdateOrderStatusWithContext(id, models.OrderStatus(status), models.OrderStatusChange{
+		Source: models.OrderStatusSourceMCP,
+		Actor:  "mcp",
+	})
 }
 
 func (a *OrderMCPAdapter) AddItemsToOrder(orderID uint, items []map[string]interface{}) error {
@@ -873,7 +936,10 @@ func (a *OrderMCPAdapter) SendOrderToKitchen(id uint) error {
 }
 
 func (a *OrderMCPAdapter) MarkOrderReady(id uint) error {
-	return a.svc.UpdateOrderStatus(id, models.OrderStatusReady)
+	return a.svc.UpdateOrderStatusWithContext(id, models.OrderStatusReady, models.OrderStatusChange{
+		Source: models.OrderStatusSourceMCP,
+		Actor:  "mcp",
+	})
 }
 
 // IngredientMCPAdapter adapts IngredientService to mcp.IngredientServiceInterface
@@ -1118,6 +1184,454 @@ func (a *ReportsMCPAdapter) GetInventoryReport() (map[string]interface{}, error)
 	return toMap(report), nil
 }
 
+// CashRegisterMCPAdapter adapts the cash register methods of EmployeeService to mcp.CashRegisterServiceInterface
+type CashRegisterMCPAdapter struct {
+	svc *EmployeeService
+}
+
+func NewCashRegisterMCPAdapter(svc *EmployeeService) *CashRegisterMCPAdapter {
+	return &CashRegisterMCPAdapter{svc: svc}
+}
+
+func (a *CashRegisterMCPAdapter) GetOpenCashRegister() (map[string]interface{}, error) {
+	register, err := a.svc.GetCurrentCashRegister()
+	if err != nil {
+		return nil, err
+	}
+	result := toMap(register)
+	if summary, err := a.svc.GetCashRegisterSalesSummary(register.ID, false); err == nil {
+		result["sales_summary"] = toMap(summary)
+	}
+	return result, nil
+}
+
+func (a *CashRegisterMCPAdapter) OpenCashRegister(employeeID uint, openingAmount float64, notes string) (map[string]interface{}, error) {
+	register, err := a.svc.OpenCashRegister(employeeID, openingAmount, notes)
+	if err != nil {
+		return nil, err
+	}
+	return toMap(register), nil
+}
+
+func (a *CashRegisterMCPAdapter) CloseCashRegister(registerID uint, closingAmount float64, notes string) (map[string]interface{}, error) {
+	report, err := a.svc.CloseCashRegister(registerID, closingAmount, notes)
+	if err != nil {
+		return nil, err
+	}
+	return toMap(report), nil
+}
+
+func (a *CashRegisterMCPAdapter) AddCashMovement(registerID uint, amount float64, movementType, description, reference string, employeeID uint) error {
+	return a.svc.AddCashMovement(registerID, amount, movementType, description, reference, employeeID)
+}
+
+func (a *CashRegisterMCPAdapter) GetCashMovements(registerID uint) ([]map[string]interface{}, error) {
+	movements, err := a.svc.GetCashMovements(registerID)
+	if err != nil {
+		return nil, err
+	}
+	return toMapSlice(movements), nil
+}
+
+func (a *CashRegisterMCPAdapter) GetCashRegisterHistory(limit, offset int) ([]map[string]interface{}, error) {
+	registers, err := a.svc.GetCashRegisterHistory(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return toMapSlice(registers), nil
+}
+
+// TableMCPAdapter adapts the table methods of OrderService to mcp.TableServiceInterface
+type TableMCPAdapter struct {
+	svc *OrderService
+}
+
+func NewTableMCPAdapter(svc *OrderService) *TableMCPAdapter {
+	return &TableMCPAdapter{svc: svc}
+}
+
+func (a *TableMCPAdapter) GetTables() ([]map[string]interface{}, error) {
+	tables, err := a.svc.GetTables()
+	if err != nil {
+		return nil, err
+	}
+	return toMapSlice(tables), nil
+}
+
+func (a *TableMCPAdapter) GetTable(id uint) (map[string]interface{}, error) {
+	table, err := a.svc.GetTable(id)
+	if err != nil {
+		return nil, err
+	}
+	return toMap(table), nil
+}
+
+func (a *TableMCPAdapter) UpdateTableStatus(id uint, status string) error {
+	if _, err := a.svc.GetTable(id); err != nil {
+		return fmt.Errorf("table not found: %w", err)
+	}
+	return a.svc.UpdateTableStatus(id, status)
+}
+
+func (a *TableMCPAdapter) GetTableAreas() ([]map[string]interface{}, error) {
+	areas, err := a.svc.GetTableAreas()
+	if err != nil {
+		return nil, err
+	}
+	return toMapSlice(areas), nil
+}
+
+// ComboMCPAdapter adapts ComboService to mcp.ComboServiceInterface
+type ComboMCPAdapter struct {
+	svc *ComboService
+}
+
+func NewComboMCPAdapter(svc *ComboService) *ComboMCPAdapter {
+	return &ComboMCPAdapter{svc: svc}
+}
+
+func (a *ComboMCPAdapter) GetAllCombos() ([]map[string]interface{}, error) {
+	combos, err := a.svc.GetAllCombosAdmin()
+	if err != nil {
+		return nil, err
+	}
+	return toMapSlice(combos), nil
+}
+
+func (a *ComboMCPAdapter) GetCombo(id uint) (map[string]interface{}, error) {
+	combo, err := a.svc.GetCombo(id)
+	if err != nil {
+		return nil, err
+	}
+	return toMap(combo), nil
+}
+
+// comboItemsFromData parses the items of a combo sent by an MCP client
+func comboItemsFromData(data map[string]interface{}) ([]models.ComboItem, bool) {
+	itemsRaw, ok := data["items"].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	items := make([]models.ComboItem, 0, len(itemsRaw))
+	for _, itemRaw := range itemsRaw {
+		itemMap, ok := itemRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		productID, _ := itemMap["product_id"].(float64)
+		if productID <= 0 {
+			continue
+		}
+		quantity, _ := itemMap["quantity"].(float64)
+		if quantity <= 0 {
+			quantity = 1
+		}
+		items = append(items, models.ComboItem{ProductID: uint(productID), Quantity: int(quantity)})
+	}
+	return items, true
+}
+
+func (a *ComboMCPAdapter) CreateCombo(data map[string]interface{}) (map[string]interface{}, error) {
+	combo := models.Combo{IsActive: true, TaxTypeID: 1}
+	if name, ok := data["name"].(string); ok {
+		combo.Name = name
+	}
+	if description, ok := data["description"].(string); ok {
+		combo.Description = description
+	}
+	if price, ok := data["price"].(float64); ok {
+		combo.Price = price
+	}
+	if categoryID, ok := data["category_id"].(float64); ok && categoryID > 0 {
+		id := uint(categoryID)
+		combo.CategoryID = &id
+	}
+	if taxTypeID, ok := data["tax_type_id"].(float64); ok {
+		combo.TaxTypeID = int(taxTypeID)
+	}
+	if displayOrder, ok := data["display_order"].(float64); ok {
+		combo.DisplayOrder = int(displayOrder)
+	}
+	combo.Items, _ = comboItemsFromData(data)
+	if len(combo.Items) == 0 {
+		return nil, fmt.Errorf("combo must have at least one product")
+	}
+
+	createdCombo, err := a.svc.CreateCombo(&combo)
+	if err != nil {
+		return nil, err
+	}
+	return toMap(createdCombo), nil
+}
+
+func (a *ComboMCPAdapter) UpdateCombo(id uint, data map[string]interface{}) (map[string]interface{}, error) {
+	// Get existing combo first for partial update
+	existingCombo, err := a.svc.GetCombo(id)
+	if err != nil {
+		return nil, fmt.Errorf("combo not found: %w", err)
+	}
+
+	// Apply only the fields that were provided in the update
+	if name, ok := data["name"].(string); ok {
+		existingCombo.Name = name
+	}
+	if description, ok := data["description"].(string); ok {
+		existingCombo.Description = description
+	}
+	if price, ok := data["price"].(float64); ok {
+		existingCombo.Price = price
+	}
+	if categoryID, ok := data["category_id"].(float64); ok {
+		if categoryID > 0 {
+			id := uint(categoryID)
+			existingCombo.CategoryID = &id
+		} else {
+			existingCombo.CategoryID = nil
+		}
+	}
+	if taxTypeID, ok := data["tax_type_id"].(float64); ok {
+		existingCombo.TaxTypeID = int(taxTypeID)
+	}
+	if displayOrder, ok := data["display_order"].(float64); ok {
+		existingCombo.DisplayOrder = int(displayOrder)
+	}
+	if isActive, ok := data["is_active"].(bool); ok {
+		existingCombo.IsActive = isActive
+	}
+	// UpdateCombo replaces the items, keep the current ones unless new ones were sent
+	if items, ok := comboItemsFromData(data); ok {
+		if len(items) == 0 {
+			return nil, fmt.Errorf("combo must have at least one product")
+		}
+		existingCombo.Items = items
+	}
+
+	updatedCombo, err := a.svc.UpdateCombo(existingCombo)
+	if err != nil {
+		return nil, err
+	}
+	return toMap(updatedCombo), nil
+}
+
+func (a *ComboMCPAdapter) DeleteCombo(id uint) error {
+	return a.svc.DeleteCombo(id)
+}
+
+func (a *ComboMCPAdapter) ToggleComboActive(id uint) (map[string]interface{}, error) {
+	combo, err := a.svc.ToggleComboActive(id)
+	if err != nil {
+		return nil, err
+	}
+	return toMap(combo), nil
+}
+
+// CustomPageMCPAdapter adapts CustomPageService to mcp.CustomPageServiceInterface
+type CustomPageMCPAdapter struct {
+	svc *CustomPageService
+}
+
+func NewCustomPageMCPAdapter(svc *CustomPageService) *CustomPageMCPAdapter {
+	return &CustomPageMCPAdapter{svc: svc}
+}
+
+func (a *CustomPageMCPAdapter) GetAllPages() ([]map[string]interface{}, error) {
+	pages, err := a.svc.GetAllPages()
+	if err != nil {
+		return nil, err
+	}
+	return toMapSlice(pages), nil
+}
+
+func (a *CustomPageMCPAdapter) GetPageProducts(pageID uint) ([]map[string]interface{}, error) {
+	if _, err := a.svc.GetPage(pageID); err != nil {
+		return nil, fmt.Errorf("custom page not found: %w", err)
+	}
+	products, err := a.svc.GetPageWithProducts(pageID)
+	if err != nil {
+		return nil, err
+	}
+	return toMapSlice(products), nil
+}
+
+func (a *CustomPageMCPAdapter) CreatePage(data map[string]interface{}) (map[string]interface{}, error) {
+	page := models.CustomPage{IsActive: true}
+	if name, ok := data["name"].(string); ok {
+		page.Name = strings.TrimSpace(name)
+	}
+	if page.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if description, ok := data["description"].(string); ok {
+		page.Description = description
+	}
+	if icon, ok := data["icon"].(string); ok {
+		page.Icon = icon
+	}
+	if color, ok := data["color"].(string); ok {
+		page.Color = color
+	}
+	if displayOrder, ok := data["display_order"].(float64); ok {
+		page.DisplayOrder = int(displayOrder)
+	}
+
+	if err := a.svc.CreatePage(&page); err != nil {
+		return nil, err
+	}
+	return toMap(page), nil
+}
+
+func (a *CustomPageMCPAdapter) UpdatePage(id uint, data map[string]interface{}) (map[string]interface{}, error) {
+	// Get existing page first for partial update
+	existingPage, err := a.svc.GetPage(id)
+	if err != nil {
+		return nil, fmt.Errorf("custom page not found: %w", err)
+	}
+
+	// Apply only the fields that were provided in the update
+	if name, ok := data["name"].(string); ok && strings.TrimSpace(name) != "" {
+		existingPage.Name = strings.TrimSpace(name)
+	}
+	if description, ok := data["description"].(string); ok {
+		existingPage.Description = description
+	}
+	if icon, ok := data["icon"].(string); ok {
+		existingPage.Icon = icon
+	}
+	if color, ok := data["color"].(string); ok {
+		existingPage.Color = color
+	}
+	if displayOrder, ok := data["display_order"].(float64); ok {
+		existingPage.DisplayOrder = int(displayOrder)
+	}
+	if isActive, ok := data["is_active"].(bool); ok {
+		existingPage.IsActive = isActive
+	}
+
+	if err := a.svc.UpdatePage(existingPage); err != nil {
+		return nil, err
+	}
+	return toMap(existingPage), nil
+}
+
+func (a *CustomPageMCPAdapter) DeletePage(id uint) error {
+	return a.svc.DeletePage(id)
+}
+
+func (a *CustomPageMCPAdapter) SetPageProducts(pageID uint, productIDs []uint) error {
+	if _, err := a.svc.GetPage(pageID); err != nil {
+		return fmt.Errorf("custom page not found: %w", err)
+	}
+	return a.svc.SetPageProducts(pageID, productIDs)
+}
+
+// DIANMCPAdapter adapts the electronic invoicing methods of SalesService to mcp.DIANServiceInterface
+type DIANMCPAdapter struct {
+	svc *SalesService
+}
+
+func NewDIANMCPAdapter(svc *SalesService) *DIANMCPAdapter {
+	return &DIANMCPAdapter{svc: svc}
+}
+
+// invoiceStatusMap returns the status fields of an electronic invoice
+// The XML, PDF, request and raw DIAN response are left out
+func invoiceStatusMap(invoice *models.ElectronicInvoice) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                    invoice.ID,
+		"sale_id":               invoice.SaleID,
+		"invoice_number":        invoice.InvoiceNumber,
+		"prefix":                invoice.Prefix,
+		"cufe":                  invoice.CUFE,
+		"status":                invoice.Status,
+		"is_valid":              invoice.IsValid,
+		"validation_message":    invoice.ValidationMessage,
+		"sent_at":               invoice.SentAt,
+		"accepted_at":           invoice.AcceptedAt,
+		"validation_checked_at": invoice.ValidationCheckedAt,
+		"retry_count":           invoice.RetryCount,
+		"last_error":            invoice.LastError,
+		"created_at":            invoice.CreatedAt,
+	}
+}
+
+func (a *DIANMCPAdapter) GetInvoiceStatus(saleID uint, refresh bool) (map[string]interface{}, error) {
+	if err := a.svc.EnsureDB(); err != nil {
+		return nil, err
+	}
+	var invoice models.ElectronicInvoice
+	if err := a.svc.db.Where("sale_id = ?", saleID).First(&invoice).Error; err != nil {
+		return nil, fmt.Errorf("sale %d has no electronic invoice", saleID)
+	}
+
+	var refreshError string
+	if refresh && invoice.ZipKey != "" {
+		if err := a.svc.invoiceSvc.CheckInvoiceStatus(invoice.ID); err != nil {
+			refreshError = err.Error()
+		} else {
+			a.svc.db.First(&invoice, invoice.ID)
+		}
+	}
+
+	result := invoiceStatusMap(&invoice)
+	if refreshError != "" {
+		result["refresh_error"] = refreshError
+	}
+	return result, nil
+}
+
+func (a *DIANMCPAdapter) GetInvoicesByStatus(status, from, to string) ([]map[string]interface{}, error) {
+	if err := a.svc.EnsureDB(); err != nil {
+		return nil, err
+	}
+	query := a.svc.db.Model(&models.ElectronicInvoice{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if from != "" {
+		fromDate, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from_date format: %w", err)
+		}
+		query = query.Where("created_at >= ?", fromDate)
+	}
+	if to != "" {
+		toDate, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to_date format: %w", err)
+		}
+		query = query.Where("created_at < ?", toDate.AddDate(0, 0, 1))
+	}
+
+	var invoices []models.ElectronicInvoice
+	if err := query.Order("created_at DESC").Limit(200).Find(&invoices).Error; err != nil {
+		return nil, err
+	}
+	result := make([]map[string]interface{}, 0, len(invoices))
+	for i := range invoices {
+		result = append(result, invoiceStatusMap(&invoices[i]))
+	}
+	return result, nil
+}
+
+func (a *DIANMCPAdapter) GetDIANClosingReport(date, period string) (map[string]interface{}, error) {
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	report, err := a.svc.GetDIANClosingReportWithPeriod(date, period)
+	if err != nil {
+		return nil, err
+	}
+	return toMap(report), nil
+}
+
+func (a *DIANMCPAdapter) GetDIANClosingReportRange(from, to string) (map[string]interface{}, error) {
+	report, err := a.svc.GetDIANClosingReportCustomRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return toMap(report), nil
+}
+
 // ========== Helper Functions ==========
 
 // Fields that commonly contain base64 images and should be excluded from MCP responses
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"PosApp/app/models"
+
+	"gorm.io/gorm"
+)
+
+// MockGatewayID is the gateway ID of the local training gateway
+const MockGatewayID = "mock"
+
+func init() {
+	RegisterPaymentGateway(MockGatewayID, "Simulador (entrenamiento)",
+		"Pasarela local que aprueba pagos sin cobrar, para capacitación y pruebas (se activa con payment_gateway_mock_enabled)", newMockGateway)
+}
+
+// mockGatewayConfigKey is the system configuration that turns on the training gateway
+const mockGatewayConfigKey = "payment_gateway_mock_enabled"
+
+// errMockGatewayDisabled is returned when a payment method or a sale uses the training gateway outside training
+var errMockGatewayDisabled = errors.New("el simulador de pagos solo se puede usar en modo de entrenamiento y nunca con la DIAN en producción")
+
+// mockGatewayAllowed reports whether training payments are turned on; never with DIAN in production,
+// where they would record real sales and invoices without charging anything
+func mockGatewayAllowed(db *gorm.DB) bool {
+	if db == nil {
+		return false
+	}
+	configs := &ConfigService{BaseService: &BaseService{db: db}}
+	if !configs.GetSystemConfigBool(mockGatewayConfigKey, false) {
+		return false
+	}
+	var environment string
+	if err := db.Model(&models.DIANConfig{}).Select("environment").Limit(1).Scan(&environment).Error; err != nil {
+		return false
+	}
+	return environment != "production"
+}
+
+// mockGatewayConfig is the per payment method configuration of the mock gateway
+type mockGatewayConfig struct {
+	ApprovalDelaySeconds int     `json:"approval_delay_seconds"` // Time the "customer" takes at the terminal
+	DeclineAbove         float64 `json:"decline_above"`          // Amounts above this are rejected (0 = never)
+}
+
+// mockTransactions keeps the training transactions in memory (they never reach a real acquirer)
+var (
+	mockTransactionsMu sync.Mutex
+	mockTransactions   = make(map[string]*mockTransaction)
+)
+
+type mockTransaction struct {
+	GatewayTransaction
+	CreatedAt time.Time
+	ResolveAt time.Time
+	Decline   bool
+}
+
+// mockGateway approves payments locally without charging anything
+type mockGateway struct {
+	db     *gorm.DB
+	config mockGatewayConfig
+}
+
+func newMockGateway(db *gorm.DB, config string) (PaymentGateway, error) {
+	gateway := &mockGateway{db: db}
+	if config != "" {
+		if err := json.Unmarshal([]byte(config), &gateway.config); err != nil {
+			return nil, fmt.Errorf("invalid mock gateway configuration: %w", err)
+		}
+	}
+	return gateway, nil
+}
+
+func (g *mockGateway) ID() string {
+	return MockGatewayID
+}
+
+func (g *mockGateway) Enabled() bool {
+	return mockGatewayAllowed(g.db)
+}
+
+func (g *mockGateway) CreatePayment(req GatewayPaymentRequest) (*GatewayTransaction, error) {
+	now := time.Now()
+	transactionID := fmt.Sprintf("MOCK%d", now.UnixNano())
+	transaction := &mockTransaction{
+		GatewayTransaction: GatewayTransaction{
+			GatewayID:     MockGatewayID,
+			TransactionID: transactionID,
+			Status:        GatewayStatusPending,
+			Amount:        req.Amount,
+			TipAmount:     req.TipAmount,
+			Reference:     fmt.Sprintf("Mock-%s | Entrenamiento", transactionID),
+			CardBrand:     "VISA",
+			CardMaskedPan: "************4242",
+		},
+		CreatedAt: now,
+		ResolveAt: now.Add(time.Duration(g.config.ApprovalDelaySeconds) * time.Second),
+		Decline:   g.config.DeclineAbove > 0 && req.Amount > g.config.DeclineAbove,
+	}
+
+	mockTransactionsMu.Lock()
+	mockTransactions[transactionID] = transaction
+	mockTransactionsMu.Unlock()
+
+	return g.GetPaymentStatus(transactionID)
+}
+
+func (g *mockGateway) GetPaymentStatus(transactionID string) (*GatewayTransaction, error) {
+	mockTransactionsMu.Lock()
+	defer mockTransactionsMu.Unlock()
+
+	transaction, exists := mockTransactions[transactionID]
+	if !exists {
+		return nil, fmt.Errorf("transacción de entrenamiento %s no encontrada", transactionID)
+	}
+	if transaction.Status == GatewayStatusPending && !time.Now().Before(transaction.ResolveAt) {
+		if transaction.Decline {
+			transaction.Status = GatewayStatusRejected
+			transaction.Message = "Transacción rechazada por el simulador"
+		} else {
+			transaction.Status = GatewayStatusApproved
+			transaction.ApprovalNumber = fmt.Sprintf("%06d", rand.Intn(1000000))
+		}
+	}
+	result := transaction.GatewayTransaction
+	return &result, nil
+}
+
+func (g *mockGateway) VoidPayment(req GatewayVoidRequest) (*GatewayTransaction, error) {
+	mockTransactionsMu.Lock()
+	defer mockTransactionsMu.Unlock()
+
+	transaction, exists := mockTransactions[req.TransactionID]
+	if !exists {
+		// Transactions are lost when the app restarts, training voids always succeed
+		transaction = &mockTransaction{GatewayTransaction: GatewayTransaction{
+			GatewayID:     MockGatewayID,
+			TransactionID: req.TransactionID,
+			Amount:        req.Amount,
+		}}
+		mockTransactions[req.TransactionID] = transaction
+	} else if transaction.Status != GatewayStatusApproved {
+		return nil, fmt.Errorf("la transacción %s no se puede anular (estado: %s)", req.TransactionID, transaction.Status)
+	}
+
+	transaction.Status = GatewayStatusVoided
+	transaction.Message = req.Reason
+	result := transaction.GatewayTransaction
+	if req.Amount > 0 {
+		result.Amount = req.Amount
+	}
+	return &result, nil
+}
+
+// ParseWebhook accepts GatewayWebhookEvent JSON, so training flows can simulate notifications
+func (g *mockGateway) ParseWebhook(body []byte, headers http.Header) (*GatewayWebhookEvent, error) {
+	var event GatewayWebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %w", err)
+	}
+	event.GatewayID = MockGatewayID
+	if event.TransactionID == "" {
+		return nil, fmt.Errorf("transaction_id is required")
+	}
+	return &event, nil
+}
+
+// ApplyWebhook resolves a training transaction as the simulated notification says
+func (g *mockGateway) ApplyWebhook(event *GatewayWebhookEvent, notifier WebSocketServer) error {
+	mockTransactionsMu.Lock()
+	defer mockTransactionsMu.Unlock()
+
+	transaction, exists := mockTransactions[event.TransactionID]
+	if !exists {
+		return fmt.Errorf("transacción de entrenamiento %s no encontrada", event.TransactionID)
+	}
+	switch event.Type {
+	case GatewayEventPaymentApproved:
+		transaction.Status = GatewayStatusApproved
+		if event.Transaction != nil && event.Transaction.ApprovalNumber != "" {
+			transaction.ApprovalNumber = event.Transaction.ApprovalNumber
+		}
+	case GatewayEventPaymentRejected:
+		transaction.Status = GatewayStatusRejected
+	case GatewayEventVoidApproved:
+		transaction.Status = GatewayStatusVoided
+	case GatewayEventVoidRejected:
+		transaction.Status = GatewayStatusApproved
+	default:
+		return fmt.Errorf("unknown event type: %s", event.Type)
+	}
+	result := transaction.GatewayTransaction
+	event.Transaction = &result
+	return nil
+}
+
+func (g *mockGateway) TransactionIDFromReference(reference string) string {
+	if !strings.HasPrefix(reference, "Mock-") {
+		return ""
+	}
+	id := strings.TrimPrefix(reference, "Mock-")
+	if idx := strings.Index(id, " "); idx >= 0 {
+		id = id[:idx]
+	}
+	return strings.TrimSpace(id)
+}
+
+func (g *mockGateway) LinkPaymentToSale(tx *gorm.DB, transactionID string, saleID, paymentID uint) error {
+	// Nothing to track outside the POS payment
+	return nil
+}
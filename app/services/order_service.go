@@ -94,6 +94,17 @@ func (s *OrderService) CreateOrder(order *models.Order) (*models.Order, error) {
 			return err
 		}
 
+		var creatorID *uint
+		if order.EmployeeID > 0 {
+			creatorID = &order.EmployeeID
+		}
+		if err := RecordOrderStatusEvent(tx, order.ID, "", order.Status, models.OrderStatusChange{
+			Source:     orderStatusSourceFor(order.Source),
+			EmployeeID: creatorID,
+		}); err != nil {
+			return err
+		}
+
 		if order.TableID != nil && *order.TableID > 0 {
 			if err := tx.Model(&models.Table{}).
 				Where("id = ?", *order.TableID).
@@ -280,6 +291,19 @@ func (s *OrderService) UpdateOrder(order *models.Order) (*models.Order, error) {
 			return fmt.Errorf("failed to update order: %w", err)
 		}
 
+		if order.Status != existingOrder.Status {
+			var editorID *uint
+			if order.EmployeeID > 0 {
+				editorID = &order.EmployeeID
+			}
+			if err := RecordOrderStatusEvent(tx, order.ID, existingOrder.Status, order.Status, models.OrderStatusChange{
+				Source:     orderStatusSourceFor(order.Source),
+				EmployeeID: editorID,
+			}); err != nil {
+				return err
+			}
+		}
+
 		// Set order_id for all new items
 		for i := range order.Items {
 			order.Items[i].OrderID = order.ID
@@ -359,6 +383,10 @@ func (s *OrderService) GetOrder(id uint) (*models.Order, error) {
 		Preload("Customer").
 		Preload("Employee").
 		Preload("OrderType").
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("StatusHistory.Employee").
 		First(&order, id).Error
 
 	// LOG: Verify OrderType was loaded
@@ -455,8 +483,15 @@ func (s *OrderService) GetOrdersByStatus(status models.OrderStatus) ([]models.Or
 	return orders, err
 }
 
-// UpdateOrderStatus updates order status
+// UpdateOrderStatus updates order status from the POS
 func (s *OrderService) UpdateOrderStatus(orderID uint, status models.OrderStatus) error {
+	return s.UpdateOrderStatusWithContext(orderID, status, models.OrderStatusChange{
+		Source: models.OrderStatusSourcePOS,
+	})
+}
+
+// UpdateOrderStatusWithContext updates order status and records who changed it, from where and why
+func (s *OrderService) UpdateOrderStatusWithContext(orderID uint, status models.OrderStatus, change models.OrderStatusChange) error {
 	order := &models.Order{}
 	if err := s.db.First(order, orderID).Error; err != nil {
 		return err
@@ -467,6 +502,7 @@ func (s *OrderService) UpdateOrderStatus(orderID uint, status models.OrderStatus
 		return fmt.Errorf("invalid status transition from '%s' to '%s'", order.Status, status)
 	}
 
+	previousStatus := order.Status
 	order.Status = status
 
 	// CRITICAL FIX: Free the table when order becomes paid or cancelled
@@ -481,13 +517,32 @@ func (s *OrderService) UpdateOrderStatus(orderID uint, status models.OrderStatus
 		}
 	}
 
-	// If order is ready, notify
+	// If order is ready, stamp item preparation time and notify
+	// Only items that were sent to the kitchen get a preparation time, so the
+	// kitchen performance report doesn't count items that were never cooked
 	if status == models.OrderStatusReady {
+		if err := s.db.Model(&models.OrderItem{}).
+			Where("order_id = ? AND sent_to_kitchen_at IS NOT NULL AND prepared_at IS NULL", orderID).
+			Updates(map[string]interface{}{
+				"prepared_at": time.Now(),
+				"status":      "ready",
+			}).Error; err != nil {
+			log.Printf("Warning: Failed to mark items of order %d as prepared: %v", orderID, err)
+		}
+
 		// Send notification through WebSocket
 		s.notifyOrderReady(order)
 	}
 
-	err := s.db.Save(order).Error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(order).Error; err != nil {
+			return err
+		}
+		if previousStatus == status {
+			return nil
+		}
+		return RecordOrderStatusEvent(tx, orderID, previousStatus, status, change)
+	})
 	if err != nil {
 		return err
 	}
@@ -662,9 +717,18 @@ func (s *OrderService) RemoveItemFromOrder(orderID uint, itemID uint) error {
 	})
 }
 
-// CancelOrder cancels an order
+// CancelOrder cancels an order from the POS
 func (s *OrderService) CancelOrder(orderID uint, reason string) error {
+	return s.CancelOrderWithContext(orderID, models.OrderStatusChange{
+		Source: models.OrderStatusSourcePOS,
+		Reason: reason,
+	})
+}
+
+// CancelOrderWithContext cancels an order and records who cancelled it, from where and why
+func (s *OrderService) CancelOrderWithContext(orderID uint, change models.OrderStatusChange) error {
 	var freedTableID *uint
+	reason := change.Reason
 
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Get order with items
@@ -699,10 +763,15 @@ func (s *OrderService) CancelOrder(orderID uint, reason string) error {
 		}
 
 		// Update order status
+		previousStatus := order.Status
 		order.Status = models.OrderStatusCancelled
 		order.Notes = fmt.Sprintf("Cancelled: %s", reason)
 
-		return tx.Save(&order).Error
+		if err := tx.Save(&order).Error; err != nil {
+			return err
+		}
+
+		return RecordOrderStatusEvent(tx, order.ID, previousStatus, models.OrderStatusCancelled, change)
 	})
 
 	if err != nil {
@@ -1279,6 +1348,73 @@ func (s *OrderService) DeleteOrder(orderID uint) error {
 	return nil
 }
 
+// GetOrderStatusHistory gets the status transitions of an order in chronological order
+func (s *OrderService) GetOrderStatusHistory(orderID uint) ([]models.OrderStatusEvent, error) {
+	var events []models.OrderStatusEvent
+	err := s.db.Preload("Employee").
+		Where("order_id = ?", orderID).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error
+	return events, err
+}
+
+// RecordOrderStatusEvent stores an order status transition.
+// It is shared with services that change order status inside their own transactions (e.g. sales).
+func RecordOrderStatusEvent(tx *gorm.DB, orderID uint, from, to models.OrderStatus, change models.OrderStatusChange) error {
+	source := change.Source
+	if source == "" {
+		source = models.OrderStatusSourceSystem
+	}
+
+	event := models.OrderStatusEvent{
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		EmployeeID: change.EmployeeID,
+		Actor:      change.Actor,
+		Source:     source,
+		Reason:     change.Reason,
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return fmt.Errorf("failed to record status change: %w", err)
+	}
+	return nil
+}
+
+// UpdateOrderWithStatusEvent saves an order replacing its items, and records the
+// status transition from previousStatus, in a single transaction
+func (s *OrderService) UpdateOrderWithStatusEvent(order *models.Order, previousStatus models.OrderStatus, change models.OrderStatusChange) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Delete old modifiers first (to avoid foreign key constraint violation)
+		if err := tx.Exec("DELETE FROM order_item_modifiers WHERE order_item_id IN (SELECT id FROM order_items WHERE order_id = ?)", order.ID).Error; err != nil {
+			return fmt.Errorf("error deleting old modifiers: %w", err)
+		}
+		if err := tx.Where("order_id = ?", order.ID).Delete(&models.OrderItem{}).Error; err != nil {
+			return fmt.Errorf("error deleting old items: %w", err)
+		}
+		if err := tx.Save(order).Error; err != nil {
+			return err
+		}
+
+		if order.Status == "" || order.Status == previousStatus {
+			return nil
+		}
+		return RecordOrderStatusEvent(tx, order.ID, previousStatus, order.Status, change)
+	})
+}
+
+// orderStatusSourceFor maps an order's Source field to a status change source
+func orderStatusSourceFor(orderSource string) string {
+	switch orderSource {
+	case "waiter_app":
+		return models.OrderStatusSourceWaiter
+	case "", "pos", "split":
+		return models.OrderStatusSourcePOS
+	default:
+		return orderSource
+	}
+}
+
 // expandCombosInOrder expands combo items into individual products
 // This allows combos to be displayed as single items in POS/invoice but expanded for kitchen
 func (s *OrderService) expandCombosInOrder(items []models.OrderItem) []models.OrderItem {
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"PosApp/app/database"
+	"PosApp/app/models"
+
+	"gorm.io/gorm"
+)
+
+// Gateway transaction statuses
+const (
+	GatewayStatusPending     = "pending"
+	GatewayStatusApproved    = "approved"
+	GatewayStatusRejected    = "rejected"
+	GatewayStatusCancelled   = "cancelled"
+	GatewayStatusVoidPending = "void_pending"
+	GatewayStatusVoided      = "voided"
+)
+
+// Gateway webhook event types
+const (
+	GatewayEventPaymentApproved = "payment_approved"
+	GatewayEventPaymentRejected = "payment_rejected"
+	GatewayEventVoidApproved    = "void_approved"
+	GatewayEventVoidRejected    = "void_rejected"
+)
+
+// PaymentGateway is a card acquirer or payment provider the POS can charge through
+// (Bold, Wompi, Redeban, Credibanco, the training mock, ...)
+type PaymentGateway interface {
+	// ID returns the gateway identifier referenced by PaymentMethod.GatewayID
+	ID() string
+	// Enabled reports whether the gateway is configured and can process payments
+	Enabled() bool
+	// CreatePayment starts a payment (usually pending until the terminal or the customer completes it)
+	CreatePayment(req GatewayPaymentRequest) (*GatewayTransaction, error)
+	// GetPaymentStatus returns the current state of a transaction
+	GetPaymentStatus(transactionID string) (*GatewayTransaction, error)
+	// VoidPayment voids or refunds (partially) an approved transaction
+	VoidPayment(req GatewayVoidRequest) (*GatewayTransaction, error)
+	// ParseWebhook verifies and parses a notification sent by the gateway
+	// A bad or missing signature is reported wrapping ErrGatewayWebhookSignature
+	ParseWebhook(body []byte, headers http.Header) (*GatewayWebhookEvent, error)
+	// ApplyWebhook updates the transactions of the gateway with a parsed notification
+	ApplyWebhook(event *GatewayWebhookEvent, notifier WebSocketServer) error
+	// TransactionIDFromReference extracts the transaction ID from a POS payment reference ("" if not from this gateway)
+	TransactionIDFromReference(reference string) string
+	// LinkPaymentToSale records which sale and POS payment completed a transaction
+	LinkPaymentToSale(tx *gorm.DB, transactionID string, saleID, paymentID uint) error
+}
+
+// GatewayPaymentRequest represents a payment to create through a gateway
+type GatewayPaymentRequest struct {
+	PaymentMethodID uint    `json:"payment_method_id"`
+	Amount          float64 `json:"amount"`     // Total to charge, including the tip
+	TipAmount       float64 `json:"tip_amount"` // Tip included in Amount
+	TaxAmount       float64 `json:"tax_amount,omitempty"`
+	Reference       string  `json:"reference"`
+	Description     string  `json:"description,omitempty"`
+	PayerEmail      string  `json:"payer_email,omitempty"`
+	OrderID         uint    `json:"order_id,omitempty"`
+	CustomerID      uint    `json:"customer_id,omitempty"`
+	EmployeeID      uint    `json:"employee_id,omitempty"`
+	CashRegisterID  uint    `json:"cash_register_id,omitempty"`
+}
+
+// GatewayVoidRequest represents a void or refund of an approved transaction
+type GatewayVoidRequest struct {
+	TransactionID  string  `json:"transaction_id"`
+	Amount         float64 `json:"amount"` // 0 = full amount
+	Reason         string  `json:"reason"`
+	EmployeeID     uint    `json:"employee_id,omitempty"`
+	CashRegisterID uint    `json:"cash_register_id,omitempty"`
+}
+
+// GatewayTransaction represents the state of a transaction at the gateway
+type GatewayTransaction struct {
+	GatewayID      string  `json:"gateway_id"`
+	TransactionID  string  `json:"transaction_id"`
+	Status         string  `json:"status"` // pending, approved, rejected, cancelled, void_pending, voided
+	Amount         float64 `json:"amount"`
+	TipAmount      float64 `json:"tip_amount"`
+	Reference      string  `json:"reference"` // Reference to store in the POS payment
+	ApprovalNumber string  `json:"approval_number,omitempty"`
+	CardBrand      string  `json:"card_brand,omitempty"`
+	CardMaskedPan  string  `json:"card_masked_pan,omitempty"`
+	Message        string  `json:"message,omitempty"`
+}
+
+// ErrGatewayWebhookSignature is returned by ParseWebhook for notifications that can't be authenticated
+var ErrGatewayWebhookSignature = errors.New("invalid webhook signature")
+
+// GatewayWebhookEvent represents a parsed gateway notification
+type GatewayWebhookEvent struct {
+	GatewayID     string              `json:"gateway_id"`
+	Type          string              `json:"type"` // payment_approved, payment_rejected, void_approved, void_rejected
+	TransactionID string              `json:"transaction_id"`
+	Transaction   *GatewayTransaction `json:"transaction,omitempty"`
+	Raw           interface{}         `json:"-"` // Gateway-specific notification
+}
+
+// PaymentGatewayFactory builds a gateway from the configuration stored in the payment method (JSON)
+type PaymentGatewayFactory func(db *gorm.DB, config string) (PaymentGateway, error)
+
+// PaymentGatewayInfo describes a registered gateway
+type PaymentGatewayInfo struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+}
+
+type paymentGatewayEntry struct {
+	info    PaymentGatewayInfo
+	factory PaymentGatewayFactory
+}
+
+var (
+	paymentGatewaysMu sync.RWMutex
+	paymentGateways   = make(map[string]paymentGatewayEntry)
+)
+
+// RegisterPaymentGateway makes a gateway available to payment methods
+func RegisterPaymentGateway(id, name, description string, factory PaymentGatewayFactory) {
+	paymentGatewaysMu.Lock()
+	defer paymentGatewaysMu.Unlock()
+	paymentGateways[id] = paymentGatewayEntry{
+		info:    PaymentGatewayInfo{ID: id, Name: name, Description: description},
+		factory: factory,
+	}
+}
+
+// NewPaymentGateway builds the gateway registered with the given ID
+func NewPaymentGateway(db *gorm.DB, id, config string) (PaymentGateway, error) {
+	paymentGatewaysMu.RLock()
+	entry, exists := paymentGateways[id]
+	paymentGatewaysMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("pasarela de pago '%s' no registrada", id)
+	}
+	return entry.factory(db, config)
+}
+
+// PaymentGatewayForMethod returns the gateway of a payment method (nil if it doesn't use one)
+func PaymentGatewayForMethod(db *gorm.DB, method *models.PaymentMethod) (PaymentGateway, error) {
+	gatewayID := paymentMethodGatewayID(method)
+	if gatewayID == "" {
+		return nil, nil
+	}
+	return NewPaymentGateway(db, gatewayID, method.GatewayConfig)
+}
+
+// paymentMethodGatewayID returns the gateway of a payment method
+// Methods created before gateways existed only have UseBoldTerminal
+func paymentMethodGatewayID(method *models.PaymentMethod) string {
+	if method == nil {
+		return ""
+	}
+	if method.GatewayID != "" {
+		return method.GatewayID
+	}
+	if method.UseBoldTerminal {
+		return BoldGatewayID
+	}
+	return ""
+}
+
+// validatePaymentMethodGateway rejects payment methods with an unknown gateway, and active
+// methods on the training gateway outside training
+func validatePaymentMethodGateway(db *gorm.DB, method *models.PaymentMethod) error {
+	gateway, err := PaymentGatewayForMethod(db, method)
+	if err != nil {
+		return err
+	}
+	if gateway != nil && method.IsActive && gateway.ID() == MockGatewayID && !gateway.Enabled() {
+		return errMockGatewayDisabled
+	}
+	return nil
+}
+
+// PaymentGatewayService exposes the registered gateways to the frontend
+type PaymentGatewayService struct {
+	*BaseService
+}
+
+// NewPaymentGatewayService creates a new payment gateway service
+func NewPaymentGatewayService() *PaymentGatewayService {
+	return &PaymentGatewayService{
+		BaseService: &BaseService{db: database.GetDB()},
+	}
+}
+
+// GetPaymentGateways lists the registered gateways
+func (s *PaymentGatewayService) GetPaymentGateways() []PaymentGatewayInfo {
+	paymentGatewaysMu.RLock()
+	entries := make([]paymentGatewayEntry, 0, len(paymentGateways))
+	for _, entry := range paymentGateways {
+		entries = append(entries, entry)
+	}
+	paymentGatewaysMu.RUnlock()
+
+	infos := make([]PaymentGatewayInfo, 0, len(entries))
+	for _, entry := range entries {
+		info := entry.info
+		if s.db != nil {
+			if gateway, err := entry.factory(s.db, ""); err == nil {
+				info.Enabled = gateway.Enabled()
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// CreateGatewayPayment starts a payment through the gateway of a payment method
+func (s *PaymentGatewayService) CreateGatewayPayment(req GatewayPaymentRequest) (*GatewayTransaction, error) {
+	gateway, err := s.methodGateway(req.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+	if !gateway.Enabled() {
+		return nil, fmt.Errorf("la pasarela de pago '%s' no está habilitada", gateway.ID())
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("payment amount must be greater than 0")
+	}
+	return gateway.CreatePayment(req)
+}
+
+// GetGatewayPaymentStatus returns the status of a transaction of a payment method's gateway
+func (s *PaymentGatewayService) GetGatewayPaymentStatus(paymentMethodID uint, transactionID string) (*GatewayTransaction, error) {
+	gateway, err := s.methodGateway(paymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+	return gateway.GetPaymentStatus(transactionID)
+}
+
+// VoidGatewayPayment voids a transaction of a payment method's gateway
+func (s *PaymentGatewayService) VoidGatewayPayment(paymentMethodID uint, req GatewayVoidRequest) (*GatewayTransaction, error) {
+	gateway, err := s.methodGateway(paymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+	return gateway.VoidPayment(req)
+}
+
+func (s *PaymentGatewayService) methodGateway(paymentMethodID uint) (PaymentGateway, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	var method models.PaymentMethod
+	if err := s.db.First(&method, paymentMethodID).Error; err != nil {
+		return nil, fmt.Errorf("payment method ID %d not found", paymentMethodID)
+	}
+	gateway, err := PaymentGatewayForMethod(s.db, &method)
+	if err != nil {
+		return nil, err
+	}
+	if gateway == nil {
+		return nil, fmt.Errorf("el método de pago '%s' no usa pasarela de pago", method.Name)
+	}
+	return gateway, nil
+}
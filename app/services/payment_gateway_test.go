@@ -0,0 +1,213 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"PosApp/app/models"
+)
+
+func TestNewPaymentGateway(t *testing.T) {
+	tests := []struct {
+		id      string
+		config  string
+		wantErr bool
+	}{
+		{BoldGatewayID, "", false},
+		{MockGatewayID, `{"approval_delay_seconds": 2}`, false},
+		{MockGatewayID, `{not json`, true},
+		{"wompi", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		gateway, err := NewPaymentGateway(nil, tt.id, tt.config)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewPaymentGateway(%q, %q) error = %v, wantErr %v", tt.id, tt.config, err, tt.wantErr)
+			continue
+		}
+		if err == nil && gateway.ID() != tt.id {
+			t.Errorf("NewPaymentGateway(%q).ID() = %q", tt.id, gateway.ID())
+		}
+	}
+}
+
+func TestPaymentMethodGatewayID(t *testing.T) {
+	tests := []struct {
+		name   string
+		method *models.PaymentMethod
+		want   string
+	}{
+		{"no method", nil, ""},
+		{"plain cash", &models.PaymentMethod{Name: "Efectivo"}, ""},
+		{"explicit gateway", &models.PaymentMethod{GatewayID: MockGatewayID}, MockGatewayID},
+		{"legacy Bold terminal", &models.PaymentMethod{UseBoldTerminal: true}, BoldGatewayID},
+		{"gateway wins over the legacy flag", &models.PaymentMethod{GatewayID: MockGatewayID, UseBoldTerminal: true}, MockGatewayID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paymentMethodGatewayID(tt.method); got != tt.want {
+				t.Errorf("paymentMethodGatewayID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaymentGatewayForMethod(t *testing.T) {
+	gateway, err := PaymentGatewayForMethod(nil, &models.PaymentMethod{Name: "Efectivo"})
+	if err != nil || gateway != nil {
+		t.Errorf("method without gateway = (%v, %v), want (nil, nil)", gateway, err)
+	}
+	if _, err := PaymentGatewayForMethod(nil, &models.PaymentMethod{GatewayID: "wompi"}); err == nil {
+		t.Error("unregistered gateway should fail")
+	}
+	gateway, err = PaymentGatewayForMethod(nil, &models.PaymentMethod{UseBoldTerminal: true})
+	if err != nil || gateway == nil || gateway.ID() != BoldGatewayID {
+		t.Errorf("legacy Bold method = (%v, %v), want the Bold gateway", gateway, err)
+	}
+}
+
+func TestValidatePaymentMethodGatewayRejectsMockOutsideTraining(t *testing.T) {
+	method := &models.PaymentMethod{GatewayID: MockGatewayID, IsActive: true}
+	if err := validatePaymentMethodGateway(nil, method); !errors.Is(err, errMockGatewayDisabled) {
+		t.Errorf("active mock method error = %v, want errMockGatewayDisabled", err)
+	}
+	method.IsActive = false
+	if err := validatePaymentMethodGateway(nil, method); err != nil {
+		t.Errorf("inactive mock method error = %v, want nil", err)
+	}
+	if mockGatewayAllowed(nil) {
+		t.Error("mock gateway allowed without a database")
+	}
+}
+
+func TestTransactionIDFromReference(t *testing.T) {
+	bold, _ := NewPaymentGateway(nil, BoldGatewayID, "")
+	mock, _ := NewPaymentGateway(nil, MockGatewayID, "")
+	tests := []struct {
+		gateway   PaymentGateway
+		reference string
+		want      string
+	}{
+		{bold, "Bold-ABC123 | Visa ****4242", "ABC123"},
+		{bold, "Bold-ABC123", "ABC123"},
+		{bold, "Mock-MOCK1 | Entrenamiento", ""},
+		{mock, "Mock-MOCK1 | Entrenamiento", "MOCK1"},
+		{mock, "Bold-ABC123", ""},
+		{mock, "", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.gateway.TransactionIDFromReference(tt.reference); got != tt.want {
+			t.Errorf("%s.TransactionIDFromReference(%q) = %q, want %q", tt.gateway.ID(), tt.reference, got, tt.want)
+		}
+	}
+}
+
+func TestMockGatewayRoundTrip(t *testing.T) {
+	gateway, err := NewPaymentGateway(nil, MockGatewayID, `{"decline_above": 100000}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	approved, err := gateway.CreatePayment(GatewayPaymentRequest{Amount: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approved.Status != GatewayStatusApproved || approved.ApprovalNumber == "" {
+		t.Errorf("payment below the limit = %s (approval %q), want approved", approved.Status, approved.ApprovalNumber)
+	}
+	if got := gateway.TransactionIDFromReference(approved.Reference); got != approved.TransactionID {
+		t.Errorf("reference %q resolves to %q, want %q", approved.Reference, got, approved.TransactionID)
+	}
+
+	declined, err := gateway.CreatePayment(GatewayPaymentRequest{Amount: 150000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if declined.Status != GatewayStatusRejected {
+		t.Errorf("payment above the limit = %s, want rejected", declined.Status)
+	}
+	if _, err := gateway.VoidPayment(GatewayVoidRequest{TransactionID: declined.TransactionID}); err == nil {
+		t.Error("voiding a rejected payment should fail")
+	}
+
+	voided, err := gateway.VoidPayment(GatewayVoidRequest{TransactionID: approved.TransactionID, Reason: "Devolución"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if voided.Status != GatewayStatusVoided {
+		t.Errorf("voided payment = %s, want voided", voided.Status)
+	}
+	status, err := gateway.GetPaymentStatus(approved.TransactionID)
+	if err != nil || status.Status != GatewayStatusVoided {
+		t.Errorf("status after void = (%v, %v), want voided", status, err)
+	}
+}
+
+func TestMockGatewayWebhook(t *testing.T) {
+	gateway, _ := NewPaymentGateway(nil, MockGatewayID, `{"approval_delay_seconds": 300}`)
+	pending, err := gateway.CreatePayment(GatewayPaymentRequest{Amount: 20000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending.Status != GatewayStatusPending {
+		t.Fatalf("delayed payment = %s, want pending", pending.Status)
+	}
+
+	if _, err := gateway.ParseWebhook([]byte(`{"type": "payment_approved"}`), nil); err == nil {
+		t.Error("webhook without transaction_id should fail")
+	}
+	if _, err := gateway.ParseWebhook([]byte(`not json`), nil); err == nil {
+		t.Error("invalid webhook body should fail")
+	}
+
+	body := []byte(`{"gateway_id": "bold", "type": "payment_approved", "transaction_id": "` + pending.TransactionID + `"}`)
+	event, err := gateway.ParseWebhook(body, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.GatewayID != MockGatewayID {
+		t.Errorf("event gateway = %q, want %q", event.GatewayID, MockGatewayID)
+	}
+	if err := gateway.ApplyWebhook(event, nil); err != nil {
+		t.Fatal(err)
+	}
+	if event.Transaction == nil || event.Transaction.Status != GatewayStatusApproved {
+		t.Errorf("transaction after webhook = %+v, want approved", event.Transaction)
+	}
+
+	unknown := &GatewayWebhookEvent{Type: GatewayEventPaymentApproved, TransactionID: "MOCK0"}
+	if err := gateway.ApplyWebhook(unknown, nil); err == nil {
+		t.Error("webhook for an unknown transaction should fail")
+	}
+}
+
+func TestVerifyBoldSignature(t *testing.T) {
+	body := []byte(`{"type":"SALE_APPROVED"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(base64.StdEncoding.EncodeToString(body)))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+		secret    string
+		want      bool
+	}{
+		{"valid", body, signature, "secret", true},
+		{"wrong secret", body, signature, "other", false},
+		{"tampered body", []byte(`{"type":"VOID_APPROVED"}`), signature, "secret", false},
+		{"missing signature", body, "", "secret", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyBoldSignature(tt.body, tt.signature, tt.secret); got != tt.want {
+				t.Errorf("verifyBoldSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
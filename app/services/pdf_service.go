@@ -0,0 +1,1137 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"PosApp/app/config"
+	"PosApp/app/database"
+	"PosApp/app/models"
+)
+
+// PDFService renders electronic invoices, credit/debit notes and closing reports as PDF
+// documents, so they can be emailed, archived and reprinted without a thermal printer
+// or a round-trip to the DIAN provider
+type PDFService struct {
+	*BaseService
+	salesSvc *SalesService
+}
+
+// PDFDocumentFile is a rendered PDF document
+type PDFDocumentFile struct {
+	FileName string `json:"file_name"`
+	Path     string `json:"path"` // Archived copy on disk
+	Data     string `json:"data"` // Base64 encoded PDF
+}
+
+// NewPDFService creates a new PDF service
+func NewPDFService() *PDFService {
+	return &PDFService{
+		BaseService: &BaseService{db: database.GetDB()},
+		salesSvc:    NewSalesService(),
+	}
+}
+
+// GetPDFArchiveDirectory returns the folder where generated PDFs are archived
+func (s *PDFService) GetPDFArchiveDirectory() (string, error) {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "documents"), nil
+}
+
+// GenerateInvoicePDF renders the electronic invoice (or POS equivalent document) of a sale
+// and archives a copy. It is stored as the invoice PDF document only when the DIAN provider
+// did not return one, so the provider's PDF is never replaced
+func (s *PDFService) GenerateInvoicePDF(saleID uint) (*PDFDocumentFile, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	pdf, sale, err := s.RenderInvoicePDF(saleID)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(pdf)
+	if err := s.db.Model(&models.ElectronicInvoice{}).
+		Where("id = ? AND (pdf_document IS NULL OR pdf_document = '')", sale.ElectronicInvoice.ID).
+		Update("pdf_document", encoded).Error; err != nil {
+		log.Printf("Warning: Failed to store PDF of invoice %s%s: %v", sale.ElectronicInvoice.Prefix, sale.ElectronicInvoice.InvoiceNumber, err)
+	}
+
+	return s.archivePDF(pdf, "facturas", invoicePDFFileName(sale.ElectronicInvoice), sale.CreatedAt)
+}
+
+// RenderInvoicePDF renders the electronic invoice of a sale and returns the PDF with the loaded sale
+func (s *PDFService) RenderInvoicePDF(saleID uint) ([]byte, *models.Sale, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, nil, err
+	}
+
+	var sale models.Sale
+	err := s.db.Preload("Customer").
+		Preload("Order.Items.Product").
+		Preload("Order.Items.Modifiers.Modifier").
+		Preload("PaymentDetails.PaymentMethod").
+		Preload("Employee").
+		Preload("ElectronicInvoice").
+		First(&sale, saleID).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("venta no encontrada")
+	}
+	if sale.ElectronicInvoice == nil {
+		return nil, nil, fmt.Errorf("la venta %s no tiene factura electrónica", sale.SaleNumber)
+	}
+
+	pdf, err := s.renderInvoice(&sale)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pdf, &sale, nil
+}
+
+// GenerateCreditNotePDF renders a credit note and archives a copy
+func (s *PDFService) GenerateCreditNotePDF(creditNoteID uint) (*PDFDocumentFile, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var note models.CreditNote
+	if err := s.db.Preload("ElectronicInvoice").First(&note, creditNoteID).Error; err != nil {
+		return nil, fmt.Errorf("nota crédito no encontrada")
+	}
+
+	pdf, err := s.renderNote(pdfNote{
+		Title:           "NOTA CRÉDITO ELECTRÓNICA",
+		Label:           "Nota crédito",
+		Prefix:          note.Prefix,
+		Number:          note.Number,
+		CUDE:            note.UUID,
+		Reason:          note.Reason,
+		DiscrepancyCode: note.DiscrepancyCode,
+		Discrepancies:   models.GetDIANParametricData().CreditNoteDiscrepancies,
+		Amount:          note.Amount,
+		Status:          note.Status,
+		CreatedAt:       note.CreatedAt,
+		Invoice:         note.ElectronicInvoice,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.archivePDF(pdf, "notas", fmt.Sprintf("NC-%s%s.pdf", note.Prefix, note.Number), note.CreatedAt)
+}
+
+// GenerateDebitNotePDF renders a debit note and archives a copy
+func (s *PDFService) GenerateDebitNotePDF(debitNoteID uint) (*PDFDocumentFile, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var note models.DebitNote
+	if err := s.db.Preload("ElectronicInvoice").First(&note, debitNoteID).Error; err != nil {
+		return nil, fmt.Errorf("nota débito no encontrada")
+	}
+
+	pdf, err := s.renderNote(pdfNote{
+		Title:           "NOTA DÉBITO ELECTRÓNICA",
+		Label:           "Nota débito",
+		Prefix:          note.Prefix,
+		Number:          note.Number,
+		CUDE:            note.UUID,
+		Reason:          note.Reason,
+		DiscrepancyCode: note.DiscrepancyCode,
+		Discrepancies:   models.GetDIANParametricData().DebitNoteDiscrepancies,
+		Amount:          note.Amount,
+		Status:          note.Status,
+		CreatedAt:       note.CreatedAt,
+		Invoice:         note.ElectronicInvoice,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.archivePDF(pdf, "notas", fmt.Sprintf("ND-%s%s.pdf", note.Prefix, note.Number), note.CreatedAt)
+}
+
+// GenerateDIANClosingReportPDF renders the DIAN closing report of a period ("daily", "weekly", "monthly", "yearly")
+func (s *PDFService) GenerateDIANClosingReportPDF(dateStr string, period string) (*PDFDocumentFile, error) {
+	report, err := s.salesSvc.GetDIANClosingReportWithPeriod(dateStr, period)
+	if err != nil {
+		return nil, err
+	}
+	return s.generateDIANClosingReportPDF(report, period)
+}
+
+// GenerateDIANClosingReportCustomRangePDF renders the DIAN closing report of a custom date range
+func (s *PDFService) GenerateDIANClosingReportCustomRangePDF(startDateStr string, endDateStr string) (*PDFDocumentFile, error) {
+	report, err := s.salesSvc.GetDIANClosingReportCustomRange(startDateStr, endDateStr)
+	if err != nil {
+		return nil, err
+	}
+	return s.generateDIANClosingReportPDF(report, "custom")
+}
+
+func (s *PDFService) generateDIANClosingReportPDF(report *DIANClosingReport, period string) (*PDFDocumentFile, error) {
+	pdf, err := s.renderDIANClosingReport(report, period)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := fmt.Sprintf("cierre-dian-%s-%s.pdf", period, report.ReportDate)
+	if report.ReportEndDate != "" && report.ReportEndDate != report.ReportDate {
+		fileName = fmt.Sprintf("cierre-dian-%s-%s_%s.pdf", period, report.ReportDate, report.ReportEndDate)
+	}
+	return s.archivePDF(pdf, "cierres", fileName, report.GeneratedAt)
+}
+
+// GenerateCashRegisterReportPDF renders a stored cash register closing report
+func (s *PDFService) GenerateCashRegisterReportPDF(reportID uint) (*PDFDocumentFile, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var report models.CashRegisterReport
+	if err := s.db.Preload("Employee").Preload("BoldReconciliation").First(&report, reportID).Error; err != nil {
+		return nil, fmt.Errorf("reporte de caja no encontrado")
+	}
+	return s.generateCashRegisterReportPDF(&report)
+}
+
+// GenerateCashRegisterClosingPDF renders the closing report of a closed cash register
+func (s *PDFService) GenerateCashRegisterClosingPDF(cashRegisterID uint) (*PDFDocumentFile, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var report models.CashRegisterReport
+	err := s.db.Preload("Employee").Preload("BoldReconciliation").
+		Where("cash_register_id = ?", cashRegisterID).
+		Order("created_at DESC").
+		First(&report).Error
+	if err != nil {
+		return nil, fmt.Errorf("la caja no tiene reporte de cierre")
+	}
+	return s.generateCashRegisterReportPDF(&report)
+}
+
+// GenerateLastCashRegisterReportPDF renders the last cash register closing report of an employee
+func (s *PDFService) GenerateLastCashRegisterReportPDF(employeeID uint) (*PDFDocumentFile, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var report models.CashRegisterReport
+	err := s.db.Preload("Employee").Preload("BoldReconciliation").
+		Where("generated_by = ?", employeeID).
+		Order("created_at DESC").
+		First(&report).Error
+	if err != nil {
+		return nil, fmt.Errorf("no hay cierres de caja para este empleado")
+	}
+	return s.generateCashRegisterReportPDF(&report)
+}
+
+func (s *PDFService) generateCashRegisterReportPDF(report *models.CashRegisterReport) (*PDFDocumentFile, error) {
+	pdf, err := s.renderCashRegisterReport(report)
+	if err != nil {
+		return nil, err
+	}
+	fileName := fmt.Sprintf("cierre-caja-%d-%s.pdf", report.CashRegisterID, report.Date.Format("2006-01-02"))
+	return s.archivePDF(pdf, "cierres", fileName, report.CreatedAt)
+}
+
+// archivePDF writes the PDF to documents/<kind>/<yyyy-mm>/ and returns it encoded
+// A failure to archive is logged but never prevents returning the document
+func (s *PDFService) archivePDF(pdf []byte, kind, fileName string, date time.Time) (*PDFDocumentFile, error) {
+	fileName = pdfFileNameSanitizer.ReplaceAllString(fileName, "_")
+	file := &PDFDocumentFile{
+		FileName: fileName,
+		Data:     base64.StdEncoding.EncodeToString(pdf),
+	}
+
+	if date.IsZero() {
+		date = time.Now()
+	}
+	baseDir, err := s.GetPDFArchiveDirectory()
+	if err != nil {
+		log.Printf("Warning: Failed to resolve PDF archive directory: %v", err)
+		return file, nil
+	}
+	dir := filepath.Join(baseDir, kind, date.Format("2006-01"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("Warning: Failed to create PDF archive directory: %v", err)
+		return file, nil
+	}
+	path := filepath.Join(dir, fileName)
+	if err := os.WriteFile(path, pdf, 0644); err != nil {
+		log.Printf("Warning: Failed to archive PDF %s: %v", fileName, err)
+		return file, nil
+	}
+	file.Path = path
+	return file, nil
+}
+
+var pdfFileNameSanitizer = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// invoicePDFFileName returns the file name of the PDF of an electronic invoice
+func invoicePDFFileName(invoice *models.ElectronicInvoice) string {
+	kind := "FE"
+	if invoice.DocumentType == "pos_equivalent" {
+		kind = "POS"
+	} else if invoice.IsContingency {
+		kind = "FC"
+	}
+	return fmt.Sprintf("%s-%s%s.pdf", kind, invoice.Prefix, invoice.InvoiceNumber)
+}
+
+// ==================== DOCUMENT HEADER ====================
+
+// pdfResolution is the numbering resolution printed on a document
+type pdfResolution struct {
+	Label    string
+	Number   string
+	Prefix   string
+	From     int
+	To       int
+	DateFrom time.Time
+	DateTo   time.Time
+}
+
+// invoiceResolution returns the resolution that applies to the invoice:
+// POS equivalent documents and contingency invoices use their own ranges
+func invoiceResolution(dianConfig *models.DIANConfig, invoice *models.ElectronicInvoice) pdfResolution {
+	if invoice.DocumentType == "pos_equivalent" {
+		return pdfResolution{
+			Label:    "Resolución Documento Equivalente POS",
+			Number:   dianConfig.POSResolutionNumber,
+			Prefix:   dianConfig.POSResolutionPrefix,
+			From:     dianConfig.POSResolutionFrom,
+			To:       dianConfig.POSResolutionTo,
+			DateFrom: dianConfig.POSResolutionDateFrom,
+			DateTo:   dianConfig.POSResolutionDateTo,
+		}
+	}
+	if invoice.IsContingency {
+		return pdfResolution{
+			Label:    "Resolución Facturación de Contingencia",
+			Number:   dianConfig.ContingencyResolutionNumber,
+			Prefix:   dianConfig.ContingencyResolutionPrefix,
+			From:     dianConfig.ContingencyResolutionFrom,
+			To:       dianConfig.ContingencyResolutionTo,
+			DateFrom: dianConfig.ContingencyResolutionDateFrom,
+			DateTo:   dianConfig.ContingencyResolutionDateTo,
+		}
+	}
+	return pdfResolution{
+		Label:    "Resolución de Facturación Electrónica",
+		Number:   dianConfig.ResolutionNumber,
+		Prefix:   dianConfig.ResolutionPrefix,
+		From:     dianConfig.ResolutionFrom,
+		To:       dianConfig.ResolutionTo,
+		DateFrom: dianConfig.ResolutionDateFrom,
+		DateTo:   dianConfig.ResolutionDateTo,
+	}
+}
+
+// writeIssuerHeader writes the logo, the document title and the issuer data
+func (s *PDFService) writeIssuerHeader(w *pdfWriter, title []string, resolution *pdfResolution) {
+	var restaurant models.RestaurantConfig
+	s.db.First(&restaurant)
+	var dianConfig models.DIANConfig
+	s.db.First(&dianConfig)
+
+	parametricData := models.GetDIANParametricData()
+
+	if restaurant.Logo != "" {
+		if err := s.writeLogo(w, restaurant.Logo); err != nil {
+			log.Printf("Warning: Failed to render logo in PDF: %v", err)
+		}
+	}
+
+	w.SetFont(true, 15)
+	for _, line := range title {
+		w.Line(line, "center")
+	}
+	w.Space(6)
+
+	businessName := dianConfig.BusinessName
+	if businessName == "" {
+		businessName = restaurant.BusinessName
+	}
+	if businessName == "" {
+		businessName = restaurant.Name
+	}
+	w.SetFont(true, 11)
+	w.Line(businessName, "center")
+	if restaurant.Name != "" && restaurant.Name != businessName {
+		w.Line(restaurant.Name, "center")
+	}
+
+	w.SetFont(false, 9)
+	nitLine := fmt.Sprintf("NIT: %s", dianConfig.IdentificationNumber)
+	if dianConfig.DV != "" {
+		nitLine += fmt.Sprintf("-%s", dianConfig.DV)
+	}
+	w.Line(nitLine, "center")
+
+	var regimeLine []string
+	if regime, ok := parametricData.TypeRegimes[dianConfig.TypeRegimeID]; ok {
+		regimeLine = append(regimeLine, regime.Name)
+	}
+	if liability, ok := parametricData.TypeLiabilities[dianConfig.TypeLiabilityID]; ok {
+		regimeLine = append(regimeLine, "Obligación: "+liability.Name)
+	}
+	if len(regimeLine) > 0 {
+		w.Line(strings.Join(regimeLine, " - "), "center")
+	}
+
+	if resolution != nil && resolution.Number != "" {
+		resolutionLine := fmt.Sprintf("%s No. %s", resolution.Label, resolution.Number)
+		if !resolution.DateFrom.IsZero() {
+			resolutionLine += fmt.Sprintf(" de %s", resolution.DateFrom.Format("2006-01-02"))
+		}
+		resolutionLine += fmt.Sprintf(", Prefijo: %s, Rango %d al %d", resolution.Prefix, resolution.From, resolution.To)
+		if !resolution.DateFrom.IsZero() && !resolution.DateTo.IsZero() {
+			resolutionLine += fmt.Sprintf(", Vigencia desde %s hasta %s",
+				resolution.DateFrom.Format("2006-01-02"), resolution.DateTo.Format("2006-01-02"))
+		}
+		w.Line(resolutionLine, "center")
+	}
+
+	var addressLine []string
+	if restaurant.Address != "" {
+		addressLine = append(addressLine, restaurant.Address)
+	}
+	if municipality, ok := parametricData.Municipalities[dianConfig.MunicipalityID]; ok {
+		location := municipality.Name
+		if dept, ok := parametricData.Departments[municipality.DepartmentID]; ok {
+			location += ", " + dept.Name
+		}
+		addressLine = append(addressLine, location+" - Colombia")
+	}
+	if len(addressLine) > 0 {
+		w.Line(strings.Join(addressLine, " - "), "center")
+	}
+
+	var contactLine []string
+	if restaurant.Phone != "" {
+		contactLine = append(contactLine, "Teléfono: "+restaurant.Phone)
+	}
+	if restaurant.Email != "" {
+		contactLine = append(contactLine, "E-mail: "+restaurant.Email)
+	}
+	if len(contactLine) > 0 {
+		w.Line(strings.Join(contactLine, " - "), "center")
+	}
+}
+
+// writeLogo draws the base64 logo centered, at most 180x70 points
+func (s *PDFService) writeLogo(w *pdfWriter, logo string) error {
+	if idx := strings.Index(logo, ","); idx != -1 {
+		logo = logo[idx+1:]
+	}
+	data, err := base64.StdEncoding.DecodeString(logo)
+	if err != nil {
+		return fmt.Errorf("failed to decode logo: %w", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode logo: %w", err)
+	}
+
+	const maxWidth, maxHeight = 180.0, 70.0
+	bounds := img.Bounds()
+	width, height := float64(bounds.Dx()), float64(bounds.Dy())
+	scale := min(maxWidth/width, maxHeight/height)
+	width, height = width*scale, height*scale
+
+	w.EnsureSpace(height + 6)
+	if err := w.Image(img, pdfMargin+(w.ContentWidth()-width)/2, w.y, width, height); err != nil {
+		return err
+	}
+	w.Space(height + 8)
+	return nil
+}
+
+// writePDFSection writes a bold section title
+func writePDFSection(w *pdfWriter, title string) {
+	w.Space(4)
+	w.SetFont(true, 10)
+	w.EnsureSpace(30)
+	w.Line(title, "left")
+	w.SetFont(false, 9)
+}
+
+// writePDFDocumentKey writes the validation QR code with the CUFE/CUDE beside it
+func writePDFDocumentKey(w *pdfWriter, qrData, keyLabel, key string) {
+	const qrSize = 110.0
+	w.Space(6)
+	w.EnsureSpace(qrSize + 10)
+	top := w.y
+	if err := w.QRCode(qrData, pdfMargin, top, qrSize); err != nil {
+		log.Printf("Warning: Failed to render QR code in PDF: %v", err)
+	}
+
+	textX := pdfMargin + qrSize + 15
+	textWidth := w.ContentWidth() - qrSize - 15
+	w.Space(10)
+	w.SetFont(true, 9)
+	w.LineAt(textX, textWidth, keyLabel+":")
+	w.SetFont(false, 8)
+	w.LineAt(textX, textWidth, key)
+	w.Space(6)
+	w.LineAt(textX, textWidth, "Validar en: https://catalogo-vpfe.dian.gov.co")
+
+	w.y = max(w.y, top+qrSize) + 4
+}
+
+// pdfMoney formats an amount in Colombian pesos
+func pdfMoney(amount float64) string {
+	if amount < 0 {
+		return "-$" + formatMoney(-amount)
+	}
+	return "$" + formatMoney(amount)
+}
+
+// ==================== ELECTRONIC INVOICE ====================
+
+func (s *PDFService) renderInvoice(sale *models.Sale) ([]byte, error) {
+	invoice := sale.ElectronicInvoice
+
+	var dianConfig models.DIANConfig
+	s.db.First(&dianConfig)
+
+	isPOSDocument := invoice.DocumentType == "pos_equivalent"
+	isContingency := invoice.IsContingency
+
+	title := []string{"FACTURA ELECTRÓNICA DE VENTA"}
+	documentLabel := "Factura"
+	if isPOSDocument {
+		title = []string{"DOCUMENTO EQUIVALENTE ELECTRÓNICO POS"}
+		documentLabel = "Documento POS"
+	} else if isContingency {
+		title = []string{"FACTURA DE CONTINGENCIA"}
+		documentLabel = "Factura contingencia"
+	}
+
+	w := newPDFWriter()
+	w.pageHeader = fmt.Sprintf("%s %s%s", documentLabel, invoice.Prefix, invoice.InvoiceNumber)
+
+	resolution := invoiceResolution(&dianConfig, invoice)
+	s.writeIssuerHeader(w, title, &resolution)
+
+	// Document number and dates
+	w.Separator()
+	w.SetFont(true, 11)
+	w.Row(fmt.Sprintf("%s: %s%s", documentLabel, invoice.Prefix, invoice.InvoiceNumber),
+		"Fecha: "+sale.CreatedAt.Format("2006-01-02 15:04:05"))
+	w.SetFont(false, 9)
+	if sale.SaleNumber != "" {
+		w.Line("Venta: "+sale.SaleNumber, "left")
+	}
+
+	// Customer
+	writePDFSection(w, "DATOS DEL CLIENTE")
+	writePDFCustomer(w, sale.Customer)
+
+	// Delivery
+	if order := sale.Order; order != nil &&
+		(order.DeliveryCustomerName != "" || order.DeliveryAddress != "" || order.DeliveryPhone != "") {
+		writePDFSection(w, "DATOS DE ENTREGA")
+		if order.DeliveryCustomerName != "" {
+			w.Line("Nombre: "+order.DeliveryCustomerName, "left")
+		}
+		if order.DeliveryAddress != "" {
+			w.Line("Dirección: "+order.DeliveryAddress, "left")
+		}
+		if order.DeliveryPhone != "" {
+			w.Line("Teléfono: "+order.DeliveryPhone, "left")
+		}
+	}
+
+	// Items
+	writePDFSection(w, "DETALLE DE PRODUCTOS/SERVICIOS")
+	writePDFItems(w, sale.Order)
+
+	// Totals
+	w.Space(4)
+	writePDFSaleTotals(w, sale)
+
+	// Payment method with the DIAN parametric name (only one per invoice)
+	writePDFSection(w, "FORMA DE PAGO")
+	w.Line("Forma de pago: Contado", "left")
+	if len(sale.PaymentDetails) > 0 && sale.PaymentDetails[0].PaymentMethod != nil {
+		method := sale.PaymentDetails[0].PaymentMethod
+		methodName := method.Name
+		if method.DIANPaymentMethodID != nil {
+			if dianMethod, ok := models.GetDIANParametricData().PaymentMethods[*method.DIANPaymentMethodID]; ok {
+				methodName = dianMethod.Name
+			}
+		}
+		w.Line("Medio de pago: "+methodName, "left")
+	}
+
+	// Validation data: a contingency invoice has no CUFE until it is reported to DIAN
+	w.Separator()
+	if invoice.CUFE == "" && isContingency {
+		w.SetFont(true, 10)
+		w.Line("Pendiente de reporte a la DIAN", "left")
+		w.SetFont(false, 9)
+		w.Line("Expedida: "+invoice.CreatedAt.Format("2006-01-02 15:04:05"), "left")
+	} else {
+		qrData := invoice.QRCode
+		if qrData == "" {
+			qrData = fmt.Sprintf("https://catalogo-vpfe.dian.gov.co/document/searchqr?documentkey=%s", invoice.CUFE)
+		}
+		keyLabel := "CUFE"
+		if isPOSDocument {
+			keyLabel = "CUDE"
+		}
+		writePDFDocumentKey(w, qrData, keyLabel, invoice.CUFE)
+	}
+
+	// Footer
+	w.Separator()
+	w.SetFont(false, 9)
+	if sale.Employee != nil {
+		w.Line("Atendió: "+sale.Employee.Name, "left")
+	}
+	if sale.CashRegisterID != nil {
+		w.Line(fmt.Sprintf("Caja: %d", *sale.CashRegisterID), "left")
+	}
+	if isPOSDocument && dianConfig.POSPlateNumber != "" {
+		w.Line("Placa caja: "+dianConfig.POSPlateNumber, "left")
+	}
+	w.Space(8)
+	w.SetFont(true, 9)
+	if isPOSDocument {
+		w.Line("REPRESENTACIÓN IMPRESA DEL DOCUMENTO EQUIVALENTE ELECTRÓNICO POS", "center")
+	} else if isContingency {
+		w.Line("FACTURA DE CONTINGENCIA EXPEDIDA POR FALLA DEL SERVICIO DIAN", "center")
+	} else {
+		w.Line("REPRESENTACIÓN IMPRESA DE LA FACTURA ELECTRÓNICA DE VENTA", "center")
+	}
+	w.SetFont(false, 9)
+	w.Line("¡Gracias por su compra!", "center")
+
+	var restaurant models.RestaurantConfig
+	if err := s.db.First(&restaurant).Error; err == nil && restaurant.Website != "" {
+		w.Line(restaurant.Website, "center")
+	}
+
+	return w.Bytes()
+}
+
+// writePDFCustomer writes the customer identification
+func writePDFCustomer(w *pdfWriter, customer *models.Customer) {
+	if customer == nil {
+		w.Line("CONSUMIDOR FINAL", "left")
+		return
+	}
+
+	w.Line("Nombre: "+customer.Name, "left")
+	idLine := "NIT/CC: " + customer.IdentificationNumber
+	if customer.DV != nil && *customer.DV != "" {
+		idLine += "-" + *customer.DV
+	}
+	w.Line(idLine, "left")
+
+	// Skip the placeholder data of CONSUMIDOR FINAL
+	if customer.IdentificationNumber == "222222222222" {
+		return
+	}
+	if customer.Address != "" && customer.Address != "NO REGISTRADO" {
+		w.Line("Dirección: "+customer.Address, "left")
+	}
+	if customer.Phone != "" && customer.Phone != "0" {
+		w.Line("Teléfono: "+customer.Phone, "left")
+	}
+	if customer.Email != "" {
+		w.Line("E-mail: "+customer.Email, "left")
+	}
+}
+
+// writePDFItems writes the item table of an order, with the visible modifiers of each item
+func writePDFItems(w *pdfWriter, order *models.Order) {
+	w.SetFont(true, 9)
+	w.TableRow([]pdfColumn{
+		{Text: "Descripción", Width: 0.52},
+		{Text: "Cant.", Width: 0.1, Align: "center"},
+		{Text: "V. Unitario", Width: 0.19, Align: "right"},
+		{Text: "Total", Width: 0.19, Align: "right"},
+	}, true)
+	w.SetFont(false, 9)
+
+	if order == nil || len(order.Items) == 0 {
+		w.Line("Sin productos", "left")
+		return
+	}
+
+	for _, item := range order.Items {
+		name := ""
+		if item.Product != nil {
+			name = item.Product.Name
+		}
+
+		var visibleModifiers []string
+		for _, itemMod := range item.Modifiers {
+			if itemMod.Modifier != nil && !itemMod.Modifier.HideFromInvoice {
+				visibleModifiers = append(visibleModifiers, itemMod.Modifier.Name)
+			}
+		}
+		description := name
+		if len(visibleModifiers) > 0 {
+			description = fmt.Sprintf("%s (%s)", name, strings.Join(visibleModifiers, ", "))
+		}
+		if item.Notes != "" {
+			description += "\nNota: " + item.Notes
+		}
+
+		w.TableRow([]pdfColumn{
+			{Text: description, Width: 0.52},
+			{Text: fmt.Sprintf("%d", item.Quantity), Width: 0.1, Align: "center"},
+			{Text: pdfMoney(item.UnitPrice), Width: 0.19, Align: "right"},
+			{Text: pdfMoney(item.Subtotal), Width: 0.19, Align: "right"},
+		}, false)
+
+		for _, itemMod := range item.Modifiers {
+			if itemMod.Modifier != nil && itemMod.PriceChange != 0 && !itemMod.Modifier.HideFromInvoice {
+				w.TableRow([]pdfColumn{
+					{Text: "    + " + itemMod.Modifier.Name, Width: 0.52},
+					{Text: "", Width: 0.1},
+					{Text: pdfMoney(itemMod.PriceChange), Width: 0.19, Align: "right"},
+					{Text: "", Width: 0.19},
+				}, false)
+			}
+		}
+	}
+	w.HLine(pdfMargin, pdfPageWidth-pdfMargin, w.y)
+}
+
+// writePDFSaleTotals writes the totals block of a sale
+func writePDFSaleTotals(w *pdfWriter, sale *models.Sale) {
+	w.SetFont(false, 10)
+	w.Row("Subtotal", pdfMoney(sale.Subtotal))
+	if sale.Discount > 0 {
+		w.Row("Descuento", pdfMoney(-sale.Discount))
+	}
+	// Only show IVA if the company is VAT responsible (tax > 0)
+	if sale.Tax > 0 {
+		w.Row("IVA", pdfMoney(sale.Tax))
+	}
+	if sale.ServiceCharge > 0 {
+		w.Row("Cargo por servicio", pdfMoney(sale.ServiceCharge))
+	}
+	w.SetFont(true, 13)
+	w.Row("TOTAL", pdfMoney(sale.Total))
+	w.SetFont(false, 10)
+	// Voluntary tip is not part of the invoice total
+	if sale.Tip > 0 {
+		w.Row("Propina voluntaria", pdfMoney(sale.Tip))
+		w.Row("Total pagado", pdfMoney(sale.Total+sale.Tip))
+	}
+	w.SetFont(false, 9)
+}
+
+// ==================== CREDIT / DEBIT NOTES ====================
+
+// pdfNote holds the data shared by credit and debit notes
+type pdfNote struct {
+	Title           string
+	Label           string
+	Prefix          string
+	Number          string
+	CUDE            string
+	Reason          string
+	DiscrepancyCode int
+	Discrepancies   map[int]models.DiscrepancyResponse
+	Amount          float64
+	Status          string
+	CreatedAt       time.Time
+	Invoice         *models.ElectronicInvoice
+}
+
+func (s *PDFService) renderNote(note pdfNote) ([]byte, error) {
+	if note.Invoice == nil {
+		return nil, fmt.Errorf("la %s %s%s no tiene factura de referencia", strings.ToLower(note.Label), note.Prefix, note.Number)
+	}
+
+	var sale models.Sale
+	err := s.db.Preload("Customer").
+		Preload("Order.Items.Product").
+		Preload("Order.Items.Modifiers.Modifier").
+		First(&sale, note.Invoice.SaleID).Error
+	if err != nil {
+		return nil, fmt.Errorf("venta de la factura %s%s no encontrada", note.Invoice.Prefix, note.Invoice.InvoiceNumber)
+	}
+
+	w := newPDFWriter()
+	w.pageHeader = fmt.Sprintf("%s %s%s", note.Label, note.Prefix, note.Number)
+	s.writeIssuerHeader(w, []string{note.Title}, nil)
+
+	w.Separator()
+	w.SetFont(true, 11)
+	w.Row(fmt.Sprintf("%s: %s%s", note.Label, note.Prefix, note.Number),
+		"Fecha: "+note.CreatedAt.Format("2006-01-02 15:04:05"))
+	w.SetFont(false, 9)
+
+	// Referenced invoice
+	writePDFSection(w, "FACTURA DE REFERENCIA")
+	w.Line(fmt.Sprintf("Factura: %s%s", note.Invoice.Prefix, note.Invoice.InvoiceNumber), "left")
+	w.Line("Fecha de expedición: "+note.Invoice.CreatedAt.Format("2006-01-02"), "left")
+	if note.Invoice.CUFE != "" {
+		w.Line("CUFE: "+note.Invoice.CUFE, "left")
+	}
+
+	// Concept of the correction
+	writePDFSection(w, "CONCEPTO")
+	if discrepancy, ok := note.Discrepancies[note.DiscrepancyCode]; ok {
+		w.Line(fmt.Sprintf("Código %s: %s", discrepancy.Code, discrepancy.Name), "left")
+	} else if note.DiscrepancyCode > 0 {
+		w.Line(fmt.Sprintf("Código: %d", note.DiscrepancyCode), "left")
+	}
+	if note.Reason != "" {
+		w.Line("Motivo: "+note.Reason, "left")
+	}
+
+	writePDFSection(w, "DATOS DEL CLIENTE")
+	writePDFCustomer(w, sale.Customer)
+
+	writePDFSection(w, "DETALLE")
+	writePDFItems(w, sale.Order)
+
+	w.Space(4)
+	w.SetFont(true, 13)
+	w.Row("VALOR DE LA NOTA", pdfMoney(note.Amount))
+	w.SetFont(false, 9)
+
+	w.Separator()
+	if note.CUDE != "" {
+		writePDFDocumentKey(w, fmt.Sprintf("https://catalogo-vpfe.dian.gov.co/document/searchqr?documentkey=%s", note.CUDE), "CUDE", note.CUDE)
+	} else {
+		w.Line("Estado: "+note.Status, "left")
+	}
+
+	w.Separator()
+	w.SetFont(true, 9)
+	w.Line("REPRESENTACIÓN IMPRESA DE LA "+note.Title, "center")
+	w.SetFont(false, 9)
+
+	return w.Bytes()
+}
+
+// ==================== DIAN CLOSING REPORT ====================
+
+func (s *PDFService) renderDIANClosingReport(report *DIANClosingReport, period string) ([]byte, error) {
+	periodTitle := "REPORTE DIARIO"
+	switch period {
+	case "weekly":
+		periodTitle = "REPORTE SEMANAL"
+	case "monthly":
+		periodTitle = "REPORTE MENSUAL"
+	case "yearly":
+		periodTitle = "REPORTE ANUAL"
+	case "custom":
+		periodTitle = "REPORTE PERSONALIZADO"
+	}
+
+	w := newPDFWriter()
+	w.pageHeader = "Cierre DIAN " + report.ReportDate
+
+	w.SetFont(true, 15)
+	w.Line(periodTitle+" - CIERRE DIAN", "center")
+	w.Space(6)
+
+	// Business info
+	w.SetFont(true, 11)
+	w.Line(report.BusinessName, "center")
+	w.SetFont(false, 9)
+	if report.CommercialName != "" && report.CommercialName != report.BusinessName {
+		w.Line(report.CommercialName, "center")
+	}
+	nitLine := "NIT: " + report.NIT
+	if report.DV != "" {
+		nitLine += "-" + report.DV
+	}
+	w.Line(nitLine, "center")
+	if report.Regime != "" || report.Liability != "" {
+		w.Line(strings.Trim(report.Regime+" - "+report.Liability, " -"), "center")
+	}
+	location := report.Address
+	if report.City != "" {
+		location = strings.Trim(location+" - "+report.City, " -")
+		if report.Department != "" {
+			location += ", " + report.Department
+		}
+	}
+	if location != "" {
+		w.Line(location, "center")
+	}
+	if report.Phone != "" {
+		w.Line("Tel: "+report.Phone, "center")
+	}
+
+	w.Separator()
+	w.SetFont(true, 10)
+	if report.ReportEndDate != "" && report.ReportEndDate != report.ReportDate {
+		w.Line(fmt.Sprintf("PERIODO: %s - %s", report.ReportDate, report.ReportEndDate), "left")
+	} else {
+		w.Line("FECHA DEL REPORTE: "+report.ReportDate, "left")
+	}
+	w.SetFont(false, 9)
+	w.Line("Generado: "+report.GeneratedAt.Format("2006-01-02 15:04:05"), "left")
+	if report.Resolution != "" {
+		w.Line(fmt.Sprintf("Resolución No. %s - Prefijo: %s, Rango: %d-%d",
+			report.Resolution, report.ResolutionPrefix, report.ResolutionFrom, report.ResolutionTo), "left")
+		if report.ResolutionDateFrom != "" && report.ResolutionDateTo != "" {
+			w.Line(fmt.Sprintf("Vigencia: %s a %s", report.ResolutionDateFrom, report.ResolutionDateTo), "left")
+		}
+	}
+
+	// Invoice range
+	writePDFSection(w, "RANGO DE FACTURAS")
+	if report.TotalInvoices > 0 {
+		w.Row("Primera", report.FirstInvoiceNumber)
+		w.Row("Última", report.LastInvoiceNumber)
+		w.Row("Total", fmt.Sprintf("%d facturas", report.TotalInvoices))
+	} else {
+		w.Line("Sin facturas emitidas", "left")
+	}
+
+	// Sales by category
+	if len(report.SalesByCategory) > 0 {
+		writePDFSection(w, "VENTAS POR CATEGORÍA")
+		w.SetFont(true, 9)
+		w.TableRow([]pdfColumn{
+			{Text: "Categoría", Width: 0.4},
+			{Text: "Cant.", Width: 0.12, Align: "center"},
+			{Text: "Subtotal", Width: 0.16, Align: "right"},
+			{Text: "Impuesto", Width: 0.16, Align: "right"},
+			{Text: "Total", Width: 0.16, Align: "right"},
+		}, true)
+		w.SetFont(false, 9)
+		for _, category := range report.SalesByCategory {
+			w.TableRow([]pdfColumn{
+				{Text: category.CategoryName, Width: 0.4},
+				{Text: fmt.Sprintf("%d", category.Quantity), Width: 0.12, Align: "center"},
+				{Text: pdfMoney(category.Subtotal), Width: 0.16, Align: "right"},
+				{Text: pdfMoney(category.Tax), Width: 0.16, Align: "right"},
+				{Text: pdfMoney(category.Total), Width: 0.16, Align: "right"},
+			}, false)
+		}
+	}
+
+	// Sales by tax type
+	writePDFSection(w, "VENTAS POR TIPO DE IMPUESTO")
+	if len(report.SalesByTax) > 0 {
+		w.SetFont(true, 9)
+		w.TableRow([]pdfColumn{
+			{Text: "Impuesto", Width: 0.4},
+			{Text: "Ítems", Width: 0.12, Align: "center"},
+			{Text: "Base", Width: 0.16, Align: "right"},
+			{Text: "Impuesto", Width: 0.16, Align: "right"},
+			{Text: "Total", Width: 0.16, Align: "right"},
+		}, true)
+		w.SetFont(false, 9)
+		for _, tax := range report.SalesByTax {
+			taxPercentDisplay := "N/A"
+			if tax.TaxPercent > 0 {
+				taxPercentDisplay = fmt.Sprintf("%.0f%%", tax.TaxPercent)
+			}
+			w.TableRow([]pdfColumn{
+				{Text: fmt.Sprintf("%s (%s)", tax.TaxTypeName, taxPercentDisplay), Width: 0.4},
+				{Text: fmt.Sprintf("%d", tax.ItemCount), Width: 0.12, Align: "center"},
+				{Text: pdfMoney(tax.BaseAmount), Width: 0.16, Align: "right"},
+				{Text: pdfMoney(tax.TaxAmount), Width: 0.16, Align: "right"},
+				{Text: pdfMoney(tax.Total), Width: 0.16, Align: "right"},
+			}, false)
+		}
+	} else {
+		w.Line("Sin ventas", "left")
+	}
+
+	// Payment methods
+	writePDFSection(w, "VENTAS POR TIPO DE PAGO")
+	if len(report.PaymentMethods) > 0 {
+		w.SetFont(true, 9)
+		w.TableRow([]pdfColumn{
+			{Text: "Medio de pago", Width: 0.28},
+			{Text: "Trans.", Width: 0.08, Align: "center"},
+			{Text: "Subtotal", Width: 0.16, Align: "right"},
+			{Text: "Impuesto", Width: 0.16, Align: "right"},
+			{Text: "Descuento", Width: 0.16, Align: "right"},
+			{Text: "Total", Width: 0.16, Align: "right"},
+		}, true)
+		w.SetFont(false, 9)
+		for _, pm := range report.PaymentMethods {
+			w.TableRow([]pdfColumn{
+				{Text: pm.MethodName, Width: 0.28},
+				{Text: fmt.Sprintf("%d", pm.Transactions), Width: 0.08, Align: "center"},
+				{Text: pdfMoney(pm.Subtotal), Width: 0.16, Align: "right"},
+				{Text: pdfMoney(pm.Tax), Width: 0.16, Align: "right"},
+				{Text: pdfMoney(pm.Discount), Width: 0.16, Align: "right"},
+				{Text: pdfMoney(pm.Total), Width: 0.16, Align: "right"},
+			}, false)
+		}
+	} else {
+		w.Line("Sin pagos registrados", "left")
+	}
+
+	// Adjustments (credit/debit notes)
+	if len(report.CreditNotes) > 0 || len(report.DebitNotes) > 0 {
+		writePDFSection(w, "AJUSTES (NC/ND)")
+		for _, cn := range report.CreditNotes {
+			w.Row(fmt.Sprintf("Nota crédito %s%s - %s", cn.Prefix, cn.Number, cn.Reason), pdfMoney(-cn.Amount))
+		}
+		if len(report.CreditNotes) > 0 {
+			w.Row("Total notas crédito", pdfMoney(-report.TotalCreditNotes))
+		}
+		for _, dn := range report.DebitNotes {
+			w.Row(fmt.Sprintf("Nota débito %s%s - %s", dn.Prefix, dn.Number, dn.Reason), pdfMoney(dn.Amount))
+		}
+		if len(report.DebitNotes) > 0 {
+			w.Row("Total notas débito", pdfMoney(report.TotalDebitNotes))
+		}
+	}
+
+	// Totals
+	writePDFSection(w, "RESUMEN TOTALES")
+	w.Row("Transacciones", fmt.Sprintf("%d", report.TotalTransactions))
+	w.Row("Subtotal", pdfMoney(report.TotalSubtotal))
+	w.Row("Impuestos", pdfMoney(report.TotalTax))
+	if report.TotalDiscount > 0 {
+		w.Row("Descuentos", pdfMoney(-report.TotalDiscount))
+	}
+	w.Row("Total ventas", pdfMoney(report.TotalSales))
+	if report.TotalAdjustments != 0 {
+		w.Row("Ajustes", pdfMoney(report.TotalAdjustments))
+	}
+	w.Space(4)
+	w.SetFont(true, 13)
+	w.Row("TOTAL", pdfMoney(report.GrandTotal))
+
+	w.Separator()
+	w.SetFont(false, 8)
+	w.Line("Este documento es un reporte interno de cierre de caja para control fiscal.", "center")
+
+	return w.Bytes()
+}
+
+// ==================== CASH REGISTER REPORT ====================
+
+func (s *PDFService) renderCashRegisterReport(report *models.CashRegisterReport) ([]byte, error) {
+	if report.Employee == nil && report.GeneratedBy > 0 {
+		var employee models.Employee
+		if err := s.db.First(&employee, report.GeneratedBy).Error; err == nil {
+			report.Employee = &employee
+		}
+	}
+
+	w := newPDFWriter()
+	w.pageHeader = "Cierre de caja " + report.Date.Format("2006-01-02")
+	s.writeIssuerHeader(w, []string{"CIERRE DE CAJA"}, nil)
+
+	w.Separator()
+	w.SetFont(true, 10)
+	w.Row(fmt.Sprintf("Caja: %d", report.CashRegisterID), "Fecha: "+report.Date.Format("2006-01-02 15:04"))
+	w.SetFont(false, 9)
+	if report.Employee != nil {
+		w.Line("Cajero: "+report.Employee.Name, "left")
+	}
+
+	writePDFSection(w, "RESUMEN DE VENTAS")
+	w.Row("Total ventas", fmt.Sprintf("%d", report.NumberOfSales))
+	w.Row("Total facturado", pdfMoney(report.TotalSales))
+	if report.TotalTax > 0 {
+		w.Row("Impuestos", pdfMoney(report.TotalTax))
+	}
+	if report.TotalDiscounts > 0 {
+		w.Row("Descuentos", pdfMoney(-report.TotalDiscounts))
+	}
+	if report.NumberOfRefunds > 0 {
+		w.Row(fmt.Sprintf("Devoluciones (%d)", report.NumberOfRefunds), pdfMoney(-report.TotalRefunds))
+	}
+
+	writePDFSection(w, "FORMAS DE PAGO")
+	w.Row("Efectivo", pdfMoney(report.TotalCash))
+	w.Row("Tarjetas", pdfMoney(report.TotalCard))
+	w.Row("Digital", pdfMoney(report.TotalDigital))
+	w.Row("Otros", pdfMoney(report.TotalOther))
+	if report.TotalTips > 0 {
+		w.Row("Propinas", pdfMoney(report.TotalTips))
+	}
+
+	writePDFSection(w, "MOVIMIENTOS DE EFECTIVO")
+	w.Row("Base inicial", pdfMoney(report.OpeningBalance))
+	w.Row("Ventas en efectivo", pdfMoney(report.TotalCash))
+	if report.CashTips > 0 {
+		w.Row("Propinas en efectivo", "+"+pdfMoney(report.CashTips))
+	}
+	w.Row("Depósitos", "+"+pdfMoney(report.CashDeposits))
+	w.Row("Retiros", pdfMoney(-report.CashWithdrawals))
+
+	w.Separator()
+	w.SetFont(true, 10)
+	w.Row("Efectivo esperado", pdfMoney(report.ExpectedBalance))
+	w.Row("Efectivo contado", pdfMoney(report.ClosingBalance))
+	result := "CUADRE PERFECTO"
+	if report.Difference > 0 {
+		result = "SOBRANTE"
+	} else if report.Difference < 0 {
+		result = "FALTANTE"
+	}
+	w.Row(fmt.Sprintf("Diferencia (%s)", result), pdfMoney(report.Difference))
+	w.SetFont(false, 9)
+
+	if recon := report.BoldReconciliation; recon != nil {
+		writePDFSection(w, "CONCILIACIÓN BOLD")
+		w.Row(fmt.Sprintf("Transacciones Bold: %d", recon.BoldCount), pdfMoney(recon.BoldTotal))
+		w.Row(fmt.Sprintf("Pagos Bold en POS: %d", recon.POSCount), pdfMoney(recon.POSTotal))
+		w.Row("Conciliados", fmt.Sprintf("%d", recon.MatchedCount))
+		if recon.Status == "discrepancies" {
+			if recon.AmountMismatchCount > 0 {
+				w.Row("Diferencia de monto", fmt.Sprintf("%d", recon.AmountMismatchCount))
+			}
+			if recon.BoldOrphanCount > 0 {
+				w.Row(fmt.Sprintf("Bold sin venta: %d", recon.BoldOrphanCount), pdfMoney(recon.BoldOrphanTotal))
+			}
+			if recon.POSOrphanCount > 0 {
+				w.Row(fmt.Sprintf("Venta sin aprobar: %d", recon.POSOrphanCount), pdfMoney(recon.POSOrphanTotal))
+			}
+		} else {
+			w.Line("Sin novedades", "left")
+		}
+	}
+
+	if report.Notes != "" {
+		writePDFSection(w, "OBSERVACIONES")
+		w.Line(report.Notes, "left")
+	}
+
+	// Signatures
+	w.Space(50)
+	w.EnsureSpace(30)
+	half := w.ContentWidth() / 2
+	w.HLine(pdfMargin+20, pdfMargin+half-20, w.y)
+	w.HLine(pdfMargin+half+20, pdfPageWidth-pdfMargin-20, w.y)
+	w.Space(12)
+	w.Text(pdfMargin+(half-w.TextWidth("Firma cajero"))/2, w.y, "Firma cajero")
+	w.Text(pdfMargin+half+(half-w.TextWidth("Firma supervisor"))/2, w.y, "Firma supervisor")
+	w.Space(16)
+
+	w.SetFont(false, 8)
+	w.Line("Generado: "+time.Now().Format("2006-01-02 15:04:05"), "center")
+
+	return w.Bytes()
+}
@@ -0,0 +1,492 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Letter page size in points (1/72 inch), the paper size used for invoices in Colombia
+const (
+	pdfPageWidth  = 612.0
+	pdfPageHeight = 792.0
+	pdfMargin     = 40.0
+)
+
+// pdfImage is a JPEG image embedded as an XObject
+type pdfImage struct {
+	data   []byte
+	width  int
+	height int
+}
+
+// pdfWriter builds simple PDF documents (text in Helvetica, lines, rectangles,
+// JPEG images and QR codes) without external dependencies.
+// Coordinates are in points measured from the top-left corner of the page.
+type pdfWriter struct {
+	pages    []*bytes.Buffer
+	page     *bytes.Buffer
+	images   []pdfImage
+	bold     bool
+	fontSize float64
+	y        float64 // Cursor used by the flow helpers (Line, Row, Separator...)
+
+	// Header repeated at the top of every page after the first one
+	pageHeader string
+}
+
+func newPDFWriter() *pdfWriter {
+	w := &pdfWriter{fontSize: 10}
+	w.AddPage()
+	return w
+}
+
+// AddPage starts a new page and moves the cursor to the top margin
+func (w *pdfWriter) AddPage() {
+	w.page = &bytes.Buffer{}
+	w.pages = append(w.pages, w.page)
+	w.y = pdfMargin
+
+	if w.pageHeader != "" && len(w.pages) > 1 {
+		bold, size := w.bold, w.fontSize
+		w.SetFont(false, 8)
+		w.Line(fmt.Sprintf("%s - Página %d", w.pageHeader, len(w.pages)), "right")
+		w.Separator()
+		w.SetFont(bold, size)
+	}
+}
+
+// SetFont selects Helvetica or Helvetica-Bold at the given size
+func (w *pdfWriter) SetFont(bold bool, size float64) {
+	w.bold = bold
+	w.fontSize = size
+}
+
+// ContentWidth returns the usable width between margins
+func (w *pdfWriter) ContentWidth() float64 {
+	return pdfPageWidth - 2*pdfMargin
+}
+
+// lineHeight returns the vertical advance of a text line with the current font
+func (w *pdfWriter) lineHeight() float64 {
+	return w.fontSize * 1.35
+}
+
+// EnsureSpace starts a new page when the next block of the given height does not fit
+func (w *pdfWriter) EnsureSpace(height float64) {
+	if w.y+height > pdfPageHeight-pdfMargin {
+		w.AddPage()
+	}
+}
+
+// Space advances the cursor
+func (w *pdfWriter) Space(height float64) {
+	w.y += height
+}
+
+// Text draws a single line of text with its baseline at y
+func (w *pdfWriter) Text(x, y float64, text string) {
+	font := "F1"
+	if w.bold {
+		font = "F2"
+	}
+	fmt.Fprintf(w.page, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n",
+		font, w.fontSize, x, pdfPageHeight-y, pdfEscapeText(pdfEncodeText(text)))
+}
+
+// TextWidth returns the width in points of the text with the current font
+func (w *pdfWriter) TextWidth(text string) float64 {
+	widths := &pdfHelveticaWidths
+	if w.bold {
+		widths = &pdfHelveticaBoldWidths
+	}
+	total := 0
+	for _, b := range pdfEncodeText(text) {
+		total += pdfGlyphWidth(widths, b)
+	}
+	return float64(total) * w.fontSize / 1000
+}
+
+// Line writes a line of text at the cursor aligned "left", "center" or "right"
+// within the margins, wrapping it when it is wider than the page
+func (w *pdfWriter) Line(text, align string) {
+	for _, line := range w.Wrap(text, w.ContentWidth()) {
+		w.EnsureSpace(w.lineHeight())
+		w.y += w.fontSize
+		x := pdfMargin
+		switch align {
+		case "center":
+			x = pdfMargin + (w.ContentWidth()-w.TextWidth(line))/2
+		case "right":
+			x = pdfPageWidth - pdfMargin - w.TextWidth(line)
+		}
+		w.Text(x, w.y, line)
+		w.y += w.lineHeight() - w.fontSize
+	}
+}
+
+// LineAt writes wrapped text starting at the cursor in a column at x of the given width
+func (w *pdfWriter) LineAt(x, width float64, text string) {
+	for _, line := range w.Wrap(text, width) {
+		w.EnsureSpace(w.lineHeight())
+		w.y += w.fontSize
+		w.Text(x, w.y, line)
+		w.y += w.lineHeight() - w.fontSize
+	}
+}
+
+// Row writes a label on the left and a value aligned to the right margin
+func (w *pdfWriter) Row(label, value string) {
+	w.EnsureSpace(w.lineHeight())
+	w.y += w.fontSize
+	w.Text(pdfMargin, w.y, label)
+	w.Text(pdfPageWidth-pdfMargin-w.TextWidth(value), w.y, value)
+	w.y += w.lineHeight() - w.fontSize
+}
+
+// pdfColumn describes a column of a table row
+type pdfColumn struct {
+	Text  string
+	Width float64 // Fraction of the content width
+	Align string  // "left", "center" or "right"
+}
+
+// TableRow writes a row of columns; the text of the first left-aligned column wraps
+// and the row grows to fit it
+func (w *pdfWriter) TableRow(columns []pdfColumn, shaded bool) {
+	const padding = 3.0
+	cells := make([][]string, len(columns))
+	lines := 1
+	for i, col := range columns {
+		width := col.Width*w.ContentWidth() - 2*padding
+		if col.Align == "" || col.Align == "left" {
+			cells[i] = w.Wrap(col.Text, width)
+		} else {
+			cells[i] = []string{col.Text}
+		}
+		if len(cells[i]) > lines {
+			lines = len(cells[i])
+		}
+	}
+
+	height := float64(lines)*w.lineHeight() + padding
+	w.EnsureSpace(height)
+	if shaded {
+		w.FillRect(pdfMargin, w.y, w.ContentWidth(), height, 0.9)
+	}
+
+	x := pdfMargin
+	for i, col := range columns {
+		width := col.Width * w.ContentWidth()
+		for j, line := range cells[i] {
+			baseline := w.y + padding + w.fontSize + float64(j)*w.lineHeight()
+			switch col.Align {
+			case "right":
+				w.Text(x+width-padding-w.TextWidth(line), baseline, line)
+			case "center":
+				w.Text(x+(width-w.TextWidth(line))/2, baseline, line)
+			default:
+				w.Text(x+padding, baseline, line)
+			}
+		}
+		x += width
+	}
+	w.y += height
+}
+
+// Separator draws a horizontal rule across the content width
+func (w *pdfWriter) Separator() {
+	w.EnsureSpace(8)
+	w.y += 4
+	w.HLine(pdfMargin, pdfPageWidth-pdfMargin, w.y)
+	w.y += 4
+}
+
+// HLine draws a horizontal line at y
+func (w *pdfWriter) HLine(x1, x2, y float64) {
+	fmt.Fprintf(w.page, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, pdfPageHeight-y, x2, pdfPageHeight-y)
+}
+
+// FillRect fills a rectangle with a gray level (0 black, 1 white)
+func (w *pdfWriter) FillRect(x, y, width, height, gray float64) {
+	fmt.Fprintf(w.page, "q %.2f g %.2f %.2f %.2f %.2f re f Q\n", gray, x, pdfPageHeight-y-height, width, height)
+}
+
+// StrokeRect draws the border of a rectangle
+func (w *pdfWriter) StrokeRect(x, y, width, height float64) {
+	fmt.Fprintf(w.page, "0.5 w %.2f %.2f %.2f %.2f re S\n", x, pdfPageHeight-y-height, width, height)
+}
+
+// Wrap splits the text into lines no wider than width with the current font
+func (w *pdfWriter) Wrap(text string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		current := ""
+		for _, word := range words {
+			// Long words without spaces (CUFE, URLs) are split by characters
+			for w.TextWidth(word) > width {
+				cut := len([]rune(word))
+				for cut > 1 && w.TextWidth(string([]rune(word)[:cut])) > width {
+					cut--
+				}
+				if current != "" {
+					lines = append(lines, current)
+					current = ""
+				}
+				lines = append(lines, string([]rune(word)[:cut]))
+				word = string([]rune(word)[cut:])
+			}
+			candidate := word
+			if current != "" {
+				candidate = current + " " + word
+			}
+			if current != "" && w.TextWidth(candidate) > width {
+				lines = append(lines, current)
+				current = word
+			} else {
+				current = candidate
+			}
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+	}
+	return lines
+}
+
+// Image draws an image at (x, y) scaled to width x height; it is embedded as a JPEG
+// over a white background so transparent PNG logos render correctly
+func (w *pdfWriter) Image(img image.Image, x, y, width, height float64) error {
+	// Large logos are downscaled (nearest neighbour) to keep the document small
+	const maxPixels = 600
+	bounds := img.Bounds()
+	scale := 1.0
+	if bounds.Dx() > maxPixels || bounds.Dy() > maxPixels {
+		scale = float64(maxPixels) / float64(max(bounds.Dx(), bounds.Dy()))
+	}
+	dstWidth := max(int(float64(bounds.Dx())*scale), 1)
+	dstHeight := max(int(float64(bounds.Dy())*scale), 1)
+
+	// Transparent pixels are composed over a white background
+	canvas := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for py := 0; py < dstHeight; py++ {
+		for px := 0; px < dstWidth; px++ {
+			r, g, b, a := img.At(bounds.Min.X+int(float64(px)/scale), bounds.Min.Y+int(float64(py)/scale)).RGBA()
+			canvas.Set(px, py, color.RGBA{
+				R: uint8((r + 0xffff - a) >> 8),
+				G: uint8((g + 0xffff - a) >> 8),
+				B: uint8((b + 0xffff - a) >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, canvas, &jpeg.Options{Quality: 90}); err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	w.images = append(w.images, pdfImage{data: buf.Bytes(), width: dstWidth, height: dstHeight})
+	fmt.Fprintf(w.page, "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n",
+		width, height, x, pdfPageHeight-y-height, len(w.images))
+	return nil
+}
+
+// QRCode draws the QR code of data as vector squares of size x size points
+func (w *pdfWriter) QRCode(data string, x, y, size float64) error {
+	qr, err := qrcode.New(data, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("failed to generate QR code: %w", err)
+	}
+	bitmap := qr.Bitmap()
+	if len(bitmap) == 0 {
+		return fmt.Errorf("failed to generate QR code: empty bitmap")
+	}
+
+	module := size / float64(len(bitmap))
+	w.page.WriteString("q 0 g\n")
+	for row, cells := range bitmap {
+		// Merge horizontal runs of dark modules into a single rectangle
+		for col := 0; col < len(cells); {
+			if !cells[col] {
+				col++
+				continue
+			}
+			start := col
+			for col < len(cells) && cells[col] {
+				col++
+			}
+			fmt.Fprintf(w.page, "%.3f %.3f %.3f %.3f re\n",
+				x+float64(start)*module,
+				pdfPageHeight-y-float64(row+1)*module,
+				float64(col-start)*module,
+				module)
+		}
+	}
+	w.page.WriteString("f Q\n")
+	return nil
+}
+
+// Bytes serializes the document
+func (w *pdfWriter) Bytes() ([]byte, error) {
+	var out bytes.Buffer
+	var offsets []int
+
+	// Object numbers: 1 catalog, 2 pages, 3-4 fonts, then images, then page/content pairs
+	firstImage := 5
+	firstPage := firstImage + len(w.images)
+
+	writeObject := func(body string, stream []byte) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\n", len(offsets), body)
+		if stream != nil {
+			out.WriteString("stream\n")
+			out.Write(stream)
+			out.WriteString("\nendstream\n")
+		}
+		out.WriteString("endobj\n")
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>", nil)
+
+	kids := make([]string, len(w.pages))
+	for i := range w.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages)), nil)
+
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>", nil)
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>", nil)
+
+	xObjects := ""
+	for i, img := range w.images {
+		writeObject(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>",
+			img.width, img.height, len(img.data)), img.data)
+		xObjects += fmt.Sprintf(" /Im%d %d 0 R", i+1, firstImage+i)
+	}
+
+	resources := "<< /Font << /F1 3 0 R /F2 4 0 R >>"
+	if xObjects != "" {
+		resources += " /XObject <<" + xObjects + " >>"
+	}
+	resources += " >>"
+
+	for i, page := range w.pages {
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return nil, fmt.Errorf("failed to compress page: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress page: %w", err)
+		}
+
+		writeObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources %s /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, resources, firstPage+2*i+1), nil)
+		writeObject(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>", compressed.Len()), compressed.Bytes())
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes(), nil
+}
+
+// pdfEncodeText converts UTF-8 text to WinAnsiEncoding (Windows-1252), the encoding
+// of the standard fonts; characters outside it are replaced with '?'
+func pdfEncodeText(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\t':
+			out = append(out, ' ')
+		case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		case r == '€':
+			out = append(out, 0x80)
+		case r == '…':
+			out = append(out, 0x85)
+		case r == '‘':
+			out = append(out, 0x91)
+		case r == '’':
+			out = append(out, 0x92)
+		case r == '“':
+			out = append(out, 0x93)
+		case r == '”':
+			out = append(out, 0x94)
+		case r == '•':
+			out = append(out, 0x95)
+		case r == '–':
+			out = append(out, 0x96)
+		case r == '—':
+			out = append(out, 0x97)
+		case r < 0x20:
+			// Control characters are dropped
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// pdfEscapeText escapes the delimiters of a PDF literal string
+func pdfEscapeText(text []byte) string {
+	var b strings.Builder
+	for _, c := range text {
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// pdfLatin1Base maps the Latin-1 letters 0xC0-0xFF to an ASCII letter of similar width
+const pdfLatin1Base = "AAAAAAACEEEEIIIIDNOOOOOxOUUUUYPsaaaaaaaceeeeiiiidnooooo/ouuuuypy"
+
+// pdfGlyphWidth returns the width of a WinAnsi character in thousandths of the font size
+func pdfGlyphWidth(widths *[95]int, b byte) int {
+	if b >= 0xC0 {
+		b = pdfLatin1Base[b-0xC0]
+	}
+	if b >= 0x20 && b < 0x7F {
+		return widths[b-0x20]
+	}
+	return 556
+}
+
+// Glyph widths of the standard Helvetica fonts for the characters 0x20-0x7E (Adobe AFM metrics)
+var pdfHelveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var pdfHelveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
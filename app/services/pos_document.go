@@ -0,0 +1,179 @@
+package services
+
+import (
+	"PosApp/app/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// POSDocumentData represents the data structure for sending a POS equivalent document
+// (Documento Equivalente Electrónico del tiquete de máquina registradora con sistema P.O.S.) to DIAN
+type POSDocumentData struct {
+	InvoiceData
+	SoftwareManufacturer POSSoftwareManufacturer `json:"software_manufacturer"`
+	CashInformation      POSCashInformation      `json:"cash_information"`
+}
+
+// POSSoftwareManufacturer identifies the POS software that issues the document
+type POSSoftwareManufacturer struct {
+	OwnerName    string `json:"owner_name"`
+	CompanyName  string `json:"company_name"`
+	SoftwareName string `json:"software_name"`
+}
+
+// POSCashInformation describes the cash register and seller that issued the document
+type POSCashInformation struct {
+	PlateNumber string `json:"plate_number"`
+	Location    string `json:"location"`
+	Cashier     string `json:"cashier"`
+	CashType    string `json:"cash_type"`
+	SalesCode   string `json:"sales_code"`
+	Subtotal    string `json:"subtotal"`
+}
+
+// SendPOSDocument sends a POS equivalent document to DIAN for a CONSUMIDOR FINAL sale
+func (s *InvoiceService) SendPOSDocument(sale *models.Sale, sendEmailToCustomer bool) (*models.ElectronicInvoice, error) {
+	// Load DIAN config
+	var config models.DIANConfig
+	if err := s.db.First(&config).Error; err != nil {
+		return nil, fmt.Errorf("DIAN configuration not found")
+	}
+	s.config = &config
+
+	if !config.IsEnabled {
+		return nil, fmt.Errorf("electronic invoicing is disabled")
+	}
+	if !config.UsePOSEquivalentDocument || !config.Step8Completed {
+		return nil, fmt.Errorf("POS equivalent document is not configured")
+	}
+
+	// During an outage sales are issued with the contingency range and reported later
+	if config.ContingencyModeActive {
+		return s.issueContingencyInvoice(sale, sendEmailToCustomer)
+	}
+
+	// Prepare document data
+	posData, err := s.preparePOSDocumentData(sale, sendEmailToCustomer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare POS document data: %w", err)
+	}
+
+	// The number is reserved right before sending, so concurrent sales never get the same one
+	// A document that fails to send keeps its number on the error record
+	documentNumber, err := reservePOSNumber(s.db, config.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.config.LastPOSNumber = documentNumber
+	posData.Number = documentNumber
+
+	document, err := s.sendSaleDocument(sale, posData, posData.Number, posData.Prefix, "pos_equivalent")
+	if s.canSwitchToContingency(err) {
+		// The document never reached the provider: give the number back unless a later sale took one
+		releasePOSNumber(s.db, config.ID, documentNumber)
+		return s.switchToContingency(sale, sendEmailToCustomer, document, err)
+	}
+	return document, err
+}
+
+// reservePOSNumber atomically advances the POS consecutive and returns the reserved number
+func reservePOSNumber(db *gorm.DB, configID uint) (int, error) {
+	var reserved struct {
+		LastPOSNumber int
+	}
+	result := db.Raw(`
+		UPDATE dian_configs SET last_pos_number = last_pos_number + 1
+		WHERE id = ? AND (pos_resolution_to = 0 OR last_pos_number < pos_resolution_to)
+		RETURNING last_pos_number
+	`, configID).Scan(&reserved)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to reserve POS document number: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, fmt.Errorf("POS resolution range exhausted")
+	}
+	return reserved.LastPOSNumber, nil
+}
+
+// releasePOSNumber returns a reserved POS number that was not used, if it is still the last one
+func releasePOSNumber(db *gorm.DB, configID uint, number int) {
+	db.Exec("UPDATE dian_configs SET last_pos_number = last_pos_number - 1 WHERE id = ? AND last_pos_number = ?", configID, number)
+}
+
+// preparePOSDocumentData prepares a POS equivalent document from a sale
+// It reuses the invoice payload (customer, totals, taxes and lines) with the POS resolution
+// The number is set by the caller once it is reserved
+func (s *InvoiceService) preparePOSDocumentData(sale *models.Sale, sendEmailToCustomer bool) (*POSDocumentData, error) {
+	invoiceData, err := s.prepareInvoiceData(sale, sendEmailToCustomer)
+	if err != nil {
+		return nil, err
+	}
+
+	invoiceData.TypeDocumentID = 15 // POS Equivalent Document
+	invoiceData.ResolutionNumber = s.config.POSResolutionNumber
+	invoiceData.Prefix = s.config.POSResolutionPrefix
+
+	// Seller: the employee that made the sale, or the one that opened the cash register
+	cashier := ""
+	if sale.EmployeeID != nil {
+		var employee models.Employee
+		if err := s.db.First(&employee, *sale.EmployeeID).Error; err == nil {
+			cashier = employee.Name
+		}
+	}
+	if cashier == "" && sale.CashRegisterID != nil {
+		var cashRegister models.CashRegister
+		if err := s.db.Preload("Employee").First(&cashRegister, *sale.CashRegisterID).Error; err == nil && cashRegister.Employee != nil {
+			cashier = cashRegister.Employee.Name
+		}
+	}
+
+	cashType := s.config.POSCashType
+	if cashType == "" {
+		cashType = "Caja principal"
+	}
+
+	softwareName := s.config.POSSoftwareName
+	if softwareName == "" {
+		softwareName = "Restaurant POS"
+	}
+	ownerName := s.config.POSSoftwareOwnerName
+	if ownerName == "" {
+		ownerName = s.config.BusinessName
+	}
+	companyName := s.config.POSSoftwareCompanyName
+	if companyName == "" {
+		companyName = s.config.BusinessName
+	}
+
+	return &POSDocumentData{
+		InvoiceData: *invoiceData,
+		SoftwareManufacturer: POSSoftwareManufacturer{
+			OwnerName:    ownerName,
+			CompanyName:  companyName,
+			SoftwareName: softwareName,
+		},
+		CashInformation: POSCashInformation{
+			PlateNumber: s.config.POSPlateNumber,
+			Location:    s.config.POSLocation,
+			Cashier:     cashier,
+			CashType:    cashType,
+			SalesCode:   sale.SaleNumber,
+			Subtotal:    fmt.Sprintf("%.2f", sale.Subtotal),
+		},
+	}, nil
+}
+
+// isPOSDocumentEnabled reports whether CONSUMIDOR FINAL sales should be issued as POS equivalent documents
+func (s *InvoiceService) isPOSDocumentEnabled() bool {
+	if s == nil || s.db == nil {
+		return false
+	}
+
+	var config models.DIANConfig
+	if err := s.db.First(&config).Error; err != nil {
+		return false
+	}
+	return config.IsEnabled && config.UsePOSEquivalentDocument && config.Step8Completed
+}
@@ -0,0 +1,820 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"PosApp/app/models"
+
+	"gorm.io/gorm"
+)
+
+// printRetryDelays are the waits before each retry of a failed print job
+var printRetryDelays = []time.Duration{
+	10 * time.Second,
+	30 * time.Second,
+	1 * time.Minute,
+	2 * time.Minute,
+	5 * time.Minute,
+}
+
+const (
+	printSpoolerCheckPeriod   = 15 * time.Second // How often due retries are looked for
+	printSpoolerCleanupPeriod = 1 * time.Hour    // How often old jobs are purged
+	printSendTimeout          = 45 * time.Second // Maximum time a printer may take to accept a job
+	printSendAbandonTimeout   = 2 * time.Minute  // Wait for an interrupted write to return before freeing the printer
+	printJobsPageLimit        = 200
+	printReprintLimit         = 20
+)
+
+// errPrintOutcomeUnknown marks a write that timed out: the printer may or may not have printed it
+var errPrintOutcomeUnknown = errors.New("resultado de impresión desconocido")
+
+// printJobWake carries the printer ID of newly queued jobs to the spooler dispatcher
+var printJobWake = make(chan uint, 64)
+
+// printSpoolerRunning tells PrinterService whether documents can be queued.
+// While the spooler is stopped (e.g. before setup) documents are printed directly
+var printSpoolerRunning atomic.Bool
+
+// printerSendLocks serializes the writes to a printer (printer ID -> *sync.Mutex),
+// which may receive jobs from its own queue and as backup of another printer
+var printerSendLocks sync.Map
+
+// reprintDrawerKick is the cash drawer pulse, removed from reprinted documents
+var reprintDrawerKick = []byte{ESC, 'p', 0, 25, 250}
+
+// spoolJob describes the document PrinterService is rendering for the spooler
+type spoolJob struct {
+	Printer       *models.PrinterConfig
+	JobType       string
+	Description   string
+	ReferenceType string
+	ReferenceID   uint
+	ReprintOfID   *uint // Set when the document is a copy of another job
+}
+
+// PrintSpoolerService prints the documents rendered by PrinterService in the background.
+// Every printer has its own queue and worker, so a jammed or offline printer only delays
+// its own jobs. Failed jobs go to the backup printer of their printer, are retried with
+// backoff and are reported through WebSocket
+type PrintSpoolerService struct {
+	*BaseService
+	mu          sync.Mutex
+	stopChan    chan struct{}
+	running     bool
+	workers     map[uint]chan struct{}
+	wsServer    WebSocketServer
+	lastCleanup time.Time
+}
+
+// PrintSpoolerSettings are the spooler preferences (stored as system configs)
+type PrintSpoolerSettings struct {
+	Enabled         bool `json:"enabled"`          // Queue documents instead of printing them synchronously
+	MaxAttempts     int  `json:"max_attempts"`     // Attempts before a job is marked as failed
+	FailoverEnabled bool `json:"failover_enabled"` // Send failed jobs to the backup printer
+	RetentionDays   int  `json:"retention_days"`   // Days finished jobs are kept for reprinting
+}
+
+// PrinterQueueStatus summarizes the queue of a printer
+type PrinterQueueStatus struct {
+	PrinterID         uint       `json:"printer_id"`
+	PrinterName       string     `json:"printer_name"`
+	IsActive          bool       `json:"is_active"`
+	BackupPrinterID   *uint      `json:"backup_printer_id,omitempty"`
+	BackupPrinterName string     `json:"backup_printer_name"`
+	Pending           int64      `json:"pending"` // Pending and printing
+	Retrying          int64      `json:"retrying"`
+	Failed            int64      `json:"failed"`
+	LastError         string     `json:"last_error"`
+	LastPrintedAt     *time.Time `json:"last_printed_at,omitempty"`
+}
+
+// NewPrintSpoolerService creates a new print spooler service
+func NewPrintSpoolerService() *PrintSpoolerService {
+	return &PrintSpoolerService{
+		BaseService: NewBaseService(),
+	}
+}
+
+// SetWebSocketServer sets the WebSocket server used to notify print failures
+func (s *PrintSpoolerService) SetWebSocketServer(wsServer WebSocketServer) {
+	s.wsServer = wsServer
+}
+
+// GetPrintSpoolerSettings returns the spooler preferences
+func (s *PrintSpoolerService) GetPrintSpoolerSettings() PrintSpoolerSettings {
+	configSvc := NewConfigService()
+	return PrintSpoolerSettings{
+		Enabled:         configSvc.GetSystemConfigBool("print_spooler_enabled", true),
+		MaxAttempts:     configSvc.GetSystemConfigInt("print_spooler_max_attempts", 5),
+		FailoverEnabled: configSvc.GetSystemConfigBool("print_spooler_failover_enabled", true),
+		RetentionDays:   configSvc.GetSystemConfigInt("print_spooler_retention_days", 7),
+	}
+}
+
+// SavePrintSpoolerSettings saves the spooler preferences
+func (s *PrintSpoolerService) SavePrintSpoolerSettings(settings PrintSpoolerSettings) error {
+	if settings.MaxAttempts < 1 || settings.MaxAttempts > 20 {
+		return fmt.Errorf("el número de intentos debe estar entre 1 y 20")
+	}
+	if settings.RetentionDays < 1 || settings.RetentionDays > 90 {
+		return fmt.Errorf("los días de retención deben estar entre 1 y 90")
+	}
+
+	configSvc := NewConfigService()
+	values := []struct {
+		key, value, configType string
+	}{
+		{"print_spooler_enabled", strconv.FormatBool(settings.Enabled), "boolean"},
+		{"print_spooler_max_attempts", strconv.Itoa(settings.MaxAttempts), "number"},
+		{"print_spooler_failover_enabled", strconv.FormatBool(settings.FailoverEnabled), "boolean"},
+		{"print_spooler_retention_days", strconv.Itoa(settings.RetentionDays), "number"},
+	}
+	for _, v := range values {
+		if err := configSvc.SetSystemConfig(v.key, v.value, v.configType, "printer"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printSpoolerEnabled reports whether documents should be queued
+func printSpoolerEnabled() bool {
+	return printSpoolerRunning.Load() && NewConfigService().GetSystemConfigBool("print_spooler_enabled", true)
+}
+
+// ==================== QUEUE ====================
+
+// queuePrintJob stores a rendered document in the queue of its printer and wakes the spooler
+func queuePrintJob(db *gorm.DB, job *spoolJob, data []byte) (*models.PrintJob, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if job.Printer == nil || job.Printer.ID == 0 {
+		return nil, fmt.Errorf("print job without printer")
+	}
+
+	printJob := &models.PrintJob{
+		PrinterID:     job.Printer.ID,
+		JobType:       job.JobType,
+		Description:   job.Description,
+		ReferenceType: job.ReferenceType,
+		ReferenceID:   job.ReferenceID,
+		Payload:       base64.StdEncoding.EncodeToString(data),
+		Size:          len(data),
+		Status:        models.PrintJobStatusPending,
+		MaxAttempts:   NewConfigService().GetSystemConfigInt("print_spooler_max_attempts", 5),
+		IsReprint:     job.ReprintOfID != nil,
+		ReprintOfID:   job.ReprintOfID,
+	}
+	if err := db.Create(printJob).Error; err != nil {
+		return nil, fmt.Errorf("failed to queue print job: %w", err)
+	}
+
+	wakePrintSpooler(printJob.PrinterID)
+	return printJob, nil
+}
+
+// wakePrintSpooler asks the spooler to process the queue of a printer.
+// When the channel is full the periodic check picks the job up
+func wakePrintSpooler(printerID uint) {
+	select {
+	case printJobWake <- printerID:
+	default:
+	}
+}
+
+// printerSendLock returns the write lock of a printer
+func printerSendLock(printerID uint) *sync.Mutex {
+	lock, _ := printerSendLocks.LoadOrStore(printerID, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// sendPrintJob writes data to a printer
+// After printSendTimeout the connection is closed to interrupt the write, and the lock is
+// kept until the write returns so the next job can't interleave with it. A write that was
+// interrupted may have printed part or all of the document, so its error wraps
+// errPrintOutcomeUnknown; a job timed out before anything was written can be retried
+func sendPrintJob(config *models.PrinterConfig, data []byte) error {
+	lock := printerSendLock(config.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	var mu sync.Mutex
+	var connection io.Closer
+	timedOut, writing := false, false
+
+	done := make(chan error, 1)
+	go func() {
+		printer := NewPrinterService()
+		if err := printer.connectPrinter(config); err != nil {
+			done <- err
+			return
+		}
+		defer printer.closePrinter()
+
+		// Connected after the timeout: nothing is written
+		mu.Lock()
+		if timedOut {
+			mu.Unlock()
+			done <- nil
+			return
+		}
+		writing = true
+		if printer.connection != nil {
+			connection = printer.connection
+		}
+		mu.Unlock()
+
+		printer.buffer.Write(data)
+		done <- printer.print()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(printSendTimeout):
+	}
+
+	mu.Lock()
+	timedOut = true
+	wasWriting := writing
+	if connection != nil {
+		connection.Close()
+	}
+	mu.Unlock()
+
+	// Backends without a connection to close, or a connect that never returns, could
+	// block the printer forever; after a longer wait the goroutine is abandoned
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(printSendAbandonTimeout):
+		log.Printf("Print spooler: %s did not return after the timeout, releasing the printer", config.Name)
+	}
+
+	if !wasWriting {
+		if err == nil {
+			err = fmt.Errorf("la impresora %s no respondió en %s", config.Name, printSendTimeout)
+		}
+		return err
+	}
+	return fmt.Errorf("%w: la impresora %s no respondió en %s", errPrintOutcomeUnknown, config.Name, printSendTimeout)
+}
+
+// processJob makes one print attempt of a job on its printer and, when that fails,
+// on the backup printer; kitchen tickets may also go to the default printer. Printers
+// the monitor reports as unable to print are skipped without sending. It returns an
+// error when the job could not be printed
+func (s *PrintSpoolerService) processJob(job *models.PrintJob) error {
+	claim := s.db.Model(&models.PrintJob{}).
+		Where("id = ? AND status IN ?", job.ID, []string{models.PrintJobStatusPending, models.PrintJobStatusRetrying}).
+		Update("status", models.PrintJobStatusPrinting)
+	if claim.Error != nil || claim.RowsAffected == 0 {
+		return claim.Error
+	}
+
+	attempt := job.Attempts + 1
+	settings := s.GetPrintSpoolerSettings()
+	var failures []string
+	var printedOn *models.PrinterConfig
+	blocked, sent, unknown := false, false, false
+
+	data, err := base64.StdEncoding.DecodeString(job.Payload)
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("contenido dañado: %v", err))
+	}
+
+	// tryPrinter sends the job to a printer unless its status says it cannot print
+	tryPrinter := func(config *models.PrinterConfig, label string) {
+		if reason := printerStatusBlocked(config.ID); reason != "" {
+			blocked = true
+			failures = append(failures, fmt.Sprintf("%s: %s", label, reason))
+			return
+		}
+		sent = true
+		if err := sendPrintJob(config, data); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", label, err))
+			requestPrinterStatusCheck(config.ID)
+			// The document may have printed: sending it elsewhere could print it twice
+			unknown = errors.Is(err, errPrintOutcomeUnknown)
+			return
+		}
+		printedOn = config
+	}
+
+	var printer models.PrinterConfig
+	if err == nil {
+		if err := s.db.First(&printer, job.PrinterID).Error; err != nil {
+			failures = append(failures, fmt.Sprintf("impresora %d no encontrada", job.PrinterID))
+		} else if !printer.IsActive {
+			failures = append(failures, fmt.Sprintf("%s: impresora inactiva", printer.Name))
+		} else {
+			tryPrinter(&printer, printer.Name)
+		}
+
+		var backup *models.PrinterConfig
+		if printedOn == nil && !unknown && settings.FailoverEnabled {
+			if backup = s.backupPrinter(&printer); backup != nil {
+				tryPrinter(backup, backup.Name+" (respaldo)")
+			}
+		}
+
+		// Kitchen tickets must not get lost: the default printer takes them when the
+		// kitchen printer and its backup are down
+		if printedOn == nil && !unknown && job.JobType == models.PrintJobKitchen && NewPrinterMonitorService().GetPrinterMonitorSettings().KitchenFallback {
+			var fallback models.PrinterConfig
+			if err := s.db.Where("is_default = ? AND is_active = ?", true, true).First(&fallback).Error; err == nil &&
+				fallback.ID != job.PrinterID && (backup == nil || fallback.ID != backup.ID) {
+				tryPrinter(&fallback, fallback.Name+" (predeterminada)")
+			}
+		}
+	}
+
+	// A job no printer could even be sent to is held without spending an attempt;
+	// the monitor wakes it up when the printer recovers
+	held := printedOn == nil && blocked && !sent
+	if held {
+		attempt = job.Attempts
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"attempts": attempt}
+	if printedOn != nil {
+		updates["status"] = models.PrintJobStatusPrinted
+		updates["printed_at"] = now
+		updates["printed_on_id"] = printedOn.ID
+		updates["next_attempt_at"] = nil
+		updates["last_error"] = strings.Join(failures, "; ")
+	} else {
+		updates["last_error"] = strings.Join(failures, "; ")
+		if unknown {
+			// Not retried automatically; the user checks the printer and retries or reprints it
+			updates["status"] = models.PrintJobStatusUnknown
+			updates["next_attempt_at"] = nil
+		} else if held {
+			updates["status"] = models.PrintJobStatusRetrying
+			updates["next_attempt_at"] = now.Add(printerStatusMaxAge)
+		} else if attempt >= job.MaxAttempts {
+			updates["status"] = models.PrintJobStatusFailed
+			updates["next_attempt_at"] = nil
+		} else {
+			delay := printRetryDelays[len(printRetryDelays)-1]
+			if attempt-1 < len(printRetryDelays) {
+				delay = printRetryDelays[attempt-1]
+			}
+			updates["status"] = models.PrintJobStatusRetrying
+			updates["next_attempt_at"] = now.Add(delay)
+		}
+	}
+	if err := s.db.Model(&models.PrintJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		log.Printf("Warning: Failed to update print job %d: %v", job.ID, err)
+	}
+
+	if printedOn == nil {
+		if held {
+			log.Printf("Print spooler: job %d (%s) held: %s", job.ID, job.JobType, updates["last_error"])
+		} else {
+			log.Printf("Print spooler: job %d (%s) failed, attempt %d/%d: %s", job.ID, job.JobType, attempt, job.MaxAttempts, updates["last_error"])
+		}
+		// A held job is notified once, not on every check while the printer is down
+		if s.wsServer != nil && (!held || job.LastError != updates["last_error"]) {
+			s.wsServer.BroadcastJSON("print_job_failed", map[string]interface{}{
+				"job_id":       job.ID,
+				"job_type":     job.JobType,
+				"description":  job.Description,
+				"printer_id":   job.PrinterID,
+				"printer_name": printer.Name,
+				"attempts":     attempt,
+				"max_attempts": job.MaxAttempts,
+				"status":       updates["status"],
+				"error":        updates["last_error"],
+				"blocked":      blocked,
+			})
+		}
+		return fmt.Errorf("%s", updates["last_error"])
+	}
+
+	if printedOn.ID != job.PrinterID {
+		log.Printf("Print spooler: job %d (%s) rerouted to printer %s", job.ID, job.JobType, printedOn.Name)
+		if s.wsServer != nil {
+			s.wsServer.BroadcastJSON("print_job_failover", map[string]interface{}{
+				"job_id":              job.ID,
+				"job_type":            job.JobType,
+				"description":         job.Description,
+				"printer_id":          job.PrinterID,
+				"printer_name":        printer.Name,
+				"backup_printer_id":   printedOn.ID,
+				"backup_printer_name": printedOn.Name,
+				"error":               updates["last_error"],
+			})
+		}
+	}
+	return nil
+}
+
+// backupPrinter returns the active backup printer of a printer, if any
+func (s *PrintSpoolerService) backupPrinter(printer *models.PrinterConfig) *models.PrinterConfig {
+	if printer.BackupPrinterID == nil || *printer.BackupPrinterID == printer.ID {
+		return nil
+	}
+	var backup models.PrinterConfig
+	if err := s.db.Where("id = ? AND is_active = ?", *printer.BackupPrinterID, true).First(&backup).Error; err != nil {
+		return nil
+	}
+	return &backup
+}
+
+// processPrinterQueue prints the due jobs of a printer in order. It stops at the
+// first job that cannot be printed: the printer is most likely down and the rest
+// of its jobs are tried again on the next check
+func (s *PrintSpoolerService) processPrinterQueue(printerID uint, stop chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		var job models.PrintJob
+		err := s.db.Where("printer_id = ? AND status IN ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)",
+			printerID, []string{models.PrintJobStatusPending, models.PrintJobStatusRetrying}, time.Now()).
+			Order("id").
+			First(&job).Error
+		if err != nil {
+			return
+		}
+		if err := s.processJob(&job); err != nil {
+			return
+		}
+	}
+}
+
+// Start begins the print spooler
+func (s *PrintSpoolerService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+	s.workers = make(map[uint]chan struct{})
+	printSpoolerRunning.Store(true)
+	go s.run(s.stopChan)
+	log.Println("Print spooler started")
+}
+
+// Stop stops the print spooler and its printer workers
+func (s *PrintSpoolerService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return
+	}
+	close(s.stopChan)
+	s.running = false
+	printSpoolerRunning.Store(false)
+	log.Println("Print spooler stopped")
+}
+
+// run dispatches queued jobs to the printer workers when woken by a new job
+// and periodically for due retries
+func (s *PrintSpoolerService) run(stop chan struct{}) {
+	if s.EnsureDB() == nil {
+		// Jobs left printing by a crash (or still printing on a worker of a previous start) may
+		// have printed; they are not sent again automatically, the user retries them
+		s.db.Model(&models.PrintJob{}).
+			Where("status = ?", models.PrintJobStatusPrinting).
+			Updates(map[string]interface{}{
+				"status":          models.PrintJobStatusUnknown,
+				"next_attempt_at": nil,
+				"last_error":      "impresión interrumpida, verifique si se imprimió",
+			})
+		s.dispatchDueJobs(stop)
+	}
+
+	ticker := time.NewTicker(printSpoolerCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case printerID := <-printJobWake:
+			s.wakeWorker(printerID, stop)
+		case <-ticker.C:
+			if s.EnsureDB() == nil {
+				s.dispatchDueJobs(stop)
+				if time.Since(s.lastCleanup) >= printSpoolerCleanupPeriod {
+					s.lastCleanup = time.Now()
+					s.cleanupOldJobs()
+				}
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// dispatchDueJobs wakes the workers of the printers with due jobs
+func (s *PrintSpoolerService) dispatchDueJobs(stop chan struct{}) {
+	var printerIDs []uint
+	err := s.db.Model(&models.PrintJob{}).
+		Where("status IN ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)",
+			[]string{models.PrintJobStatusPending, models.PrintJobStatusRetrying}, time.Now()).
+		Distinct().
+		Pluck("printer_id", &printerIDs).Error
+	if err != nil {
+		log.Printf("Print spooler: could not read queue: %v", err)
+		return
+	}
+	for _, printerID := range printerIDs {
+		s.wakeWorker(printerID, stop)
+	}
+}
+
+// wakeWorker starts the worker of a printer on first use and wakes it
+func (s *PrintSpoolerService) wakeWorker(printerID uint, stop chan struct{}) {
+	s.mu.Lock()
+	wake, ok := s.workers[printerID]
+	if !ok {
+		wake = make(chan struct{}, 1)
+		s.workers[printerID] = wake
+		go s.worker(printerID, wake, stop)
+	}
+	s.mu.Unlock()
+
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// worker processes the queue of one printer every time it is woken
+func (s *PrintSpoolerService) worker(printerID uint, wake chan struct{}, stop chan struct{}) {
+	for {
+		select {
+		case <-wake:
+			s.processPrinterQueue(printerID, stop)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// cleanupOldJobs deletes finished jobs older than the retention period
+func (s *PrintSpoolerService) cleanupOldJobs() {
+	cutoff := time.Now().AddDate(0, 0, -s.GetPrintSpoolerSettings().RetentionDays)
+	result := s.db.Where("status IN ? AND created_at < ?",
+		[]string{models.PrintJobStatusPrinted, models.PrintJobStatusFailed, models.PrintJobStatusCancelled, models.PrintJobStatusUnknown}, cutoff).
+		Delete(&models.PrintJob{})
+	if result.Error != nil {
+		log.Printf("Warning: Failed to purge old print jobs: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("Print spooler: purged %d old jobs", result.RowsAffected)
+	}
+}
+
+// ==================== JOBS ====================
+
+// GetPrintJobs returns the print jobs, newest first
+// status filters by status ("" = all) and printerID by printer (0 = all)
+func (s *PrintSpoolerService) GetPrintJobs(status string, printerID uint, limit int, offset int) ([]models.PrintJob, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > printJobsPageLimit {
+		limit = printJobsPageLimit
+	}
+
+	query := s.db.Omit("payload")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if printerID > 0 {
+		query = query.Where("printer_id = ?", printerID)
+	}
+
+	var jobs []models.PrintJob
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// GetPrintQueueStatus returns the queue summary of every printer
+func (s *PrintSpoolerService) GetPrintQueueStatus() ([]PrinterQueueStatus, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var printers []models.PrinterConfig
+	if err := s.db.Order("name").Find(&printers).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(printers))
+	for _, printer := range printers {
+		names[printer.ID] = printer.Name
+	}
+
+	var counts []struct {
+		PrinterID uint
+		Status    string
+		Count     int64
+	}
+	err := s.db.Model(&models.PrintJob{}).
+		Select("printer_id, status, COUNT(*) AS count").
+		Where("status IN ?", []string{models.PrintJobStatusPending, models.PrintJobStatusPrinting,
+			models.PrintJobStatusRetrying, models.PrintJobStatusFailed, models.PrintJobStatusUnknown}).
+		Group("printer_id, status").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]PrinterQueueStatus, 0, len(printers))
+	for _, printer := range printers {
+		status := PrinterQueueStatus{
+			PrinterID:       printer.ID,
+			PrinterName:     printer.Name,
+			IsActive:        printer.IsActive,
+			BackupPrinterID: printer.BackupPrinterID,
+		}
+		if printer.BackupPrinterID != nil {
+			status.BackupPrinterName = names[*printer.BackupPrinterID]
+		}
+		for _, count := range counts {
+			if count.PrinterID != printer.ID {
+				continue
+			}
+			switch count.Status {
+			case models.PrintJobStatusPending, models.PrintJobStatusPrinting:
+				status.Pending += count.Count
+			case models.PrintJobStatusRetrying:
+				status.Retrying += count.Count
+			case models.PrintJobStatusFailed, models.PrintJobStatusUnknown:
+				status.Failed += count.Count
+			}
+		}
+
+		var lastFailed models.PrintJob
+		if err := s.db.Select("last_error").
+			Where("printer_id = ? AND status IN ?", printer.ID, []string{models.PrintJobStatusRetrying, models.PrintJobStatusFailed, models.PrintJobStatusUnknown}).
+			Order("updated_at DESC").First(&lastFailed).Error; err == nil {
+			status.LastError = lastFailed.LastError
+		}
+		var lastPrinted models.PrintJob
+		if err := s.db.Select("printed_at").
+			Where("printed_on_id = ? AND status = ?", printer.ID, models.PrintJobStatusPrinted).
+			Order("printed_at DESC").First(&lastPrinted).Error; err == nil {
+			status.LastPrintedAt = lastPrinted.PrintedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// RetryPrintJob schedules one more attempt of a failed, retrying, unknown or cancelled job
+func (s *PrintSpoolerService) RetryPrintJob(id uint) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+
+	var job models.PrintJob
+	if err := s.db.Omit("payload").First(&job, id).Error; err != nil {
+		return fmt.Errorf("trabajo de impresión no encontrado")
+	}
+	if job.Status == models.PrintJobStatusPrinted {
+		return fmt.Errorf("el trabajo ya fue impreso, use reimprimir")
+	}
+	if job.Status == models.PrintJobStatusPrinting {
+		return fmt.Errorf("el trabajo se está imprimiendo")
+	}
+
+	maxAttempts := job.MaxAttempts
+	if job.Attempts >= maxAttempts {
+		maxAttempts = job.Attempts + 1
+	}
+	err := s.db.Model(&models.PrintJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"status":          models.PrintJobStatusPending,
+		"max_attempts":    maxAttempts,
+		"next_attempt_at": time.Now(),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	wakePrintSpooler(job.PrinterID)
+	return nil
+}
+
+// CancelPrintJob cancels a job that has not been printed yet
+func (s *PrintSpoolerService) CancelPrintJob(id uint) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.PrintJob{}).
+		Where("id = ? AND status IN ?", id, []string{models.PrintJobStatusPending, models.PrintJobStatusRetrying, models.PrintJobStatusUnknown}).
+		Updates(map[string]interface{}{"status": models.PrintJobStatusCancelled, "next_attempt_at": nil})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("solo se pueden cancelar trabajos pendientes")
+	}
+	return nil
+}
+
+// ReprintJob queues a copy of a job on its printer
+func (s *PrintSpoolerService) ReprintJob(id uint) (*models.PrintJob, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var job models.PrintJob
+	if err := s.db.First(&job, id).Error; err != nil {
+		return nil, fmt.Errorf("trabajo de impresión no encontrado")
+	}
+	return s.queueReprint(&job)
+}
+
+// ReprintLast queues a copy of the last n printed jobs, oldest first.
+// printerID filters by printer (0 = all) and jobType by type ("" = all); reprints are not repeated
+func (s *PrintSpoolerService) ReprintLast(n int, printerID uint, jobType string) ([]models.PrintJob, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	if n < 1 || n > printReprintLimit {
+		return nil, fmt.Errorf("se pueden reimprimir entre 1 y %d documentos", printReprintLimit)
+	}
+
+	query := s.db.Where("status = ? AND is_reprint = ?", models.PrintJobStatusPrinted, false)
+	if printerID > 0 {
+		query = query.Where("printer_id = ?", printerID)
+	}
+	if jobType != "" {
+		query = query.Where("job_type = ?", jobType)
+	}
+
+	var jobs []models.PrintJob
+	if err := query.Order("id DESC").Limit(n).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("no hay documentos impresos para reimprimir")
+	}
+
+	reprints := make([]models.PrintJob, 0, len(jobs))
+	for i := len(jobs) - 1; i >= 0; i-- {
+		reprint, err := s.queueReprint(&jobs[i])
+		if err != nil {
+			return reprints, err
+		}
+		reprints = append(reprints, *reprint)
+	}
+	return reprints, nil
+}
+
+// queueReprint queues a copy of a job marked as a reprint, without the cash drawer pulse
+func (s *PrintSpoolerService) queueReprint(job *models.PrintJob) (*models.PrintJob, error) {
+	data, err := base64.StdEncoding.DecodeString(job.Payload)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("el contenido del trabajo %d ya no está disponible", job.ID)
+	}
+
+	var printer models.PrinterConfig
+	if err := s.db.First(&printer, job.PrinterID).Error; err != nil {
+		return nil, fmt.Errorf("impresora %d no encontrada", job.PrinterID)
+	}
+
+	var payload bytes.Buffer
+	payload.Write([]byte{ESC, '@', ESC, 'a', 1, ESC, 'E', 1})
+	payload.WriteString("*** REIMPRESION ***\n")
+	payload.Write([]byte{ESC, 'E', 0, ESC, 'a', 0})
+	payload.Write(bytes.ReplaceAll(data, reprintDrawerKick, nil))
+
+	originalID := job.ID
+	reprint, err := queuePrintJob(s.db, &spoolJob{
+		Printer:       &printer,
+		JobType:       job.JobType,
+		Description:   job.Description,
+		ReferenceType: job.ReferenceType,
+		ReferenceID:   job.ReferenceID,
+		ReprintOfID:   &originalID,
+	}, payload.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return reprint, nil
+}
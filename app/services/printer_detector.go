@@ -9,14 +9,17 @@ import (
 
 // DetectedPrinter represents a printer detected in the system
 type DetectedPrinter struct {
-	Name           string `json:"name"`
-	Type           string `json:"type"`            // "usb", "network", "serial"
-	ConnectionType string `json:"connection_type"` // "usb", "ethernet", "serial"
-	Address        string `json:"address"`
-	Port           int    `json:"port"`
-	IsDefault      bool   `json:"is_default"`
-	Status         string `json:"status"` // "online", "offline", "unknown"
-	Model          string `json:"model"`
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`            // "usb", "network", "serial", "windows", "cups"
+	ConnectionType string   `json:"connection_type"` // "usb", "ethernet", "serial", "windows_share", "cups"
+	Address        string   `json:"address"`
+	Port           int      `json:"port"`
+	IsDefault      bool     `json:"is_default"`
+	Status         string   `json:"status"` // "online", "printing", "paper_out", "offline", "error", "unknown"
+	StatusMessage  string   `json:"status_message,omitempty"`
+	StateReasons   []string `json:"state_reasons,omitempty"` // IPP printer-state-reasons of CUPS queues
+	DeviceURI      string   `json:"device_uri,omitempty"`    // CUPS backend device
+	Model          string   `json:"model"`
 }
 
 // DetectSystemPrinters detects printers installed on the system
@@ -210,6 +213,10 @@ func parseWindowsWMICOutput(output string) []DetectedPrinter {
 
 // detectLinuxPrinters detects printers on Linux using CUPS
 func detectLinuxPrinters() ([]DetectedPrinter, error) {
+	if printers, err := detectCUPSPrinters(); err == nil {
+		return printers, nil
+	}
+
 	cmd := exec.Command("lpstat", "-p", "-d")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -241,18 +248,20 @@ func parseLinuxCUPSOutput(output string) []DetectedPrinter {
 				name := parts[1]
 				printer := DetectedPrinter{
 					Name:           name,
-					Type:           "usb",
-					ConnectionType: "usb",
-					Address:        "/dev/usb/lp0", // Default USB printer device
+					Type:           "cups",
+					ConnectionType: "cups",
+					Address:        name, // CUPS queue name
 					IsDefault:      name == defaultPrinter,
 					Status:         "unknown",
 				}
 
 				// Determine status
-				if strings.Contains(line, "idle") {
-					printer.Status = "online"
-				} else if strings.Contains(line, "disabled") {
+				if strings.Contains(line, "disabled") {
 					printer.Status = "offline"
+				} else if strings.Contains(line, "idle") {
+					printer.Status = "online"
+				} else if strings.Contains(line, "printing") {
+					printer.Status = "printing"
 				}
 
 				printers = append(printers, printer)
@@ -265,6 +274,10 @@ func parseLinuxCUPSOutput(output string) []DetectedPrinter {
 
 // detectMacOSPrinters detects printers on macOS using CUPS
 func detectMacOSPrinters() ([]DetectedPrinter, error) {
+	if printers, err := detectCUPSPrinters(); err == nil {
+		return printers, nil
+	}
+
 	cmd := exec.Command("lpstat", "-p", "-d")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -274,6 +287,31 @@ func detectMacOSPrinters() ([]DetectedPrinter, error) {
 	return parseLinuxCUPSOutput(string(output)), nil
 }
 
+// detectCUPSPrinters lists the CUPS queues with their state through IPP
+func detectCUPSPrinters() ([]DetectedPrinter, error) {
+	queues, defaultName, err := cupsListPrinters()
+	if err != nil {
+		return nil, err
+	}
+
+	printers := make([]DetectedPrinter, 0, len(queues))
+	for _, queue := range queues {
+		printers = append(printers, DetectedPrinter{
+			Name:           queue.Name,
+			Type:           "cups",
+			ConnectionType: "cups",
+			Address:        queue.Name,
+			IsDefault:      queue.Name == defaultName,
+			Status:         queue.Health(),
+			StatusMessage:  queue.StatusMessage(),
+			StateReasons:   queue.Reasons,
+			DeviceURI:      queue.DeviceURI,
+			Model:          queue.Model,
+		})
+	}
+	return printers, nil
+}
+
 // DetectSerialPorts detects available serial/USB ports for thermal printers
 func DetectSerialPorts() ([]string, error) {
 	switch runtime.GOOS {
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"PosApp/app/models"
+)
+
+const (
+	printerStatusMaxAge   = 2 * time.Minute // Older statuses are not used to hold jobs
+	printerMonitorMinWait = 5               // Seconds
+	printerMonitorMaxWait = 60              // Seconds
+)
+
+// printerStatuses keeps the last status of every monitored printer (printer ID -> *PrinterStatus)
+var printerStatuses sync.Map
+
+// printerStatusCheck carries the printer ID of printers to check right away
+var printerStatusCheck = make(chan uint, 64)
+
+// PrinterMonitorService polls the configured printers for their status, broadcasts
+// the changes through WebSocket and releases the held print jobs of a printer
+// when it recovers
+type PrinterMonitorService struct {
+	*BaseService
+	mu       sync.Mutex
+	stopChan chan struct{}
+	running  bool
+	wsServer WebSocketServer
+}
+
+// PrinterMonitorSettings are the monitor preferences (stored as system configs)
+type PrinterMonitorSettings struct {
+	Enabled         bool `json:"enabled"`
+	IntervalSeconds int  `json:"interval_seconds"` // Time between checks of each printer
+	KitchenFallback bool `json:"kitchen_fallback"` // Send kitchen tickets to the default printer when the kitchen printer and its backup are down
+}
+
+// NewPrinterMonitorService creates a new printer monitor service
+func NewPrinterMonitorService() *PrinterMonitorService {
+	return &PrinterMonitorService{
+		BaseService: NewBaseService(),
+	}
+}
+
+// SetWebSocketServer sets the WebSocket server used to notify status changes
+func (s *PrinterMonitorService) SetWebSocketServer(wsServer WebSocketServer) {
+	s.wsServer = wsServer
+}
+
+// GetPrinterMonitorSettings returns the monitor preferences
+func (s *PrinterMonitorService) GetPrinterMonitorSettings() PrinterMonitorSettings {
+	configSvc := NewConfigService()
+	return PrinterMonitorSettings{
+		Enabled:         configSvc.GetSystemConfigBool("printer_monitor_enabled", true),
+		IntervalSeconds: configSvc.GetSystemConfigInt("printer_monitor_interval", 15),
+		KitchenFallback: configSvc.GetSystemConfigBool("printer_monitor_kitchen_fallback", true),
+	}
+}
+
+// SavePrinterMonitorSettings saves the monitor preferences
+func (s *PrinterMonitorService) SavePrinterMonitorSettings(settings PrinterMonitorSettings) error {
+	if settings.IntervalSeconds < printerMonitorMinWait || settings.IntervalSeconds > printerMonitorMaxWait {
+		return fmt.Errorf("el intervalo debe estar entre %d y %d segundos", printerMonitorMinWait, printerMonitorMaxWait)
+	}
+
+	configSvc := NewConfigService()
+	values := []struct {
+		key, value, configType string
+	}{
+		{"printer_monitor_enabled", strconv.FormatBool(settings.Enabled), "boolean"},
+		{"printer_monitor_interval", strconv.Itoa(settings.IntervalSeconds), "number"},
+		{"printer_monitor_kitchen_fallback", strconv.FormatBool(settings.KitchenFallback), "boolean"},
+	}
+	for _, v := range values {
+		if err := configSvc.SetSystemConfig(v.key, v.value, v.configType, "printer"); err != nil {
+			return err
+		}
+	}
+	if !settings.Enabled {
+		clearPrinterStatuses()
+	}
+	return nil
+}
+
+// GetPrinterStatuses returns the last known status of the monitored printers
+func (s *PrinterMonitorService) GetPrinterStatuses() []PrinterStatus {
+	var statuses []PrinterStatus
+	printerStatuses.Range(func(_, value interface{}) bool {
+		statuses = append(statuses, *value.(*PrinterStatus))
+		return true
+	})
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].PrinterName < statuses[j].PrinterName })
+	return statuses
+}
+
+// CheckPrinterStatus reads the status of a printer right away
+func (s *PrinterMonitorService) CheckPrinterStatus(printerID uint) (*PrinterStatus, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	var config models.PrinterConfig
+	if err := s.db.First(&config, printerID).Error; err != nil {
+		return nil, fmt.Errorf("impresora no encontrada")
+	}
+
+	lock := printerSendLock(config.ID)
+	lock.Lock()
+	status, err := NewPrinterService().QueryPrinterStatus(&config)
+	lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	s.updateStatus(status)
+	return status, nil
+}
+
+// ==================== STATUS ====================
+
+// printerStatusBlocked returns the reason a printer cannot print, according to
+// its last recent status, or "" when it can (or its status is not known)
+func printerStatusBlocked(printerID uint) string {
+	value, ok := printerStatuses.Load(printerID)
+	if !ok {
+		return ""
+	}
+	status := value.(*PrinterStatus)
+	if !status.Blocks() || time.Since(status.CheckedAt) > printerStatusMaxAge {
+		return ""
+	}
+	return status.Message
+}
+
+// requestPrinterStatusCheck asks the monitor to check a printer, e.g. after a failed print.
+// When the channel is full the periodic check picks it up
+func requestPrinterStatusCheck(printerID uint) {
+	select {
+	case printerStatusCheck <- printerID:
+	default:
+	}
+}
+
+func clearPrinterStatuses() {
+	printerStatuses.Range(func(key, _ interface{}) bool {
+		printerStatuses.Delete(key)
+		return true
+	})
+}
+
+// updateStatus stores a status and, when it changed, notifies it and releases the
+// jobs held by the printer
+func (s *PrinterMonitorService) updateStatus(status *PrinterStatus) {
+	status.ChangedAt = status.CheckedAt
+	previous := ""
+	if value, ok := printerStatuses.Load(status.PrinterID); ok {
+		prev := value.(*PrinterStatus)
+		previous = prev.Status
+		if prev.Status == status.Status {
+			status.ChangedAt = prev.ChangedAt
+		}
+	}
+	printerStatuses.Store(status.PrinterID, status)
+
+	if previous == status.Status {
+		return
+	}
+	// The first check of a printer is only notified when it finds a problem, and a
+	// printer that stops (or starts) answering status requests is not news
+	quiet := func(s string) bool { return s == "" || s == PrinterStatusOnline || s == PrinterStatusUnknown }
+	if quiet(previous) && quiet(status.Status) {
+		return
+	}
+
+	log.Printf("Printer monitor: %s is %s (%s)", status.PrinterName, status.Status, status.Message)
+	if s.wsServer != nil {
+		s.wsServer.BroadcastJSON("printer_status_changed", map[string]interface{}{
+			"printer_id":      status.PrinterID,
+			"printer_name":    status.PrinterName,
+			"status":          status.Status,
+			"previous_status": previous,
+			"message":         status.Message,
+			"blocked":         status.Blocks(),
+		})
+	}
+
+	if !status.Blocks() {
+		s.releaseHeldJobs(status.PrinterID)
+	}
+}
+
+// releaseHeldJobs retries right away the jobs waiting for a printer that recovered,
+// including those of the printers it backs up
+func (s *PrinterMonitorService) releaseHeldJobs(printerID uint) {
+	if s.EnsureDB() != nil {
+		return
+	}
+	printerIDs := []uint{printerID}
+	var backedUp []uint
+	s.db.Model(&models.PrinterConfig{}).Where("backup_printer_id = ?", printerID).Pluck("id", &backedUp)
+	printerIDs = append(printerIDs, backedUp...)
+
+	s.db.Model(&models.PrintJob{}).
+		Where("printer_id IN ? AND status = ?", printerIDs, models.PrintJobStatusRetrying).
+		Update("next_attempt_at", time.Now())
+	for _, id := range printerIDs {
+		wakePrintSpooler(id)
+	}
+}
+
+// ==================== MONITOR ====================
+
+// Start begins monitoring the printers
+func (s *PrinterMonitorService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+	go s.run(s.stopChan)
+	log.Println("Printer monitor started")
+}
+
+// Stop stops monitoring the printers; their statuses are forgotten
+func (s *PrinterMonitorService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return
+	}
+	close(s.stopChan)
+	s.running = false
+	clearPrinterStatuses()
+	log.Println("Printer monitor stopped")
+}
+
+// run checks all printers every interval and single printers on request
+func (s *PrinterMonitorService) run(stop chan struct{}) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			settings := s.GetPrinterMonitorSettings()
+			if settings.Enabled && s.EnsureDB() == nil {
+				s.checkAll()
+			} else {
+				clearPrinterStatuses()
+			}
+			interval := settings.IntervalSeconds
+			if interval < printerMonitorMinWait || interval > printerMonitorMaxWait {
+				interval = 15
+			}
+			timer.Reset(time.Duration(interval) * time.Second)
+		case printerID := <-printerStatusCheck:
+			if s.GetPrinterMonitorSettings().Enabled && s.EnsureDB() == nil {
+				var config models.PrinterConfig
+				if err := s.db.Where("id = ? AND is_active = ?", printerID, true).First(&config).Error; err == nil {
+					s.checkPrinter(&config)
+				}
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// checkAll checks the active printers in parallel and forgets the removed ones
+func (s *PrinterMonitorService) checkAll() {
+	var printers []models.PrinterConfig
+	if err := s.db.Where("is_active = ?", true).Find(&printers).Error; err != nil {
+		log.Printf("Printer monitor: could not read printers: %v", err)
+		return
+	}
+
+	monitored := make(map[uint]bool)
+	var wg sync.WaitGroup
+	for i := range printers {
+		if !printerStatusSupported(resolvePrinterType(&printers[i])) {
+			continue
+		}
+		monitored[printers[i].ID] = true
+		wg.Add(1)
+		go func(config *models.PrinterConfig) {
+			defer wg.Done()
+			s.checkPrinter(config)
+		}(&printers[i])
+	}
+	wg.Wait()
+
+	printerStatuses.Range(func(key, _ interface{}) bool {
+		if !monitored[key.(uint)] {
+			printerStatuses.Delete(key)
+		}
+		return true
+	})
+}
+
+// checkPrinter reads the status of a printer unless it is printing; most printers
+// take a single connection and the status request would wait behind the job
+func (s *PrinterMonitorService) checkPrinter(config *models.PrinterConfig) {
+	lock := printerSendLock(config.ID)
+	if !lock.TryLock() {
+		return
+	}
+	status, err := NewPrinterService().QueryPrinterStatus(config)
+	lock.Unlock()
+	if err != nil {
+		return
+	}
+	s.updateStatus(status)
+}
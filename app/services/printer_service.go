@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"image"
@@ -15,6 +16,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -37,14 +39,21 @@ const (
 	FF  byte = 0x0C
 )
 
+const (
+	printerConnectTimeout = 5 * time.Second  // Dial timeout of network printers
+	printerWriteTimeout   = 30 * time.Second // Write deadline of network printers
+)
+
 // PrinterService handles thermal printer operations
 type PrinterService struct {
 	db                 *gorm.DB
 	connection         io.WriteCloser
 	buffer             *bytes.Buffer
 	windowsPrinterName string                 // For Windows shared printers
-	printerType        string                 // "usb", "network", "serial", "file", "windows"
+	cupsQueue          string                 // For CUPS queues (name or IPP URI)
+	printerType        string                 // "usb", "network", "serial", "file", "windows", "cups"
 	currentConfig      *models.PrinterConfig  // Current printer configuration
+	job                *spoolJob              // Document being rendered for the print spooler
 }
 
 // NewPrinterService creates a new printer service
@@ -326,10 +335,23 @@ func (s *PrinterService) printImage(img image.Image) error {
 }
 
 func (s *PrinterService) print() error {
+	// Queue the document when it was started for the print spooler
+	if s.job != nil {
+		job := s.job
+		s.job = nil
+		data := append([]byte(nil), s.buffer.Bytes()...)
+		s.buffer.Reset()
+		_, err := queuePrintJob(s.db, job, data)
+		return err
+	}
+
 	// Handle Windows printers differently
 	if s.printerType == "windows" {
 		return s.printToWindowsPrinter()
 	}
+	if s.printerType == "cups" {
+		return s.printToCUPSPrinter()
+	}
 
 	// For other types, write directly to connection
 	if s.connection == nil {
@@ -482,7 +504,10 @@ if (-not $result) {
 Write-Output "Print job sent successfully"
 `, s.windowsPrinterName, tmpFilePath)
 
-	cmd := exec.Command("powershell", "-Command", psScript)
+	// A stuck spooler call must not hold the printer lock of the print spooler forever
+	ctx, cancel := context.WithTimeout(context.Background(), printerWriteTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "powershell", "-Command", psScript)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -492,6 +517,24 @@ Write-Output "Print job sent successfully"
 	return nil
 }
 
+// printToCUPSPrinter submits the buffer as a raw job to a CUPS queue through IPP
+func (s *PrinterService) printToCUPSPrinter() error {
+	if s.cupsQueue == "" {
+		return fmt.Errorf("no CUPS queue specified")
+	}
+	data := append([]byte(nil), s.buffer.Bytes()...)
+	s.buffer.Reset()
+
+	jobName := "PosApp"
+	if s.currentConfig != nil && s.currentConfig.Name != "" {
+		jobName = "PosApp - " + s.currentConfig.Name
+	}
+	if _, err := cupsPrintRaw(s.cupsQueue, jobName, data); err != nil {
+		return fmt.Errorf("failed to send to CUPS printer '%s': %w", s.cupsQueue, err)
+	}
+	return nil
+}
+
 // PrintReceipt prints a receipt for a sale using the default printer
 func (s *PrinterService) PrintReceipt(sale *models.Sale, isElectronicInvoice bool) error {
 	return s.PrintReceiptWithPrinter(sale, isElectronicInvoice, 0)
@@ -515,12 +558,23 @@ func (s *PrinterService) PrintReceiptWithPrinter(sale *models.Sale, isElectronic
 		}
 	}
 
-	if err := s.connectPrinter(config); err != nil {
+	jobType, description := models.PrintJobReceipt, fmt.Sprintf("Recibo venta %s", sale.SaleNumber)
+	if isElectronicInvoice && sale.ElectronicInvoice != nil {
+		jobType, description = models.PrintJobInvoice, fmt.Sprintf("Factura %s%s", sale.ElectronicInvoice.Prefix, sale.ElectronicInvoice.InvoiceNumber)
+	}
+	if err := s.beginJob(config, jobType, description, "sale", sale.ID); err != nil {
 		return fmt.Errorf("failed to connect to printer: %w", err)
 	}
 	defer s.closePrinter()
 
-	if isElectronicInvoice && sale.ElectronicInvoice != nil {
+	electronic := isElectronicInvoice && sale.ElectronicInvoice != nil
+	if printed, err := s.printWithReceiptTemplate(jobType, config, func() *ReceiptDocument {
+		return s.saleReceiptDocument(sale, config, electronic)
+	}); printed {
+		return err
+	}
+
+	if electronic {
 		return s.printElectronicInvoice(sale, config)
 	}
 	return s.printSimpleReceipt(sale, config)
@@ -541,6 +595,12 @@ func (s *PrinterService) printElectronicInvoice(sale *models.Sale, config *model
 	var dianConfig models.DIANConfig
 	s.db.First(&dianConfig)
 
+	// POS equivalent documents use their own title, resolution and CUDE
+	isPOSDocument := sale.ElectronicInvoice.DocumentType == "pos_equivalent"
+
+	// Contingency invoices are issued with their own range while DIAN is unavailable
+	isContingency := sale.ElectronicInvoice.IsContingency
+
 	// Print logo if available
 	if restaurant.Logo != "" {
 		s.lineFeed()
@@ -554,8 +614,16 @@ func (s *PrinterService) printElectronicInvoice(sale *models.Sale, config *model
 	// Print header title - FACTURA ELECTRÓNICA DE VENTA (split in 2 lines)
 	s.setEmphasize(true)
 	s.setSize(2, 2)
-	s.write("FACTURA ELECTRONICA\n")
-	s.write("DE VENTA\n")
+	if isPOSDocument {
+		s.write("DOCUMENTO\n")
+		s.write("EQUIVALENTE POS\n")
+	} else if isContingency {
+		s.write("FACTURA DE\n")
+		s.write("CONTINGENCIA\n")
+	} else {
+		s.write("FACTURA ELECTRONICA\n")
+		s.write("DE VENTA\n")
+	}
 	s.setSize(1, 1)
 	s.setEmphasize(false)
 	s.lineFeed()
@@ -598,8 +666,29 @@ func (s *PrinterService) printElectronicInvoice(sale *models.Sale, config *model
 	}
 
 	// Print Resolution info on separate lines
+	if isPOSDocument {
+		dianConfig.ResolutionNumber = dianConfig.POSResolutionNumber
+		dianConfig.ResolutionPrefix = dianConfig.POSResolutionPrefix
+		dianConfig.ResolutionFrom = dianConfig.POSResolutionFrom
+		dianConfig.ResolutionTo = dianConfig.POSResolutionTo
+		dianConfig.ResolutionDateFrom = dianConfig.POSResolutionDateFrom
+		dianConfig.ResolutionDateTo = dianConfig.POSResolutionDateTo
+	} else if isContingency {
+		dianConfig.ResolutionNumber = dianConfig.ContingencyResolutionNumber
+		dianConfig.ResolutionPrefix = dianConfig.ContingencyResolutionPrefix
+		dianConfig.ResolutionFrom = dianConfig.ContingencyResolutionFrom
+		dianConfig.ResolutionTo = dianConfig.ContingencyResolutionTo
+		dianConfig.ResolutionDateFrom = dianConfig.ContingencyResolutionDateFrom
+		dianConfig.ResolutionDateTo = dianConfig.ContingencyResolutionDateTo
+	}
 	if dianConfig.ResolutionNumber != "" {
-		s.write(fmt.Sprintf("Resolucion de Facturacion Electronica No. %s\n", dianConfig.ResolutionNumber))
+		if isPOSDocument {
+			s.write(fmt.Sprintf("Resolucion Documento Equivalente POS No. %s\n", dianConfig.ResolutionNumber))
+		} else if isContingency {
+			s.write(fmt.Sprintf("Resolucion Facturacion de Contingencia No. %s\n", dianConfig.ResolutionNumber))
+		} else {
+			s.write(fmt.Sprintf("Resolucion de Facturacion Electronica No. %s\n", dianConfig.ResolutionNumber))
+		}
 		if !dianConfig.ResolutionDateFrom.IsZero() {
 			s.write(fmt.Sprintf("de %s, Prefijo: %s, Rango %d Al %d\n",
 				dianConfig.ResolutionDateFrom.Format("2006-01-02"),
@@ -652,7 +741,14 @@ func (s *PrinterService) printElectronicInvoice(sale *models.Sale, config *model
 	// Print invoice number and dates
 	s.write(s.printSeparator())
 	s.setEmphasize(true)
-	s.write(fmt.Sprintf("Factura: %s%s\n",
+	documentLabel := "Factura"
+	if isPOSDocument {
+		documentLabel = "Documento POS"
+	} else if isContingency {
+		documentLabel = "Factura contingencia"
+	}
+	s.write(fmt.Sprintf("%s: %s%s\n",
+		documentLabel,
 		sale.ElectronicInvoice.Prefix,
 		sale.ElectronicInvoice.InvoiceNumber))
 	s.setEmphasize(false)
@@ -788,6 +884,11 @@ func (s *PrinterService) printElectronicInvoice(sale *models.Sale, config *model
 	s.write(fmt.Sprintf("TOTAL: $%s\n", s.formatMoney(sale.Total)))
 	s.setSize(1, 1)
 	s.setEmphasize(false)
+	// Voluntary tip is not part of the invoice total
+	if sale.Tip > 0 {
+		s.write(fmt.Sprintf("Propina voluntaria: $%s\n", s.formatMoney(sale.Tip)))
+		s.write(fmt.Sprintf("Total pagado: $%s\n", s.formatMoney(sale.Total+sale.Tip)))
+	}
 	s.setAlign("left")
 
 	// Print payment method with DIAN parametric names (only one per invoice)
@@ -815,36 +916,56 @@ func (s *PrinterService) printElectronicInvoice(sale *models.Sale, config *model
 	// Print footer
 	s.lineFeed()
 	s.setAlign("center")
-	s.write("*** REPRESENTACIÓN IMPRESA DE LA ***\n")
-	s.write("*** FACTURA ELECTRÓNICA DE VENTA ***\n")
-	s.lineFeed()
-	s.write("Validar en:\n")
-	s.write("https://catalogo-vpfe.dian.gov.co\n")
-
-	// Print QR Code as image using the URL from DIAN response (QRStr)
-	s.lineFeed()
-	s.setAlign("center")
-	qrURL := sale.ElectronicInvoice.QRCode // Use QR URL from DIAN response
-	if qrURL == "" {
-		// Fallback if QRCode not available (shouldn't happen with valid DIAN response)
-		qrURL = fmt.Sprintf("https://catalogo-vpfe.dian.gov.co/document/searchqr?documentkey=%s", sale.ElectronicInvoice.CUFE)
-	}
-	if err := s.printQRCodeAsImage(qrURL, 256); err != nil {
-		// If QR fails, show placeholder
-		s.write("[ CÓDIGO QR - ERROR ]\n")
-		s.write("(Error al generar QR)\n")
+	if isPOSDocument {
+		s.write("*** REPRESENTACIÓN IMPRESA DEL ***\n")
+		s.write("*** DOCUMENTO EQUIVALENTE ELECTRÓNICO POS ***\n")
+	} else if isContingency {
+		s.write("*** FACTURA DE CONTINGENCIA ***\n")
+		s.write("*** EXPEDIDA POR FALLA DEL SERVICIO DIAN ***\n")
+	} else {
+		s.write("*** REPRESENTACIÓN IMPRESA DE LA ***\n")
+		s.write("*** FACTURA ELECTRÓNICA DE VENTA ***\n")
 	}
 	s.lineFeed()
-	s.lineFeed()
 
-	// Print CUFE text below QR
-	s.setAlign("center")
-	s.setEmphasize(true)
-	s.write("CUFE:\n")
-	s.setEmphasize(false)
-	s.setAlign("left")
-	s.write(s.wrapText(sale.ElectronicInvoice.CUFE, 48))
-	s.lineFeed()
+	// A contingency invoice has no CUFE until it is reported to DIAN
+	if sale.ElectronicInvoice.CUFE == "" && isContingency {
+		s.write("Pendiente de reporte a la DIAN.\n")
+		s.write(fmt.Sprintf("Expedida: %s\n", sale.ElectronicInvoice.CreatedAt.Format("2006-01-02 15:04:05")))
+		s.setAlign("left")
+	} else {
+		s.write("Validar en:\n")
+		s.write("https://catalogo-vpfe.dian.gov.co\n")
+
+		// Print QR Code as image using the URL from DIAN response (QRStr)
+		s.lineFeed()
+		s.setAlign("center")
+		qrURL := sale.ElectronicInvoice.QRCode // Use QR URL from DIAN response
+		if qrURL == "" {
+			// Fallback if QRCode not available (shouldn't happen with valid DIAN response)
+			qrURL = fmt.Sprintf("https://catalogo-vpfe.dian.gov.co/document/searchqr?documentkey=%s", sale.ElectronicInvoice.CUFE)
+		}
+		if err := s.printQRCodeAsImage(qrURL, 256); err != nil {
+			// If QR fails, show placeholder
+			s.write("[ CÓDIGO QR - ERROR ]\n")
+			s.write("(Error al generar QR)\n")
+		}
+		s.lineFeed()
+		s.lineFeed()
+
+		// Print CUFE text below QR
+		s.setAlign("center")
+		s.setEmphasize(true)
+		if isPOSDocument {
+			s.write("CUDE:\n")
+		} else {
+			s.write("CUFE:\n")
+		}
+		s.setEmphasize(false)
+		s.setAlign("left")
+		s.write(s.wrapText(sale.ElectronicInvoice.CUFE, 48))
+		s.lineFeed()
+	}
 
 	// Employee info
 	s.lineFeed()
@@ -853,6 +974,9 @@ func (s *PrinterService) printElectronicInvoice(sale *models.Sale, config *model
 	s.db.Preload("Employee").First(sale, sale.ID)
 	s.write(fmt.Sprintf("Atendió: %s\n", sale.Employee.Name))
 	s.write(fmt.Sprintf("Caja: %d\n", sale.CashRegisterID))
+	if isPOSDocument && dianConfig.POSPlateNumber != "" {
+		s.write(fmt.Sprintf("Placa caja: %s\n", dianConfig.POSPlateNumber))
+	}
 
 	// Final message
 	s.lineFeed()
@@ -996,6 +1120,10 @@ func (s *PrinterService) printSimpleReceipt(sale *models.Sale, config *models.Pr
 	s.setEmphasize(true)
 	s.write(fmt.Sprintf("TOTAL: $%s\n", s.formatMoney(sale.Total)))
 	s.setEmphasize(false)
+	if sale.Tip > 0 {
+		s.write(fmt.Sprintf("Propina voluntaria: $%s\n", s.formatMoney(sale.Tip)))
+		s.write(fmt.Sprintf("Total pagado: $%s\n", s.formatMoney(sale.Total+sale.Tip)))
+	}
 
 	// Payment info
 	s.write(s.printSeparator())
@@ -1035,11 +1163,17 @@ func (s *PrinterService) PrintKitchenOrder(order *models.Order) error {
 		return fmt.Errorf("no kitchen printer configured: %w", err)
 	}
 
-	if err := s.connectPrinter(config); err != nil {
+	if err := s.beginJob(config, models.PrintJobKitchen, fmt.Sprintf("Comanda orden %s", order.OrderNumber), "order", order.ID); err != nil {
 		return fmt.Errorf("failed to connect to printer: %w", err)
 	}
 	defer s.closePrinter()
 
+	if printed, err := s.printWithReceiptTemplate(models.PrintJobKitchen, config, func() *ReceiptDocument {
+		return s.orderReceiptDocument(order, config, true)
+	}); printed {
+		return err
+	}
+
 	s.init()
 	s.setAlign("center")
 
@@ -1146,11 +1280,17 @@ func (s *PrinterService) PrintOrder(order *models.Order) error {
 		return fmt.Errorf("no default printer configured: %w", err)
 	}
 
-	if err := s.connectPrinter(config); err != nil {
+	if err := s.beginJob(config, models.PrintJobOrder, fmt.Sprintf("Orden %s", order.OrderNumber), "order", order.ID); err != nil {
 		return fmt.Errorf("failed to connect to printer: %w", err)
 	}
 	defer s.closePrinter()
 
+	if printed, err := s.printWithReceiptTemplate(models.PrintJobOrder, config, func() *ReceiptDocument {
+		return s.orderReceiptDocument(order, config, false)
+	}); printed {
+		return err
+	}
+
 	s.init()
 	s.setAlign("center")
 
@@ -1328,7 +1468,11 @@ func (s *PrinterService) PrintWaiterReceipt(orderData map[string]interface{}, pr
 		}
 	}
 
-	if err := s.connectPrinter(config); err != nil {
+	description := "Cuenta mesero"
+	if tn, ok := orderData["table_number"].(string); ok && tn != "" {
+		description = fmt.Sprintf("Cuenta mesa %s", tn)
+	}
+	if err := s.beginJob(config, models.PrintJobWaiterReceipt, description, "", 0); err != nil {
 		return fmt.Errorf("failed to connect to printer: %w", err)
 	}
 	defer s.closePrinter()
@@ -1506,11 +1650,17 @@ func (s *PrinterService) PrintCashRegisterReport(report *models.CashRegisterRepo
 		return err
 	}
 
-	if err := s.connectPrinter(config); err != nil {
+	if err := s.beginJob(config, models.PrintJobCashRegisterReport, fmt.Sprintf("Cierre de caja #%d", report.ID), "cash_register_report", report.ID); err != nil {
 		return err
 	}
 	defer s.closePrinter()
 
+	if printed, err := s.printWithReceiptTemplate(models.PrintJobCashRegisterReport, config, func() *ReceiptDocument {
+		return s.cashRegisterReceiptDocument(report, config)
+	}); printed {
+		return err
+	}
+
 	s.init()
 	s.setAlign("center")
 
@@ -1566,6 +1716,9 @@ func (s *PrinterService) PrintCashRegisterReport(report *models.CashRegisterRepo
 	s.write(fmt.Sprintf("Tarjetas: $%s\n", s.formatMoney(report.TotalCard)))
 	s.write(fmt.Sprintf("Digital: $%s\n", s.formatMoney(report.TotalDigital)))
 	s.write(fmt.Sprintf("Otros: $%s\n", s.formatMoney(report.TotalOther)))
+	if report.TotalTips > 0 {
+		s.write(fmt.Sprintf("Propinas: $%s\n", s.formatMoney(report.TotalTips)))
+	}
 	s.lineFeed()
 
 	// Cash movements
@@ -1574,6 +1727,9 @@ func (s *PrinterService) PrintCashRegisterReport(report *models.CashRegisterRepo
 	s.setEmphasize(false)
 	s.write(fmt.Sprintf("Base Inicial: $%s\n", s.formatMoney(report.OpeningBalance)))
 	s.write(fmt.Sprintf("Ventas en Efectivo: $%s\n", s.formatMoney(report.TotalCash)))
+	if report.CashTips > 0 {
+		s.write(fmt.Sprintf("Propinas en Efectivo: +$%s\n", s.formatMoney(report.CashTips)))
+	}
 	s.write(fmt.Sprintf("Depósitos: +$%s\n", s.formatMoney(report.CashDeposits)))
 	s.write(fmt.Sprintf("Retiros: -$%s\n", s.formatMoney(report.CashWithdrawals)))
 	s.write(s.printSeparator())
@@ -1594,6 +1750,30 @@ func (s *PrinterService) PrintCashRegisterReport(report *models.CashRegisterRepo
 	}
 	s.setEmphasize(false)
 
+	// Bold reconciliation summary
+	if recon := report.BoldReconciliation; recon != nil {
+		s.write(s.printSeparator())
+		s.setEmphasize(true)
+		s.write("CONCILIACION BOLD\n")
+		s.setEmphasize(false)
+		s.write(fmt.Sprintf("Transacciones Bold: %d ($%s)\n", recon.BoldCount, s.formatMoney(recon.BoldTotal)))
+		s.write(fmt.Sprintf("Pagos Bold en POS: %d ($%s)\n", recon.POSCount, s.formatMoney(recon.POSTotal)))
+		s.write(fmt.Sprintf("Conciliados: %d\n", recon.MatchedCount))
+		if recon.Status == "discrepancies" {
+			if recon.AmountMismatchCount > 0 {
+				s.write(fmt.Sprintf("Diferencia de monto: %d\n", recon.AmountMismatchCount))
+			}
+			if recon.BoldOrphanCount > 0 {
+				s.write(fmt.Sprintf("Bold sin venta: %d ($%s)\n", recon.BoldOrphanCount, s.formatMoney(recon.BoldOrphanTotal)))
+			}
+			if recon.POSOrphanCount > 0 {
+				s.write(fmt.Sprintf("Venta sin aprobar: %d ($%s)\n", recon.POSOrphanCount, s.formatMoney(recon.POSOrphanTotal)))
+			}
+		} else {
+			s.write("(SIN NOVEDADES)\n")
+		}
+	}
+
 	// Notes if any
 	if report.Notes != "" {
 		s.lineFeed()
@@ -1637,22 +1817,7 @@ func (s *PrinterService) connectPrinter(config *models.PrinterConfig) error {
 	// Save current config for later use (e.g., calculating image width)
 	s.currentConfig = config
 
-	// Auto-detect type if empty based on address and connection_type
-	printerType := config.Type
-	if printerType == "" {
-		if strings.HasPrefix(config.Address, "\\\\") {
-			printerType = "windows"
-		} else if config.ConnectionType == "ethernet" || config.ConnectionType == "network" {
-			printerType = "network"
-		} else if config.ConnectionType == "serial" {
-			printerType = "serial"
-		} else if config.ConnectionType == "windows_share" {
-			printerType = "windows"
-		} else {
-			printerType = "usb"
-		}
-	}
-
+	printerType := resolvePrinterType(config)
 	s.printerType = printerType
 
 	switch printerType {
@@ -1664,12 +1829,13 @@ func (s *PrinterService) connectPrinter(config *models.PrinterConfig) error {
 		}
 
 	case "network":
-		// Network printer connection
-		address := fmt.Sprintf("%s:%d", config.Address, config.Port)
-		conn, err := net.Dial("tcp", address)
+		// Network printer connection; a printer that is off must not hang the caller
+		address := net.JoinHostPort(config.Address, strconv.Itoa(config.Port))
+		conn, err := net.DialTimeout("tcp", address, printerConnectTimeout)
 		if err != nil {
 			return fmt.Errorf("failed to connect to network printer at %s: %w", address, err)
 		}
+		conn.SetWriteDeadline(time.Now().Add(printerWriteTimeout))
 		// Wrap the net.Conn to implement io.WriteCloser
 		s.connection = conn
 
@@ -1706,6 +1872,17 @@ func (s *PrinterService) connectPrinter(config *models.PrinterConfig) error {
 			return fmt.Errorf("Windows printer type only supported on Windows OS")
 		}
 
+	case "cups":
+		// CUPS queue on the local server or IPP printer URI; the job is submitted on
+		// print(). A queue that cannot print now fails here like an unreachable printer
+		if runtime.GOOS == "windows" {
+			return fmt.Errorf("CUPS printer type not supported on Windows OS")
+		}
+		s.cupsQueue = config.Address
+		if err := cupsPrinterReady(config.Address); err != nil {
+			return err
+		}
+
 	default:
 		return fmt.Errorf("unsupported printer type: %s", config.Type)
 	}
@@ -1713,6 +1890,47 @@ func (s *PrinterService) connectPrinter(config *models.PrinterConfig) error {
 	return err
 }
 
+// resolvePrinterType returns the printer type, auto-detected from the address and
+// connection type when it is empty
+func resolvePrinterType(config *models.PrinterConfig) string {
+	if config.Type != "" {
+		return config.Type
+	}
+	if strings.HasPrefix(config.Address, "\\\\") {
+		return "windows"
+	} else if config.ConnectionType == "ethernet" || config.ConnectionType == "network" {
+		return "network"
+	} else if config.ConnectionType == "serial" {
+		return "serial"
+	} else if config.ConnectionType == "windows_share" {
+		return "windows"
+	} else if config.ConnectionType == "cups" {
+		return "cups"
+	}
+	return "usb"
+}
+
+// beginJob starts rendering a document for config. While the print spooler is running
+// the document is queued as a PrintJob by print(); otherwise the printer is connected
+// right away and print() writes to it
+func (s *PrinterService) beginJob(config *models.PrinterConfig, jobType, description, referenceType string, referenceID uint) error {
+	s.buffer.Reset()
+	s.job = nil
+
+	if printSpoolerEnabled() {
+		s.currentConfig = config
+		s.job = &spoolJob{
+			Printer:       config,
+			JobType:       jobType,
+			Description:   description,
+			ReferenceType: referenceType,
+			ReferenceID:   referenceID,
+		}
+		return nil
+	}
+	return s.connectPrinter(config)
+}
+
 func (s *PrinterService) closePrinter() {
 	if s.connection != nil {
 		s.connection.Close()
@@ -2069,7 +2287,7 @@ func (s *PrinterService) PrintDIANClosingReport(report *DIANClosingReport, perio
 		return fmt.Errorf("no default printer configured: %w", err)
 	}
 
-	if err := s.connectPrinter(config); err != nil {
+	if err := s.beginJob(config, models.PrintJobDIANClosingReport, fmt.Sprintf("Reporte DIAN %s %s", period, report.ReportDate), "dian_closing_report", 0); err != nil {
 		return fmt.Errorf("failed to connect to printer: %w", err)
 	}
 	defer s.closePrinter()
@@ -2287,7 +2505,7 @@ func (s *PrinterService) PrintCustomerDataForm() error {
 		return fmt.Errorf("no default printer configured: %w", err)
 	}
 
-	if err := s.connectPrinter(config); err != nil {
+	if err := s.beginJob(config, models.PrintJobCustomerForm, "Formulario datos cliente", "", 0); err != nil {
 		return fmt.Errorf("failed to connect to printer: %w", err)
 	}
 	defer s.closePrinter()
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"PosApp/app/models"
+)
+
+// Printer status values
+const (
+	PrinterStatusOnline       = "online"
+	PrinterStatusPaperNearEnd = "paper_near_end" // Can still print
+	PrinterStatusPaperOut     = "paper_out"
+	PrinterStatusCoverOpen    = "cover_open"
+	PrinterStatusOffline      = "offline"
+	PrinterStatusError        = "error"
+	PrinterStatusUnknown      = "unknown" // Connected but the printer does not answer status requests
+)
+
+// printerStatusReadTimeout is the wait for each real-time status reply
+const printerStatusReadTimeout = 2 * time.Second
+
+// PrinterStatus is the state of a configured printer read with the ESC/POS
+// real-time status commands (or IPP for CUPS queues)
+type PrinterStatus struct {
+	PrinterID    uint      `json:"printer_id"`
+	PrinterName  string    `json:"printer_name"`
+	Status       string    `json:"status"`
+	Message      string    `json:"message"`
+	Offline      bool      `json:"offline"`
+	CoverOpen    bool      `json:"cover_open"`
+	PaperNearEnd bool      `json:"paper_near_end"`
+	PaperOut     bool      `json:"paper_out"`
+	Error        bool      `json:"error"`
+	CheckedAt    time.Time `json:"checked_at"`
+	ChangedAt    time.Time `json:"changed_at"` // When the printer entered its current status
+}
+
+// Blocks reports whether the printer cannot print in its current status
+func (p *PrinterStatus) Blocks() bool {
+	switch p.Status {
+	case PrinterStatusPaperOut, PrinterStatusCoverOpen, PrinterStatusOffline, PrinterStatusError:
+		return true
+	}
+	return false
+}
+
+// resolve sets Status and Message from the flags, the most serious condition first
+func (p *PrinterStatus) resolve(detail string) {
+	switch {
+	case p.CoverOpen:
+		p.Status, p.Message = PrinterStatusCoverOpen, "Tapa abierta"
+	case p.PaperOut:
+		p.Status, p.Message = PrinterStatusPaperOut, "Sin papel"
+	case p.Error:
+		p.Status, p.Message = PrinterStatusError, "Error en la impresora"
+	case p.Offline:
+		p.Status, p.Message = PrinterStatusOffline, "Impresora fuera de línea"
+	case p.PaperNearEnd:
+		p.Status, p.Message = PrinterStatusPaperNearEnd, "Papel por agotarse"
+	default:
+		p.Status, p.Message = PrinterStatusOnline, "Lista"
+	}
+	if detail != "" {
+		p.Message += ": " + detail
+	}
+}
+
+// printerStatusSupported reports whether the state of a printer type can be read
+func printerStatusSupported(printerType string) bool {
+	switch printerType {
+	case "network", "usb", "serial", "cups":
+		return true
+	}
+	return false
+}
+
+// QueryPrinterStatus reads the current state of a printer. Network, USB and serial
+// printers are asked with DLE EOT; CUPS queues through IPP
+func (s *PrinterService) QueryPrinterStatus(config *models.PrinterConfig) (*PrinterStatus, error) {
+	printerType := resolvePrinterType(config)
+	if !printerStatusSupported(printerType) {
+		return nil, fmt.Errorf("el estado no está disponible para impresoras tipo %s", printerType)
+	}
+
+	status := &PrinterStatus{
+		PrinterID:   config.ID,
+		PrinterName: config.Name,
+		CheckedAt:   time.Now(),
+	}
+
+	if printerType == "cups" {
+		state, err := cupsGetPrinterState(config.Address)
+		if err != nil {
+			status.Offline = true
+			status.resolve(err.Error())
+			return status, nil
+		}
+		cupsPrinterStatus(status, state)
+		return status, nil
+	}
+
+	var conn io.ReadWriteCloser
+	if printerType == "network" {
+		address := net.JoinHostPort(config.Address, strconv.Itoa(config.Port))
+		netConn, err := net.DialTimeout("tcp", address, printerConnectTimeout)
+		if err != nil {
+			status.Offline = true
+			status.resolve("sin conexión con " + address)
+			return status, nil
+		}
+		conn = netConn
+	} else {
+		file, err := os.OpenFile(config.Address, os.O_RDWR, 0)
+		if err != nil {
+			status.Offline = true
+			status.resolve("dispositivo " + config.Address + " no disponible")
+			return status, nil
+		}
+		conn = file
+	}
+	defer conn.Close()
+
+	if err := queryESCPOSStatus(conn, status); err != nil {
+		status.Status = PrinterStatusUnknown
+		status.Message = "Conectada, sin respuesta de estado"
+	}
+	return status, nil
+}
+
+// queryESCPOSStatus sends DLE EOT 1-4 and decodes the replies. Every reply byte has
+// bits 1 and 4 set and bits 0 and 7 clear
+func queryESCPOSStatus(conn io.ReadWriter, status *PrinterStatus) error {
+	ask := func(n byte) (byte, error) {
+		if _, err := conn.Write([]byte{DLE, EOT, n}); err != nil {
+			return 0, err
+		}
+		reply, err := readStatusByte(conn, printerStatusReadTimeout)
+		if err != nil {
+			return 0, err
+		}
+		if reply&0x93 != 0x12 {
+			return 0, fmt.Errorf("invalid status reply 0x%02X", reply)
+		}
+		return reply, nil
+	}
+
+	// n=1 printer status: bit 3 offline
+	printer, err := ask(1)
+	if err != nil {
+		return err
+	}
+	status.Offline = printer&0x08 != 0
+
+	// n=2 offline cause: bit 2 cover open, bit 5 stopped by paper end, bit 6 error
+	offline, err := ask(2)
+	if err != nil {
+		return err
+	}
+	status.CoverOpen = offline&0x04 != 0
+	status.PaperOut = offline&0x20 != 0
+	status.Error = offline&0x40 != 0
+
+	// n=3 error cause: bit 3 autocutter, bit 5 unrecoverable, bit 6 auto-recoverable
+	detail := ""
+	if status.Error {
+		if cause, err := ask(3); err == nil {
+			var causes []string
+			if cause&0x08 != 0 {
+				causes = append(causes, "cortador")
+			}
+			if cause&0x20 != 0 {
+				causes = append(causes, "error irrecuperable")
+			}
+			if cause&0x40 != 0 {
+				causes = append(causes, "error recuperable")
+			}
+			detail = strings.Join(causes, ", ")
+		}
+	}
+
+	// n=4 roll paper sensor: bits 2-3 near end, bits 5-6 paper end
+	paper, err := ask(4)
+	if err != nil {
+		return err
+	}
+	status.PaperNearEnd = paper&0x0C != 0
+	if paper&0x60 != 0 {
+		status.PaperOut = true
+	}
+
+	status.resolve(detail)
+	return nil
+}
+
+// readStatusByte reads one byte with a timeout. Device files do not always support
+// deadlines, so the read runs apart; an abandoned read ends when the device is closed
+func readStatusByte(conn io.Reader, timeout time.Duration) (byte, error) {
+	if deadline, ok := conn.(interface{ SetReadDeadline(time.Time) error }); ok {
+		deadline.SetReadDeadline(time.Now().Add(timeout))
+	}
+
+	type result struct {
+		b   byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		buf := make([]byte, 1)
+		_, err := io.ReadFull(conn, buf)
+		done <- result{buf[0], err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.b, r.err
+	case <-time.After(timeout + 500*time.Millisecond):
+		return 0, fmt.Errorf("status reply timeout")
+	}
+}
+
+// cupsPrinterStatus maps the IPP state of a CUPS queue to a printer status
+func cupsPrinterStatus(status *PrinterStatus, state *cupsPrinterState) {
+	for _, reason := range state.Reasons {
+		base := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(reason, "-report"), "-warning"), "-error")
+		switch base {
+		case "cover-open", "door-open":
+			status.CoverOpen = true
+		case "media-low":
+			status.PaperNearEnd = true
+		}
+	}
+	switch state.Health() {
+	case "paper_out":
+		status.PaperOut = true
+	case "offline":
+		status.Offline = true
+	case "error":
+		status.Error = !status.CoverOpen
+	}
+
+	detail := ""
+	if status.Error {
+		detail = state.Message
+		if detail == "" {
+			detail = strings.Join(state.Reasons, ", ")
+		}
+	} else if status.Offline && !status.CoverOpen {
+		detail = state.StatusMessage()
+	}
+	status.resolve(detail)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+)
+
+// fakeStatusPrinter answers each DLE EOT n with replies[n]; a missing reply ends the stream
+type fakeStatusPrinter struct {
+	replies map[byte]byte
+	out     bytes.Buffer
+}
+
+func (p *fakeStatusPrinter) Write(data []byte) (int, error) {
+	if len(data) == 3 && data[0] == DLE && data[1] == EOT {
+		if reply, ok := p.replies[data[2]]; ok {
+			p.out.WriteByte(reply)
+		}
+	}
+	return len(data), nil
+}
+
+func (p *fakeStatusPrinter) Read(data []byte) (int, error) {
+	return p.out.Read(data)
+}
+
+func TestQueryESCPOSStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		replies     map[byte]byte
+		wantStatus  string
+		wantMessage string
+		wantErr     bool
+	}{
+		{"ready", map[byte]byte{1: 0x12, 2: 0x12, 4: 0x12}, PrinterStatusOnline, "Lista", false},
+		{"paper near end", map[byte]byte{1: 0x12, 2: 0x12, 4: 0x1E}, PrinterStatusPaperNearEnd, "Papel por agotarse", false},
+		{"paper end sensor", map[byte]byte{1: 0x12, 2: 0x12, 4: 0x72}, PrinterStatusPaperOut, "Sin papel", false},
+		{"stopped by paper end", map[byte]byte{1: 0x1A, 2: 0x32, 4: 0x12}, PrinterStatusPaperOut, "Sin papel", false},
+		{"cover open", map[byte]byte{1: 0x1A, 2: 0x36, 4: 0x72}, PrinterStatusCoverOpen, "Tapa abierta", false},
+		{"offline", map[byte]byte{1: 0x1A, 2: 0x12, 4: 0x12}, PrinterStatusOffline, "Impresora fuera de línea", false},
+		{"cutter error", map[byte]byte{1: 0x1A, 2: 0x52, 3: 0x1A, 4: 0x12}, PrinterStatusError, "Error en la impresora: cortador", false},
+		{"several error causes", map[byte]byte{1: 0x1A, 2: 0x52, 3: 0x72, 4: 0x12}, PrinterStatusError, "Error en la impresora: error irrecuperable, error recuperable", false},
+		{"error cause unanswered", map[byte]byte{1: 0x1A, 2: 0x52, 4: 0x12}, PrinterStatusError, "Error en la impresora", false},
+		{"invalid reply", map[byte]byte{1: 0x00}, "", "", true},
+		{"no reply", map[byte]byte{}, "", "", true},
+		{"paper sensor unanswered", map[byte]byte{1: 0x12, 2: 0x12}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &PrinterStatus{}
+			err := queryESCPOSStatus(&fakeStatusPrinter{replies: tt.replies}, status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("queryESCPOSStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if status.Status != tt.wantStatus || status.Message != tt.wantMessage {
+				t.Errorf("queryESCPOSStatus() = %q (%q), want %q (%q)", status.Status, status.Message, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestCUPSPrinterStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       cupsPrinterState
+		wantStatus  string
+		wantMessage string
+		wantBlocks  bool
+	}{
+		{"ready", cupsPrinterState{State: 3, AcceptingJobs: true}, PrinterStatusOnline, "Lista", false},
+		{"paper low", cupsPrinterState{State: 3, AcceptingJobs: true, Reasons: []string{"media-low-warning"}}, PrinterStatusPaperNearEnd, "Papel por agotarse", false},
+		{"paper out", cupsPrinterState{State: 3, AcceptingJobs: true, Reasons: []string{"media-empty-error"}}, PrinterStatusPaperOut, "Sin papel", true},
+		{"cover open", cupsPrinterState{State: 3, AcceptingJobs: true, Reasons: []string{"cover-open-error"}}, PrinterStatusCoverOpen, "Tapa abierta", true},
+		{"jam", cupsPrinterState{State: 3, AcceptingJobs: true, Reasons: []string{"media-jam-error"}}, PrinterStatusError, "Error en la impresora: media-jam-error", true},
+		{"stopped queue", cupsPrinterState{State: ippPrinterStopped, AcceptingJobs: true}, PrinterStatusOffline, "Impresora fuera de línea: Cola de impresión detenida", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &PrinterStatus{}
+			cupsPrinterStatus(status, &tt.state)
+			if status.Status != tt.wantStatus || status.Message != tt.wantMessage {
+				t.Errorf("cupsPrinterStatus() = %q (%q), want %q (%q)", status.Status, status.Message, tt.wantStatus, tt.wantMessage)
+			}
+			if status.Blocks() != tt.wantBlocks {
+				t.Errorf("Blocks() = %v, want %v", status.Blocks(), tt.wantBlocks)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"PosApp/app/database"
+	"PosApp/app/models"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurchaseService handles suppliers and inventory purchases
+type PurchaseService struct {
+	*BaseService
+	invoiceSvc *InvoiceService
+}
+
+// NewPurchaseService creates a new purchase service
+func NewPurchaseService() *PurchaseService {
+	return &PurchaseService{
+		BaseService: &BaseService{db: database.GetDB()},
+		invoiceSvc:  NewInvoiceService(),
+	}
+}
+
+// Supplier management
+
+// GetSuppliers gets all suppliers
+func (s *PurchaseService) GetSuppliers() ([]models.Supplier, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var suppliers []models.Supplier
+	err := s.db.Order("name ASC").Find(&suppliers).Error
+	return suppliers, err
+}
+
+// CreateSupplier creates a new supplier
+func (s *PurchaseService) CreateSupplier(supplier *models.Supplier) (*models.Supplier, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	if err := validateSupplier(supplier); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(supplier).Error; err != nil {
+		return nil, fmt.Errorf("error al crear proveedor: %w", err)
+	}
+	return supplier, nil
+}
+
+// UpdateSupplier updates an existing supplier
+func (s *PurchaseService) UpdateSupplier(supplier *models.Supplier) (*models.Supplier, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	if supplier.ID == 0 {
+		return nil, errors.New("proveedor ID es requerido")
+	}
+	if err := validateSupplier(supplier); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Save(supplier).Error; err != nil {
+		return nil, fmt.Errorf("error al actualizar proveedor: %w", err)
+	}
+	return supplier, nil
+}
+
+// DeleteSupplier soft deletes a supplier
+func (s *PurchaseService) DeleteSupplier(id uint) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+	return s.db.Delete(&models.Supplier{}, id).Error
+}
+
+func validateSupplier(supplier *models.Supplier) error {
+	if supplier.Name == "" {
+		return errors.New("el nombre del proveedor es requerido")
+	}
+	if supplier.IdentificationNumber == "" {
+		return errors.New("el número de identificación del proveedor es requerido")
+	}
+	if supplier.IdentificationType == "" {
+		supplier.IdentificationType = "CC"
+	}
+	return nil
+}
+
+// Purchases
+
+// GetPurchases gets purchases in a date range (YYYY-MM-DD); empty dates return the latest purchases
+func (s *PurchaseService) GetPurchases(startDate, endDate string) ([]models.InventoryPurchase, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var purchases []models.InventoryPurchase
+	query := s.db.Preload("Supplier").
+		Preload("Items").
+		Preload("Withholdings").
+		Preload("SupportDocument.AdjustmentNotes")
+
+	if startDate != "" && endDate != "" {
+		start, _ := time.Parse("2006-01-02", startDate)
+		end, _ := time.Parse("2006-01-02", endDate)
+		query = query.Where("purchased_at BETWEEN ? AND ?", start, end.Add(24*time.Hour))
+	} else {
+		query = query.Limit(200)
+	}
+
+	err := query.Order("purchased_at DESC").Find(&purchases).Error
+	return purchases, err
+}
+
+// GetPurchase gets a single purchase with its support document
+func (s *PurchaseService) GetPurchase(id uint) (*models.InventoryPurchase, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var purchase models.InventoryPurchase
+	err := s.db.Preload("Supplier").
+		Preload("Items").
+		Preload("Withholdings").
+		Preload("SupportDocument.AdjustmentNotes").
+		First(&purchase, id).Error
+	return &purchase, err
+}
+
+// CreatePurchase registers a purchase, adds the goods to stock and, when the supplier
+// does not issue invoices, sends the electronic support document to DIAN
+// A failed support document does not undo the purchase; it can be retried with IssueSupportDocument
+func (s *PurchaseService) CreatePurchase(purchase *models.InventoryPurchase, employeeID uint) (*models.InventoryPurchase, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	if purchase.SupplierID == 0 {
+		return nil, errors.New("el proveedor es requerido")
+	}
+	if len(purchase.Items) == 0 {
+		return nil, errors.New("la compra debe tener al menos un ítem")
+	}
+
+	var supplier models.Supplier
+	if err := s.db.First(&supplier, purchase.SupplierID).Error; err != nil {
+		return nil, errors.New("proveedor no encontrado")
+	}
+
+	if err := calculatePurchaseTotals(purchase); err != nil {
+		return nil, err
+	}
+
+	purchase.ID = 0
+	purchase.Supplier = nil
+	purchase.SupportDocument = nil
+	purchase.NeedsSupportDocument = !supplier.IssuesInvoices
+	if purchase.PurchasedAt.IsZero() {
+		purchase.PurchasedAt = time.Now()
+	}
+	if employeeID != 0 {
+		purchase.EmployeeID = &employeeID
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// The purchase number is taken from the row ID, reserved before the insert
+		var id uint
+		if err := tx.Raw("SELECT nextval(pg_get_serial_sequence('inventory_purchases', 'id'))").Scan(&id).Error; err != nil {
+			return fmt.Errorf("error al numerar la compra: %w", err)
+		}
+		purchase.ID = id
+		purchase.PurchaseNumber = fmt.Sprintf("COMP-%06d", id)
+
+		if err := tx.Create(purchase).Error; err != nil {
+			return fmt.Errorf("error al crear compra: %w", err)
+		}
+
+		for _, item := range purchase.Items {
+			if err := s.addPurchasedStock(tx, purchase, &item, employeeID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if purchase.NeedsSupportDocument {
+		if _, err := s.invoiceSvc.SendSupportDocument(purchase); err != nil {
+			log.Printf("Support document for purchase %s failed: %v", purchase.PurchaseNumber, err)
+		}
+	}
+
+	return s.GetPurchase(purchase.ID)
+}
+
+// IssueSupportDocument sends (or retries) the support document of a purchase
+func (s *PurchaseService) IssueSupportDocument(purchaseID uint) (*models.SupportDocument, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	purchase, err := s.GetPurchase(purchaseID)
+	if err != nil {
+		return nil, errors.New("compra no encontrada")
+	}
+	if purchase.SupportDocument != nil && purchase.SupportDocument.Status != "error" && purchase.SupportDocument.Status != "rejected" {
+		return nil, errors.New("la compra ya tiene un documento soporte")
+	}
+
+	return s.invoiceSvc.SendSupportDocument(purchase)
+}
+
+// CreateSupportDocumentAdjustment sends an adjustment note for the support document of a purchase
+func (s *PurchaseService) CreateSupportDocumentAdjustment(purchaseID uint, reason string, discrepancyCode int) (*models.SupportDocumentAdjustmentNote, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	if reason == "" {
+		return nil, errors.New("el motivo de la nota de ajuste es requerido")
+	}
+	if discrepancyCode == 0 {
+		discrepancyCode = 2 // Anulación del documento soporte
+	}
+
+	var document models.SupportDocument
+	if err := s.db.Where("purchase_id = ?", purchaseID).First(&document).Error; err != nil {
+		return nil, errors.New("la compra no tiene documento soporte")
+	}
+	if document.CUDS == "" {
+		return nil, errors.New("el documento soporte no fue aceptado por la DIAN")
+	}
+
+	return s.invoiceSvc.SendSupportDocumentAdjustmentNote(&document, reason, discrepancyCode)
+}
+
+// addPurchasedStock adds a purchased item to the stock of its ingredient or product
+func (s *PurchaseService) addPurchasedStock(tx *gorm.DB, purchase *models.InventoryPurchase, item *models.InventoryPurchaseItem, employeeID uint) error {
+	if item.IngredientID != nil {
+		var ingredient models.Ingredient
+		if err := tx.First(&ingredient, *item.IngredientID).Error; err != nil {
+			return fmt.Errorf("ingrediente no encontrado: %w", err)
+		}
+
+		previousStock := ingredient.Stock
+		ingredient.Stock += item.Quantity
+		if err := tx.Save(&ingredient).Error; err != nil {
+			return err
+		}
+
+		movement := models.IngredientMovement{
+			IngredientID: ingredient.ID,
+			Type:         "purchase",
+			Quantity:     item.Quantity,
+			PreviousQty:  previousStock,
+			NewQty:       ingredient.Stock,
+			Reference:    purchase.PurchaseNumber,
+			Notes:        item.Description,
+		}
+		if employeeID != 0 {
+			movement.EmployeeID = &employeeID
+		}
+		return tx.Create(&movement).Error
+	}
+
+	if item.ProductID != nil {
+		var product models.Product
+		if err := tx.First(&product, *item.ProductID).Error; err != nil {
+			return fmt.Errorf("producto no encontrado: %w", err)
+		}
+
+		quantity := int(item.Quantity)
+		previousStock := product.Stock
+		product.Stock += quantity
+		if err := tx.Save(&product).Error; err != nil {
+			return err
+		}
+
+		movement := models.InventoryMovement{
+			ProductID:   product.ID,
+			Type:        "purchase",
+			Quantity:    quantity,
+			PreviousQty: previousStock,
+			NewQty:      product.Stock,
+			Reference:   purchase.PurchaseNumber,
+			Notes:       item.Description,
+		}
+		if employeeID != 0 {
+			movement.EmployeeID = &employeeID
+		}
+		return tx.Create(&movement).Error
+	}
+
+	return nil
+}
+
+// calculatePurchaseTotals calculates item, tax and withholding totals of a purchase
+func calculatePurchaseTotals(purchase *models.InventoryPurchase) error {
+	purchase.Subtotal = 0
+	purchase.Tax = 0
+	purchase.WithholdingTotal = 0
+
+	for i := range purchase.Items {
+		item := &purchase.Items[i]
+		if item.Quantity <= 0 {
+			return errors.New("la cantidad debe ser mayor a 0")
+		}
+		if item.UnitPrice < 0 {
+			return errors.New("el precio unitario no puede ser negativo")
+		}
+		if item.Description == "" {
+			return errors.New("la descripción del ítem es requerida")
+		}
+		if item.ProductID != nil && item.Quantity != math.Trunc(item.Quantity) {
+			return fmt.Errorf("la cantidad de %s debe ser un número entero", item.Description)
+		}
+
+		item.ID = 0
+		item.Subtotal = math.Round(item.Quantity*item.UnitPrice*100) / 100
+		item.TaxAmount = math.Round(item.Subtotal*item.TaxPercent) / 100
+		purchase.Subtotal += item.Subtotal
+		purchase.Tax += item.TaxAmount
+	}
+
+	for i := range purchase.Withholdings {
+		withholding := &purchase.Withholdings[i]
+		withholding.ID = 0
+		if withholding.TaxableAmount == 0 {
+			// ReteIVA is withheld over the IVA, the other withholdings over the subtotal
+			if withholding.TaxID == models.WithholdingReteIVA {
+				withholding.TaxableAmount = purchase.Tax
+			} else {
+				withholding.TaxableAmount = purchase.Subtotal
+			}
+		}
+		withholding.Amount = math.Round(withholding.TaxableAmount*withholding.Percent) / 100
+		purchase.WithholdingTotal += withholding.Amount
+	}
+
+	purchase.Total = purchase.Subtotal + purchase.Tax - purchase.WithholdingTotal
+	return nil
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"testing"
+
+	"PosApp/app/models"
+)
+
+func TestCalculatePurchaseTotalsQuantities(t *testing.T) {
+	productID, ingredientID := uint(1), uint(2)
+	tests := []struct {
+		name    string
+		item    models.InventoryPurchaseItem
+		wantErr bool
+	}{
+		{"whole product units", models.InventoryPurchaseItem{ProductID: &productID, Description: "Gaseosa", Quantity: 12, UnitPrice: 1500}, false},
+		{"fractional product units", models.InventoryPurchaseItem{ProductID: &productID, Description: "Gaseosa", Quantity: 2.5, UnitPrice: 1500}, true},
+		{"fractional ingredient", models.InventoryPurchaseItem{IngredientID: &ingredientID, Description: "Harina", Quantity: 2.5, UnitPrice: 3000}, false},
+		{"zero quantity", models.InventoryPurchaseItem{IngredientID: &ingredientID, Description: "Harina", Quantity: 0, UnitPrice: 3000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			purchase := &models.InventoryPurchase{Items: []models.InventoryPurchaseItem{tt.item}}
+			if err := calculatePurchaseTotals(purchase); (err != nil) != tt.wantErr {
+				t.Errorf("calculatePurchaseTotals() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
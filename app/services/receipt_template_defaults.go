@@ -0,0 +1,386 @@
+package services
+
+import "PosApp/app/models"
+
+// defaultReceiptTemplates are the starting point offered when creating a template.
+// They reproduce the built-in layouts, adding the restaurant header and footer texts.
+var defaultReceiptTemplates = map[string]string{
+	models.PrintJobInvoice: `@center
+{{if .Business.HasLogo}}
+@feed
+@logo
+@feed
+{{end}}
+@bold
+@size 2x2
+{{range .Invoice.Title}}{{.}}
+{{end}}
+@normal
+@feed
+@bold
+{{.Business.LegalName}}
+{{if and .Business.Name (ne .Business.Name .Business.LegalName)}}{{.Business.Name}}{{end}}
+@bold off
+NIT: {{.Business.NIT}}
+{{.Business.Regime}}
+{{if .Business.Liability}}Obligacion: {{.Business.Liability}}{{end}}
+{{with .Invoice.Resolution}}{{if .Number}}
+{{.Label}} No. {{.Number}}
+{{if not .DateFrom.IsZero}}de {{date .DateFrom}}, {{end}}Prefijo: {{.Prefix}}, Rango {{.From}} Al {{.To}}
+{{if and (not .DateFrom.IsZero) (not .DateTo.IsZero)}}Vigencia Desde: {{date .DateFrom}} Hasta: {{date .DateTo}}{{end}}
+{{end}}{{end}}
+{{.Business.Address}}
+{{.Business.Location}}
+{{if .Business.Phone}}Telefono: {{.Business.Phone}}{{end}}
+{{if .Business.Email}}E-mail: {{.Business.Email}}{{end}}
+{{if .Business.Header}}
+@feed
+{{wrap .Business.Header}}
+{{end}}
+@feed
+@left
+@line
+@bold
+{{.Invoice.Label}}: {{.Invoice.Number}}
+@bold off
+Fecha: {{datetime .Date}}
+@line
+@bold
+DATOS DEL CLIENTE
+@bold off
+{{with .Customer}}
+Nombre: {{.Name}}
+NIT/CC: {{.Identification}}
+{{if .Address}}Dirección: {{.Address}}{{end}}
+{{if .Phone}}Teléfono: {{.Phone}}{{end}}
+{{else}}
+CONSUMIDOR FINAL
+{{end}}
+{{with .Delivery}}
+@line
+@bold
+DATOS DE ENTREGA
+@bold off
+{{if .Name}}Nombre: {{.Name}}{{end}}
+{{if .Address}}Dirección: {{.Address}}{{end}}
+{{if .Phone}}Teléfono: {{.Phone}}{{end}}
+{{end}}
+@line
+@bold
+DETALLE DE PRODUCTOS/SERVICIOS
+@bold off
+@line
+{{range .Items}}
+{{.Description}}
+  {{.Quantity}} x ${{money .UnitPrice}} = ${{money .Subtotal}}
+{{range .Modifiers}}{{if .Price}}    + {{.Name}}: ${{money .Price}}
+{{end}}{{end}}
+{{if .Notes}}  Nota: {{.Notes}}{{end}}
+{{end}}
+@line
+@right
+Subtotal: ${{money .Totals.Subtotal}}
+{{if gt .Totals.Discount 0.0}}Descuento: -${{money .Totals.Discount}}{{end}}
+{{if gt .Totals.Tax 0.0}}IVA: ${{money .Totals.Tax}}{{end}}
+@bold
+@size 1x2
+TOTAL: ${{money .Totals.Total}}
+@normal
+{{if gt .Totals.Tip 0.0}}
+Propina voluntaria: ${{money .Totals.Tip}}
+Total pagado: ${{money .Totals.TotalPaid}}
+{{end}}
+@left
+@line
+Forma de pago: Contado
+{{if .Invoice.PaymentMeans}}Medio de pago: {{.Invoice.PaymentMeans}}{{end}}
+@feed
+@center
+{{range .Invoice.Legend}}{{.}}
+{{end}}
+@feed
+{{if .Invoice.PendingDIAN}}
+Pendiente de reporte a la DIAN.
+Expedida: {{datetime .Invoice.IssuedAt}}
+{{else}}
+Validar en:
+https://catalogo-vpfe.dian.gov.co
+@feed
+@qr {{.Invoice.QRURL}}
+@feed 2
+@bold
+{{.Invoice.KeyLabel}}:
+@bold off
+@left
+{{wrap .Invoice.CUFE}}
+@feed
+{{end}}
+@feed
+@left
+@line
+Atendió: {{.Employee}}
+Caja: {{.CashRegisterID}}
+{{if and .Invoice.IsPOS .Invoice.PlateNumber}}Placa caja: {{.Invoice.PlateNumber}}{{end}}
+@feed
+@center
+{{if .Business.Footer}}{{wrap .Business.Footer}}{{else}}¡Gracias por su compra!{{end}}
+{{.Business.Website}}
+@cut
+@drawer
+`,
+
+	models.PrintJobReceipt: `@center
+{{if .Business.HasLogo}}
+@feed
+@logo
+@feed
+{{end}}
+@bold
+@size 2x2
+{{.Business.Name}}
+@normal
+{{.Business.Address}}
+Tel: {{.Business.Phone}}
+{{if .Business.Header}}{{wrap .Business.Header}}{{end}}
+@feed
+@left
+@line
+Recibo: {{.Number}}
+Fecha: {{datetime .Date}}
+{{with .Customer}}Cliente: {{.Name}}{{end}}
+{{with .Delivery}}
+@line
+@bold
+DATOS DE ENTREGA
+@bold off
+{{if .Name}}Nombre: {{.Name}}{{end}}
+{{if .Address}}Dirección: {{.Address}}{{end}}
+{{if .Phone}}Teléfono: {{.Phone}}{{end}}
+{{end}}
+@line
+{{range .Items}}
+{{.Quantity}} x {{.Description}}
+  ${{money .UnitPrice}} c/u = ${{money .Subtotal}}
+{{range .Modifiers}}{{if .Price}}    + {{.Name}}: ${{money .Price}}
+{{end}}{{end}}
+{{end}}
+@line
+Subtotal: ${{money .Totals.Subtotal}}
+{{if gt .Totals.Discount 0.0}}Descuento: -${{money .Totals.Discount}}{{end}}
+{{if gt .Totals.Tax 0.0}}IVA: ${{money .Totals.Tax}}{{end}}
+@bold
+TOTAL: ${{money .Totals.Total}}
+@bold off
+{{if gt .Totals.Tip 0.0}}
+Propina voluntaria: ${{money .Totals.Tip}}
+Total pagado: ${{money .Totals.TotalPaid}}
+{{end}}
+@line
+{{range .Payments}}{{.Method}}: ${{money .Amount}}
+{{end}}
+@feed
+@center
+{{if .Business.Footer}}{{wrap .Business.Footer}}{{else}}¡Gracias por su compra!{{end}}
+@cut
+@drawer
+`,
+
+	models.PrintJobKitchen: `@center
+@bold
+@size 2x2
+ORDEN DE COCINA
+@normal
+@feed
+@left
+@line
+@bold
+Orden #: {{.Number}}
+@bold off
+Fecha: {{time .Date}}
+{{if .Table}}
+@bold
+@size 1x2
+Mesa: {{.Table}}
+@normal
+{{end}}
+Tipo: {{.OrderType}}
+{{with .Delivery}}
+@line
+@bold
+DATOS DE ENTREGA
+@bold off
+{{if .Name}}Cliente: {{.Name}}{{end}}
+{{if .Address}}Dirección: {{.Address}}{{end}}
+{{if .Phone}}Teléfono: {{.Phone}}{{end}}
+{{end}}
+@line
+{{range .Items}}
+@bold
+{{.Quantity}} x {{.Name}}
+@bold off
+{{range .Modifiers}}  - {{.Name}}
+{{end}}
+{{if .Notes}}  NOTA: {{.Notes}}{{end}}
+@feed
+{{end}}
+{{if .Notes}}
+@line
+@bold
+NOTAS:
+@bold off
+{{wrap .Notes}}
+{{end}}
+@line
+@center
+Hora: {{time .PrintedAt}}
+@cut
+`,
+
+	models.PrintJobOrder: `@center
+{{if .Business.HasLogo}}
+@feed
+@logo
+@feed
+{{end}}
+@bold
+@size 2x2
+{{.Business.Name}}
+@normal
+{{.Business.Address}}
+Tel: {{.Business.Phone}}
+{{if .Business.Header}}{{wrap .Business.Header}}{{end}}
+@feed
+@left
+@line
+@bold
+Orden #: {{.Number}}
+@bold off
+Fecha: {{datetime .Date}}
+{{if .Table}}Mesa: {{.Table}}{{end}}
+{{if .SequenceNumber}}Para Llevar #: {{.SequenceNumber}}{{end}}
+{{with .Customer}}Cliente: {{.Name}}{{end}}
+{{with .Delivery}}
+@line
+@bold
+DATOS DE ENTREGA
+@bold off
+{{if .Name}}Cliente: {{.Name}}{{end}}
+{{if .Address}}Dirección: {{.Address}}{{end}}
+{{if .Phone}}Teléfono: {{.Phone}}{{end}}
+{{end}}
+@line
+{{range .Items}}
+{{.Quantity}} x {{.Name}}
+{{range .Modifiers}}  + {{.Name}}{{if .Price}} (${{money .Price}}){{end}}
+{{end}}
+  ${{money .UnitPriceWithModifiers}} c/u = ${{money .Subtotal}}
+{{if .Notes}}  Nota: {{.Notes}}{{end}}
+{{end}}
+@line
+@right
+Subtotal: ${{money .Totals.Subtotal}}
+{{if gt .Totals.Discount 0.0}}Descuento: -${{money .Totals.Discount}}{{end}}
+{{if gt .Totals.Tax 0.0}}IVA: ${{money .Totals.Tax}}{{end}}
+@bold
+@size 1x2
+TOTAL: ${{money .Totals.Total}}
+@normal
+@left
+{{if .Notes}}
+@line
+Notas:
+{{wrap .Notes}}
+{{end}}
+{{if .Employee}}
+@feed
+@line
+Atendió: {{.Employee}}
+{{end}}
+@feed
+@center
+{{if .Business.Footer}}{{wrap .Business.Footer}}{{else}}¡Gracias por su preferencia!{{end}}
+{{.Business.Website}}
+@cut
+`,
+
+	models.PrintJobCashRegisterReport: `@center
+{{if .Business.HasLogo}}
+@feed
+@logo
+@feed
+{{end}}
+@bold
+@size 2x2
+CIERRE DE CAJA
+@normal
+Fecha: {{date .Date}}
+@feed
+@left
+{{if .Employee}}Cajero: {{.Employee}}{{end}}
+@line
+{{with .Report}}
+@bold
+RESUMEN DE VENTAS
+@bold off
+Total Ventas: {{.NumberOfSales}}
+Total Facturado: ${{money .TotalSales}}
+@feed
+@bold
+FORMAS DE PAGO
+@bold off
+Efectivo: ${{money .TotalCash}}
+Tarjetas: ${{money .TotalCard}}
+Digital: ${{money .TotalDigital}}
+Otros: ${{money .TotalOther}}
+{{if gt .TotalTips 0.0}}Propinas: ${{money .TotalTips}}{{end}}
+@feed
+@bold
+MOVIMIENTOS DE EFECTIVO
+@bold off
+Base Inicial: ${{money .OpeningBalance}}
+Ventas en Efectivo: ${{money .TotalCash}}
+{{if gt .CashTips 0.0}}Propinas en Efectivo: +${{money .CashTips}}{{end}}
+Depósitos: +${{money .CashDeposits}}
+Retiros: -${{money .CashWithdrawals}}
+@line
+@bold
+Efectivo Esperado: ${{money .ExpectedBalance}}
+Efectivo Contado: ${{money .ClosingBalance}}
+Diferencia: ${{money .Difference}}
+{{end}}
+({{.DifferenceLabel}})
+@bold off
+{{with .Report.BoldReconciliation}}
+@line
+@bold
+CONCILIACION BOLD
+@bold off
+Transacciones Bold: {{.BoldCount}} (${{money .BoldTotal}})
+Pagos Bold en POS: {{.POSCount}} (${{money .POSTotal}})
+Conciliados: {{.MatchedCount}}
+{{if eq .Status "discrepancies"}}
+{{if .AmountMismatchCount}}Diferencia de monto: {{.AmountMismatchCount}}{{end}}
+{{if .BoldOrphanCount}}Bold sin venta: {{.BoldOrphanCount}} (${{money .BoldOrphanTotal}}){{end}}
+{{if .POSOrphanCount}}Venta sin aprobar: {{.POSOrphanCount}} (${{money .POSOrphanTotal}}){{end}}
+{{else}}
+(SIN NOVEDADES)
+{{end}}
+{{end}}
+{{if .Notes}}
+@feed
+Observaciones:
+{{wrap .Notes}}
+{{end}}
+@feed
+@line
+@center
+Impreso: {{datetime .PrintedAt}}
+@feed 2
+_______________________
+Firma Cajero
+@feed 2
+_______________________
+Firma Supervisor
+@cut
+`,
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"PosApp/app/models"
+
+	"gorm.io/gorm"
+)
+
+// ReceiptTemplateService manages the receipt and ticket templates
+type ReceiptTemplateService struct {
+	*BaseService
+}
+
+// NewReceiptTemplateService creates a new receipt template service
+func NewReceiptTemplateService() *ReceiptTemplateService {
+	return &ReceiptTemplateService{
+		BaseService: NewBaseService(),
+	}
+}
+
+// ReceiptTemplateDocumentType is a document that can be printed from a template
+type ReceiptTemplateDocumentType struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// GetReceiptTemplateDocumentTypes returns the documents that can use templates
+func (s *ReceiptTemplateService) GetReceiptTemplateDocumentTypes() []ReceiptTemplateDocumentType {
+	order := []string{
+		models.PrintJobInvoice,
+		models.PrintJobReceipt,
+		models.PrintJobKitchen,
+		models.PrintJobOrder,
+		models.PrintJobCashRegisterReport,
+	}
+	types := make([]ReceiptTemplateDocumentType, 0, len(order))
+	for _, value := range order {
+		types = append(types, ReceiptTemplateDocumentType{Value: value, Label: receiptTemplateDocumentTypes[value]})
+	}
+	return types
+}
+
+// GetReceiptTemplates returns all templates
+func (s *ReceiptTemplateService) GetReceiptTemplates() ([]models.ReceiptTemplate, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	var templates []models.ReceiptTemplate
+	if err := s.db.Order("document_type, name").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// GetDefaultReceiptTemplate returns the template of the built-in layout of a document type
+func (s *ReceiptTemplateService) GetDefaultReceiptTemplate(documentType string) (string, error) {
+	content, ok := defaultReceiptTemplates[documentType]
+	if !ok {
+		return "", fmt.Errorf("tipo de documento inválido: %s", documentType)
+	}
+	return content, nil
+}
+
+// SaveReceiptTemplate validates and saves a template. An active template replaces
+// the active one of the same document type and printer
+func (s *ReceiptTemplateService) SaveReceiptTemplate(tmpl *models.ReceiptTemplate) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+	tmpl.Name = strings.TrimSpace(tmpl.Name)
+	if tmpl.Name == "" {
+		return fmt.Errorf("el nombre de la plantilla es requerido")
+	}
+	if _, ok := receiptTemplateDocumentTypes[tmpl.DocumentType]; !ok {
+		return fmt.Errorf("tipo de documento inválido: %s", tmpl.DocumentType)
+	}
+	if strings.TrimSpace(tmpl.Content) == "" {
+		return fmt.Errorf("el contenido de la plantilla es requerido")
+	}
+	if tmpl.PrinterID != nil && *tmpl.PrinterID == 0 {
+		tmpl.PrinterID = nil
+	}
+	if tmpl.PrinterID != nil {
+		var count int64
+		s.db.Model(&models.PrinterConfig{}).Where("id = ?", *tmpl.PrinterID).Count(&count)
+		if count == 0 {
+			return fmt.Errorf("impresora no encontrada")
+		}
+	}
+
+	// The template must render for both paper widths
+	printer := NewPrinterService()
+	for _, paperWidth := range []int{58, 80} {
+		doc := sampleReceiptDocument(printer, tmpl.DocumentType, paperWidth)
+		if _, err := renderReceiptTemplate(tmpl.Content, doc); err != nil {
+			return fmt.Errorf("papel %dmm: %w", paperWidth, err)
+		}
+	}
+
+	return s.WithTransaction(func(tx *gorm.DB) error {
+		if tmpl.IsActive {
+			query := tx.Model(&models.ReceiptTemplate{}).Where("document_type = ? AND id != ?", tmpl.DocumentType, tmpl.ID)
+			if tmpl.PrinterID != nil {
+				query = query.Where("printer_id = ?", *tmpl.PrinterID)
+			} else {
+				query = query.Where("printer_id IS NULL")
+			}
+			if err := query.Update("is_active", false).Error; err != nil {
+				return err
+			}
+		}
+
+		if tmpl.ID == 0 {
+			if err := tx.Create(tmpl).Error; err != nil {
+				return err
+			}
+			// is_active has a database default, so an inactive template is updated after insert
+			if !tmpl.IsActive {
+				return tx.Model(tmpl).Update("is_active", false).Error
+			}
+			return nil
+		}
+		return tx.Save(tmpl).Error
+	})
+}
+
+// DeleteReceiptTemplate deletes a template; its documents go back to the built-in layout
+func (s *ReceiptTemplateService) DeleteReceiptTemplate(id uint) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+	return s.db.Delete(&models.ReceiptTemplate{}, id).Error
+}
+
+// PreviewReceiptTemplate renders a template with sample data as plain text
+func (s *ReceiptTemplateService) PreviewReceiptTemplate(content string, documentType string, paperWidth int) (string, error) {
+	if _, ok := receiptTemplateDocumentTypes[documentType]; !ok {
+		return "", fmt.Errorf("tipo de documento inválido: %s", documentType)
+	}
+	doc := sampleReceiptDocument(NewPrinterService(), documentType, paperWidth)
+	ops, err := renderReceiptTemplate(content, doc)
+	if err != nil {
+		return "", err
+	}
+	return previewReceiptOps(ops, doc), nil
+}
+
+// PrintReceiptTemplateSample prints a template with sample data on a printer
+func (s *ReceiptTemplateService) PrintReceiptTemplateSample(content string, documentType string, printerID uint) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+	if _, ok := receiptTemplateDocumentTypes[documentType]; !ok {
+		return fmt.Errorf("tipo de documento inválido: %s", documentType)
+	}
+
+	var config models.PrinterConfig
+	if err := s.db.First(&config, printerID).Error; err != nil {
+		return fmt.Errorf("impresora no encontrada")
+	}
+
+	printer := NewPrinterService()
+	doc := sampleReceiptDocument(printer, documentType, config.PaperWidth)
+	doc.Printer = ReceiptPrinter{Name: config.Name, AutoCut: config.AutoCut, CashDrawer: false}
+	ops, err := renderReceiptTemplate(content, doc)
+	if err != nil {
+		return err
+	}
+
+	if err := printer.beginJob(&config, documentType, fmt.Sprintf("Prueba plantilla %s", receiptTemplateDocumentTypes[documentType]), "", 0); err != nil {
+		return fmt.Errorf("failed to connect to printer: %w", err)
+	}
+	defer printer.closePrinter()
+
+	printer.writeReceiptOps(ops, doc)
+	return printer.print()
+}
+
+// sampleReceiptDocument builds a document with sample data and the real business data,
+// used to validate and preview templates
+func sampleReceiptDocument(printer *PrinterService, documentType string, paperWidth int) *ReceiptDocument {
+	config := &models.PrinterConfig{Name: "Impresora", PaperWidth: paperWidth, AutoCut: true, CashDrawer: true}
+	var doc *ReceiptDocument
+	if printer.db != nil {
+		doc = printer.newReceiptDocument(documentType, config)
+	} else {
+		doc = &ReceiptDocument{
+			DocumentType: documentType,
+			PaperWidth:   paperWidth,
+			Width:        receiptLineWidth(paperWidth),
+			PrintedAt:    time.Now(),
+			Printer:      ReceiptPrinter{Name: config.Name, AutoCut: true, CashDrawer: true},
+		}
+	}
+	if doc.Business.Name == "" {
+		doc.Business.Name = "Mi Restaurante"
+	}
+	if doc.Business.LegalName == "" {
+		doc.Business.LegalName = doc.Business.Name
+	}
+
+	now := time.Now()
+	doc.Date = now
+	doc.Number = "VTA-0001"
+	doc.Employee = "Cajero"
+	doc.CashRegisterID = 1
+	doc.Items = []ReceiptItem{
+		{
+			Quantity: 2, Name: "Hamburguesa", Description: "Hamburguesa (Sin cebolla, Queso extra)",
+			UnitPrice: 18000, UnitPriceWithModifiers: 21000, Subtotal: 42000,
+			Modifiers: []ReceiptModifier{{Name: "Sin cebolla"}, {Name: "Queso extra", Price: 3000}},
+			Notes:     "Término medio", Status: "pending",
+		},
+		{
+			Quantity: 1, Name: "Limonada", Description: "Limonada",
+			UnitPrice: 6000, UnitPriceWithModifiers: 6000, Subtotal: 6000, Status: "pending",
+		},
+	}
+	doc.Totals = ReceiptTotals{Subtotal: 48000, Tax: 0, Total: 48000, Tip: 4800, TotalPaid: 52800}
+	doc.Payments = []ReceiptPayment{{Method: "Efectivo", Amount: 52800}}
+	doc.Customer = &ReceiptCustomer{Name: "Cliente de Prueba", Identification: "1234567890", Phone: "3000000000"}
+
+	switch documentType {
+	case models.PrintJobInvoice:
+		doc.Invoice = &ReceiptInvoice{
+			Title:  []string{"FACTURA ELECTRONICA", "DE VENTA"},
+			Label:  "Factura",
+			Number: "SETP990000001",
+			Resolution: ReceiptResolution{
+				Label: "Resolución de Facturación Electrónica", Number: "18760000001", Prefix: "SETP",
+				From: 990000000, To: 995000000, DateFrom: now.AddDate(0, -1, 0), DateTo: now.AddDate(1, 0, 0),
+			},
+			CUFE:         strings.Repeat("0a1b2c3d", 12),
+			KeyLabel:     "CUFE",
+			QRURL:        "https://catalogo-vpfe.dian.gov.co/document/searchqr?documentkey=0",
+			IssuedAt:     now,
+			PaymentMeans: "Efectivo",
+			Legend:       []string{"*** REPRESENTACIÓN IMPRESA DE LA ***", "*** FACTURA ELECTRÓNICA DE VENTA ***"},
+		}
+	case models.PrintJobKitchen, models.PrintJobOrder:
+		doc.Number = "ORD-0001"
+		doc.Table = "5"
+		doc.OrderType = "Mesa"
+		doc.SequenceNumber = 12
+		doc.Notes = "Cliente alérgico al maní"
+		doc.Totals.Tip = 0
+		doc.Totals.TotalPaid = doc.Totals.Total
+		doc.Payments = nil
+	case models.PrintJobCashRegisterReport:
+		doc.Customer = nil
+		doc.Items = nil
+		doc.Payments = nil
+		doc.Report = &models.CashRegisterReport{
+			CashRegisterID: 1, Date: now, NumberOfSales: 25, TotalSales: 1250000,
+			TotalCash: 650000, TotalCard: 400000, TotalDigital: 200000, TotalTips: 45000, CashTips: 15000,
+			OpeningBalance: 200000, CashDeposits: 0, CashWithdrawals: 50000,
+			ExpectedBalance: 815000, ClosingBalance: 815000,
+			BoldReconciliation: &models.BoldReconciliation{
+				Status: "discrepancies", BoldCount: 8, BoldTotal: 400000, POSCount: 8, POSTotal: 400000,
+				MatchedCount: 7, AmountMismatchCount: 1,
+			},
+		}
+		doc.DifferenceLabel = "CUADRE PERFECTO"
+	}
+	return doc
+}
@@ -0,0 +1,939 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
+
+	"PosApp/app/models"
+)
+
+// Receipt templates are Go text templates over a ReceiptDocument. Their output is
+// read line by line: plain lines are printed as text and lines starting with "@"
+// are layout directives. Empty lines are ignored (use @feed for blank lines), so
+// {{if}}/{{range}} actions can sit on their own lines. Directives:
+//
+//	@left / @center / @right      alignment (also "@align center")
+//	@bold [on|off]                emphasis
+//	@underline [on|off]
+//	@size WxH                     character size, 1-8 (e.g. @size 2x2)
+//	@normal                       size 1x1, bold and underline off
+//	@feed [n]                     n blank lines
+//	@line [char]                  full width separator (default "=")
+//	@logo                         restaurant logo
+//	@qr <data>                    QR code
+//	@barcode <data>               CODE128 barcode
+//	@cut                          cut the paper (feeds when the printer has no cutter)
+//	@drawer                       open the cash drawer (when the printer has one)
+//	@@text                        literal line starting with "@"
+//
+// Values printed by the template are always text: an "@" coming from the data
+// (a customer name or an item note) never starts a directive.
+
+// receiptTemplateDocumentTypes are the documents that can be printed from a template
+var receiptTemplateDocumentTypes = map[string]string{
+	models.PrintJobInvoice:            "Factura electrónica",
+	models.PrintJobReceipt:            "Recibo simple",
+	models.PrintJobKitchen:            "Comanda de cocina",
+	models.PrintJobOrder:              "Orden",
+	models.PrintJobCashRegisterReport: "Cierre de caja",
+}
+
+// ReceiptDocument is the data available to receipt templates
+type ReceiptDocument struct {
+	DocumentType string    `json:"document_type"`
+	Width        int       `json:"width"`       // Characters per line: 32 (58mm) or 48 (80mm)
+	PaperWidth   int       `json:"paper_width"` // 58 or 80
+	PrintedAt    time.Time `json:"printed_at"`
+	Printer      ReceiptPrinter
+	Business     ReceiptBusiness
+
+	// Sales and orders
+	Number         string           // Sale or order number
+	Date           time.Time        // Sale or order date
+	Invoice        *ReceiptInvoice  // Electronic invoice (invoice documents only)
+	Customer       *ReceiptCustomer // nil = CONSUMIDOR FINAL
+	Delivery       *ReceiptDelivery // nil when the order is not a delivery
+	Table          string
+	SequenceNumber int    // Takeout/sequence number (0 = none)
+	OrderType      string // Order type name
+	Items          []ReceiptItem
+	Totals         ReceiptTotals
+	Payments       []ReceiptPayment
+	Employee       string
+	CashRegisterID uint
+	Notes          string
+
+	// Cash register closing
+	Report          *models.CashRegisterReport
+	DifferenceLabel string // "SOBRANTE", "FALTANTE" or "CUADRE PERFECTO"
+
+	logo string
+}
+
+// ReceiptPrinter describes the printer the document is printed on
+type ReceiptPrinter struct {
+	Name       string
+	AutoCut    bool
+	CashDrawer bool
+}
+
+// ReceiptBusiness is the issuer data
+type ReceiptBusiness struct {
+	Name      string // Commercial name
+	LegalName string // Razón social
+	NIT       string // With verification digit
+	Regime    string
+	Liability string
+	Address   string
+	Location  string // Municipality, department and country
+	Phone     string
+	Email     string
+	Website   string
+	Header    string // Restaurant invoice header text
+	Footer    string // Restaurant invoice footer text
+	HasLogo   bool
+}
+
+// ReceiptInvoice is the DIAN data of an electronic invoice
+type ReceiptInvoice struct {
+	Title         []string // Header title lines
+	Label         string   // "Factura", "Documento POS", "Factura contingencia"
+	Number        string   // Prefix and number
+	IsPOS         bool
+	IsContingency bool
+	Resolution    ReceiptResolution
+	CUFE          string
+	KeyLabel      string // "CUFE" or "CUDE"
+	QRURL         string
+	PendingDIAN   bool // Contingency invoice not reported to DIAN yet (no CUFE)
+	IssuedAt      time.Time
+	PaymentMeans  string   // DIAN name of the payment method
+	PlateNumber   string   // POS cash register plate
+	Legend        []string // "REPRESENTACIÓN IMPRESA DE LA ..." lines
+}
+
+// ReceiptResolution is the numbering resolution of an invoice
+type ReceiptResolution struct {
+	Label    string
+	Number   string
+	Prefix   string
+	From     int
+	To       int
+	DateFrom time.Time
+	DateTo   time.Time
+}
+
+// ReceiptCustomer is the buyer of a sale
+type ReceiptCustomer struct {
+	Name            string
+	Identification  string // Number with verification digit
+	Address         string
+	Phone           string
+	Email           string
+	IsFinalConsumer bool
+}
+
+// ReceiptDelivery is the delivery data of an order
+type ReceiptDelivery struct {
+	Name    string
+	Address string
+	Phone   string
+}
+
+// ReceiptItem is a line of a sale or order
+type ReceiptItem struct {
+	Quantity               int
+	Name                   string  // Product name
+	Description            string  // Product name with the visible modifiers
+	UnitPrice              float64 // Base price, without modifiers
+	UnitPriceWithModifiers float64
+	Subtotal               float64
+	Modifiers              []ReceiptModifier
+	Notes                  string
+	Status                 string
+}
+
+// ReceiptModifier is a modifier of an item
+type ReceiptModifier struct {
+	Name  string
+	Price float64
+}
+
+// ReceiptTotals are the totals of a sale or order
+type ReceiptTotals struct {
+	Subtotal      float64
+	Discount      float64
+	Tax           float64
+	ServiceCharge float64
+	Total         float64
+	Tip           float64 // Voluntary tip, not part of Total
+	TotalPaid     float64 // Total plus tip
+}
+
+// ReceiptPayment is a payment of a sale
+type ReceiptPayment struct {
+	Method string
+	Amount float64
+}
+
+// receiptLineWidth returns the characters per line of a printer
+func receiptLineWidth(paperWidth int) int {
+	if paperWidth == 58 {
+		return 32
+	}
+	return 48
+}
+
+// receiptMoney formats an amount without decimals, like the built-in layouts
+func receiptMoney(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 0, 64)
+}
+
+// receiptTemplateFuncs are the functions available to templates; row and wrap
+// use the line width of the document
+func receiptTemplateFuncs(width int) template.FuncMap {
+	formatTime := func(layout string) func(t time.Time) string {
+		return func(t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.Format(layout)
+		}
+	}
+	return template.FuncMap{
+		"money":    receiptMoney,
+		"date":     formatTime("2006-01-02"),
+		"datetime": formatTime("2006-01-02 15:04:05"),
+		"time":     formatTime("15:04:05"),
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"repeat":   func(s string, n int) string { return strings.Repeat(s, n) },
+		// row puts left and right on the same line, right aligned
+		"row": func(left, right string) string {
+			space := width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+			if space < 1 {
+				pad := width - utf8.RuneCountInString(right)
+				if pad < 0 {
+					pad = 0
+				}
+				return left + "\n" + strings.Repeat(" ", pad) + right
+			}
+			return left + strings.Repeat(" ", space) + right
+		},
+		// wrap breaks text in lines of the document width
+		"wrap": func(text string) string {
+			return strings.Join(wrapReceiptText(text, width), "\n")
+		},
+		// receiptField is added by escapeReceiptFields to every printed value
+		"receiptField": func(value interface{}) string {
+			return strings.ReplaceAll(fmt.Sprint(value), "@", receiptFieldAt)
+		},
+	}
+}
+
+// receiptFieldAt stands for an "@" printed from a template value until the markup is parsed
+const receiptFieldAt = "\uE000"
+
+// escapeReceiptFields pipes every printed value of a template through receiptField,
+// so data can't add directives to the markup (like html/template does for HTML)
+func escapeReceiptFields(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			escapeReceiptFields(child)
+		}
+	case *parse.ActionNode:
+		// Variable declarations print nothing
+		if len(n.Pipe.Decl) == 0 {
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{parse.NewIdentifier("receiptField").SetPos(n.Pos)},
+			})
+		}
+	case *parse.IfNode:
+		escapeReceiptFields(n.List)
+		escapeReceiptFields(n.ElseList)
+	case *parse.RangeNode:
+		escapeReceiptFields(n.List)
+		escapeReceiptFields(n.ElseList)
+	case *parse.WithNode:
+		escapeReceiptFields(n.List)
+		escapeReceiptFields(n.ElseList)
+	}
+}
+
+// wrapReceiptText breaks text at spaces, or anywhere for words longer than width
+func wrapReceiptText(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for utf8.RuneCountInString(word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				runes := []rune(word)
+				lines = append(lines, string(runes[:width]))
+				word = string(runes[width:])
+			}
+			if line == "" {
+				line = word
+			} else if utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width {
+				line += " " + word
+			} else {
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// renderReceiptTemplate executes a template and parses its output into operations
+func renderReceiptTemplate(content string, doc *ReceiptDocument) ([]receiptOp, error) {
+	tmpl, err := template.New("receipt").Funcs(receiptTemplateFuncs(doc.Width)).Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("plantilla inválida: %w", err)
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			escapeReceiptFields(t.Tree.Root)
+		}
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, doc); err != nil {
+		return nil, fmt.Errorf("error generando la plantilla: %w", err)
+	}
+	return parseReceiptMarkup(out.String())
+}
+
+// receiptOp is one line of rendered template output
+type receiptOp struct {
+	kind string // "text", "align", "bold", "underline", "size", "normal", "feed", "line", "logo", "qr", "barcode", "cut", "drawer"
+	text string
+	on   bool
+	n    int
+	w, h int
+}
+
+// parseReceiptMarkup parses the template output; unknown or malformed directives are errors
+// An "@" escaped by receiptField is restored to a plain "@" here
+func parseReceiptMarkup(output string) ([]receiptOp, error) {
+	var ops []receiptOp
+	for number, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "@") {
+			ops = append(ops, receiptOp{kind: "text", text: strings.ReplaceAll(line, receiptFieldAt, "@")})
+			continue
+		}
+		if strings.HasPrefix(line, "@@") {
+			ops = append(ops, receiptOp{kind: "text", text: strings.ReplaceAll(line[1:], receiptFieldAt, "@")})
+			continue
+		}
+
+		directive, arg, _ := strings.Cut(line[1:], " ")
+		arg = strings.ReplaceAll(strings.TrimSpace(arg), receiptFieldAt, "@")
+		op, err := parseReceiptDirective(strings.ToLower(directive), arg)
+		if err != nil {
+			return nil, fmt.Errorf("línea %d (%s): %w", number+1, line, err)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+func parseReceiptDirective(directive, arg string) (receiptOp, error) {
+	onOff := func() (bool, error) {
+		switch strings.ToLower(arg) {
+		case "", "on":
+			return true, nil
+		case "off":
+			return false, nil
+		}
+		return false, fmt.Errorf("use on u off")
+	}
+
+	switch directive {
+	case "left", "center", "right":
+		return receiptOp{kind: "align", text: directive}, nil
+	case "align":
+		if arg != "left" && arg != "center" && arg != "right" {
+			return receiptOp{}, fmt.Errorf("alineación inválida, use left, center o right")
+		}
+		return receiptOp{kind: "align", text: arg}, nil
+	case "bold", "underline":
+		on, err := onOff()
+		return receiptOp{kind: directive, on: on}, err
+	case "size":
+		var w, h int
+		if _, err := fmt.Sscanf(strings.ToLower(arg), "%dx%d", &w, &h); err != nil || w < 1 || w > 8 || h < 1 || h > 8 {
+			return receiptOp{}, fmt.Errorf("tamaño inválido, use AxB entre 1 y 8 (ej: 2x2)")
+		}
+		return receiptOp{kind: "size", w: w, h: h}, nil
+	case "normal", "logo", "cut", "drawer":
+		return receiptOp{kind: directive}, nil
+	case "feed":
+		n := 1
+		if arg != "" {
+			var err error
+			if n, err = strconv.Atoi(arg); err != nil || n < 1 || n > 10 {
+				return receiptOp{}, fmt.Errorf("use un número de líneas entre 1 y 10")
+			}
+		}
+		return receiptOp{kind: "feed", n: n}, nil
+	case "line":
+		if arg == "" {
+			arg = "="
+		}
+		return receiptOp{kind: "line", text: arg}, nil
+	case "qr":
+		return receiptOp{kind: "qr", text: arg}, nil
+	case "barcode":
+		data := removeDiacritics(arg)
+		if len(data) > 250 {
+			return receiptOp{}, fmt.Errorf("el código de barras admite máximo 250 caracteres")
+		}
+		return receiptOp{kind: "barcode", text: data}, nil
+	}
+	return receiptOp{}, fmt.Errorf("directiva desconocida")
+}
+
+// writeReceiptOps writes rendered template operations to the printer buffer
+func (s *PrinterService) writeReceiptOps(ops []receiptOp, doc *ReceiptDocument) {
+	sizeWidth := 1
+	s.init()
+	for _, op := range ops {
+		switch op.kind {
+		case "text":
+			s.write(op.text + "\n")
+		case "align":
+			s.setAlign(op.text)
+		case "bold":
+			s.setEmphasize(op.on)
+		case "underline":
+			var n byte
+			if op.on {
+				n = 1
+			}
+			s.buffer.Write([]byte{ESC, '-', n})
+		case "size":
+			sizeWidth = op.w
+			s.setSize(byte(op.w), byte(op.h))
+		case "normal":
+			sizeWidth = 1
+			s.setSize(1, 1)
+			s.setEmphasize(false)
+			s.buffer.Write([]byte{ESC, '-', 0})
+		case "feed":
+			for i := 0; i < op.n; i++ {
+				s.lineFeed()
+			}
+		case "line":
+			s.write(receiptSeparator(op.text, doc.Width/sizeWidth) + "\n")
+		case "logo":
+			if doc.logo != "" {
+				if err := s.printLogoFromBase64(doc.logo); err != nil {
+					s.write("[LOGO]\n")
+				}
+			}
+		case "qr":
+			if op.text != "" {
+				if err := s.printQRCodeAsImage(op.text, 256); err != nil {
+					s.write("[ CODIGO QR - ERROR ]\n")
+				}
+			}
+		case "barcode":
+			if op.text != "" {
+				s.printBarcode(op.text)
+			}
+		case "cut":
+			if doc.Printer.AutoCut {
+				s.cut()
+			} else {
+				s.lineFeed()
+				s.lineFeed()
+				s.lineFeed()
+			}
+		case "drawer":
+			if doc.Printer.CashDrawer {
+				s.cashDrawer()
+			}
+		}
+	}
+}
+
+// printBarcode prints a CODE128 barcode with its text below
+func (s *PrinterService) printBarcode(data string) {
+	payload := append([]byte("{B"), data...)
+	s.buffer.Write([]byte{GS, 'h', 80}) // Height in dots
+	s.buffer.Write([]byte{GS, 'w', 2})  // Module width
+	s.buffer.Write([]byte{GS, 'H', 2})  // Text below the bars
+	s.buffer.Write([]byte{GS, 'k', 73, byte(len(payload))})
+	s.buffer.Write(payload)
+	s.lineFeed()
+}
+
+func receiptSeparator(char string, width int) string {
+	if width < 1 {
+		width = 1
+	}
+	line := strings.Repeat(char, width)
+	return string([]rune(line)[:width])
+}
+
+// previewReceiptOps renders the operations as plain text, approximating alignment and size
+func previewReceiptOps(ops []receiptOp, doc *ReceiptDocument) string {
+	var out strings.Builder
+	align := "left"
+	sizeWidth := 1
+
+	writeLine := func(text string) {
+		width := doc.Width / sizeWidth
+		for _, line := range strings.Split(text, "\n") {
+			length := utf8.RuneCountInString(line)
+			pad := 0
+			switch align {
+			case "center":
+				pad = (width - length) / 2
+			case "right":
+				pad = width - length
+			}
+			if pad < 0 {
+				pad = 0
+			}
+			out.WriteString(strings.Repeat(" ", pad*sizeWidth))
+			if sizeWidth > 1 {
+				// Wide characters are shown spaced out
+				runes := []rune(line)
+				for i, r := range runes {
+					out.WriteRune(r)
+					if i < len(runes)-1 {
+						out.WriteString(strings.Repeat(" ", sizeWidth-1))
+					}
+				}
+			} else {
+				out.WriteString(line)
+			}
+			out.WriteString("\n")
+		}
+	}
+
+	for _, op := range ops {
+		switch op.kind {
+		case "text":
+			writeLine(op.text)
+		case "align":
+			align = op.text
+		case "size":
+			sizeWidth = op.w
+		case "normal":
+			sizeWidth = 1
+		case "feed":
+			out.WriteString(strings.Repeat("\n", op.n))
+		case "line":
+			writeLine(receiptSeparator(op.text, doc.Width/sizeWidth))
+		case "logo":
+			if doc.logo != "" || doc.Business.HasLogo {
+				writeLine("[LOGO]")
+			}
+		case "qr":
+			if op.text != "" {
+				writeLine("[QR]")
+			}
+		case "barcode":
+			if op.text != "" {
+				writeLine("||||| " + op.text + " |||||")
+			}
+		case "cut":
+			out.WriteString(strings.Repeat("- ", doc.Width/2) + "\n")
+		case "drawer":
+			if doc.Printer.CashDrawer {
+				writeLine("[ABRIR CAJON]")
+			}
+		}
+	}
+	return out.String()
+}
+
+// ==================== DOCUMENTS ====================
+
+// findReceiptTemplate returns the active template of a document type for a printer:
+// the one bound to the printer, else the one for all printers
+func (s *PrinterService) findReceiptTemplate(documentType string, printerID uint) *models.ReceiptTemplate {
+	if s.db == nil {
+		return nil
+	}
+	var tmpl models.ReceiptTemplate
+	err := s.db.Where("document_type = ? AND is_active = ? AND printer_id = ?", documentType, true, printerID).
+		Order("updated_at DESC").First(&tmpl).Error
+	if err == nil {
+		return &tmpl
+	}
+	err = s.db.Where("document_type = ? AND is_active = ? AND printer_id IS NULL", documentType, true).
+		Order("updated_at DESC").First(&tmpl).Error
+	if err == nil {
+		return &tmpl
+	}
+	return nil
+}
+
+// printWithReceiptTemplate prints a document with the template configured for it.
+// It returns false when there is no template or it could not be rendered, in which
+// case the caller prints the built-in layout
+func (s *PrinterService) printWithReceiptTemplate(documentType string, config *models.PrinterConfig, build func() *ReceiptDocument) (bool, error) {
+	tmpl := s.findReceiptTemplate(documentType, config.ID)
+	if tmpl == nil {
+		return false, nil
+	}
+
+	doc := build()
+	ops, err := renderReceiptTemplate(tmpl.Content, doc)
+	if err != nil {
+		log.Printf("Warning: Receipt template %d (%s) failed, using built-in layout: %v", tmpl.ID, tmpl.Name, err)
+		return false, nil
+	}
+
+	s.buffer.Reset()
+	s.writeReceiptOps(ops, doc)
+	return true, s.print()
+}
+
+// newReceiptDocument fills the printer and business data of a document
+func (s *PrinterService) newReceiptDocument(documentType string, config *models.PrinterConfig) *ReceiptDocument {
+	doc := &ReceiptDocument{
+		DocumentType: documentType,
+		PaperWidth:   config.PaperWidth,
+		Width:        receiptLineWidth(config.PaperWidth),
+		PrintedAt:    time.Now(),
+		Printer: ReceiptPrinter{
+			Name:       config.Name,
+			AutoCut:    config.AutoCut,
+			CashDrawer: config.CashDrawer,
+		},
+	}
+	if doc.PaperWidth != 58 {
+		doc.PaperWidth = 80
+	}
+
+	var restaurant models.RestaurantConfig
+	s.db.First(&restaurant)
+	var dianConfig models.DIANConfig
+	s.db.First(&dianConfig)
+	parametricData := models.GetDIANParametricData()
+
+	legalName := dianConfig.BusinessName
+	if legalName == "" {
+		legalName = restaurant.BusinessName
+	}
+	if legalName == "" {
+		legalName = restaurant.Name
+	}
+	nit := dianConfig.IdentificationNumber
+	if dianConfig.DV != "" {
+		nit += "-" + dianConfig.DV
+	}
+
+	doc.logo = restaurant.Logo
+	doc.Business = ReceiptBusiness{
+		Name:      restaurant.Name,
+		LegalName: legalName,
+		NIT:       nit,
+		Address:   restaurant.Address,
+		Phone:     restaurant.Phone,
+		Email:     restaurant.Email,
+		Website:   restaurant.Website,
+		Header:    restaurant.InvoiceHeader,
+		Footer:    restaurant.InvoiceFooter,
+		HasLogo:   restaurant.Logo != "",
+	}
+	if regime, ok := parametricData.TypeRegimes[dianConfig.TypeRegimeID]; ok {
+		doc.Business.Regime = regime.Name
+	}
+	if liability, ok := parametricData.TypeLiabilities[dianConfig.TypeLiabilityID]; ok {
+		doc.Business.Liability = liability.Name
+	}
+	if municipality, ok := parametricData.Municipalities[dianConfig.MunicipalityID]; ok && dianConfig.MunicipalityID > 0 {
+		location := municipality.Name
+		if dept, ok := parametricData.Departments[municipality.DepartmentID]; ok {
+			location += ", " + dept.Name
+		}
+		doc.Business.Location = location + " - Colombia"
+	}
+	return doc
+}
+
+// receiptItems converts order items; hideInvoiceModifiers leaves out the modifiers hidden from invoices
+func receiptItems(items []models.OrderItem, hideInvoiceModifiers bool) []ReceiptItem {
+	result := make([]ReceiptItem, 0, len(items))
+	for _, item := range items {
+		receiptItem := ReceiptItem{
+			Quantity:               item.Quantity,
+			UnitPrice:              item.UnitPrice,
+			UnitPriceWithModifiers: item.UnitPrice,
+			Subtotal:               item.Subtotal,
+			Notes:                  item.Notes,
+			Status:                 item.Status,
+		}
+		if item.Product != nil {
+			receiptItem.Name = item.Product.Name
+		}
+
+		var visible []string
+		for _, itemMod := range item.Modifiers {
+			if itemMod.Modifier == nil || (hideInvoiceModifiers && itemMod.Modifier.HideFromInvoice) {
+				continue
+			}
+			visible = append(visible, itemMod.Modifier.Name)
+			receiptItem.Modifiers = append(receiptItem.Modifiers, ReceiptModifier{
+				Name:  itemMod.Modifier.Name,
+				Price: itemMod.PriceChange,
+			})
+			receiptItem.UnitPriceWithModifiers += itemMod.PriceChange
+		}
+		receiptItem.Description = receiptItem.Name
+		if len(visible) > 0 {
+			receiptItem.Description = fmt.Sprintf("%s (%s)", receiptItem.Name, strings.Join(visible, ", "))
+		}
+		result = append(result, receiptItem)
+	}
+	return result
+}
+
+func receiptDelivery(order *models.Order) *ReceiptDelivery {
+	if order == nil || (order.DeliveryCustomerName == "" && order.DeliveryAddress == "" && order.DeliveryPhone == "") {
+		return nil
+	}
+	return &ReceiptDelivery{
+		Name:    order.DeliveryCustomerName,
+		Address: order.DeliveryAddress,
+		Phone:   order.DeliveryPhone,
+	}
+}
+
+// saleReceiptDocument builds the document of a sale receipt or electronic invoice
+func (s *PrinterService) saleReceiptDocument(sale *models.Sale, config *models.PrinterConfig, electronic bool) *ReceiptDocument {
+	s.db.Preload("Customer").Preload("Order").Preload("Order.Items.Product").Preload("Order.Items.Modifiers.Modifier").
+		Preload("PaymentDetails.PaymentMethod").Preload("Employee").First(sale, sale.ID)
+
+	documentType := models.PrintJobReceipt
+	if electronic {
+		documentType = models.PrintJobInvoice
+	}
+	doc := s.newReceiptDocument(documentType, config)
+	doc.Number = sale.SaleNumber
+	doc.Date = sale.CreatedAt
+	doc.Notes = sale.Notes
+	doc.Delivery = receiptDelivery(sale.Order)
+	if sale.Order != nil {
+		doc.Items = receiptItems(sale.Order.Items, true)
+	}
+	if sale.Employee != nil {
+		doc.Employee = sale.Employee.Name
+	}
+	if sale.CashRegisterID != nil {
+		doc.CashRegisterID = *sale.CashRegisterID
+	}
+	doc.Totals = ReceiptTotals{
+		Subtotal:      sale.Subtotal,
+		Discount:      sale.Discount,
+		Tax:           sale.Tax,
+		ServiceCharge: sale.ServiceCharge,
+		Total:         sale.Total,
+		Tip:           sale.Tip,
+		TotalPaid:     sale.Total + sale.Tip,
+	}
+	for _, payment := range sale.PaymentDetails {
+		method := ""
+		if payment.PaymentMethod != nil {
+			method = payment.PaymentMethod.Name
+		}
+		doc.Payments = append(doc.Payments, ReceiptPayment{Method: method, Amount: payment.Amount})
+	}
+
+	if customer := sale.Customer; customer != nil {
+		doc.Customer = &ReceiptCustomer{
+			Name:            customer.Name,
+			Identification:  customer.IdentificationNumber,
+			Email:           customer.Email,
+			IsFinalConsumer: customer.IdentificationNumber == "222222222222",
+		}
+		if customer.DV != nil && *customer.DV != "" {
+			doc.Customer.Identification += "-" + *customer.DV
+		}
+		// CONSUMIDOR FINAL carries placeholder data that is not printed
+		if !doc.Customer.IsFinalConsumer {
+			if customer.Address != "NO REGISTRADO" {
+				doc.Customer.Address = customer.Address
+			}
+			if customer.Phone != "0" {
+				doc.Customer.Phone = customer.Phone
+			}
+		}
+	}
+
+	if electronic && sale.ElectronicInvoice != nil {
+		doc.Invoice = s.receiptInvoice(sale)
+	}
+	return doc
+}
+
+// receiptInvoice builds the DIAN data of an electronic invoice
+func (s *PrinterService) receiptInvoice(sale *models.Sale) *ReceiptInvoice {
+	invoice := sale.ElectronicInvoice
+	var dianConfig models.DIANConfig
+	s.db.First(&dianConfig)
+
+	resolution := invoiceResolution(&dianConfig, invoice)
+	receiptInvoice := &ReceiptInvoice{
+		Title:         []string{"FACTURA ELECTRONICA", "DE VENTA"},
+		Label:         "Factura",
+		Number:        invoice.Prefix + invoice.InvoiceNumber,
+		IsPOS:         invoice.DocumentType == "pos_equivalent",
+		IsContingency: invoice.IsContingency,
+		Resolution: ReceiptResolution{
+			Label:    resolution.Label,
+			Number:   resolution.Number,
+			Prefix:   resolution.Prefix,
+			From:     resolution.From,
+			To:       resolution.To,
+			DateFrom: resolution.DateFrom,
+			DateTo:   resolution.DateTo,
+		},
+		CUFE:     invoice.CUFE,
+		KeyLabel: "CUFE",
+		QRURL:    invoice.QRCode,
+		IssuedAt: invoice.CreatedAt,
+		Legend:   []string{"*** REPRESENTACIÓN IMPRESA DE LA ***", "*** FACTURA ELECTRÓNICA DE VENTA ***"},
+	}
+	if receiptInvoice.IsPOS {
+		receiptInvoice.Title = []string{"DOCUMENTO", "EQUIVALENTE POS"}
+		receiptInvoice.Label = "Documento POS"
+		receiptInvoice.KeyLabel = "CUDE"
+		receiptInvoice.PlateNumber = dianConfig.POSPlateNumber
+		receiptInvoice.Legend = []string{"*** REPRESENTACIÓN IMPRESA DEL ***", "*** DOCUMENTO EQUIVALENTE ELECTRÓNICO POS ***"}
+	} else if receiptInvoice.IsContingency {
+		receiptInvoice.Title = []string{"FACTURA DE", "CONTINGENCIA"}
+		receiptInvoice.Label = "Factura contingencia"
+		receiptInvoice.Legend = []string{"*** FACTURA DE CONTINGENCIA ***", "*** EXPEDIDA POR FALLA DEL SERVICIO DIAN ***"}
+	}
+	receiptInvoice.PendingDIAN = invoice.CUFE == "" && invoice.IsContingency
+	if receiptInvoice.QRURL == "" && invoice.CUFE != "" {
+		receiptInvoice.QRURL = fmt.Sprintf("https://catalogo-vpfe.dian.gov.co/document/searchqr?documentkey=%s", invoice.CUFE)
+	}
+
+	// Only one payment method is reported per invoice, with its DIAN name
+	if len(sale.PaymentDetails) > 0 && sale.PaymentDetails[0].PaymentMethod != nil {
+		method := sale.PaymentDetails[0].PaymentMethod
+		receiptInvoice.PaymentMeans = method.Name
+		if method.DIANPaymentMethodID != nil {
+			if dianMethod, ok := models.GetDIANParametricData().PaymentMethods[*method.DIANPaymentMethodID]; ok {
+				receiptInvoice.PaymentMeans = dianMethod.Name
+			}
+		}
+	}
+	return receiptInvoice
+}
+
+// orderReceiptDocument builds the document of an order or, for kitchen tickets,
+// of the order items that still have to be prepared
+func (s *PrinterService) orderReceiptDocument(order *models.Order, config *models.PrinterConfig, kitchen bool) *ReceiptDocument {
+	s.db.Preload("Table").Preload("Customer").Preload("Employee").Preload("OrderType").
+		Preload("Items.Product").Preload("Items.Modifiers.Modifier").First(order, order.ID)
+
+	documentType := models.PrintJobOrder
+	if kitchen {
+		documentType = models.PrintJobKitchen
+	}
+	doc := s.newReceiptDocument(documentType, config)
+	doc.Number = order.OrderNumber
+	doc.Date = order.CreatedAt
+	doc.Notes = order.Notes
+	doc.Delivery = receiptDelivery(order)
+	doc.OrderType = order.Type
+	if order.OrderType != nil {
+		doc.OrderType = order.OrderType.Name
+	}
+	if order.Table != nil {
+		doc.Table = order.Table.Number
+	}
+	if order.SequenceNumber != nil {
+		doc.SequenceNumber = *order.SequenceNumber
+	} else if order.TakeoutNumber != nil {
+		doc.SequenceNumber = *order.TakeoutNumber
+	}
+	if order.Customer != nil {
+		doc.Customer = &ReceiptCustomer{
+			Name:            order.Customer.Name,
+			Identification:  order.Customer.IdentificationNumber,
+			Phone:           order.Customer.Phone,
+			IsFinalConsumer: order.Customer.IdentificationNumber == "222222222222",
+		}
+	}
+	if order.Employee != nil {
+		doc.Employee = order.Employee.Name
+	}
+	doc.Totals = ReceiptTotals{
+		Subtotal:      order.Subtotal,
+		Discount:      order.Discount,
+		Tax:           order.Tax,
+		ServiceCharge: order.ServiceCharge,
+		Total:         order.Total,
+		TotalPaid:     order.Total,
+	}
+
+	items := order.Items
+	if kitchen {
+		items = nil
+		for _, item := range order.Items {
+			if item.Status == "pending" || item.Status == "preparing" {
+				items = append(items, item)
+			}
+		}
+	}
+	doc.Items = receiptItems(items, false)
+	return doc
+}
+
+// cashRegisterReceiptDocument builds the document of a cash register closing
+func (s *PrinterService) cashRegisterReceiptDocument(report *models.CashRegisterReport, config *models.PrinterConfig) *ReceiptDocument {
+	if report.Employee == nil && report.GeneratedBy > 0 {
+		var employee models.Employee
+		if err := s.db.First(&employee, report.GeneratedBy).Error; err == nil {
+			report.Employee = &employee
+		}
+	}
+
+	doc := s.newReceiptDocument(models.PrintJobCashRegisterReport, config)
+	doc.Report = report
+	doc.Date = report.Date
+	doc.Notes = report.Notes
+	doc.CashRegisterID = report.CashRegisterID
+	if report.Employee != nil {
+		doc.Employee = report.Employee.Name
+	}
+	switch {
+	case report.Difference > 0:
+		doc.DifferenceLabel = "SOBRANTE"
+	case report.Difference < 0:
+		doc.DifferenceLabel = "FALTANTE"
+	default:
+		doc.DifferenceLabel = "CUADRE PERFECTO"
+	}
+	return doc
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReceiptMarkup(t *testing.T) {
+	tests := []struct {
+		name    string
+		markup  string
+		want    []receiptOp
+		wantErr bool
+	}{
+		{"text and blank lines", "Hola\n\n  \nMundo", []receiptOp{{kind: "text", text: "Hola"}, {kind: "text", text: "Mundo"}}, false},
+		{"alignment", "@center\n@align right", []receiptOp{{kind: "align", text: "center"}, {kind: "align", text: "right"}}, false},
+		{"bold off", "@bold off", []receiptOp{{kind: "bold", on: false}}, false},
+		{"size", "@size 2X3", []receiptOp{{kind: "size", w: 2, h: 3}}, false},
+		{"default separator", "@line", []receiptOp{{kind: "line", text: "="}}, false},
+		{"feed", "@feed 3", []receiptOp{{kind: "feed", n: 3}}, false},
+		{"literal at", "@@usuario", []receiptOp{{kind: "text", text: "@usuario"}}, false},
+		{"escaped value", receiptFieldAt + "cut", []receiptOp{{kind: "text", text: "@cut"}}, false},
+		{"escaped value in argument", "@qr pago" + receiptFieldAt + "pos", []receiptOp{{kind: "qr", text: "pago@pos"}}, false},
+		{"unknown directive", "@beep", nil, true},
+		{"size out of range", "@size 9x1", nil, true},
+		{"invalid alignment", "@align top", nil, true},
+		{"feed out of range", "@feed 11", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseReceiptMarkup(tt.markup)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseReceiptMarkup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseReceiptMarkup() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapReceiptText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"fits", "sin cebolla", 32, []string{"sin cebolla"}},
+		{"breaks at spaces", "uno dos tres", 7, []string{"uno dos", "tres"}},
+		{"long word", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"keeps line breaks", "uno\ndos", 32, []string{"uno", "dos"}},
+		{"counts runes", "ñandú ñandú", 5, []string{"ñandú", "ñandú"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapReceiptText(tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapReceiptText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderReceiptTemplateEscapesValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		doc      ReceiptDocument
+		want     []receiptOp
+	}{
+		{
+			name:     "note starting with at",
+			template: "{{wrap .Notes}}",
+			doc:      ReceiptDocument{Width: 32, Notes: "@drawer"},
+			want:     []receiptOp{{kind: "text", text: "@drawer"}},
+		},
+		{
+			name:     "directive after a line break in a value",
+			template: "Nota: {{.Notes}}",
+			doc:      ReceiptDocument{Width: 32, Notes: "sin sal\n@cut"},
+			want:     []receiptOp{{kind: "text", text: "Nota: sin sal"}, {kind: "text", text: "@cut"}},
+		},
+		{
+			name:     "row",
+			template: "{{range .Items}}{{row .Name (money .Subtotal)}}{{end}}",
+			doc:      ReceiptDocument{Width: 12, Items: []ReceiptItem{{Name: "@qr x", Subtotal: 500}}},
+			want:     []receiptOp{{kind: "text", text: "@qr x    500"}},
+		},
+		{
+			name:     "template directives still work",
+			template: "@center\n{{.Business.Name}}\n{{if .Printer.AutoCut}}@cut{{end}}",
+			doc:      ReceiptDocument{Width: 32, Business: ReceiptBusiness{Name: "@bold Café"}, Printer: ReceiptPrinter{AutoCut: true}},
+			want:     []receiptOp{{kind: "align", text: "center"}, {kind: "text", text: "@bold Café"}, {kind: "cut"}},
+		},
+		{
+			name:     "value as directive argument",
+			template: "@qr {{.Notes}}",
+			doc:      ReceiptDocument{Width: 32, Notes: "mesa@5"},
+			want:     []receiptOp{{kind: "qr", text: "mesa@5"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderReceiptTemplate(tt.template, &tt.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("renderReceiptTemplate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,11 +3,16 @@ package services
 import (
 	"PosApp/app/database"
 	"PosApp/app/models"
+	"PosApp/app/websocket"
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -15,8 +20,11 @@ import (
 
 // ReportsService handles report generation
 type ReportsService struct {
-	db        *gorm.DB
-	configSvc *ConfigService
+	db          *gorm.DB
+	configSvc   *ConfigService
+	wsServer    *websocket.Server
+	monitorMu   sync.Mutex
+	monitorStop chan bool
 }
 
 // NewReportsService creates a new reports service
@@ -624,6 +632,992 @@ func (s *ReportsService) GetDashboardStats() (map[string]interface{}, error) {
 	return stats, nil
 }
 
+// Kitchen Reports
+
+// KitchenPerformanceReport represents kitchen ticket timing metrics for a period
+type KitchenPerformanceReport struct {
+	Period               string                `json:"period"`
+	StartDate            time.Time             `json:"start_date"`
+	EndDate              time.Time             `json:"end_date"`
+	SLAMinutes           int                   `json:"sla_minutes"`
+	TotalTickets         int                   `json:"total_tickets"`
+	AvgTicketMinutes     float64               `json:"avg_ticket_minutes"`
+	P90TicketMinutes     float64               `json:"p90_ticket_minutes"`
+	AvgAckToReadyMinutes float64               `json:"avg_ack_to_ready_minutes"`
+	P90AckToReadyMinutes float64               `json:"p90_ack_to_ready_minutes"`
+	LateTickets          int                   `json:"late_tickets"`
+	LateTicketRate       float64               `json:"late_ticket_rate"`
+	ByHour               []KitchenTimingBucket `json:"by_hour"`
+	ByStation            []KitchenTimingBucket `json:"by_station"`
+	ByProduct            []KitchenTimingBucket `json:"by_product"`
+	ByEmployee           []KitchenTimingBucket `json:"by_employee"`
+	LateTicketDetails    []KitchenLateTicket   `json:"late_ticket_details"`
+}
+
+// KitchenTimingBucket represents ticket timing aggregated by a dimension (hour, station, product, employee)
+type KitchenTimingBucket struct {
+	Key         string  `json:"key"`
+	Label       string  `json:"label"`
+	Tickets     int     `json:"tickets"`
+	AvgMinutes  float64 `json:"avg_minutes"`
+	P90Minutes  float64 `json:"p90_minutes"`
+	LateTickets int     `json:"late_tickets"`
+}
+
+// KitchenLateTicket represents a ticket that exceeded the kitchen SLA
+type KitchenLateTicket struct {
+	OrderID      uint      `json:"order_id"`
+	OrderNumber  string    `json:"order_number"`
+	EmployeeName string    `json:"employee_name"`
+	SentAt       time.Time `json:"sent_at"`
+	ReadyAt      time.Time `json:"ready_at"`
+	Minutes      float64   `json:"minutes"`
+}
+
+// KitchenOpenTicket represents an order still being prepared in the kitchen
+type KitchenOpenTicket struct {
+	OrderID     uint      `json:"order_id"`
+	OrderNumber string    `json:"order_number"`
+	TableNumber string    `json:"table_number,omitempty"`
+	Status      string    `json:"status"`
+	SentAt      time.Time `json:"sent_at"`
+	AgeMinutes  float64   `json:"age_minutes"`
+	IsLate      bool      `json:"is_late"`
+}
+
+// KitchenLiveStatus represents the live kitchen state pushed to kitchen displays
+type KitchenLiveStatus struct {
+	OpenTickets     int                `json:"open_tickets"`
+	LateOpenTickets int                `json:"late_open_tickets"`
+	SLAMinutes      int                `json:"sla_minutes"`
+	OldestTicket    *KitchenOpenTicket `json:"oldest_ticket,omitempty"`
+	GeneratedAt     time.Time          `json:"generated_at"`
+}
+
+// kitchenItemTiming is a prepared order item with its kitchen timestamps
+type kitchenItemTiming struct {
+	OrderID               uint
+	OrderNumber           string
+	EmployeeID            uint
+	EmployeeName          string
+	ProductID             uint
+	ProductName           string
+	Station               string
+	SentToKitchenAt       time.Time
+	PreparedAt            time.Time
+	KitchenAcknowledgedAt *time.Time
+}
+
+// kitchenTicket is an order-level ticket built from its items
+type kitchenTicket struct {
+	OrderID      uint
+	OrderNumber  string
+	EmployeeID   uint
+	EmployeeName string
+	SentAt       time.Time
+	ReadyAt      time.Time
+	AckAt        *time.Time
+}
+
+// GetKitchenSLAMinutes returns the configured kitchen ticket SLA in minutes
+func (s *ReportsService) GetKitchenSLAMinutes() int {
+	return s.configSvc.GetSystemConfigInt("kitchen_ticket_sla_minutes", 15)
+}
+
+// GetKitchenPerformanceReport generates ticket time metrics from order item kitchen timestamps.
+// A ticket is an order: it starts when its first item is sent to kitchen and ends when its last item is prepared.
+func (s *ReportsService) GetKitchenPerformanceReport(startDate, endDate time.Time) (*KitchenPerformanceReport, error) {
+	slaMinutes := s.GetKitchenSLAMinutes()
+	report := &KitchenPerformanceReport{
+		Period:     fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
+		StartDate:  startDate,
+		EndDate:    endDate,
+		SLAMinutes: slaMinutes,
+	}
+
+	var items []kitchenItemTiming
+	err := s.db.Table("order_items").
+		Select(`order_items.order_id, orders.order_number, orders.employee_id,
+			COALESCE(employees.name, '') as employee_name,
+			order_items.product_id, COALESCE(products.name, '') as product_name,
+			COALESCE(NULLIF(categories.kitchen_station, ''), categories.name, 'General') as station,
+			order_items.sent_to_kitchen_at, order_items.prepared_at, orders.kitchen_acknowledged_at`).
+		Joins("JOIN orders ON order_items.order_id = orders.id AND orders.deleted_at IS NULL").
+		Joins("LEFT JOIN employees ON orders.employee_id = employees.id").
+		Joins("LEFT JOIN products ON order_items.product_id = products.id").
+		Joins("LEFT JOIN categories ON products.category_id = categories.id").
+		Where("order_items.sent_to_kitchen_at BETWEEN ? AND ?", startDate, endDate).
+		Where("order_items.prepared_at IS NOT NULL").
+		Where("orders.status <> ?", models.OrderStatusCancelled).
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sla := float64(slaMinutes)
+
+	// Item-level timings for station and product breakdowns
+	stationTimes := make(map[string][]float64)
+	productTimes := make(map[string][]float64)
+	productLabels := make(map[string]string)
+
+	// Build order-level tickets
+	tickets := make(map[uint]*kitchenTicket)
+	for _, item := range items {
+		minutes := item.PreparedAt.Sub(item.SentToKitchenAt).Minutes()
+		if minutes < 0 {
+			continue
+		}
+		stationTimes[item.Station] = append(stationTimes[item.Station], minutes)
+		productKey := fmt.Sprintf("%d", item.ProductID)
+		productTimes[productKey] = append(productTimes[productKey], minutes)
+		productLabels[productKey] = item.ProductName
+
+		ticket, exists := tickets[item.OrderID]
+		if !exists {
+			ticket = &kitchenTicket{
+				OrderID:      item.OrderID,
+				OrderNumber:  item.OrderNumber,
+				EmployeeID:   item.EmployeeID,
+				EmployeeName: item.EmployeeName,
+				SentAt:       item.SentToKitchenAt,
+				ReadyAt:      item.PreparedAt,
+				AckAt:        item.KitchenAcknowledgedAt,
+			}
+			tickets[item.OrderID] = ticket
+			continue
+		}
+		if item.SentToKitchenAt.Before(ticket.SentAt) {
+			ticket.SentAt = item.SentToKitchenAt
+		}
+		if item.PreparedAt.After(ticket.ReadyAt) {
+			ticket.ReadyAt = item.PreparedAt
+		}
+	}
+
+	// The recorded transition to "ready" is the authoritative ready time when available
+	if len(tickets) > 0 {
+		orderIDs := make([]uint, 0, len(tickets))
+		for id := range tickets {
+			orderIDs = append(orderIDs, id)
+		}
+
+		var readyEvents []struct {
+			OrderID uint
+			ReadyAt time.Time
+		}
+		s.db.Model(&models.OrderStatusEvent{}).
+			Select("order_id, MIN(created_at) as ready_at").
+			Where("order_id IN ? AND to_status = ?", orderIDs, models.OrderStatusReady).
+			Group("order_id").
+			Scan(&readyEvents)
+
+		for _, ev := range readyEvents {
+			if ticket, ok := tickets[ev.OrderID]; ok && ev.ReadyAt.After(ticket.SentAt) {
+				ticket.ReadyAt = ev.ReadyAt
+			}
+		}
+	}
+
+	var ticketTimes, ackTimes []float64
+	hourTimes := make(map[string][]float64)
+	employeeTimes := make(map[string][]float64)
+	employeeLabels := make(map[string]string)
+
+	for _, ticket := range tickets {
+		minutes := ticket.ReadyAt.Sub(ticket.SentAt).Minutes()
+		ticketTimes = append(ticketTimes, minutes)
+
+		if ticket.AckAt != nil && !ticket.ReadyAt.Before(*ticket.AckAt) {
+			ackTimes = append(ackTimes, ticket.ReadyAt.Sub(*ticket.AckAt).Minutes())
+		}
+
+		hourKey := fmt.Sprintf("%02d", ticket.SentAt.In(time.Local).Hour())
+		hourTimes[hourKey] = append(hourTimes[hourKey], minutes)
+
+		employeeKey := fmt.Sprintf("%d", ticket.EmployeeID)
+		employeeTimes[employeeKey] = append(employeeTimes[employeeKey], minutes)
+		employeeLabels[employeeKey] = ticket.EmployeeName
+
+		if minutes > sla {
+			report.LateTicketDetails = append(report.LateTicketDetails, KitchenLateTicket{
+				OrderID:      ticket.OrderID,
+				OrderNumber:  ticket.OrderNumber,
+				EmployeeName: ticket.EmployeeName,
+				SentAt:       ticket.SentAt,
+				ReadyAt:      ticket.ReadyAt,
+				Minutes:      minutes,
+			})
+		}
+	}
+
+	report.TotalTickets = len(ticketTimes)
+	report.AvgTicketMinutes = averageMinutes(ticketTimes)
+	report.P90TicketMinutes = percentileMinutes(ticketTimes, 90)
+	report.AvgAckToReadyMinutes = averageMinutes(ackTimes)
+	report.P90AckToReadyMinutes = percentileMinutes(ackTimes, 90)
+	report.LateTickets = len(report.LateTicketDetails)
+	if report.TotalTickets > 0 {
+		report.LateTicketRate = (float64(report.LateTickets) / float64(report.TotalTickets)) * 100
+	}
+
+	sort.Slice(report.LateTicketDetails, func(i, j int) bool {
+		return report.LateTicketDetails[i].Minutes > report.LateTicketDetails[j].Minutes
+	})
+
+	report.ByHour = buildKitchenBuckets(hourTimes, nil, sla)
+	report.ByStation = buildKitchenBuckets(stationTimes, nil, sla)
+	report.ByProduct = buildKitchenBuckets(productTimes, productLabels, sla)
+	report.ByEmployee = buildKitchenBuckets(employeeTimes, employeeLabels, sla)
+
+	// Hours read best in chronological order
+	sort.Slice(report.ByHour, func(i, j int) bool {
+		return report.ByHour[i].Key < report.ByHour[j].Key
+	})
+
+	return report, nil
+}
+
+// GetDailyKitchenPerformanceReport gets the kitchen performance report for a specific day
+func (s *ReportsService) GetDailyKitchenPerformanceReport(date time.Time) (*KitchenPerformanceReport, error) {
+	startDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endDate := startDate.Add(24 * time.Hour)
+	return s.GetKitchenPerformanceReport(startDate, endDate)
+}
+
+// GetKitchenLiveStatus returns the open kitchen tickets summary, including the oldest open ticket
+func (s *ReportsService) GetKitchenLiveStatus() (*KitchenLiveStatus, error) {
+	slaMinutes := s.GetKitchenSLAMinutes()
+	status := &KitchenLiveStatus{
+		SLAMinutes:  slaMinutes,
+		GeneratedAt: time.Now(),
+	}
+
+	var openTickets []struct {
+		OrderID     uint
+		OrderNumber string
+		TableNumber string
+		Status      string
+		SentAt      time.Time
+	}
+	err := s.db.Table("order_items").
+		Select(`order_items.order_id, orders.order_number, COALESCE(tables.number, '') as table_number,
+			orders.status, MIN(order_items.sent_to_kitchen_at) as sent_at`).
+		Joins("JOIN orders ON order_items.order_id = orders.id AND orders.deleted_at IS NULL").
+		Joins("LEFT JOIN tables ON orders.table_id = tables.id").
+		Where("orders.status IN ?", []models.OrderStatus{models.OrderStatusPending, models.OrderStatusPreparing}).
+		Where("order_items.sent_to_kitchen_at IS NOT NULL AND order_items.prepared_at IS NULL").
+		Group("order_items.order_id, orders.order_number, tables.number, orders.status").
+		Order("sent_at ASC").
+		Scan(&openTickets).Error
+	if err != nil {
+		return nil, err
+	}
+
+	status.OpenTickets = len(openTickets)
+	for i, t := range openTickets {
+		age := time.Since(t.SentAt).Minutes()
+		isLate := age > float64(slaMinutes)
+		if isLate {
+			status.LateOpenTickets++
+		}
+		if i == 0 {
+			status.OldestTicket = &KitchenOpenTicket{
+				OrderID:     t.OrderID,
+				OrderNumber: t.OrderNumber,
+				TableNumber: t.TableNumber,
+				Status:      t.Status,
+				SentAt:      t.SentAt,
+				AgeMinutes:  age,
+				IsLate:      isLate,
+			}
+		}
+	}
+
+	return status, nil
+}
+
+// SetWebSocketServer sets the WebSocket server used to push live kitchen metrics
+func (s *ReportsService) SetWebSocketServer(server *websocket.Server) {
+	s.wsServer = server
+}
+
+// StartKitchenMonitor periodically pushes the live kitchen status to kitchen displays.
+// The interval follows the kitchen_refresh_interval system config (seconds).
+func (s *ReportsService) StartKitchenMonitor() {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	if s.monitorStop != nil {
+		return
+	}
+	s.monitorStop = make(chan bool)
+
+	interval := time.Duration(s.configSvc.GetSystemConfigInt("kitchen_refresh_interval", 30)) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	go func(stop chan bool) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("📊 [REPORTS] Kitchen monitor started (interval: %v)", interval)
+		for {
+			select {
+			case <-ticker.C:
+				s.pushKitchenLiveStatus()
+			case <-stop:
+				log.Println("📊 [REPORTS] Kitchen monitor stopped")
+				return
+			}
+		}
+	}(s.monitorStop)
+}
+
+// StopKitchenMonitor stops the live kitchen status push
+func (s *ReportsService) StopKitchenMonitor() {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	if s.monitorStop == nil {
+		return
+	}
+	close(s.monitorStop)
+	s.monitorStop = nil
+}
+
+// pushKitchenLiveStatus sends the current kitchen status over WebSocket
+func (s *ReportsService) pushKitchenLiveStatus() {
+	if s.wsServer == nil || s.db == nil {
+		return
+	}
+
+	status, err := s.GetKitchenLiveStatus()
+	if err != nil {
+		log.Printf("📊 [REPORTS] Error building kitchen live status: %v", err)
+		return
+	}
+
+	s.wsServer.SendKitchenMetrics(status)
+}
+
+// buildKitchenBuckets aggregates timings per key, sorted by average time (slowest first)
+func buildKitchenBuckets(timings map[string][]float64, labels map[string]string, sla float64) []KitchenTimingBucket {
+	buckets := make([]KitchenTimingBucket, 0, len(timings))
+	for key, values := range timings {
+		label := key
+		if labels != nil && labels[key] != "" {
+			label = labels[key]
+		}
+
+		late := 0
+		for _, v := range values {
+			if v > sla {
+				late++
+			}
+		}
+
+		buckets = append(buckets, KitchenTimingBucket{
+			Key:         key,
+			Label:       label,
+			Tickets:     len(values),
+			AvgMinutes:  averageMinutes(values),
+			P90Minutes:  percentileMinutes(values, 90),
+			LateTickets: late,
+		})
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].AvgMinutes > buckets[j].AvgMinutes
+	})
+
+	return buckets
+}
+
+// averageMinutes returns the mean of the given durations
+func averageMinutes(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
+
+// percentileMinutes returns the nearest-rank percentile of the given durations
+func percentileMinutes(values []float64, percentile float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(percentile/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// Order Lifecycle Reports
+
+// OrderLifecycleReport represents order status timing and cancellations for a period
+type OrderLifecycleReport struct {
+	Period            string                  `json:"period"`
+	StartDate         time.Time               `json:"start_date"`
+	EndDate           time.Time               `json:"end_date"`
+	TotalOrders       int                     `json:"total_orders"`
+	StatusTimings     []OrderStatusTiming     `json:"status_timings"`
+	Cancellations     []OrderCancellationData `json:"cancellations"`
+	CancelledBySource map[string]int          `json:"cancelled_by_source"`
+}
+
+// OrderStatusTiming represents the time from order creation until it reached a status
+type OrderStatusTiming struct {
+	Status     string  `json:"status"`
+	Orders     int     `json:"orders"`
+	AvgMinutes float64 `json:"avg_minutes"`
+	P90Minutes float64 `json:"p90_minutes"`
+}
+
+// OrderCancellationData represents who cancelled an order and why
+type OrderCancellationData struct {
+	OrderID      uint      `json:"order_id"`
+	OrderNumber  string    `json:"order_number"`
+	FromStatus   string    `json:"from_status"`
+	Source       string    `json:"source"`
+	EmployeeName string    `json:"employee_name"`
+	Actor        string    `json:"actor"`
+	Reason       string    `json:"reason"`
+	Total        float64   `json:"total"`
+	CancelledAt  time.Time `json:"cancelled_at"`
+}
+
+// GetOrderLifecycleReport generates order status timings and cancellation details from the status history
+func (s *ReportsService) GetOrderLifecycleReport(startDate, endDate time.Time) (*OrderLifecycleReport, error) {
+	report := &OrderLifecycleReport{
+		Period:            fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
+		StartDate:         startDate,
+		EndDate:           endDate,
+		CancelledBySource: make(map[string]int),
+	}
+
+	var events []struct {
+		OrderID        uint
+		OrderNumber    string
+		OrderCreatedAt time.Time
+		Total          float64
+		FromStatus     string
+		ToStatus       string
+		Source         string
+		Actor          string
+		Reason         string
+		EmployeeName   string
+		CreatedAt      time.Time
+	}
+	err := s.db.Table("order_status_events").
+		Select(`order_status_events.order_id, orders.order_number, orders.created_at as order_created_at, orders.total,
+			order_status_events.from_status, order_status_events.to_status, order_status_events.source,
+			order_status_events.actor, order_status_events.reason, COALESCE(employees.name, '') as employee_name,
+			order_status_events.created_at`).
+		Joins("JOIN orders ON order_status_events.order_id = orders.id").
+		Joins("LEFT JOIN employees ON order_status_events.employee_id = employees.id").
+		Where("orders.created_at BETWEEN ? AND ?", startDate, endDate).
+		Order("order_status_events.created_at ASC").
+		Scan(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make(map[uint]bool)
+	reached := make(map[string]map[uint]float64)
+	for _, ev := range events {
+		orders[ev.OrderID] = true
+		if ev.FromStatus == "" {
+			continue
+		}
+
+		// First time each order reached a status
+		if reached[ev.ToStatus] == nil {
+			reached[ev.ToStatus] = make(map[uint]float64)
+		}
+		if _, seen := reached[ev.ToStatus][ev.OrderID]; !seen {
+			reached[ev.ToStatus][ev.OrderID] = ev.CreatedAt.Sub(ev.OrderCreatedAt).Minutes()
+		}
+
+		if ev.ToStatus == string(models.OrderStatusCancelled) {
+			report.CancelledBySource[ev.Source]++
+			report.Cancellations = append(report.Cancellations, OrderCancellationData{
+				OrderID:      ev.OrderID,
+				OrderNumber:  ev.OrderNumber,
+				FromStatus:   ev.FromStatus,
+				Source:       ev.Source,
+				EmployeeName: ev.EmployeeName,
+				Actor:        ev.Actor,
+				Reason:       ev.Reason,
+				Total:        ev.Total,
+				CancelledAt:  ev.CreatedAt,
+			})
+		}
+	}
+	report.TotalOrders = len(orders)
+
+	statuses := []models.OrderStatus{
+		models.OrderStatusPreparing,
+		models.OrderStatusReady,
+		models.OrderStatusDelivered,
+		models.OrderStatusPaid,
+		models.OrderStatusCancelled,
+	}
+	for _, status := range statuses {
+		var values []float64
+		for _, minutes := range reached[string(status)] {
+			values = append(values, minutes)
+		}
+		report.StatusTimings = append(report.StatusTimings, OrderStatusTiming{
+			Status:     string(status),
+			Orders:     len(values),
+			AvgMinutes: averageMinutes(values),
+			P90Minutes: percentileMinutes(values, 90),
+		})
+	}
+
+	return report, nil
+}
+
+// Tip Reports
+
+// Tip pool distribution modes
+const (
+	TipPoolModeHours = "hours"
+	TipPoolModeSales = "sales"
+)
+
+// TipPoolReport represents how the voluntary tips of a shift are split among staff
+type TipPoolReport struct {
+	CashRegisterID uint               `json:"cash_register_id"`
+	ShiftStart     time.Time          `json:"shift_start"`
+	ShiftEnd       time.Time          `json:"shift_end"`
+	Mode           string             `json:"mode"` // "hours" or "sales"
+	TotalTips      float64            `json:"total_tips"`
+	CashTips       float64            `json:"cash_tips"`
+	TipsByPayment  map[string]float64 `json:"tips_by_payment"`
+	TotalHours     float64            `json:"total_hours"`
+	TotalSales     float64            `json:"total_sales"`
+	Shares         []TipPoolShare     `json:"shares"`
+}
+
+// TipPoolShare represents the tip share of one employee
+type TipPoolShare struct {
+	EmployeeID    uint    `json:"employee_id"`
+	EmployeeName  string  `json:"employee_name"`
+	Role          string  `json:"role"`
+	HoursWorked   float64 `json:"hours_worked"`
+	Sales         float64 `json:"sales"`
+	TipsCollected float64 `json:"tips_collected"` // Tips left on this employee's own orders
+	Percentage    float64 `json:"percentage"`
+	Amount        float64 `json:"amount"`
+}
+
+// GetTipPoolDistribution splits the tips collected during a cash register shift
+// mode "hours" weights each employee by hoursWorked (employee ID -> hours)
+// mode "sales" weights each employee by the sales of the orders they served
+func (s *ReportsService) GetTipPoolDistribution(registerID uint, mode string, hoursWorked map[uint]float64) (*TipPoolReport, error) {
+	if mode != TipPoolModeHours && mode != TipPoolModeSales {
+		return nil, fmt.Errorf("invalid tip distribution mode: %s", mode)
+	}
+
+	var register models.CashRegister
+	if err := s.db.First(&register, registerID).Error; err != nil {
+		return nil, fmt.Errorf("cash register not found: %w", err)
+	}
+
+	shiftEnd := time.Now()
+	if register.ClosedAt != nil {
+		shiftEnd = *register.ClosedAt
+	}
+
+	report := &TipPoolReport{
+		CashRegisterID: registerID,
+		ShiftStart:     register.OpenedAt,
+		ShiftEnd:       shiftEnd,
+		Mode:           mode,
+		TipsByPayment:  make(map[string]float64),
+	}
+
+	// Sales of the shift attributed to the employee who served the order
+	// (falls back to the cashier when the order has no employee)
+	var rows []struct {
+		SaleID              uint
+		SaleStatus          string
+		Total               float64
+		TipAmount           float64
+		PaymentStatus       string
+		PaymentMethodName   string
+		AffectsCashRegister bool
+		EmployeeID          uint
+	}
+	err := s.db.Table("payments").
+		Select(`sales.id as sale_id, sales.status as sale_status, sales.total, payments.tip_amount,
+			payments.status as payment_status,
+			payment_methods.name as payment_method_name, payment_methods.affects_cash_register,
+			COALESCE(NULLIF(orders.employee_id, 0), sales.employee_id, 0) as employee_id`).
+		Joins("JOIN sales ON payments.sale_id = sales.id AND sales.deleted_at IS NULL").
+		Joins("JOIN payment_methods ON payments.payment_method_id = payment_methods.id").
+		Joins("LEFT JOIN orders ON sales.order_id = orders.id").
+		Where("sales.cash_register_id = ?", registerID).
+		Where("sales.created_at >= ?", register.OpenedAt).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	salesByEmployee := make(map[uint]float64)
+	tipsByEmployee := make(map[uint]float64)
+	seenSales := make(map[uint]bool)
+	for _, row := range rows {
+		// Tips follow the same rule as the cash register closing (see collectedTipsQuery)
+		if tipCollected(&models.Payment{TipAmount: row.TipAmount, Status: row.PaymentStatus}) {
+			report.TotalTips += row.TipAmount
+			tipsByEmployee[row.EmployeeID] += row.TipAmount
+			report.TipsByPayment[row.PaymentMethodName] += row.TipAmount
+			if row.AffectsCashRegister {
+				report.CashTips += row.TipAmount
+			}
+		}
+
+		// Refunded sales don't weigh in the sales mode
+		if row.SaleStatus == "refunded" || row.SaleStatus == "partial_refund" {
+			continue
+		}
+
+		// A sale with split payments appears once per payment
+		if !seenSales[row.SaleID] {
+			seenSales[row.SaleID] = true
+			salesByEmployee[row.EmployeeID] += row.Total
+		}
+	}
+
+	// Weight of each participant according to the distribution mode
+	weights := make(map[uint]float64)
+	if mode == TipPoolModeHours {
+		// Without explicit hours, use the time clock entries of the shift
+		if len(hoursWorked) == 0 {
+			hoursWorked = s.getShiftHoursWorked(register.OpenedAt, shiftEnd)
+		}
+		for employeeID, hours := range hoursWorked {
+			if hours > 0 {
+				weights[employeeID] = hours
+			}
+		}
+		if len(weights) == 0 {
+			return nil, fmt.Errorf("no hours worked found for this shift (clock in employees or provide hours)")
+		}
+	} else {
+		for employeeID, sales := range salesByEmployee {
+			if employeeID > 0 && sales > 0 {
+				weights[employeeID] = sales
+			}
+		}
+	}
+
+	employeeIDs := make([]uint, 0, len(weights))
+	totalWeight := 0.0
+	for employeeID, weight := range weights {
+		employeeIDs = append(employeeIDs, employeeID)
+		totalWeight += weight
+	}
+
+	employees := make(map[uint]models.Employee)
+	if len(employeeIDs) > 0 {
+		var list []models.Employee
+		s.db.Unscoped().Where("id IN ?", employeeIDs).Find(&list)
+		for _, employee := range list {
+			employees[employee.ID] = employee
+		}
+	}
+
+	// Shares are rounded to whole pesos; the rounding remainder goes to the largest share
+	distributed := 0.0
+	for _, employeeID := range employeeIDs {
+		employee := employees[employeeID]
+		share := TipPoolShare{
+			EmployeeID:    employeeID,
+			EmployeeName:  employee.Name,
+			Role:          employee.Role,
+			HoursWorked:   hoursWorked[employeeID],
+			Sales:         salesByEmployee[employeeID],
+			TipsCollected: tipsByEmployee[employeeID],
+		}
+		if totalWeight > 0 {
+			share.Percentage = weights[employeeID] / totalWeight * 100
+			share.Amount = math.Round(report.TotalTips * weights[employeeID] / totalWeight)
+		}
+		distributed += share.Amount
+		report.TotalHours += share.HoursWorked
+		report.TotalSales += share.Sales
+		report.Shares = append(report.Shares, share)
+	}
+
+	sort.Slice(report.Shares, func(i, j int) bool {
+		return report.Shares[i].Amount > report.Shares[j].Amount
+	})
+	if len(report.Shares) > 0 {
+		report.Shares[0].Amount += math.Round(report.TotalTips) - distributed
+	}
+
+	return report, nil
+}
+
+// getShiftHoursWorked returns the clocked hours of each employee within a time window
+func (s *ReportsService) getShiftHoursWorked(start, end time.Time) map[uint]float64 {
+	hours := make(map[uint]float64)
+
+	var entries []models.TimeClockEntry
+	s.db.Preload("Breaks").
+		Where("clock_in < ? AND (clock_out IS NULL OR clock_out > ?)", end, start).
+		Find(&entries)
+	for i := range entries {
+		for _, iv := range entryWorkIntervals(&entries[i], end) {
+			if iv.start.Before(start) {
+				iv.start = start
+			}
+			if iv.end.After(end) {
+				iv.end = end
+			}
+			if iv.end.After(iv.start) {
+				hours[entries[i].EmployeeID] += iv.end.Sub(iv.start).Hours()
+			}
+		}
+	}
+	return hours
+}
+
+// Labor Reports
+
+// LaborRules represents the Colombian labor rules used to classify worked hours
+type LaborRules struct {
+	DailyHours             int      `json:"daily_hours"`      // Ordinary hours per day before overtime
+	NightStartHour         int      `json:"night_start_hour"` // Recargo nocturno starts (e.g. 19 = 7 PM)
+	NightEndHour           int      `json:"night_end_hour"`   // Recargo nocturno ends (e.g. 6 = 6 AM)
+	NightSurchargePercent  float64  `json:"night_surcharge_percent"`
+	OvertimeDayPercent     float64  `json:"overtime_day_percent"`
+	OvertimeNightPercent   float64  `json:"overtime_night_percent"`
+	SundaySurchargePercent float64  `json:"sunday_surcharge_percent"` // Recargo dominical y festivo
+	Holidays               []string `json:"holidays"`                 // Festivos (YYYY-MM-DD)
+}
+
+// TimesheetReport represents hours worked, overtime and labor cost for a period
+type TimesheetReport struct {
+	Period             string                  `json:"period"`
+	StartDate          time.Time               `json:"start_date"`
+	EndDate            time.Time               `json:"end_date"`
+	Rules              LaborRules              `json:"rules"`
+	Employees          []TimesheetEmployeeData `json:"employees"`
+	TotalHours         float64                 `json:"total_hours"`
+	TotalOvertimeHours float64                 `json:"total_overtime_hours"`
+	TotalLaborCost     float64                 `json:"total_labor_cost"`
+	TotalSales         float64                 `json:"total_sales"`
+	LaborCostPercent   float64                 `json:"labor_cost_percent"` // Labor cost as a percentage of sales
+}
+
+// TimesheetEmployeeData represents the timesheet of a single employee
+type TimesheetEmployeeData struct {
+	EmployeeID         uint    `json:"employee_id"`
+	EmployeeName       string  `json:"employee_name"`
+	Role               string  `json:"role"`
+	HourlyRate         float64 `json:"hourly_rate"`
+	Shifts             int     `json:"shifts"`
+	OpenShifts         int     `json:"open_shifts"` // Entries without clock-out (measured until now)
+	ScheduledHours     float64 `json:"scheduled_hours"`
+	WorkedHours        float64 `json:"worked_hours"`
+	OrdinaryDayHours   float64 `json:"ordinary_day_hours"`
+	OrdinaryNightHours float64 `json:"ordinary_night_hours"` // Recargo nocturno
+	OvertimeDayHours   float64 `json:"overtime_day_hours"`
+	OvertimeNightHours float64 `json:"overtime_night_hours"`
+	SundayHolidayHours float64 `json:"sunday_holiday_hours"` // Recargo dominical/festivo (any of the above)
+	BaseCost           float64 `json:"base_cost"`
+	SurchargeCost      float64 `json:"surcharge_cost"`
+	LaborCost          float64 `json:"labor_cost"`
+}
+
+// GetLaborRules returns the labor rules configured in system config
+func (s *ReportsService) GetLaborRules() LaborRules {
+	rules := LaborRules{
+		DailyHours:             s.configSvc.GetSystemConfigInt("labor_daily_hours", 8),
+		NightStartHour:         s.configSvc.GetSystemConfigInt("labor_night_start_hour", 19),
+		NightEndHour:           s.configSvc.GetSystemConfigInt("labor_night_end_hour", 6),
+		NightSurchargePercent:  float64(s.configSvc.GetSystemConfigInt("labor_night_surcharge_percent", 35)),
+		OvertimeDayPercent:     float64(s.configSvc.GetSystemConfigInt("labor_overtime_day_percent", 25)),
+		OvertimeNightPercent:   float64(s.configSvc.GetSystemConfigInt("labor_overtime_night_percent", 75)),
+		SundaySurchargePercent: float64(s.configSvc.GetSystemConfigInt("labor_sunday_surcharge_percent", 90)),
+	}
+	if holidays, err := s.configSvc.GetSystemConfig("labor_holidays"); err == nil {
+		for _, day := range strings.Split(holidays, ",") {
+			if day = strings.TrimSpace(day); day != "" {
+				rules.Holidays = append(rules.Holidays, day)
+			}
+		}
+	}
+	return rules
+}
+
+// GetTimesheetReport generates the timesheet of all employees who clocked in during a period
+func (s *ReportsService) GetTimesheetReport(startDate, endDate time.Time) (*TimesheetReport, error) {
+	report := &TimesheetReport{
+		Period:    fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
+		StartDate: startDate,
+		EndDate:   endDate,
+		Rules:     s.GetLaborRules(),
+	}
+
+	var entries []models.TimeClockEntry
+	err := s.db.Preload("Employee", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Breaks").
+		Where("clock_in BETWEEN ? AND ?", startDate, endDate).
+		Order("clock_in ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	holidays := make(map[string]bool)
+	for _, day := range report.Rules.Holidays {
+		holidays[day] = true
+	}
+
+	now := time.Now()
+	byEmployee := make(map[uint]*TimesheetEmployeeData)
+	var order []uint
+	dailyWorked := make(map[string]float64) // employeeID|date -> ordinary hours already counted
+	for i := range entries {
+		entry := &entries[i]
+		data, exists := byEmployee[entry.EmployeeID]
+		if !exists {
+			data = &TimesheetEmployeeData{EmployeeID: entry.EmployeeID}
+			if entry.Employee != nil {
+				data.EmployeeName = entry.Employee.Name
+				data.Role = entry.Employee.Role
+				data.HourlyRate = entry.Employee.HourlyRate
+			}
+			byEmployee[entry.EmployeeID] = data
+			order = append(order, entry.EmployeeID)
+		}
+
+		data.Shifts++
+		if entry.ClockOut == nil {
+			data.OpenShifts++
+		}
+
+		// Overtime is counted per work day (the day of the clock-in)
+		dayKey := fmt.Sprintf("%d|%s", entry.EmployeeID, entry.ClockIn.Format("2006-01-02"))
+		for _, iv := range entryWorkIntervals(entry, now) {
+			addLaborHours(data, iv, report.Rules, holidays, dailyWorked, dayKey)
+		}
+	}
+
+	// Scheduled hours for comparison against worked hours
+	var shifts []models.ShiftSchedule
+	s.db.Where("start_at BETWEEN ? AND ?", startDate, endDate).Find(&shifts)
+	for _, shift := range shifts {
+		if data, exists := byEmployee[shift.EmployeeID]; exists {
+			data.ScheduledHours += shift.EndAt.Sub(shift.StartAt).Hours()
+		}
+	}
+
+	for _, employeeID := range order {
+		data := byEmployee[employeeID]
+		data.LaborCost = math.Round(data.BaseCost + data.SurchargeCost)
+		report.TotalHours += data.WorkedHours
+		report.TotalOvertimeHours += data.OvertimeDayHours + data.OvertimeNightHours
+		report.TotalLaborCost += data.LaborCost
+		report.Employees = append(report.Employees, *data)
+	}
+
+	// Labor cost as a percentage of sales (same criteria as GetSalesReport)
+	s.db.Model(&models.Sale{}).
+		Where("created_at BETWEEN ? AND ?", startDate, endDate).
+		Where("status NOT IN ?", []string{"refunded"}).
+		Select("COALESCE(SUM(total), 0)").
+		Scan(&report.TotalSales)
+	if report.TotalSales > 0 {
+		report.LaborCostPercent = report.TotalLaborCost / report.TotalSales * 100
+	}
+
+	log.Printf("📊 [REPORTS] GetTimesheetReport: %d employees, %.1f hours, labor cost %.0f (%.1f%% of sales)",
+		len(report.Employees), report.TotalHours, report.TotalLaborCost, report.LaborCostPercent)
+
+	return report, nil
+}
+
+// addLaborHours classifies a worked interval into ordinary/overtime and day/night hours,
+// adding the hours and their cost to the employee's timesheet
+// dailyWorked holds the ordinary hours already counted per dayKey (overtime starts after DailyHours)
+func addLaborHours(data *TimesheetEmployeeData, iv workInterval, rules LaborRules, holidays map[string]bool, dailyWorked map[string]float64, dayKey string) {
+	for _, seg := range splitLaborSegments(iv, rules) {
+		hours := seg.end.Sub(seg.start).Hours()
+		night := isNightHour(seg.start.Hour(), rules)
+		sunday := seg.start.Weekday() == time.Sunday || holidays[seg.start.Format("2006-01-02")]
+
+		ordinary := math.Max(0, math.Min(hours, float64(rules.DailyHours)-dailyWorked[dayKey]))
+		overtime := hours - ordinary
+		dailyWorked[dayKey] += ordinary
+
+		surcharge := 0.0
+		if night {
+			data.OrdinaryNightHours += ordinary
+			data.OvertimeNightHours += overtime
+			surcharge += ordinary*rules.NightSurchargePercent + overtime*rules.OvertimeNightPercent
+		} else {
+			data.OrdinaryDayHours += ordinary
+			data.OvertimeDayHours += overtime
+			surcharge += overtime * rules.OvertimeDayPercent
+		}
+		if sunday {
+			data.SundayHolidayHours += hours
+			surcharge += hours * rules.SundaySurchargePercent
+		}
+
+		data.WorkedHours += hours
+		data.BaseCost += hours * data.HourlyRate
+		data.SurchargeCost += surcharge / 100 * data.HourlyRate
+	}
+}
+
+// splitLaborSegments splits a worked interval at midnight and at the night start/end hours,
+// so every segment is entirely day or night time and within a single calendar day
+func splitLaborSegments(iv workInterval, rules LaborRules) []workInterval {
+	var segments []workInterval
+	cursor := iv.start
+	for cursor.Before(iv.end) {
+		next := iv.end
+		day := time.Date(cursor.Year(), cursor.Month(), cursor.Day(), 0, 0, 0, 0, cursor.Location())
+		for _, boundary := range []time.Time{
+			day.Add(time.Duration(rules.NightEndHour) * time.Hour),
+			day.Add(time.Duration(rules.NightStartHour) * time.Hour),
+			day.AddDate(0, 0, 1),
+		} {
+			if boundary.After(cursor) && boundary.Before(next) {
+				next = boundary
+			}
+		}
+		segments = append(segments, workInterval{start: cursor, end: next})
+		cursor = next
+	}
+	return segments
+}
+
+// isNightHour returns true if the hour falls within the recargo nocturno window
+func isNightHour(hour int, rules LaborRules) bool {
+	return hour >= rules.NightStartHour || hour < rules.NightEndHour
+}
+
 // CustomerStatsData represents customer statistics
 type CustomerStatsData struct {
 	TotalCustomers      int     `json:"total_customers"`
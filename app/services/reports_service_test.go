@@ -0,0 +1,119 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentileMinutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []float64
+		percentile float64
+		want       float64
+	}{
+		{"empty", nil, 90, 0},
+		{"single value", []float64{7}, 90, 7},
+		{"median of odd count", []float64{5, 1, 3}, 50, 3},
+		{"p90 of ten values", []float64{10, 1, 9, 2, 8, 3, 7, 4, 6, 5}, 90, 9},
+		{"p100 is the max", []float64{4, 12, 8}, 100, 12},
+		{"p0 is the min", []float64{4, 12, 8}, 0, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentileMinutes(tt.values, tt.percentile); got != tt.want {
+				t.Errorf("percentileMinutes(%v, %v) = %v, want %v", tt.values, tt.percentile, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercentileMinutesDoesNotReorderInput(t *testing.T) {
+	values := []float64{3, 1, 2}
+	percentileMinutes(values, 50)
+	if values[0] != 3 || values[1] != 1 || values[2] != 2 {
+		t.Errorf("input was reordered: %v", values)
+	}
+}
+
+func TestAddLaborHours(t *testing.T) {
+	rules := LaborRules{
+		DailyHours:             8,
+		NightStartHour:         19,
+		NightEndHour:           6,
+		NightSurchargePercent:  35,
+		OvertimeDayPercent:     25,
+		OvertimeNightPercent:   75,
+		SundaySurchargePercent: 90,
+	}
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, time.October, day, hour, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name      string
+		start     time.Time
+		end       time.Time
+		holidays  map[string]bool
+		want      TimesheetEmployeeData
+		surcharge float64 // Percent points of the hourly rate
+	}{
+		{
+			name:  "weekday day shift",
+			start: at(14, 10), end: at(14, 18),
+			want: TimesheetEmployeeData{WorkedHours: 8, OrdinaryDayHours: 8},
+		},
+		{
+			name:  "overtime at night",
+			start: at(14, 14), end: at(14, 23),
+			want:      TimesheetEmployeeData{WorkedHours: 9, OrdinaryDayHours: 5, OrdinaryNightHours: 3, OvertimeNightHours: 1},
+			surcharge: 3*35 + 1*75,
+		},
+		{
+			name:  "sunday morning",
+			start: at(18, 8), end: at(18, 12),
+			want:      TimesheetEmployeeData{WorkedHours: 4, OrdinaryDayHours: 4, SundayHolidayHours: 4},
+			surcharge: 4 * 90,
+		},
+		{
+			name:  "holiday night crossing midnight",
+			start: at(14, 22), end: at(15, 2),
+			holidays:  map[string]bool{"2026-10-14": true},
+			want:      TimesheetEmployeeData{WorkedHours: 4, OrdinaryNightHours: 4, SundayHolidayHours: 2},
+			surcharge: 4*35 + 2*90,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &TimesheetEmployeeData{HourlyRate: 100}
+			addLaborHours(data, workInterval{start: tt.start, end: tt.end}, rules, tt.holidays, map[string]float64{}, "1|day")
+
+			tt.want.HourlyRate = 100
+			tt.want.BaseCost = tt.want.WorkedHours * 100
+			tt.want.SurchargeCost = tt.surcharge
+			if *data != tt.want {
+				t.Errorf("got %+v\nwant %+v", *data, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitLaborSegments(t *testing.T) {
+	rules := LaborRules{NightStartHour: 19, NightEndHour: 6}
+	start := time.Date(2026, time.October, 14, 17, 0, 0, 0, time.Local)
+	end := time.Date(2026, time.October, 15, 7, 0, 0, 0, time.Local)
+
+	segments := splitLaborSegments(workInterval{start: start, end: end}, rules)
+	wantHours := []int{17, 19, 0, 6}
+	if len(segments) != len(wantHours) {
+		t.Fatalf("got %d segments, want %d", len(segments), len(wantHours))
+	}
+	for i, seg := range segments {
+		if seg.start.Hour() != wantHours[i] {
+			t.Errorf("segment %d starts at %d:00, want %d:00", i, seg.start.Hour(), wantHours[i])
+		}
+	}
+	if !segments[len(segments)-1].end.Equal(end) {
+		t.Errorf("last segment ends at %v, want %v", segments[len(segments)-1].end, end)
+	}
+}
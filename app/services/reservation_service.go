@@ -0,0 +1,942 @@
+package services
+
+import (
+	"PosApp/app/database"
+	"PosApp/app/models"
+	"PosApp/app/websocket"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReservationService handles table reservations and the walk-in waitlist
+type ReservationService struct {
+	*BaseService
+	configSvc   *ConfigService
+	wsServer    *websocket.Server
+	monitorMu   sync.Mutex
+	monitorStop chan bool
+}
+
+// NewReservationService creates a new reservation service
+func NewReservationService() *ReservationService {
+	return &ReservationService{
+		BaseService: &BaseService{db: database.GetDB()},
+		configSvc:   NewConfigService(),
+	}
+}
+
+// SetWebSocketServer sets the WebSocket server used for reservation events
+func (s *ReservationService) SetWebSocketServer(server *websocket.Server) {
+	s.wsServer = server
+}
+
+// TableSuggestion represents a table proposed for a party
+type TableSuggestion struct {
+	Table          models.Table `json:"table"`
+	ExtraSeats     int          `json:"extra_seats"`   // Capacity not used by the party
+	AreaMatch      bool         `json:"area_match"`    // Table is in the preferred area
+	AvailableNow   bool         `json:"available_now"` // Table is currently free
+	NextReservedAt *time.Time   `json:"next_reserved_at,omitempty"`
+}
+
+// Reservations
+
+// GetReservationsByDate gets all reservations for a specific day
+func (s *ReservationService) GetReservationsByDate(date time.Time) ([]models.Reservation, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	startDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endDate := startDate.Add(24 * time.Hour)
+
+	var reservations []models.Reservation
+	err := s.db.Preload("Table").
+		Preload("Area").
+		Preload("Customer").
+		Where("reserved_at >= ? AND reserved_at < ?", startDate, endDate).
+		Order("reserved_at ASC").
+		Find(&reservations).Error
+
+	return reservations, err
+}
+
+// GetUpcomingReservations gets active reservations starting within the next given hours
+func (s *ReservationService) GetUpcomingReservations(hours int) ([]models.Reservation, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var reservations []models.Reservation
+	err := s.db.Preload("Table").
+		Preload("Area").
+		Preload("Customer").
+		Where("status = ?", models.ReservationStatusConfirmed).
+		Where("reserved_at BETWEEN ? AND ?", now.Add(-time.Duration(s.noShowMinutes())*time.Minute), now.Add(time.Duration(hours)*time.Hour)).
+		Order("reserved_at ASC").
+		Find(&reservations).Error
+
+	return reservations, err
+}
+
+// GetReservation gets a reservation by ID
+func (s *ReservationService) GetReservation(id uint) (*models.Reservation, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var reservation models.Reservation
+	err := s.db.Preload("Table").
+		Preload("Area").
+		Preload("Customer").
+		Preload("Employee").
+		First(&reservation, id).Error
+
+	return &reservation, err
+}
+
+// CreateReservation creates a new reservation.
+// If no table is given, the best suggested table is assigned automatically.
+func (s *ReservationService) CreateReservation(reservation *models.Reservation) (*models.Reservation, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	if err := s.prepareReservation(reservation); err != nil {
+		return nil, err
+	}
+	reservation.Status = models.ReservationStatusConfirmed
+
+	if reservation.TableID == nil || *reservation.TableID == 0 {
+		suggestions, err := s.SuggestTables(reservation.PartySize, reservation.ReservedAt, reservation.DurationMinutes, reservation.AreaID)
+		if err != nil {
+			return nil, err
+		}
+		if len(suggestions) > 0 {
+			tableID := suggestions[0].Table.ID
+			reservation.TableID = &tableID
+		} else {
+			log.Printf("ReservationService: No free table for party of %d at %s, booking without table",
+				reservation.PartySize, reservation.ReservedAt.Format("2006-01-02 15:04"))
+			reservation.TableID = nil
+		}
+	} else if err := s.validateTableForReservation(reservation); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(reservation).Error; err != nil {
+		return nil, err
+	}
+
+	created, err := s.GetReservation(reservation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyReservation("created", created)
+
+	// The booking may already be inside the hold window
+	s.holdDueTables()
+
+	return created, nil
+}
+
+// UpdateReservation updates an existing reservation
+func (s *ReservationService) UpdateReservation(reservation *models.Reservation) (*models.Reservation, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var existing models.Reservation
+	if err := s.db.First(&existing, reservation.ID).Error; err != nil {
+		return nil, fmt.Errorf("reserva no encontrada: %w", err)
+	}
+
+	if !existing.IsActive() {
+		return nil, fmt.Errorf("no se puede modificar una reserva en estado '%s'", existing.Status)
+	}
+
+	if err := s.prepareReservation(reservation); err != nil {
+		return nil, err
+	}
+
+	// Preserve lifecycle fields
+	reservation.Status = existing.Status
+	reservation.SeatedAt = existing.SeatedAt
+	reservation.CreatedAt = existing.CreatedAt
+	reservation.TableHeldAt = existing.TableHeldAt
+
+	if reservation.TableID != nil && *reservation.TableID > 0 {
+		if err := s.validateTableForReservation(reservation); err != nil {
+			return nil, err
+		}
+	}
+
+	// Release the previously held table if the table or time changed
+	tableChanged := !sameUintPtr(existing.TableID, reservation.TableID)
+	if existing.TableHeldAt != nil && (tableChanged || !existing.ReservedAt.Equal(reservation.ReservedAt)) {
+		s.releaseHeldTable(&existing)
+		reservation.TableHeldAt = nil
+	}
+
+	if err := s.db.Save(reservation).Error; err != nil {
+		return nil, err
+	}
+
+	updated, err := s.GetReservation(reservation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyReservation("updated", updated)
+	s.holdDueTables()
+
+	return updated, nil
+}
+
+// CancelReservation cancels a reservation and releases its table
+func (s *ReservationService) CancelReservation(id uint, reason string) error {
+	return s.closeReservation(id, models.ReservationStatusCancelled, reason)
+}
+
+// MarkReservationNoShow marks a reservation as no-show and releases its table
+func (s *ReservationService) MarkReservationNoShow(id uint) error {
+	return s.closeReservation(id, models.ReservationStatusNoShow, "")
+}
+
+// SeatReservation marks the party as seated and occupies the table
+func (s *ReservationService) SeatReservation(id uint, tableID *uint) (*models.Reservation, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var reservation models.Reservation
+	if err := s.db.First(&reservation, id).Error; err != nil {
+		return nil, fmt.Errorf("reserva no encontrada: %w", err)
+	}
+	if reservation.Status != models.ReservationStatusConfirmed {
+		return nil, fmt.Errorf("no se puede sentar una reserva en estado '%s'", reservation.Status)
+	}
+
+	previous := reservation
+	if tableID != nil && *tableID > 0 {
+		reservation.TableID = tableID
+	}
+	if reservation.TableID == nil {
+		return nil, fmt.Errorf("la reserva no tiene mesa asignada")
+	}
+
+	now := time.Now()
+	moved := !sameUintPtr(previous.TableID, reservation.TableID)
+	if moved {
+		if err := s.validateTableForSeating(&reservation, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// The party is seated somewhere else: the table held for them goes back to available
+	movedFromHeldTable := previous.TableHeldAt != nil && moved
+	if movedFromHeldTable {
+		reservation.TableHeldAt = nil
+	}
+
+	reservation.Status = models.ReservationStatusSeated
+	reservation.SeatedAt = &now
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&reservation).Error; err != nil {
+			return err
+		}
+		occupy := tx.Model(&models.Table{}).Where("id = ?", *reservation.TableID)
+		if moved {
+			// Another party may have taken the new table since it was checked
+			occupy = occupy.Where("status = ? OR status = '' OR status IS NULL", "available")
+		}
+		result := occupy.Update("status", "occupied")
+		if result.Error != nil {
+			return result.Error
+		}
+		if moved && result.RowsAffected == 0 {
+			return fmt.Errorf("la mesa ya no está disponible")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if movedFromHeldTable {
+		s.releaseHeldTable(&previous)
+	}
+	if s.wsServer != nil {
+		s.wsServer.SendTableUpdate(*reservation.TableID, "occupied")
+	}
+
+	seated, err := s.GetReservation(id)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyReservation("seated", seated)
+
+	return seated, nil
+}
+
+// CompleteReservation marks a seated reservation as completed
+func (s *ReservationService) CompleteReservation(id uint) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.Reservation{}).
+		Where("id = ? AND status = ?", id, models.ReservationStatusSeated).
+		Update("status", models.ReservationStatusCompleted)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("la reserva no está sentada")
+	}
+
+	if reservation, err := s.GetReservation(id); err == nil {
+		s.notifyReservation("completed", reservation)
+	}
+	return nil
+}
+
+// GetReservationConflicts returns active reservations on the table overlapping the given window
+func (s *ReservationService) GetReservationConflicts(tableID uint, start time.Time, durationMinutes int, excludeID uint) ([]models.Reservation, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	if durationMinutes <= 0 {
+		durationMinutes = s.defaultDuration()
+	}
+	end := start.Add(time.Duration(durationMinutes) * time.Minute)
+
+	// Load candidates on the table whose start is before our end; overlap is checked with each duration
+	var candidates []models.Reservation
+	err := s.db.Where("table_id = ? AND id <> ?", tableID, excludeID).
+		Where("status IN ?", []models.ReservationStatus{models.ReservationStatusConfirmed, models.ReservationStatusSeated}).
+		Where("reserved_at < ?", end).
+		Where("reserved_at > ?", start.Add(-24*time.Hour)).
+		Order("reserved_at ASC").
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []models.Reservation
+	for _, c := range candidates {
+		if c.EndsAt().After(start) {
+			conflicts = append(conflicts, c)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// SuggestTables returns free tables that fit the party at the given time,
+// best first: preferred area, then the smallest table that fits.
+func (s *ReservationService) SuggestTables(partySize int, at time.Time, durationMinutes int, areaID *uint) ([]TableSuggestion, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	if partySize <= 0 {
+		return nil, fmt.Errorf("el tamaño del grupo debe ser mayor a cero")
+	}
+	if durationMinutes <= 0 {
+		durationMinutes = s.defaultDuration()
+	}
+
+	var tables []models.Table
+	if err := s.db.Preload("Area").
+		Where("is_active = ? AND capacity >= ?", true, partySize).
+		Find(&tables).Error; err != nil {
+		return nil, err
+	}
+
+	// Tables in use right now only matter if the booking starts before they are expected to free up
+	startsSoon := at.Before(time.Now().Add(time.Duration(s.defaultDuration()) * time.Minute))
+
+	var suggestions []TableSuggestion
+	for _, table := range tables {
+		conflicts, err := s.GetReservationConflicts(table.ID, at, durationMinutes, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) > 0 {
+			continue
+		}
+
+		availableNow := table.Status == "" || table.Status == "available"
+		if startsSoon && (table.Status == "occupied" || table.Status == "cleaning") {
+			continue
+		}
+
+		suggestion := TableSuggestion{
+			Table:        table,
+			ExtraSeats:   table.Capacity - partySize,
+			AreaMatch:    areaID != nil && table.AreaID != nil && *table.AreaID == *areaID,
+			AvailableNow: availableNow,
+		}
+
+		var next models.Reservation
+		if err := s.db.Where("table_id = ? AND status = ? AND reserved_at >= ?", table.ID, models.ReservationStatusConfirmed, at).
+			Order("reserved_at ASC").First(&next).Error; err == nil {
+			suggestion.NextReservedAt = &next.ReservedAt
+		}
+
+		suggestions = append(suggestions, suggestion)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].AreaMatch != suggestions[j].AreaMatch {
+			return suggestions[i].AreaMatch
+		}
+		if suggestions[i].ExtraSeats != suggestions[j].ExtraSeats {
+			return suggestions[i].ExtraSeats < suggestions[j].ExtraSeats
+		}
+		return suggestions[i].Table.Number < suggestions[j].Table.Number
+	})
+
+	return suggestions, nil
+}
+
+// Waitlist
+
+// GetWaitlist gets the parties currently waiting, in arrival order
+func (s *ReservationService) GetWaitlist() ([]models.WaitlistEntry, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+
+	var entries []models.WaitlistEntry
+	err := s.db.Preload("Customer").
+		Where("status IN ?", []models.WaitlistStatus{models.WaitlistStatusWaiting, models.WaitlistStatusNotified}).
+		Order("created_at ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		entries[i].Position = i + 1
+	}
+
+	return entries, nil
+}
+
+// AddToWaitlist adds a walk-in party to the waitlist with a quoted wait time
+func (s *ReservationService) AddToWaitlist(entry *models.WaitlistEntry) (*models.WaitlistEntry, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	if entry.PartySize <= 0 {
+		return nil, fmt.Errorf("el tamaño del grupo debe ser mayor a cero")
+	}
+	if err := s.fillCustomerData(entry.CustomerID, &entry.CustomerName, &entry.CustomerPhone); err != nil {
+		return nil, err
+	}
+	if entry.CustomerName == "" {
+		return nil, fmt.Errorf("el nombre del cliente es requerido")
+	}
+
+	quote, err := s.QuoteWaitTime(entry.PartySize, entry.AreaID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.ID = 0
+	entry.Status = models.WaitlistStatusWaiting
+	entry.QuotedWaitMinutes = quote
+
+	if err := s.db.Create(entry).Error; err != nil {
+		return nil, err
+	}
+
+	s.notifyWaitlist("added", entry)
+	return entry, nil
+}
+
+// QuoteWaitTime estimates the wait in minutes for a new walk-in party.
+// Parties already waiting for a fitting table go first; each busy table is expected
+// to free up once its current order reaches the default reservation duration.
+func (s *ReservationService) QuoteWaitTime(partySize int, areaID *uint) (int, error) {
+	if err := s.EnsureDB(); err != nil {
+		return 0, err
+	}
+
+	var tables []models.Table
+	query := s.db.Where("is_active = ? AND capacity >= ?", true, partySize)
+	if areaID != nil {
+		query = query.Where("area_id = ?", *areaID)
+	}
+	if err := query.Find(&tables).Error; err != nil {
+		return 0, err
+	}
+	if len(tables) == 0 {
+		return 0, fmt.Errorf("no hay mesas con capacidad para %d personas", partySize)
+	}
+
+	var ahead int64
+	s.db.Model(&models.WaitlistEntry{}).
+		Where("status IN ? AND party_size <= ?",
+			[]models.WaitlistStatus{models.WaitlistStatusWaiting, models.WaitlistStatusNotified}, maxTableCapacity(tables)).
+		Count(&ahead)
+
+	turnMinutes := float64(s.defaultDuration())
+	holdWindow := time.Now().Add(time.Duration(s.defaultDuration()) * time.Minute)
+
+	// Minutes until each fitting table is expected to be free
+	var freeIn []float64
+	for _, table := range tables {
+		var upcoming int64
+		s.db.Model(&models.Reservation{}).
+			Where("table_id = ? AND status = ? AND reserved_at < ?", table.ID, models.ReservationStatusConfirmed, holdWindow).
+			Count(&upcoming)
+		if upcoming > 0 {
+			continue
+		}
+
+		switch table.Status {
+		case "", "available":
+			freeIn = append(freeIn, 0)
+		case "cleaning":
+			freeIn = append(freeIn, 5)
+		default:
+			var order models.Order
+			remaining := turnMinutes
+			if err := s.db.Where("table_id = ? AND status NOT IN ?", table.ID,
+				[]models.OrderStatus{models.OrderStatusPaid, models.OrderStatusCancelled}).
+				Order("created_at ASC").First(&order).Error; err == nil {
+				remaining = turnMinutes - time.Since(order.CreatedAt).Minutes()
+			}
+			freeIn = append(freeIn, math.Max(5, remaining))
+		}
+	}
+
+	return quoteWaitMinutes(freeIn, int(ahead), turnMinutes), nil
+}
+
+// quoteWaitMinutes returns the wait for a party with `ahead` parties in front of it,
+// given the minutes until each fitting table is free and the expected table turn
+func quoteWaitMinutes(freeIn []float64, ahead int, turnMinutes float64) int {
+	if len(freeIn) == 0 {
+		// Every fitting table is held for reservations
+		return int(turnMinutes)
+	}
+
+	sorted := make([]float64, len(freeIn))
+	copy(sorted, freeIn)
+	sort.Float64s(sorted)
+
+	// Each table serves parties in turn: the n-th party gets table n%len after n/len turns
+	rounds := ahead / len(sorted)
+	wait := sorted[ahead%len(sorted)] + float64(rounds)*turnMinutes
+
+	// Round up to 5-minute steps as quoted to guests
+	return int(math.Ceil(wait/5) * 5)
+}
+
+// NotifyWaitlistEntry marks the party as called because a table is ready
+func (s *ReservationService) NotifyWaitlistEntry(id uint) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+
+	var entry models.WaitlistEntry
+	if err := s.db.First(&entry, id).Error; err != nil {
+		return fmt.Errorf("entrada no encontrada: %w", err)
+	}
+	if entry.Status != models.WaitlistStatusWaiting {
+		return fmt.Errorf("la entrada no está en espera")
+	}
+
+	now := time.Now()
+	entry.Status = models.WaitlistStatusNotified
+	entry.NotifiedAt = &now
+	if err := s.db.Save(&entry).Error; err != nil {
+		return err
+	}
+
+	s.notifyWaitlist("notified", &entry)
+	return nil
+}
+
+// SeatWaitlistEntry seats a waiting party at a table
+func (s *ReservationService) SeatWaitlistEntry(id uint, tableID uint) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+
+	var entry models.WaitlistEntry
+	if err := s.db.First(&entry, id).Error; err != nil {
+		return fmt.Errorf("entrada no encontrada: %w", err)
+	}
+	if entry.Status != models.WaitlistStatusWaiting && entry.Status != models.WaitlistStatusNotified {
+		return fmt.Errorf("la entrada ya no está en la lista de espera")
+	}
+
+	var table models.Table
+	if err := s.db.First(&table, tableID).Error; err != nil {
+		return fmt.Errorf("mesa no encontrada: %w", err)
+	}
+	if table.Status == "occupied" {
+		return fmt.Errorf("la mesa '%s' está ocupada", table.Number)
+	}
+	if table.Status == "reserved" {
+		return fmt.Errorf("la mesa '%s' está apartada para una reserva", table.Number)
+	}
+
+	// Walk-ins can't take a table booked before they would be done
+	now := time.Now()
+	conflicts, err := s.GetReservationConflicts(tableID, now, s.defaultDuration(), 0)
+	if err != nil {
+		return err
+	}
+	for _, c := range conflicts {
+		if c.Status == models.ReservationStatusConfirmed {
+			return fmt.Errorf("la mesa '%s' está reservada a las %s (%s)",
+				table.Number, c.ReservedAt.In(time.Local).Format("15:04"), c.CustomerName)
+		}
+	}
+
+	entry.Status = models.WaitlistStatusSeated
+	entry.SeatedAt = &now
+	entry.TableID = &tableID
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&entry).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Table{}).Where("id = ?", tableID).Update("status", "occupied").Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.wsServer != nil {
+		s.wsServer.SendTableUpdate(tableID, "occupied")
+	}
+	s.notifyWaitlist("seated", &entry)
+	return nil
+}
+
+// RemoveFromWaitlist marks a party as having left the waitlist
+func (s *ReservationService) RemoveFromWaitlist(id uint) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+
+	var entry models.WaitlistEntry
+	if err := s.db.First(&entry, id).Error; err != nil {
+		return fmt.Errorf("entrada no encontrada: %w", err)
+	}
+
+	entry.Status = models.WaitlistStatusLeft
+	if err := s.db.Save(&entry).Error; err != nil {
+		return err
+	}
+
+	s.notifyWaitlist("removed", &entry)
+	return nil
+}
+
+// Reservation monitor
+
+// StartReservationMonitor periodically holds tables for upcoming reservations
+// and releases tables of reservations that never showed up.
+func (s *ReservationService) StartReservationMonitor() {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	if s.monitorStop != nil {
+		return
+	}
+	s.monitorStop = make(chan bool)
+
+	go func(stop chan bool) {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+
+		log.Println("ReservationService: Reservation monitor started")
+		s.holdDueTables()
+		for {
+			select {
+			case <-ticker.C:
+				s.holdDueTables()
+				s.releaseNoShows()
+			case <-stop:
+				log.Println("ReservationService: Reservation monitor stopped")
+				return
+			}
+		}
+	}(s.monitorStop)
+}
+
+// StopReservationMonitor stops the reservation monitor
+func (s *ReservationService) StopReservationMonitor() {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	if s.monitorStop == nil {
+		return
+	}
+	close(s.monitorStop)
+	s.monitorStop = nil
+}
+
+// holdDueTables switches tables to "reserved" when their reservation enters the hold window
+func (s *ReservationService) holdDueTables() {
+	if s.db == nil {
+		return
+	}
+
+	holdUntil := time.Now().Add(time.Duration(s.configSvc.GetSystemConfigInt("reservation_hold_minutes", 30)) * time.Minute)
+
+	var due []models.Reservation
+	if err := s.db.Where("status = ? AND table_id IS NOT NULL AND table_held_at IS NULL AND reserved_at <= ?",
+		models.ReservationStatusConfirmed, holdUntil).
+		Find(&due).Error; err != nil {
+		log.Printf("ReservationService: Error loading due reservations: %v", err)
+		return
+	}
+
+	for i := range due {
+		reservation := &due[i]
+
+		// Only free tables can be held; busy tables are held once they are released
+		result := s.db.Model(&models.Table{}).
+			Where("id = ? AND (status = ? OR status = '' OR status IS NULL)", *reservation.TableID, "available").
+			Update("status", "reserved")
+		if result.Error != nil {
+			log.Printf("ReservationService: Error holding table %d: %v", *reservation.TableID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		now := time.Now()
+		s.db.Model(reservation).Update("table_held_at", now)
+		reservation.TableHeldAt = &now
+
+		log.Printf("ReservationService: Table %d held for reservation %d (%s)",
+			*reservation.TableID, reservation.ID, reservation.CustomerName)
+
+		if s.wsServer != nil {
+			s.wsServer.SendTableUpdate(*reservation.TableID, "reserved")
+		}
+		s.notifyReservation("table_held", reservation)
+	}
+}
+
+// releaseNoShows marks overdue reservations as no-show and frees their tables
+func (s *ReservationService) releaseNoShows() {
+	if s.db == nil {
+		return
+	}
+
+	deadline := time.Now().Add(-time.Duration(s.noShowMinutes()) * time.Minute)
+
+	var overdue []models.Reservation
+	if err := s.db.Where("status = ? AND reserved_at < ?", models.ReservationStatusConfirmed, deadline).
+		Find(&overdue).Error; err != nil {
+		log.Printf("ReservationService: Error loading overdue reservations: %v", err)
+		return
+	}
+
+	for _, reservation := range overdue {
+		if err := s.MarkReservationNoShow(reservation.ID); err != nil {
+			log.Printf("ReservationService: Error marking reservation %d as no-show: %v", reservation.ID, err)
+		}
+	}
+}
+
+// Helper methods
+
+// closeReservation moves a reservation to a final status and releases its held table
+func (s *ReservationService) closeReservation(id uint, status models.ReservationStatus, reason string) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+
+	var reservation models.Reservation
+	if err := s.db.First(&reservation, id).Error; err != nil {
+		return fmt.Errorf("reserva no encontrada: %w", err)
+	}
+	if reservation.Status != models.ReservationStatusConfirmed {
+		return fmt.Errorf("no se puede cerrar una reserva en estado '%s'", reservation.Status)
+	}
+
+	now := time.Now()
+	reservation.Status = status
+	if status == models.ReservationStatusCancelled {
+		reservation.CancelledAt = &now
+		reservation.CancelReason = reason
+	}
+
+	if err := s.db.Save(&reservation).Error; err != nil {
+		return err
+	}
+
+	if reservation.TableHeldAt != nil {
+		s.releaseHeldTable(&reservation)
+	}
+
+	s.notifyReservation(string(status), &reservation)
+	return nil
+}
+
+// releaseHeldTable sets a table held by the reservation back to available
+func (s *ReservationService) releaseHeldTable(reservation *models.Reservation) {
+	if reservation.TableID == nil {
+		return
+	}
+
+	result := s.db.Model(&models.Table{}).
+		Where("id = ? AND status = ?", *reservation.TableID, "reserved").
+		Update("status", "available")
+	if result.Error != nil {
+		log.Printf("ReservationService: Error releasing table %d: %v", *reservation.TableID, result.Error)
+		return
+	}
+
+	s.db.Model(reservation).Update("table_held_at", nil)
+
+	if result.RowsAffected > 0 && s.wsServer != nil {
+		s.wsServer.SendTableUpdate(*reservation.TableID, "available")
+	}
+}
+
+// prepareReservation validates input and fills defaults for create/update
+func (s *ReservationService) prepareReservation(reservation *models.Reservation) error {
+	if reservation.PartySize <= 0 {
+		return fmt.Errorf("el tamaño del grupo debe ser mayor a cero")
+	}
+	if reservation.ReservedAt.IsZero() {
+		return fmt.Errorf("la fecha de la reserva es requerida")
+	}
+	if reservation.DurationMinutes <= 0 {
+		reservation.DurationMinutes = s.defaultDuration()
+	}
+	if reservation.TableID != nil && *reservation.TableID == 0 {
+		reservation.TableID = nil
+	}
+	if err := s.fillCustomerData(reservation.CustomerID, &reservation.CustomerName, &reservation.CustomerPhone); err != nil {
+		return err
+	}
+	if reservation.CustomerName == "" {
+		return fmt.Errorf("el nombre del cliente es requerido")
+	}
+	return nil
+}
+
+// validateTableForReservation checks capacity and overlapping bookings on the chosen table
+func (s *ReservationService) validateTableForReservation(reservation *models.Reservation) error {
+	var table models.Table
+	if err := s.db.First(&table, *reservation.TableID).Error; err != nil {
+		return fmt.Errorf("mesa no encontrada: %w", err)
+	}
+	if !table.IsActive {
+		return fmt.Errorf("la mesa '%s' no está activa", table.Number)
+	}
+	if table.Capacity > 0 && table.Capacity < reservation.PartySize {
+		return fmt.Errorf("la mesa '%s' tiene capacidad para %d personas", table.Number, table.Capacity)
+	}
+
+	conflicts, err := s.GetReservationConflicts(table.ID, reservation.ReservedAt, reservation.DurationMinutes, reservation.ID)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("la mesa '%s' ya está reservada a las %s (%s)",
+			table.Number, conflicts[0].ReservedAt.In(time.Local).Format("15:04"), conflicts[0].CustomerName)
+	}
+	return nil
+}
+
+// validateTableForSeating checks a table the party is moved to when seated: it must
+// be free now and fit the party until the end of the reservation
+func (s *ReservationService) validateTableForSeating(reservation *models.Reservation, at time.Time) error {
+	var table models.Table
+	if err := s.db.First(&table, *reservation.TableID).Error; err != nil {
+		return fmt.Errorf("mesa no encontrada: %w", err)
+	}
+	if table.Status == "occupied" {
+		return fmt.Errorf("la mesa '%s' está ocupada", table.Number)
+	}
+	if table.Status == "reserved" {
+		return fmt.Errorf("la mesa '%s' está apartada para una reserva", table.Number)
+	}
+
+	seating := *reservation
+	seating.ReservedAt = at
+	return s.validateTableForReservation(&seating)
+}
+
+// fillCustomerData copies name and phone from the linked customer when not provided
+func (s *ReservationService) fillCustomerData(customerID *uint, name, phone *string) error {
+	if customerID == nil || *customerID == 0 {
+		return nil
+	}
+
+	var customer models.Customer
+	if err := s.db.First(&customer, *customerID).Error; err != nil {
+		return fmt.Errorf("cliente no encontrado: %w", err)
+	}
+	if *name == "" {
+		*name = customer.Name
+	}
+	if *phone == "" {
+		*phone = customer.Phone
+	}
+	return nil
+}
+
+func (s *ReservationService) defaultDuration() int {
+	return s.configSvc.GetSystemConfigInt("reservation_default_duration", 90)
+}
+
+func (s *ReservationService) noShowMinutes() int {
+	return s.configSvc.GetSystemConfigInt("reservation_no_show_minutes", 20)
+}
+
+// notifyReservation broadcasts a reservation event to POS and waiter apps
+func (s *ReservationService) notifyReservation(event string, reservation *models.Reservation) {
+	if s.wsServer == nil {
+		return
+	}
+	s.wsServer.SendReservationEvent(websocket.TypeReservationUpdate, event, reservation)
+}
+
+// notifyWaitlist broadcasts a waitlist event to POS and waiter apps
+func (s *ReservationService) notifyWaitlist(event string, entry *models.WaitlistEntry) {
+	if s.wsServer == nil {
+		return
+	}
+	s.wsServer.SendReservationEvent(websocket.TypeWaitlistUpdate, event, entry)
+}
+
+func maxTableCapacity(tables []models.Table) int {
+	max := 0
+	for _, t := range tables {
+		if t.Capacity > max {
+			max = t.Capacity
+		}
+	}
+	return max
+}
+
+func sameUintPtr(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,27 @@
+package services
+
+import "testing"
+
+func TestQuoteWaitMinutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		freeIn []float64
+		ahead  int
+		turn   float64
+		want   int
+	}{
+		{"all tables held", nil, 0, 90, 90},
+		{"free table and nobody ahead", []float64{0, 30}, 0, 90, 0},
+		{"second party takes the next table", []float64{30, 0}, 1, 90, 30},
+		{"rounds up to five minutes", []float64{12}, 0, 90, 15},
+		{"waits a full turn when every table is taken", []float64{0, 10}, 2, 90, 90},
+		{"two turns on a single table", []float64{5}, 2, 60, 125},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteWaitMinutes(tt.freeIn, tt.ahead, tt.turn); got != tt.want {
+				t.Errorf("quoteWaitMinutes(%v, %d, %v) = %d, want %d", tt.freeIn, tt.ahead, tt.turn, got, tt.want)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -111,11 +112,24 @@ func (s *SalesService) ProcessSale(orderID uint, paymentData []PaymentData, cust
 		}
 	}
 
+	// CONSUMIDOR FINAL sales are issued as POS equivalent documents when configured
+	issuePOSDocument := !needsElectronicInvoice &&
+		(sale.Customer == nil || sale.Customer.IdentificationNumber == "222222222222") &&
+		s.invoiceSvc.isPOSDocumentEnabled()
+	if issuePOSDocument {
+		sale.InvoiceType = "pos_equivalent"
+	}
+
 	totalPaymentAmount := 0.0
+	totalTip := 0.0
+	paymentMethods := make(map[uint]*models.PaymentMethod)
 	for _, payment := range paymentData {
 		if payment.Amount <= 0 {
 			return nil, fmt.Errorf("payment amount must be greater than 0")
 		}
+		if payment.TipAmount < 0 {
+			return nil, fmt.Errorf("tip amount cannot be negative")
+		}
 
 		var paymentMethod models.PaymentMethod
 		if err := s.db.First(&paymentMethod, payment.PaymentMethodID).Error; err != nil {
@@ -124,10 +138,16 @@ func (s *SalesService) ProcessSale(orderID uint, paymentData []PaymentData, cust
 		if !paymentMethod.IsActive {
 			return nil, fmt.Errorf("payment method '%s' is not active", paymentMethod.Name)
 		}
+		paymentMethods[paymentMethod.ID] = &paymentMethod
 
 		totalPaymentAmount += payment.Amount
+		totalTip += payment.TipAmount
 	}
 
+	// Tips are voluntary and charged on top of the sale: they are not part of
+	// sale.Total, so they never reach the DIAN invoice lines or totals
+	sale.Tip = totalTip
+
 	roundedPaymentTotal := math.Round(totalPaymentAmount)
 	roundedSaleTotal := math.Round(sale.Total)
 	difference := math.Abs(roundedPaymentTotal - roundedSaleTotal)
@@ -161,20 +181,50 @@ func (s *SalesService) ProcessSale(orderID uint, paymentData []PaymentData, cust
 				SaleID:          sale.ID,
 				PaymentMethodID: payment.PaymentMethodID,
 				Amount:          payment.Amount,
+				TipAmount:       payment.TipAmount,
 				Reference:       payment.Reference,
 				VoucherImage:    payment.VoucherImage,
+				Status:          models.PaymentStatusCompleted,
+			}
+			gateway := s.paymentGateway(payment.GatewayID, paymentMethods[payment.PaymentMethodID])
+			if gateway != nil && gateway.ID() == MockGatewayID && !gateway.Enabled() {
+				return errMockGatewayDisabled
+			}
+			if gateway != nil {
+				p.GatewayID = gateway.ID()
+				p.GatewayTransactionID = payment.GatewayTransactionID
+				if p.GatewayTransactionID == "" {
+					p.GatewayTransactionID = gateway.TransactionIDFromReference(payment.Reference)
+				}
 			}
 			if err := tx.Create(&p).Error; err != nil {
 				return fmt.Errorf("failed to create payment: %w", err)
 			}
+			if gateway != nil && p.GatewayTransactionID != "" {
+				if err := gateway.LinkPaymentToSale(tx, p.GatewayTransactionID, sale.ID, p.ID); err != nil {
+					log.Printf("Warning: Failed to link %s payment %s to sale %s: %v", p.GatewayID, p.GatewayTransactionID, sale.SaleNumber, err)
+				}
+			}
 		}
 
 		order.Status = models.OrderStatusPaid
 		order.SaleID = &sale.ID
-		if err := tx.Save(order).Error; err != nil {
+		if err := tx.Omit("StatusHistory").Save(order).Error; err != nil {
 			return fmt.Errorf("failed to update order: %w", err)
 		}
 
+		var cashierID *uint
+		if employeeID > 0 {
+			cashierID = &employeeID
+		}
+		if err := RecordOrderStatusEvent(tx, orderID, lockedOrder.Status, models.OrderStatusPaid, models.OrderStatusChange{
+			Source:     models.OrderStatusSourcePOS,
+			EmployeeID: cashierID,
+			Reason:     fmt.Sprintf("Sale %s", sale.SaleNumber),
+		}); err != nil {
+			return err
+		}
+
 		if order.TableID != nil {
 			if err := tx.Model(&models.Table{}).
 				Where("id = ?", *order.TableID).
@@ -196,6 +246,10 @@ func (s *SalesService) ProcessSale(orderID uint, paymentData []PaymentData, cust
 		}
 	}
 
+	// Invoices can be emailed from our own SMTP server (with PDF and XML) instead of by the DIAN provider
+	emailViaSMTP := sendEmailToCustomer && mailSendsInvoices(s.db)
+	providerSendsEmail := sendEmailToCustomer && !emailViaSMTP
+
 	if needsElectronicInvoice {
 		go func() {
 			// Recover from any panics to prevent crashing the application
@@ -207,7 +261,7 @@ func (s *SalesService) ProcessSale(orderID uint, paymentData []PaymentData, cust
 			}()
 
 			fmt.Printf("🧾 Sending electronic invoice for sale #%s...\n", sale.SaleNumber)
-			invoice, err := s.invoiceSvc.SendInvoice(sale, sendEmailToCustomer)
+			invoice, err := s.invoiceSvc.SendInvoice(sale, providerSendsEmail)
 			if err != nil {
 				// Log error, invoice will be queued for retry
 				fmt.Printf("❌ Failed to send electronic invoice for sale #%s: %v\n", sale.SaleNumber, err)
@@ -223,6 +277,11 @@ func (s *SalesService) ProcessSale(orderID uint, paymentData []PaymentData, cust
 
 				fmt.Printf("✅ Electronic invoice created for sale #%s (Status: %s)\n", sale.SaleNumber, invoice.Status)
 
+				// The email is queued once DIAN accepts the invoice: now, or when its validation finishes
+				if emailViaSMTP {
+					markInvoiceEmailPending(s.db, invoice.ID)
+				}
+
 				// Print electronic invoice after successful creation ONLY if printReceipt is true
 				if printReceipt {
 					if err := s.printerSvc.PrintReceipt(sale, true); err != nil {
@@ -235,6 +294,45 @@ func (s *SalesService) ProcessSale(orderID uint, paymentData []PaymentData, cust
 				}
 			}
 		}()
+	} else if issuePOSDocument {
+		go func() {
+			// Recover from any panics to prevent crashing the application
+			defer func() {
+				if r := recover(); r != nil {
+					fmt.Printf("❌ PANIC recovered in POS document goroutine for sale #%s: %v\n", sale.SaleNumber, r)
+					fmt.Printf("⚠️  Please check logs and report this error.\n")
+				}
+			}()
+
+			fmt.Printf("🧾 Sending POS equivalent document for sale #%s...\n", sale.SaleNumber)
+			document, err := s.invoiceSvc.SendPOSDocument(sale, providerSendsEmail)
+			if err != nil {
+				fmt.Printf("❌ Failed to send POS equivalent document for sale #%s: %v\n", sale.SaleNumber, err)
+			} else {
+				sale.ElectronicInvoice = document
+				if err := s.db.Save(sale).Error; err != nil {
+					fmt.Printf("Error saving POS equivalent document to sale: %v\n", err)
+				}
+				fmt.Printf("✅ POS equivalent document created for sale #%s (Status: %s)\n", sale.SaleNumber, document.Status)
+
+				if emailViaSMTP {
+					markInvoiceEmailPending(s.db, document.ID)
+				}
+			}
+
+			if !printReceipt {
+				fmt.Printf("🖨️  POS document printing skipped (user disabled printReceipt)\n")
+				return
+			}
+
+			// The customer always gets a receipt: the POS document when it was issued,
+			// otherwise a simple receipt that can be reprinted once the document is sent
+			if err := s.printerSvc.PrintReceipt(sale, err == nil); err != nil {
+				fmt.Printf("Failed to print POS receipt for sale #%s: %v\n", sale.SaleNumber, err)
+			} else {
+				fmt.Printf("🖨️  POS receipt printed for sale #%s\n", sale.SaleNumber)
+			}
+		}()
 	} else {
 		// Print simple receipt asynchronously ONLY if printReceipt is true
 		if printReceipt {
@@ -267,10 +365,13 @@ func (s *SalesService) ProcessSale(orderID uint, paymentData []PaymentData, cust
 
 // PaymentData represents payment information
 type PaymentData struct {
-	PaymentMethodID uint    `json:"payment_method_id"`
-	Amount          float64 `json:"amount"`
-	Reference       string  `json:"reference"`
-	VoucherImage    string  `json:"voucher_image,omitempty"` // Base64 encoded voucher image
+	PaymentMethodID      uint    `json:"payment_method_id"`
+	Amount               float64 `json:"amount"`
+	TipAmount            float64 `json:"tip_amount,omitempty"` // Voluntary tip on top of Amount (propina)
+	Reference            string  `json:"reference"`
+	VoucherImage         string  `json:"voucher_image,omitempty"`          // Base64 encoded voucher image
+	GatewayID            string  `json:"gateway_id,omitempty"`             // Overrides the gateway of the payment method
+	GatewayTransactionID string  `json:"gateway_transaction_id,omitempty"` // Defaults to the ID found in Reference
 }
 
 // QuickSaleItem represents an item in a quick sale
@@ -422,6 +523,9 @@ func (s *SalesService) GetConsumidorFinal() (*models.Customer, error) {
 }
 
 // RefundSale processes a refund for a sale
+// The refund and the gateway voids it needs are saved before calling the gateways, and the sale,
+// inventory and cash drawer only change once every void was accepted. A refund interrupted by a
+// gateway error stays pending and the next RefundSale call for the sale resumes it.
 func (s *SalesService) RefundSale(saleID uint, amount float64, reason string, employeeID uint) error {
 	var sale models.Sale
 	if err := s.db.First(&sale, saleID).Error; err != nil {
@@ -432,51 +536,319 @@ func (s *SalesService) RefundSale(saleID uint, amount float64, reason string, em
 		return fmt.Errorf("sale already refunded")
 	}
 
+	refund, err := s.pendingRefund(&sale, amount, reason, employeeID)
+	if err != nil {
+		return err
+	}
+
+	// Card payments made through a payment gateway are returned to the card by the gateway,
+	// so they don't come out of the cash drawer
+	if err := s.requestRefundVoids(&sale, refund); err != nil {
+		return err
+	}
+
+	return s.completeRefund(refund)
+}
+
+// pendingRefund returns the pending refund of a sale, or saves a new one with the gateway voids to request
+func (s *SalesService) pendingRefund(sale *models.Sale, amount float64, reason string, employeeID uint) (*models.SaleRefund, error) {
+	var refund models.SaleRefund
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the sale so two refunds of the same sale can't be planned at once
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(sale, sale.ID).Error; err != nil {
+			return fmt.Errorf("sale not found: %w", err)
+		}
+		if sale.Status == "refunded" {
+			return fmt.Errorf("sale already refunded")
+		}
+
+		err := tx.Preload("Voids").Where("sale_id = ? AND status = ?", sale.ID, "pending").First(&refund).Error
+		if err == nil {
+			if math.Abs(refund.Amount-amount) > 0.01 {
+				return fmt.Errorf("la venta tiene una devolución pendiente de %.2f, reintente con ese monto", refund.Amount)
+			}
+			return nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		voids, gatewayAmount := s.planGatewayVoids(tx, sale, amount)
+		refund = models.SaleRefund{
+			SaleID:     sale.ID,
+			Amount:     amount,
+			CashAmount: math.Max(0, amount-gatewayAmount),
+			Reason:     reason,
+			EmployeeID: employeeID,
+			Status:     "pending",
+			Voids:      voids,
+		}
+		if err := tx.Create(&refund).Error; err != nil {
+			return fmt.Errorf("error saving refund: %w", err)
+		}
+
+		// Payments being voided can't be picked by another refund
+		for _, void := range refund.Voids {
+			if err := takePaymentForVoid(tx, &void); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// planGatewayVoids returns the gateway voids needed for a refund, up to the refund amount,
+// and the part of the amount they return
+func (s *SalesService) planGatewayVoids(tx *gorm.DB, sale *models.Sale, amount float64) ([]models.SaleRefundVoid, float64) {
+	var payments []models.Payment
+	tx.Preload("PaymentMethod").
+		Where("sale_id = ? AND gateway_transaction_id <> '' AND status IN ?", sale.ID,
+			[]string{models.PaymentStatusCompleted, models.PaymentStatusPartlyVoided, models.PaymentStatusVoidRejected}).
+		Find(&payments)
+
+	fullRefund := amount >= sale.Total
+	remaining := amount
+	planned := 0.0
+	var voids []models.SaleRefundVoid
+	for _, payment := range payments {
+		if remaining <= 0 {
+			break
+		}
+		available := payment.Amount - payment.VoidAmount
+		if available <= 0 {
+			continue
+		}
+
+		gateway := s.paymentGateway(payment.GatewayID, payment.PaymentMethod)
+		if gateway == nil || !gateway.Enabled() {
+			log.Printf("Warning: Sale %s has %s payments but the gateway is not enabled, refund must be done from the gateway dashboard", sale.SaleNumber, payment.GatewayID)
+			continue
+		}
+
+		saleAmount := math.Min(remaining, available)
+		voidAmount := saleAmount
+		// A full refund also returns the tip charged on the card
+		if fullRefund && payment.VoidAmount == 0 {
+			voidAmount = payment.Amount + payment.TipAmount
+		}
+
+		voids = append(voids, models.SaleRefundVoid{
+			PaymentID:     payment.ID,
+			GatewayID:     payment.GatewayID,
+			TransactionID: payment.GatewayTransactionID,
+			Amount:        voidAmount,
+			SaleAmount:    saleAmount,
+			PaymentStatus: payment.Status,
+			Status:        models.RefundVoidPending,
+		})
+		planned += saleAmount
+		remaining -= saleAmount
+	}
+	return voids, planned
+}
+
+// takePaymentForVoid marks a payment as waiting for a refund void
+func takePaymentForVoid(tx *gorm.DB, void *models.SaleRefundVoid) error {
+	return tx.Model(&models.Payment{}).Where("id = ?", void.PaymentID).Updates(map[string]interface{}{
+		"status":      models.PaymentStatusVoidPending,
+		"void_amount": gorm.Expr("void_amount + ?", void.SaleAmount),
+	}).Error
+}
+
+// requestRefundVoids sends the voids of a refund that no gateway has accepted yet
+// A failed call is compensated by giving the payment back its previous status, so the refund
+// never counts money the gateway didn't take back
+func (s *SalesService) requestRefundVoids(sale *models.Sale, refund *models.SaleRefund) error {
+	var cashRegisterID uint
+	if sale.CashRegisterID != nil {
+		cashRegisterID = *sale.CashRegisterID
+	}
+
+	var failures []string
+	for i := range refund.Voids {
+		void := &refund.Voids[i]
+		if void.Status != models.RefundVoidPending && void.Status != models.RefundVoidFailed {
+			continue
+		}
+		if void.Status == models.RefundVoidFailed {
+			if err := takePaymentForVoid(s.db, void); err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+		}
+
+		if err := s.requestRefundVoid(void, refund, cashRegisterID); err != nil {
+			failures = append(failures, fmt.Sprintf("%s %s: %v", void.GatewayID, void.TransactionID, err))
+			s.compensateRefundVoid(void, err.Error())
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	message := strings.Join(failures, "; ")
+	accepted := false
+	for _, void := range refund.Voids {
+		if void.Status == models.RefundVoidRequested || void.Status == models.RefundVoidVoided {
+			accepted = true
+		}
+	}
+	if !accepted {
+		// Nothing reached the gateways, the refund can start over
+		s.db.Model(refund).Updates(map[string]interface{}{"status": "failed", "error_message": message})
+		return fmt.Errorf("failed to void gateway payments: %s", message)
+	}
+	s.db.Model(refund).Update("error_message", message)
+	return fmt.Errorf("failed to void gateway payments: %s (la devolución quedó pendiente, reintente para completarla)", message)
+}
+
+// requestRefundVoid sends one void to its gateway and records that the gateway accepted it
+func (s *SalesService) requestRefundVoid(void *models.SaleRefundVoid, refund *models.SaleRefund, cashRegisterID uint) error {
+	var payment models.Payment
+	if err := s.db.Preload("PaymentMethod").First(&payment, void.PaymentID).Error; err != nil {
+		return fmt.Errorf("payment not found: %w", err)
+	}
+	gateway := s.paymentGateway(void.GatewayID, payment.PaymentMethod)
+	if gateway == nil || !gateway.Enabled() {
+		return fmt.Errorf("payment gateway is not enabled")
+	}
+
+	result, err := gateway.VoidPayment(GatewayVoidRequest{
+		TransactionID:  void.TransactionID,
+		Amount:         void.Amount,
+		Reason:         refund.Reason,
+		EmployeeID:     refund.EmployeeID,
+		CashRegisterID: cashRegisterID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Gateways that confirm later (webhook) update the payment themselves
+	void.Status = models.RefundVoidRequested
+	if result.Status == GatewayStatusVoided {
+		void.Status = models.RefundVoidVoided
+		status := models.PaymentStatusPartlyVoided
+		if payment.VoidAmount >= payment.Amount {
+			status = models.PaymentStatusVoided
+		}
+		s.db.Model(&models.Payment{}).Where("id = ?", payment.ID).
+			Updates(map[string]interface{}{"status": status, "voided_at": time.Now()})
+	}
+	void.ErrorMessage = ""
+	return s.db.Model(void).Updates(map[string]interface{}{"status": void.Status, "error_message": ""}).Error
+}
+
+// compensateRefundVoid undoes a void the gateway didn't accept
+func (s *SalesService) compensateRefundVoid(void *models.SaleRefundVoid, message string) {
+	void.Status = models.RefundVoidFailed
+	void.ErrorMessage = message
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(void).Updates(map[string]interface{}{"status": void.Status, "error_message": message}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Payment{}).Where("id = ?", void.PaymentID).Updates(map[string]interface{}{
+			"status":      void.PaymentStatus,
+			"void_amount": gorm.Expr("void_amount - ?", void.SaleAmount),
+		}).Error
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to restore payment %d after a failed void: %v", void.PaymentID, err)
+	}
+}
+
+// completeRefund applies a refund whose voids were all accepted: sale status, inventory and cash drawer
+func (s *SalesService) completeRefund(refund *models.SaleRefund) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
+		var sale models.Sale
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&sale, refund.SaleID).Error; err != nil {
+			return fmt.Errorf("sale not found: %w", err)
+		}
+		if sale.Status == "refunded" {
+			return fmt.Errorf("sale already refunded")
+		}
+
+		// A gateway may have rejected a void before we got here
+		var rejected int64
+		tx.Model(&models.SaleRefundVoid{}).Where("refund_id = ? AND status = ?", refund.ID, models.RefundVoidRejected).Count(&rejected)
+		if rejected > 0 {
+			tx.Model(refund).Update("status", "void_rejected")
+			return fmt.Errorf("la pasarela rechazó la anulación, la devolución no se aplicó")
+		}
+
+		// Inventory only goes back on the first refund of the sale
+		firstRefund := sale.Status == "completed"
+
 		// Update sale status
-		if amount >= sale.Total {
+		if refund.Amount >= sale.Total {
 			sale.Status = "refunded"
 		} else {
 			sale.Status = "partial_refund"
 		}
-		sale.Notes = fmt.Sprintf("Refund: %s", reason)
+		sale.Notes = fmt.Sprintf("Refund: %s", refund.Reason)
 
 		if err := tx.Save(&sale).Error; err != nil {
 			return err
 		}
 
-		// Return inventory
-		var order models.Order
-		if err := tx.Preload("Items").First(&order, sale.OrderID).Error; err != nil {
-			return err
-		}
+		if firstRefund {
+			// Return inventory
+			var order models.Order
+			if err := tx.Preload("Items").First(&order, sale.OrderID).Error; err != nil {
+				return err
+			}
 
-		// CRITICAL FIX: Restore product inventory within transaction
-		for _, item := range order.Items {
-			if err := s.productSvc.AdjustStockInTransaction(tx, item.ProductID, item.Quantity,
-				fmt.Sprintf("Refund - Sale %s", sale.SaleNumber), employeeID); err != nil {
-				log.Printf("Warning: Failed to adjust stock for product %d: %v", item.ProductID, err)
-				// Continue even if stock adjustment fails
+			// CRITICAL FIX: Restore product inventory within transaction
+			for _, item := range order.Items {
+				if err := s.productSvc.AdjustStockInTransaction(tx, item.ProductID, item.Quantity,
+					fmt.Sprintf("Refund - Sale %s", sale.SaleNumber), refund.EmployeeID); err != nil {
+					log.Printf("Warning: Failed to adjust stock for product %d: %v", item.ProductID, err)
+					// Continue even if stock adjustment fails
+				}
 			}
-		}
 
-		// CRITICAL FIX: Restore ingredient stocks
-		// Previously this was missing, causing ingredient stock inconsistency
-		if err := s.ingredientSvc.RestoreIngredientsInTransaction(tx, order.Items); err != nil {
-			log.Printf("Warning: Failed to restore ingredients for refunded sale %s: %v", sale.SaleNumber, err)
-			// Continue despite error - don't fail the refund
+			// CRITICAL FIX: Restore ingredient stocks
+			// Previously this was missing, causing ingredient stock inconsistency
+			if err := s.ingredientSvc.RestoreIngredientsInTransaction(tx, order.Items); err != nil {
+				log.Printf("Warning: Failed to restore ingredients for refunded sale %s: %v", sale.SaleNumber, err)
+				// Continue despite error - don't fail the refund
+			}
 		}
 
 		// Record cash movement (negative)
-		if sale.CashRegisterID != nil && *sale.CashRegisterID > 0 {
-			s.recordCashMovement(tx, *sale.CashRegisterID, -amount, "refund",
-				sale.SaleNumber, employeeID)
+		if sale.CashRegisterID != nil && *sale.CashRegisterID > 0 && refund.CashAmount > 0 {
+			s.recordCashMovement(tx, *sale.CashRegisterID, -refund.CashAmount, "refund",
+				sale.SaleNumber, refund.EmployeeID)
 		}
 
-		return nil
+		return tx.Model(refund).Updates(map[string]interface{}{"status": "completed", "error_message": ""}).Error
 	})
 }
 
+// paymentGateway returns the gateway of a payment (explicit ID, else the payment method's gateway)
+func (s *SalesService) paymentGateway(gatewayID string, method *models.PaymentMethod) PaymentGateway {
+	var gateway PaymentGateway
+	var err error
+	if gatewayID != "" {
+		config := ""
+		if method != nil && paymentMethodGatewayID(method) == gatewayID {
+			config = method.GatewayConfig
+		}
+		gateway, err = NewPaymentGateway(s.db, gatewayID, config)
+	} else {
+		gateway, err = PaymentGatewayForMethod(s.db, method)
+	}
+	if err != nil {
+		log.Printf("Warning: Payment gateway unavailable: %v", err)
+		return nil
+	}
+	return gateway
+}
+
 // DeleteSale deletes a sale and all related data (cascade)
 func (s *SalesService) DeleteSale(saleID uint, employeeID uint) error {
 	var sale models.Sale
@@ -763,6 +1135,14 @@ func (s *SalesService) ResendElectronicInvoice(saleID uint) error {
 		return fmt.Errorf("sale already has a valid electronic invoice")
 	}
 
+	// Contingency invoices keep their number: report the stored request instead of issuing a new one
+	if sale.ElectronicInvoice != nil && (sale.ElectronicInvoice.Status == contingencyStatusPending || sale.ElectronicInvoice.Status == contingencyStatusReporting) {
+		if err := s.invoiceSvc.ReportContingencyInvoice(sale.ElectronicInvoice); err != nil {
+			return fmt.Errorf("failed to report contingency invoice: %w", err)
+		}
+		return nil
+	}
+
 	// Delete existing failed electronic invoice record to avoid duplicates
 	// This also ensures a fresh consecutive number will be used
 	if sale.ElectronicInvoice != nil && sale.ElectronicInvoice.ID > 0 {
@@ -827,7 +1207,7 @@ func (s *SalesService) UpdateSaleCustomer(saleID uint, customerID uint) error {
 	// Validate that electronic invoice is not processed
 	if sale.ElectronicInvoice != nil {
 		status := sale.ElectronicInvoice.Status
-		if status == "sent" || status == "validating" || status == "accepted" {
+		if status == "sent" || status == "validating" || status == "accepted" || status == contingencyStatusPending || status == contingencyStatusReporting {
 			return fmt.Errorf("cannot update customer: electronic invoice has already been processed")
 		}
 	}
@@ -1186,6 +1566,9 @@ func (s *SalesService) GetAllPaymentMethods() ([]models.PaymentMethod, error) {
 
 // CreatePaymentMethod creates a new payment method
 func (s *SalesService) CreatePaymentMethod(method *models.PaymentMethod) error {
+	if err := validatePaymentMethodGateway(s.db, method); err != nil {
+		return err
+	}
 	return s.db.Create(method).Error
 }
 
@@ -1195,6 +1578,9 @@ func (s *SalesService) UpdatePaymentMethod(method *models.PaymentMethod) error {
 	fmt.Printf("   ID=%d, Name=%s\n", method.ID, method.Name)
 	fmt.Printf("   UseBoldTerminal=%v, BoldPaymentMethod=%s\n", method.UseBoldTerminal, method.BoldPaymentMethod)
 
+	if err := validatePaymentMethodGateway(s.db, method); err != nil {
+		return err
+	}
 	err := s.db.Save(method).Error
 	if err != nil {
 		fmt.Printf("   ❌ Error al guardar: %v\n", err)
@@ -1307,7 +1693,8 @@ func (s *SalesService) PrintReceiptWithPrinter(saleID uint, printerID uint) erro
 
 	// Determine if it's an electronic invoice based on the flag, not just presence of invoice object
 	// This ensures we print the electronic format when requested, even if invoice is still processing
-	isElectronicInvoice := sale.NeedsElectronicInvoice && sale.ElectronicInvoice != nil
+	// POS equivalent documents print with the electronic format too
+	isElectronicInvoice := (sale.NeedsElectronicInvoice || sale.InvoiceType == "pos_equivalent") && sale.ElectronicInvoice != nil
 
 	// If electronic invoice was requested but not yet created, return error
 	if sale.NeedsElectronicInvoice && sale.ElectronicInvoice == nil {
@@ -1402,6 +1789,7 @@ type TaxBreakdownDetail struct {
 
 // NoteDetail represents credit/debit note detail
 type NoteDetail struct {
+	ID        uint      `json:"id"`
 	Number    string    `json:"number"`
 	Prefix    string    `json:"prefix"`
 	Reason    string    `json:"reason"`
@@ -1702,6 +2090,7 @@ func (s *SalesService) GetDIANClosingReportWithPeriod(dateStr string, period str
 					cn.CreatedAt.Month() == reportDate.Month() &&
 					cn.CreatedAt.Day() == reportDate.Day() {
 					report.CreditNotes = append(report.CreditNotes, NoteDetail{
+						ID:        cn.ID,
 						Number:    cn.Number,
 						Prefix:    cn.Prefix,
 						Reason:    cn.Reason,
@@ -1718,6 +2107,7 @@ func (s *SalesService) GetDIANClosingReportWithPeriod(dateStr string, period str
 					dn.CreatedAt.Month() == reportDate.Month() &&
 					dn.CreatedAt.Day() == reportDate.Day() {
 					report.DebitNotes = append(report.DebitNotes, NoteDetail{
+						ID:        dn.ID,
 						Number:    dn.Number,
 						Prefix:    dn.Prefix,
 						Reason:    dn.Reason,
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"PosApp/app/models"
+
+	"gorm.io/gorm"
+)
+
+// mailTransport is the SMTP server used to deliver mail
+type mailTransport struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	Encryption string // "tls" (STARTTLS when offered), "ssl" (implicit TLS) or "none"
+	From       string // Envelope sender address
+	FromName   string
+	Local      bool // Local SMTP stand-in: plain connection without authentication
+}
+
+// Addr returns host:port of the server
+func (t *mailTransport) Addr() string {
+	return net.JoinHostPort(t.Host, fmt.Sprintf("%d", t.Port))
+}
+
+// mailFile is an attachment of an outgoing email
+type mailFile struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// outgoingMail is a message ready to be delivered
+type outgoingMail struct {
+	To          []string
+	Subject     string
+	HTMLBody    string
+	Attachments []mailFile
+}
+
+// mailTransportFromConfig returns the SMTP settings of the DIAN configuration,
+// or the local SMTP stand-in when mail test mode is enabled
+func mailTransportFromConfig(db *gorm.DB) (*mailTransport, error) {
+	var config models.DIANConfig
+	if err := db.First(&config).Error; err != nil {
+		return nil, fmt.Errorf("configuración de correo no encontrada")
+	}
+
+	transport := &mailTransport{
+		Host:       config.EmailHost,
+		Port:       config.EmailPort,
+		Username:   config.EmailUsername,
+		Password:   config.EmailPassword,
+		Encryption: strings.ToLower(config.EmailEncryption),
+		From:       config.EmailUsername,
+	}
+
+	var restaurant models.RestaurantConfig
+	if err := db.First(&restaurant).Error; err == nil {
+		transport.FromName = restaurant.Name
+		if transport.From == "" {
+			transport.From = restaurant.Email
+		}
+	}
+
+	if NewConfigService().GetSystemConfigBool("mail_use_local_smtp", false) {
+		server, err := ensureLocalSMTPServer()
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo iniciar el servidor de correo local: %w", err)
+		}
+		host, port := server.HostPort()
+		transport.Host = host
+		transport.Port = port
+		transport.Encryption = "none"
+		transport.Local = true
+		if transport.From == "" {
+			transport.From = "pos@localhost"
+		}
+		return transport, nil
+	}
+
+	if transport.Host == "" || transport.Username == "" {
+		return nil, fmt.Errorf("el servidor de correo no está configurado")
+	}
+	if transport.Port == 0 {
+		transport.Port = 587
+	}
+	return transport, nil
+}
+
+// buildMIMEMessage builds the RFC 5322 message with an HTML body and optional attachments
+func buildMIMEMessage(transport *mailTransport, mail *outgoingMail) []byte {
+	from := transport.From
+	if transport.FromName != "" {
+		from = fmt.Sprintf("%s <%s>", mime.QEncoding.Encode("utf-8", transport.FromName), transport.From)
+	}
+
+	var msg bytes.Buffer
+	writeHeader := func(name, value string) {
+		msg.WriteString(name + ": " + value + "\r\n")
+	}
+	writeHeader("From", from)
+	writeHeader("To", strings.Join(mail.To, ", "))
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", mail.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), randomMailToken(), mailDomain(transport.From)))
+	writeHeader("MIME-Version", "1.0")
+
+	if len(mail.Attachments) == 0 {
+		writeHeader("Content-Type", "text/html; charset=UTF-8")
+		writeHeader("Content-Transfer-Encoding", "base64")
+		msg.WriteString("\r\n")
+		writeBase64Lines(&msg, []byte(mail.HTMLBody))
+		return msg.Bytes()
+	}
+
+	boundary := "posapp-" + randomMailToken()
+	writeHeader("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": boundary}))
+	msg.WriteString("\r\n")
+
+	msg.WriteString("--" + boundary + "\r\n")
+	writeHeader("Content-Type", "text/html; charset=UTF-8")
+	writeHeader("Content-Transfer-Encoding", "base64")
+	msg.WriteString("\r\n")
+	writeBase64Lines(&msg, []byte(mail.HTMLBody))
+
+	for _, file := range mail.Attachments {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		msg.WriteString("--" + boundary + "\r\n")
+		writeHeader("Content-Type", mime.FormatMediaType(contentType, map[string]string{"name": file.FileName}))
+		writeHeader("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.FileName}))
+		writeHeader("Content-Transfer-Encoding", "base64")
+		msg.WriteString("\r\n")
+		writeBase64Lines(&msg, file.Data)
+	}
+	msg.WriteString("--" + boundary + "--\r\n")
+
+	return msg.Bytes()
+}
+
+// writeBase64Lines writes data base64 encoded in lines of 76 characters
+func writeBase64Lines(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+func randomMailToken() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func mailDomain(address string) string {
+	if idx := strings.LastIndex(address, "@"); idx != -1 && idx < len(address)-1 {
+		return address[idx+1:]
+	}
+	return "localhost"
+}
+
+// deliverMail sends the message through the SMTP server
+func deliverMail(transport *mailTransport, mail *outgoingMail) error {
+	if len(mail.To) == 0 {
+		return fmt.Errorf("el correo no tiene destinatarios")
+	}
+
+	message := buildMIMEMessage(transport, mail)
+	implicitTLS := transport.Encryption == "ssl" || transport.Port == 465
+
+	var conn net.Conn
+	var err error
+	tlsConfig := &tls.Config{ServerName: transport.Host}
+	if implicitTLS && !transport.Local {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: 15 * time.Second}, "tcp", transport.Addr(), tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", transport.Addr(), 15*time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("error connecting to SMTP server: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(2 * time.Minute))
+
+	client, err := smtp.NewClient(conn, transport.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error starting SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && !implicitTLS && transport.Encryption != "none" {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("error starting TLS: %w", err)
+		}
+	}
+	if transport.Password != "" && !transport.Local {
+		if err := client.Auth(smtp.PlainAuth("", transport.Username, transport.Password, transport.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(transport.From); err != nil {
+		return fmt.Errorf("error setting sender: %w", err)
+	}
+	for _, to := range mail.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("error setting recipient %s: %w", to, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error sending message: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		writer.Close()
+		return fmt.Errorf("error sending message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error sending message: %w", err)
+	}
+	return client.Quit()
+}
+
+// splitMailRecipients parses a comma or semicolon separated list of addresses
+func splitMailRecipients(list string) []string {
+	var recipients []string
+	for _, address := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		address = strings.TrimSpace(address)
+		if address != "" && strings.Contains(address, "@") {
+			recipients = append(recipients, address)
+		}
+	}
+	return recipients
+}
@@ -0,0 +1,427 @@
+package services
+
+import (
+	"PosApp/app/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SupportDocumentData represents the data structure for sending a support document (Documento Soporte) to DIAN
+type SupportDocumentData struct {
+	Number              int                   `json:"number"`
+	TypeDocumentID      int                   `json:"type_document_id"`
+	Date                string                `json:"date"`
+	Time                string                `json:"time"`
+	ResolutionNumber    string                `json:"resolution_number"`
+	Prefix              string                `json:"prefix"`
+	Notes               string                `json:"notes,omitempty"`
+	Sendmail            bool                  `json:"sendmail"`
+	Seller              InvoiceCustomer       `json:"seller"`
+	PaymentForm         PaymentFormData       `json:"payment_form"`
+	LegalMonetaryTotals LegalMonetaryTotals   `json:"legal_monetary_totals"`
+	TaxTotals           []TaxTotal            `json:"tax_totals,omitempty"`
+	WithHoldingTaxTotal []TaxTotal            `json:"with_holding_tax_total,omitempty"`
+	InvoiceLines        []SupportDocumentLine `json:"invoice_lines"`
+}
+
+// SupportDocumentLine represents a support document line item
+type SupportDocumentLine struct {
+	UnitMeasureID               int        `json:"unit_measure_id"`
+	InvoicedQuantity            string     `json:"invoiced_quantity"`
+	LineExtensionAmount         string     `json:"line_extension_amount"`
+	FreeOfChargeIndicator       bool       `json:"free_of_charge_indicator"`
+	TaxTotals                   []TaxTotal `json:"tax_totals,omitempty"`
+	Description                 string     `json:"description"`
+	Code                        string     `json:"code"`
+	TypeItemIdentificationID    int        `json:"type_item_identification_id"`
+	PriceAmount                 string     `json:"price_amount"`
+	BaseQuantity                string     `json:"base_quantity"`
+	TypeGenerationTransmitionID int        `json:"type_generation_transmition_id"` // 1 = Por operación
+	StartDate                   string     `json:"start_date"`
+}
+
+// SupportDocumentAdjustmentData represents the adjustment note (Nota de Ajuste) to a support document
+type SupportDocumentAdjustmentData struct {
+	Number                         int                   `json:"number"`
+	TypeDocumentID                 int                   `json:"type_document_id"`
+	Date                           string                `json:"date"`
+	Time                           string                `json:"time"`
+	Prefix                         string                `json:"prefix"`
+	Notes                          string                `json:"notes"`
+	BillingReference               BillingReference      `json:"billing_reference"`
+	DiscrepancyResponseCode        int                   `json:"discrepancyresponsecode"`
+	DiscrepancyResponseDescription string                `json:"discrepancyresponsedescription"`
+	Seller                         InvoiceCustomer       `json:"seller"`
+	LegalMonetaryTotals            LegalMonetaryTotals   `json:"legal_monetary_totals"`
+	TaxTotals                      []TaxTotal            `json:"tax_totals,omitempty"`
+	CreditNoteLines                []SupportDocumentLine `json:"credit_note_lines"`
+}
+
+// SendSupportDocument sends a support document to DIAN for an inventory purchase
+func (s *InvoiceService) SendSupportDocument(purchase *models.InventoryPurchase) (*models.SupportDocument, error) {
+	// Load DIAN config
+	var config models.DIANConfig
+	if err := s.db.First(&config).Error; err != nil {
+		return nil, fmt.Errorf("DIAN configuration not found")
+	}
+	s.config = &config
+
+	if !config.IsEnabled {
+		return nil, fmt.Errorf("electronic invoicing is disabled")
+	}
+	if !config.Step9Completed {
+		return nil, fmt.Errorf("support document resolution is not configured")
+	}
+
+	// The number is reserved atomically, so concurrent purchases never get the same one
+	documentNumber, err := reserveSupportDocNumber(s.db, config.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.config.LastSupportDocNumber = documentNumber
+
+	// Prepare support document data
+	documentData, err := s.prepareSupportDocumentData(purchase, documentNumber)
+	if err != nil {
+		releaseSupportDocNumber(s.db, config.ID, documentNumber)
+		return nil, fmt.Errorf("failed to prepare support document data: %w", err)
+	}
+
+	// Marshal document data to JSON for storage (what we send to DIAN)
+	requestDataJSON, err := json.Marshal(documentData)
+	if err != nil {
+		fmt.Printf("Warning: Could not marshal support document request data to JSON: %v\n", err)
+		requestDataJSON = []byte("{}")
+	}
+
+	// Reuse the record of a previous failed attempt so the purchase keeps a single support document
+	var document models.SupportDocument
+	s.db.Where("purchase_id = ?", purchase.ID).First(&document)
+	document.PurchaseID = purchase.ID
+	document.Number = strconv.Itoa(documentData.Number)
+	document.Prefix = documentData.Prefix
+	document.RequestData = string(requestDataJSON)
+
+	// Send to DIAN API
+	now := time.Now()
+	response, err := s.sendToDIAN(documentData, "support_document")
+	if err != nil {
+		// The document never reached the provider: give the number back unless a later purchase took one
+		if errors.Is(err, errDIANUnavailable) {
+			releaseSupportDocNumber(s.db, config.ID, documentNumber)
+		}
+		errorResponse, _ := json.Marshal(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"message": "Error al enviar documento soporte a DIAN",
+		})
+		document.Status = "error"
+		document.ValidationMessage = fmt.Sprintf("Error: %s", err.Error())
+		document.DIANResponse = string(errorResponse)
+		document.LastError = err.Error()
+
+		if saveErr := s.db.Save(&document).Error; saveErr != nil {
+			fmt.Printf("Warning: Could not save error support document: %v\n", saveErr)
+		}
+		return &document, fmt.Errorf("failed to send support document: %w", err)
+	}
+
+	// Extract document key and validation status from response
+	result := parseDocumentResponse(response)
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		fmt.Printf("Warning: Could not marshal DIAN response to JSON: %v\n", err)
+		responseJSON = []byte("{}")
+	}
+
+	document.CUDS = result.Key
+	document.QRCode = result.QRCode
+	document.ZipKey = result.ZipKey
+	document.Status = result.Status
+	document.IsValid = result.IsValid
+	document.ValidationMessage = result.ValidationMessage
+	document.DIANResponse = string(responseJSON)
+	document.LastError = ""
+	document.SentAt = &now
+	document.ValidationCheckedAt = &now
+	if result.IsValid != nil && *result.IsValid {
+		document.AcceptedAt = &now
+	}
+
+	if err := s.db.Save(&document).Error; err != nil {
+		return nil, fmt.Errorf("failed to save support document: %w", err)
+	}
+
+	// If zipkey was returned, start validation worker
+	if result.ZipKey != "" {
+		go s.validateZipKeyAsync(&models.SupportDocument{}, document.ID, result.ZipKey)
+	}
+
+	return &document, nil
+}
+
+// reserveSupportDocNumber atomically advances the support document consecutive and returns the reserved number
+func reserveSupportDocNumber(db *gorm.DB, configID uint) (int, error) {
+	var reserved struct {
+		LastSupportDocNumber int
+	}
+	result := db.Raw(`
+		UPDATE dian_configs SET last_support_doc_number = last_support_doc_number + 1
+		WHERE id = ? AND (support_doc_resolution_to = 0 OR last_support_doc_number < support_doc_resolution_to)
+		RETURNING last_support_doc_number
+	`, configID).Scan(&reserved)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to reserve support document number: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, fmt.Errorf("support document resolution range exhausted")
+	}
+	return reserved.LastSupportDocNumber, nil
+}
+
+// releaseSupportDocNumber returns a reserved support document number that was not used, if it is still the last one
+func releaseSupportDocNumber(db *gorm.DB, configID uint, number int) {
+	db.Exec("UPDATE dian_configs SET last_support_doc_number = last_support_doc_number - 1 WHERE id = ? AND last_support_doc_number = ?", configID, number)
+}
+
+// SendSupportDocumentAdjustmentNote sends an adjustment note that reverses a support document
+func (s *InvoiceService) SendSupportDocumentAdjustmentNote(document *models.SupportDocument, reason string, discrepancyCode int) (*models.SupportDocumentAdjustmentNote, error) {
+	// Load DIAN config
+	var config models.DIANConfig
+	if err := s.db.First(&config).Error; err != nil {
+		return nil, fmt.Errorf("DIAN configuration not found")
+	}
+	s.config = &config
+
+	if !config.IsEnabled {
+		return nil, fmt.Errorf("electronic invoicing is disabled")
+	}
+	if config.SupportDocAdjustmentPrefix == "" {
+		return nil, fmt.Errorf("support document adjustment note prefix is not configured")
+	}
+
+	var purchase models.InventoryPurchase
+	if err := s.db.Preload("Supplier").Preload("Items").First(&purchase, document.PurchaseID).Error; err != nil {
+		return nil, fmt.Errorf("purchase not found: %w", err)
+	}
+
+	noteData := &SupportDocumentAdjustmentData{
+		Number:                         config.LastSupportDocAdjustmentNumber + 1,
+		TypeDocumentID:                 13, // Support Document Adjustment Note
+		Date:                           time.Now().Format("2006-01-02"),
+		Time:                           time.Now().Format("15:04:05"),
+		Prefix:                         config.SupportDocAdjustmentPrefix,
+		Notes:                          reason,
+		DiscrepancyResponseCode:        discrepancyCode,
+		DiscrepancyResponseDescription: reason,
+		BillingReference: BillingReference{
+			Number:         document.Prefix + document.Number,
+			UUID:           document.CUDS,
+			IssueDate:      document.CreatedAt.Format("2006-01-02"),
+			TypeDocumentID: 11, // Support Document
+		},
+		Seller:              s.buildInvoiceCustomer(supplierAsCustomer(purchase.Supplier)),
+		LegalMonetaryTotals: purchaseMonetaryTotals(&purchase),
+		TaxTotals:           purchaseTaxTotals(&purchase),
+		CreditNoteLines:     purchaseLines(&purchase),
+	}
+	if noteData.Number < config.SupportDocAdjustmentFrom {
+		noteData.Number = config.SupportDocAdjustmentFrom
+	}
+
+	requestDataJSON, _ := json.Marshal(noteData)
+
+	// Send to DIAN API
+	response, err := s.sendToDIAN(noteData, "support_document_adjustment")
+	if err != nil {
+		return nil, fmt.Errorf("failed to send support document adjustment note: %w", err)
+	}
+
+	result := parseDocumentResponse(response)
+	responseJSON, _ := json.Marshal(response)
+
+	note := &models.SupportDocumentAdjustmentNote{
+		SupportDocumentID: document.ID,
+		Number:            strconv.Itoa(noteData.Number),
+		Prefix:            noteData.Prefix,
+		CUDS:              result.Key,
+		Reason:            reason,
+		DiscrepancyCode:   discrepancyCode,
+		Amount:            purchase.Subtotal + purchase.Tax,
+		Status:            result.Status,
+		DIANResponse:      string(responseJSON),
+		RequestData:       string(requestDataJSON),
+	}
+
+	if err := s.db.Create(note).Error; err != nil {
+		return nil, fmt.Errorf("failed to save support document adjustment note: %w", err)
+	}
+
+	// Update adjustment note counter
+	config.LastSupportDocAdjustmentNumber = noteData.Number
+	s.db.Model(&config).UpdateColumn("last_support_doc_adjustment_number", config.LastSupportDocAdjustmentNumber)
+
+	return note, nil
+}
+
+// prepareSupportDocumentData prepares support document data from a purchase
+func (s *InvoiceService) prepareSupportDocumentData(purchase *models.InventoryPurchase, documentNumber int) (*SupportDocumentData, error) {
+	if err := s.db.Preload("Supplier").Preload("Items").Preload("Withholdings").First(purchase, purchase.ID).Error; err != nil {
+		return nil, err
+	}
+	if purchase.Supplier == nil {
+		return nil, fmt.Errorf("purchase has no supplier")
+	}
+
+	if documentNumber < s.config.SupportDocResolutionFrom {
+		documentNumber = s.config.SupportDocResolutionFrom
+	}
+
+	paymentMethodCode := purchase.PaymentMethodCode
+	if paymentMethodCode == 0 {
+		paymentMethodCode = 10 // Efectivo
+	}
+
+	document := &SupportDocumentData{
+		Number:           documentNumber,
+		TypeDocumentID:   11, // Support Document
+		Date:             time.Now().Format("2006-01-02"),
+		Time:             time.Now().Format("15:04:05"),
+		ResolutionNumber: s.config.SupportDocResolutionNumber,
+		Prefix:           s.config.SupportDocResolutionPrefix,
+		Notes:            purchase.Notes,
+		Sendmail:         false,
+		Seller:           s.buildInvoiceCustomer(supplierAsCustomer(purchase.Supplier)),
+		PaymentForm: PaymentFormData{
+			PaymentFormID:   1, // Contado
+			PaymentMethodID: paymentMethodCode,
+			PaymentDueDate:  purchase.PurchasedAt.Format("2006-01-02"),
+			DurationMeasure: "0",
+		},
+		LegalMonetaryTotals: purchaseMonetaryTotals(purchase),
+		TaxTotals:           purchaseTaxTotals(purchase),
+		InvoiceLines:        purchaseLines(purchase),
+	}
+
+	// Withholdings are informative: they do not change the payable amount of the document
+	for _, withholding := range purchase.Withholdings {
+		document.WithHoldingTaxTotal = append(document.WithHoldingTaxTotal, TaxTotal{
+			TaxID:         withholding.TaxID,
+			TaxAmount:     fmt.Sprintf("%.2f", withholding.Amount),
+			Percent:       fmt.Sprintf("%.2f", withholding.Percent),
+			TaxableAmount: fmt.Sprintf("%.2f", withholding.TaxableAmount),
+		})
+	}
+
+	return document, nil
+}
+
+// supplierAsCustomer maps a supplier to the Customer identification fields used to build DIAN parties
+func supplierAsCustomer(supplier *models.Supplier) *models.Customer {
+	return &models.Customer{
+		IdentificationType:           supplier.IdentificationType,
+		IdentificationNumber:         supplier.IdentificationNumber,
+		DV:                           supplier.DV,
+		Name:                         supplier.Name,
+		Email:                        supplier.Email,
+		Phone:                        supplier.Phone,
+		Address:                      supplier.Address,
+		MunicipalityID:               supplier.MunicipalityID,
+		TypeDocumentIdentificationID: supplier.TypeDocumentIdentificationID,
+		TypeOrganizationID:           supplier.TypeOrganizationID,
+		TypeLiabilityID:              supplier.TypeLiabilityID,
+		TypeRegimeID:                 supplier.TypeRegimeID,
+		MerchantRegistration:         supplier.MerchantRegistration,
+	}
+}
+
+// purchaseMonetaryTotals calculates the document totals of a purchase
+func purchaseMonetaryTotals(purchase *models.InventoryPurchase) LegalMonetaryTotals {
+	return LegalMonetaryTotals{
+		LineExtensionAmount: fmt.Sprintf("%.2f", purchase.Subtotal),
+		TaxExclusiveAmount:  fmt.Sprintf("%.2f", purchase.Subtotal),
+		TaxInclusiveAmount:  fmt.Sprintf("%.2f", purchase.Subtotal+purchase.Tax),
+		PayableAmount:       fmt.Sprintf("%.2f", purchase.Subtotal+purchase.Tax),
+	}
+}
+
+// purchaseTaxTotals groups the IVA charged by the supplier by percentage
+func purchaseTaxTotals(purchase *models.InventoryPurchase) []TaxTotal {
+	type taxAccumulator struct {
+		TaxableAmount float64
+		TaxAmount     float64
+	}
+	taxMap := make(map[float64]*taxAccumulator)
+	var percents []float64
+
+	for _, item := range purchase.Items {
+		if item.TaxPercent <= 0 {
+			continue
+		}
+		acc, ok := taxMap[item.TaxPercent]
+		if !ok {
+			acc = &taxAccumulator{}
+			taxMap[item.TaxPercent] = acc
+			percents = append(percents, item.TaxPercent)
+		}
+		acc.TaxableAmount += item.Subtotal
+		acc.TaxAmount += item.TaxAmount
+	}
+
+	var taxTotals []TaxTotal
+	for _, percent := range percents {
+		acc := taxMap[percent]
+		taxTotals = append(taxTotals, TaxTotal{
+			TaxID:         1, // IVA
+			TaxAmount:     fmt.Sprintf("%.2f", acc.TaxAmount),
+			Percent:       fmt.Sprintf("%.2f", percent),
+			TaxableAmount: fmt.Sprintf("%.2f", acc.TaxableAmount),
+		})
+	}
+	return taxTotals
+}
+
+// purchaseLines prepares support document lines from purchase items
+func purchaseLines(purchase *models.InventoryPurchase) []SupportDocumentLine {
+	lines := make([]SupportDocumentLine, 0, len(purchase.Items))
+
+	for _, item := range purchase.Items {
+		unitMeasureID := item.UnitMeasureID
+		if unitMeasureID == 0 {
+			unitMeasureID = 70 // Unidad
+		}
+
+		line := SupportDocumentLine{
+			UnitMeasureID:               unitMeasureID,
+			InvoicedQuantity:            strconv.FormatFloat(item.Quantity, 'f', -1, 64),
+			LineExtensionAmount:         fmt.Sprintf("%.2f", item.Subtotal),
+			FreeOfChargeIndicator:       false,
+			Description:                 item.Description,
+			Code:                        fmt.Sprintf("%d", item.ID),
+			TypeItemIdentificationID:    4,
+			PriceAmount:                 fmt.Sprintf("%.2f", item.UnitPrice),
+			BaseQuantity:                "1",
+			TypeGenerationTransmitionID: 1, // Por operación
+			StartDate:                   purchase.PurchasedAt.Format("2006-01-02"),
+		}
+		if item.TaxPercent > 0 {
+			line.TaxTotals = []TaxTotal{
+				{
+					TaxID:         1, // IVA
+					TaxAmount:     fmt.Sprintf("%.2f", item.TaxAmount),
+					Percent:       fmt.Sprintf("%.2f", item.TaxPercent),
+					TaxableAmount: fmt.Sprintf("%.2f", item.Subtotal),
+				},
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
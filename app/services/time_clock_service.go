@@ -0,0 +1,641 @@
+package services
+
+import (
+	"PosApp/app/database"
+	"PosApp/app/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TimeClockService handles employee clock-in/out, breaks and shift schedules
+type TimeClockService struct {
+	*BaseService
+	employeeSvc *EmployeeService
+}
+
+// NewTimeClockService creates a new time clock service
+func NewTimeClockService() *TimeClockService {
+	return &TimeClockService{
+		BaseService: &BaseService{db: database.GetDB()},
+		employeeSvc: NewEmployeeService(),
+	}
+}
+
+// TimeClockStatusInfo represents the current clock state of an employee
+type TimeClockStatusInfo struct {
+	Employee    *models.Employee       `json:"employee"`
+	Entry       *models.TimeClockEntry `json:"entry,omitempty"` // Open entry, nil when clocked out
+	Status      string                 `json:"status"`          // "clocked_out", "working", "on_break"
+	WorkedToday float64                `json:"worked_today"`    // Hours worked today (excluding unpaid breaks)
+	NextShift   *models.ShiftSchedule  `json:"next_shift,omitempty"`
+}
+
+// Clock in / out
+
+// ClockIn starts a time clock entry for the employee identified by PIN
+func (s *TimeClockService) ClockIn(pin string, notes string) (*models.TimeClockEntry, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	employee, err := s.employeeSvc.AuthenticateEmployeeByPIN(pin)
+	if err != nil {
+		return nil, err
+	}
+
+	if open, _ := s.getOpenEntry(employee.ID); open != nil {
+		return nil, fmt.Errorf("%s ya tiene un turno abierto desde las %s", employee.Name, open.ClockIn.Format("15:04"))
+	}
+
+	entry := &models.TimeClockEntry{
+		EmployeeID: employee.ID,
+		ClockIn:    time.Now(),
+		Status:     models.TimeClockStatusWorking,
+		Source:     "pin",
+		Notes:      notes,
+	}
+	if err := s.db.Create(entry).Error; err != nil {
+		// A concurrent clock-in of the same employee hits the open entry unique index
+		if open, _ := s.getOpenEntry(employee.ID); open != nil {
+			return nil, fmt.Errorf("%s ya tiene un turno abierto desde las %s", employee.Name, open.ClockIn.Format("15:04"))
+		}
+		return nil, fmt.Errorf("error registrando entrada: %w", err)
+	}
+	entry.Employee = employee
+
+	log.Printf("TimeClockService: %s clocked in", employee.Name)
+	return entry, nil
+}
+
+// ClockOut closes the open time clock entry of the employee identified by PIN
+// An ongoing break is closed at the same time
+func (s *TimeClockService) ClockOut(pin string, notes string) (*models.TimeClockEntry, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	employee, err := s.employeeSvc.AuthenticateEmployeeByPIN(pin)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.getOpenEntry(employee.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s no tiene un turno abierto", employee.Name)
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TimeClockBreak{}).
+			Where("entry_id = ? AND end_at IS NULL", entry.ID).
+			Update("end_at", now).Error; err != nil {
+			return err
+		}
+
+		entry.ClockOut = &now
+		entry.Status = models.TimeClockStatusClosed
+		if notes != "" {
+			entry.Notes = strings.TrimSpace(entry.Notes + "\n" + notes)
+		}
+		return tx.Omit("Breaks", "Employee").Save(entry).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error registrando salida: %w", err)
+	}
+
+	log.Printf("TimeClockService: %s clocked out", employee.Name)
+	return s.GetTimeEntry(entry.ID)
+}
+
+// StartBreak starts a break on the open entry of the employee identified by PIN
+func (s *TimeClockService) StartBreak(pin string, paid bool) (*models.TimeClockEntry, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	employee, err := s.employeeSvc.AuthenticateEmployeeByPIN(pin)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.getOpenEntry(employee.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s no tiene un turno abierto", employee.Name)
+	}
+	if entry.Status == models.TimeClockStatusOnBreak {
+		return nil, fmt.Errorf("%s ya está en descanso", employee.Name)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		brk := &models.TimeClockBreak{
+			EntryID: entry.ID,
+			StartAt: time.Now(),
+			Paid:    paid,
+		}
+		if err := tx.Create(brk).Error; err != nil {
+			return err
+		}
+		return tx.Model(entry).Update("status", models.TimeClockStatusOnBreak).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando descanso: %w", err)
+	}
+
+	return s.GetTimeEntry(entry.ID)
+}
+
+// EndBreak ends the ongoing break of the employee identified by PIN
+func (s *TimeClockService) EndBreak(pin string) (*models.TimeClockEntry, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	employee, err := s.employeeSvc.AuthenticateEmployeeByPIN(pin)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.getOpenEntry(employee.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s no tiene un turno abierto", employee.Name)
+	}
+	if entry.Status != models.TimeClockStatusOnBreak {
+		return nil, fmt.Errorf("%s no está en descanso", employee.Name)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TimeClockBreak{}).
+			Where("entry_id = ? AND end_at IS NULL", entry.ID).
+			Update("end_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Model(entry).Update("status", models.TimeClockStatusWorking).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error terminando descanso: %w", err)
+	}
+
+	return s.GetTimeEntry(entry.ID)
+}
+
+// GetClockStatus returns the current clock state of the employee identified by PIN
+func (s *TimeClockService) GetClockStatus(pin string) (*TimeClockStatusInfo, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	employee, err := s.employeeSvc.AuthenticateEmployeeByPIN(pin)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &TimeClockStatusInfo{
+		Employee: employee,
+		Status:   "clocked_out",
+	}
+	if entry, err := s.getOpenEntry(employee.ID); err == nil {
+		info.Entry = entry
+		info.Status = string(entry.Status)
+	}
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var entries []models.TimeClockEntry
+	s.db.Preload("Breaks").
+		Where("employee_id = ? AND clock_in >= ?", employee.ID, startOfDay).
+		Find(&entries)
+	for _, entry := range entries {
+		info.WorkedToday += entryWorkedHours(&entry, now)
+	}
+
+	var next models.ShiftSchedule
+	if err := s.db.Where("employee_id = ? AND end_at > ?", employee.ID, now).
+		Order("start_at ASC").First(&next).Error; err == nil {
+		info.NextShift = &next
+	}
+
+	return info, nil
+}
+
+// GetClockedInEmployees returns all open time clock entries
+func (s *TimeClockService) GetClockedInEmployees() ([]models.TimeClockEntry, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	var entries []models.TimeClockEntry
+	err := s.db.Preload("Employee").Preload("Breaks").
+		Where("status <> ?", models.TimeClockStatusClosed).
+		Order("clock_in ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// Time entries (manager)
+
+// GetTimeEntry gets a time clock entry by ID
+func (s *TimeClockService) GetTimeEntry(entryID uint) (*models.TimeClockEntry, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	var entry models.TimeClockEntry
+	if err := s.db.Preload("Employee").Preload("Breaks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("start_at ASC")
+	}).First(&entry, entryID).Error; err != nil {
+		return nil, fmt.Errorf("registro de tiempo no encontrado: %w", err)
+	}
+	return &entry, nil
+}
+
+// GetTimeEntries gets time clock entries in a period, optionally for a single employee (0 = all)
+func (s *TimeClockService) GetTimeEntries(startDate, endDate time.Time, employeeID uint) ([]models.TimeClockEntry, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	var entries []models.TimeClockEntry
+	query := s.db.Preload("Employee").Preload("Breaks").
+		Where("clock_in BETWEEN ? AND ?", startDate, endDate)
+	if employeeID > 0 {
+		query = query.Where("employee_id = ?", employeeID)
+	}
+	err := query.Order("clock_in ASC").Find(&entries).Error
+	return entries, err
+}
+
+// CreateTimeEntry creates a time clock entry manually (forgotten clock-in)
+func (s *TimeClockService) CreateTimeEntry(entry *models.TimeClockEntry, managerID uint, reason string) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+	if err := s.requireManager(managerID); err != nil {
+		return err
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("se requiere un motivo para registrar tiempo manualmente")
+	}
+	if err := validateTimeEntry(entry); err != nil {
+		return err
+	}
+
+	if entry.ClockOut == nil {
+		if open, err := s.getOpenEntry(entry.EmployeeID); err == nil {
+			return fmt.Errorf("el empleado ya tiene un turno abierto desde %s", open.ClockIn.Format("2006-01-02 15:04"))
+		}
+	}
+
+	now := time.Now()
+	entry.ID = 0
+	entry.Source = "manual"
+	entry.EditedBy = &managerID
+	entry.EditedAt = &now
+	entry.EditReason = reason
+	if entry.ClockOut != nil {
+		entry.Status = models.TimeClockStatusClosed
+	} else {
+		entry.Status = models.TimeClockStatusWorking
+	}
+	if err := s.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("error creando registro de tiempo: %w", err)
+	}
+
+	s.employeeSvc.LogAudit(managerID, "create", "time_clock_entry", entry.ID, "", toAuditJSON(entry), "", "")
+	return nil
+}
+
+// UpdateTimeEntry lets a manager correct clock times and breaks, keeping an audit trail
+func (s *TimeClockService) UpdateTimeEntry(entry *models.TimeClockEntry, managerID uint, reason string) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+	if err := s.requireManager(managerID); err != nil {
+		return err
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("se requiere un motivo para editar el registro de tiempo")
+	}
+	if err := validateTimeEntry(entry); err != nil {
+		return err
+	}
+
+	existing, err := s.GetTimeEntry(entry.ID)
+	if err != nil {
+		return err
+	}
+	oldValue := toAuditJSON(existing)
+
+	// Reopening an entry is only allowed when the employee has no other open entry
+	if entry.ClockOut == nil {
+		if open, err := s.getOpenEntry(existing.EmployeeID); err == nil && open.ID != existing.ID {
+			return fmt.Errorf("el empleado ya tiene un turno abierto desde %s", open.ClockIn.Format("2006-01-02 15:04"))
+		}
+	}
+
+	now := time.Now()
+	existing.ClockIn = entry.ClockIn
+	existing.ClockOut = entry.ClockOut
+	existing.Notes = entry.Notes
+	existing.EditedBy = &managerID
+	existing.EditedAt = &now
+	existing.EditReason = reason
+	if existing.ClockOut != nil {
+		existing.Status = models.TimeClockStatusClosed
+	} else if existing.Status == models.TimeClockStatusClosed {
+		existing.Status = models.TimeClockStatusWorking
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Breaks", "Employee").Save(existing).Error; err != nil {
+			return err
+		}
+		if entry.Breaks == nil {
+			return nil
+		}
+		// Replace breaks with the edited set
+		if err := tx.Where("entry_id = ?", existing.ID).Delete(&models.TimeClockBreak{}).Error; err != nil {
+			return err
+		}
+		for _, brk := range entry.Breaks {
+			brk.ID = 0
+			brk.EntryID = existing.ID
+			if err := tx.Create(&brk).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error actualizando registro de tiempo: %w", err)
+	}
+
+	updated, _ := s.GetTimeEntry(existing.ID)
+	s.employeeSvc.LogAudit(managerID, "update", "time_clock_entry", existing.ID, oldValue, toAuditJSON(updated), "", "")
+	return nil
+}
+
+// DeleteTimeEntry deletes a time clock entry, keeping an audit trail
+func (s *TimeClockService) DeleteTimeEntry(entryID uint, managerID uint, reason string) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+	if err := s.requireManager(managerID); err != nil {
+		return err
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("se requiere un motivo para eliminar el registro de tiempo")
+	}
+
+	existing, err := s.GetTimeEntry(entryID)
+	if err != nil {
+		return err
+	}
+	oldValue := toAuditJSON(existing)
+
+	// The deleted row keeps who deleted it and why
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(existing).Updates(map[string]interface{}{
+			"edited_by":   managerID,
+			"edited_at":   now,
+			"edit_reason": reason,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(existing).Error
+	})
+	if err != nil {
+		return fmt.Errorf("error eliminando registro de tiempo: %w", err)
+	}
+
+	s.employeeSvc.LogAudit(managerID, "delete", "time_clock_entry", entryID, oldValue, toAuditJSON(existing), "", "")
+	return nil
+}
+
+// GetTimeEntryAuditLog returns the audit trail of time clock edits
+func (s *TimeClockService) GetTimeEntryAuditLog(limit, offset int) ([]models.AuditLog, error) {
+	return s.employeeSvc.GetAuditLogs(0, "time_clock_entry", limit, offset)
+}
+
+// Shift schedules
+
+// GetWeeklySchedule returns the shifts of the week starting at weekStart, optionally filtered by role
+func (s *TimeClockService) GetWeeklySchedule(weekStart time.Time, role string) ([]models.ShiftSchedule, error) {
+	if err := s.EnsureDB(); err != nil {
+		return nil, err
+	}
+	start := time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, weekStart.Location())
+	end := start.AddDate(0, 0, 7)
+
+	var shifts []models.ShiftSchedule
+	query := s.db.Preload("Employee").
+		Where("start_at >= ? AND start_at < ?", start, end)
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	err := query.Order("start_at ASC").Find(&shifts).Error
+	return shifts, err
+}
+
+// CreateShift schedules a shift for an employee
+func (s *TimeClockService) CreateShift(shift *models.ShiftSchedule) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+	if err := s.validateShift(shift); err != nil {
+		return err
+	}
+	return s.db.Create(shift).Error
+}
+
+// UpdateShift updates a scheduled shift
+func (s *TimeClockService) UpdateShift(shift *models.ShiftSchedule) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+	if err := s.validateShift(shift); err != nil {
+		return err
+	}
+	return s.db.Omit("Employee").Save(shift).Error
+}
+
+// DeleteShift deletes a scheduled shift
+func (s *TimeClockService) DeleteShift(shiftID uint) error {
+	if err := s.EnsureDB(); err != nil {
+		return err
+	}
+	return s.db.Delete(&models.ShiftSchedule{}, shiftID).Error
+}
+
+// CopyWeekSchedule copies all shifts from one week to another, skipping shifts that would overlap
+func (s *TimeClockService) CopyWeekSchedule(fromWeekStart, toWeekStart time.Time, createdBy uint) (int, error) {
+	shifts, err := s.GetWeeklySchedule(fromWeekStart, "")
+	if err != nil {
+		return 0, err
+	}
+
+	from := time.Date(fromWeekStart.Year(), fromWeekStart.Month(), fromWeekStart.Day(), 0, 0, 0, 0, fromWeekStart.Location())
+	to := time.Date(toWeekStart.Year(), toWeekStart.Month(), toWeekStart.Day(), 0, 0, 0, 0, toWeekStart.Location())
+	days := int(to.Sub(from).Hours() / 24)
+
+	copied := 0
+	for _, shift := range shifts {
+		newShift := models.ShiftSchedule{
+			EmployeeID: shift.EmployeeID,
+			Role:       shift.Role,
+			StartAt:    shift.StartAt.AddDate(0, 0, days),
+			EndAt:      shift.EndAt.AddDate(0, 0, days),
+			Notes:      shift.Notes,
+		}
+		if createdBy > 0 {
+			newShift.CreatedBy = &createdBy
+		}
+		if err := s.CreateShift(&newShift); err != nil {
+			log.Printf("TimeClockService: skipping shift copy for employee %d: %v", shift.EmployeeID, err)
+			continue
+		}
+		copied++
+	}
+
+	return copied, nil
+}
+
+// Helper methods
+
+func (s *TimeClockService) getOpenEntry(employeeID uint) (*models.TimeClockEntry, error) {
+	var entry models.TimeClockEntry
+	err := s.db.Preload("Breaks").
+		Where("employee_id = ? AND status <> ?", employeeID, models.TimeClockStatusClosed).
+		Order("clock_in DESC").
+		First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (s *TimeClockService) requireManager(managerID uint) error {
+	var manager models.Employee
+	if err := s.db.First(&manager, managerID).Error; err != nil {
+		return fmt.Errorf("empleado no encontrado")
+	}
+	if !isManagerRole(manager.Role) {
+		return fmt.Errorf("solo un administrador puede modificar registros de tiempo")
+	}
+	return nil
+}
+
+func (s *TimeClockService) validateShift(shift *models.ShiftSchedule) error {
+	if shift.EmployeeID == 0 {
+		return fmt.Errorf("el turno requiere un empleado")
+	}
+	if !shift.EndAt.After(shift.StartAt) {
+		return fmt.Errorf("la hora de fin del turno debe ser posterior a la de inicio")
+	}
+	if shift.EndAt.Sub(shift.StartAt) > 24*time.Hour {
+		return fmt.Errorf("un turno no puede durar más de 24 horas")
+	}
+
+	if shift.Role == "" {
+		var employee models.Employee
+		if err := s.db.First(&employee, shift.EmployeeID).Error; err != nil {
+			return fmt.Errorf("empleado no encontrado")
+		}
+		shift.Role = employee.Role
+	}
+
+	var overlapping int64
+	s.db.Model(&models.ShiftSchedule{}).
+		Where("employee_id = ? AND id <> ? AND start_at < ? AND end_at > ?",
+			shift.EmployeeID, shift.ID, shift.EndAt, shift.StartAt).
+		Count(&overlapping)
+	if overlapping > 0 {
+		return fmt.Errorf("el empleado ya tiene un turno programado en ese horario")
+	}
+	return nil
+}
+
+func validateTimeEntry(entry *models.TimeClockEntry) error {
+	if entry.EmployeeID == 0 {
+		return fmt.Errorf("el registro requiere un empleado")
+	}
+	if entry.ClockIn.IsZero() {
+		return fmt.Errorf("la hora de entrada es obligatoria")
+	}
+	if entry.ClockOut != nil && !entry.ClockOut.After(entry.ClockIn) {
+		return fmt.Errorf("la hora de salida debe ser posterior a la de entrada")
+	}
+	for _, brk := range entry.Breaks {
+		if brk.StartAt.Before(entry.ClockIn) {
+			return fmt.Errorf("los descansos deben estar dentro del turno")
+		}
+		if brk.EndAt != nil && (!brk.EndAt.After(brk.StartAt) || (entry.ClockOut != nil && brk.EndAt.After(*entry.ClockOut))) {
+			return fmt.Errorf("los descansos deben estar dentro del turno")
+		}
+	}
+	return nil
+}
+
+// isManagerRole returns true for roles allowed to manage staff records
+func isManagerRole(role string) bool {
+	return role == "admin" || role == "manager"
+}
+
+func toAuditJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// workInterval is a continuous period of worked time
+type workInterval struct {
+	start time.Time
+	end   time.Time
+}
+
+// entryWorkIntervals returns the worked periods of an entry (unpaid breaks removed)
+// Open entries and breaks are measured until now
+func entryWorkIntervals(entry *models.TimeClockEntry, now time.Time) []workInterval {
+	end := now
+	if entry.ClockOut != nil {
+		end = *entry.ClockOut
+	}
+	if !end.After(entry.ClockIn) {
+		return nil
+	}
+
+	intervals := []workInterval{{start: entry.ClockIn, end: end}}
+	for _, brk := range entry.Breaks {
+		if brk.Paid {
+			continue
+		}
+		brkEnd := end
+		if brk.EndAt != nil && brk.EndAt.Before(end) {
+			brkEnd = *brk.EndAt
+		}
+
+		var next []workInterval
+		for _, iv := range intervals {
+			if !brk.StartAt.Before(iv.end) || !brkEnd.After(iv.start) {
+				next = append(next, iv)
+				continue
+			}
+			if brk.StartAt.After(iv.start) {
+				next = append(next, workInterval{start: iv.start, end: brk.StartAt})
+			}
+			if brkEnd.Before(iv.end) {
+				next = append(next, workInterval{start: brkEnd, end: iv.end})
+			}
+		}
+		intervals = next
+	}
+	return intervals
+}
+
+// entryWorkedHours returns the worked hours of an entry (unpaid breaks removed)
+func entryWorkedHours(entry *models.TimeClockEntry, now time.Time) float64 {
+	hours := 0.0
+	for _, iv := range entryWorkIntervals(entry, now) {
+		hours += iv.end.Sub(iv.start).Hours()
+	}
+	return hours
+}
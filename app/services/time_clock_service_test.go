@@ -0,0 +1,69 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"PosApp/app/models"
+)
+
+func TestEntryWorkedHours(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, time.October, 14, hour, minute, 0, 0, time.Local)
+	}
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name  string
+		entry models.TimeClockEntry
+		now   time.Time
+		want  float64
+	}{
+		{
+			name:  "closed entry without breaks",
+			entry: models.TimeClockEntry{ClockIn: at(8, 0), ClockOut: ptr(at(16, 0))},
+			want:  8,
+		},
+		{
+			name: "unpaid break is removed",
+			entry: models.TimeClockEntry{ClockIn: at(8, 0), ClockOut: ptr(at(16, 0)), Breaks: []models.TimeClockBreak{
+				{StartAt: at(12, 0), EndAt: ptr(at(13, 0))},
+			}},
+			want: 7,
+		},
+		{
+			name: "paid break counts as worked",
+			entry: models.TimeClockEntry{ClockIn: at(8, 0), ClockOut: ptr(at(16, 0)), Breaks: []models.TimeClockBreak{
+				{StartAt: at(12, 0), EndAt: ptr(at(12, 30)), Paid: true},
+			}},
+			want: 8,
+		},
+		{
+			name:  "open entry is measured until now",
+			entry: models.TimeClockEntry{ClockIn: at(8, 0)},
+			now:   at(10, 30),
+			want:  2.5,
+		},
+		{
+			name: "open break is measured until now",
+			entry: models.TimeClockEntry{ClockIn: at(8, 0), Breaks: []models.TimeClockBreak{
+				{StartAt: at(10, 0)},
+			}},
+			now:  at(11, 0),
+			want: 2,
+		},
+		{
+			name:  "clock out before clock in",
+			entry: models.TimeClockEntry{ClockIn: at(8, 0), ClockOut: ptr(at(7, 0))},
+			want:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := entryWorkedHours(&tt.entry, tt.now); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("entryWorkedHours() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,11 @@ package services
 
 import (
 	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,17 +15,40 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	CurrentVersion = "3.5.0"
-	GitHubAPIURL   = "https://api.github.com/repos/DrewGGM/wails-posapp-releases/releases/latest"
+	CurrentVersion    = "3.5.0"
+	GitHubAPIURL      = "https://api.github.com/repos/DrewGGM/wails-posapp-releases/releases/latest"
+	GitHubReleasesURL = "https://api.github.com/repos/DrewGGM/wails-posapp-releases/releases"
+
+	// Release assets: the update archive, its SHA-256 (sha256sum format) and the
+	// base64 Ed25519 signature of updateSignedMessage (version, channel and digest)
+	updateAssetName     = "PosApp.zip"
+	updateChecksumAsset = "PosApp.zip.sha256"
+	updateSignatureName = "PosApp.zip.sig"
 )
 
+// Update channels
+const (
+	UpdateChannelStable = "stable"
+	UpdateChannelBeta   = "beta" // Also offers pre-releases
+)
+
+// UpdatePublicKey is the base64 Ed25519 public key release artifacts are signed with.
+// Release builds bundle it with -ldflags "-X PosApp/app/services.UpdatePublicKey=<key>"
+var UpdatePublicKey = ""
+
 // UpdateService handles application updates
 type UpdateService struct {
 	client *http.Client
+	mu     sync.Mutex // Serializes downloads, installs and state changes
+
+	// Health check of the first start after an update
+	healthPending bool
+	healthDone    chan struct{}
 }
 
 // NewUpdateService creates a new update service
@@ -34,10 +62,12 @@ func NewUpdateService() *UpdateService {
 
 // GitHubRelease represents a GitHub release
 type GitHubRelease struct {
-	TagName string `json:"tag_name"`
-	Name    string `json:"name"`
-	Body    string `json:"body"`
-	Assets  []struct {
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	Assets     []struct {
 		Name               string `json:"name"`
 		BrowserDownloadURL string `json:"browser_download_url"`
 		Size               int64  `json:"size"`
@@ -47,13 +77,18 @@ type GitHubRelease struct {
 
 // UpdateInfo represents update information
 type UpdateInfo struct {
-	CurrentVersion string    `json:"current_version"`
-	LatestVersion  string    `json:"latest_version"`
-	UpdateAvailable bool     `json:"update_available"`
-	DownloadURL    string    `json:"download_url"`
-	ReleaseNotes   string    `json:"release_notes"`
-	PublishedAt    time.Time `json:"published_at"`
-	FileSize       int64     `json:"file_size"`
+	CurrentVersion  string    `json:"current_version"`
+	LatestVersion   string    `json:"latest_version"`
+	UpdateAvailable bool      `json:"update_available"`
+	DownloadURL     string    `json:"download_url"`
+	ChecksumURL     string    `json:"checksum_url"`
+	SignatureURL    string    `json:"signature_url"`
+	Signed          bool      `json:"signed"` // Release publishes checksum and signature
+	Channel         string    `json:"channel"`
+	Prerelease      bool      `json:"prerelease"`
+	ReleaseNotes    string    `json:"release_notes"`
+	PublishedAt     time.Time `json:"published_at"`
+	FileSize        int64     `json:"file_size"`
 }
 
 // GetCurrentVersion returns the current application version
@@ -61,72 +96,108 @@ func (s *UpdateService) GetCurrentVersion() string {
 	return CurrentVersion
 }
 
-// CheckForUpdates checks if a new version is available
+// CheckForUpdates checks if a new version is available in the configured channel
 func (s *UpdateService) CheckForUpdates() (*UpdateInfo, error) {
-	// Get latest release from GitHub
-	req, err := http.NewRequest("GET", GitHubAPIURL, nil)
+	channel := s.GetUpdateSettings().Channel
+
+	var release *GitHubRelease
+	var err error
+	if channel == UpdateChannelBeta {
+		release, err = s.latestBetaRelease()
+	} else {
+		release = &GitHubRelease{}
+		err = s.fetchGitHub(GitHubAPIURL, release)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+	// Find the update archive and its verification files
+	info := &UpdateInfo{Channel: channel}
+	for _, asset := range release.Assets {
+		switch asset.Name {
+		case updateAssetName:
+			info.DownloadURL = asset.BrowserDownloadURL
+			info.FileSize = asset.Size
+		case updateChecksumAsset:
+			info.ChecksumURL = asset.BrowserDownloadURL
+		case updateSignatureName:
+			info.SignatureURL = asset.BrowserDownloadURL
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	if info.DownloadURL == "" {
+		return nil, fmt.Errorf("no %s found in release", updateAssetName)
 	}
 
-	var release GitHubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, fmt.Errorf("failed to decode release info: %w", err)
+	// Compare versions
+	info.CurrentVersion = CurrentVersion
+	info.LatestVersion = strings.TrimPrefix(release.TagName, "v")
+	info.UpdateAvailable = compareVersions(info.LatestVersion, info.CurrentVersion) > 0
+	info.Signed = info.ChecksumURL != "" && info.SignatureURL != ""
+	info.Prerelease = release.Prerelease
+	info.ReleaseNotes = release.Body
+	info.PublishedAt = release.PublishedAt
+	return info, nil
+}
+
+// latestBetaRelease returns the newest published release, pre-releases included
+func (s *UpdateService) latestBetaRelease() (*GitHubRelease, error) {
+	var releases []GitHubRelease
+	if err := s.fetchGitHub(GitHubReleasesURL+"?per_page=20", &releases); err != nil {
+		return nil, err
 	}
 
-	// Find the PosApp.zip asset
-	var downloadURL string
-	var fileSize int64
-	for _, asset := range release.Assets {
-		if asset.Name == "PosApp.zip" {
-			downloadURL = asset.BrowserDownloadURL
-			fileSize = asset.Size
-			break
+	var latest *GitHubRelease
+	for i := range releases {
+		if releases[i].Draft {
+			continue
+		}
+		if latest == nil || compareVersions(strings.TrimPrefix(releases[i].TagName, "v"), strings.TrimPrefix(latest.TagName, "v")) > 0 {
+			latest = &releases[i]
 		}
 	}
+	if latest == nil {
+		return nil, fmt.Errorf("no releases found")
+	}
+	return latest, nil
+}
 
-	if downloadURL == "" {
-		return nil, fmt.Errorf("no PosApp.zip found in release")
+// fetchGitHub gets a GitHub API resource and decodes it into v
+func (s *UpdateService) fetchGitHub(url string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Compare versions
-	latestVersion := strings.TrimPrefix(release.TagName, "v")
-	currentVersion := CurrentVersion
-	updateAvailable := compareVersions(latestVersion, currentVersion) > 0
-
-	return &UpdateInfo{
-		CurrentVersion:  currentVersion,
-		LatestVersion:   latestVersion,
-		UpdateAvailable: updateAvailable,
-		DownloadURL:     downloadURL,
-		ReleaseNotes:    release.Body,
-		PublishedAt:     release.PublishedAt,
-		FileSize:        fileSize,
-	}, nil
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode release info: %w", err)
+	}
+	return nil
 }
 
 // DownloadUpdate downloads the update file
 func (s *UpdateService) DownloadUpdate(url string) (string, error) {
-	// Create temp directory
-	tempDir := filepath.Join(os.TempDir(), "posapp-update")
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	// Create update directory
+	tempDir, err := updateDir()
+	if err != nil {
+		return "", err
 	}
 
 	// Download file
-	zipPath := filepath.Join(tempDir, "PosApp.zip")
+	zipPath := filepath.Join(tempDir, updateAssetName)
 	out, err := os.Create(zipPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to create zip file: %w", err)
@@ -151,6 +222,86 @@ func (s *UpdateService) DownloadUpdate(url string) (string, error) {
 	return zipPath, nil
 }
 
+// VerifyUpdate checks the downloaded archive against the published SHA-256 and
+// its Ed25519 signature made with the release key
+func (s *UpdateService) VerifyUpdate(zipPath string, info *UpdateInfo) error {
+	checksumURL, signatureURL := info.ChecksumURL, info.SignatureURL
+	if UpdatePublicKey == "" {
+		return fmt.Errorf("this build has no update public key, install the new version manually")
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(UpdatePublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid update public key")
+	}
+	if checksumURL == "" || signatureURL == "" {
+		return fmt.Errorf("release is not signed (missing %s or %s)", updateChecksumAsset, updateSignatureName)
+	}
+
+	checksumData, err := s.downloadSmall(checksumURL)
+	if err != nil {
+		return fmt.Errorf("failed to download checksum: %w", err)
+	}
+	fields := strings.Fields(string(checksumData))
+	if len(fields) == 0 {
+		return fmt.Errorf("empty checksum file")
+	}
+	expected, err := hex.DecodeString(fields[0])
+	if err != nil || len(expected) != sha256.Size {
+		return fmt.Errorf("invalid checksum file")
+	}
+
+	signatureData, err := s.downloadSmall(signatureURL)
+	if err != nil {
+		return fmt.Errorf("failed to download signature: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signatureData)))
+	if err != nil {
+		return fmt.Errorf("invalid signature file: %w", err)
+	}
+
+	digest, err := fileSHA256(zipPath)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(digest, expected) {
+		return fmt.Errorf("checksum mismatch: the downloaded file is corrupt or was modified")
+	}
+	// The signature covers the digest together with the version and channel, so a matching
+	// checksum file can't be forged and an older signed build can't be served as a newer one
+	message := updateSignedMessage(info.LatestVersion, releaseChannel(info.Prerelease), expected)
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), message, signature) {
+		return fmt.Errorf("invalid signature: the release was not signed with the update key for version %s", info.LatestVersion)
+	}
+	return nil
+}
+
+// updateSignedMessage is what the release signature covers: "PosApp <version> <channel> <sha256 hex>"
+func updateSignedMessage(version, channel string, digest []byte) []byte {
+	return []byte(fmt.Sprintf("PosApp %s %s %s", version, channel, hex.EncodeToString(digest)))
+}
+
+// releaseChannel returns the channel a release is published in
+func releaseChannel(prerelease bool) string {
+	if prerelease {
+		return UpdateChannelBeta
+	}
+	return UpdateChannelStable
+}
+
+// downloadSmall downloads a small text asset (checksum or signature)
+func (s *UpdateService) downloadSmall(url string) ([]byte, error) {
+	resp, err := s.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+}
+
 // ExtractUpdate extracts the downloaded update
 func (s *UpdateService) ExtractUpdate(zipPath string) (string, error) {
 	tempDir := filepath.Dir(zipPath)
@@ -203,68 +354,32 @@ func (s *UpdateService) ExtractUpdate(zipPath string) (string, error) {
 }
 
 // ApplyUpdate replaces the current executable with the new one
+// The current executable is kept as <exe>.previous so a failed update can be rolled back
 func (s *UpdateService) ApplyUpdate(newExePath string) error {
-	// Get current executable path
-	currentExe, err := os.Executable()
+	currentExe, err := currentExecutable()
 	if err != nil {
-		return fmt.Errorf("failed to get current executable path: %w", err)
-	}
-
-	// Resolve symlinks
-	currentExe, err = filepath.EvalSymlinks(currentExe)
-	if err != nil {
-		return fmt.Errorf("failed to resolve executable path: %w", err)
+		return err
 	}
 
-	// Create backup
-	backupPath := currentExe + ".backup"
-	if err := copyFile(currentExe, backupPath); err != nil {
-		return fmt.Errorf("failed to create backup: %w", err)
+	// Keep the previous binary
+	previousPath := currentExe + ".previous"
+	if err := copyFile(currentExe, previousPath); err != nil {
+		return fmt.Errorf("failed to keep previous executable: %w", err)
 	}
 
-	// On Windows, we need to rename the current exe and then copy the new one
-	if runtime.GOOS == "windows" {
-		oldPath := currentExe + ".old"
-
-		// Remove old .old file if exists
-		os.Remove(oldPath)
-
-		// Rename current exe to .old
-		if err := os.Rename(currentExe, oldPath); err != nil {
-			return fmt.Errorf("failed to rename current exe: %w", err)
-		}
-
-		// Copy new exe to current location
-		if err := copyFile(newExePath, currentExe); err != nil {
-			// Restore from backup
-			os.Rename(oldPath, currentExe)
-			return fmt.Errorf("failed to copy new exe: %w", err)
-		}
-
-		// Schedule cleanup of old file (best effort)
-		go func() {
-			time.Sleep(5 * time.Second)
-			os.Remove(oldPath)
-		}()
-	} else {
-		// On Unix-like systems, we can replace directly
-		if err := copyFile(newExePath, currentExe); err != nil {
-			// Restore from backup
-			copyFile(backupPath, currentExe)
-			return fmt.Errorf("failed to replace executable: %w", err)
-		}
-
-		// Make executable
-		if err := os.Chmod(currentExe, 0755); err != nil {
-			return fmt.Errorf("failed to set executable permissions: %w", err)
-		}
+	if err := replaceExecutable(newExePath, currentExe); err != nil {
+		return err
 	}
-
 	return nil
 }
 
 // PerformUpdate performs the complete update process
+// The download is verified before anything is installed. When installs are deferred
+// until after closing time the verified update is staged and installed later.
 func (s *UpdateService) PerformUpdate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Check for updates
 	updateInfo, err := s.CheckForUpdates()
 	if err != nil {
@@ -282,48 +397,112 @@ func (s *UpdateService) PerformUpdate() error {
 	}
 	defer os.Remove(zipPath)
 
+	// Verify checksum and signature
+	if err := s.VerifyUpdate(zipPath, updateInfo); err != nil {
+		return fmt.Errorf("update verification failed: %w", err)
+	}
+
 	// Extract update
 	newExePath, err := s.ExtractUpdate(zipPath)
 	if err != nil {
 		return fmt.Errorf("failed to extract update: %w", err)
 	}
+
+	settings := s.GetUpdateSettings()
+	if settings.DeferUntilClosing {
+		if installAfter := nextClosingTime(time.Now()); installAfter != nil {
+			return s.stageUpdate(newExePath, updateInfo.LatestVersion, updateInfo.Channel, installAfter)
+		}
+	}
+
+	return s.install(newExePath, updateInfo.LatestVersion, updateInfo.Channel)
+}
+
+// install applies a verified executable and records it for the health check of the next start
+func (s *UpdateService) install(newExePath, version, channel string) error {
 	defer os.Remove(newExePath)
 
+	// Back up the database before the new version runs its migrations
+	backup, err := BackupBeforeChange(BackupReasonPreUpdate)
+	if err != nil {
+		return err
+	}
+
 	// Apply update
 	if err := s.ApplyUpdate(newExePath); err != nil {
 		return fmt.Errorf("failed to apply update: %w", err)
 	}
 
-	return nil
+	currentExe, _ := currentExecutable()
+	now := time.Now()
+	return s.saveState(&UpdateState{
+		Status:          UpdateStatusPendingVerification,
+		Version:         version,
+		PreviousVersion: CurrentVersion,
+		Channel:         channel,
+		PreviousPath:    currentExe + ".previous",
+		PreUpdateBackup: backup,
+		AppliedAt:       &now,
+		Message:         fmt.Sprintf("Versión %s instalada, se verificará al reiniciar", version),
+	})
 }
 
 // Helper functions
 
 func compareVersions(v1, v2 string) int {
-	// Simple version comparison (assumes semantic versioning)
+	// Semantic version comparison
 	// Returns: 1 if v1 > v2, -1 if v1 < v2, 0 if equal
+	// A pre-release (3.6.0-beta.1) is older than its release (3.6.0)
 
-	parts1 := strings.Split(v1, ".")
-	parts2 := strings.Split(v2, ".")
+	core1, pre1, _ := strings.Cut(v1, "-")
+	core2, pre2, _ := strings.Cut(v2, "-")
+
+	if result := compareVersionParts(strings.Split(core1, "."), strings.Split(core2, ".")); result != 0 {
+		return result
+	}
+
+	switch {
+	case pre1 == pre2:
+		return 0
+	case pre1 == "":
+		return 1
+	case pre2 == "":
+		return -1
+	}
+	return compareVersionParts(strings.Split(pre1, "."), strings.Split(pre2, "."))
+}
 
+// compareVersionParts compares dot-separated identifiers, numerically when both are numbers
+func compareVersionParts(parts1, parts2 []string) int {
 	maxLen := len(parts1)
 	if len(parts2) > maxLen {
 		maxLen = len(parts2)
 	}
 
 	for i := 0; i < maxLen; i++ {
-		var n1, n2 int
-
+		var p1, p2 string
 		if i < len(parts1) {
-			fmt.Sscanf(parts1[i], "%d", &n1)
+			p1 = parts1[i]
 		}
 		if i < len(parts2) {
-			fmt.Sscanf(parts2[i], "%d", &n2)
+			p2 = parts2[i]
+		}
+
+		var n1, n2 int
+		_, err1 := fmt.Sscanf(p1, "%d", &n1)
+		_, err2 := fmt.Sscanf(p2, "%d", &n2)
+		if (err1 == nil || p1 == "") && (err2 == nil || p2 == "") {
+			if n1 > n2 {
+				return 1
+			} else if n1 < n2 {
+				return -1
+			}
+			continue
 		}
 
-		if n1 > n2 {
+		if p1 > p2 {
 			return 1
-		} else if n1 < n2 {
+		} else if p1 < p2 {
 			return -1
 		}
 	}
@@ -331,6 +510,73 @@ func compareVersions(v1, v2 string) int {
 	return 0
 }
 
+// fileSHA256 returns the SHA-256 digest of a file
+func fileSHA256(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return nil, fmt.Errorf("failed to hash update: %w", err)
+	}
+	return hash.Sum(nil), nil
+}
+
+// currentExecutable returns the resolved path of the running executable
+func currentExecutable() (string, error) {
+	currentExe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current executable path: %w", err)
+	}
+
+	// Resolve symlinks
+	currentExe, err = filepath.EvalSymlinks(currentExe)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+	return currentExe, nil
+}
+
+// replaceExecutable puts src in place of the executable at exePath
+// A running executable can't be overwritten (Windows locks it, Linux reports
+// "text file busy") but it can be renamed, so it is moved aside first
+func replaceExecutable(src, exePath string) error {
+	oldPath := exePath + ".old"
+
+	// Remove old .old file if exists
+	os.Remove(oldPath)
+
+	// Rename current exe to .old
+	if err := os.Rename(exePath, oldPath); err != nil {
+		return fmt.Errorf("failed to rename current exe: %w", err)
+	}
+
+	// Copy new exe to current location
+	if err := copyFile(src, exePath); err != nil {
+		// Restore the original
+		os.Remove(exePath)
+		os.Rename(oldPath, exePath)
+		return fmt.Errorf("failed to copy new exe: %w", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		// Make executable
+		if err := os.Chmod(exePath, 0755); err != nil {
+			return fmt.Errorf("failed to set executable permissions: %w", err)
+		}
+	}
+
+	// The running process may still hold the old file (best effort)
+	go func() {
+		time.Sleep(5 * time.Second)
+		os.Remove(oldPath)
+	}()
+	return nil
+}
+
 func copyFile(src, dst string) error {
 	sourceFile, err := os.Open(src)
 	if err != nil {
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		v1, v2 string
+		want   int
+	}{
+		{"3.5.0", "3.5.0", 0},
+		{"3.5.1", "3.5.0", 1},
+		{"3.5.0", "3.10.0", -1},
+		{"4.0", "3.9.9", 1},
+		{"3.5", "3.5.0", 0},
+		{"3.6.0-beta.1", "3.6.0", -1},
+		{"3.6.0", "3.6.0-beta.1", 1},
+		{"3.6.0-beta.2", "3.6.0-beta.10", -1},
+		{"3.6.0-beta.1", "3.5.9", 1},
+		{"3.6.0-alpha", "3.6.0-beta", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v1+"_vs_"+tt.v2, func(t *testing.T) {
+			if got := compareVersions(tt.v1, tt.v2); got != tt.want {
+				t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.v1, tt.v2, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyUpdateSignatureCoversVersionAndChannel(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousKey := UpdatePublicKey
+	UpdatePublicKey = base64.StdEncoding.EncodeToString(publicKey)
+	defer func() { UpdatePublicKey = previousKey }()
+
+	zipPath := filepath.Join(t.TempDir(), updateAssetName)
+	if err := os.WriteFile(zipPath, []byte("release archive"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("release archive"))
+	signature := ed25519.Sign(privateKey, updateSignedMessage("3.6.0", UpdateChannelStable, digest[:]))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sha256":
+			w.Write([]byte(hex.EncodeToString(digest[:]) + "  " + updateAssetName + "\n"))
+		case "/sig":
+			w.Write([]byte(base64.StdEncoding.EncodeToString(signature)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		version    string
+		prerelease bool
+		wantErr    bool
+	}{
+		{"signed release", "3.6.0", false, false},
+		{"signature of another version", "3.7.0", false, true},
+		{"stable signature served as beta", "3.6.0", true, true},
+	}
+	service := NewUpdateService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.VerifyUpdate(zipPath, &UpdateInfo{
+				LatestVersion: tt.version,
+				Prerelease:    tt.prerelease,
+				ChecksumURL:   server.URL + "/sha256",
+				SignatureURL:  server.URL + "/sig",
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
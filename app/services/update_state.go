@@ -0,0 +1,434 @@
+package services
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"PosApp/app/config"
+	"PosApp/app/database"
+	"PosApp/app/models"
+)
+
+// Update statuses stored in the update state file
+const (
+	UpdateStatusScheduled           = "scheduled"            // Verified and staged, installs after closing time
+	UpdateStatusPendingVerification = "pending_verification" // Installed, waiting for the health check of the first start
+	UpdateStatusHealthy             = "healthy"              // First start passed the health check
+	UpdateStatusRolledBack          = "rolled_back"          // Previous version restored
+	UpdateStatusFailed              = "failed"               // Scheduled install could not be applied
+)
+
+const (
+	// updateHealthTimeout is how long the first start of a new version has to pass the health check
+	updateHealthTimeout = 10 * time.Minute
+	// maxUpdateStartAttempts is how many starts a new version gets before it is rolled back
+	maxUpdateStartAttempts = 3
+	// updateInstallDelay leaves time for the closing tasks before a deferred install
+	updateInstallDelay = 30 * time.Minute
+)
+
+// UpdateState tracks an update across restarts
+// It lives in a file next to config.json because it must be readable before
+// (and without) the database
+type UpdateState struct {
+	Status          string     `json:"status"`
+	Version         string     `json:"version"`
+	PreviousVersion string     `json:"previous_version"`
+	Channel         string     `json:"channel"`
+	StagedPath      string     `json:"staged_path,omitempty"`
+	StagedSHA256    string     `json:"staged_sha256,omitempty"`
+	PreviousPath    string     `json:"previous_path,omitempty"`
+	PreUpdateBackup string     `json:"pre_update_backup,omitempty"` // Database backup taken before installing
+	RestorePending  bool       `json:"restore_pending,omitempty"`   // Previous version restores PreUpdateBackup on its next start
+	InstallAfter    *time.Time `json:"install_after,omitempty"`
+	AppliedAt       *time.Time `json:"applied_at,omitempty"`
+	StartAttempts   int        `json:"start_attempts"`
+	VerifiedAt      *time.Time `json:"verified_at,omitempty"`
+	RolledBackAt    *time.Time `json:"rolled_back_at,omitempty"`
+	Message         string     `json:"message"`
+}
+
+// UpdateSettings are the update preferences (stored as system configs)
+type UpdateSettings struct {
+	Channel           string `json:"channel"`             // stable or beta
+	DeferUntilClosing bool   `json:"defer_until_closing"` // Install downloaded updates after closing time
+}
+
+// updateDir returns the directory where updates are downloaded and staged
+func updateDir() (string, error) {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(filepath.Dir(configPath), "updates")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create update directory: %w", err)
+	}
+	return dir, nil
+}
+
+func updateStatePath() (string, error) {
+	dir, err := updateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "update_state.json"), nil
+}
+
+// loadUpdateState returns the stored update state (nil if there is none)
+func loadUpdateState() *UpdateState {
+	path, err := updateStatePath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var state UpdateState
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Printf("Update: ignoring unreadable state file: %v", err)
+		return nil
+	}
+	return &state
+}
+
+func (s *UpdateService) saveState(state *UpdateState) error {
+	path, err := updateStatePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// GetUpdateStatus returns the state of the last update (nil if none)
+func (s *UpdateService) GetUpdateStatus() *UpdateState {
+	return loadUpdateState()
+}
+
+// GetUpdateSettings returns the update channel and install preferences
+func (s *UpdateService) GetUpdateSettings() UpdateSettings {
+	configSvc := NewConfigService()
+	settings := UpdateSettings{
+		Channel:           UpdateChannelStable,
+		DeferUntilClosing: configSvc.GetSystemConfigBool("update_defer_until_closing", false),
+	}
+	if channel, err := configSvc.GetSystemConfig("update_channel"); err == nil && channel == UpdateChannelBeta {
+		settings.Channel = UpdateChannelBeta
+	}
+	return settings
+}
+
+// SaveUpdateSettings saves the update channel and install preferences
+func (s *UpdateService) SaveUpdateSettings(settings UpdateSettings) error {
+	if settings.Channel != UpdateChannelStable && settings.Channel != UpdateChannelBeta {
+		return fmt.Errorf("canal de actualización inválido: %s", settings.Channel)
+	}
+	configSvc := NewConfigService()
+	if err := configSvc.SetSystemConfig("update_channel", settings.Channel, "string", "update"); err != nil {
+		return err
+	}
+	return configSvc.SetSystemConfig("update_defer_until_closing", strconv.FormatBool(settings.DeferUntilClosing), "boolean", "update")
+}
+
+// nextClosingTime returns when a deferred install may run: after today's closing
+// time while the restaurant is open, nil when it is already closed
+func nextClosingTime(now time.Time) *time.Time {
+	db := database.GetDB()
+	if db == nil {
+		return nil
+	}
+	var restaurant models.RestaurantConfig
+	if err := db.First(&restaurant).Error; err != nil {
+		return nil
+	}
+	opening, err1 := time.Parse("15:04", restaurant.OpeningTime)
+	closing, err2 := time.Parse("15:04", restaurant.ClosingTime)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+
+	today := func(t time.Time) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	}
+	openAt, closeAt := today(opening), today(closing)
+
+	var installAt time.Time
+	if closeAt.After(openAt) {
+		// Same-day hours, e.g. 08:00-22:00
+		if now.Before(openAt) || !now.Before(closeAt) {
+			return nil
+		}
+		installAt = closeAt
+	} else {
+		// Hours past midnight, e.g. 16:00-02:00
+		switch {
+		case now.Before(closeAt):
+			installAt = closeAt
+		case !now.Before(openAt):
+			installAt = closeAt.AddDate(0, 0, 1)
+		default:
+			return nil
+		}
+	}
+	installAt = installAt.Add(updateInstallDelay)
+	return &installAt
+}
+
+// stageUpdate records a verified executable to be installed after closing time
+func (s *UpdateService) stageUpdate(newExePath, version, channel string, installAfter *time.Time) error {
+	digest, err := fileSHA256(newExePath)
+	if err != nil {
+		return err
+	}
+	return s.saveState(&UpdateState{
+		Status:          UpdateStatusScheduled,
+		Version:         version,
+		PreviousVersion: CurrentVersion,
+		Channel:         channel,
+		StagedPath:      newExePath,
+		StagedSHA256:    hex.EncodeToString(digest),
+		InstallAfter:    installAfter,
+		Message:         fmt.Sprintf("La versión %s se instalará después del cierre (%s)", version, installAfter.Format("2006-01-02 15:04")),
+	})
+}
+
+// CancelScheduledUpdate discards an update waiting for closing time
+func (s *UpdateService) CancelScheduledUpdate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := loadUpdateState()
+	if state == nil || state.Status != UpdateStatusScheduled {
+		return fmt.Errorf("no hay actualizaciones programadas")
+	}
+	os.Remove(state.StagedPath)
+	state.Status = UpdateStatusFailed
+	state.Message = fmt.Sprintf("Instalación de la versión %s cancelada", state.Version)
+	return s.saveState(state)
+}
+
+// StartDeferredInstaller installs staged updates once their install time has passed
+func (s *UpdateService) StartDeferredInstaller() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			s.installScheduled()
+			<-ticker.C
+		}
+	}()
+}
+
+func (s *UpdateService) installScheduled() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := loadUpdateState()
+	if state == nil || state.Status != UpdateStatusScheduled || state.InstallAfter == nil || time.Now().Before(*state.InstallAfter) {
+		return
+	}
+
+	// The staged file sat on disk for hours, check it is still the verified one
+	digest, err := fileSHA256(state.StagedPath)
+	if err != nil || hex.EncodeToString(digest) != state.StagedSHA256 {
+		os.Remove(state.StagedPath)
+		state.Status = UpdateStatusFailed
+		state.Message = fmt.Sprintf("La actualización %s descargada no es válida, descárguela de nuevo", state.Version)
+		s.saveState(state)
+		log.Printf("Update: staged update %s failed verification", state.Version)
+		return
+	}
+
+	if err := s.install(state.StagedPath, state.Version, state.Channel); err != nil {
+		state.Status = UpdateStatusFailed
+		state.Message = fmt.Sprintf("No se pudo instalar la versión %s: %v", state.Version, err)
+		s.saveState(state)
+		log.Printf("Update: deferred install of %s failed: %v", state.Version, err)
+		return
+	}
+	log.Printf("Update: version %s installed after closing time, it runs on the next start", state.Version)
+}
+
+// StartHealthCheck is called first thing when the application starts. On the first starts
+// of a newly installed version it counts the attempt and arms the health check timeout;
+// after too many starts without passing the check the previous version is restored.
+// Returns true when this start has to pass the health check.
+// A build that dies before main runs (missing DLL, panic in a package init) never counts
+// an attempt and can't roll itself back: the previous executable is kept next to it as
+// <exe>.previous to be put back by hand.
+func (s *UpdateService) StartHealthCheck() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := loadUpdateState()
+	if state == nil || state.Status != UpdateStatusPendingVerification || state.Version != CurrentVersion {
+		return false
+	}
+
+	state.StartAttempts++
+	if state.StartAttempts > maxUpdateStartAttempts {
+		s.rollback(state, fmt.Sprintf("la versión %s no completó el arranque en %d intentos", state.Version, maxUpdateStartAttempts), true, true)
+		return false
+	}
+	s.saveState(state)
+
+	s.healthPending = true
+	s.healthDone = make(chan struct{})
+	go func(done chan struct{}) {
+		select {
+		case <-done:
+		case <-time.After(updateHealthTimeout):
+			s.FailHealthCheck("la aplicación no completó el arranque a tiempo")
+		}
+	}(s.healthDone)
+	return true
+}
+
+// CompleteHealthCheck runs the health check of the first start after an update
+// The update is confirmed if check passes, otherwise the previous version is restored
+func (s *UpdateService) CompleteHealthCheck(check func() error) {
+	s.mu.Lock()
+	pending := s.healthPending
+	s.mu.Unlock()
+	if !pending {
+		return
+	}
+
+	if err := check(); err != nil {
+		s.FailHealthCheck(err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.healthPending {
+		return
+	}
+	s.healthPending = false
+	close(s.healthDone)
+
+	state := loadUpdateState()
+	if state == nil || state.Status != UpdateStatusPendingVerification {
+		return
+	}
+	now := time.Now()
+	state.Status = UpdateStatusHealthy
+	state.VerifiedAt = &now
+	state.Message = fmt.Sprintf("Versión %s verificada correctamente", state.Version)
+	s.saveState(state)
+	log.Printf("Update: version %s passed the health check", state.Version)
+}
+
+// FailHealthCheck restores the previous version and restarts it
+// It does nothing unless this start is the health check of a new version
+func (s *UpdateService) FailHealthCheck(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.healthPending {
+		return
+	}
+	s.healthPending = false
+	close(s.healthDone)
+
+	if state := loadUpdateState(); state != nil && state.Status == UpdateStatusPendingVerification {
+		s.rollback(state, reason, true, true)
+	}
+}
+
+// RollbackUpdate restores the version that was running before the last update
+// The restored version runs after restarting the application. With restoreData it also
+// restores the database backup taken before the update, which undoes everything recorded
+// since then; it is needed when the new version migrated the database in a way the
+// previous version can't work with.
+func (s *UpdateService) RollbackUpdate(restoreData bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := loadUpdateState()
+	if state == nil || (state.Status != UpdateStatusPendingVerification && state.Status != UpdateStatusHealthy) {
+		return fmt.Errorf("no hay una actualización para revertir")
+	}
+	if restoreData && state.PreUpdateBackup == "" {
+		return fmt.Errorf("la actualización no tiene copia de seguridad previa para restaurar")
+	}
+	return s.rollback(state, "reversión manual", false, restoreData)
+}
+
+// RestoreRollbackBackup restores the pre-update backup after a rollback that asked for it
+// It runs in the restored version once the database is connected, so the data is loaded
+// with the schema that version expects
+func (s *UpdateService) RestoreRollbackBackup() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := loadUpdateState()
+	if state == nil || state.Status != UpdateStatusRolledBack || !state.RestorePending || state.PreviousVersion != CurrentVersion {
+		return nil
+	}
+
+	// Only one try: a backup that doesn't restore must not block every start
+	state.RestorePending = false
+	check, err := NewBackupService().RestoreBackup(state.PreUpdateBackup)
+	if err != nil {
+		state.Message = fmt.Sprintf("%s. No se pudo restaurar la copia %s: %v, restáurela desde Copias de seguridad", state.Message, state.PreUpdateBackup, err)
+	} else {
+		state.Message = fmt.Sprintf("%s. Datos restaurados de la copia %s (copia de los datos anteriores: %s)", state.Message, state.PreUpdateBackup, check.SafetyCopy)
+		log.Printf("Update: restored backup %s after rolling back %s", state.PreUpdateBackup, state.Version)
+	}
+	if saveErr := s.saveState(state); saveErr != nil {
+		log.Printf("Update: could not save rollback state: %v", saveErr)
+	}
+	return err
+}
+
+// rollback puts the previous executable back in place
+// With restoreData the previous version restores the pre-update backup when it starts
+func (s *UpdateService) rollback(state *UpdateState, reason string, restart, restoreData bool) error {
+	log.Printf("Update: rolling back version %s: %s", state.Version, reason)
+
+	if state.PreviousPath == "" {
+		return fmt.Errorf("previous executable not recorded")
+	}
+	if _, err := os.Stat(state.PreviousPath); err != nil {
+		return fmt.Errorf("previous executable not found: %w", err)
+	}
+	currentExe, err := currentExecutable()
+	if err != nil {
+		return err
+	}
+	if err := replaceExecutable(state.PreviousPath, currentExe); err != nil {
+		log.Printf("Update: rollback failed: %v", err)
+		return err
+	}
+
+	now := time.Now()
+	state.Status = UpdateStatusRolledBack
+	state.RolledBackAt = &now
+	state.RestorePending = restoreData && state.PreUpdateBackup != ""
+	state.Message = fmt.Sprintf("Se restauró la versión %s: %s", state.PreviousVersion, reason)
+	if err := s.saveState(state); err != nil {
+		log.Printf("Update: could not save rollback state: %v", err)
+	}
+
+	if restart {
+		cmd := exec.Command(currentExe, os.Args[1:]...)
+		if err := cmd.Start(); err != nil {
+			log.Printf("Update: could not restart previous version: %v", err)
+		}
+		os.Exit(1)
+	}
+	return nil
+}
@@ -17,6 +17,8 @@ type OrderCreator interface {
 	CreateOrder(order *models.Order) (*models.Order, error)
 	SendToKitchen(orderID uint) error
 	UpdateOrderStatus(orderID uint, status models.OrderStatus) error
+	UpdateOrderStatusWithContext(orderID uint, status models.OrderStatus, change models.OrderStatusChange) error
+	UpdateOrderWithStatusEvent(order *models.Order, previousStatus models.OrderStatus, change models.OrderStatusChange) error
 }
 
 // RESTHandlers provides HTTP REST endpoints for mobile apps
@@ -743,21 +745,8 @@ func (h *RESTHandlers) HandleUpdateOrder(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	// Delete old modifiers first (to avoid foreign key constraint violation)
-	if err := h.db.Exec("DELETE FROM order_item_modifiers WHERE order_item_id IN (SELECT id FROM order_items WHERE order_id = ?)", orderID).Error; err != nil {
-		log.Printf("REST API: Error deleting old modifiers: %v", err)
-		http.Error(w, "Error updating order", http.StatusInternalServerError)
-		return
-	}
-
-	// Delete old items
-	if err := h.db.Where("order_id = ?", orderID).Delete(&models.OrderItem{}).Error; err != nil {
-		log.Printf("REST API: Error deleting old items: %v", err)
-		http.Error(w, "Error updating order", http.StatusInternalServerError)
-		return
-	}
-
 	// Update order fields
+	previousStatus := existingOrder.Status
 	existingOrder.Type = orderReq.Type
 	existingOrder.Status = models.OrderStatus(orderReq.Status)
 	existingOrder.TableID = orderReq.TableID
@@ -806,8 +795,23 @@ func (h *RESTHandlers) HandleUpdateOrder(w http.ResponseWriter, r *http.Request,
 		existingOrder.Items = append(existingOrder.Items, item)
 	}
 
-	// Save updated order
-	if err := h.db.Save(&existingOrder).Error; err != nil {
+	// The change is attributed to the employee sent by the waiter app; older apps
+	// send none or an unknown one, and the change is recorded without employee
+	change := models.OrderStatusChange{
+		Actor:  r.RemoteAddr,
+		Source: models.OrderStatusSourceWaiter,
+	}
+	if orderReq.EmployeeID > 0 {
+		var employee models.Employee
+		if err := h.db.Select("id").First(&employee, orderReq.EmployeeID).Error; err == nil {
+			change.EmployeeID = &employee.ID
+		} else {
+			log.Printf("REST API: Employee %d not found, recording the change without employee", orderReq.EmployeeID)
+		}
+	}
+
+	// Replace items and record the status transition in a single transaction
+	if err := h.orderService.UpdateOrderWithStatusEvent(&existingOrder, previousStatus, change); err != nil {
 		log.Printf("REST API: Error updating order: %v", err)
 		http.Error(w, "Error updating order", http.StatusInternalServerError)
 		return
@@ -32,6 +32,9 @@ const (
 	TypeKitchenAck      MessageType = "kitchen_ack"      // Kitchen acknowledges order receipt
 	TypeKitchenAckResult MessageType = "kitchen_ack_result" // Result broadcast to source apps
 	TypePrintReceipt    MessageType = "print_receipt"    // Waiter App print request
+	TypeKitchenMetrics  MessageType = "kitchen_metrics"  // Live kitchen ticket timing (oldest open ticket)
+	TypeReservationUpdate MessageType = "reservation_update" // Reservation created/updated/held/seated
+	TypeWaitlistUpdate    MessageType = "waitlist_update"    // Walk-in waitlist changed
 	TypeNotification    MessageType = "notification"
 	TypeHeartbeat       MessageType = "heartbeat"
 	TypeAuthenticate    MessageType = "authenticate"
@@ -573,7 +576,11 @@ func (c *Client) handleKitchenUpdate(message *Message) {
 		}
 
 		// Update the order status in the database
-		if err := c.Server.orderService.UpdateOrderStatus(uint(orderID), status); err != nil {
+		change := models.OrderStatusChange{
+			Source: models.OrderStatusSourceKitchen,
+			Actor:  c.ID,
+		}
+		if err := c.Server.orderService.UpdateOrderStatusWithContext(uint(orderID), status, change); err != nil {
 			log.Printf("Error updating order status: %v", err)
 		} else {
 			log.Printf("Order %d status updated to %s", orderID, status)
@@ -638,7 +645,14 @@ func (c *Client) handleOrderUpdate(message *Message) {
 		}
 
 		// Update the order status in the database
-		if err := c.Server.orderService.UpdateOrderStatus(orderID, orderStatus); err != nil {
+		change := models.OrderStatusChange{
+			Source: clientStatusSource(c.Type),
+			Actor:  c.ID,
+		}
+		if reason, ok := updateData["reason"].(string); ok {
+			change.Reason = reason
+		}
+		if err := c.Server.orderService.UpdateOrderStatusWithContext(orderID, orderStatus, change); err != nil {
 			log.Printf("Error updating order status: %v", err)
 		} else {
 			log.Printf("Order %d status updated to %s", orderID, status)
@@ -903,6 +917,39 @@ func (s *Server) SendKitchenOrder(orderData interface{}) {
 	s.broadcastToKitchen(&message)
 }
 
+// SendKitchenMetrics sends live kitchen timing data to kitchen displays
+func (s *Server) SendKitchenMetrics(metrics interface{}) {
+	dataBytes, _ := json.Marshal(metrics)
+
+	message := Message{
+		Type:      TypeKitchenMetrics,
+		Timestamp: time.Now(),
+		Data:      json.RawMessage(dataBytes),
+	}
+
+	s.broadcastToKitchen(&message)
+}
+
+// SendReservationEvent sends a reservation or waitlist event to POS and waiter apps
+func (s *Server) SendReservationEvent(messageType MessageType, event string, payload interface{}) {
+	data := map[string]interface{}{
+		"event": event,
+		"data":  payload,
+		"time":  time.Now(),
+	}
+
+	dataBytes, _ := json.Marshal(data)
+
+	message := Message{
+		Type:      messageType,
+		Timestamp: time.Now(),
+		Data:      json.RawMessage(dataBytes),
+	}
+
+	s.broadcastToPOS(&message)
+	s.broadcastToWaiters(&message)
+}
+
 // SendTableUpdate sends table status update
 func (s *Server) SendTableUpdate(tableID uint, status string) {
 	data := map[string]interface{}{
@@ -998,6 +1045,18 @@ func (s *Server) DisconnectClient(clientID string) error {
 
 // Helper functions
 
+// clientStatusSource maps a client type to an order status change source
+func clientStatusSource(clientType ClientType) string {
+	switch clientType {
+	case ClientKitchen:
+		return models.OrderStatusSourceKitchen
+	case ClientWaiter:
+		return models.OrderStatusSourceWaiter
+	default:
+		return models.OrderStatusSourcePOS
+	}
+}
+
 func generateClientID() string {
 	return fmt.Sprintf("%d-%d", time.Now().Unix(), time.Now().Nanosecond())
 }
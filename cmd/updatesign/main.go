@@ -0,0 +1,100 @@
+// Command updatesign creates the update signing key and signs release archives.
+//
+//	go run ./cmd/updatesign keygen                 prints a new key pair
+//	go run ./cmd/updatesign sign PosApp.zip 3.6.0  writes PosApp.zip.sha256 and PosApp.zip.sig
+//
+// The signature covers "PosApp <version> <channel> <sha256 hex>", the channel being beta for
+// pre-release versions (3.6.0-beta.1) and stable otherwise, so a signed archive can't be
+// republished as another version or channel.
+//
+// sign reads the base64 private key from the UPDATE_SIGNING_KEY environment variable.
+// The public key is bundled in the application at build time with
+// -ldflags "-X PosApp/app/services.UpdatePublicKey=<public key>"
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "keygen":
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Println("UPDATE_PUBLIC_KEY=" + base64.StdEncoding.EncodeToString(publicKey))
+		fmt.Println("UPDATE_SIGNING_KEY=" + base64.StdEncoding.EncodeToString(privateKey))
+
+	case "sign":
+		if len(os.Args) != 4 {
+			usage()
+		}
+		if err := sign(os.Args[2], strings.TrimPrefix(os.Args[3], "v")); err != nil {
+			fail(err)
+		}
+
+	default:
+		usage()
+	}
+}
+
+// sign writes the sha256sum-style checksum of the archive and the signature of its digest,
+// version and channel
+func sign(path, version string) error {
+	privateKey, err := base64.StdEncoding.DecodeString(os.Getenv("UPDATE_SIGNING_KEY"))
+	if err != nil || len(privateKey) != ed25519.PrivateKeySize {
+		return fmt.Errorf("UPDATE_SIGNING_KEY is missing or invalid")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+	digest := hash.Sum(nil)
+
+	checksum := fmt.Sprintf("%s  %s\n", hex.EncodeToString(digest), filepath.Base(path))
+	if err := os.WriteFile(path+".sha256", []byte(checksum), 0644); err != nil {
+		return err
+	}
+	channel := "stable"
+	if strings.Contains(version, "-") {
+		channel = "beta"
+	}
+	message := fmt.Sprintf("PosApp %s %s %s", version, channel, hex.EncodeToString(digest))
+	signature := ed25519.Sign(ed25519.PrivateKey(privateKey), []byte(message))
+	if err := os.WriteFile(path+".sig", []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Signed %s %s (%s, sha256 %s)\n", path, version, channel, hex.EncodeToString(digest))
+	return nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: updatesign keygen | updatesign sign <archive> <version>")
+	os.Exit(2)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "updatesign:", err)
+	os.Exit(1)
+}
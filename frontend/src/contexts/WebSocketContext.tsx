@@ -191,6 +191,40 @@ export const WebSocketProvider: React.FC<{ children: React.ReactNode }> = ({ chi
         // Handle table status updates silently
         break;
 
+      case 'printer_status_changed': {
+        const { printer_name, message: statusMessage, status, blocked } = message.data;
+        if (blocked) {
+          playNotificationSound();
+          toast.error(`🖨️ ${printer_name}: ${statusMessage}`, {
+            position: 'top-center',
+            autoClose: false,
+          });
+          addNotification({
+            type: 'error',
+            title: 'Impresora con problemas',
+            message: `${printer_name}: ${statusMessage}. Las comandas se retienen o se envían a otra impresora.`,
+            action: {
+              label: 'Ver Impresoras',
+              path: '/settings',
+            },
+          });
+        } else if (status === 'paper_near_end') {
+          toast.warning(`🖨️ ${printer_name}: ${statusMessage}`, {
+            position: 'bottom-right',
+          });
+          addNotification({
+            type: 'warning',
+            title: 'Papel por agotarse',
+            message: `${printer_name}: ${statusMessage}`,
+          });
+        } else {
+          toast.success(`🖨️ ${printer_name}: ${statusMessage}`, {
+            position: 'bottom-right',
+          });
+        }
+        break;
+      }
+
       case 'notification':
         // Check if this is a PWA order notification
         if (message.data.type === 'pwa_order') {
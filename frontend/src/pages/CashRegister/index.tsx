@@ -45,6 +45,8 @@ import {
   History as HistoryIcon,
   Description as DIANReportIcon,
   Edit as EditIcon,
+  PictureAsPdf as PdfIcon,
+  Email as EmailIcon,
 } from '@mui/icons-material';
 import { format, startOfWeek, endOfWeek, startOfMonth, endOfMonth, startOfYear, endOfYear } from 'date-fns';
 import { es } from 'date-fns/locale';
@@ -53,6 +55,8 @@ import { useAuth, useDIANMode } from '../../hooks';
 import { wailsAuthService } from '../../services/wailsAuthService';
 import { wailsSalesService, DIANClosingReport } from '../../services/wailsSalesService';
 import { wailsConfigService } from '../../services/wailsConfigService';
+import { wailsPdfService, PDFDocumentFile } from '../../services/wailsPdfService';
+import { wailsMailService } from '../../services/wailsMailService';
 import { toast } from 'react-toastify';
 
 interface CashRegisterStatus {
@@ -110,6 +114,8 @@ const CashRegister: React.FC = () => {
   const [dianReport, setDianReport] = useState<DIANClosingReport | null>(null);
   const [loadingDianReport, setLoadingDianReport] = useState(false);
   const [printingDianReport, setPrintingDianReport] = useState(false);
+  const [exportingDianPdf, setExportingDianPdf] = useState(false);
+  const [emailingDianReport, setEmailingDianReport] = useState(false);
   // For custom date range
   const [customStartDate, setCustomStartDate] = useState(format(new Date(), 'yyyy-MM-dd'));
   const [customEndDate, setCustomEndDate] = useState(format(new Date(), 'yyyy-MM-dd'));
@@ -405,6 +411,51 @@ const CashRegister: React.FC = () => {
     }
   };
 
+  const downloadPdf = (file: PDFDocumentFile) => {
+    wailsPdfService.download(file);
+    toast.success(file.path ? `PDF guardado en ${file.path}` : 'PDF generado');
+  };
+
+  const handleDownloadDianReportPDF = async () => {
+    try {
+      setExportingDianPdf(true);
+      if (dianReportPeriod === 'custom') {
+        downloadPdf(await wailsPdfService.generateDIANClosingReportPDF(customStartDate, 'custom', customEndDate));
+      } else {
+        downloadPdf(await wailsPdfService.generateDIANClosingReportPDF(getReportDate(), dianReportPeriod));
+      }
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al generar PDF del reporte DIAN');
+    } finally {
+      setExportingDianPdf(false);
+    }
+  };
+
+  const handleEmailDianReport = async () => {
+    try {
+      setEmailingDianReport(true);
+      const message = dianReportPeriod === 'custom'
+        ? await wailsMailService.sendDIANClosingReportEmail(customStartDate, 'custom', customEndDate)
+        : await wailsMailService.sendDIANClosingReportEmail(getReportDate(), dianReportPeriod);
+      toast.success(`Reporte en cola de envío para ${message.to}`);
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al enviar el reporte DIAN por correo');
+    } finally {
+      setEmailingDianReport(false);
+    }
+  };
+
+  const handleDownloadNotePDF = async (type: 'credit' | 'debit', noteId: number) => {
+    try {
+      const file = type === 'credit'
+        ? await wailsPdfService.generateCreditNotePDF(noteId)
+        : await wailsPdfService.generateDebitNotePDF(noteId);
+      downloadPdf(file);
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al generar PDF de la nota');
+    }
+  };
+
   const handlePrintDianReport = async () => {
     try {
       setPrintingDianReport(true);
@@ -1289,6 +1340,9 @@ const CashRegister: React.FC = () => {
                       {dianReport.credit_notes.map((cn, idx) => (
                         <Typography key={idx} variant="body2">
                           {cn.prefix}{cn.number}: -{formatCurrency(cn.amount)} ({cn.reason})
+                          <IconButton size="small" title="Descargar PDF" onClick={() => handleDownloadNotePDF('credit', cn.id)}>
+                            <PdfIcon fontSize="small" />
+                          </IconButton>
                         </Typography>
                       ))}
                       <Typography variant="body2" fontWeight="bold" color="error.main" sx={{ mb: 1 }}>
@@ -1302,6 +1356,9 @@ const CashRegister: React.FC = () => {
                       {dianReport.debit_notes.map((dn, idx) => (
                         <Typography key={idx} variant="body2">
                           {dn.prefix}{dn.number}: +{formatCurrency(dn.amount)} ({dn.reason})
+                          <IconButton size="small" title="Descargar PDF" onClick={() => handleDownloadNotePDF('debit', dn.id)}>
+                            <PdfIcon fontSize="small" />
+                          </IconButton>
                         </Typography>
                       ))}
                       <Typography variant="body2" fontWeight="bold" color="success.main">
@@ -1406,6 +1463,22 @@ const CashRegister: React.FC = () => {
         </DialogContent>
         <DialogActions>
           <Button onClick={() => setDianReportDialog(false)}>Cerrar</Button>
+          <Button
+            variant="outlined"
+            startIcon={exportingDianPdf ? <CircularProgress size={20} /> : <PdfIcon />}
+            onClick={handleDownloadDianReportPDF}
+            disabled={!dianReport || exportingDianPdf}
+          >
+            Descargar PDF
+          </Button>
+          <Button
+            variant="outlined"
+            startIcon={emailingDianReport ? <CircularProgress size={20} /> : <EmailIcon />}
+            onClick={handleEmailDianReport}
+            disabled={!dianReport || emailingDianReport}
+          >
+            Enviar por Correo
+          </Button>
           <Button
             variant="contained"
             color="primary"
@@ -22,14 +22,18 @@ import {
   Grid,
   Chip,
   CircularProgress,
+  Button,
 } from '@mui/material';
 import {
   ExpandMore as ExpandMoreIcon,
   History as HistoryIcon,
   AttachMoney as MoneyIcon,
+  PictureAsPdf as PdfIcon,
 } from '@mui/icons-material';
 import { format } from 'date-fns';
 import { wailsAuthService } from '../../services/wailsAuthService';
+import { wailsPdfService } from '../../services/wailsPdfService';
+import { toast } from 'react-toastify';
 import { useDIANMode } from '../../hooks';
 
 interface CashMovement {
@@ -117,6 +121,16 @@ const CashRegisterHistory: React.FC = () => {
     setExpandedId(expandedId === registerId ? null : registerId);
   };
 
+  const handleDownloadPDF = async (registerId: number) => {
+    try {
+      const file = await wailsPdfService.generateCashRegisterClosingPDF(registerId);
+      wailsPdfService.download(file);
+      toast.success(file.path ? `PDF guardado en ${file.path}` : 'PDF generado');
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al generar PDF del cierre');
+    }
+  };
+
   if (loading) {
     return (
       <Box sx={{ display: 'flex', justifyContent: 'center', alignItems: 'center', minHeight: '60vh' }}>
@@ -200,6 +214,18 @@ const CashRegisterHistory: React.FC = () => {
               </AccordionSummary>
 
               <AccordionDetails>
+                {register.closed_at && (
+                  <Box sx={{ display: 'flex', justifyContent: 'flex-end', mb: 2 }}>
+                    <Button
+                      variant="outlined"
+                      size="small"
+                      startIcon={<PdfIcon />}
+                      onClick={() => handleDownloadPDF(register.id)}
+                    >
+                      Descargar PDF del Cierre
+                    </Button>
+                  </Box>
+                )}
                 <Grid container spacing={3}>
                   {/* Left Column - Balance Summary */}
                   <Grid item xs={12} md={6}>
@@ -0,0 +1,690 @@
+import React, { useState, useEffect } from 'react';
+import {
+  Box,
+  Paper,
+  Typography,
+  TextField,
+  Button,
+  Table,
+  TableBody,
+  TableCell,
+  TableContainer,
+  TableHead,
+  TableRow,
+  Chip,
+  IconButton,
+  Dialog,
+  DialogTitle,
+  DialogContent,
+  DialogActions,
+  Grid,
+  FormControl,
+  FormControlLabel,
+  InputLabel,
+  Select,
+  MenuItem,
+  Switch,
+  Stack,
+  Tooltip,
+  Alert,
+} from '@mui/material';
+import {
+  Add as AddIcon,
+  Delete as DeleteIcon,
+  Edit as EditIcon,
+  Send as SendIcon,
+  Undo as UndoIcon,
+} from '@mui/icons-material';
+import { wailsPurchaseService } from '../../services/wailsPurchaseService';
+import { wailsIngredientService } from '../../services/wailsIngredientService';
+import { wailsProductService } from '../../services/wailsProductService';
+import { useAuth } from '../../hooks';
+import {
+  Supplier,
+  InventoryPurchase,
+  InventoryPurchaseItem,
+  PurchaseWithholding,
+  Ingredient,
+  Product,
+} from '../../types/models';
+import { toast } from 'react-toastify';
+
+const emptySupplier: Supplier = {
+  name: '',
+  identification_type: 'CC',
+  identification_number: '',
+  email: '',
+  phone: '',
+  address: '',
+  issues_invoices: false,
+  is_active: true,
+};
+
+const emptyItem: InventoryPurchaseItem = {
+  description: '',
+  quantity: 1,
+  unit_price: 0,
+  tax_percent: 0,
+};
+
+const withholdingLabels: { [key: number]: string } = {
+  5: 'ReteIVA',
+  6: 'ReteRenta',
+  7: 'ReteICA',
+};
+
+const getSupportDocumentChip = (purchase: InventoryPurchase) => {
+  if (!purchase.needs_support_document) {
+    return <Chip label="Factura proveedor" size="small" />;
+  }
+  const doc = purchase.support_document;
+  if (!doc) {
+    return <Chip label="Pendiente" color="warning" size="small" />;
+  }
+  const labels: { [key: string]: { label: string; color: 'success' | 'error' | 'warning' | 'info' } } = {
+    accepted: { label: 'Aceptado', color: 'success' },
+    sent: { label: 'Enviado', color: 'info' },
+    validating: { label: 'Validando', color: 'info' },
+    rejected: { label: 'Rechazado', color: 'error' },
+    error: { label: 'Error', color: 'error' },
+  };
+  const status = labels[doc.status] || { label: doc.status, color: 'info' };
+  return (
+    <Tooltip title={doc.last_error || doc.validation_message || ''}>
+      <Chip label={`${doc.prefix}${doc.number} - ${status.label}`} color={status.color} size="small" />
+    </Tooltip>
+  );
+};
+
+const PurchasesPanel: React.FC = () => {
+  const { user } = useAuth();
+  const [suppliers, setSuppliers] = useState<Supplier[]>([]);
+  const [purchases, setPurchases] = useState<InventoryPurchase[]>([]);
+  const [ingredients, setIngredients] = useState<Ingredient[]>([]);
+  const [products, setProducts] = useState<Product[]>([]);
+
+  // Supplier dialog
+  const [supplierDialogOpen, setSupplierDialogOpen] = useState(false);
+  const [editingSupplier, setEditingSupplier] = useState<Supplier>(emptySupplier);
+
+  // Purchase dialog
+  const [purchaseDialogOpen, setPurchaseDialogOpen] = useState(false);
+  const [supplierId, setSupplierId] = useState<number | ''>('');
+  const [supplierInvoiceNumber, setSupplierInvoiceNumber] = useState('');
+  const [notes, setNotes] = useState('');
+  const [items, setItems] = useState<InventoryPurchaseItem[]>([{ ...emptyItem }]);
+  const [withholdings, setWithholdings] = useState<PurchaseWithholding[]>([]);
+  const [saving, setSaving] = useState(false);
+
+  // Adjustment note dialog
+  const [adjustmentPurchase, setAdjustmentPurchase] = useState<InventoryPurchase | null>(null);
+  const [adjustmentReason, setAdjustmentReason] = useState('');
+
+  useEffect(() => {
+    loadData();
+  }, []);
+
+  const loadData = async () => {
+    const [supplierData, purchaseData, ingredientData, productData] = await Promise.all([
+      wailsPurchaseService.getSuppliers(),
+      wailsPurchaseService.getPurchases(),
+      wailsIngredientService.getIngredients().catch(() => [] as Ingredient[]),
+      wailsProductService.getProducts().catch(() => [] as Product[]),
+    ]);
+    setSuppliers(supplierData);
+    setPurchases(purchaseData);
+    setIngredients(ingredientData);
+    setProducts(productData);
+  };
+
+  const selectedSupplier = suppliers.find((s) => s.id === supplierId);
+
+  const handleSaveSupplier = async () => {
+    try {
+      if (editingSupplier.id) {
+        await wailsPurchaseService.updateSupplier(editingSupplier);
+      } else {
+        await wailsPurchaseService.createSupplier(editingSupplier);
+      }
+      toast.success('Proveedor guardado');
+      setSupplierDialogOpen(false);
+      loadData();
+    } catch (error: any) {
+      toast.error(error.message);
+    }
+  };
+
+  const handleDeleteSupplier = async (supplier: Supplier) => {
+    if (!supplier.id || !window.confirm(`¿Eliminar el proveedor ${supplier.name}?`)) return;
+    try {
+      await wailsPurchaseService.deleteSupplier(supplier.id);
+      loadData();
+    } catch (error: any) {
+      toast.error(error.message);
+    }
+  };
+
+  const handleOpenPurchaseDialog = () => {
+    setSupplierId('');
+    setSupplierInvoiceNumber('');
+    setNotes('');
+    setItems([{ ...emptyItem }]);
+    setWithholdings([]);
+    setPurchaseDialogOpen(true);
+  };
+
+  const updateItem = (index: number, changes: Partial<InventoryPurchaseItem>) => {
+    setItems(items.map((item, i) => (i === index ? { ...item, ...changes } : item)));
+  };
+
+  const handleItemSourceChange = (index: number, value: string) => {
+    if (value.startsWith('i-')) {
+      const ingredient = ingredients.find((ing) => ing.id === Number(value.slice(2)));
+      updateItem(index, { ingredient_id: ingredient?.id, product_id: undefined, description: ingredient?.name || '' });
+    } else if (value.startsWith('p-')) {
+      const product = products.find((p) => p.id === Number(value.slice(2)));
+      updateItem(index, { product_id: product?.id, ingredient_id: undefined, description: product?.name || '' });
+    } else {
+      updateItem(index, { ingredient_id: undefined, product_id: undefined });
+    }
+  };
+
+  const subtotal = items.reduce((sum, item) => sum + item.quantity * item.unit_price, 0);
+  const tax = items.reduce((sum, item) => sum + (item.quantity * item.unit_price * item.tax_percent) / 100, 0);
+  const withholdingTotal = withholdings.reduce(
+    (sum, w) => sum + ((w.tax_id === 5 ? tax : subtotal) * w.percent) / 100,
+    0
+  );
+
+  const handleSavePurchase = async () => {
+    if (!supplierId) {
+      toast.error('Seleccione un proveedor');
+      return;
+    }
+    setSaving(true);
+    try {
+      const purchase = await wailsPurchaseService.createPurchase(
+        {
+          supplier_id: supplierId,
+          supplier_invoice_number: supplierInvoiceNumber,
+          notes,
+          items,
+          withholdings,
+        },
+        user?.id || 0
+      );
+      const doc = purchase.support_document;
+      if (purchase.needs_support_document && (!doc || doc.status === 'error')) {
+        toast.warning(`Compra registrada, pero el documento soporte falló: ${doc?.last_error || 'no configurado'}`);
+      } else {
+        toast.success('Compra registrada');
+      }
+      setPurchaseDialogOpen(false);
+      loadData();
+    } catch (error: any) {
+      toast.error(error.message);
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const handleIssueSupportDocument = async (purchase: InventoryPurchase) => {
+    try {
+      await wailsPurchaseService.issueSupportDocument(purchase.id!);
+      toast.success('Documento soporte enviado');
+    } catch (error: any) {
+      toast.error(error.message);
+    }
+    loadData();
+  };
+
+  const handleSendAdjustment = async () => {
+    if (!adjustmentPurchase) return;
+    try {
+      await wailsPurchaseService.createSupportDocumentAdjustment(adjustmentPurchase.id!, adjustmentReason, 2);
+      toast.success('Nota de ajuste enviada');
+      setAdjustmentPurchase(null);
+      loadData();
+    } catch (error: any) {
+      toast.error(error.message);
+    }
+  };
+
+  return (
+    <Box>
+      {/* Suppliers */}
+      <Paper sx={{ p: 2, mb: 3 }}>
+        <Box sx={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', mb: 2 }}>
+          <Typography variant="h6">Proveedores</Typography>
+          <Button
+            startIcon={<AddIcon />}
+            onClick={() => {
+              setEditingSupplier({ ...emptySupplier });
+              setSupplierDialogOpen(true);
+            }}
+          >
+            Nuevo Proveedor
+          </Button>
+        </Box>
+        <Table size="small">
+          <TableHead>
+            <TableRow>
+              <TableCell>Nombre</TableCell>
+              <TableCell>Identificación</TableCell>
+              <TableCell>Teléfono</TableCell>
+              <TableCell>Facturación</TableCell>
+              <TableCell align="center">Acciones</TableCell>
+            </TableRow>
+          </TableHead>
+          <TableBody>
+            {suppliers.map((supplier) => (
+              <TableRow key={supplier.id} hover>
+                <TableCell>{supplier.name}</TableCell>
+                <TableCell>
+                  {supplier.identification_type} {supplier.identification_number}
+                  {supplier.dv ? `-${supplier.dv}` : ''}
+                </TableCell>
+                <TableCell>{supplier.phone}</TableCell>
+                <TableCell>
+                  {supplier.issues_invoices ? (
+                    <Chip label="Emite factura" size="small" />
+                  ) : (
+                    <Chip label="Requiere documento soporte" color="warning" size="small" />
+                  )}
+                </TableCell>
+                <TableCell align="center">
+                  <IconButton
+                    size="small"
+                    onClick={() => {
+                      setEditingSupplier({ ...supplier });
+                      setSupplierDialogOpen(true);
+                    }}
+                  >
+                    <EditIcon fontSize="small" />
+                  </IconButton>
+                  <IconButton size="small" color="error" onClick={() => handleDeleteSupplier(supplier)}>
+                    <DeleteIcon fontSize="small" />
+                  </IconButton>
+                </TableCell>
+              </TableRow>
+            ))}
+          </TableBody>
+        </Table>
+      </Paper>
+
+      {/* Purchases */}
+      <Box sx={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', mb: 2 }}>
+        <Typography variant="h6">Compras</Typography>
+        <Button variant="contained" startIcon={<AddIcon />} onClick={handleOpenPurchaseDialog}>
+          Registrar Compra
+        </Button>
+      </Box>
+      <TableContainer component={Paper}>
+        <Table>
+          <TableHead>
+            <TableRow>
+              <TableCell>Número</TableCell>
+              <TableCell>Fecha</TableCell>
+              <TableCell>Proveedor</TableCell>
+              <TableCell align="right">Subtotal</TableCell>
+              <TableCell align="right">Retenciones</TableCell>
+              <TableCell align="right">Total pagado</TableCell>
+              <TableCell>Documento Soporte</TableCell>
+              <TableCell align="center">Acciones</TableCell>
+            </TableRow>
+          </TableHead>
+          <TableBody>
+            {purchases.length === 0 ? (
+              <TableRow>
+                <TableCell colSpan={8} align="center">
+                  <Typography color="text.secondary">No hay compras registradas</Typography>
+                </TableCell>
+              </TableRow>
+            ) : (
+              purchases.map((purchase) => {
+                const doc = purchase.support_document;
+                const canRetry = purchase.needs_support_document && (!doc || doc.status === 'error' || doc.status === 'rejected');
+                const canAdjust = !!doc?.cuds && (doc.adjustment_notes || []).length === 0;
+                return (
+                  <TableRow key={purchase.id} hover>
+                    <TableCell>{purchase.purchase_number}</TableCell>
+                    <TableCell>{purchase.purchased_at ? new Date(purchase.purchased_at).toLocaleString() : ''}</TableCell>
+                    <TableCell>{purchase.supplier?.name}</TableCell>
+                    <TableCell align="right">${(purchase.subtotal || 0).toLocaleString()}</TableCell>
+                    <TableCell align="right">${(purchase.withholding_total || 0).toLocaleString()}</TableCell>
+                    <TableCell align="right">${(purchase.total || 0).toLocaleString()}</TableCell>
+                    <TableCell>
+                      {getSupportDocumentChip(purchase)}
+                      {(doc?.adjustment_notes || []).map((note) => (
+                        <Chip key={note.id} label={`Ajuste ${note.prefix}${note.number}`} size="small" sx={{ ml: 1 }} />
+                      ))}
+                    </TableCell>
+                    <TableCell align="center">
+                      {canRetry && (
+                        <Tooltip title="Enviar documento soporte">
+                          <IconButton size="small" color="primary" onClick={() => handleIssueSupportDocument(purchase)}>
+                            <SendIcon fontSize="small" />
+                          </IconButton>
+                        </Tooltip>
+                      )}
+                      {canAdjust && (
+                        <Tooltip title="Nota de ajuste (anular)">
+                          <IconButton
+                            size="small"
+                            color="warning"
+                            onClick={() => {
+                              setAdjustmentReason('');
+                              setAdjustmentPurchase(purchase);
+                            }}
+                          >
+                            <UndoIcon fontSize="small" />
+                          </IconButton>
+                        </Tooltip>
+                      )}
+                    </TableCell>
+                  </TableRow>
+                );
+              })
+            )}
+          </TableBody>
+        </Table>
+      </TableContainer>
+
+      {/* Supplier Dialog */}
+      <Dialog open={supplierDialogOpen} onClose={() => setSupplierDialogOpen(false)} maxWidth="sm" fullWidth>
+        <DialogTitle>{editingSupplier.id ? 'Editar Proveedor' : 'Nuevo Proveedor'}</DialogTitle>
+        <DialogContent>
+          <Grid container spacing={2} sx={{ pt: 1 }}>
+            <Grid item xs={12}>
+              <TextField
+                fullWidth
+                label="Nombre o razón social"
+                value={editingSupplier.name}
+                onChange={(e) => setEditingSupplier({ ...editingSupplier, name: e.target.value })}
+              />
+            </Grid>
+            <Grid item xs={4}>
+              <FormControl fullWidth>
+                <InputLabel>Tipo</InputLabel>
+                <Select
+                  value={editingSupplier.identification_type}
+                  label="Tipo"
+                  onChange={(e) => setEditingSupplier({ ...editingSupplier, identification_type: e.target.value })}
+                >
+                  <MenuItem value="CC">CC</MenuItem>
+                  <MenuItem value="NIT">NIT</MenuItem>
+                  <MenuItem value="CE">CE</MenuItem>
+                  <MenuItem value="PP">Pasaporte</MenuItem>
+                </Select>
+              </FormControl>
+            </Grid>
+            <Grid item xs={editingSupplier.identification_type === 'NIT' ? 5 : 8}>
+              <TextField
+                fullWidth
+                label="Número de identificación"
+                value={editingSupplier.identification_number}
+                onChange={(e) => setEditingSupplier({ ...editingSupplier, identification_number: e.target.value })}
+              />
+            </Grid>
+            {editingSupplier.identification_type === 'NIT' && (
+              <Grid item xs={3}>
+                <TextField
+                  fullWidth
+                  label="DV"
+                  value={editingSupplier.dv || ''}
+                  onChange={(e) => setEditingSupplier({ ...editingSupplier, dv: e.target.value })}
+                />
+              </Grid>
+            )}
+            <Grid item xs={6}>
+              <TextField
+                fullWidth
+                label="Teléfono"
+                value={editingSupplier.phone || ''}
+                onChange={(e) => setEditingSupplier({ ...editingSupplier, phone: e.target.value })}
+              />
+            </Grid>
+            <Grid item xs={6}>
+              <TextField
+                fullWidth
+                label="Email"
+                value={editingSupplier.email || ''}
+                onChange={(e) => setEditingSupplier({ ...editingSupplier, email: e.target.value })}
+              />
+            </Grid>
+            <Grid item xs={12}>
+              <TextField
+                fullWidth
+                label="Dirección"
+                value={editingSupplier.address || ''}
+                onChange={(e) => setEditingSupplier({ ...editingSupplier, address: e.target.value })}
+              />
+            </Grid>
+            <Grid item xs={12}>
+              <FormControlLabel
+                control={
+                  <Switch
+                    checked={editingSupplier.issues_invoices}
+                    onChange={(e) => setEditingSupplier({ ...editingSupplier, issues_invoices: e.target.checked })}
+                  />
+                }
+                label="El proveedor emite factura electrónica"
+              />
+              {!editingSupplier.issues_invoices && (
+                <Alert severity="info" sx={{ mt: 1 }}>
+                  Las compras a este proveedor generarán un documento soporte electrónico ante la DIAN.
+                </Alert>
+              )}
+            </Grid>
+          </Grid>
+        </DialogContent>
+        <DialogActions>
+          <Button onClick={() => setSupplierDialogOpen(false)}>Cancelar</Button>
+          <Button variant="contained" onClick={handleSaveSupplier}>
+            Guardar
+          </Button>
+        </DialogActions>
+      </Dialog>
+
+      {/* Purchase Dialog */}
+      <Dialog open={purchaseDialogOpen} onClose={() => setPurchaseDialogOpen(false)} maxWidth="md" fullWidth>
+        <DialogTitle>Registrar Compra</DialogTitle>
+        <DialogContent>
+          <Grid container spacing={2} sx={{ pt: 1 }}>
+            <Grid item xs={12} md={6}>
+              <FormControl fullWidth>
+                <InputLabel>Proveedor</InputLabel>
+                <Select value={supplierId} label="Proveedor" onChange={(e) => setSupplierId(e.target.value as number)}>
+                  {suppliers.map((supplier) => (
+                    <MenuItem key={supplier.id} value={supplier.id}>
+                      {supplier.name}
+                    </MenuItem>
+                  ))}
+                </Select>
+              </FormControl>
+            </Grid>
+            <Grid item xs={12} md={6}>
+              <TextField
+                fullWidth
+                label="Factura del proveedor"
+                disabled={!selectedSupplier?.issues_invoices}
+                value={supplierInvoiceNumber}
+                onChange={(e) => setSupplierInvoiceNumber(e.target.value)}
+              />
+            </Grid>
+            {selectedSupplier && !selectedSupplier.issues_invoices && (
+              <Grid item xs={12}>
+                <Alert severity="info">Se emitirá un documento soporte electrónico al registrar la compra.</Alert>
+              </Grid>
+            )}
+          </Grid>
+
+          <Typography variant="subtitle1" sx={{ mt: 3, mb: 1 }}>
+            Ítems
+          </Typography>
+          {items.map((item, index) => (
+            <Stack direction="row" spacing={1} key={index} sx={{ mb: 1 }}>
+              <FormControl sx={{ minWidth: 180 }} size="small">
+                <InputLabel>Inventario</InputLabel>
+                <Select
+                  label="Inventario"
+                  value={item.ingredient_id ? `i-${item.ingredient_id}` : item.product_id ? `p-${item.product_id}` : ''}
+                  onChange={(e) => handleItemSourceChange(index, e.target.value as string)}
+                >
+                  <MenuItem value="">Sin vincular</MenuItem>
+                  {ingredients.map((ing) => (
+                    <MenuItem key={`i-${ing.id}`} value={`i-${ing.id}`}>
+                      {ing.name} ({ing.unit})
+                    </MenuItem>
+                  ))}
+                  {products
+                    .filter((p) => p.track_inventory)
+                    .map((p) => (
+                      <MenuItem key={`p-${p.id}`} value={`p-${p.id}`}>
+                        {p.name}
+                      </MenuItem>
+                    ))}
+                </Select>
+              </FormControl>
+              <TextField
+                size="small"
+                label="Descripción"
+                value={item.description}
+                onChange={(e) => updateItem(index, { description: e.target.value })}
+                sx={{ flex: 1 }}
+              />
+              <TextField
+                size="small"
+                type="number"
+                label="Cantidad"
+                value={item.quantity}
+                onChange={(e) => updateItem(index, { quantity: parseFloat(e.target.value) || 0 })}
+                sx={{ width: 100 }}
+              />
+              <TextField
+                size="small"
+                type="number"
+                label="Precio unit."
+                value={item.unit_price}
+                onChange={(e) => updateItem(index, { unit_price: parseFloat(e.target.value) || 0 })}
+                sx={{ width: 130 }}
+              />
+              <TextField
+                size="small"
+                type="number"
+                label="IVA %"
+                value={item.tax_percent}
+                onChange={(e) => updateItem(index, { tax_percent: parseFloat(e.target.value) || 0 })}
+                sx={{ width: 80 }}
+              />
+              <IconButton
+                size="small"
+                color="error"
+                disabled={items.length === 1}
+                onClick={() => setItems(items.filter((_, i) => i !== index))}
+              >
+                <DeleteIcon fontSize="small" />
+              </IconButton>
+            </Stack>
+          ))}
+          <Button size="small" startIcon={<AddIcon />} onClick={() => setItems([...items, { ...emptyItem }])}>
+            Agregar ítem
+          </Button>
+
+          <Typography variant="subtitle1" sx={{ mt: 3, mb: 1 }}>
+            Retenciones
+          </Typography>
+          {withholdings.map((withholding, index) => (
+            <Stack direction="row" spacing={1} key={index} sx={{ mb: 1 }}>
+              <FormControl sx={{ minWidth: 180 }} size="small">
+                <InputLabel>Retención</InputLabel>
+                <Select
+                  label="Retención"
+                  value={withholding.tax_id}
+                  onChange={(e) =>
+                    setWithholdings(
+                      withholdings.map((w, i) => (i === index ? { ...w, tax_id: e.target.value as number } : w))
+                    )
+                  }
+                >
+                  {Object.entries(withholdingLabels).map(([id, label]) => (
+                    <MenuItem key={id} value={Number(id)}>
+                      {label}
+                    </MenuItem>
+                  ))}
+                </Select>
+              </FormControl>
+              <TextField
+                size="small"
+                type="number"
+                label="Porcentaje %"
+                value={withholding.percent}
+                onChange={(e) =>
+                  setWithholdings(
+                    withholdings.map((w, i) => (i === index ? { ...w, percent: parseFloat(e.target.value) || 0 } : w))
+                  )
+                }
+                sx={{ width: 130 }}
+              />
+              <IconButton size="small" color="error" onClick={() => setWithholdings(withholdings.filter((_, i) => i !== index))}>
+                <DeleteIcon fontSize="small" />
+              </IconButton>
+            </Stack>
+          ))}
+          <Button size="small" startIcon={<AddIcon />} onClick={() => setWithholdings([...withholdings, { tax_id: 6, percent: 2.5 }])}>
+            Agregar retención
+          </Button>
+
+          <TextField
+            fullWidth
+            multiline
+            rows={2}
+            label="Notas"
+            value={notes}
+            onChange={(e) => setNotes(e.target.value)}
+            sx={{ mt: 3 }}
+          />
+
+          <Box sx={{ mt: 2, textAlign: 'right' }}>
+            <Typography>Subtotal: ${subtotal.toLocaleString()}</Typography>
+            <Typography>IVA: ${tax.toLocaleString()}</Typography>
+            <Typography>Retenciones: -${withholdingTotal.toLocaleString()}</Typography>
+            <Typography variant="h6">Total a pagar: ${(subtotal + tax - withholdingTotal).toLocaleString()}</Typography>
+          </Box>
+        </DialogContent>
+        <DialogActions>
+          <Button onClick={() => setPurchaseDialogOpen(false)}>Cancelar</Button>
+          <Button variant="contained" onClick={handleSavePurchase} disabled={saving}>
+            {saving ? 'Registrando...' : 'Registrar Compra'}
+          </Button>
+        </DialogActions>
+      </Dialog>
+
+      {/* Adjustment Note Dialog */}
+      <Dialog open={!!adjustmentPurchase} onClose={() => setAdjustmentPurchase(null)} maxWidth="sm" fullWidth>
+        <DialogTitle>Nota de Ajuste - {adjustmentPurchase?.purchase_number}</DialogTitle>
+        <DialogContent>
+          <Alert severity="warning" sx={{ mb: 2, mt: 1 }}>
+            La nota de ajuste anula el documento soporte ante la DIAN. El inventario no se modifica.
+          </Alert>
+          <TextField
+            fullWidth
+            multiline
+            rows={3}
+            label="Motivo"
+            value={adjustmentReason}
+            onChange={(e) => setAdjustmentReason(e.target.value)}
+          />
+        </DialogContent>
+        <DialogActions>
+          <Button onClick={() => setAdjustmentPurchase(null)}>Cancelar</Button>
+          <Button variant="contained" color="warning" onClick={handleSendAdjustment} disabled={!adjustmentReason.trim()}>
+            Enviar Nota de Ajuste
+          </Button>
+        </DialogActions>
+      </Dialog>
+    </Box>
+  );
+};
+
+export default PurchasesPanel;
@@ -46,6 +46,7 @@ import { wailsProductService } from '../../services/wailsProductService';
 import { GetInventoryMovements } from '../../../wailsjs/go/services/ProductService';
 import { Product, InventoryMovement } from '../../types/models';
 import { toast } from 'react-toastify';
+import PurchasesPanel from './PurchasesPanel';
 
 interface TabPanelProps {
   children?: React.ReactNode;
@@ -269,195 +270,210 @@ const InventoryManagement: React.FC = () => {
         </Typography>
       </Box>
 
-      {/* Summary Cards */}
-      <Grid container spacing={2} sx={{ mb: 3 }}>
-        <Grid item xs={12} sm={6} md={3}>
-          <Card>
-            <CardContent>
-              <Typography color="text.secondary" gutterBottom>
-                Total Productos
-              </Typography>
-              <Typography variant="h4">{stats.total_products}</Typography>
-              <Typography variant="caption" color="text.secondary">
-                {stats.tracked_products} con seguimiento
-              </Typography>
-            </CardContent>
-          </Card>
-        </Grid>
-        <Grid item xs={12} sm={6} md={3}>
-          <Card sx={{ bgcolor: 'warning.light' }}>
-            <CardContent>
-              <Box sx={{ display: 'flex', alignItems: 'center', gap: 1 }}>
-                <WarningIcon />
-                <Typography color="text.secondary" gutterBottom>
-                  Stock Bajo
-                </Typography>
-              </Box>
-              <Typography variant="h4">{stats.low_stock}</Typography>
-              <Typography variant="caption">
-                Productos con inventario bajo
-              </Typography>
-            </CardContent>
-          </Card>
-        </Grid>
-        <Grid item xs={12} sm={6} md={3}>
-          <Card sx={{ bgcolor: 'error.light' }}>
-            <CardContent>
-              <Box sx={{ display: 'flex', alignItems: 'center', gap: 1 }}>
-                <TrendingDownIcon />
-                <Typography color="text.secondary" gutterBottom>
-                  Agotados
-                </Typography>
-              </Box>
-              <Typography variant="h4">{stats.out_of_stock}</Typography>
-              <Typography variant="caption">
-                Productos sin stock
-              </Typography>
-            </CardContent>
-          </Card>
-        </Grid>
-        <Grid item xs={12} sm={6} md={3}>
-          <Card sx={{ bgcolor: 'success.light' }}>
-            <CardContent>
-              <Typography color="text.secondary" gutterBottom>
-                Valor Total
-              </Typography>
-              <Typography variant="h4">
-                ${stats.total_value.toLocaleString()}
-              </Typography>
-              <Typography variant="caption">
-                Inventario valorizado
-              </Typography>
-            </CardContent>
-          </Card>
-        </Grid>
-      </Grid>
-
-      {/* Filters */}
-      <Paper sx={{ p: 2, mb: 3 }}>
-        <Grid container spacing={2} alignItems="center">
-          <Grid item xs={12} md={6}>
-            <TextField
-              fullWidth
-              placeholder="Buscar por nombre o código de barras..."
-              value={searchTerm}
-              onChange={(e) => setSearchTerm(e.target.value)}
-              InputProps={{
-                startAdornment: <SearchIcon sx={{ mr: 1, color: 'text.secondary' }} />,
-              }}
-            />
-          </Grid>
-          <Grid item xs={12} md={6}>
-            <FormControl fullWidth>
-              <InputLabel>Filtrar por</InputLabel>
-              <Select
-                value={filterType}
-                onChange={(e) => setFilterType(e.target.value as any)}
-                label="Filtrar por"
-                startAdornment={<FilterListIcon sx={{ ml: 1, mr: 0.5, color: 'text.secondary' }} />}
-              >
-                <MenuItem value="all">Todos los productos</MenuItem>
-                <MenuItem value="tracked">Solo con seguimiento</MenuItem>
-                <MenuItem value="low">Stock bajo</MenuItem>
-                <MenuItem value="out">Agotados</MenuItem>
-              </Select>
-            </FormControl>
-          </Grid>
-        </Grid>
+      <Paper sx={{ mb: 2 }}>
+        <Tabs value={tabValue} onChange={(_, value) => setTabValue(value)}>
+          <Tab label="Existencias" />
+          <Tab label="Compras y Proveedores" />
+        </Tabs>
       </Paper>
 
-      {/* Products Table */}
-      <TableContainer component={Paper}>
-        <Table>
-          <TableHead>
-            <TableRow>
-              <TableCell>Producto</TableCell>
-              <TableCell>Código</TableCell>
-              <TableCell align="center">Stock Actual</TableCell>
-              <TableCell align="center">Stock Mínimo</TableCell>
-              <TableCell align="center">Estado</TableCell>
-              <TableCell align="center">Valor Inventario</TableCell>
-              <TableCell align="center">Acciones</TableCell>
-            </TableRow>
-          </TableHead>
-          <TableBody>
-            {filteredProducts.length === 0 ? (
-              <TableRow>
-                <TableCell colSpan={7} align="center">
-                  <Typography color="text.secondary" sx={{ py: 4 }}>
-                    No se encontraron productos
+      <TabPanel value={tabValue} index={1}>
+        <PurchasesPanel />
+      </TabPanel>
+
+      {tabValue === 0 && (
+        <>
+          {/* Summary Cards */}
+          <Grid container spacing={2} sx={{ mb: 3 }}>
+            <Grid item xs={12} sm={6} md={3}>
+              <Card>
+                <CardContent>
+                  <Typography color="text.secondary" gutterBottom>
+                    Total Productos
                   </Typography>
-                </TableCell>
-              </TableRow>
-            ) : (
-              filteredProducts.map((product) => {
-                const status = getStockStatus(product);
-                const inventoryValue = product.stock * (product.cost || product.price);
-
-                return (
-                  <TableRow key={product.id} hover>
-                    <TableCell>
-                      <Box>
-                        <Typography variant="body1">{product.name}</Typography>
-                        {product.track_inventory === false && (
-                          <Chip
-                            size="small"
-                            label="Sin seguimiento"
-                            variant="outlined"
-                            sx={{ mt: 0.5 }}
-                          />
-                        )}
-                      </Box>
-                    </TableCell>
-                    <TableCell>{product.barcode || '-'}</TableCell>
-                    <TableCell align="center">
-                      <Typography
-                        variant="h6"
-                        color={product.stock <= 0 ? 'error' : product.stock <= (product.min_stock || 0) ? 'warning.main' : 'inherit'}
-                      >
-                        {product.track_inventory === false ? '-' : product.stock}
-                      </Typography>
-                    </TableCell>
-                    <TableCell align="center">
-                      {product.track_inventory === false ? '-' : (product.min_stock || 0)}
-                    </TableCell>
-                    <TableCell align="center">
-                      <Chip label={status.label} color={status.color} size="small" />
-                    </TableCell>
-                    <TableCell align="center">
-                      <Typography variant="body2">
-                        {product.track_inventory === false ? '-' : `$${inventoryValue.toLocaleString()}`}
+                  <Typography variant="h4">{stats.total_products}</Typography>
+                  <Typography variant="caption" color="text.secondary">
+                    {stats.tracked_products} con seguimiento
+                  </Typography>
+                </CardContent>
+              </Card>
+            </Grid>
+            <Grid item xs={12} sm={6} md={3}>
+              <Card sx={{ bgcolor: 'warning.light' }}>
+                <CardContent>
+                  <Box sx={{ display: 'flex', alignItems: 'center', gap: 1 }}>
+                    <WarningIcon />
+                    <Typography color="text.secondary" gutterBottom>
+                      Stock Bajo
+                    </Typography>
+                  </Box>
+                  <Typography variant="h4">{stats.low_stock}</Typography>
+                  <Typography variant="caption">
+                    Productos con inventario bajo
+                  </Typography>
+                </CardContent>
+              </Card>
+            </Grid>
+            <Grid item xs={12} sm={6} md={3}>
+              <Card sx={{ bgcolor: 'error.light' }}>
+                <CardContent>
+                  <Box sx={{ display: 'flex', alignItems: 'center', gap: 1 }}>
+                    <TrendingDownIcon />
+                    <Typography color="text.secondary" gutterBottom>
+                      Agotados
+                    </Typography>
+                  </Box>
+                  <Typography variant="h4">{stats.out_of_stock}</Typography>
+                  <Typography variant="caption">
+                    Productos sin stock
+                  </Typography>
+                </CardContent>
+              </Card>
+            </Grid>
+            <Grid item xs={12} sm={6} md={3}>
+              <Card sx={{ bgcolor: 'success.light' }}>
+                <CardContent>
+                  <Typography color="text.secondary" gutterBottom>
+                    Valor Total
+                  </Typography>
+                  <Typography variant="h4">
+                    ${stats.total_value.toLocaleString()}
+                  </Typography>
+                  <Typography variant="caption">
+                    Inventario valorizado
+                  </Typography>
+                </CardContent>
+              </Card>
+            </Grid>
+          </Grid>
+
+          {/* Filters */}
+          <Paper sx={{ p: 2, mb: 3 }}>
+            <Grid container spacing={2} alignItems="center">
+              <Grid item xs={12} md={6}>
+                <TextField
+                  fullWidth
+                  placeholder="Buscar por nombre o código de barras..."
+                  value={searchTerm}
+                  onChange={(e) => setSearchTerm(e.target.value)}
+                  InputProps={{
+                    startAdornment: <SearchIcon sx={{ mr: 1, color: 'text.secondary' }} />,
+                  }}
+                />
+              </Grid>
+              <Grid item xs={12} md={6}>
+                <FormControl fullWidth>
+                  <InputLabel>Filtrar por</InputLabel>
+                  <Select
+                    value={filterType}
+                    onChange={(e) => setFilterType(e.target.value as any)}
+                    label="Filtrar por"
+                    startAdornment={<FilterListIcon sx={{ ml: 1, mr: 0.5, color: 'text.secondary' }} />}
+                  >
+                    <MenuItem value="all">Todos los productos</MenuItem>
+                    <MenuItem value="tracked">Solo con seguimiento</MenuItem>
+                    <MenuItem value="low">Stock bajo</MenuItem>
+                    <MenuItem value="out">Agotados</MenuItem>
+                  </Select>
+                </FormControl>
+              </Grid>
+            </Grid>
+          </Paper>
+
+          {/* Products Table */}
+          <TableContainer component={Paper}>
+            <Table>
+              <TableHead>
+                <TableRow>
+                  <TableCell>Producto</TableCell>
+                  <TableCell>Código</TableCell>
+                  <TableCell align="center">Stock Actual</TableCell>
+                  <TableCell align="center">Stock Mínimo</TableCell>
+                  <TableCell align="center">Estado</TableCell>
+                  <TableCell align="center">Valor Inventario</TableCell>
+                  <TableCell align="center">Acciones</TableCell>
+                </TableRow>
+              </TableHead>
+              <TableBody>
+                {filteredProducts.length === 0 ? (
+                  <TableRow>
+                    <TableCell colSpan={7} align="center">
+                      <Typography color="text.secondary" sx={{ py: 4 }}>
+                        No se encontraron productos
                       </Typography>
                     </TableCell>
-                    <TableCell align="center">
-                      <Stack direction="row" spacing={1} justifyContent="center">
-                        <Tooltip title="Ajustar Stock">
-                          <IconButton
-                            size="small"
-                            color="primary"
-                            onClick={() => handleOpenAdjustDialog(product)}
-                          >
-                            <EditIcon fontSize="small" />
-                          </IconButton>
-                        </Tooltip>
-                        <Tooltip title="Ver Historial">
-                          <IconButton
-                            size="small"
-                            color="info"
-                            onClick={() => handleOpenHistoryDialog(product)}
-                          >
-                            <HistoryIcon fontSize="small" />
-                          </IconButton>
-                        </Tooltip>
-                      </Stack>
-                    </TableCell>
                   </TableRow>
-                );
-              })
-            )}
-          </TableBody>
-        </Table>
-      </TableContainer>
+                ) : (
+                  filteredProducts.map((product) => {
+                    const status = getStockStatus(product);
+                    const inventoryValue = product.stock * (product.cost || product.price);
+
+                    return (
+                      <TableRow key={product.id} hover>
+                        <TableCell>
+                          <Box>
+                            <Typography variant="body1">{product.name}</Typography>
+                            {product.track_inventory === false && (
+                              <Chip
+                                size="small"
+                                label="Sin seguimiento"
+                                variant="outlined"
+                                sx={{ mt: 0.5 }}
+                              />
+                            )}
+                          </Box>
+                        </TableCell>
+                        <TableCell>{product.barcode || '-'}</TableCell>
+                        <TableCell align="center">
+                          <Typography
+                            variant="h6"
+                            color={product.stock <= 0 ? 'error' : product.stock <= (product.min_stock || 0) ? 'warning.main' : 'inherit'}
+                          >
+                            {product.track_inventory === false ? '-' : product.stock}
+                          </Typography>
+                        </TableCell>
+                        <TableCell align="center">
+                          {product.track_inventory === false ? '-' : (product.min_stock || 0)}
+                        </TableCell>
+                        <TableCell align="center">
+                          <Chip label={status.label} color={status.color} size="small" />
+                        </TableCell>
+                        <TableCell align="center">
+                          <Typography variant="body2">
+                            {product.track_inventory === false ? '-' : `$${inventoryValue.toLocaleString()}`}
+                          </Typography>
+                        </TableCell>
+                        <TableCell align="center">
+                          <Stack direction="row" spacing={1} justifyContent="center">
+                            <Tooltip title="Ajustar Stock">
+                              <IconButton
+                                size="small"
+                                color="primary"
+                                onClick={() => handleOpenAdjustDialog(product)}
+                              >
+                                <EditIcon fontSize="small" />
+                              </IconButton>
+                            </Tooltip>
+                            <Tooltip title="Ver Historial">
+                              <IconButton
+                                size="small"
+                                color="info"
+                                onClick={() => handleOpenHistoryDialog(product)}
+                              >
+                                <HistoryIcon fontSize="small" />
+                              </IconButton>
+                            </Tooltip>
+                          </Stack>
+                        </TableCell>
+                      </TableRow>
+                    );
+                  })
+                )}
+              </TableBody>
+            </Table>
+          </TableContainer>
+        </>
+      )}
 
       {/* Stock Adjustment Dialog */}
       <Dialog open={adjustDialogOpen} onClose={handleCloseAdjustDialog} maxWidth="sm" fullWidth>
@@ -50,6 +50,7 @@ import {
   Close as CloseIcon,
   Edit as EditIcon,
   Person as PersonIcon,
+  PictureAsPdf as PdfIcon,
 } from '@mui/icons-material';
 import { DatePicker } from '@mui/x-date-pickers/DatePicker';
 import { LocalizationProvider } from '@mui/x-date-pickers/LocalizationProvider';
@@ -65,6 +66,7 @@ import {
 import { Sale } from '../../types/models';
 import { wailsSalesService } from '../../services/wailsSalesService';
 import { wailsDianService } from '../../services/wailsDianService';
+import { wailsPdfService } from '../../services/wailsPdfService';
 import { useAuth, useDIANMode } from '../../hooks';
 import { toast } from 'react-toastify';
 import { GetRestaurantConfig } from '../../../wailsjs/go/services/ConfigService';
@@ -150,6 +152,18 @@ const Sales: React.FC = () => {
     }
   };
 
+  const handleDownloadInvoicePDF = async (sale: Sale) => {
+    try {
+      if (sale.id) {
+        const file = await wailsPdfService.generateInvoicePDF(sale.id);
+        wailsPdfService.download(file);
+        toast.success(file.path ? `PDF guardado en ${file.path}` : 'PDF generado');
+      }
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al generar PDF de la factura');
+    }
+  };
+
   const handleSendElectronicInvoice = async (sale: Sale) => {
     try {
       if (!sale.id) {
@@ -167,6 +181,14 @@ const Sales: React.FC = () => {
         return;
       }
 
+      if (sale.electronic_invoice?.status === 'contingency') {
+        toast.info('Reportando factura de contingencia a DIAN...');
+        await wailsSalesService.resendElectronicInvoice(sale.id);
+        toast.success('Factura de contingencia reportada exitosamente');
+        loadSalesHistory();
+        return;
+      }
+
       // Close dialog if open to show fresh data after refresh
       setDianResponseDialog(false);
 
@@ -637,6 +659,29 @@ const Sales: React.FC = () => {
                                   label="Validando..."
                                   color="warning"
                                 />
+                              ) : sale.electronic_invoice.status === 'contingency_reporting' ? (
+                                <Chip
+                                  size="small"
+                                  icon={<PendingIcon />}
+                                  label="Reportando..."
+                                  color="warning"
+                                />
+                              ) : sale.electronic_invoice.status === 'contingency' ? (
+                                <>
+                                  <Chip
+                                    size="small"
+                                    icon={<PendingIcon />}
+                                    label="Contingencia"
+                                    color="warning"
+                                  />
+                                  <IconButton
+                                    size="small"
+                                    onClick={() => handleSendElectronicInvoice(sale)}
+                                    title="Reportar a DIAN"
+                                  >
+                                    <SendIcon fontSize="small" />
+                                  </IconButton>
+                                </>
                               ) : sale.electronic_invoice.status === 'error' || sale.electronic_invoice.status === 'rejected' ? (
                                 <>
                                   <Chip
@@ -772,6 +817,11 @@ const Sales: React.FC = () => {
         <MenuItem onClick={() => selectedSale && handlePrintInvoice(selectedSale)}>
           <ReceiptIcon sx={{ mr: 1 }} /> Imprimir Factura
         </MenuItem>
+        {selectedSale?.electronic_invoice?.invoice_number && (
+          <MenuItem onClick={() => selectedSale && handleDownloadInvoicePDF(selectedSale)}>
+            <PdfIcon sx={{ mr: 1 }} /> Descargar PDF
+          </MenuItem>
+        )}
         {selectedSale?.invoice_type === 'electronic' && (
           <MenuItem onClick={() => selectedSale && handleResendInvoice(selectedSale)}>
             <ReceiptIcon sx={{ mr: 1 }} /> Reenviar Factura Electrónica
@@ -786,6 +836,7 @@ const Sales: React.FC = () => {
         {selectedSale && (!selectedSale.electronic_invoice ||
           (selectedSale.electronic_invoice.status !== 'sent' &&
            selectedSale.electronic_invoice.status !== 'validating' &&
+           selectedSale.electronic_invoice.status !== 'contingency' &&
            selectedSale.electronic_invoice.status !== 'accepted')) && (
           <MenuItem onClick={() => selectedSale && handleOpenEditCustomerDialog(selectedSale)}>
             <EditIcon sx={{ mr: 1 }} /> Cambiar Cliente
@@ -1093,6 +1144,15 @@ const Sales: React.FC = () => {
         </DialogContent>
         <DialogActions>
           <Button onClick={() => setDetailDialog(false)}>Cerrar</Button>
+          {selectedSale?.electronic_invoice?.invoice_number && (
+            <Button
+              variant="outlined"
+              startIcon={<PdfIcon />}
+              onClick={() => selectedSale && handleDownloadInvoicePDF(selectedSale)}
+            >
+              PDF
+            </Button>
+          )}
           <Button
             variant="outlined"
             startIcon={<ReceiptIcon />}
@@ -0,0 +1,417 @@
+import React, { useState, useEffect } from 'react';
+import {
+  Box,
+  Typography,
+  Grid,
+  TextField,
+  Button,
+  Alert,
+  Chip,
+  Switch,
+  FormControlLabel,
+  Table,
+  TableBody,
+  TableCell,
+  TableContainer,
+  TableHead,
+  TableRow,
+  Paper,
+  Divider,
+} from '@mui/material';
+import { toast } from 'react-toastify';
+import { wailsDianService } from '../../services/wailsDianService';
+import { useAuth } from '../../hooks';
+import { ContingencyEvent } from '../../types/models';
+
+interface ContingencyForm {
+  prefix: string;
+  resolution: string;
+  technicalKey: string;
+  startNumber: number;
+  endNumber: number;
+  dateFrom: string;
+  dateTo: string;
+  consecutiveNumber: number;
+  autoActivate: boolean;
+  reportHours: number;
+}
+
+const toDateInput = (value?: string, fallback: string = '') =>
+  value && value !== '0001-01-01T00:00:00Z' ? value.split('T')[0] : fallback;
+
+const formatDate = (value?: string) => (value ? new Date(value).toLocaleString() : '-');
+
+const ContingencySettings: React.FC = () => {
+  const { user } = useAuth();
+  const [form, setForm] = useState<ContingencyForm>({
+    prefix: 'CONT',
+    resolution: '',
+    technicalKey: '',
+    startNumber: 1,
+    endNumber: 99999999,
+    dateFrom: '2019-01-19',
+    dateTo: '2030-01-19',
+    consecutiveNumber: 0,
+    autoActivate: true,
+    reportHours: 48,
+  });
+  const [configured, setConfigured] = useState(false);
+  const [hasToken, setHasToken] = useState(false);
+  const [status, setStatus] = useState<any>(null);
+  const [events, setEvents] = useState<ContingencyEvent[]>([]);
+  const [reason, setReason] = useState('');
+
+  useEffect(() => {
+    loadData();
+  }, []);
+
+  const loadData = async () => {
+    try {
+      const config = await wailsDianService.getConfig();
+      if (config) {
+        setForm({
+          prefix: config.contingency_resolution_prefix || 'CONT',
+          resolution: config.contingency_resolution_number || '',
+          technicalKey: config.contingency_technical_key || '',
+          startNumber: config.contingency_resolution_from || 1,
+          endNumber: config.contingency_resolution_to || 99999999,
+          dateFrom: toDateInput(config.contingency_resolution_date_from, '2019-01-19'),
+          dateTo: toDateInput(config.contingency_resolution_date_to, '2030-01-19'),
+          consecutiveNumber: config.last_contingency_number || 0,
+          autoActivate: config.contingency_auto_activate ?? true,
+          reportHours: config.contingency_report_hours || 48,
+        });
+        setConfigured(config.step10_completed || false);
+        setHasToken(!!config.api_token);
+      }
+      setStatus(await wailsDianService.getContingencyStatus());
+      setEvents(await wailsDianService.getContingencyEvents(50));
+    } catch (e: any) {
+      console.error('Error loading contingency settings:', e);
+    }
+  };
+
+  const saveForm = async () => {
+    const currentDianConfig = await wailsDianService.getConfig();
+    await wailsDianService.updateConfig({
+      ...currentDianConfig,
+      contingency_resolution_prefix: form.prefix,
+      contingency_resolution_number: form.resolution,
+      contingency_technical_key: form.technicalKey,
+      contingency_resolution_from: form.startNumber || 1,
+      contingency_resolution_to: form.endNumber || 99999999,
+      // Use noon time to avoid timezone issues (UTC midnight can shift to previous day)
+      contingency_resolution_date_from: new Date(form.dateFrom + 'T12:00:00'),
+      contingency_resolution_date_to: new Date(form.dateTo + 'T12:00:00'),
+      last_contingency_number: form.consecutiveNumber || 0,
+      contingency_auto_activate: form.autoActivate,
+      contingency_report_hours: form.reportHours || 48,
+    });
+  };
+
+  const handleSave = async () => {
+    try {
+      await saveForm();
+      toast.success('Configuración de contingencia guardada');
+      loadData();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error guardando configuración de contingencia');
+    }
+  };
+
+  const handleConfigureResolution = async () => {
+    if (!form.prefix || !form.resolution) {
+      toast.error('Por favor completa el prefijo y número de resolución de contingencia');
+      return;
+    }
+    if (!hasToken) {
+      toast.error('Primero debes completar los pasos anteriores para obtener el token.');
+      return;
+    }
+    try {
+      toast.info('Configurando resolución de contingencia con DIAN...');
+      await saveForm();
+      await wailsDianService.configureContingencyResolution();
+      toast.success('Resolución de contingencia configurada exitosamente con DIAN');
+      loadData();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error configurando resolución de contingencia');
+    }
+  };
+
+  const handleActivate = async () => {
+    try {
+      await wailsDianService.activateContingencyMode(reason || 'Activación manual', user?.id || 0);
+      toast.warning('Modo contingencia activado: las facturas usarán la numeración de contingencia');
+      setReason('');
+      loadData();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error activando modo contingencia');
+    }
+  };
+
+  const handleDeactivate = async () => {
+    try {
+      await wailsDianService.deactivateContingencyMode();
+      toast.success('Modo contingencia desactivado. Las facturas pendientes se reportarán a la DIAN.');
+      loadData();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error desactivando modo contingencia');
+    }
+  };
+
+  const handleReportNow = async () => {
+    try {
+      const reported = await wailsDianService.reportContingencyInvoices();
+      toast.success(`${reported} facturas de contingencia reportadas`);
+      loadData();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error reportando facturas de contingencia');
+      loadData();
+    }
+  };
+
+  const active = status?.active || false;
+
+  return (
+    <Grid container spacing={2}>
+      <Grid item xs={12}>
+        <Alert severity={active ? 'warning' : 'info'}>
+          <Typography variant="body2">
+            {active
+              ? 'Modo contingencia ACTIVO: las facturas electrónicas se expiden con la numeración de contingencia y se reportarán a la DIAN cuando el servicio se restablezca.'
+              : 'Cuando el proveedor DIAN no responde, las facturas se expiden en contingencia con su propia numeración y se reportan dentro del plazo configurado.'}
+          </Typography>
+        </Alert>
+      </Grid>
+
+      {/* Status */}
+      <Grid item xs={12}>
+        <Box sx={{ display: 'flex', gap: 1, flexWrap: 'wrap', alignItems: 'center' }}>
+          <Chip label={active ? 'Contingencia activa' : 'Operación normal'} color={active ? 'warning' : 'success'} />
+          <Chip label={`Pendientes de reporte: ${status?.pending_invoices || 0}`} />
+          {status?.report_deadline && (
+            <Chip
+              label={`Plazo de reporte: ${formatDate(status.report_deadline)}`}
+              color={status.report_overdue ? 'error' : 'default'}
+            />
+          )}
+          {!configured && <Chip label="Resolución no configurada (Paso 10)" color="error" variant="outlined" />}
+        </Box>
+      </Grid>
+
+      <Grid item xs={12} sm={8}>
+        <TextField
+          fullWidth
+          size="small"
+          label="Motivo de activación"
+          value={reason}
+          disabled={active}
+          onChange={(e) => setReason(e.target.value)}
+          helperText="Se registra en la bitácora de contingencias"
+        />
+      </Grid>
+      <Grid item xs={12} sm={4}>
+        <Box sx={{ display: 'flex', gap: 1 }}>
+          {active ? (
+            <Button variant="contained" color="success" fullWidth onClick={handleDeactivate}>
+              Finalizar Contingencia
+            </Button>
+          ) : (
+            <Button variant="outlined" color="warning" fullWidth onClick={handleActivate} disabled={!configured}>
+              Activar Contingencia
+            </Button>
+          )}
+          <Button
+            variant="outlined"
+            onClick={handleReportNow}
+            disabled={active || !status?.pending_invoices}
+          >
+            Reportar
+          </Button>
+        </Box>
+      </Grid>
+
+      <Grid item xs={12}>
+        <Divider />
+      </Grid>
+
+      {/* Resolution */}
+      <Grid item xs={12} sm={6}>
+        <TextField
+          fullWidth
+          label="Prefijo Contingencia"
+          value={form.prefix}
+          onChange={(e) => setForm({ ...form, prefix: e.target.value })}
+          helperText="Prefijo autorizado para facturas de contingencia"
+        />
+      </Grid>
+      <Grid item xs={12} sm={6}>
+        <TextField
+          fullWidth
+          label="Número de Resolución"
+          value={form.resolution}
+          onChange={(e) => setForm({ ...form, resolution: e.target.value })}
+        />
+      </Grid>
+      <Grid item xs={12} sm={6}>
+        <TextField
+          fullWidth
+          label="Número Inicial"
+          type="number"
+          value={form.startNumber}
+          onChange={(e) => setForm({ ...form, startNumber: Number(e.target.value) })}
+        />
+      </Grid>
+      <Grid item xs={12} sm={6}>
+        <TextField
+          fullWidth
+          label="Número Final"
+          type="number"
+          value={form.endNumber}
+          onChange={(e) => setForm({ ...form, endNumber: Number(e.target.value) })}
+        />
+      </Grid>
+      <Grid item xs={12} sm={6}>
+        <TextField
+          fullWidth
+          label="Vigencia Desde"
+          type="date"
+          InputLabelProps={{ shrink: true }}
+          value={form.dateFrom}
+          onChange={(e) => setForm({ ...form, dateFrom: e.target.value })}
+        />
+      </Grid>
+      <Grid item xs={12} sm={6}>
+        <TextField
+          fullWidth
+          label="Vigencia Hasta"
+          type="date"
+          InputLabelProps={{ shrink: true }}
+          value={form.dateTo}
+          onChange={(e) => setForm({ ...form, dateTo: e.target.value })}
+        />
+      </Grid>
+      <Grid item xs={12} sm={6}>
+        <TextField
+          fullWidth
+          label="Clave Técnica (opcional)"
+          value={form.technicalKey}
+          onChange={(e) => setForm({ ...form, technicalKey: e.target.value })}
+        />
+      </Grid>
+      <Grid item xs={12} sm={6}>
+        <TextField
+          fullWidth
+          label="Último Consecutivo Usado"
+          type="number"
+          value={form.consecutiveNumber}
+          onChange={(e) => setForm({ ...form, consecutiveNumber: Number(e.target.value) })}
+          helperText="La siguiente factura de contingencia usará este número + 1"
+        />
+      </Grid>
+      <Grid item xs={12} sm={6}>
+        <FormControlLabel
+          control={
+            <Switch
+              checked={form.autoActivate}
+              onChange={(e) => setForm({ ...form, autoActivate: e.target.checked })}
+            />
+          }
+          label="Activar contingencia automáticamente si el proveedor DIAN no responde"
+        />
+      </Grid>
+      <Grid item xs={12} sm={6}>
+        <TextField
+          fullWidth
+          label="Plazo de reporte (horas)"
+          type="number"
+          value={form.reportHours}
+          onChange={(e) => setForm({ ...form, reportHours: Number(e.target.value) })}
+          helperText="Tiempo para reportar las facturas una vez restablecido el servicio"
+        />
+      </Grid>
+      <Grid item xs={12}>
+        <Box sx={{ display: 'flex', gap: 1 }}>
+          <Button variant="outlined" onClick={handleSave}>
+            Guardar
+          </Button>
+          <Button
+            variant={configured ? 'contained' : 'outlined'}
+            color={configured ? 'success' : 'primary'}
+            onClick={handleConfigureResolution}
+            disabled={configured}
+            sx={{ flex: 1 }}
+          >
+            {configured
+              ? '✓ Paso 10 Completado: Resolución de Contingencia Configurada'
+              : 'Paso 10 (Opcional): Configurar Facturación de Contingencia'}
+          </Button>
+        </Box>
+      </Grid>
+
+      {/* Outage log */}
+      <Grid item xs={12}>
+        <Typography variant="subtitle1" sx={{ mt: 2, mb: 1 }}>
+          Bitácora de Contingencias
+        </Typography>
+        <TableContainer component={Paper} variant="outlined">
+          <Table size="small">
+            <TableHead>
+              <TableRow>
+                <TableCell>Inicio</TableCell>
+                <TableCell>Fin</TableCell>
+                <TableCell>Motivo</TableCell>
+                <TableCell>Activación</TableCell>
+                <TableCell align="center">Emitidas</TableCell>
+                <TableCell align="center">Reportadas</TableCell>
+                <TableCell>Plazo de reporte</TableCell>
+              </TableRow>
+            </TableHead>
+            <TableBody>
+              {events.length === 0 ? (
+                <TableRow>
+                  <TableCell colSpan={7} align="center">
+                    <Typography variant="body2" color="text.secondary">
+                      No se han registrado contingencias
+                    </Typography>
+                  </TableCell>
+                </TableRow>
+              ) : (
+                events.map((event) => {
+                  const overdue =
+                    !event.report_completed_at &&
+                    !!event.report_deadline &&
+                    new Date(event.report_deadline) < new Date();
+                  return (
+                    <TableRow key={event.id}>
+                      <TableCell>{formatDate(event.started_at)}</TableCell>
+                      <TableCell>{event.ended_at ? formatDate(event.ended_at) : <Chip size="small" label="En curso" color="warning" />}</TableCell>
+                      <TableCell sx={{ maxWidth: 240, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' }}>
+                        {event.reason}
+                      </TableCell>
+                      <TableCell>{event.activated_by === 'auto' ? 'Automática' : 'Manual'}</TableCell>
+                      <TableCell align="center">{event.invoices_issued}</TableCell>
+                      <TableCell align="center">{event.invoices_reported}</TableCell>
+                      <TableCell>
+                        {event.report_completed_at ? (
+                          <Chip size="small" label="Completado" color="success" />
+                        ) : event.report_deadline ? (
+                          <Chip size="small" label={formatDate(event.report_deadline)} color={overdue ? 'error' : 'default'} />
+                        ) : (
+                          '-'
+                        )}
+                      </TableCell>
+                    </TableRow>
+                  );
+                })
+              )}
+            </TableBody>
+          </Table>
+        </TableContainer>
+      </Grid>
+    </Grid>
+  );
+};
+
+export default ContingencySettings;
@@ -61,6 +61,7 @@ const MCPSettings: React.FC = () => {
     port: 8090,
     api_key: '',
     allowed_ips: '',
+    allowed_origins: '',
     read_only_mode: false,
     disabled_tools: '',
   });
@@ -293,6 +294,16 @@ const MCPSettings: React.FC = () => {
                 placeholder="127.0.0.1, 192.168.1.100"
               />
             </Grid>
+            <Grid item xs={12}>
+              <TextField
+                fullWidth
+                label="Orígenes Web Permitidos"
+                value={config.allowed_origins}
+                onChange={(e) => setConfig({ ...config, allowed_origins: e.target.value })}
+                helperText="Páginas web que pueden llamar al servidor desde el navegador, separadas por coma (localhost siempre está permitido)"
+                placeholder="https://claude.ai"
+              />
+            </Grid>
             <Grid item xs={12}>
               <FormControlLabel
                 control={
@@ -0,0 +1,523 @@
+import React, { useState, useEffect } from 'react';
+import {
+  Box,
+  Typography,
+  Grid,
+  TextField,
+  Button,
+  Alert,
+  Chip,
+  Switch,
+  FormControlLabel,
+  Divider,
+  Table,
+  TableHead,
+  TableBody,
+  TableRow,
+  TableCell,
+  TableContainer,
+  IconButton,
+  Tooltip,
+  Dialog,
+  DialogTitle,
+  DialogContent,
+  DialogActions,
+  MenuItem,
+  Select,
+  FormControl,
+  InputLabel,
+} from '@mui/material';
+import {
+  Refresh as RefreshIcon,
+  Replay as RetryIcon,
+  Cancel as CancelIcon,
+  Visibility as ViewIcon,
+  Send as SendIcon,
+  DeleteSweep as ClearIcon,
+} from '@mui/icons-material';
+import { toast } from 'react-toastify';
+import {
+  wailsMailService,
+  MailSettings as MailSettingsData,
+  MailMessage,
+  LocalSMTPInbox,
+  CapturedMail,
+} from '../../services/wailsMailService';
+
+const statusLabels: Record<string, { label: string; color: 'default' | 'success' | 'warning' | 'error' | 'info' }> = {
+  pending: { label: 'Pendiente', color: 'info' },
+  retrying: { label: 'Reintentando', color: 'warning' },
+  sent: { label: 'Enviado', color: 'success' },
+  failed: { label: 'Fallido', color: 'error' },
+  cancelled: { label: 'Cancelado', color: 'default' },
+};
+
+const kindLabels: Record<string, string> = {
+  invoice: 'Factura',
+  dian_closing_report: 'Cierre DIAN',
+  cash_register_report: 'Cierre de caja',
+  low_stock_alert: 'Inventario bajo',
+  invoice_range_alert: 'Rango DIAN',
+  payment_link: 'Link de pago',
+  test: 'Prueba',
+};
+
+const formatDate = (value?: string) => (value ? new Date(value).toLocaleString('es-CO') : '');
+
+const MailSettings: React.FC = () => {
+  const [settings, setSettings] = useState<MailSettingsData>({
+    owner_emails: '',
+    send_invoices: false,
+    send_daily_closing_report: false,
+    daily_report_time: '06:00',
+    send_cash_register_reports: false,
+    send_low_stock_alerts: false,
+    send_invoice_range_alerts: false,
+    max_attempts: 5,
+    use_local_smtp: false,
+  });
+  const [testEmail, setTestEmail] = useState('');
+  const [sendingTest, setSendingTest] = useState(false);
+  const [statusFilter, setStatusFilter] = useState('');
+  const [messages, setMessages] = useState<MailMessage[]>([]);
+  const [selectedMessage, setSelectedMessage] = useState<MailMessage | null>(null);
+  const [inbox, setInbox] = useState<LocalSMTPInbox | null>(null);
+  const [selectedCaptured, setSelectedCaptured] = useState<CapturedMail | null>(null);
+
+  useEffect(() => {
+    loadSettings();
+  }, []);
+
+  useEffect(() => {
+    loadMessages();
+  }, [statusFilter]);
+
+  const loadSettings = async () => {
+    try {
+      const data = await wailsMailService.getSettings();
+      setSettings(data);
+      if (data.use_local_smtp) {
+        setInbox(await wailsMailService.getLocalInbox());
+      }
+    } catch (e: any) {
+      console.error('Error loading mail settings:', e);
+    }
+  };
+
+  const loadMessages = async () => {
+    try {
+      setMessages(await wailsMailService.getMessages(statusFilter, 50, 0));
+      if (settings.use_local_smtp) {
+        setInbox(await wailsMailService.getLocalInbox());
+      }
+    } catch (e: any) {
+      console.error('Error loading mail messages:', e);
+    }
+  };
+
+  const handleSave = async () => {
+    try {
+      await wailsMailService.saveSettings({
+        ...settings,
+        max_attempts: settings.max_attempts || 5,
+      });
+      toast.success('Configuración de correo guardada');
+      loadSettings();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error guardando configuración de correo');
+    }
+  };
+
+  const handleSendTest = async () => {
+    setSendingTest(true);
+    try {
+      await wailsMailService.sendTestEmail(testEmail);
+      toast.success('Correo de prueba enviado');
+    } catch (e: any) {
+      toast.error(e?.message || 'Error enviando correo de prueba');
+    } finally {
+      setSendingTest(false);
+      loadMessages();
+    }
+  };
+
+  const handleView = async (message: MailMessage) => {
+    try {
+      setSelectedMessage(await wailsMailService.getMessage(message.id));
+    } catch (e: any) {
+      toast.error(e?.message || 'Error cargando el correo');
+    }
+  };
+
+  const handleRetry = async (message: MailMessage) => {
+    try {
+      await wailsMailService.retryMessage(message.id);
+      toast.info('Reintento programado');
+      loadMessages();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error reintentando el correo');
+    }
+  };
+
+  const handleCancel = async (message: MailMessage) => {
+    if (!window.confirm(`¿Cancelar el envío de "${message.subject}"?`)) {
+      return;
+    }
+    try {
+      await wailsMailService.cancelMessage(message.id);
+      loadMessages();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error cancelando el correo');
+    }
+  };
+
+  const handleClearInbox = async () => {
+    try {
+      await wailsMailService.clearLocalInbox();
+      setInbox(await wailsMailService.getLocalInbox());
+    } catch (e: any) {
+      toast.error(e?.message || 'Error limpiando la bandeja');
+    }
+  };
+
+  const toggle = (field: keyof MailSettingsData) => (e: React.ChangeEvent<HTMLInputElement>) =>
+    setSettings({ ...settings, [field]: e.target.checked });
+
+  return (
+    <Box sx={{ border: 1, borderColor: 'divider', borderRadius: 1, p: 2 }}>
+      <Typography variant="subtitle2" gutterBottom>
+        Envío de correos
+      </Typography>
+      <Typography variant="caption" color="text.secondary" component="p" sx={{ mb: 2 }}>
+        Los correos se envían con el servidor SMTP configurado arriba (guarde la configuración antes de probar).
+        Los envíos fallidos se reintentan automáticamente.
+      </Typography>
+
+      <Grid container spacing={2}>
+        <Grid item xs={12}>
+          <TextField
+            fullWidth
+            size="small"
+            label="Correos de propietarios"
+            value={settings.owner_emails}
+            onChange={(e) => setSettings({ ...settings, owner_emails: e.target.value })}
+            helperText="Reciben los cierres y alertas. Separados por coma; vacío = correo del restaurante"
+          />
+        </Grid>
+        <Grid item xs={12} sm={6}>
+          <FormControlLabel
+            control={<Switch checked={settings.send_invoices} onChange={toggle('send_invoices')} />}
+            label="Enviar facturas a clientes (PDF y XML)"
+          />
+          <Typography variant="caption" color="text.secondary" component="p">
+            Reemplaza el envío de correo del proveedor DIAN
+          </Typography>
+        </Grid>
+        <Grid item xs={12} sm={6}>
+          <FormControlLabel
+            control={<Switch checked={settings.send_cash_register_reports} onChange={toggle('send_cash_register_reports')} />}
+            label="Enviar cierres de caja"
+          />
+        </Grid>
+        <Grid item xs={12} sm={6}>
+          <FormControlLabel
+            control={<Switch checked={settings.send_daily_closing_report} onChange={toggle('send_daily_closing_report')} />}
+            label="Enviar cierre DIAN diario"
+          />
+        </Grid>
+        <Grid item xs={12} sm={6}>
+          <TextField
+            fullWidth
+            size="small"
+            label="Hora de envío del cierre diario"
+            type="time"
+            value={settings.daily_report_time}
+            disabled={!settings.send_daily_closing_report}
+            onChange={(e) => setSettings({ ...settings, daily_report_time: e.target.value })}
+            InputLabelProps={{ shrink: true }}
+            helperText="Se envía el cierre del día anterior"
+          />
+        </Grid>
+        <Grid item xs={12} sm={6}>
+          <FormControlLabel
+            control={<Switch checked={settings.send_low_stock_alerts} onChange={toggle('send_low_stock_alerts')} />}
+            label="Alertas de inventario bajo"
+          />
+        </Grid>
+        <Grid item xs={12} sm={6}>
+          <FormControlLabel
+            control={<Switch checked={settings.send_invoice_range_alerts} onChange={toggle('send_invoice_range_alerts')} />}
+            label="Alertas de rango de numeración DIAN"
+          />
+        </Grid>
+        <Grid item xs={12} sm={6}>
+          <TextField
+            fullWidth
+            size="small"
+            label="Intentos de envío"
+            type="number"
+            value={settings.max_attempts}
+            onChange={(e) => setSettings({ ...settings, max_attempts: Number(e.target.value) })}
+            inputProps={{ min: 1, max: 20 }}
+          />
+        </Grid>
+        <Grid item xs={12} sm={6}>
+          <FormControlLabel
+            control={<Switch checked={settings.use_local_smtp} color="warning" onChange={toggle('use_local_smtp')} />}
+            label="Servidor de correo local (pruebas)"
+          />
+          <Typography variant="caption" color="text.secondary" component="p">
+            Los correos no salen del equipo; se pueden revisar abajo
+          </Typography>
+        </Grid>
+        <Grid item xs={12}>
+          <Button variant="contained" size="small" onClick={handleSave}>
+            Guardar Envío de Correos
+          </Button>
+        </Grid>
+
+        <Grid item xs={12}>
+          <Divider />
+        </Grid>
+        <Grid item xs={12} sm={8}>
+          <TextField
+            fullWidth
+            size="small"
+            label="Enviar correo de prueba a"
+            type="email"
+            value={testEmail}
+            onChange={(e) => setTestEmail(e.target.value)}
+            placeholder="Vacío = correos de propietarios"
+          />
+        </Grid>
+        <Grid item xs={12} sm={4}>
+          <Button
+            fullWidth
+            variant="outlined"
+            startIcon={<SendIcon />}
+            onClick={handleSendTest}
+            disabled={sendingTest}
+          >
+            {sendingTest ? 'Enviando...' : 'Enviar Prueba'}
+          </Button>
+        </Grid>
+      </Grid>
+
+      <Box sx={{ display: 'flex', alignItems: 'center', gap: 1, mt: 3, mb: 1 }}>
+        <Typography variant="subtitle2" sx={{ flexGrow: 1 }}>
+          Registro de envíos
+        </Typography>
+        <FormControl size="small" sx={{ minWidth: 150 }}>
+          <InputLabel>Estado</InputLabel>
+          <Select value={statusFilter} label="Estado" onChange={(e) => setStatusFilter(e.target.value)}>
+            <MenuItem value="">Todos</MenuItem>
+            {Object.entries(statusLabels).map(([value, { label }]) => (
+              <MenuItem key={value} value={value}>{label}</MenuItem>
+            ))}
+          </Select>
+        </FormControl>
+        <IconButton size="small" onClick={loadMessages}>
+          <RefreshIcon />
+        </IconButton>
+      </Box>
+      <TableContainer sx={{ maxHeight: 320 }}>
+        <Table size="small" stickyHeader>
+          <TableHead>
+            <TableRow>
+              <TableCell>Fecha</TableCell>
+              <TableCell>Tipo</TableCell>
+              <TableCell>Para</TableCell>
+              <TableCell>Asunto</TableCell>
+              <TableCell>Estado</TableCell>
+              <TableCell align="right">Acciones</TableCell>
+            </TableRow>
+          </TableHead>
+          <TableBody>
+            {messages.length === 0 ? (
+              <TableRow>
+                <TableCell colSpan={6} align="center">
+                  <Typography variant="body2" color="text.secondary">Sin correos</Typography>
+                </TableCell>
+              </TableRow>
+            ) : (
+              messages.map((message) => {
+                const status = statusLabels[message.status] || { label: message.status, color: 'default' as const };
+                return (
+                  <TableRow key={message.id} hover>
+                    <TableCell>{formatDate(message.created_at)}</TableCell>
+                    <TableCell>{kindLabels[message.kind] || message.kind}</TableCell>
+                    <TableCell sx={{ maxWidth: 180, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' }}>
+                      {message.to}
+                    </TableCell>
+                    <TableCell sx={{ maxWidth: 220, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' }}>
+                      {message.subject}
+                    </TableCell>
+                    <TableCell>
+                      <Tooltip title={message.last_error || ''}>
+                        <Chip size="small" color={status.color} label={`${status.label} (${message.attempts}/${message.max_attempts})`} />
+                      </Tooltip>
+                    </TableCell>
+                    <TableCell align="right" sx={{ whiteSpace: 'nowrap' }}>
+                      <Tooltip title="Ver detalle">
+                        <IconButton size="small" onClick={() => handleView(message)}>
+                          <ViewIcon fontSize="small" />
+                        </IconButton>
+                      </Tooltip>
+                      {message.status !== 'sent' && (
+                        <Tooltip title="Reintentar">
+                          <IconButton size="small" onClick={() => handleRetry(message)}>
+                            <RetryIcon fontSize="small" />
+                          </IconButton>
+                        </Tooltip>
+                      )}
+                      {(message.status === 'pending' || message.status === 'retrying') && (
+                        <Tooltip title="Cancelar">
+                          <IconButton size="small" color="error" onClick={() => handleCancel(message)}>
+                            <CancelIcon fontSize="small" />
+                          </IconButton>
+                        </Tooltip>
+                      )}
+                    </TableCell>
+                  </TableRow>
+                );
+              })
+            )}
+          </TableBody>
+        </Table>
+      </TableContainer>
+
+      {settings.use_local_smtp && (
+        <>
+          <Box sx={{ display: 'flex', alignItems: 'center', gap: 1, mt: 3, mb: 1 }}>
+            <Typography variant="subtitle2" sx={{ flexGrow: 1 }}>
+              Bandeja del servidor local
+            </Typography>
+            {inbox?.running ? (
+              <Chip size="small" color="success" label={`Activo en ${inbox.address}`} />
+            ) : (
+              <Chip size="small" label="Se inicia con el primer envío" />
+            )}
+            <Tooltip title="Vaciar bandeja">
+              <span>
+                <IconButton size="small" onClick={handleClearInbox} disabled={!inbox?.messages?.length}>
+                  <ClearIcon />
+                </IconButton>
+              </span>
+            </Tooltip>
+          </Box>
+          {!inbox?.messages?.length ? (
+            <Alert severity="info">No se han recibido correos</Alert>
+          ) : (
+            <TableContainer sx={{ maxHeight: 280 }}>
+              <Table size="small" stickyHeader>
+                <TableHead>
+                  <TableRow>
+                    <TableCell>Recibido</TableCell>
+                    <TableCell>Para</TableCell>
+                    <TableCell>Asunto</TableCell>
+                    <TableCell>Adjuntos</TableCell>
+                    <TableCell />
+                  </TableRow>
+                </TableHead>
+                <TableBody>
+                  {inbox.messages.map((mail) => (
+                    <TableRow key={mail.id} hover>
+                      <TableCell>{formatDate(mail.received_at)}</TableCell>
+                      <TableCell>{(mail.to || []).join(', ')}</TableCell>
+                      <TableCell>{mail.subject}</TableCell>
+                      <TableCell>{(mail.attachments || []).map((a) => a.file_name).join(', ')}</TableCell>
+                      <TableCell align="right">
+                        <IconButton size="small" onClick={() => setSelectedCaptured(mail)}>
+                          <ViewIcon fontSize="small" />
+                        </IconButton>
+                      </TableCell>
+                    </TableRow>
+                  ))}
+                </TableBody>
+              </Table>
+            </TableContainer>
+          )}
+        </>
+      )}
+
+      <Dialog open={!!selectedMessage} onClose={() => setSelectedMessage(null)} maxWidth="md" fullWidth>
+        <DialogTitle>{selectedMessage?.subject}</DialogTitle>
+        <DialogContent dividers>
+          {selectedMessage && (
+            <>
+              <Typography variant="body2">Para: {selectedMessage.to}</Typography>
+              <Typography variant="body2">
+                Estado: {(statusLabels[selectedMessage.status] || { label: selectedMessage.status }).label}
+                {selectedMessage.sent_at && ` - ${formatDate(selectedMessage.sent_at)}`}
+                {selectedMessage.next_attempt_at && ` - próximo intento ${formatDate(selectedMessage.next_attempt_at)}`}
+              </Typography>
+              {!!selectedMessage.attachments?.length && (
+                <Typography variant="body2">
+                  Adjuntos: {selectedMessage.attachments.map((a) => `${a.file_name} (${Math.ceil(a.size / 1024)} KB)`).join(', ')}
+                </Typography>
+              )}
+              <Typography variant="subtitle2" sx={{ mt: 2 }}>Intentos</Typography>
+              <Table size="small">
+                <TableHead>
+                  <TableRow>
+                    <TableCell>#</TableCell>
+                    <TableCell>Fecha</TableCell>
+                    <TableCell>Servidor</TableCell>
+                    <TableCell>Duración</TableCell>
+                    <TableCell>Resultado</TableCell>
+                  </TableRow>
+                </TableHead>
+                <TableBody>
+                  {(selectedMessage.delivery_log || []).map((attempt) => (
+                    <TableRow key={attempt.id}>
+                      <TableCell>{attempt.attempt}</TableCell>
+                      <TableCell>{formatDate(attempt.created_at)}</TableCell>
+                      <TableCell>{attempt.server}</TableCell>
+                      <TableCell>{attempt.duration_ms} ms</TableCell>
+                      <TableCell sx={{ color: attempt.success ? 'success.main' : 'error.main' }}>
+                        {attempt.success ? 'Enviado' : attempt.error}
+                      </TableCell>
+                    </TableRow>
+                  ))}
+                </TableBody>
+              </Table>
+              {selectedMessage.html_body && (
+                <Box sx={{ mt: 2, border: 1, borderColor: 'divider' }}>
+                  <iframe title="mail-preview" srcDoc={selectedMessage.html_body} sandbox="" style={{ width: '100%', height: 400, border: 0 }} />
+                </Box>
+              )}
+            </>
+          )}
+        </DialogContent>
+        <DialogActions>
+          <Button onClick={() => setSelectedMessage(null)}>Cerrar</Button>
+        </DialogActions>
+      </Dialog>
+
+      <Dialog open={!!selectedCaptured} onClose={() => setSelectedCaptured(null)} maxWidth="md" fullWidth>
+        <DialogTitle>{selectedCaptured?.subject}</DialogTitle>
+        <DialogContent dividers>
+          {selectedCaptured && (
+            <>
+              <Typography variant="body2">De: {selectedCaptured.from}</Typography>
+              <Typography variant="body2">Para: {(selectedCaptured.to || []).join(', ')}</Typography>
+              {!!selectedCaptured.attachments?.length && (
+                <Typography variant="body2">
+                  Adjuntos: {selectedCaptured.attachments.map((a) => `${a.file_name} (${Math.ceil(a.size / 1024)} KB)`).join(', ')}
+                </Typography>
+              )}
+              <Box sx={{ mt: 2, border: 1, borderColor: 'divider' }}>
+                <iframe title="captured-preview" srcDoc={selectedCaptured.html_body} sandbox="" style={{ width: '100%', height: 400, border: 0 }} />
+              </Box>
+            </>
+          )}
+        </DialogContent>
+        <DialogActions>
+          <Button onClick={() => setSelectedCaptured(null)}>Cerrar</Button>
+        </DialogActions>
+      </Dialog>
+    </Box>
+  );
+};
+
+export default MailSettings;
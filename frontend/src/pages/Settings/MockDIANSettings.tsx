@@ -0,0 +1,222 @@
+import React, { useState, useEffect } from 'react';
+import {
+  Box,
+  Typography,
+  Grid,
+  TextField,
+  Button,
+  Alert,
+  Chip,
+  Switch,
+  FormControlLabel,
+} from '@mui/material';
+import { toast } from 'react-toastify';
+import { wailsDianService } from '../../services/wailsDianService';
+
+interface MockForm {
+  latencyMs: number;
+  asyncValidation: boolean;
+  validationSeconds: number;
+  rejectAbove: number;
+  rejectCustomers: string;
+  rejectRate: number;
+  serviceUnavailable: boolean;
+}
+
+const MockDIANSettings: React.FC = () => {
+  const [form, setForm] = useState<MockForm>({
+    latencyMs: 300,
+    asyncValidation: true,
+    validationSeconds: 5,
+    rejectAbove: 0,
+    rejectCustomers: '',
+    rejectRate: 0,
+    serviceUnavailable: false,
+  });
+  const [status, setStatus] = useState<any>(null);
+
+  useEffect(() => {
+    loadData();
+  }, []);
+
+  const loadData = async () => {
+    try {
+      const config = await wailsDianService.getConfig();
+      if (config) {
+        setForm({
+          latencyMs: config.mock_latency_ms ?? 300,
+          asyncValidation: config.mock_async_validation ?? true,
+          validationSeconds: config.mock_validation_seconds ?? 5,
+          rejectAbove: config.mock_reject_above || 0,
+          rejectCustomers: config.mock_reject_customers || '',
+          rejectRate: config.mock_reject_rate || 0,
+          serviceUnavailable: config.mock_service_unavailable || false,
+        });
+      }
+      setStatus(await wailsDianService.getMockDianStatus());
+    } catch (e: any) {
+      console.error('Error loading mock DIAN settings:', e);
+    }
+  };
+
+  const handleSave = async () => {
+    try {
+      const currentDianConfig = await wailsDianService.getConfig();
+      await wailsDianService.updateConfig({
+        ...currentDianConfig,
+        mock_latency_ms: form.latencyMs || 0,
+        mock_async_validation: form.asyncValidation,
+        mock_validation_seconds: form.validationSeconds || 0,
+        mock_reject_above: form.rejectAbove || 0,
+        mock_reject_customers: form.rejectCustomers,
+        mock_reject_rate: Math.min(Math.max(form.rejectRate || 0, 0), 100),
+        mock_service_unavailable: form.serviceUnavailable,
+      });
+      toast.success('Configuración del simulador guardada');
+      loadData();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error guardando configuración del simulador');
+    }
+  };
+
+  const handleReset = async () => {
+    if (!window.confirm('¿Borrar los documentos recibidos por el simulador?\n\nLos números ya enviados podrán reutilizarse.')) {
+      return;
+    }
+    try {
+      await wailsDianService.resetMockDian();
+      toast.success('Simulador reiniciado');
+      loadData();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error reiniciando simulador');
+    }
+  };
+
+  return (
+    <Box sx={{ border: 1, borderColor: 'warning.light', borderRadius: 1, p: 2 }}>
+      <Box sx={{ display: 'flex', gap: 1, flexWrap: 'wrap', alignItems: 'center', mb: 2 }}>
+        <Typography variant="subtitle2" sx={{ mr: 1 }}>
+          Simulador DIAN
+        </Typography>
+        {status?.running ? (
+          <>
+            <Chip size="small" color="success" label={`Activo en ${status.url}`} />
+            <Chip size="small" label={`Ambiente: ${status.environment === 'production' ? 'Producción' : 'Pruebas'}`} />
+            <Chip size="small" label={`Documentos: ${status.documents_received || 0}`} />
+            {status.async_pending > 0 && (
+              <Chip size="small" color="info" label={`En validación: ${status.async_pending}`} />
+            )}
+          </>
+        ) : (
+          <Chip size="small" label="Se inicia al guardar y usar la facturación" />
+        )}
+      </Box>
+
+      {form.serviceUnavailable && (
+        <Alert severity="warning" sx={{ mb: 2 }}>
+          El simulador responde como si la DIAN estuviera caída: sirve para practicar la facturación de contingencia.
+        </Alert>
+      )}
+
+      <Grid container spacing={2}>
+        <Grid item xs={12} sm={4}>
+          <TextField
+            fullWidth
+            size="small"
+            label="Latencia (ms)"
+            type="number"
+            value={form.latencyMs}
+            onChange={(e) => setForm({ ...form, latencyMs: Number(e.target.value) })}
+            helperText="Demora agregada a cada respuesta"
+          />
+        </Grid>
+        <Grid item xs={12} sm={4}>
+          <FormControlLabel
+            control={
+              <Switch
+                checked={form.asyncValidation}
+                onChange={(e) => setForm({ ...form, asyncValidation: e.target.checked })}
+              />
+            }
+            label="Validación asíncrona (zip key)"
+          />
+        </Grid>
+        <Grid item xs={12} sm={4}>
+          <TextField
+            fullWidth
+            size="small"
+            label="Tiempo de validación (s)"
+            type="number"
+            value={form.validationSeconds}
+            disabled={!form.asyncValidation}
+            onChange={(e) => setForm({ ...form, validationSeconds: Number(e.target.value) })}
+          />
+        </Grid>
+
+        <Grid item xs={12}>
+          <Typography variant="caption" color="text.secondary">
+            Reglas de rechazo (además de las reglas propias: número fuera de rango, documento duplicado y totales que no cuadran)
+          </Typography>
+        </Grid>
+        <Grid item xs={12} sm={4}>
+          <TextField
+            fullWidth
+            size="small"
+            label="Rechazar montos mayores a"
+            type="number"
+            value={form.rejectAbove}
+            onChange={(e) => setForm({ ...form, rejectAbove: Number(e.target.value) })}
+            helperText="0 = nunca"
+          />
+        </Grid>
+        <Grid item xs={12} sm={4}>
+          <TextField
+            fullWidth
+            size="small"
+            label="Rechazar clientes (NIT/CC)"
+            value={form.rejectCustomers}
+            onChange={(e) => setForm({ ...form, rejectCustomers: e.target.value })}
+            helperText="Separados por coma"
+          />
+        </Grid>
+        <Grid item xs={12} sm={4}>
+          <TextField
+            fullWidth
+            size="small"
+            label="Rechazo aleatorio (%)"
+            type="number"
+            value={form.rejectRate}
+            onChange={(e) => setForm({ ...form, rejectRate: Number(e.target.value) })}
+            inputProps={{ min: 0, max: 100 }}
+          />
+        </Grid>
+
+        <Grid item xs={12}>
+          <FormControlLabel
+            control={
+              <Switch
+                checked={form.serviceUnavailable}
+                color="error"
+                onChange={(e) => setForm({ ...form, serviceUnavailable: e.target.checked })}
+              />
+            }
+            label="Simular caída del servicio DIAN"
+          />
+        </Grid>
+
+        <Grid item xs={12}>
+          <Box sx={{ display: 'flex', gap: 1 }}>
+            <Button variant="contained" size="small" onClick={handleSave}>
+              Guardar Simulador
+            </Button>
+            <Button variant="outlined" size="small" color="warning" onClick={handleReset} disabled={!status?.running}>
+              Reiniciar Documentos
+            </Button>
+          </Box>
+        </Grid>
+      </Grid>
+    </Box>
+  );
+};
+
+export default MockDIANSettings;
@@ -0,0 +1,363 @@
+import React, { useState, useEffect } from 'react';
+import {
+  Box,
+  Typography,
+  Grid,
+  TextField,
+  Button,
+  Alert,
+  Chip,
+  Switch,
+  FormControlLabel,
+  Table,
+  TableHead,
+  TableBody,
+  TableRow,
+  TableCell,
+  TableContainer,
+  IconButton,
+  Tooltip,
+  MenuItem,
+  Select,
+  FormControl,
+  InputLabel,
+} from '@mui/material';
+import {
+  Refresh as RefreshIcon,
+  Replay as RetryIcon,
+  Cancel as CancelIcon,
+  Print as PrintIcon,
+} from '@mui/icons-material';
+import { toast } from 'react-toastify';
+import {
+  wailsPrintSpoolerService,
+  PrintSpoolerSettings as PrintSpoolerSettingsData,
+  PrintJob,
+  PrinterQueueStatus,
+} from '../../services/wailsPrintSpoolerService';
+
+const statusLabels: Record<string, { label: string; color: 'default' | 'success' | 'warning' | 'error' | 'info' }> = {
+  pending: { label: 'Pendiente', color: 'info' },
+  printing: { label: 'Imprimiendo', color: 'info' },
+  retrying: { label: 'Reintentando', color: 'warning' },
+  printed: { label: 'Impreso', color: 'success' },
+  failed: { label: 'Fallido', color: 'error' },
+  cancelled: { label: 'Cancelado', color: 'default' },
+  unknown: { label: 'Sin confirmar', color: 'error' },
+};
+
+const jobTypeLabels: Record<string, string> = {
+  receipt: 'Recibo',
+  invoice: 'Factura',
+  kitchen: 'Comanda',
+  order: 'Orden',
+  waiter_receipt: 'Cuenta mesero',
+  cash_register_report: 'Cierre de caja',
+  dian_closing_report: 'Cierre DIAN',
+  customer_form: 'Formulario cliente',
+};
+
+const formatDate = (value?: string) => (value ? new Date(value).toLocaleString('es-CO') : '');
+
+interface PrintSpoolerSettingsProps {
+  printers: any[];
+}
+
+const PrintSpoolerSettings: React.FC<PrintSpoolerSettingsProps> = ({ printers }) => {
+  const [settings, setSettings] = useState<PrintSpoolerSettingsData>({
+    enabled: true,
+    max_attempts: 5,
+    failover_enabled: true,
+    retention_days: 7,
+  });
+  const [queues, setQueues] = useState<PrinterQueueStatus[]>([]);
+  const [jobs, setJobs] = useState<PrintJob[]>([]);
+  const [statusFilter, setStatusFilter] = useState('');
+  const [printerFilter, setPrinterFilter] = useState<number>(0);
+  const [reprintCount, setReprintCount] = useState(1);
+  const [reprintType, setReprintType] = useState('');
+
+  useEffect(() => {
+    loadSettings();
+  }, []);
+
+  useEffect(() => {
+    loadJobs();
+  }, [statusFilter, printerFilter]);
+
+  const printerName = (id?: number) => printers.find((p) => p.id === id)?.name || (id ? `#${id}` : '');
+
+  const loadSettings = async () => {
+    try {
+      setSettings(await wailsPrintSpoolerService.getSettings());
+    } catch (e: any) {
+      console.error('Error loading print spooler settings:', e);
+    }
+  };
+
+  const loadJobs = async () => {
+    try {
+      setQueues(await wailsPrintSpoolerService.getQueueStatus());
+      setJobs(await wailsPrintSpoolerService.getJobs(statusFilter, printerFilter, 50, 0));
+    } catch (e: any) {
+      console.error('Error loading print jobs:', e);
+    }
+  };
+
+  const handleSave = async () => {
+    try {
+      await wailsPrintSpoolerService.saveSettings({
+        ...settings,
+        max_attempts: settings.max_attempts || 5,
+        retention_days: settings.retention_days || 7,
+      });
+      toast.success('Configuración de cola de impresión guardada');
+      loadSettings();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error guardando configuración de impresión');
+    }
+  };
+
+  const handleRetry = async (job: PrintJob) => {
+    try {
+      await wailsPrintSpoolerService.retryJob(job.id);
+      toast.info('Reintento programado');
+      loadJobs();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error reintentando la impresión');
+    }
+  };
+
+  const handleCancel = async (job: PrintJob) => {
+    if (!window.confirm(`¿Cancelar la impresión de "${job.description}"?`)) {
+      return;
+    }
+    try {
+      await wailsPrintSpoolerService.cancelJob(job.id);
+      loadJobs();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error cancelando la impresión');
+    }
+  };
+
+  const handleReprint = async (job: PrintJob) => {
+    try {
+      await wailsPrintSpoolerService.reprintJob(job.id);
+      toast.info(`Reimprimiendo ${job.description}`);
+      loadJobs();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error reimprimiendo');
+    }
+  };
+
+  const handleReprintLast = async () => {
+    try {
+      const reprints = await wailsPrintSpoolerService.reprintLast(reprintCount, printerFilter, reprintType);
+      toast.info(`${reprints.length} documento(s) enviados a reimprimir`);
+      loadJobs();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error reimprimiendo');
+    }
+  };
+
+  const toggle = (field: keyof PrintSpoolerSettingsData) => (e: React.ChangeEvent<HTMLInputElement>) =>
+    setSettings({ ...settings, [field]: e.target.checked });
+
+  return (
+    <Box>
+      <Typography variant="h6" gutterBottom>
+        Cola de Impresión
+      </Typography>
+      <Typography variant="body2" color="text.secondary" sx={{ mb: 2 }}>
+        Los documentos se imprimen en segundo plano con una cola por impresora. Si una impresora falla,
+        el trabajo pasa a su impresora de respaldo y se reintenta automáticamente.
+      </Typography>
+
+      <Grid container spacing={2}>
+        <Grid item xs={12} sm={6}>
+          <FormControlLabel
+            control={<Switch checked={settings.enabled} onChange={toggle('enabled')} />}
+            label="Imprimir en segundo plano"
+          />
+        </Grid>
+        <Grid item xs={12} sm={6}>
+          <FormControlLabel
+            control={<Switch checked={settings.failover_enabled} onChange={toggle('failover_enabled')} />}
+            label="Usar impresora de respaldo cuando falla"
+          />
+        </Grid>
+        <Grid item xs={12} sm={4}>
+          <TextField
+            fullWidth
+            size="small"
+            label="Intentos de impresión"
+            type="number"
+            value={settings.max_attempts}
+            onChange={(e) => setSettings({ ...settings, max_attempts: Number(e.target.value) })}
+            inputProps={{ min: 1, max: 20 }}
+          />
+        </Grid>
+        <Grid item xs={12} sm={4}>
+          <TextField
+            fullWidth
+            size="small"
+            label="Días de historial"
+            type="number"
+            value={settings.retention_days}
+            onChange={(e) => setSettings({ ...settings, retention_days: Number(e.target.value) })}
+            inputProps={{ min: 1, max: 90 }}
+            helperText="Para reimprimir documentos"
+          />
+        </Grid>
+        <Grid item xs={12} sm={4}>
+          <Button variant="contained" size="small" onClick={handleSave}>
+            Guardar Cola de Impresión
+          </Button>
+        </Grid>
+      </Grid>
+
+      {queues.some((q) => q.failed > 0 || q.retrying > 0) && (
+        <Alert severity="warning" sx={{ mt: 2 }}>
+          Hay impresiones pendientes por fallas de impresora. Revise las impresoras marcadas abajo.
+        </Alert>
+      )}
+
+      <Box sx={{ display: 'flex', flexWrap: 'wrap', gap: 1, mt: 2 }}>
+        {queues.map((queue) => (
+          <Tooltip key={queue.printer_id} title={queue.last_error || `Última impresión: ${formatDate(queue.last_printed_at) || '-'}`}>
+            <Chip
+              icon={<PrintIcon />}
+              color={queue.failed > 0 ? 'error' : queue.retrying > 0 ? 'warning' : 'default'}
+              variant={queue.is_active ? 'filled' : 'outlined'}
+              label={`${queue.printer_name}: ${queue.pending} en cola, ${queue.retrying} reintentando, ${queue.failed} fallidos${
+                queue.backup_printer_name ? ` · respaldo ${queue.backup_printer_name}` : ''
+              }`}
+            />
+          </Tooltip>
+        ))}
+      </Box>
+
+      <Box sx={{ display: 'flex', alignItems: 'center', flexWrap: 'wrap', gap: 1, mt: 3, mb: 1 }}>
+        <Typography variant="subtitle2" sx={{ flexGrow: 1 }}>
+          Trabajos de impresión
+        </Typography>
+        <FormControl size="small" sx={{ minWidth: 150 }}>
+          <InputLabel>Impresora</InputLabel>
+          <Select value={printerFilter} label="Impresora" onChange={(e) => setPrinterFilter(Number(e.target.value))}>
+            <MenuItem value={0}>Todas</MenuItem>
+            {printers.map((printer) => (
+              <MenuItem key={printer.id} value={printer.id}>{printer.name}</MenuItem>
+            ))}
+          </Select>
+        </FormControl>
+        <FormControl size="small" sx={{ minWidth: 150 }}>
+          <InputLabel>Estado</InputLabel>
+          <Select value={statusFilter} label="Estado" onChange={(e) => setStatusFilter(e.target.value)}>
+            <MenuItem value="">Todos</MenuItem>
+            {Object.entries(statusLabels).map(([value, { label }]) => (
+              <MenuItem key={value} value={value}>{label}</MenuItem>
+            ))}
+          </Select>
+        </FormControl>
+        <IconButton size="small" onClick={loadJobs}>
+          <RefreshIcon />
+        </IconButton>
+      </Box>
+
+      <Box sx={{ display: 'flex', alignItems: 'center', flexWrap: 'wrap', gap: 1, mb: 1 }}>
+        <Typography variant="body2">Reimprimir los últimos</Typography>
+        <TextField
+          size="small"
+          type="number"
+          value={reprintCount}
+          onChange={(e) => setReprintCount(Number(e.target.value))}
+          inputProps={{ min: 1, max: 20 }}
+          sx={{ width: 80 }}
+        />
+        <FormControl size="small" sx={{ minWidth: 160 }}>
+          <InputLabel>Documento</InputLabel>
+          <Select value={reprintType} label="Documento" onChange={(e) => setReprintType(e.target.value)}>
+            <MenuItem value="">Todos</MenuItem>
+            {Object.entries(jobTypeLabels).map(([value, label]) => (
+              <MenuItem key={value} value={value}>{label}</MenuItem>
+            ))}
+          </Select>
+        </FormControl>
+        <Button variant="outlined" size="small" startIcon={<PrintIcon />} onClick={handleReprintLast}>
+          Reimprimir
+        </Button>
+      </Box>
+
+      <TableContainer sx={{ maxHeight: 320 }}>
+        <Table size="small" stickyHeader>
+          <TableHead>
+            <TableRow>
+              <TableCell>Fecha</TableCell>
+              <TableCell>Documento</TableCell>
+              <TableCell>Impresora</TableCell>
+              <TableCell>Estado</TableCell>
+              <TableCell align="right">Acciones</TableCell>
+            </TableRow>
+          </TableHead>
+          <TableBody>
+            {jobs.length === 0 ? (
+              <TableRow>
+                <TableCell colSpan={5} align="center">
+                  <Typography variant="body2" color="text.secondary">Sin trabajos de impresión</Typography>
+                </TableCell>
+              </TableRow>
+            ) : (
+              jobs.map((job) => {
+                const status = statusLabels[job.status] || { label: job.status, color: 'default' as const };
+                return (
+                  <TableRow key={job.id} hover>
+                    <TableCell>{formatDate(job.created_at)}</TableCell>
+                    <TableCell>
+                      {jobTypeLabels[job.job_type] || job.job_type}
+                      {job.description && ` · ${job.description}`}
+                      {job.is_reprint && ' (reimpresión)'}
+                    </TableCell>
+                    <TableCell>
+                      {printerName(job.printer_id)}
+                      {job.printed_on_id && job.printed_on_id !== job.printer_id && ` → ${printerName(job.printed_on_id)}`}
+                    </TableCell>
+                    <TableCell>
+                      <Tooltip title={job.last_error || ''}>
+                        <Chip size="small" color={status.color} label={`${status.label} (${job.attempts}/${job.max_attempts})`} />
+                      </Tooltip>
+                    </TableCell>
+                    <TableCell align="right" sx={{ whiteSpace: 'nowrap' }}>
+                      {job.status === 'printed' && (
+                        <Tooltip title="Reimprimir">
+                          <IconButton size="small" onClick={() => handleReprint(job)}>
+                            <PrintIcon fontSize="small" />
+                          </IconButton>
+                        </Tooltip>
+                      )}
+                      {(job.status === 'failed' || job.status === 'retrying' || job.status === 'cancelled' || job.status === 'unknown') && (
+                        <Tooltip title="Reintentar">
+                          <IconButton size="small" onClick={() => handleRetry(job)}>
+                            <RetryIcon fontSize="small" />
+                          </IconButton>
+                        </Tooltip>
+                      )}
+                      {(job.status === 'pending' || job.status === 'retrying' || job.status === 'unknown') && (
+                        <Tooltip title="Cancelar">
+                          <IconButton size="small" color="error" onClick={() => handleCancel(job)}>
+                            <CancelIcon fontSize="small" />
+                          </IconButton>
+                        </Tooltip>
+                      )}
+                    </TableCell>
+                  </TableRow>
+                );
+              })
+            )}
+          </TableBody>
+        </Table>
+      </TableContainer>
+    </Box>
+  );
+};
+
+export default PrintSpoolerSettings;
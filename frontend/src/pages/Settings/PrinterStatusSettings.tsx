@@ -0,0 +1,254 @@
+import React, { useState, useEffect } from 'react';
+import {
+  Box,
+  Typography,
+  Grid,
+  TextField,
+  Button,
+  Alert,
+  Chip,
+  Switch,
+  FormControlLabel,
+  Table,
+  TableHead,
+  TableBody,
+  TableRow,
+  TableCell,
+  TableContainer,
+  IconButton,
+  Tooltip,
+} from '@mui/material';
+import { Refresh as RefreshIcon } from '@mui/icons-material';
+import { toast } from 'react-toastify';
+import { useWebSocket } from '../../hooks';
+import {
+  wailsPrinterMonitorService,
+  PrinterMonitorSettings,
+  PrinterStatus,
+} from '../../services/wailsPrinterMonitorService';
+
+const statusLabels: Record<string, { label: string; color: 'default' | 'success' | 'warning' | 'error' | 'info' }> = {
+  online: { label: 'Lista', color: 'success' },
+  paper_near_end: { label: 'Papel por agotarse', color: 'warning' },
+  paper_out: { label: 'Sin papel', color: 'error' },
+  cover_open: { label: 'Tapa abierta', color: 'error' },
+  offline: { label: 'Fuera de línea', color: 'error' },
+  error: { label: 'Error', color: 'error' },
+  unknown: { label: 'Sin estado', color: 'default' },
+};
+
+// Printer types whose status can be read (ESC/POS DLE EOT or IPP)
+const monitoredTypes = ['network', 'usb', 'serial', 'cups'];
+
+// Same resolution as the backend: the type, or else the connection type
+const printerType = (printer: any): string => {
+  if (printer.type) return printer.type;
+  if ((printer.address || '').startsWith('\\\\')) return 'windows';
+  switch (printer.connection_type) {
+    case 'ethernet':
+    case 'network':
+      return 'network';
+    case 'serial':
+      return 'serial';
+    case 'windows_share':
+      return 'windows';
+    case 'cups':
+      return 'cups';
+    default:
+      return 'usb';
+  }
+};
+
+const formatDate = (value?: string) => (value ? new Date(value).toLocaleString('es-CO') : '');
+
+interface PrinterStatusSettingsProps {
+  printers: any[];
+}
+
+const PrinterStatusSettings: React.FC<PrinterStatusSettingsProps> = ({ printers }) => {
+  const { subscribe } = useWebSocket();
+  const [settings, setSettings] = useState<PrinterMonitorSettings>({
+    enabled: true,
+    interval_seconds: 15,
+    kitchen_fallback: true,
+  });
+  const [statuses, setStatuses] = useState<PrinterStatus[]>([]);
+  const [checking, setChecking] = useState<number | null>(null);
+
+  useEffect(() => {
+    loadSettings();
+    loadStatuses();
+  }, []);
+
+  useEffect(() => {
+    const unsubscribe = subscribe('printer_status_changed', () => {
+      loadStatuses();
+    });
+    return () => {
+      unsubscribe();
+    };
+  }, [subscribe]);
+
+  const loadSettings = async () => {
+    try {
+      setSettings(await wailsPrinterMonitorService.getSettings());
+    } catch (e: any) {
+      console.error('Error loading printer monitor settings:', e);
+    }
+  };
+
+  const loadStatuses = async () => {
+    try {
+      setStatuses(await wailsPrinterMonitorService.getStatuses());
+    } catch (e: any) {
+      console.error('Error loading printer statuses:', e);
+    }
+  };
+
+  const handleSave = async () => {
+    try {
+      await wailsPrinterMonitorService.saveSettings(settings);
+      toast.success('Configuración del monitor de impresoras guardada');
+      loadSettings();
+      loadStatuses();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error guardando configuración del monitor');
+    }
+  };
+
+  const handleCheck = async (printerId: number) => {
+    setChecking(printerId);
+    try {
+      const status = await wailsPrinterMonitorService.checkPrinter(printerId);
+      toast.info(`${status.printer_name}: ${status.message}`);
+      loadStatuses();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error consultando la impresora');
+    } finally {
+      setChecking(null);
+    }
+  };
+
+  const toggle = (field: 'enabled' | 'kitchen_fallback') => (e: React.ChangeEvent<HTMLInputElement>) =>
+    setSettings({ ...settings, [field]: e.target.checked });
+
+  const monitoredPrinters = printers.filter(
+    (printer) => printer.is_active && monitoredTypes.includes(printerType(printer))
+  );
+  const statusOf = (printerId: number) => statuses.find((s) => s.printer_id === printerId);
+
+  return (
+    <Box>
+      <Typography variant="h6" gutterBottom>
+        Estado de Impresoras
+      </Typography>
+      <Typography variant="body2" color="text.secondary" sx={{ mb: 2 }}>
+        Consulta periódicamente las impresoras de red, USB, serial y CUPS para detectar tapa abierta,
+        papel por agotarse, falta de papel o desconexión. Las comandas no se envían a una impresora con
+        problemas: pasan a su respaldo o quedan retenidas hasta que se recupere.
+      </Typography>
+
+      <Grid container spacing={2}>
+        <Grid item xs={12} sm={4}>
+          <FormControlLabel
+            control={<Switch checked={settings.enabled} onChange={toggle('enabled')} />}
+            label="Monitorear impresoras"
+          />
+        </Grid>
+        <Grid item xs={12} sm={4}>
+          <FormControlLabel
+            control={<Switch checked={settings.kitchen_fallback} onChange={toggle('kitchen_fallback')} />}
+            label="Enviar comandas a la impresora predeterminada si cocina falla"
+          />
+        </Grid>
+        <Grid item xs={12} sm={2}>
+          <TextField
+            fullWidth
+            size="small"
+            label="Intervalo (seg)"
+            type="number"
+            value={settings.interval_seconds}
+            onChange={(e) => setSettings({ ...settings, interval_seconds: Number(e.target.value) })}
+            inputProps={{ min: 5, max: 60 }}
+          />
+        </Grid>
+        <Grid item xs={12} sm={2}>
+          <Button variant="contained" size="small" onClick={handleSave}>
+            Guardar Monitor
+          </Button>
+        </Grid>
+      </Grid>
+
+      {statuses.some((s) => ['paper_out', 'cover_open', 'offline', 'error'].includes(s.status)) && (
+        <Alert severity="error" sx={{ mt: 2 }}>
+          Hay impresoras que no pueden imprimir. Revise el papel, la tapa o la conexión.
+        </Alert>
+      )}
+
+      <Box sx={{ display: 'flex', alignItems: 'center', mt: 3, mb: 1 }}>
+        <Typography variant="subtitle2" sx={{ flexGrow: 1 }}>
+          Impresoras monitoreadas
+        </Typography>
+        <IconButton size="small" onClick={loadStatuses}>
+          <RefreshIcon />
+        </IconButton>
+      </Box>
+
+      <TableContainer>
+        <Table size="small">
+          <TableHead>
+            <TableRow>
+              <TableCell>Impresora</TableCell>
+              <TableCell>Estado</TableCell>
+              <TableCell>Desde</TableCell>
+              <TableCell>Última consulta</TableCell>
+              <TableCell align="right">Acciones</TableCell>
+            </TableRow>
+          </TableHead>
+          <TableBody>
+            {monitoredPrinters.length === 0 ? (
+              <TableRow>
+                <TableCell colSpan={5} align="center">
+                  <Typography variant="body2" color="text.secondary">
+                    No hay impresoras de red, USB, serial o CUPS activas
+                  </Typography>
+                </TableCell>
+              </TableRow>
+            ) : (
+              monitoredPrinters.map((printer) => {
+                const status = statusOf(printer.id);
+                const label = status
+                  ? statusLabels[status.status] || { label: status.status, color: 'default' as const }
+                  : { label: 'Sin consultar', color: 'default' as const };
+                return (
+                  <TableRow key={printer.id} hover>
+                    <TableCell>{printer.name}</TableCell>
+                    <TableCell>
+                      <Tooltip title={status?.message || ''}>
+                        <Chip size="small" color={label.color} label={label.label} />
+                      </Tooltip>
+                    </TableCell>
+                    <TableCell>{formatDate(status?.changed_at)}</TableCell>
+                    <TableCell>{formatDate(status?.checked_at)}</TableCell>
+                    <TableCell align="right">
+                      <Button
+                        size="small"
+                        variant="outlined"
+                        disabled={checking === printer.id}
+                        onClick={() => handleCheck(printer.id)}
+                      >
+                        Verificar
+                      </Button>
+                    </TableCell>
+                  </TableRow>
+                );
+              })
+            )}
+          </TableBody>
+        </Table>
+      </TableContainer>
+    </Box>
+  );
+};
+
+export default PrinterStatusSettings;
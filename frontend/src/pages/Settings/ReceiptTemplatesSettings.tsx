@@ -0,0 +1,439 @@
+import React, { useState, useEffect } from 'react';
+import {
+  Box,
+  Typography,
+  Grid,
+  TextField,
+  Button,
+  Alert,
+  Chip,
+  Switch,
+  FormControlLabel,
+  Table,
+  TableHead,
+  TableBody,
+  TableRow,
+  TableCell,
+  TableContainer,
+  IconButton,
+  Tooltip,
+  MenuItem,
+  Select,
+  FormControl,
+  InputLabel,
+  Dialog,
+  DialogTitle,
+  DialogContent,
+  DialogActions,
+  ToggleButton,
+  ToggleButtonGroup,
+} from '@mui/material';
+import {
+  Add as AddIcon,
+  Edit as EditIcon,
+  Delete as DeleteIcon,
+  Print as PrintIcon,
+  Restore as RestoreIcon,
+  Visibility as PreviewIcon,
+} from '@mui/icons-material';
+import { toast } from 'react-toastify';
+import {
+  wailsReceiptTemplateService,
+  ReceiptTemplate,
+  ReceiptTemplateDocumentType,
+} from '../../services/wailsReceiptTemplateService';
+
+const emptyTemplate: ReceiptTemplate = {
+  name: '',
+  document_type: 'receipt',
+  printer_id: null,
+  content: '',
+  is_active: true,
+};
+
+const directivesHelp = [
+  ['@left / @center / @right', 'Alineación'],
+  ['@bold [on|off]', 'Negrita'],
+  ['@underline [on|off]', 'Subrayado'],
+  ['@size 2x2', 'Tamaño de letra (ancho x alto, 1 a 8)'],
+  ['@normal', 'Tamaño normal, sin negrita ni subrayado'],
+  ['@feed [n]', 'Líneas en blanco'],
+  ['@line [car]', 'Separador del ancho del papel'],
+  ['@logo', 'Logo del restaurante'],
+  ['@qr {{.Invoice.QRURL}}', 'Código QR'],
+  ['@barcode {{.Number}}', 'Código de barras CODE128'],
+  ['@cut', 'Cortar papel'],
+  ['@drawer', 'Abrir cajón monedero'],
+];
+
+const fieldsHelp = [
+  ['.Business', 'Name, LegalName, NIT, Address, Phone, Email, Website, Header, Footer, HasLogo'],
+  ['.Number .Date .Employee .Table .OrderType .Notes', 'Datos del documento'],
+  ['.Customer / .Delivery', 'Cliente y datos de entrega (vacíos si no aplican)'],
+  ['range .Items', 'Quantity, Name, Description, UnitPrice, Subtotal, Modifiers, Notes'],
+  ['.Totals', 'Subtotal, Discount, Tax, ServiceCharge, Total, Tip, TotalPaid'],
+  ['range .Payments', 'Method, Amount'],
+  ['.Invoice', 'Title, Label, Number, Resolution, CUFE, KeyLabel, QRURL, Legend (factura)'],
+  ['.Report', 'Campos del cierre de caja y .DifferenceLabel'],
+  ['Funciones', 'money, date, datetime, time, upper, lower, row "izq" "der", wrap, repeat'],
+];
+
+interface ReceiptTemplatesSettingsProps {
+  printers: any[];
+}
+
+const ReceiptTemplatesSettings: React.FC<ReceiptTemplatesSettingsProps> = ({ printers }) => {
+  const [templates, setTemplates] = useState<ReceiptTemplate[]>([]);
+  const [documentTypes, setDocumentTypes] = useState<ReceiptTemplateDocumentType[]>([]);
+  const [editing, setEditing] = useState<ReceiptTemplate | null>(null);
+  const [paperWidth, setPaperWidth] = useState(80);
+  const [preview, setPreview] = useState('');
+  const [previewError, setPreviewError] = useState('');
+  const [samplePrinterId, setSamplePrinterId] = useState<number>(0);
+  const [showHelp, setShowHelp] = useState(false);
+
+  useEffect(() => {
+    loadTemplates();
+  }, []);
+
+  const loadTemplates = async () => {
+    try {
+      setDocumentTypes(await wailsReceiptTemplateService.getDocumentTypes());
+      setTemplates(await wailsReceiptTemplateService.getTemplates());
+    } catch (e: any) {
+      console.error('Error loading receipt templates:', e);
+    }
+  };
+
+  const typeLabel = (value: string) => documentTypes.find((t) => t.value === value)?.label || value;
+  const printerName = (id?: number | null) =>
+    id ? printers.find((p) => p.id === id)?.name || `#${id}` : 'Todas las impresoras';
+
+  const handleNew = async () => {
+    const template = { ...emptyTemplate };
+    try {
+      template.content = await wailsReceiptTemplateService.getDefaultTemplate(template.document_type);
+    } catch (e: any) {
+      console.error('Error loading default template:', e);
+    }
+    setPreview('');
+    setPreviewError('');
+    setEditing(template);
+  };
+
+  const handleEdit = (template: ReceiptTemplate) => {
+    setPreview('');
+    setPreviewError('');
+    setEditing({ ...template });
+  };
+
+  const handleLoadDefault = async () => {
+    if (!editing) return;
+    if (editing.content.trim() && !window.confirm('¿Reemplazar el contenido por la plantilla predeterminada?')) {
+      return;
+    }
+    try {
+      const content = await wailsReceiptTemplateService.getDefaultTemplate(editing.document_type);
+      setEditing({ ...editing, content });
+    } catch (e: any) {
+      toast.error(e?.message || 'Error cargando la plantilla predeterminada');
+    }
+  };
+
+  const handlePreview = async (width: number = paperWidth) => {
+    if (!editing) return;
+    try {
+      setPreview(await wailsReceiptTemplateService.preview(editing.content, editing.document_type, width));
+      setPreviewError('');
+    } catch (e: any) {
+      setPreview('');
+      setPreviewError(e?.message || String(e));
+    }
+  };
+
+  const handlePrintSample = async () => {
+    if (!editing || !samplePrinterId) return;
+    try {
+      await wailsReceiptTemplateService.printSample(editing.content, editing.document_type, samplePrinterId);
+      toast.success('Prueba enviada a la impresora');
+    } catch (e: any) {
+      toast.error(e?.message || 'Error imprimiendo la prueba');
+    }
+  };
+
+  const handleSave = async () => {
+    if (!editing) return;
+    try {
+      await wailsReceiptTemplateService.saveTemplate(editing);
+      toast.success('Plantilla guardada');
+      setEditing(null);
+      loadTemplates();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error guardando la plantilla');
+    }
+  };
+
+  const handleDelete = async (template: ReceiptTemplate) => {
+    if (!template.id || !window.confirm(`¿Eliminar la plantilla "${template.name}"?`)) {
+      return;
+    }
+    try {
+      await wailsReceiptTemplateService.deleteTemplate(template.id);
+      toast.success('Plantilla eliminada');
+      loadTemplates();
+    } catch (e: any) {
+      toast.error(e?.message || 'Error eliminando la plantilla');
+    }
+  };
+
+  return (
+    <Box>
+      <Box sx={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', mb: 1 }}>
+        <Typography variant="h6">Plantillas de Impresión</Typography>
+        <Button variant="contained" size="small" startIcon={<AddIcon />} onClick={handleNew}>
+          Nueva Plantilla
+        </Button>
+      </Box>
+      <Alert severity="info" sx={{ mb: 2 }}>
+        Personalice facturas, recibos, comandas, órdenes y cierres de caja. La plantilla de una impresora tiene
+        prioridad sobre la de todas las impresoras; sin plantilla activa se usa el formato predeterminado.
+      </Alert>
+
+      <TableContainer>
+        <Table size="small">
+          <TableHead>
+            <TableRow>
+              <TableCell>Nombre</TableCell>
+              <TableCell>Documento</TableCell>
+              <TableCell>Impresora</TableCell>
+              <TableCell>Estado</TableCell>
+              <TableCell align="right">Acciones</TableCell>
+            </TableRow>
+          </TableHead>
+          <TableBody>
+            {templates.length === 0 && (
+              <TableRow>
+                <TableCell colSpan={5} align="center">
+                  No hay plantillas, se usan los formatos predeterminados
+                </TableCell>
+              </TableRow>
+            )}
+            {templates.map((template) => (
+              <TableRow key={template.id}>
+                <TableCell>{template.name}</TableCell>
+                <TableCell>{typeLabel(template.document_type)}</TableCell>
+                <TableCell>{printerName(template.printer_id)}</TableCell>
+                <TableCell>
+                  <Chip
+                    size="small"
+                    label={template.is_active ? 'Activa' : 'Inactiva'}
+                    color={template.is_active ? 'success' : 'default'}
+                  />
+                </TableCell>
+                <TableCell align="right">
+                  <Tooltip title="Editar">
+                    <IconButton size="small" onClick={() => handleEdit(template)}>
+                      <EditIcon fontSize="small" />
+                    </IconButton>
+                  </Tooltip>
+                  <Tooltip title="Eliminar">
+                    <IconButton size="small" color="error" onClick={() => handleDelete(template)}>
+                      <DeleteIcon fontSize="small" />
+                    </IconButton>
+                  </Tooltip>
+                </TableCell>
+              </TableRow>
+            ))}
+          </TableBody>
+        </Table>
+      </TableContainer>
+
+      <Dialog open={!!editing} onClose={() => setEditing(null)} maxWidth="lg" fullWidth>
+        <DialogTitle>{editing?.id ? 'Editar Plantilla' : 'Nueva Plantilla'}</DialogTitle>
+        <DialogContent>
+          {editing && (
+            <Grid container spacing={2} sx={{ mt: 0 }}>
+              <Grid item xs={12} md={4}>
+                <TextField
+                  fullWidth
+                  size="small"
+                  label="Nombre"
+                  value={editing.name}
+                  onChange={(e) => setEditing({ ...editing, name: e.target.value })}
+                />
+              </Grid>
+              <Grid item xs={12} md={3}>
+                <FormControl fullWidth size="small">
+                  <InputLabel>Documento</InputLabel>
+                  <Select
+                    label="Documento"
+                    value={editing.document_type}
+                    onChange={(e) => setEditing({ ...editing, document_type: e.target.value as string })}
+                  >
+                    {documentTypes.map((type) => (
+                      <MenuItem key={type.value} value={type.value}>
+                        {type.label}
+                      </MenuItem>
+                    ))}
+                  </Select>
+                </FormControl>
+              </Grid>
+              <Grid item xs={12} md={3}>
+                <FormControl fullWidth size="small">
+                  <InputLabel>Impresora</InputLabel>
+                  <Select
+                    label="Impresora"
+                    value={editing.printer_id || 0}
+                    onChange={(e) => setEditing({ ...editing, printer_id: Number(e.target.value) || null })}
+                  >
+                    <MenuItem value={0}>Todas las impresoras</MenuItem>
+                    {printers.map((printer) => (
+                      <MenuItem key={printer.id} value={printer.id}>
+                        {printer.name}
+                      </MenuItem>
+                    ))}
+                  </Select>
+                </FormControl>
+              </Grid>
+              <Grid item xs={12} md={2}>
+                <FormControlLabel
+                  control={
+                    <Switch
+                      checked={editing.is_active}
+                      onChange={(e) => setEditing({ ...editing, is_active: e.target.checked })}
+                    />
+                  }
+                  label="Activa"
+                />
+              </Grid>
+
+              <Grid item xs={12} md={7}>
+                <Box sx={{ display: 'flex', gap: 1, mb: 1 }}>
+                  <Button size="small" startIcon={<RestoreIcon />} onClick={handleLoadDefault}>
+                    Cargar predeterminada
+                  </Button>
+                  <Button size="small" onClick={() => setShowHelp(!showHelp)}>
+                    {showHelp ? 'Ocultar ayuda' : 'Ver ayuda'}
+                  </Button>
+                </Box>
+                {showHelp && (
+                  <Alert severity="info" sx={{ mb: 1, '& .MuiAlert-message': { width: '100%' } }}>
+                    <Typography variant="body2" sx={{ mb: 1 }}>
+                      Plantillas Go (<code>{'{{.Campo}}'}</code>, <code>{'{{if}}'}</code>, <code>{'{{range}}'}</code>).
+                      Cada línea se imprime como texto; las líneas vacías se ignoran y las que empiezan con @ son
+                      directivas (use @@ para imprimir una @).
+                    </Typography>
+                    <Grid container spacing={1}>
+                      <Grid item xs={12} md={5}>
+                        {directivesHelp.map(([directive, description]) => (
+                          <Typography key={directive} variant="caption" component="div">
+                            <code>{directive}</code> — {description}
+                          </Typography>
+                        ))}
+                      </Grid>
+                      <Grid item xs={12} md={7}>
+                        {fieldsHelp.map(([field, description]) => (
+                          <Typography key={field} variant="caption" component="div">
+                            <code>{field}</code> — {description}
+                          </Typography>
+                        ))}
+                      </Grid>
+                    </Grid>
+                  </Alert>
+                )}
+                <TextField
+                  fullWidth
+                  multiline
+                  minRows={22}
+                  maxRows={22}
+                  value={editing.content}
+                  onChange={(e) => setEditing({ ...editing, content: e.target.value })}
+                  InputProps={{ sx: { fontFamily: 'monospace', fontSize: 13 } }}
+                />
+              </Grid>
+
+              <Grid item xs={12} md={5}>
+                <Box sx={{ display: 'flex', alignItems: 'center', gap: 1, mb: 1 }}>
+                  <ToggleButtonGroup
+                    size="small"
+                    exclusive
+                    value={paperWidth}
+                    onChange={(_, value) => {
+                      if (value) {
+                        setPaperWidth(value);
+                        handlePreview(value);
+                      }
+                    }}
+                  >
+                    <ToggleButton value={58}>58mm</ToggleButton>
+                    <ToggleButton value={80}>80mm</ToggleButton>
+                  </ToggleButtonGroup>
+                  <Button size="small" variant="outlined" startIcon={<PreviewIcon />} onClick={() => handlePreview()}>
+                    Vista previa
+                  </Button>
+                </Box>
+                {previewError && (
+                  <Alert severity="error" sx={{ mb: 1 }}>
+                    {previewError}
+                  </Alert>
+                )}
+                <Box
+                  component="pre"
+                  sx={{
+                    m: 0,
+                    p: 1,
+                    height: 420,
+                    overflow: 'auto',
+                    bgcolor: 'grey.100',
+                    border: 1,
+                    borderColor: 'divider',
+                    fontFamily: 'monospace',
+                    fontSize: 12,
+                    width: `${paperWidth === 58 ? 34 : 50}ch`,
+                    maxWidth: '100%',
+                  }}
+                >
+                  {preview || 'Presione "Vista previa" para ver el documento con datos de ejemplo'}
+                </Box>
+                <Box sx={{ display: 'flex', alignItems: 'center', gap: 1, mt: 1 }}>
+                  <FormControl size="small" sx={{ minWidth: 180 }}>
+                    <InputLabel>Imprimir prueba en</InputLabel>
+                    <Select
+                      label="Imprimir prueba en"
+                      value={samplePrinterId}
+                      onChange={(e) => setSamplePrinterId(Number(e.target.value))}
+                    >
+                      <MenuItem value={0}>Seleccione...</MenuItem>
+                      {printers.map((printer) => (
+                        <MenuItem key={printer.id} value={printer.id}>
+                          {printer.name}
+                        </MenuItem>
+                      ))}
+                    </Select>
+                  </FormControl>
+                  <Button
+                    size="small"
+                    variant="outlined"
+                    startIcon={<PrintIcon />}
+                    disabled={!samplePrinterId}
+                    onClick={handlePrintSample}
+                  >
+                    Imprimir
+                  </Button>
+                </Box>
+              </Grid>
+            </Grid>
+          )}
+        </DialogContent>
+        <DialogActions>
+          <Button onClick={() => setEditing(null)}>Cancelar</Button>
+          <Button variant="contained" onClick={handleSave}>
+            Guardar
+          </Button>
+        </DialogActions>
+      </Dialog>
+    </Box>
+  );
+};
+
+export default ReceiptTemplatesSettings;
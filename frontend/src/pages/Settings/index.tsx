@@ -67,12 +67,15 @@ import {
   Smartphone as SmartphoneIcon,
   SmartToy as SmartToyIcon,
   Settings as SettingsIcon,
+  Restore as RestoreIcon,
+  Backup as BackupIcon,
 } from '@mui/icons-material';
 import { toast } from 'react-toastify';
 import { wailsDianService } from '../../services/wailsDianService';
 import { wailsConfigService } from '../../services/wailsConfigService';
 import { wailsPrinterService, DetectedPrinter } from '../../services/wailsPrinterService';
-import { wailsUpdateService, UpdateInfo } from '../../services/wailsUpdateService';
+import { wailsUpdateService, UpdateInfo, UpdateState, UpdateSettings } from '../../services/wailsUpdateService';
+import { wailsBackupService, BackupInfo, BackupSettings, BackupCheck } from '../../services/wailsBackupService';
 import { wailsWebSocketService, WebSocketStatus, WebSocketClient } from '../../services/wailsWebSocketService';
 import { useEffect } from 'react';
 import {
@@ -93,6 +96,12 @@ import DIANDatabaseSettings from './DIANDatabaseSettings';
 import MCPSettings from './MCPSettings';
 import NetworkSettings from './NetworkSettings';
 import BoldSettings from './BoldSettings';
+import ContingencySettings from './ContingencySettings';
+import MockDIANSettings from './MockDIANSettings';
+import MailSettings from './MailSettings';
+import PrintSpoolerSettings from './PrintSpoolerSettings';
+import PrinterStatusSettings from './PrinterStatusSettings';
+import ReceiptTemplatesSettings from './ReceiptTemplatesSettings';
 import GeneralSettings, {
   ModuleConfig,
   loadModuleConfig,
@@ -133,6 +142,15 @@ const TabPanel: React.FC<TabPanelProps> = ({ children, value, index, ...other })
   );
 };
 
+// Status of printers detected in the system (CUPS reports it through IPP)
+const detectedPrinterStatusLabels: Record<string, string> = {
+  online: 'Lista',
+  printing: 'Imprimiendo',
+  paper_out: 'Sin papel',
+  offline: 'Desconectada',
+  error: 'Error',
+};
+
 const Settings: React.FC = () => {
   const [selectedTab, setSelectedTab] = useState(0);
   const [moduleConfig, setModuleConfig] = useState<ModuleConfig[]>(loadModuleConfig);
@@ -174,6 +192,7 @@ const Settings: React.FC = () => {
   const [dianSettings, setDianSettings] = useState({
     enabled: true,
     testMode: true,
+    useMockServer: false, // Send every DIAN call to the local simulator
     apiUrl: '', // Must be configured in Settings
     merchantRegistration: '',
     softwareId: '',
@@ -206,6 +225,30 @@ const Settings: React.FC = () => {
     ndEndNumber: 99999999,
     ndResolution: '',
     ndConsecutiveNumber: 0,
+    // POS Equivalent Document Resolution
+    usePOSDocument: false,
+    posPrefix: 'EPOS',
+    posStartNumber: 1,
+    posEndNumber: 99999999,
+    posResolution: '',
+    posTechnicalKey: '',
+    posDateFrom: '2019-01-19',
+    posDateTo: '2030-01-19',
+    posConsecutiveNumber: 0,
+    posPlateNumber: '',
+    posLocation: '',
+    posCashType: 'Caja principal',
+    // Support Document (Documento Soporte) Resolution
+    supportDocPrefix: 'DS',
+    supportDocStartNumber: 1,
+    supportDocEndNumber: 99999999,
+    supportDocResolution: '',
+    supportDocDateFrom: '2019-01-19',
+    supportDocDateTo: '2030-01-19',
+    supportDocConsecutiveNumber: 0,
+    supportDocAdjustmentPrefix: 'NAS',
+    supportDocAdjustmentStartNumber: 1,
+    supportDocAdjustmentEndNumber: 99999999,
     certificate: '',
     certificatePassword: '',
     certificateFileName: '',
@@ -231,6 +274,8 @@ const Settings: React.FC = () => {
     resolution: false,
     creditNote: false,
     debitNote: false,
+    posDocument: false,
+    supportDocument: false,
     production: false,
   });
 
@@ -296,6 +341,25 @@ const Settings: React.FC = () => {
   const [checkingUpdate, setCheckingUpdate] = useState(false);
   const [downloadingUpdate, setDownloadingUpdate] = useState(false);
   const [updateProgress, setUpdateProgress] = useState<string>('');
+  const [updateState, setUpdateState] = useState<UpdateState | null>(null);
+  const [updateSettings, setUpdateSettings] = useState<UpdateSettings>({
+    channel: 'stable',
+    defer_until_closing: false,
+  });
+
+  // Backup Settings
+  const [backupSettings, setBackupSettings] = useState<BackupSettings>({
+    enabled: true,
+    interval_hours: 24,
+    retention_count: 14,
+    retention_days: 30,
+    directory: '',
+  });
+  const [backups, setBackups] = useState<BackupInfo[]>([]);
+  const [backupInProgress, setBackupInProgress] = useState(false);
+  const [backupCheck, setBackupCheck] = useState<BackupCheck | null>(null);
+  const [restoringBackup, setRestoringBackup] = useState(false);
+  const [keyExportDirectory, setKeyExportDirectory] = useState('');
 
   // WebSocket Settings
   const [wsStatus, setWsStatus] = useState<WebSocketStatus>({ running: false });
@@ -407,7 +471,8 @@ const Settings: React.FC = () => {
       if (config) {
         setDianSettings({
           enabled: config.is_enabled || false,
-          testMode: config.environment === 'test',
+          testMode: config.environment !== 'production',
+          useMockServer: config.environment === 'mock',
           apiUrl: config.api_url || '',
           merchantRegistration: config.merchant_registration || '',
           softwareId: config.software_id || '',
@@ -452,6 +517,42 @@ const Settings: React.FC = () => {
           ndEndNumber: config.debit_note_resolution_to || 99999999,
           ndResolution: config.debit_note_resolution_number || '',
           ndConsecutiveNumber: config.last_debit_note_number || 0,
+          // POS Equivalent Document Resolution
+          usePOSDocument: config.use_pos_equivalent_document || false,
+          posPrefix: config.pos_resolution_prefix || 'EPOS',
+          posStartNumber: config.pos_resolution_from || 1,
+          posEndNumber: config.pos_resolution_to || 99999999,
+          posResolution: config.pos_resolution_number || '',
+          posTechnicalKey: config.pos_technical_key || '',
+          posDateFrom: (config.pos_resolution_date_from &&
+                       config.pos_resolution_date_from !== '0001-01-01T00:00:00Z')
+            ? config.pos_resolution_date_from.split('T')[0]
+            : '2019-01-19',
+          posDateTo: (config.pos_resolution_date_to &&
+                     config.pos_resolution_date_to !== '0001-01-01T00:00:00Z')
+            ? config.pos_resolution_date_to.split('T')[0]
+            : '2030-01-19',
+          posConsecutiveNumber: config.last_pos_number || 0,
+          posPlateNumber: config.pos_plate_number || '',
+          posLocation: config.pos_location || '',
+          posCashType: config.pos_cash_type || 'Caja principal',
+          // Support Document Resolution
+          supportDocPrefix: config.support_doc_resolution_prefix || 'DS',
+          supportDocStartNumber: config.support_doc_resolution_from || 1,
+          supportDocEndNumber: config.support_doc_resolution_to || 99999999,
+          supportDocResolution: config.support_doc_resolution_number || '',
+          supportDocDateFrom: (config.support_doc_resolution_date_from &&
+                              config.support_doc_resolution_date_from !== '0001-01-01T00:00:00Z')
+            ? config.support_doc_resolution_date_from.split('T')[0]
+            : '2019-01-19',
+          supportDocDateTo: (config.support_doc_resolution_date_to &&
+                            config.support_doc_resolution_date_to !== '0001-01-01T00:00:00Z')
+            ? config.support_doc_resolution_date_to.split('T')[0]
+            : '2030-01-19',
+          supportDocConsecutiveNumber: config.last_support_doc_number || 0,
+          supportDocAdjustmentPrefix: config.support_doc_adjustment_prefix || 'NAS',
+          supportDocAdjustmentStartNumber: config.support_doc_adjustment_from || 1,
+          supportDocAdjustmentEndNumber: config.support_doc_adjustment_to || 99999999,
           certificate: config.certificate || '',
           certificatePassword: '', // Don't load password for security
           certificateFileName: config.certificate ? 'Certificado existente' : '',
@@ -477,6 +578,8 @@ const Settings: React.FC = () => {
           resolution: config.step4_completed || false,
           creditNote: config.step5_completed || false,
           debitNote: config.step6_completed || false,
+          posDocument: config.step8_completed || false,
+          supportDocument: config.step9_completed || false,
           production: config.step7_completed || false,
         });
       }
@@ -645,7 +748,7 @@ const Settings: React.FC = () => {
       const updated = {
         ...current,
         is_enabled: dianSettings.enabled,
-        environment: dianSettings.testMode ? 'test' : 'production',
+        environment: dianSettings.useMockServer ? 'mock' : (dianSettings.testMode ? 'test' : 'production'),
         api_url: dianSettings.apiUrl,
         // Sync company data from Empresa tab
         identification_number: nit,
@@ -676,6 +779,7 @@ const Settings: React.FC = () => {
         test_set_id: dianSettings.testSetId,
         use_test_set_id: dianSettings.useTestSetId,
         last_invoice_number: dianSettings.consecutiveNumber,
+        use_pos_equivalent_document: dianSettings.usePOSDocument,
       };
 
       // Only save certificate if it has been changed
@@ -1065,7 +1169,7 @@ const Settings: React.FC = () => {
         resolution_date_from: new Date(defaultTestValues.dateFrom + 'T12:00:00'),
         resolution_date_to: new Date(defaultTestValues.dateTo + 'T12:00:00'),
         last_invoice_number: defaultTestValues.consecutiveNumber,
-        environment: 'test',
+        environment: currentDianConfig?.environment === 'mock' ? 'mock' : 'test',
         step4_completed: false,
         step7_completed: false,
       };
@@ -1297,6 +1401,102 @@ const Settings: React.FC = () => {
     }
   };
 
+  const handleConfigurePOSResolution = async () => {
+    try {
+      // Validate required fields
+      if (!dianSettings.posPrefix || !dianSettings.posResolution || !dianSettings.posTechnicalKey) {
+        toast.error('Por favor completa el prefijo, número de resolución y clave técnica del Documento Equivalente POS');
+        return;
+      }
+      if (!dianSettings.posPlateNumber) {
+        toast.error('Por favor ingresa la placa (serial) de la caja registradora');
+        return;
+      }
+
+      if (!dianSettings.apiToken) {
+        toast.error('Primero debes completar los pasos anteriores para obtener el token.');
+        return;
+      }
+
+      toast.info('Configurando resolución de Documento Equivalente POS con DIAN...');
+
+      // Ensure POS resolution and cash register data are saved
+      const currentDianConfig = await wailsDianService.getConfig();
+      const updatedConfig = {
+        ...currentDianConfig,
+        pos_resolution_prefix: dianSettings.posPrefix,
+        pos_resolution_from: dianSettings.posStartNumber || 1,
+        pos_resolution_to: dianSettings.posEndNumber || 99999999,
+        pos_resolution_number: dianSettings.posResolution,
+        pos_technical_key: dianSettings.posTechnicalKey,
+        // Use noon time to avoid timezone issues (UTC midnight can shift to previous day)
+        pos_resolution_date_from: new Date(dianSettings.posDateFrom + 'T12:00:00'),
+        pos_resolution_date_to: new Date(dianSettings.posDateTo + 'T12:00:00'),
+        last_pos_number: dianSettings.posConsecutiveNumber || 0,
+        pos_plate_number: dianSettings.posPlateNumber,
+        pos_location: dianSettings.posLocation,
+        pos_cash_type: dianSettings.posCashType,
+        use_pos_equivalent_document: dianSettings.usePOSDocument,
+      };
+      await wailsDianService.updateConfig(updatedConfig as any);
+
+      // Call backend to configure POS resolution with DIAN API
+      await wailsDianService.configurePOSResolution();
+
+      toast.success('Resolución de Documento Equivalente POS configurada exitosamente con DIAN');
+      setCompletedSteps(prev => ({ ...prev, posDocument: true }));
+
+      await loadDianConfig();
+    } catch (e:any) {
+      toast.error(e?.message || 'Error configurando resolución de Documento Equivalente POS');
+    }
+  };
+
+  const handleConfigureSupportDocumentResolution = async () => {
+    try {
+      // Validate required fields
+      if (!dianSettings.supportDocPrefix || !dianSettings.supportDocResolution) {
+        toast.error('Por favor completa el prefijo y número de resolución del Documento Soporte');
+        return;
+      }
+
+      if (!dianSettings.apiToken) {
+        toast.error('Primero debes completar los pasos anteriores para obtener el token.');
+        return;
+      }
+
+      toast.info('Configurando resolución de Documento Soporte con DIAN...');
+
+      // Ensure support document resolution data is saved
+      const currentDianConfig = await wailsDianService.getConfig();
+      const updatedConfig = {
+        ...currentDianConfig,
+        support_doc_resolution_prefix: dianSettings.supportDocPrefix,
+        support_doc_resolution_from: dianSettings.supportDocStartNumber || 1,
+        support_doc_resolution_to: dianSettings.supportDocEndNumber || 99999999,
+        support_doc_resolution_number: dianSettings.supportDocResolution,
+        // Use noon time to avoid timezone issues (UTC midnight can shift to previous day)
+        support_doc_resolution_date_from: new Date(dianSettings.supportDocDateFrom + 'T12:00:00'),
+        support_doc_resolution_date_to: new Date(dianSettings.supportDocDateTo + 'T12:00:00'),
+        last_support_doc_number: dianSettings.supportDocConsecutiveNumber || 0,
+        support_doc_adjustment_prefix: dianSettings.supportDocAdjustmentPrefix,
+        support_doc_adjustment_from: dianSettings.supportDocAdjustmentStartNumber || 1,
+        support_doc_adjustment_to: dianSettings.supportDocAdjustmentEndNumber || 99999999,
+      };
+      await wailsDianService.updateConfig(updatedConfig as any);
+
+      // Call backend to configure support document resolution with DIAN API
+      await wailsDianService.configureSupportDocumentResolution();
+
+      toast.success('Resolución de Documento Soporte configurada exitosamente con DIAN');
+      setCompletedSteps(prev => ({ ...prev, supportDocument: true }));
+
+      await loadDianConfig();
+    } catch (e:any) {
+      toast.error(e?.message || 'Error configurando resolución de Documento Soporte');
+    }
+  };
+
   const handleMigrateToProduction = async () => {
     try {
       // Validate all previous steps are completed
@@ -1362,6 +1562,8 @@ const Settings: React.FC = () => {
         resolution: false,
         creditNote: false,
         debitNote: false,
+        posDocument: false,
+        supportDocument: false,
         production: false,
       });
 
@@ -1383,6 +1585,161 @@ const Settings: React.FC = () => {
     }
   };
 
+  const loadUpdateStatus = async () => {
+    try {
+      const [state, settings] = await Promise.all([
+        wailsUpdateService.getUpdateStatus(),
+        wailsUpdateService.getUpdateSettings(),
+      ]);
+      setUpdateState(state);
+      if (settings) {
+        setUpdateSettings(settings);
+      }
+    } catch (error) {
+    }
+  };
+
+  const handleSaveUpdateSettings = async (settings: UpdateSettings) => {
+    const previous = updateSettings;
+    setUpdateSettings(settings);
+    try {
+      await wailsUpdateService.saveUpdateSettings(settings);
+      if (settings.channel !== previous.channel) {
+        setUpdateInfo(null);
+      }
+      toast.success('Preferencias de actualización guardadas');
+    } catch (error: any) {
+      setUpdateSettings(previous);
+      toast.error(error?.message || 'Error al guardar las preferencias de actualización');
+    }
+  };
+
+  const handleCancelScheduledUpdate = async () => {
+    try {
+      await wailsUpdateService.cancelScheduledUpdate();
+      toast.info('Actualización programada cancelada');
+      await loadUpdateStatus();
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al cancelar la actualización');
+    }
+  };
+
+  const handleRollbackUpdate = async () => {
+    if (!updateState?.previous_version) return;
+    if (!window.confirm(`¿Volver a la versión ${updateState.previous_version}? Deberás reiniciar la aplicación.`)) {
+      return;
+    }
+    // The previous version may not work with the database migrated by the new one
+    const restoreData = !!updateState.pre_update_backup && window.confirm(
+      `¿Restaurar también la copia de seguridad tomada antes de actualizar (${updateState.pre_update_backup})? ` +
+      'Hágalo si la versión anterior no funciona con los datos actuales. Se perderá todo lo registrado desde la actualización.'
+    );
+    try {
+      await wailsUpdateService.rollbackUpdate(restoreData);
+      toast.success('Versión anterior restaurada. Por favor, reinicia la aplicación.');
+      await loadUpdateStatus();
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al restaurar la versión anterior');
+    }
+  };
+
+  // Backup handlers
+  const backupReasonLabels: Record<string, string> = {
+    scheduled: 'Programada',
+    manual: 'Manual',
+    pre_update: 'Antes de actualizar',
+    pre_dian_production: 'Antes de producción DIAN',
+    pre_restore: 'Antes de restaurar',
+  };
+
+  const loadBackups = async () => {
+    try {
+      const [settings, list] = await Promise.all([
+        wailsBackupService.getBackupSettings(),
+        wailsBackupService.listBackups(),
+      ]);
+      if (settings) {
+        setBackupSettings(settings);
+      }
+      setBackups(list);
+    } catch (error) {
+    }
+  };
+
+  const handleSaveBackupSettings = async () => {
+    try {
+      await wailsBackupService.saveBackupSettings(backupSettings);
+      toast.success('Configuración de copias de seguridad guardada');
+      await loadBackups();
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al guardar la configuración de copias');
+    }
+  };
+
+  const handleBackupNow = async () => {
+    setBackupInProgress(true);
+    try {
+      const backup = await wailsBackupService.backupNow();
+      if (backup) {
+        toast.success(`Copia de seguridad creada: ${backup.file_name}`);
+      }
+      await loadBackups();
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al crear la copia de seguridad');
+    } finally {
+      setBackupInProgress(false);
+    }
+  };
+
+  const handleSelectBackupToRestore = async (fileName: string) => {
+    try {
+      const check = await wailsBackupService.checkBackup(fileName);
+      setBackupCheck(check);
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al leer la copia de seguridad');
+    }
+  };
+
+  const handleRestoreBackup = async () => {
+    if (!backupCheck) return;
+    setRestoringBackup(true);
+    try {
+      const result = await wailsBackupService.restoreBackup(backupCheck.file_name);
+      setBackupCheck(null);
+      if (result?.restored) {
+        toast.success('Copia restaurada. Por favor, reinicia la aplicación.');
+      }
+      await loadBackups();
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al restaurar la copia de seguridad');
+    } finally {
+      setRestoringBackup(false);
+    }
+  };
+
+  const handleDeleteBackup = async (fileName: string) => {
+    if (!window.confirm(`¿Eliminar la copia ${fileName}?`)) {
+      return;
+    }
+    try {
+      await wailsBackupService.deleteBackup(fileName);
+      await loadBackups();
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al eliminar la copia de seguridad');
+    }
+  };
+
+  const handleExportBackupKey = async () => {
+    try {
+      const path = await wailsBackupService.exportBackupKey(keyExportDirectory);
+      if (path) {
+        toast.success(`Clave exportada a ${path}. Guárdala en un lugar seguro`);
+      }
+    } catch (error: any) {
+      toast.error(error?.message || 'Error al exportar la clave');
+    }
+  };
+
   const handleCheckForUpdates = async () => {
     setCheckingUpdate(true);
     setUpdateProgress('Verificando actualizaciones...');
@@ -1415,8 +1772,16 @@ const Settings: React.FC = () => {
       setUpdateProgress('Descargando actualización...');
       await wailsUpdateService.performUpdate();
 
-      toast.success('Actualización instalada correctamente. Por favor, reinicia la aplicación.');
-      setUpdateProgress('Actualización completada. Reinicia la aplicación.');
+      // With "install after closing" the update is only verified and scheduled
+      const state = await wailsUpdateService.getUpdateStatus();
+      setUpdateState(state);
+      if (state?.status === 'scheduled') {
+        toast.success('Actualización descargada y verificada. Se instalará después del cierre.');
+        setUpdateProgress('');
+      } else {
+        toast.success('Actualización instalada correctamente. Por favor, reinicia la aplicación.');
+        setUpdateProgress('Actualización completada. Reinicia la aplicación.');
+      }
     } catch (error: any) {
       toast.error(error?.message || 'Error al instalar la actualización');
       setUpdateProgress('');
@@ -1428,6 +1793,8 @@ const Settings: React.FC = () => {
   // Load version on mount
   useEffect(() => {
     loadCurrentVersion();
+    loadUpdateStatus();
+    loadBackups();
   }, []);
 
   return (
@@ -1943,6 +2310,9 @@ const Settings: React.FC = () => {
                           label={dianSettings.testMode ? 'TEST' : 'PROD'}
                           color={dianSettings.testMode ? 'info' : 'error'}
                         />
+                        {dianSettings.useMockServer && (
+                          <Chip size="small" label="SIMULADOR" color="warning" />
+                        )}
                       </Box>
                     }
                   />
@@ -2007,11 +2377,37 @@ const Settings: React.FC = () => {
                           ...dianSettings,
                           apiUrl: e.target.value,
                         })}
-                        helperText="Ejemplo: http://localhost:3000 o http://api-dian.miempresa.com (sin /api/ubl2.1 al final)"
+                        disabled={dianSettings.useMockServer}
+                        helperText={dianSettings.useMockServer
+                          ? 'Con el simulador activo las llamadas no salen del equipo'
+                          : 'Ejemplo: http://localhost:3000 o http://api-dian.miempresa.com (sin /api/ubl2.1 al final)'}
                         placeholder="http://localhost:3000"
                         required
                       />
                     </Grid>
+                    <Grid item xs={12}>
+                      <FormControlLabel
+                        control={
+                          <Switch
+                            checked={dianSettings.useMockServer}
+                            onChange={(e) => setDianSettings({
+                              ...dianSettings,
+                              useMockServer: e.target.checked,
+                            })}
+                            color="warning"
+                          />
+                        }
+                        label="Usar simulador DIAN local (desarrollo y capacitación)"
+                      />
+                      <Typography variant="caption" color="text.secondary" display="block" sx={{ ml: 4 }}>
+                        Las facturas se validan en un simulador dentro del POS: no llegan a la DIAN ni consumen numeración del set de pruebas. Guarda la configuración para aplicar el cambio.
+                      </Typography>
+                    </Grid>
+                    {dianSettings.useMockServer && (
+                      <Grid item xs={12}>
+                        <MockDIANSettings />
+                      </Grid>
+                    )}
                   </Grid>
                 </AccordionDetails>
               </Accordion>
@@ -2352,145 +2748,467 @@ const Settings: React.FC = () => {
               </Accordion>
             </Grid>
 
-            {/* Datos Adicionales de Facturación */}
+            {/* Documento Equivalente Electrónico POS */}
             <Grid item xs={12}>
               <Accordion>
                 <AccordionSummary expandIcon={<ExpandMoreIcon />}>
-                  <Typography>Datos Adicionales de Facturación</Typography>
+                  <Typography>Documento Equivalente Electrónico POS</Typography>
                 </AccordionSummary>
                 <AccordionDetails>
                   <Grid container spacing={2}>
                     <Grid item xs={12}>
                       <Alert severity="info">
                         <Typography variant="body2">
-                          Estos campos se utilizan para el seguimiento interno de la facturación electrónica.
+                          Las ventas a CONSUMIDOR FINAL sin factura electrónica se emiten como Documento Equivalente POS.
+                          Requiere su propia resolución de numeración y los datos de la caja registradora.
                         </Typography>
                       </Alert>
                     </Grid>
-                    <Grid item xs={12} sm={6}>
-                      <TextField
-                        fullWidth
-                        label="Test Set ID"
-                        value={dianSettings.testSetId}
-                        onChange={(e) => setDianSettings({
-                          ...dianSettings,
-                          testSetId: e.target.value,
-                        })}
-                        helperText="ID del conjunto de pruebas DIAN (se asigna automáticamente)"
-                      />
-                    </Grid>
-                    <Grid item xs={12} sm={6}>
+                    <Grid item xs={12}>
                       <FormControlLabel
                         control={
                           <Switch
-                            checked={dianSettings.useTestSetId}
+                            checked={dianSettings.usePOSDocument}
                             onChange={(e) => setDianSettings({
                               ...dianSettings,
-                              useTestSetId: e.target.checked,
+                              usePOSDocument: e.target.checked,
                             })}
-                            color="primary"
                           />
                         }
-                        label="Usar Test Set ID en URL"
+                        label="Emitir Documento Equivalente POS para ventas a CONSUMIDOR FINAL"
                       />
-                      <Typography variant="caption" color="textSecondary" display="block">
-                        Activa esto para incluir el test_set_id en la URL de las pruebas.
-                        Algunos conjuntos de pruebas no lo requieren.
-                      </Typography>
-                    </Grid>
-                    <Grid item xs={12} sm={6}>
-                      <Box sx={{ display: 'flex', gap: 1, alignItems: 'flex-start' }}>
-                        <TextField
-                          fullWidth
-                          label="Último Consecutivo"
-                          type="number"
-                          value={dianSettings.consecutiveNumber}
-                          onChange={(e) => setDianSettings({
-                            ...dianSettings,
-                            consecutiveNumber: Number(e.target.value),
-                          })}
-                          helperText="Último número de factura generado"
-                        />
-                        <Button
-                          variant="outlined"
-                          color="primary"
-                          onClick={handleSyncConsecutive}
-                          startIcon={<SyncIcon />}
-                          sx={{ mt: 1, minWidth: 'auto', whiteSpace: 'nowrap' }}
-                        >
-                          Sincronizar
-                        </Button>
-                      </Box>
-                    </Grid>
-                  </Grid>
-                </AccordionDetails>
-              </Accordion>
-            </Grid>
-
-            {/* Datos de Empresa (Referencia) */}
-            <Grid item xs={12}>
-              <Accordion>
-                <AccordionSummary expandIcon={<ExpandMoreIcon />}>
-                  <Typography>Datos de Empresa para DIAN</Typography>
-                </AccordionSummary>
-                <AccordionDetails>
-                  <Grid container spacing={2}>
-                    <Grid item xs={12}>
-                      <Alert severity="info">
-                        <Typography variant="body2" sx={{ fontWeight: 'bold', mb: 1 }}>
-                          Datos cargados desde configuración de Empresa
-                        </Typography>
-                        <Typography variant="caption" color="text.secondary">
-                          Los siguientes datos se toman de la pestaña "Empresa".
-                          Si necesitas modificarlos, ve a la pestaña Empresa y guarda los cambios.
-                          Se sincronizarán automáticamente con DIAN.
-                        </Typography>
-                      </Alert>
                     </Grid>
                     <Grid item xs={12} sm={6}>
                       <TextField
                         fullWidth
-                        label="NIT"
-                        value={businessSettings.nit}
-                        disabled
-                        helperText="Configurado en pestaña Empresa"
+                        label="Prefijo POS"
+                        value={dianSettings.posPrefix}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          posPrefix: e.target.value,
+                        })}
+                        helperText="Prefijo autorizado para Documento Equivalente POS"
                       />
                     </Grid>
                     <Grid item xs={12} sm={6}>
                       <TextField
                         fullWidth
-                        label="Razón Social"
-                        value={businessSettings.legalName}
-                        disabled
-                        helperText="Configurado en pestaña Empresa"
+                        label="Número de Resolución POS"
+                        value={dianSettings.posResolution}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          posResolution: e.target.value,
+                        })}
                       />
                     </Grid>
-                    <Grid item xs={12}>
+                    <Grid item xs={12} sm={6}>
                       <TextField
                         fullWidth
-                        label="Matrícula Mercantil"
-                        value={dianSettings.merchantRegistration}
+                        label="Número Inicial POS"
+                        type="number"
+                        value={dianSettings.posStartNumber}
                         onChange={(e) => setDianSettings({
                           ...dianSettings,
-                          merchantRegistration: e.target.value,
+                          posStartNumber: Number(e.target.value),
                         })}
-                        placeholder="0000000-00"
-                        helperText="Número de matrícula mercantil de la empresa (por defecto: 0000000-00)"
                       />
                     </Grid>
                     <Grid item xs={12} sm={6}>
                       <TextField
                         fullWidth
-                        label="Dirección"
-                        value={businessSettings.address}
-                        disabled
-                        helperText="Configurado en pestaña Empresa"
+                        label="Número Final POS"
+                        type="number"
+                        value={dianSettings.posEndNumber}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          posEndNumber: Number(e.target.value),
+                        })}
                       />
                     </Grid>
                     <Grid item xs={12} sm={6}>
                       <TextField
                         fullWidth
-                        label="Teléfono"
+                        label="Vigencia Desde"
+                        type="date"
+                        InputLabelProps={{ shrink: true }}
+                        value={dianSettings.posDateFrom}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          posDateFrom: e.target.value,
+                        })}
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Vigencia Hasta"
+                        type="date"
+                        InputLabelProps={{ shrink: true }}
+                        value={dianSettings.posDateTo}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          posDateTo: e.target.value,
+                        })}
+                      />
+                    </Grid>
+                    <Grid item xs={12}>
+                      <TextField
+                        fullWidth
+                        label="Clave Técnica POS"
+                        value={dianSettings.posTechnicalKey}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          posTechnicalKey: e.target.value,
+                        })}
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Último Consecutivo POS"
+                        type="number"
+                        value={dianSettings.posConsecutiveNumber}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          posConsecutiveNumber: Number(e.target.value),
+                        })}
+                        helperText="Último número de Documento POS generado"
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Placa de la Caja"
+                        value={dianSettings.posPlateNumber}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          posPlateNumber: e.target.value,
+                        })}
+                        helperText="Serial o placa de la caja registradora"
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Ubicación de la Caja"
+                        value={dianSettings.posLocation}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          posLocation: e.target.value,
+                        })}
+                        helperText="Ej: Piso 1 - Entrada"
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Tipo de Caja"
+                        value={dianSettings.posCashType}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          posCashType: e.target.value,
+                        })}
+                        helperText="Ej: Caja principal"
+                      />
+                    </Grid>
+                  </Grid>
+                </AccordionDetails>
+              </Accordion>
+            </Grid>
+
+            {/* Documento Soporte Electrónico */}
+            <Grid item xs={12}>
+              <Accordion>
+                <AccordionSummary expandIcon={<ExpandMoreIcon />}>
+                  <Typography>Documento Soporte (Compras a No Obligados a Facturar)</Typography>
+                </AccordionSummary>
+                <AccordionDetails>
+                  <Grid container spacing={2}>
+                    <Grid item xs={12}>
+                      <Alert severity="info">
+                        <Typography variant="body2">
+                          Las compras de inventario a proveedores que no emiten factura generan un Documento Soporte electrónico.
+                        </Typography>
+                      </Alert>
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Prefijo Documento Soporte"
+                        value={dianSettings.supportDocPrefix}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          supportDocPrefix: e.target.value,
+                        })}
+                        helperText="Prefijo autorizado para Documento Soporte"
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Número de Resolución"
+                        value={dianSettings.supportDocResolution}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          supportDocResolution: e.target.value,
+                        })}
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Número Inicial"
+                        type="number"
+                        value={dianSettings.supportDocStartNumber}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          supportDocStartNumber: Number(e.target.value),
+                        })}
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Número Final"
+                        type="number"
+                        value={dianSettings.supportDocEndNumber}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          supportDocEndNumber: Number(e.target.value),
+                        })}
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Vigencia Desde"
+                        type="date"
+                        InputLabelProps={{ shrink: true }}
+                        value={dianSettings.supportDocDateFrom}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          supportDocDateFrom: e.target.value,
+                        })}
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Vigencia Hasta"
+                        type="date"
+                        InputLabelProps={{ shrink: true }}
+                        value={dianSettings.supportDocDateTo}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          supportDocDateTo: e.target.value,
+                        })}
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Último Consecutivo Usado"
+                        type="number"
+                        value={dianSettings.supportDocConsecutiveNumber}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          supportDocConsecutiveNumber: Number(e.target.value),
+                        })}
+                        helperText="El siguiente documento soporte usará este número + 1"
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Prefijo Nota de Ajuste"
+                        value={dianSettings.supportDocAdjustmentPrefix}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          supportDocAdjustmentPrefix: e.target.value,
+                        })}
+                        helperText="Prefijo para notas de ajuste al documento soporte"
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Número Inicial Nota de Ajuste"
+                        type="number"
+                        value={dianSettings.supportDocAdjustmentStartNumber}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          supportDocAdjustmentStartNumber: Number(e.target.value),
+                        })}
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Número Final Nota de Ajuste"
+                        type="number"
+                        value={dianSettings.supportDocAdjustmentEndNumber}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          supportDocAdjustmentEndNumber: Number(e.target.value),
+                        })}
+                      />
+                    </Grid>
+                  </Grid>
+                </AccordionDetails>
+              </Accordion>
+            </Grid>
+
+            {/* Facturación de Contingencia */}
+            <Grid item xs={12}>
+              <Accordion>
+                <AccordionSummary expandIcon={<ExpandMoreIcon />}>
+                  <Typography>Facturación de Contingencia (Fallas del Servicio DIAN)</Typography>
+                </AccordionSummary>
+                <AccordionDetails>
+                  <ContingencySettings />
+                </AccordionDetails>
+              </Accordion>
+            </Grid>
+
+            {/* Datos Adicionales de Facturación */}
+            <Grid item xs={12}>
+              <Accordion>
+                <AccordionSummary expandIcon={<ExpandMoreIcon />}>
+                  <Typography>Datos Adicionales de Facturación</Typography>
+                </AccordionSummary>
+                <AccordionDetails>
+                  <Grid container spacing={2}>
+                    <Grid item xs={12}>
+                      <Alert severity="info">
+                        <Typography variant="body2">
+                          Estos campos se utilizan para el seguimiento interno de la facturación electrónica.
+                        </Typography>
+                      </Alert>
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Test Set ID"
+                        value={dianSettings.testSetId}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          testSetId: e.target.value,
+                        })}
+                        helperText="ID del conjunto de pruebas DIAN (se asigna automáticamente)"
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <FormControlLabel
+                        control={
+                          <Switch
+                            checked={dianSettings.useTestSetId}
+                            onChange={(e) => setDianSettings({
+                              ...dianSettings,
+                              useTestSetId: e.target.checked,
+                            })}
+                            color="primary"
+                          />
+                        }
+                        label="Usar Test Set ID en URL"
+                      />
+                      <Typography variant="caption" color="textSecondary" display="block">
+                        Activa esto para incluir el test_set_id en la URL de las pruebas.
+                        Algunos conjuntos de pruebas no lo requieren.
+                      </Typography>
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <Box sx={{ display: 'flex', gap: 1, alignItems: 'flex-start' }}>
+                        <TextField
+                          fullWidth
+                          label="Último Consecutivo"
+                          type="number"
+                          value={dianSettings.consecutiveNumber}
+                          onChange={(e) => setDianSettings({
+                            ...dianSettings,
+                            consecutiveNumber: Number(e.target.value),
+                          })}
+                          helperText="Último número de factura generado"
+                        />
+                        <Button
+                          variant="outlined"
+                          color="primary"
+                          onClick={handleSyncConsecutive}
+                          startIcon={<SyncIcon />}
+                          sx={{ mt: 1, minWidth: 'auto', whiteSpace: 'nowrap' }}
+                        >
+                          Sincronizar
+                        </Button>
+                      </Box>
+                    </Grid>
+                  </Grid>
+                </AccordionDetails>
+              </Accordion>
+            </Grid>
+
+            {/* Datos de Empresa (Referencia) */}
+            <Grid item xs={12}>
+              <Accordion>
+                <AccordionSummary expandIcon={<ExpandMoreIcon />}>
+                  <Typography>Datos de Empresa para DIAN</Typography>
+                </AccordionSummary>
+                <AccordionDetails>
+                  <Grid container spacing={2}>
+                    <Grid item xs={12}>
+                      <Alert severity="info">
+                        <Typography variant="body2" sx={{ fontWeight: 'bold', mb: 1 }}>
+                          Datos cargados desde configuración de Empresa
+                        </Typography>
+                        <Typography variant="caption" color="text.secondary">
+                          Los siguientes datos se toman de la pestaña "Empresa".
+                          Si necesitas modificarlos, ve a la pestaña Empresa y guarda los cambios.
+                          Se sincronizarán automáticamente con DIAN.
+                        </Typography>
+                      </Alert>
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="NIT"
+                        value={businessSettings.nit}
+                        disabled
+                        helperText="Configurado en pestaña Empresa"
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Razón Social"
+                        value={businessSettings.legalName}
+                        disabled
+                        helperText="Configurado en pestaña Empresa"
+                      />
+                    </Grid>
+                    <Grid item xs={12}>
+                      <TextField
+                        fullWidth
+                        label="Matrícula Mercantil"
+                        value={dianSettings.merchantRegistration}
+                        onChange={(e) => setDianSettings({
+                          ...dianSettings,
+                          merchantRegistration: e.target.value,
+                        })}
+                        placeholder="0000000-00"
+                        helperText="Número de matrícula mercantil de la empresa (por defecto: 0000000-00)"
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Dirección"
+                        value={businessSettings.address}
+                        disabled
+                        helperText="Configurado en pestaña Empresa"
+                      />
+                    </Grid>
+                    <Grid item xs={12} sm={6}>
+                      <TextField
+                        fullWidth
+                        label="Teléfono"
                         value={businessSettings.phone}
                         disabled
                         helperText="Configurado en pestaña Empresa"
@@ -2514,7 +3232,7 @@ const Settings: React.FC = () => {
             <Grid item xs={12}>
               <Accordion>
                 <AccordionSummary expandIcon={<ExpandMoreIcon />}>
-                  <Typography>Configuración de Email (Envío de Facturas)</Typography>
+                  <Typography>Configuración de Email (Facturas, Reportes y Alertas)</Typography>
                 </AccordionSummary>
                 <AccordionDetails>
                   <Grid container spacing={2}>
@@ -2584,6 +3302,9 @@ const Settings: React.FC = () => {
                         </Select>
                       </FormControl>
                     </Grid>
+                    <Grid item xs={12}>
+                      <MailSettings />
+                    </Grid>
                   </Grid>
                 </AccordionDetails>
               </Accordion>
@@ -2800,6 +3521,44 @@ const Settings: React.FC = () => {
                       )}
                     </Grid>
 
+                    {/* Step 8: Configure POS Equivalent Document Resolution */}
+                    <Grid item xs={12}>
+                      <Button
+                        variant={completedSteps.posDocument ? "contained" : "outlined"}
+                        color={completedSteps.posDocument ? "success" : "primary"}
+                        fullWidth
+                        onClick={handleConfigurePOSResolution}
+                        disabled={!completedSteps.resolution || completedSteps.posDocument}
+                        startIcon={completedSteps.posDocument ? <span>✓</span> : null}
+                      >
+                        {completedSteps.posDocument ? "✓ Paso 8 Completado: Resolución POS Configurada" : "Paso 8 (Opcional): Configurar Documento Equivalente POS"}
+                      </Button>
+                      {!completedSteps.resolution && (
+                        <Typography variant="caption" color="text.secondary" sx={{ display: 'block', mt: 0.5, ml: 2 }}>
+                          Completa el Paso 4 primero
+                        </Typography>
+                      )}
+                    </Grid>
+
+                    {/* Step 9: Configure Support Document Resolution */}
+                    <Grid item xs={12}>
+                      <Button
+                        variant={completedSteps.supportDocument ? "contained" : "outlined"}
+                        color={completedSteps.supportDocument ? "success" : "primary"}
+                        fullWidth
+                        onClick={handleConfigureSupportDocumentResolution}
+                        disabled={!completedSteps.company || completedSteps.supportDocument}
+                        startIcon={completedSteps.supportDocument ? <span>✓</span> : null}
+                      >
+                        {completedSteps.supportDocument ? "✓ Paso 9 Completado: Resolución Documento Soporte Configurada" : "Paso 9 (Opcional): Configurar Documento Soporte"}
+                      </Button>
+                      {!completedSteps.company && (
+                        <Typography variant="caption" color="text.secondary" sx={{ display: 'block', mt: 0.5, ml: 2 }}>
+                          Completa el Paso 1 primero
+                        </Typography>
+                      )}
+                    </Grid>
+
                     {/* Optional: Configure Logo */}
                     <Grid item xs={12}>
                       <Button
@@ -2975,11 +3734,22 @@ const Settings: React.FC = () => {
                                     {printer.connection_type.toUpperCase()} - {printer.address}
                                   </Typography>
                                   {printer.model && ` - ${printer.model}`}
-                                  {` - ${printer.status}`}
+                                  {` - ${printer.status_message || printer.status}`}
                                 </>
                               }
                             />
                             <ListItemSecondaryAction>
+                              {printer.status !== 'unknown' && (
+                                <Chip
+                                  size="small"
+                                  sx={{ mr: 1 }}
+                                  label={detectedPrinterStatusLabels[printer.status] || printer.status}
+                                  color={
+                                    printer.status === 'online' || printer.status === 'printing' ? 'success' :
+                                    printer.status === 'paper_out' ? 'warning' : 'error'
+                                  }
+                                />
+                              )}
                               <Button
                                 size="small"
                                 variant="contained"
@@ -3006,6 +3776,7 @@ const Settings: React.FC = () => {
                         const connTypeDisplay =
                           printer.connection_type === 'ethernet' ? 'Red/Ethernet' :
                           printer.connection_type === 'windows_share' ? 'Windows' :
+                          printer.connection_type === 'cups' ? 'CUPS' :
                           printer.connection_type === 'usb' ? 'USB' :
                           printer.connection_type === 'serial' ? 'Serial' :
                           printer.connection_type.toUpperCase();
@@ -3151,6 +3922,33 @@ const Settings: React.FC = () => {
               </Card>
             </Grid>
 
+            {/* Printer Status */}
+            <Grid item xs={12}>
+              <Card>
+                <CardContent>
+                  <PrinterStatusSettings printers={printerConfigs} />
+                </CardContent>
+              </Card>
+            </Grid>
+
+            {/* Print Spooler */}
+            <Grid item xs={12}>
+              <Card>
+                <CardContent>
+                  <PrintSpoolerSettings printers={printerConfigs} />
+                </CardContent>
+              </Card>
+            </Grid>
+
+            {/* Receipt Templates */}
+            <Grid item xs={12}>
+              <Card>
+                <CardContent>
+                  <ReceiptTemplatesSettings printers={printerConfigs} />
+                </CardContent>
+              </Card>
+            </Grid>
+
             {/* Waiter App Printer Configuration */}
             <Grid item xs={12}>
               <Card>
@@ -3492,6 +4290,38 @@ const Settings: React.FC = () => {
                       </Box>
                     </Grid>
 
+                    <Grid item xs={12} md={6}>
+                      <FormControl fullWidth size="small">
+                        <InputLabel>Canal de Actualizaciones</InputLabel>
+                        <Select
+                          value={updateSettings.channel}
+                          label="Canal de Actualizaciones"
+                          onChange={(e) => handleSaveUpdateSettings({
+                            ...updateSettings,
+                            channel: e.target.value as UpdateSettings['channel'],
+                          })}
+                        >
+                          <MenuItem value="stable">Estable</MenuItem>
+                          <MenuItem value="beta">Beta (versiones de prueba)</MenuItem>
+                        </Select>
+                      </FormControl>
+                    </Grid>
+
+                    <Grid item xs={12} md={6}>
+                      <FormControlLabel
+                        control={
+                          <Switch
+                            checked={updateSettings.defer_until_closing}
+                            onChange={(e) => handleSaveUpdateSettings({
+                              ...updateSettings,
+                              defer_until_closing: e.target.checked,
+                            })}
+                          />
+                        }
+                        label="Instalar después del cierre del restaurante"
+                      />
+                    </Grid>
+
                     {updateProgress && (
                       <Grid item xs={12}>
                         <Alert severity="info">
@@ -3500,6 +4330,45 @@ const Settings: React.FC = () => {
                       </Grid>
                     )}
 
+                    {updateState && updateState.status !== 'healthy' && (
+                      <Grid item xs={12}>
+                        <Alert
+                          severity={
+                            updateState.status === 'rolled_back' || updateState.status === 'failed'
+                              ? 'warning'
+                              : 'info'
+                          }
+                          action={
+                            updateState.status === 'scheduled' ? (
+                              <Button color="inherit" size="small" onClick={handleCancelScheduledUpdate}>
+                                Cancelar
+                              </Button>
+                            ) : undefined
+                          }
+                        >
+                          <Typography variant="body2">
+                            {updateState.status === 'scheduled' && updateState.install_after
+                              ? `La versión ${updateState.version} se instalará el ${new Date(updateState.install_after).toLocaleString('es-CO')}`
+                              : updateState.message}
+                          </Typography>
+                        </Alert>
+                      </Grid>
+                    )}
+
+                    {updateState?.previous_version && (updateState.status === 'healthy' || updateState.status === 'pending_verification') && (
+                      <Grid item xs={12}>
+                        <Button
+                          variant="outlined"
+                          color="warning"
+                          size="small"
+                          onClick={handleRollbackUpdate}
+                          startIcon={<RestoreIcon />}
+                        >
+                          Volver a la versión {updateState.previous_version}
+                        </Button>
+                      </Grid>
+                    )}
+
                     {updateInfo?.update_available && updateInfo.release_notes && (
                       <Grid item xs={12}>
                         <Divider sx={{ my: 2 }} />
@@ -3522,6 +4391,212 @@ const Settings: React.FC = () => {
                 </CardContent>
               </Card>
             </Grid>
+
+            {/* Backup Section */}
+            <Grid item xs={12}>
+              <Card>
+                <CardContent>
+                  <Box sx={{ display: 'flex', alignItems: 'center', justifyContent: 'space-between', mb: 2 }}>
+                    <Box sx={{ display: 'flex', alignItems: 'center' }}>
+                      <BackupIcon sx={{ mr: 1 }} />
+                      <Typography variant="h6">
+                        Copias de Seguridad
+                      </Typography>
+                    </Box>
+                    <Button
+                      variant="contained"
+                      onClick={handleBackupNow}
+                      disabled={backupInProgress || restoringBackup}
+                      startIcon={<BackupIcon />}
+                    >
+                      {backupInProgress ? 'Creando copia...' : 'Crear Copia Ahora'}
+                    </Button>
+                  </Box>
+                  <Typography variant="body2" color="text.secondary" sx={{ mb: 2 }}>
+                    Las copias incluyen todos los datos del sistema, se guardan comprimidas y cifradas con la clave de este equipo.
+                    También se crea una copia automáticamente antes de cada actualización y antes de migrar la DIAN a producción.
+                  </Typography>
+                  <Grid container spacing={2}>
+                    <Grid item xs={12} md={3}>
+                      <FormControlLabel
+                        control={
+                          <Switch
+                            checked={backupSettings.enabled}
+                            onChange={(e) => setBackupSettings({ ...backupSettings, enabled: e.target.checked })}
+                          />
+                        }
+                        label="Copias automáticas"
+                      />
+                    </Grid>
+                    <Grid item xs={12} md={3}>
+                      <TextField
+                        fullWidth
+                        size="small"
+                        type="number"
+                        label="Cada (horas)"
+                        value={backupSettings.interval_hours}
+                        onChange={(e) => setBackupSettings({ ...backupSettings, interval_hours: parseInt(e.target.value) || 0 })}
+                        disabled={!backupSettings.enabled}
+                      />
+                    </Grid>
+                    <Grid item xs={12} md={3}>
+                      <TextField
+                        fullWidth
+                        size="small"
+                        type="number"
+                        label="Conservar (copias)"
+                        helperText="0 = sin límite"
+                        value={backupSettings.retention_count}
+                        onChange={(e) => setBackupSettings({ ...backupSettings, retention_count: parseInt(e.target.value) || 0 })}
+                      />
+                    </Grid>
+                    <Grid item xs={12} md={3}>
+                      <TextField
+                        fullWidth
+                        size="small"
+                        type="number"
+                        label="Conservar (días)"
+                        helperText="0 = sin límite"
+                        value={backupSettings.retention_days}
+                        onChange={(e) => setBackupSettings({ ...backupSettings, retention_days: parseInt(e.target.value) || 0 })}
+                      />
+                    </Grid>
+                    <Grid item xs={12} md={9}>
+                      <TextField
+                        fullWidth
+                        size="small"
+                        label="Carpeta de copias"
+                        placeholder="Por defecto: carpeta de configuración de PosApp"
+                        helperText="Se recomienda una carpeta en otro disco o sincronizada con la nube"
+                        value={backupSettings.directory}
+                        onChange={(e) => setBackupSettings({ ...backupSettings, directory: e.target.value })}
+                      />
+                    </Grid>
+                    <Grid item xs={12} md={3}>
+                      <Button
+                        variant="outlined"
+                        fullWidth
+                        onClick={handleSaveBackupSettings}
+                        startIcon={<SaveIcon />}
+                      >
+                        Guardar
+                      </Button>
+                    </Grid>
+
+                    <Grid item xs={12} md={9}>
+                      <TextField
+                        fullWidth
+                        size="small"
+                        label="Exportar clave de cifrado a"
+                        placeholder="Ej: E:\ (memoria USB)"
+                        helperText="Las copias solo se pueden restaurar con la clave de este equipo (key.bin). Para restaurarlas en otro equipo, copia key.bin a %APPDATA%\PosApp antes de iniciar PosApp"
+                        value={keyExportDirectory}
+                        onChange={(e) => setKeyExportDirectory(e.target.value)}
+                      />
+                    </Grid>
+                    <Grid item xs={12} md={3}>
+                      <Button
+                        variant="outlined"
+                        fullWidth
+                        onClick={handleExportBackupKey}
+                        disabled={!keyExportDirectory.trim()}
+                      >
+                        Exportar Clave
+                      </Button>
+                    </Grid>
+
+                    <Grid item xs={12}>
+                      <Divider sx={{ mb: 1 }} />
+                      {backups.length === 0 ? (
+                        <Typography variant="body2" color="text.secondary">
+                          No hay copias de seguridad
+                        </Typography>
+                      ) : (
+                        <List dense sx={{ maxHeight: 300, overflow: 'auto' }}>
+                          {backups.map((backup) => (
+                            <ListItem key={backup.file_name}>
+                              <ListItemText
+                                primary={new Date(backup.created_at).toLocaleString('es-CO')}
+                                secondary={`${backup.file_name} • ${(backup.size / 1024 / 1024).toFixed(2)} MB`}
+                              />
+                              <ListItemSecondaryAction>
+                                <Chip
+                                  size="small"
+                                  label={backupReasonLabels[backup.reason] || backup.reason}
+                                  sx={{ mr: 1 }}
+                                />
+                                <IconButton
+                                  title="Restaurar"
+                                  onClick={() => handleSelectBackupToRestore(backup.file_name)}
+                                  disabled={backupInProgress || restoringBackup}
+                                >
+                                  <RestoreIcon />
+                                </IconButton>
+                                <IconButton
+                                  title="Eliminar"
+                                  onClick={() => handleDeleteBackup(backup.file_name)}
+                                  disabled={backupInProgress || restoringBackup}
+                                >
+                                  <DeleteIcon />
+                                </IconButton>
+                              </ListItemSecondaryAction>
+                            </ListItem>
+                          ))}
+                        </List>
+                      )}
+                    </Grid>
+                  </Grid>
+                </CardContent>
+              </Card>
+
+              <Dialog open={!!backupCheck} onClose={() => !restoringBackup && setBackupCheck(null)} maxWidth="sm" fullWidth>
+                <DialogTitle>Restaurar Copia de Seguridad</DialogTitle>
+                <DialogContent>
+                  {backupCheck && (
+                    <Box sx={{ display: 'flex', flexDirection: 'column', gap: 2, mt: 1 }}>
+                      <Typography variant="body2">
+                        Creada: {backupCheck.created_at} • Versión {backupCheck.app_version}
+                        <br />
+                        {backupCheck.tables} tablas • {backupCheck.rows} registros
+                      </Typography>
+                      {backupCheck.errors.length > 0 && (
+                        <Alert severity="error">
+                          {backupCheck.errors.map((error) => (
+                            <Typography key={error} variant="body2">{error}</Typography>
+                          ))}
+                        </Alert>
+                      )}
+                      {backupCheck.warnings.length > 0 && (
+                        <Alert severity="warning">
+                          {backupCheck.warnings.map((warning) => (
+                            <Typography key={warning} variant="body2">{warning}</Typography>
+                          ))}
+                        </Alert>
+                      )}
+                      {backupCheck.compatible && (
+                        <Alert severity="info">
+                          Todos los datos actuales serán reemplazados por los de la copia. Antes se creará
+                          una copia de los datos actuales. Al terminar, reinicia la aplicación.
+                        </Alert>
+                      )}
+                    </Box>
+                  )}
+                </DialogContent>
+                <DialogActions>
+                  <Button onClick={() => setBackupCheck(null)} disabled={restoringBackup}>
+                    Cancelar
+                  </Button>
+                  <Button
+                    variant="contained"
+                    color="warning"
+                    onClick={handleRestoreBackup}
+                    disabled={!backupCheck?.compatible || restoringBackup}
+                  >
+                    {restoringBackup ? 'Restaurando...' : 'Restaurar'}
+                  </Button>
+                </DialogActions>
+              </Dialog>
+            </Grid>
           </Grid>
                 </TabPanel>
               )}
@@ -3836,6 +4911,7 @@ const Settings: React.FC = () => {
                   <MenuItem value="ethernet">Red/Ethernet (TCP/IP)</MenuItem>
                   <MenuItem value="serial">Serial/COM</MenuItem>
                   <MenuItem value="windows_share">Windows Compartida</MenuItem>
+                  <MenuItem value="cups">CUPS/IPP (Linux)</MenuItem>
                 </Select>
               </FormControl>
             </Grid>
@@ -3863,6 +4939,7 @@ const Settings: React.FC = () => {
                   printerForm.connection_type === 'serial' ? 'Puerto serial (ej: COM1)' :
                   printerForm.connection_type === 'ethernet' ? 'Dirección IP (ej: 192.168.1.100)' :
                   printerForm.connection_type === 'windows_share' ? 'Ruta UNC o nombre (ej: \\\\COMPUTER\\Printer)' :
+                  printerForm.connection_type === 'cups' ? 'Nombre de la cola CUPS o URI IPP (ej: TM-T20, ipp://192.168.1.100/ipp/print)' :
                   'Dirección de la impresora'
                 }
               />
@@ -3886,6 +4963,30 @@ const Settings: React.FC = () => {
                 onChange={(e) => setPrinterForm({ ...printerForm, model: e.target.value })}
               />
             </Grid>
+            <Grid item xs={12}>
+              <FormControl fullWidth>
+                <InputLabel>Impresora de Respaldo</InputLabel>
+                <Select
+                  value={printerForm.backup_printer_id || ''}
+                  label="Impresora de Respaldo"
+                  onChange={(e) => setPrinterForm({
+                    ...printerForm,
+                    backup_printer_id: e.target.value ? Number(e.target.value) : null,
+                  })}
+                >
+                  <MenuItem value="">
+                    <em>Sin respaldo</em>
+                  </MenuItem>
+                  {printerConfigs
+                    .filter((printer) => printer.id !== selectedPrinter?.id)
+                    .map((printer) => (
+                      <MenuItem key={printer.id} value={printer.id}>
+                        {printer.name}
+                      </MenuItem>
+                    ))}
+                </Select>
+              </FormControl>
+            </Grid>
             <Grid item xs={12}>
               <FormControlLabel
                 control={
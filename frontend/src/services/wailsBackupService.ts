@@ -0,0 +1,99 @@
+// Frontend wrapper for Wails Backup service
+
+type AnyObject = Record<string, any>;
+
+function getBackupService(): AnyObject | null {
+  const w = (window as AnyObject);
+  if (!w.go || !w.go.services || !w.go.services.BackupService) {
+    return null;
+  }
+  return w.go.services.BackupService;
+}
+
+export interface BackupInfo {
+  file_name: string;
+  path: string;
+  size: number;
+  reason: string;
+  created_at: string;
+}
+
+export interface BackupSettings {
+  enabled: boolean;
+  interval_hours: number;
+  retention_count: number;
+  retention_days: number;
+  directory: string;
+}
+
+export interface BackupCheck {
+  file_name: string;
+  app_version: string;
+  reason: string;
+  created_at: string;
+  tables: number;
+  rows: number;
+  compatible: boolean;
+  errors: string[];
+  warnings: string[];
+  restored: boolean;
+  safety_copy?: string;
+}
+
+export const wailsBackupService = {
+  // Create a backup now
+  async backupNow(): Promise<BackupInfo | null> {
+    const svc = getBackupService();
+    if (!svc) return null;
+    return await svc.BackupNow();
+  },
+
+  // List backup files, newest first
+  async listBackups(): Promise<BackupInfo[]> {
+    const svc = getBackupService();
+    if (!svc) return [];
+    return (await svc.ListBackups()) || [];
+  },
+
+  // Check whether a backup can be restored on the current schema
+  async checkBackup(fileName: string): Promise<BackupCheck | null> {
+    const svc = getBackupService();
+    if (!svc) return null;
+    return await svc.CheckBackup(fileName);
+  },
+
+  // Replace the database data with a backup
+  async restoreBackup(fileName: string): Promise<BackupCheck | null> {
+    const svc = getBackupService();
+    if (!svc) return null;
+    return await svc.RestoreBackup(fileName);
+  },
+
+  // Delete a backup file
+  async deleteBackup(fileName: string): Promise<void> {
+    const svc = getBackupService();
+    if (!svc) return;
+    return await svc.DeleteBackup(fileName);
+  },
+
+  // Copy the encryption key needed to restore backups to a directory
+  async exportBackupKey(directory: string): Promise<string | null> {
+    const svc = getBackupService();
+    if (!svc) return null;
+    return await svc.ExportBackupKey(directory);
+  },
+
+  // Get scheduling and retention settings
+  async getBackupSettings(): Promise<BackupSettings | null> {
+    const svc = getBackupService();
+    if (!svc) return null;
+    return await svc.GetBackupSettings();
+  },
+
+  // Save scheduling and retention settings
+  async saveBackupSettings(settings: BackupSettings): Promise<void> {
+    const svc = getBackupService();
+    if (!svc) return;
+    return await svc.SaveBackupSettings(settings);
+  }
+};
@@ -65,6 +65,66 @@ export const wailsDianService = {
     await svc.ConfigureDebitNoteResolution();
   },
 
+  async configurePOSResolution(): Promise<void> {
+    const svc = getDian();
+    if (!svc) throw new Error('Service not ready');
+    await svc.ConfigurePOSResolution();
+  },
+
+  async configureSupportDocumentResolution(): Promise<void> {
+    const svc = getDian();
+    if (!svc) throw new Error('Service not ready');
+    await svc.ConfigureSupportDocumentResolution();
+  },
+
+  async configureContingencyResolution(): Promise<void> {
+    const svc = getDian();
+    if (!svc) throw new Error('Service not ready');
+    await svc.ConfigureContingencyResolution();
+  },
+
+  async activateContingencyMode(reason: string, employeeId: number = 0): Promise<void> {
+    const svc = getDian();
+    if (!svc) throw new Error('Service not ready');
+    await svc.ActivateContingencyMode(reason, employeeId);
+  },
+
+  async deactivateContingencyMode(): Promise<void> {
+    const svc = getDian();
+    if (!svc) throw new Error('Service not ready');
+    await svc.DeactivateContingencyMode();
+  },
+
+  async reportContingencyInvoices(): Promise<number> {
+    const svc = getDian();
+    if (!svc) throw new Error('Service not ready');
+    return await svc.ReportContingencyInvoices();
+  },
+
+  async getContingencyStatus(): Promise<any> {
+    const svc = getDian();
+    if (!svc) return null;
+    return await svc.GetContingencyStatus();
+  },
+
+  async getContingencyEvents(limit: number = 100): Promise<any[]> {
+    const svc = getDian();
+    if (!svc) return [];
+    return (await svc.GetContingencyEvents(limit)) || [];
+  },
+
+  async getMockDianStatus(): Promise<any> {
+    const svc = getDian();
+    if (!svc) return null;
+    return await svc.GetMockDIANStatus();
+  },
+
+  async resetMockDian(): Promise<void> {
+    const svc = getDian();
+    if (!svc) throw new Error('Service not ready');
+    await svc.ResetMockDIAN();
+  },
+
   async changeEnvironment(environment: 'test' | 'production'): Promise<void> {
     const svc = getDian();
     if (!svc) throw new Error('Service not ready');
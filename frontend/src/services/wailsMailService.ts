@@ -0,0 +1,136 @@
+// Frontend wrapper for Wails Mail service
+
+type AnyObject = Record<string, any>;
+
+function getMailService(): AnyObject {
+  const w = (window as AnyObject);
+  if (!w.go || !w.go.services || !w.go.services.MailService) {
+    throw new Error('Servicio de correo no disponible');
+  }
+  return w.go.services.MailService;
+}
+
+export type MailStatus = 'pending' | 'retrying' | 'sent' | 'failed' | 'cancelled';
+
+export interface MailSettings {
+  owner_emails: string; // Comma separated, empty = restaurant email
+  send_invoices: boolean;
+  send_daily_closing_report: boolean;
+  daily_report_time: string; // HH:MM
+  send_cash_register_reports: boolean;
+  send_low_stock_alerts: boolean;
+  send_invoice_range_alerts: boolean;
+  max_attempts: number;
+  use_local_smtp: boolean;
+}
+
+export interface MailAttachment {
+  id: number;
+  file_name: string;
+  content_type: string;
+  size: number;
+}
+
+export interface MailDeliveryAttempt {
+  id: number;
+  attempt: number;
+  server: string;
+  success: boolean;
+  error: string;
+  duration_ms: number;
+  created_at: string;
+}
+
+export interface MailMessage {
+  id: number;
+  kind: string;
+  to: string;
+  subject: string;
+  html_body?: string;
+  status: MailStatus;
+  attempts: number;
+  max_attempts: number;
+  next_attempt_at?: string;
+  last_error: string;
+  sent_at?: string;
+  reference_type: string;
+  reference_id: number;
+  attachments?: MailAttachment[];
+  delivery_log?: MailDeliveryAttempt[];
+  created_at: string;
+}
+
+export interface CapturedMail {
+  id: number;
+  from: string;
+  to: string[];
+  subject: string;
+  html_body: string;
+  attachments: { file_name: string; content_type: string; size: number }[];
+  size: number;
+  received_at: string;
+}
+
+export interface LocalSMTPInbox {
+  running: boolean;
+  address: string;
+  messages: CapturedMail[];
+}
+
+export const wailsMailService = {
+  async getSettings(): Promise<MailSettings> {
+    return await getMailService().GetMailSettings();
+  },
+
+  async saveSettings(settings: MailSettings): Promise<void> {
+    await getMailService().SaveMailSettings(settings);
+  },
+
+  // Send a test email right away (empty = owner emails)
+  async sendTestEmail(to: string): Promise<MailMessage> {
+    return await getMailService().SendTestEmail(to);
+  },
+
+  // Queue the invoice of a sale with its PDF and XML (empty email = customer's email)
+  async sendInvoiceEmail(saleId: number, email: string = ''): Promise<MailMessage> {
+    return await getMailService().SendInvoiceEmail(saleId, email);
+  },
+
+  // Queue the DIAN closing report to the owners; custom periods use the end date
+  async sendDIANClosingReportEmail(date: string, period: string = 'daily', endDate?: string): Promise<MailMessage> {
+    if (period === 'custom' && endDate) {
+      return await getMailService().SendDIANClosingReportCustomRangeEmail(date, endDate);
+    }
+    return await getMailService().SendDIANClosingReportEmail(date, period);
+  },
+
+  async sendCashRegisterReportEmail(reportId: number): Promise<MailMessage> {
+    return await getMailService().SendCashRegisterReportEmail(reportId);
+  },
+
+  async getMessages(status: string = '', limit: number = 50, offset: number = 0): Promise<MailMessage[]> {
+    return (await getMailService().GetMailMessages(status, limit, offset)) || [];
+  },
+
+  // Message with its delivery log
+  async getMessage(id: number): Promise<MailMessage> {
+    return await getMailService().GetMailMessage(id);
+  },
+
+  async retryMessage(id: number): Promise<void> {
+    await getMailService().RetryMailMessage(id);
+  },
+
+  async cancelMessage(id: number): Promise<void> {
+    await getMailService().CancelMailMessage(id);
+  },
+
+  // Messages received by the built-in test SMTP server
+  async getLocalInbox(): Promise<LocalSMTPInbox> {
+    return await getMailService().GetLocalSMTPInbox();
+  },
+
+  async clearLocalInbox(): Promise<void> {
+    await getMailService().ClearLocalSMTPInbox();
+  },
+};
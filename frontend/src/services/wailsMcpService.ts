@@ -15,6 +15,7 @@ export interface MCPConfig {
   port: number;
   api_key: string;
   allowed_ips: string;
+  allowed_origins: string;
   read_only_mode: boolean;
   disabled_tools: string;
 }
@@ -44,6 +45,7 @@ export const wailsMcpService = {
       port: config.port,
       api_key: config.api_key,
       allowed_ips: config.allowed_ips,
+      allowed_origins: config.allowed_origins || '',
       read_only_mode: config.read_only_mode,
       disabled_tools: config.disabled_tools || '',
     };
@@ -0,0 +1,68 @@
+// Frontend wrapper for Wails PDF service
+
+type AnyObject = Record<string, any>;
+
+function getPdfService(): AnyObject {
+  const w = (window as AnyObject);
+  if (!w.go || !w.go.services || !w.go.services.PDFService) {
+    throw new Error('Servicio de PDF no disponible');
+  }
+  return w.go.services.PDFService;
+}
+
+export interface PDFDocumentFile {
+  file_name: string;
+  path: string; // Archived copy on disk
+  data: string; // Base64 encoded PDF
+}
+
+export const wailsPdfService = {
+  // Render the electronic invoice / POS document of a sale
+  async generateInvoicePDF(saleId: number): Promise<PDFDocumentFile> {
+    return await getPdfService().GenerateInvoicePDF(saleId);
+  },
+
+  async generateCreditNotePDF(creditNoteId: number): Promise<PDFDocumentFile> {
+    return await getPdfService().GenerateCreditNotePDF(creditNoteId);
+  },
+
+  async generateDebitNotePDF(debitNoteId: number): Promise<PDFDocumentFile> {
+    return await getPdfService().GenerateDebitNotePDF(debitNoteId);
+  },
+
+  // Render the DIAN closing report; custom periods use the end date
+  async generateDIANClosingReportPDF(date: string, period: string = 'daily', endDate?: string): Promise<PDFDocumentFile> {
+    if (period === 'custom' && endDate) {
+      return await getPdfService().GenerateDIANClosingReportCustomRangePDF(date, endDate);
+    }
+    return await getPdfService().GenerateDIANClosingReportPDF(date, period);
+  },
+
+  async generateCashRegisterReportPDF(reportId: number): Promise<PDFDocumentFile> {
+    return await getPdfService().GenerateCashRegisterReportPDF(reportId);
+  },
+
+  // Render the closing report of a closed cash register
+  async generateCashRegisterClosingPDF(cashRegisterId: number): Promise<PDFDocumentFile> {
+    return await getPdfService().GenerateCashRegisterClosingPDF(cashRegisterId);
+  },
+
+  // Render the last cash register closing of an employee
+  async generateLastCashRegisterReportPDF(employeeId: number): Promise<PDFDocumentFile> {
+    return await getPdfService().GenerateLastCashRegisterReportPDF(employeeId);
+  },
+
+  async getArchiveDirectory(): Promise<string> {
+    return await getPdfService().GetPDFArchiveDirectory();
+  },
+
+  // Save a rendered PDF through the browser download flow
+  download(file: PDFDocumentFile): void {
+    const link = document.createElement('a');
+    link.href = `data:application/pdf;base64,${file.data}`;
+    link.download = file.file_name;
+    document.body.appendChild(link);
+    link.click();
+    document.body.removeChild(link);
+  },
+};
@@ -0,0 +1,89 @@
+// Frontend wrapper for Wails Print Spooler service
+
+type AnyObject = Record<string, any>;
+
+function getPrintSpoolerService(): AnyObject {
+  const w = (window as AnyObject);
+  if (!w.go || !w.go.services || !w.go.services.PrintSpoolerService) {
+    throw new Error('Cola de impresión no disponible');
+  }
+  return w.go.services.PrintSpoolerService;
+}
+
+export type PrintJobStatus = 'pending' | 'printing' | 'retrying' | 'printed' | 'failed' | 'cancelled' | 'unknown';
+
+export interface PrintSpoolerSettings {
+  enabled: boolean; // Queue documents instead of printing them synchronously
+  max_attempts: number;
+  failover_enabled: boolean; // Send failed jobs to the backup printer
+  retention_days: number; // Days finished jobs are kept for reprinting
+}
+
+export interface PrintJob {
+  id: number;
+  printer_id: number;
+  printed_on_id?: number;
+  job_type: string;
+  description: string;
+  reference_type: string;
+  reference_id: number;
+  size: number;
+  status: PrintJobStatus;
+  attempts: number;
+  max_attempts: number;
+  next_attempt_at?: string;
+  last_error: string;
+  printed_at?: string;
+  is_reprint: boolean;
+  reprint_of_id?: number;
+  created_at: string;
+}
+
+export interface PrinterQueueStatus {
+  printer_id: number;
+  printer_name: string;
+  is_active: boolean;
+  backup_printer_id?: number;
+  backup_printer_name: string;
+  pending: number;
+  retrying: number;
+  failed: number;
+  last_error: string;
+  last_printed_at?: string;
+}
+
+export const wailsPrintSpoolerService = {
+  async getSettings(): Promise<PrintSpoolerSettings> {
+    return await getPrintSpoolerService().GetPrintSpoolerSettings();
+  },
+
+  async saveSettings(settings: PrintSpoolerSettings): Promise<void> {
+    await getPrintSpoolerService().SavePrintSpoolerSettings(settings);
+  },
+
+  // printerId 0 = all printers
+  async getJobs(status: string = '', printerId: number = 0, limit: number = 50, offset: number = 0): Promise<PrintJob[]> {
+    return (await getPrintSpoolerService().GetPrintJobs(status, printerId, limit, offset)) || [];
+  },
+
+  async getQueueStatus(): Promise<PrinterQueueStatus[]> {
+    return (await getPrintSpoolerService().GetPrintQueueStatus()) || [];
+  },
+
+  async retryJob(id: number): Promise<void> {
+    await getPrintSpoolerService().RetryPrintJob(id);
+  },
+
+  async cancelJob(id: number): Promise<void> {
+    await getPrintSpoolerService().CancelPrintJob(id);
+  },
+
+  async reprintJob(id: number): Promise<PrintJob> {
+    return await getPrintSpoolerService().ReprintJob(id);
+  },
+
+  // Reprint the last n printed documents (printerId 0 = all, jobType '' = all)
+  async reprintLast(n: number, printerId: number = 0, jobType: string = ''): Promise<PrintJob[]> {
+    return (await getPrintSpoolerService().ReprintLast(n, printerId, jobType)) || [];
+  },
+};
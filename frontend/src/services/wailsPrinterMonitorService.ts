@@ -0,0 +1,51 @@
+// Frontend wrapper for Wails Printer Monitor service
+
+type AnyObject = Record<string, any>;
+
+function getPrinterMonitorService(): AnyObject {
+  const w = (window as AnyObject);
+  if (!w.go || !w.go.services || !w.go.services.PrinterMonitorService) {
+    throw new Error('Monitor de impresoras no disponible');
+  }
+  return w.go.services.PrinterMonitorService;
+}
+
+export type PrinterStatusValue = 'online' | 'paper_near_end' | 'paper_out' | 'cover_open' | 'offline' | 'error' | 'unknown';
+
+export interface PrinterMonitorSettings {
+  enabled: boolean;
+  interval_seconds: number; // Time between checks of each printer (5-60)
+  kitchen_fallback: boolean; // Send kitchen tickets to the default printer when the kitchen printer and its backup are down
+}
+
+export interface PrinterStatus {
+  printer_id: number;
+  printer_name: string;
+  status: PrinterStatusValue;
+  message: string;
+  offline: boolean;
+  cover_open: boolean;
+  paper_near_end: boolean;
+  paper_out: boolean;
+  error: boolean;
+  checked_at: string;
+  changed_at: string; // When the printer entered its current status
+}
+
+export const wailsPrinterMonitorService = {
+  async getSettings(): Promise<PrinterMonitorSettings> {
+    return await getPrinterMonitorService().GetPrinterMonitorSettings();
+  },
+
+  async saveSettings(settings: PrinterMonitorSettings): Promise<void> {
+    await getPrinterMonitorService().SavePrinterMonitorSettings(settings);
+  },
+
+  async getStatuses(): Promise<PrinterStatus[]> {
+    return (await getPrinterMonitorService().GetPrinterStatuses()) || [];
+  },
+
+  async checkPrinter(printerId: number): Promise<PrinterStatus> {
+    return await getPrinterMonitorService().CheckPrinterStatus(printerId);
+  },
+};
@@ -4,12 +4,15 @@ type AnyObject = Record<string, any>;
 
 export interface DetectedPrinter {
   name: string;
-  type: string;  // "usb", "network", "serial"
-  connection_type: string;  // "usb", "ethernet", "serial"
+  type: string;  // "usb", "network", "serial", "windows", "cups"
+  connection_type: string;  // "usb", "ethernet", "serial", "windows_share", "cups"
   address: string;
   port: number;
   is_default: boolean;
-  status: string;  // "online", "offline", "unknown"
+  status: string;  // "online", "printing", "paper_out", "offline", "error", "unknown"
+  status_message?: string;
+  state_reasons?: string[];  // IPP printer-state-reasons of CUPS queues
+  device_uri?: string;
   model: string;
 }
 
@@ -0,0 +1,97 @@
+import { Supplier, InventoryPurchase, SupportDocument, SupportDocumentAdjustmentNote } from '../types/models';
+
+// Helper to check if Wails bindings are ready
+function areBindingsReady(): boolean {
+  return typeof (window as any).go !== 'undefined';
+}
+
+// Get the PurchaseService from Wails bindings
+function getPurchaseService() {
+  if (!areBindingsReady()) {
+    throw new Error('Wails bindings not ready');
+  }
+  const service = (window as any).go?.services?.PurchaseService;
+  if (!service) {
+    throw new Error('PurchaseService not available');
+  }
+  return service;
+}
+
+class WailsPurchaseService {
+  // Suppliers
+  async getSuppliers(): Promise<Supplier[]> {
+    try {
+      const suppliers = await getPurchaseService().GetSuppliers();
+      return suppliers || [];
+    } catch (error) {
+      console.error('Error getting suppliers:', error);
+      return [];
+    }
+  }
+
+  async createSupplier(supplier: Supplier): Promise<Supplier> {
+    try {
+      return await getPurchaseService().CreateSupplier(supplier);
+    } catch (error: any) {
+      throw new Error(error?.message || error || 'Error al crear proveedor');
+    }
+  }
+
+  async updateSupplier(supplier: Supplier): Promise<Supplier> {
+    try {
+      return await getPurchaseService().UpdateSupplier(supplier);
+    } catch (error: any) {
+      throw new Error(error?.message || error || 'Error al actualizar proveedor');
+    }
+  }
+
+  async deleteSupplier(id: number): Promise<void> {
+    try {
+      await getPurchaseService().DeleteSupplier(id);
+    } catch (error: any) {
+      throw new Error(error?.message || error || 'Error al eliminar proveedor');
+    }
+  }
+
+  // Purchases
+  async getPurchases(startDate?: string, endDate?: string): Promise<InventoryPurchase[]> {
+    try {
+      const purchases = await getPurchaseService().GetPurchases(startDate || '', endDate || '');
+      return purchases || [];
+    } catch (error) {
+      console.error('Error getting purchases:', error);
+      return [];
+    }
+  }
+
+  async createPurchase(purchase: InventoryPurchase, employeeId: number): Promise<InventoryPurchase> {
+    try {
+      return await getPurchaseService().CreatePurchase(purchase, employeeId);
+    } catch (error: any) {
+      throw new Error(error?.message || error || 'Error al registrar compra');
+    }
+  }
+
+  // Support documents
+  async issueSupportDocument(purchaseId: number): Promise<SupportDocument> {
+    try {
+      return await getPurchaseService().IssueSupportDocument(purchaseId);
+    } catch (error: any) {
+      throw new Error(error?.message || error || 'Error al enviar documento soporte');
+    }
+  }
+
+  async createSupportDocumentAdjustment(
+    purchaseId: number,
+    reason: string,
+    discrepancyCode: number
+  ): Promise<SupportDocumentAdjustmentNote> {
+    try {
+      return await getPurchaseService().CreateSupportDocumentAdjustment(purchaseId, reason, discrepancyCode);
+    } catch (error: any) {
+      throw new Error(error?.message || error || 'Error al enviar nota de ajuste');
+    }
+  }
+}
+
+export const wailsPurchaseService = new WailsPurchaseService();
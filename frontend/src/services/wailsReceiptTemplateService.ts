@@ -0,0 +1,58 @@
+// Frontend wrapper for Wails Receipt Template service
+
+type AnyObject = Record<string, any>;
+
+function getReceiptTemplateService(): AnyObject {
+  const w = (window as AnyObject);
+  if (!w.go || !w.go.services || !w.go.services.ReceiptTemplateService) {
+    throw new Error('Plantillas de impresión no disponibles');
+  }
+  return w.go.services.ReceiptTemplateService;
+}
+
+export interface ReceiptTemplate {
+  id?: number;
+  name: string;
+  document_type: string; // invoice, receipt, kitchen, order, cash_register_report
+  printer_id?: number | null; // null = all printers
+  content: string;
+  is_active: boolean;
+  created_at?: string;
+  updated_at?: string;
+}
+
+export interface ReceiptTemplateDocumentType {
+  value: string;
+  label: string;
+}
+
+export const wailsReceiptTemplateService = {
+  async getDocumentTypes(): Promise<ReceiptTemplateDocumentType[]> {
+    return (await getReceiptTemplateService().GetReceiptTemplateDocumentTypes()) || [];
+  },
+
+  async getTemplates(): Promise<ReceiptTemplate[]> {
+    return (await getReceiptTemplateService().GetReceiptTemplates()) || [];
+  },
+
+  async getDefaultTemplate(documentType: string): Promise<string> {
+    return await getReceiptTemplateService().GetDefaultReceiptTemplate(documentType);
+  },
+
+  async saveTemplate(template: ReceiptTemplate): Promise<void> {
+    await getReceiptTemplateService().SaveReceiptTemplate(template);
+  },
+
+  async deleteTemplate(id: number): Promise<void> {
+    await getReceiptTemplateService().DeleteReceiptTemplate(id);
+  },
+
+  // Renders the template with sample data as plain text (paperWidth 58 or 80)
+  async preview(content: string, documentType: string, paperWidth: number): Promise<string> {
+    return await getReceiptTemplateService().PreviewReceiptTemplate(content, documentType, paperWidth);
+  },
+
+  async printSample(content: string, documentType: string, printerId: number): Promise<void> {
+    await getReceiptTemplateService().PrintReceiptTemplateSample(content, documentType, printerId);
+  },
+};
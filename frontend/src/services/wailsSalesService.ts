@@ -46,6 +46,7 @@ export interface TaxBreakdownDetail {
 }
 
 export interface NoteDetail {
+  id: number;
   number: string;
   prefix: string;
   reason: string;
@@ -222,13 +223,16 @@ function mapSale(w: models.Sale): Sale {
       uuid: w.electronic_invoice.uuid || '',
       cufe: w.electronic_invoice.cufe || '',
       qr_code: w.electronic_invoice.qr_code || '',
-      status: w.electronic_invoice.status as 'pending' | 'sent' | 'accepted' | 'rejected' | 'error',
+      status: w.electronic_invoice.status as 'pending' | 'contingency' | 'contingency_reporting' | 'sent' | 'accepted' | 'rejected' | 'error',
       dian_response: w.electronic_invoice.dian_response || '',
       request_data: (w.electronic_invoice as any).request_data || '',
       sent_at: w.electronic_invoice.sent_at ? new Date(w.electronic_invoice.sent_at as any).toISOString() : undefined,
       accepted_at: w.electronic_invoice.accepted_at ? new Date(w.electronic_invoice.accepted_at as any).toISOString() : undefined,
       retry_count: w.electronic_invoice.retry_count || 0,
       last_error: w.electronic_invoice.last_error || '',
+      is_contingency: (w.electronic_invoice as any).is_contingency || false,
+      contingency_event_id: (w.electronic_invoice as any).contingency_event_id,
+      reported_at: (w.electronic_invoice as any).reported_at,
     } : undefined,
     cash_register_id: w.cash_register_id as unknown as number,
     notes: w.notes || '',
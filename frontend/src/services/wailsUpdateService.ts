@@ -18,6 +18,33 @@ export interface UpdateInfo {
   release_notes: string;
   published_at: string;
   file_size: number;
+  checksum_url: string;
+  signature_url: string;
+  signed: boolean;
+  channel: string;
+  prerelease: boolean;
+}
+
+export type UpdateStatus = 'scheduled' | 'pending_verification' | 'healthy' | 'rolled_back' | 'failed';
+
+export interface UpdateState {
+  status: UpdateStatus;
+  version: string;
+  previous_version: string;
+  channel: string;
+  install_after?: string;
+  applied_at?: string;
+  start_attempts: number;
+  verified_at?: string;
+  rolled_back_at?: string;
+  pre_update_backup?: string;
+  restore_pending?: boolean;
+  message: string;
+}
+
+export interface UpdateSettings {
+  channel: 'stable' | 'beta';
+  defer_until_closing: boolean;
 }
 
 export const wailsUpdateService = {
@@ -61,5 +88,41 @@ export const wailsUpdateService = {
     const svc = getUpdateService();
     if (!svc) return;
     return await svc.PerformUpdate();
+  },
+
+  // Get the state of the last update (scheduled, installed, rolled back...)
+  async getUpdateStatus(): Promise<UpdateState | null> {
+    const svc = getUpdateService();
+    if (!svc) return null;
+    return await svc.GetUpdateStatus();
+  },
+
+  // Get update channel and install preferences
+  async getUpdateSettings(): Promise<UpdateSettings | null> {
+    const svc = getUpdateService();
+    if (!svc) return null;
+    return await svc.GetUpdateSettings();
+  },
+
+  // Save update channel and install preferences
+  async saveUpdateSettings(settings: UpdateSettings): Promise<void> {
+    const svc = getUpdateService();
+    if (!svc) return;
+    return await svc.SaveUpdateSettings(settings);
+  },
+
+  // Cancel an update scheduled for after closing time
+  async cancelScheduledUpdate(): Promise<void> {
+    const svc = getUpdateService();
+    if (!svc) return;
+    return await svc.CancelScheduledUpdate();
+  },
+
+  // Restore the version installed before the last update
+  // restoreData also restores the database backup taken before the update
+  async rollbackUpdate(restoreData: boolean): Promise<void> {
+    const svc = getUpdateService();
+    if (!svc) return;
+    return await svc.RollbackUpdate(restoreData);
   }
 };
@@ -274,6 +274,7 @@ export interface PaymentMethod extends BaseModel {
 // Electronic invoice model
 export interface ElectronicInvoice extends BaseModel {
   sale_id: number;
+  document_type?: 'invoice' | 'pos_equivalent';
   prefix: string;
   invoice_number: string;
   uuid?: string;
@@ -283,7 +284,7 @@ export interface ElectronicInvoice extends BaseModel {
   xml_document?: string;
   pdf_document?: string;
   request_data?: string;
-  status: 'pending' | 'sent' | 'validating' | 'accepted' | 'rejected' | 'error';
+  status: 'pending' | 'contingency' | 'contingency_reporting' | 'sent' | 'validating' | 'accepted' | 'rejected' | 'error';
   is_valid?: boolean;
   validation_message?: string;
   validation_checked_at?: string;
@@ -292,6 +293,24 @@ export interface ElectronicInvoice extends BaseModel {
   accepted_at?: string;
   retry_count?: number;
   last_error?: string;
+  is_contingency?: boolean; // Issued with the contingency range during a DIAN outage
+  contingency_event_id?: number;
+  reported_at?: string;
+}
+
+// Contingency event (DIAN outage window)
+export interface ContingencyEvent {
+  id: number;
+  started_at: string;
+  ended_at?: string;
+  reason: string;
+  activated_by: 'auto' | 'manual';
+  employee_id?: number;
+  invoices_issued: number;
+  invoices_reported: number;
+  report_deadline?: string;
+  report_completed_at?: string;
+  notes?: string;
 }
 
 // Cash register model
@@ -411,7 +430,7 @@ export interface RestaurantConfig extends BaseModel {
 
 // DIAN config model
 export interface DIANConfig extends BaseModel {
-  environment: 'test' | 'production';
+  environment: 'test' | 'production' | 'mock';
   is_enabled: boolean;
   api_url: string;
   identification_number: string;
@@ -449,6 +468,49 @@ export interface DIANConfig extends BaseModel {
   debit_note_resolution_date_from?: string;
   debit_note_resolution_date_to?: string;
 
+  // POS Equivalent Document Resolution
+  pos_resolution_number?: string;
+  pos_resolution_prefix?: string;
+  pos_resolution_from?: number;
+  pos_resolution_to?: number;
+  pos_resolution_date_from?: string;
+  pos_resolution_date_to?: string;
+  pos_technical_key?: string;
+
+  // POS Cash Register
+  use_pos_equivalent_document?: boolean;
+  pos_plate_number?: string;
+  pos_location?: string;
+  pos_cash_type?: string;
+  pos_software_owner_name?: string;
+  pos_software_company_name?: string;
+  pos_software_name?: string;
+
+  // Support Document (Documento Soporte) Resolution
+  support_doc_resolution_number?: string;
+  support_doc_resolution_prefix?: string;
+  support_doc_resolution_from?: number;
+  support_doc_resolution_to?: number;
+  support_doc_resolution_date_from?: string;
+  support_doc_resolution_date_to?: string;
+  support_doc_adjustment_prefix?: string;
+  support_doc_adjustment_from?: number;
+  support_doc_adjustment_to?: number;
+
+  // Contingency Invoice Resolution (tipo 03)
+  contingency_resolution_number?: string;
+  contingency_resolution_prefix?: string;
+  contingency_resolution_from?: number;
+  contingency_resolution_to?: number;
+  contingency_resolution_date_from?: string;
+  contingency_resolution_date_to?: string;
+  contingency_technical_key?: string;
+
+  // Contingency Mode
+  contingency_mode_active?: boolean;
+  contingency_auto_activate?: boolean;
+  contingency_report_hours?: number;
+
   // Parametric IDs
   type_document_id?: number;
   type_organization_id?: number;
@@ -461,10 +523,23 @@ export interface DIANConfig extends BaseModel {
   test_set_id?: string;
   use_test_set_id?: boolean;
 
+  // Local Mock DIAN API (environment 'mock')
+  mock_latency_ms?: number;
+  mock_async_validation?: boolean;
+  mock_validation_seconds?: number;
+  mock_reject_above?: number;
+  mock_reject_customers?: string;
+  mock_reject_rate?: number;
+  mock_service_unavailable?: boolean;
+
   // Counters
   last_invoice_number?: number;
   last_credit_note_number?: number;
   last_debit_note_number?: number;
+  last_pos_number?: number;
+  last_support_doc_number?: number;
+  last_support_doc_adjustment_number?: number;
+  last_contingency_number?: number;
 
   // Email Settings
   send_email?: boolean;
@@ -482,6 +557,9 @@ export interface DIANConfig extends BaseModel {
   step5_completed?: boolean;
   step6_completed?: boolean;
   step7_completed?: boolean;
+  step8_completed?: boolean;
+  step9_completed?: boolean;
+  step10_completed?: boolean;
 }
 
 // Printer config model
@@ -498,6 +576,7 @@ export interface PrinterConfig extends BaseModel {
   print_logo: boolean;
   auto_cut: boolean;
   cash_drawer: boolean;
+  backup_printer_id?: number | null; // Printer that takes the jobs of this one when it fails
 }
 
 // Inventory movement model
@@ -545,6 +624,100 @@ export interface IngredientMovement extends BaseModel {
   employee?: Employee;
 }
 
+// Supplier model
+export interface Supplier {
+  id?: number;
+  name: string;
+  identification_type: string;
+  identification_number: string;
+  dv?: string;
+  email?: string;
+  phone?: string;
+  address?: string;
+  municipality_id?: number;
+  type_document_identification_id?: number;
+  type_organization_id?: number; // 1=Jurídica, 2=Natural
+  type_liability_id?: number;
+  type_regime_id?: number;
+  merchant_registration?: string;
+  issues_invoices: boolean; // false = purchases require a support document
+  is_active: boolean;
+}
+
+// Inventory purchase item model
+export interface InventoryPurchaseItem {
+  id?: number;
+  ingredient_id?: number;
+  product_id?: number;
+  description: string;
+  quantity: number;
+  unit_measure_id?: number;
+  unit_price: number;
+  tax_percent: number;
+  tax_amount?: number;
+  subtotal?: number;
+}
+
+// Purchase withholding model (5=ReteIVA, 6=ReteRenta, 7=ReteICA)
+export interface PurchaseWithholding {
+  id?: number;
+  tax_id: number;
+  percent: number;
+  taxable_amount?: number;
+  amount?: number;
+}
+
+// Support document adjustment note model
+export interface SupportDocumentAdjustmentNote {
+  id: number;
+  number: string;
+  prefix: string;
+  cuds: string;
+  reason: string;
+  discrepancy_code: number;
+  amount: number;
+  status: string;
+  created_at: string;
+}
+
+// Electronic support document model
+export interface SupportDocument {
+  id: number;
+  purchase_id: number;
+  number: string;
+  prefix: string;
+  cuds: string;
+  qr_code?: string;
+  zip_key?: string;
+  status: 'sent' | 'validating' | 'accepted' | 'rejected' | 'error';
+  is_valid?: boolean;
+  validation_message?: string;
+  last_error?: string;
+  adjustment_notes?: SupportDocumentAdjustmentNote[];
+  created_at: string;
+}
+
+// Inventory purchase model
+export interface InventoryPurchase {
+  id?: number;
+  purchase_number?: string;
+  supplier_id: number;
+  supplier?: Supplier;
+  supplier_invoice_number?: string;
+  items: InventoryPurchaseItem[];
+  withholdings: PurchaseWithholding[];
+  subtotal?: number;
+  tax?: number;
+  withholding_total?: number;
+  total?: number;
+  payment_method_code?: number;
+  needs_support_document?: boolean;
+  support_document?: SupportDocument;
+  notes?: string;
+  purchased_at?: string;
+  created_at?: string;
+}
+
 // CreateOrderData interface
 export interface CreateOrderData {
   type: 'dine_in' | 'takeout' | 'delivery';
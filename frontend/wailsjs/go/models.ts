@@ -3047,6 +3047,7 @@ export namespace models {
 	    port: number;
 	    api_key: string;
 	    allowed_ips: string;
+	    allowed_origins: string;
 	    read_only_mode: boolean;
 	    disabled_tools: string;
 	    created_at: time.Time;
@@ -3063,6 +3064,7 @@ export namespace models {
 	        this.port = source["port"];
 	        this.api_key = source["api_key"];
 	        this.allowed_ips = source["allowed_ips"];
+	        this.allowed_origins = source["allowed_origins"];
 	        this.read_only_mode = source["read_only_mode"];
 	        this.disabled_tools = source["disabled_tools"];
 	        this.created_at = this.convertValues(source["created_at"], time.Time);
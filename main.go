@@ -23,36 +23,47 @@ var assets embed.FS
 
 // App struct
 type App struct {
-	ctx                     context.Context
-	LoggerService           *services.LoggerService
-	ConfigManagerService    *services.ConfigManagerService
-	ProductService          *services.ProductService
-	IngredientService       *services.IngredientService
-	CustomPageService       *services.CustomPageService
-	OrderService            *services.OrderService
-	OrderTypeService        *services.OrderTypeService
-	SalesService            *services.SalesService
-	DIANService             *services.DIANService
-	EmployeeService         *services.EmployeeService
-	ReportsService          *services.ReportsService
-	PrinterService          *services.PrinterService
-	ConfigService           *services.ConfigService
-	ParametricService       *services.ParametricService
-	DashboardService        *services.DashboardService
-	ComboService            *services.ComboService
-	UpdateService           *services.UpdateService
-	GoogleSheetsService     *services.GoogleSheetsService
-	ReportSchedulerService  *services.ReportSchedulerService
-	RappiConfigService      *services.RappiConfigService
-	RappiWebhookServer      *services.RappiWebhookServer
-	InvoiceLimitService     *services.InvoiceLimitService
-	ConfigAPIServer         *services.ConfigAPIServer
-	MCPService              *services.MCPService
-	BoldService             *services.BoldService
-	BoldWebhookService      *services.BoldWebhookService
-	WSServer                *websocket.Server
-	WSManagementService     *services.WebSocketManagementService
-	isFirstRun              bool
+	ctx                       context.Context
+	LoggerService             *services.LoggerService
+	ConfigManagerService      *services.ConfigManagerService
+	ProductService            *services.ProductService
+	IngredientService         *services.IngredientService
+	CustomPageService         *services.CustomPageService
+	OrderService              *services.OrderService
+	OrderTypeService          *services.OrderTypeService
+	ReservationService        *services.ReservationService
+	TimeClockService          *services.TimeClockService
+	SalesService              *services.SalesService
+	DIANService               *services.DIANService
+	EmployeeService           *services.EmployeeService
+	ReportsService            *services.ReportsService
+	PrinterService            *services.PrinterService
+	ConfigService             *services.ConfigService
+	ParametricService         *services.ParametricService
+	DashboardService          *services.DashboardService
+	ComboService              *services.ComboService
+	PurchaseService           *services.PurchaseService
+	UpdateService             *services.UpdateService
+	BackupService             *services.BackupService
+	PDFService                *services.PDFService
+	MailService               *services.MailService
+	PrintSpoolerService       *services.PrintSpoolerService
+	PrinterMonitorService     *services.PrinterMonitorService
+	ReceiptTemplateService    *services.ReceiptTemplateService
+	GoogleSheetsService       *services.GoogleSheetsService
+	ReportSchedulerService    *services.ReportSchedulerService
+	RappiConfigService        *services.RappiConfigService
+	RappiWebhookServer        *services.RappiWebhookServer
+	InvoiceLimitService       *services.InvoiceLimitService
+	ConfigAPIServer           *services.ConfigAPIServer
+	MCPService                *services.MCPService
+	BoldService               *services.BoldService
+	BoldWebhookService        *services.BoldWebhookService
+	BoldReconciliationService *services.BoldReconciliationService
+	PaymentGatewayService     *services.PaymentGatewayService
+	WSServer                  *websocket.Server
+	WSManagementService       *services.WebSocketManagementService
+	isFirstRun                bool
 }
 
 // NewApp creates a new App application struct
@@ -91,6 +102,29 @@ func (a *App) startup(ctx context.Context) {
 			a.BoldWebhookService.SetWebSocketServer(a.WSServer)
 			a.LoggerService.LogInfo("WebSocket server configured for Bold webhook notifications")
 		}
+		if a.BoldReconciliationService != nil {
+			a.BoldReconciliationService.SetWebSocketServer(a.WSServer)
+			a.BoldReconciliationService.StartDailyReconciliation()
+			a.LoggerService.LogInfo("Bold daily reconciliation job configured")
+		}
+		if a.PrintSpoolerService != nil {
+			a.PrintSpoolerService.SetWebSocketServer(a.WSServer)
+			a.LoggerService.LogInfo("WebSocket server configured for print failure notifications")
+		}
+		if a.PrinterMonitorService != nil {
+			a.PrinterMonitorService.SetWebSocketServer(a.WSServer)
+			a.LoggerService.LogInfo("WebSocket server configured for printer status notifications")
+		}
+		if a.ReportsService != nil {
+			a.ReportsService.SetWebSocketServer(a.WSServer)
+			a.ReportsService.StartKitchenMonitor()
+			a.LoggerService.LogInfo("Kitchen monitor configured for WebSocket live metrics")
+		}
+		if a.ReservationService != nil {
+			a.ReservationService.SetWebSocketServer(a.WSServer)
+			a.ReservationService.StartReservationMonitor()
+			a.LoggerService.LogInfo("Reservation monitor configured for WebSocket events")
+		}
 		go func() {
 			defer a.LoggerService.RecoverPanic()
 			if err := a.WSServer.Start(); err != nil {
@@ -98,12 +132,42 @@ func (a *App) startup(ctx context.Context) {
 			}
 		}()
 
+		if a.UpdateService != nil {
+			a.UpdateService.StartDeferredInstaller()
+		}
+
+		if a.BackupService != nil {
+			a.LoggerService.LogInfo("Starting backup scheduler")
+			a.BackupService.Start()
+		}
+
+		if a.MailService != nil {
+			a.LoggerService.LogInfo("Starting mail queue worker")
+			a.MailService.Start()
+		}
+
+		if a.PrintSpoolerService != nil {
+			a.LoggerService.LogInfo("Starting print spooler")
+			a.PrintSpoolerService.Start()
+		}
+
+		if a.PrinterMonitorService != nil {
+			a.LoggerService.LogInfo("Starting printer status monitor")
+			a.PrinterMonitorService.Start()
+		}
+
 		a.LoggerService.LogInfo("Starting DIAN validation worker")
 		go func() {
 			defer a.LoggerService.RecoverPanic()
 			services.StartValidationWorker()
 		}()
 
+		a.LoggerService.LogInfo("Starting DIAN contingency worker")
+		go func() {
+			defer a.LoggerService.RecoverPanic()
+			services.StartContingencyWorker()
+		}()
+
 		if a.ReportSchedulerService != nil {
 			a.LoggerService.LogInfo("Starting Google Sheets report scheduler")
 			go func() {
@@ -117,6 +181,32 @@ func (a *App) startup(ctx context.Context) {
 }
 
 func (a *App) domReady(ctx context.Context) {
+	if a.UpdateService != nil {
+		go func() {
+			defer a.LoggerService.RecoverPanic()
+			a.UpdateService.CompleteHealthCheck(a.checkUpdateHealth)
+		}()
+	}
+}
+
+// checkUpdateHealth verifies that a freshly updated version works: the UI loaded
+// (domReady) and, once configured, the database answers
+func (a *App) checkUpdateHealth() error {
+	if a.isFirstRun {
+		return nil
+	}
+	db := database.GetDB()
+	if db == nil {
+		return fmt.Errorf("la base de datos no está disponible")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := sqlDB.Ping(); err != nil {
+		return fmt.Errorf("la base de datos no responde: %w", err)
+	}
+	return nil
 }
 
 // beforeClose is called when the application is about to quit,
@@ -138,6 +228,41 @@ func (a *App) beforeClose(ctx context.Context) (prevent bool) {
 		a.ReportSchedulerService.Stop()
 	}
 
+	if a.BackupService != nil {
+		a.LoggerService.LogInfo("Stopping backup scheduler")
+		a.BackupService.Stop()
+	}
+
+	if a.MailService != nil {
+		a.LoggerService.LogInfo("Stopping mail queue worker")
+		a.MailService.Stop()
+	}
+
+	if a.PrintSpoolerService != nil {
+		a.LoggerService.LogInfo("Stopping print spooler")
+		a.PrintSpoolerService.Stop()
+	}
+
+	if a.PrinterMonitorService != nil {
+		a.LoggerService.LogInfo("Stopping printer status monitor")
+		a.PrinterMonitorService.Stop()
+	}
+
+	if a.BoldReconciliationService != nil {
+		a.LoggerService.LogInfo("Stopping Bold reconciliation job")
+		a.BoldReconciliationService.StopDailyReconciliation()
+	}
+
+	if a.ReportsService != nil {
+		a.LoggerService.LogInfo("Stopping kitchen monitor")
+		a.ReportsService.StopKitchenMonitor()
+	}
+
+	if a.ReservationService != nil {
+		a.LoggerService.LogInfo("Stopping reservation monitor")
+		a.ReservationService.StopReservationMonitor()
+	}
+
 	if a.InvoiceLimitService != nil {
 		a.LoggerService.LogInfo("Stopping invoice limit sync")
 		a.InvoiceLimitService.StopPeriodicSync()
@@ -181,19 +306,52 @@ func (a *App) InitializeServicesAfterSetup() error {
 	a.IngredientService = services.NewIngredientService()
 	a.CustomPageService = services.NewCustomPageService()
 	a.ComboService = services.NewComboService()
+	a.PurchaseService = services.NewPurchaseService()
 	a.OrderService = services.NewOrderService()
 	a.OrderTypeService = services.NewOrderTypeService()
+	if a.ReservationService != nil {
+		a.ReservationService.StopReservationMonitor()
+	}
+	a.ReservationService = services.NewReservationService()
+	a.TimeClockService = services.NewTimeClockService()
 	a.SalesService = services.NewSalesService()
 	a.DIANService = services.NewDIANService()
 	a.EmployeeService = services.NewEmployeeService()
+	if a.ReportsService != nil {
+		a.ReportsService.StopKitchenMonitor()
+	}
 	a.ReportsService = services.NewReportsService()
 	a.PrinterService = services.NewPrinterService()
 	a.ConfigService = services.NewConfigService()
 	a.ParametricService = services.NewParametricService()
 	a.DashboardService = services.NewDashboardService()
+	if a.BackupService != nil {
+		a.BackupService.Stop()
+	}
+	a.BackupService = services.NewBackupService()
+	a.BackupService.Start()
+	a.PDFService = services.NewPDFService()
+	if a.MailService != nil {
+		a.MailService.Stop()
+	}
+	a.MailService = services.NewMailService()
+	a.MailService.Start()
+	if a.PrintSpoolerService != nil {
+		a.PrintSpoolerService.Stop()
+	}
+	a.PrintSpoolerService = services.NewPrintSpoolerService()
+	a.PrintSpoolerService.Start()
+	if a.PrinterMonitorService != nil {
+		a.PrinterMonitorService.Stop()
+	}
+	a.PrinterMonitorService = services.NewPrinterMonitorService()
+	a.PrinterMonitorService.Start()
+	a.ReceiptTemplateService = services.NewReceiptTemplateService()
 	a.BoldService = services.NewBoldService(database.GetDB())
 
 	a.BoldWebhookService = services.NewBoldWebhookService(database.GetDB(), a.BoldService)
+	a.BoldReconciliationService = services.NewBoldReconciliationService(database.GetDB())
+	a.PaymentGatewayService = services.NewPaymentGatewayService()
 
 	if a.WSServer != nil {
 		a.BoldWebhookService.SetWebSocketServer(a.WSServer)
@@ -258,6 +416,9 @@ func (a *App) InitializeServicesAfterSetup() error {
 		a.IngredientService,
 		a.DashboardService,
 		a.ReportsService,
+		a.EmployeeService,
+		a.ComboService,
+		a.CustomPageService,
 	)
 	a.LoggerService.LogInfo("MCP Service initialized")
 	go func() {
@@ -313,17 +474,47 @@ func (a *App) ConnectDatabaseWithConfig(cfg *config.AppConfig) error {
 		a.LoggerService.LogInfo("WebSocket server configured for Bold webhook notifications")
 	}
 
+	if a.BoldReconciliationService != nil {
+		a.BoldReconciliationService.SetWebSocketServer(a.WSServer)
+		a.BoldReconciliationService.StartDailyReconciliation()
+	}
+
+	if a.PrintSpoolerService != nil {
+		a.PrintSpoolerService.SetWebSocketServer(a.WSServer)
+	}
+
+	if a.PrinterMonitorService != nil {
+		a.PrinterMonitorService.SetWebSocketServer(a.WSServer)
+	}
+
+	if a.ReportsService != nil {
+		a.ReportsService.SetWebSocketServer(a.WSServer)
+		a.ReportsService.StartKitchenMonitor()
+		a.LoggerService.LogInfo("Kitchen monitor configured for WebSocket live metrics")
+	}
+
+	if a.ReservationService != nil {
+		a.ReservationService.SetWebSocketServer(a.WSServer)
+		a.ReservationService.StartReservationMonitor()
+		a.LoggerService.LogInfo("Reservation monitor configured for WebSocket events")
+	}
+
 	go func() {
 		defer a.LoggerService.RecoverPanic()
 		a.WSServer.Start()
 	}()
 
 	go services.StartValidationWorker()
+	go services.StartContingencyWorker()
 
 	return nil
 }
 
 func main() {
+	// Count the start of a freshly updated version before anything else can fail
+	updateService := services.NewUpdateService()
+	updateHealthPending := updateService.StartHealthCheck()
+
 	loggerService := services.NewLoggerService()
 	if loggerService == nil {
 		fmt.Println("CRITICAL: Logger service failed to initialize")
@@ -348,7 +539,16 @@ func main() {
 	app.LoggerService = loggerService
 
 	app.ConfigManagerService = services.NewConfigManagerService()
-	app.UpdateService = services.NewUpdateService()
+	app.UpdateService = updateService
+	if updateHealthPending {
+		loggerService.LogInfo("First start after update, health check pending", services.CurrentVersion)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			app.UpdateService.FailHealthCheck(fmt.Sprintf("panic: %v", r))
+			panic(r)
+		}
+	}()
 
 	isFirstRun, err := app.ConfigManagerService.IsFirstRun()
 	if err != nil {
@@ -363,8 +563,11 @@ func main() {
 	app.IngredientService = services.NewIngredientService()
 	app.CustomPageService = services.NewCustomPageService()
 	app.ComboService = services.NewComboService()
+	app.PurchaseService = services.NewPurchaseService()
 	app.OrderService = services.NewOrderService()
 	app.OrderTypeService = services.NewOrderTypeService()
+	app.ReservationService = services.NewReservationService()
+	app.TimeClockService = services.NewTimeClockService()
 	app.SalesService = services.NewSalesService()
 	app.DIANService = services.NewDIANService()
 	app.EmployeeService = services.NewEmployeeService()
@@ -373,13 +576,21 @@ func main() {
 	app.ConfigService = services.NewConfigService()
 	app.ParametricService = services.NewParametricService()
 	app.DashboardService = services.NewDashboardService()
+	app.BackupService = services.NewBackupService()
+	app.PDFService = services.NewPDFService()
+	app.MailService = services.NewMailService()
+	app.PrintSpoolerService = services.NewPrintSpoolerService()
+	app.PrinterMonitorService = services.NewPrinterMonitorService()
+	app.ReceiptTemplateService = services.NewReceiptTemplateService()
 	app.BoldService = services.NewBoldService(nil)
+	app.BoldReconciliationService = services.NewBoldReconciliationService(nil)
+	app.PaymentGatewayService = services.NewPaymentGatewayService()
 	app.WSManagementService = services.NewWebSocketManagementService(nil)
 	app.GoogleSheetsService = services.NewGoogleSheetsService(nil)
 	app.ReportSchedulerService = services.NewReportSchedulerService(nil, app.GoogleSheetsService)
 	app.RappiConfigService = services.NewRappiConfigService()
 	app.InvoiceLimitService = services.NewInvoiceLimitService(nil)
-	app.MCPService = services.NewMCPService(nil, nil, nil, nil, nil, nil)
+	app.MCPService = services.NewMCPService(nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	if !isFirstRun {
 		loggerService.LogInfo("Loading configuration from config.json")
@@ -398,13 +609,21 @@ func main() {
 		}
 
 		if !isFirstRun {
+			// A rollback may have left the pre-update backup to restore with this version
+			if err := app.UpdateService.RestoreRollbackBackup(); err != nil {
+				loggerService.LogWarning("Could not restore the pre-update backup", err.Error())
+			}
+
 			loggerService.LogInfo("Reinitializing services with database connection")
 			app.ProductService = services.NewProductService()
 			app.IngredientService = services.NewIngredientService()
 			app.CustomPageService = services.NewCustomPageService()
 			app.ComboService = services.NewComboService()
+			app.PurchaseService = services.NewPurchaseService()
 			app.OrderService = services.NewOrderService()
 			app.OrderTypeService = services.NewOrderTypeService()
+			app.ReservationService = services.NewReservationService()
+			app.TimeClockService = services.NewTimeClockService()
 			app.SalesService = services.NewSalesService()
 			app.DIANService = services.NewDIANService()
 			app.EmployeeService = services.NewEmployeeService()
@@ -413,9 +632,17 @@ func main() {
 			app.ConfigService = services.NewConfigService()
 			app.ParametricService = services.NewParametricService()
 			app.DashboardService = services.NewDashboardService()
+			app.BackupService = services.NewBackupService()
+			app.PDFService = services.NewPDFService()
+			app.MailService = services.NewMailService()
+			app.PrintSpoolerService = services.NewPrintSpoolerService()
+			app.PrinterMonitorService = services.NewPrinterMonitorService()
+			app.ReceiptTemplateService = services.NewReceiptTemplateService()
 			app.BoldService = services.NewBoldService(database.GetDB())
 
 			app.BoldWebhookService = services.NewBoldWebhookService(database.GetDB(), app.BoldService)
+			app.BoldReconciliationService = services.NewBoldReconciliationService(database.GetDB())
+			app.PaymentGatewayService = services.NewPaymentGatewayService()
 
 			loggerService.LogInfo("Starting Bold webhook server")
 			go func() {
@@ -475,6 +702,9 @@ func main() {
 				app.IngredientService,
 				app.DashboardService,
 				app.ReportsService,
+				app.EmployeeService,
+				app.ComboService,
+				app.CustomPageService,
 			)
 			loggerService.LogInfo("MCP Service initialized")
 			go func() {
@@ -498,12 +728,21 @@ func main() {
 		app.LoggerService,
 		app.ConfigManagerService,
 		app.UpdateService,
+		app.BackupService,
+		app.PDFService,
+		app.MailService,
+		app.PrintSpoolerService,
+		app.PrinterMonitorService,
+		app.ReceiptTemplateService,
 		app.ProductService,
 		app.IngredientService,
 		app.ComboService,
+		app.PurchaseService,
 		app.CustomPageService,
 		app.OrderService,
 		app.OrderTypeService,
+		app.ReservationService,
+		app.TimeClockService,
 		app.SalesService,
 		app.DIANService,
 		app.EmployeeService,
@@ -519,6 +758,8 @@ func main() {
 		app.WSManagementService,
 		app.MCPService,
 		app.BoldService,
+		app.BoldReconciliationService,
+		app.PaymentGatewayService,
 	}
 
 	err = wails.Run(&options.App{
@@ -545,5 +786,6 @@ func main() {
 	if err != nil {
 		loggerService.LogError("Wails application error", err)
 		println("Error:", err.Error())
+		app.UpdateService.FailHealthCheck(err.Error())
 	}
 }
@@ -102,13 +102,13 @@ Write-Info "📱 VersionCode actual: $currentVersionCode"
 # Solicitar nueva versión si no se proporcionó
 if (-not $Version) {
     Write-Host ""
-    Write-Host "Ingresa la nueva versión (formato: X.Y.Z, ejemplo: 1.2.0):" -NoNewline
+    Write-Host "Ingresa la nueva versión (formato: X.Y.Z o X.Y.Z-beta.N, ejemplo: 1.2.0):" -NoNewline
     $Version = Read-Host " "
 }
 
 # Validar formato de versión (semver)
-if ($Version -notmatch '^\d+\.\d+\.\d+$') {
-    Write-Error "❌ Error: Formato de versión inválido. Debe ser X.Y.Z (ejemplo: 1.2.0)"
+if ($Version -notmatch '^\d+\.\d+\.\d+(-[0-9A-Za-z.]+)?$') {
+    Write-Error "❌ Error: Formato de versión inválido. Debe ser X.Y.Z o X.Y.Z-beta.N (ejemplo: 1.2.0)"
     exit 1
 }
 
@@ -110,14 +110,14 @@ info "📱 VersionCode actual: $CURRENT_VERSION_CODE"
 # Solicitar nueva versión
 if [ -z "$1" ]; then
     echo ""
-    read -p "Ingresa la nueva versión (formato: X.Y.Z, ejemplo: 1.2.0): " NEW_VERSION
+    read -p "Ingresa la nueva versión (formato: X.Y.Z o X.Y.Z-beta.N, ejemplo: 1.2.0): " NEW_VERSION
 else
     NEW_VERSION=$1
 fi
 
 # Validar formato de versión (semver)
-if ! [[ $NEW_VERSION =~ ^[0-9]+\.[0-9]+\.[0-9]+$ ]]; then
-    error "❌ Error: Formato de versión inválido. Debe ser X.Y.Z (ejemplo: 1.2.0)"
+if ! [[ $NEW_VERSION =~ ^[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.]+)?$ ]]; then
+    error "❌ Error: Formato de versión inválido. Debe ser X.Y.Z o X.Y.Z-beta.N (ejemplo: 1.2.0)"
     exit 1
 fi
 
@@ -3,7 +3,7 @@ import { googleSheetsService, type ReportData, type ProductDetail, type OrderTyp
 import { InvoiceLimitsSettings } from './components/InvoiceLimitsSettings'
 import { Login } from './components/Login'
 import { Orders } from './components/Orders'
-import { authApiService, type AuthUser } from './services/authApi'
+import { authApiService, AUTH_EXPIRED_EVENT, type AuthUser } from './services/authApi'
 import './App.css'
 
 type ViewPeriod = 'day' | 'week' | 'month' | 'year'
@@ -94,6 +94,18 @@ function App() {
     checkAuth()
   }, [])
 
+  // Go back to the login when the session expires and can't be renewed
+  useEffect(() => {
+    const onExpired = () => {
+      setCurrentUser(null)
+      setIsAuthenticated(false)
+      setReports([])
+      setCurrentReport(null)
+    }
+    window.addEventListener(AUTH_EXPIRED_EVENT, onExpired)
+    return () => window.removeEventListener(AUTH_EXPIRED_EVENT, onExpired)
+  }, [])
+
   useEffect(() => {
     // Only check config and load reports if authenticated
     if (!isAuthenticated) return
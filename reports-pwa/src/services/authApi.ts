@@ -9,6 +9,7 @@ export interface AuthUser {
   email: string
   phone: string
   token: string
+  expires_at?: string
 }
 
 export interface AuthResponse {
@@ -21,12 +22,25 @@ export interface AuthResponse {
 // Use the same Config API URL since auth is now part of the Config API server
 const AUTH_API_URL = import.meta.env.VITE_CONFIG_API_URL || ''
 
+// Renew the session this long before the token expires
+const REFRESH_BEFORE_EXPIRY_MS = 5 * 60 * 1000
+
+// Event dispatched on window when the session expired and could not be renewed
+export const AUTH_EXPIRED_EVENT = 'auth:expired'
+
 class AuthApiService {
   private baseUrl: string
+  private refreshTimer: ReturnType<typeof setTimeout> | null = null
 
   constructor() {
     // Remove trailing slash to avoid double slashes in URLs
     this.baseUrl = AUTH_API_URL.replace(/\/$/, '')
+
+    // Keep renewing a session restored from a previous visit
+    const user = this.getUser()
+    if (user && this.getToken()) {
+      this.scheduleRefresh(user)
+    }
   }
 
   private async request<T>(endpoint: string, options: RequestInit = {}): Promise<T> {
@@ -149,10 +163,96 @@ class AuthApiService {
   // Set user in localStorage
   private setUser(user: AuthUser): void {
     localStorage.setItem('auth_user', JSON.stringify(user))
+    this.scheduleRefresh(user)
+  }
+
+  // Refresh the token shortly before it expires
+  private scheduleRefresh(user: AuthUser): void {
+    if (this.refreshTimer) {
+      clearTimeout(this.refreshTimer)
+      this.refreshTimer = null
+    }
+    if (!user.expires_at) {
+      return
+    }
+
+    const delay = Math.max(0, new Date(user.expires_at).getTime() - Date.now() - REFRESH_BEFORE_EXPIRY_MS)
+    this.refreshTimer = setTimeout(async () => {
+      this.refreshTimer = null
+      if (!(await this.refreshToken())) {
+        this.expireSession()
+      }
+    }, delay)
+  }
+
+  // Clear the stored session and tell the app to show the login again
+  private expireSession(): void {
+    if (this.refreshTimer) {
+      clearTimeout(this.refreshTimer)
+      this.refreshTimer = null
+    }
+    localStorage.removeItem('auth_token')
+    localStorage.removeItem('auth_user')
+    window.dispatchEvent(new Event(AUTH_EXPIRED_EVENT))
+  }
+
+  // Fetch an authenticated endpoint of the Config API
+  // On 401 the token is refreshed once and the request retried; if the session
+  // can't be renewed it is cleared and AUTH_EXPIRED_EVENT is dispatched
+  async authorizedFetch(url: string, options: RequestInit = {}): Promise<Response> {
+    const send = () => {
+      const headers = new Headers(options.headers)
+      const token = this.getToken()
+      if (token) {
+        headers.set('Authorization', `Bearer ${token}`)
+      }
+      return fetch(url, { ...options, headers })
+    }
+
+    let response = await send()
+    if (response.status === 401 && this.getToken()) {
+      if (await this.refreshToken()) {
+        response = await send()
+      }
+      if (response.status === 401) {
+        this.expireSession()
+      }
+    }
+    return response
   }
 
-  // Logout - clear stored data
+  // Exchange the current token for a new one before it expires
+  async refreshToken(): Promise<AuthUser | null> {
+    if (!this.getToken()) {
+      return null
+    }
+
+    try {
+      const response = await this.request<AuthResponse>('/api/v1/auth/refresh', {
+        method: 'POST',
+      })
+
+      if (!response.success || !response.data) {
+        return null
+      }
+
+      this.setToken(response.data.token)
+      this.setUser(response.data)
+      return response.data
+    } catch {
+      return null
+    }
+  }
+
+  // Logout - revoke the session on the server and clear stored data
   logout(): void {
+    if (this.getToken()) {
+      this.request('/api/v1/auth/logout', { method: 'POST' }).catch(() => {})
+    }
+    if (this.refreshTimer) {
+      clearTimeout(this.refreshTimer)
+      this.refreshTimer = null
+    }
     localStorage.removeItem('auth_token')
     localStorage.removeItem('auth_user')
   }
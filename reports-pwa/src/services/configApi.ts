@@ -1,5 +1,7 @@
 // Config API Service for managing invoice limits and other configurations
 
+import { authApiService } from './authApi'
+
 export interface TimeInterval {
   start_time: string // HH:MM format
   end_time: string   // HH:MM format
@@ -89,7 +91,7 @@ class ConfigApiService {
       throw new Error('Config API no está configurado')
     }
 
-    const response = await fetch(`${this.baseUrl}/api/v1/config/invoice-limits`)
+    const response = await authApiService.authorizedFetch(`${this.baseUrl}/api/v1/config/invoice-limits`)
 
     if (!response.ok) {
       throw new Error(`Error al obtener configuración: ${response.status} ${response.statusText}`)
@@ -110,7 +112,7 @@ class ConfigApiService {
       throw new Error('Config API no está configurado')
     }
 
-    const response = await fetch(`${this.baseUrl}/api/v1/config/invoice-limits`, {
+    const response = await authApiService.authorizedFetch(`${this.baseUrl}/api/v1/config/invoice-limits`, {
       method: 'PUT',
       headers: {
         'Content-Type': 'application/json',
@@ -137,7 +139,7 @@ class ConfigApiService {
       throw new Error('Config API no está configurado')
     }
 
-    const response = await fetch(`${this.baseUrl}/api/v1/config/invoice-limits/status`)
+    const response = await authApiService.authorizedFetch(`${this.baseUrl}/api/v1/config/invoice-limits/status`)
 
     if (!response.ok) {
       throw new Error(`Error al obtener estado: ${response.status} ${response.statusText}`)
@@ -158,7 +160,7 @@ class ConfigApiService {
       throw new Error('Config API no está configurado')
     }
 
-    const response = await fetch(`${this.baseUrl}/api/v1/config/invoice-limits/sync`, {
+    const response = await authApiService.authorizedFetch(`${this.baseUrl}/api/v1/config/invoice-limits/sync`, {
       method: 'POST',
     })
 
@@ -1,5 +1,7 @@
 // Orders API Service for creating orders from PWA
 
+import { authApiService } from './authApi'
+
 export interface OrderType {
   id: number
   code: string
@@ -170,7 +172,8 @@ class OrdersApiService {
   private async request<T>(endpoint: string, options: RequestInit = {}): Promise<T> {
     const url = `${this.baseUrl}${endpoint}`
 
-    const response = await fetch(url, {
+    // The auth service adds the session token and handles expired sessions
+    const response = await authApiService.authorizedFetch(url, {
       ...options,
       headers: {
         'Content-Type': 'application/json',